@@ -0,0 +1,231 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/madmin-go"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var adminAPIListCmd = cli.Command{
+	Name:         "ls",
+	Usage:        "list the admin APIs supported by a server",
+	Action:       mainAdminAPIList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+STATUS:
+  supported     the API answered the request
+  unsupported   the server does not implement the API
+  denied        the credentials are not allowed to call the API
+  unconfigured  the API needs a subsystem the server is not configured with
+  error         the API failed for another reason
+
+EXAMPLES:
+  1. List the admin APIs supported by a MinIO server/cluster.
+     {{.Prompt}} {{.HelpName}} play
+
+  2. Check from a script whether the server supports tiering before using it.
+     {{.Prompt}} {{.HelpName}} --json play | jq -r '.apis[] | select(.name == "tier") | .status'
+`,
+}
+
+// Status of an admin API on a server.
+const (
+	adminAPISupported    = "supported"
+	adminAPIUnsupported  = "unsupported"
+	adminAPIDenied       = "denied"
+	adminAPIUnconfigured = "unconfigured"
+	adminAPIError        = "error"
+)
+
+// adminAPIProbe calls a read-only admin API to check its support.
+type adminAPIProbe struct {
+	name    string
+	command string
+	probe   func(ctx context.Context, client *madmin.AdminClient) error
+}
+
+var adminAPIProbes = []adminAPIProbe{
+	{"server-info", "admin info", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.ServerInfo(ctx)
+		return e
+	}},
+	{"storage-info", "admin info", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.StorageInfo(ctx)
+		return e
+	}},
+	{"data-usage-info", "du", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.DataUsageInfo(ctx)
+		return e
+	}},
+	{"account-info", "ls", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.AccountInfo(ctx, madmin.AccountOpts{})
+		return e
+	}},
+	{"user", "admin user", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.ListUsers(ctx)
+		return e
+	}},
+	{"group", "admin group", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.ListGroups(ctx)
+		return e
+	}},
+	{"policy", "admin policy", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.ListCannedPolicies(ctx)
+		return e
+	}},
+	{"service-account", "admin user svcacct", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.ListServiceAccounts(ctx, "")
+		return e
+	}},
+	{"config", "admin config", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.GetConfig(ctx)
+		return e
+	}},
+	{"config-history", "admin config history", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.ListConfigHistoryKV(ctx, 1)
+		return e
+	}},
+	{"heal-status", "admin heal", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.BackgroundHealStatus(ctx)
+		return e
+	}},
+	{"top-locks", "admin top locks", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.TopLocks(ctx)
+		return e
+	}},
+	{"kms", "admin kms", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.KMSStatus(ctx)
+		return e
+	}},
+	{"tier", "ilm tier", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.ListTiers(ctx)
+		return e
+	}},
+	{"remote-target", "admin bucket remote", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.ListRemoteTargets(ctx, "", "")
+		return e
+	}},
+	{"site-replication", "admin replicate", func(ctx context.Context, client *madmin.AdminClient) error {
+		_, e := client.SiteReplicationInfo(ctx)
+		return e
+	}},
+}
+
+// adminAPIStatus classifies the error returned by an admin API.
+func adminAPIStatus(e error) string {
+	if e == nil {
+		return adminAPISupported
+	}
+	errResp, ok := e.(madmin.ErrorResponse)
+	if !ok {
+		return adminAPIError
+	}
+	switch {
+	case errResp.Code == "NotImplemented", errResp.Code == "XMinioAdminVersionMismatch",
+		strings.HasPrefix(errResp.Code, "404 "), strings.HasPrefix(errResp.Code, "405 "), strings.HasPrefix(errResp.Code, "501 "):
+		return adminAPIUnsupported
+	case errResp.Code == "AccessDenied", strings.HasPrefix(errResp.Code, "403 "):
+		return adminAPIDenied
+	case strings.Contains(errResp.Code, "NotConfigured"), strings.Contains(errResp.Code, "NotEnabled"):
+		return adminAPIUnconfigured
+	}
+	return adminAPIError
+}
+
+type adminAPIInfo struct {
+	Name    string `json:"name"`
+	Command string `json:"command"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+type adminAPIListMessage struct {
+	Status  string         `json:"status"`
+	Alias   string         `json:"alias"`
+	Version string         `json:"version,omitempty"`
+	APIs    []adminAPIInfo `json:"apis"`
+}
+
+func (m adminAPIListMessage) JSON() string {
+	m.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+func (m adminAPIListMessage) String() string {
+	var b strings.Builder
+	if m.Version != "" {
+		fmt.Fprintf(&b, "Server version: %s\n", m.Version)
+	}
+	for _, api := range m.APIs {
+		mark := console.Colorize("APISupported", check)
+		if api.Status != adminAPISupported {
+			mark = console.Colorize("APIUnsupported", "✗")
+		}
+		fmt.Fprintf(&b, "%s %-17s %-13s %s\n", mark, api.Name, api.Status, api.Command)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func mainAdminAPIList(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "ls", 1) // last argument is exit code
+	}
+
+	console.SetColor("APISupported", color.New(color.FgGreen, color.Bold))
+	console.SetColor("APIUnsupported", color.New(color.FgRed, color.Bold))
+
+	aliasedURL := ctx.Args().Get(0)
+	client, err := newAdminClient(aliasedURL)
+	fatalIf(err, "Unable to initialize admin connection.")
+
+	msg := adminAPIListMessage{Alias: aliasedURL}
+	if info, e := client.ServerInfo(globalContext); e == nil && len(info.Servers) > 0 {
+		msg.Version = info.Servers[0].Version
+	}
+	for _, api := range adminAPIProbes {
+		e := api.probe(globalContext, client)
+		info := adminAPIInfo{Name: api.name, Command: "mc " + api.command, Status: adminAPIStatus(e)}
+		if e != nil {
+			info.Error = e.Error()
+		}
+		msg.APIs = append(msg.APIs, info)
+	}
+	printMsg(msg)
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestAdminAPIStatus(t *testing.T) {
+	testCases := []struct {
+		err    error
+		status string
+	}{
+		{nil, adminAPISupported},
+		{madmin.ErrorResponse{Code: "NotImplemented"}, adminAPIUnsupported},
+		{madmin.ErrorResponse{Code: "XMinioAdminVersionMismatch"}, adminAPIUnsupported},
+		{madmin.ErrorResponse{Code: "404 Not Found"}, adminAPIUnsupported},
+		{madmin.ErrorResponse{Code: "501 Not Implemented"}, adminAPIUnsupported},
+		{madmin.ErrorResponse{Code: "AccessDenied"}, adminAPIDenied},
+		{madmin.ErrorResponse{Code: "403 Forbidden"}, adminAPIDenied},
+		{madmin.ErrorResponse{Code: "XMinioKMSNotConfigured"}, adminAPIUnconfigured},
+		{madmin.ErrorResponse{Code: "XMinioAdminNoSuchUser"}, adminAPIError},
+		{errors.New("connection refused"), adminAPIError},
+	}
+	for i, testCase := range testCases {
+		if status := adminAPIStatus(testCase.err); status != testCase.status {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.status, status)
+		}
+	}
+}
@@ -18,26 +18,44 @@
 package cmd
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
 	"github.com/minio/madmin-go"
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
 )
 
+var adminConfigGetFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "describe",
+		Usage: "group keys by sub-system with their descriptions, highlighting the keys which are set",
+	},
+	cli.BoolFlag{
+		Name:  "defaults",
+		Usage: "only show keys which are not set, i.e. empty or off",
+	},
+	cli.BoolFlag{
+		Name:  "modified-only",
+		Usage: "only show keys which are set",
+	},
+}
+
 var adminConfigGetCmd = cli.Command{
 	Name:         "get",
 	Usage:        "interactively retrieve a config key parameters",
 	Before:       setGlobalsFromContext,
 	Action:       mainAdminConfigGet,
 	OnUsageError: onUsageError,
-	Flags:        globalFlags,
+	Flags:        append(adminConfigGetFlags, globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
 USAGE:
-  {{.HelpName}} TARGET
+  {{.HelpName}} [FLAGS] TARGET [SUB-SYSTEM...]
 
 FLAGS:
   {{range .VisibleFlags}}{{.}}
@@ -54,19 +72,186 @@ EXAMPLES:
   3. Get the current compression settings on MinIO server
      {{.Prompt}} {{.HelpName}} myminio/ compression
      compression extensions=".txt,.csv" mime_types="text/*"
+
+  4. Get only the API settings which are set on MinIO server.
+     {{.Prompt}} {{.HelpName}} --modified-only myminio/ api
+
+  5. Review the API settings on MinIO server, with the description of each key.
+     {{.Prompt}} {{.HelpName}} --describe myminio/ api
 `,
 }
 
+// isUnsetConfigValue returns true if the key is not set, the config
+// API reports the values of the keys but not their defaults.
+func isUnsetConfigValue(key, value string) bool {
+	return value == "" || (key == madmin.EnableKey && value == madmin.EnableOff)
+}
+
+// configGetKV is a config key with its value and description.
+type configGetKV struct {
+	Key         string
+	Value       string
+	Description string
+	Modified    bool
+}
+
+// configGetTarget is a sub-system, or one of its targets, with its keys.
+type configGetTarget struct {
+	Name        string
+	Description string
+	Env         []string
+	KVS         []configGetKV
+}
+
+// parseConfigGetTargets parses the output of the get config API into
+// the sub-systems it contains, help returns the help of a sub-system.
+func parseConfigGetTargets(buf []byte, help func(subSys string) (madmin.Help, error)) ([]configGetTarget, error) {
+	var targets []configGetTarget
+	var env []string
+	for _, line := range strings.Split(string(buf), madmin.KvNewline) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// Comments hold the environment variables overriding
+		// the sub-system on the next line.
+		if strings.HasPrefix(line, madmin.KvComment) {
+			env = append(env, strings.TrimSpace(strings.TrimPrefix(line, madmin.KvComment)))
+			continue
+		}
+
+		name := strings.SplitN(line, madmin.KvSpaceSeparator, 2)[0]
+		subSys := strings.SplitN(name, madmin.SubSystemSeparator, 2)[0]
+		hr, e := help(subSys)
+		if e != nil {
+			return nil, e
+		}
+		target := configGetTarget{Name: name, Description: hr.Description, Env: env}
+		env = nil
+
+		var kvs madmin.KVS
+		if strings.Contains(line, madmin.KvSpaceSeparator) {
+			tgt, e := madmin.ParseTarget(line, hr)
+			if e != nil {
+				return nil, e
+			}
+			kvs = tgt.KVS
+		}
+		// Keys are listed in the order of the help.
+		known := make(map[string]bool, len(hr.KeysHelp))
+		for _, kh := range hr.KeysHelp {
+			known[kh.Key] = true
+			value, _ := kvs.Lookup(kh.Key)
+			target.KVS = append(target.KVS, configGetKV{
+				Key:         kh.Key,
+				Value:       value,
+				Description: kh.Description,
+				Modified:    !isUnsetConfigValue(kh.Key, value),
+			})
+		}
+		for _, kv := range kvs {
+			if !known[kv.Key] {
+				target.KVS = append(target.KVS, configGetKV{
+					Key:      kv.Key,
+					Value:    kv.Value,
+					Modified: !isUnsetConfigValue(kv.Key, kv.Value),
+				})
+			}
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// filterConfigGetKVS keeps only the keys which are not set, or only the
+// ones which are set.
+func filterConfigGetKVS(kvs []configGetKV, modified bool) []configGetKV {
+	var filtered []configGetKV
+	for _, kv := range kvs {
+		if kv.Modified == modified {
+			filtered = append(filtered, kv)
+		}
+	}
+	return filtered
+}
+
 // configGetMessage container to hold locks information.
 type configGetMessage struct {
-	Status string         `json:"status"`
-	Value  *madmin.Target `json:"value"`
-	value  []byte
+	Status   string         `json:"status"`
+	Value    *madmin.Target `json:"value"`
+	value    []byte
+	targets  []configGetTarget
+	describe bool
 }
 
 // String colorized service status message.
 func (u configGetMessage) String() string {
-	return string(u.value)
+	if u.targets == nil {
+		return string(u.value)
+	}
+	if !u.describe {
+		return configGetTargetsString(u.targets)
+	}
+
+	var s strings.Builder
+	for i, target := range u.targets {
+		if i > 0 {
+			s.WriteString("\n")
+		}
+		s.WriteString(console.Colorize("ConfigSubSys", target.Name))
+		if target.Description != "" {
+			s.WriteString("  " + console.Colorize("ConfigDescription", target.Description))
+		}
+		s.WriteString("\n")
+		for _, env := range target.Env {
+			s.WriteString("  " + console.Colorize("ConfigEnv", "# "+env) + "\n")
+		}
+
+		// Pad before colorizing to keep columns aligned.
+		keyWidth, valueWidth := 0, 0
+		for _, kv := range target.KVS {
+			if len(kv.Key) > keyWidth {
+				keyWidth = len(kv.Key)
+			}
+			if len(kv.Value) > valueWidth {
+				valueWidth = len(kv.Value)
+			}
+		}
+		for _, kv := range target.KVS {
+			key := fmt.Sprintf("%-*s", keyWidth, kv.Key)
+			value := fmt.Sprintf("%-*s", valueWidth, kv.Value)
+			if kv.Modified {
+				key = console.Colorize("ConfigModified", key)
+				value = console.Colorize("ConfigModified", value)
+			} else {
+				key = console.Colorize("ConfigKey", key)
+			}
+			line := "  " + key + "  " + value
+			if kv.Description != "" {
+				line += "  " + console.Colorize("ConfigDescription", kv.Description)
+			}
+			s.WriteString(strings.TrimRight(line, " ") + "\n")
+		}
+	}
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+// configGetTargetsString formats the targets the way the config API
+// does, a target per line followed by its keys.
+func configGetTargetsString(targets []configGetTarget) string {
+	var lines []string
+	for _, target := range targets {
+		line := target.Name
+		for _, kv := range target.KVS {
+			value := kv.Value
+			if strings.ContainsAny(value, " \t") {
+				value = `"` + value + `"`
+			}
+			line += " " + kv.Key + "=" + value
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
 }
 
 // JSON jsonified service status Message message.
@@ -83,12 +268,21 @@ func checkAdminConfigGetSyntax(ctx *cli.Context) {
 	if !ctx.Args().Present() || len(ctx.Args()) < 1 {
 		cli.ShowCommandHelpAndExit(ctx, "get", 1) // last argument is exit code
 	}
+	if ctx.Bool("defaults") && ctx.Bool("modified-only") {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "`--defaults` and `--modified-only` cannot be used together.")
+	}
 }
 
 func mainAdminConfigGet(ctx *cli.Context) error {
 
 	checkAdminConfigGetSyntax(ctx)
 
+	console.SetColor("ConfigSubSys", color.New(color.FgBlue, color.Bold))
+	console.SetColor("ConfigKey", color.New(color.Bold))
+	console.SetColor("ConfigModified", color.New(color.FgYellow, color.Bold))
+	console.SetColor("ConfigDescription", color.New(color.Faint))
+	console.SetColor("ConfigEnv", color.New(color.FgCyan))
+
 	// Get the alias parameter from cli
 	args := ctx.Args()
 	aliasedURL := args.Get(0)
@@ -124,16 +318,59 @@ func mainAdminConfigGet(ctx *cli.Context) error {
 		tgt, e := madmin.ParseSubSysTarget(buf, hr)
 		fatalIf(probe.NewError(e), "Unable to parse sub-system target "+subSys)
 
+		if ctx.Bool("defaults") || ctx.Bool("modified-only") {
+			var kvs madmin.KVS
+			for _, kv := range tgt.KVS {
+				if isUnsetConfigValue(kv.Key, kv.Value) == ctx.Bool("defaults") {
+					kvs = append(kvs, kv)
+				}
+			}
+			tgt.KVS = kvs
+		}
+
 		printMsg(configGetMessage{
 			Value: tgt,
 			value: buf,
 		})
-	} else {
+		return nil
+	}
+
+	describe := ctx.Bool("describe")
+	filter := ctx.Bool("defaults") || ctx.Bool("modified-only")
+	if !describe && !filter {
 		// Print
 		printMsg(configGetMessage{
 			value: buf,
 		})
+		return nil
 	}
 
+	helps := make(map[string]madmin.Help)
+	targets, e := parseConfigGetTargets(buf, func(subSys string) (madmin.Help, error) {
+		if hr, ok := helps[subSys]; ok {
+			return hr, nil
+		}
+		hr, e := client.HelpConfigKV(globalContext, subSys, "", false)
+		if e != nil {
+			return hr, e
+		}
+		helps[subSys] = hr
+		return hr, nil
+	})
+	fatalIf(probe.NewError(e), "Unable to parse sub-system target "+subSys)
+
+	if filter {
+		for i := range targets {
+			targets[i].KVS = filterConfigGetKVS(targets[i].KVS, ctx.Bool("modified-only"))
+		}
+	}
+
+	// Print
+	printMsg(configGetMessage{
+		value:    buf,
+		targets:  targets,
+		describe: describe,
+	})
+
 	return nil
 }
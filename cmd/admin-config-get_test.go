@@ -0,0 +1,89 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestParseConfigGetTargets(t *testing.T) {
+	help := func(subSys string) (madmin.Help, error) {
+		return madmin.Help{
+			SubSys:          subSys,
+			MultipleTargets: subSys == "notify_webhook",
+			KeysHelp: madmin.HelpKVS{
+				{Key: "enable"},
+				{Key: "endpoint"},
+				{Key: "queue_limit"},
+			},
+		}, nil
+	}
+	buf := []byte(`notify_webhook enable=off endpoint= queue_limit=0
+# MINIO_NOTIFY_WEBHOOK_ENABLE_1=on
+notify_webhook:1 enable=on endpoint="http://localhost:8080" queue_limit=0
+`)
+
+	targets, e := parseConfigGetTargets(buf, help)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if len(targets) != 2 {
+		t.Fatalf("expected 2 targets, got %d", len(targets))
+	}
+	if targets[0].Name != "notify_webhook" || len(targets[0].Env) != 0 {
+		t.Fatalf("unexpected first target %#v", targets[0])
+	}
+	if targets[1].Name != "notify_webhook:1" || len(targets[1].Env) != 1 {
+		t.Fatalf("unexpected second target %#v", targets[1])
+	}
+
+	testCases := []struct {
+		target   int
+		modified []string
+	}{
+		{0, []string{"queue_limit"}},
+		{1, []string{"enable", "endpoint", "queue_limit"}},
+	}
+	for i, testCase := range testCases {
+		var modified []string
+		for _, kv := range filterConfigGetKVS(targets[testCase.target].KVS, true) {
+			modified = append(modified, kv.Key)
+		}
+		if len(modified) != len(testCase.modified) {
+			t.Fatalf("Test %d: expected %v modified, got %v", i+1, testCase.modified, modified)
+		}
+		for j := range modified {
+			if modified[j] != testCase.modified[j] {
+				t.Fatalf("Test %d: expected %v modified, got %v", i+1, testCase.modified, modified)
+			}
+		}
+	}
+}
+
+func TestConfigGetTargetsString(t *testing.T) {
+	targets := []configGetTarget{
+		{Name: "notify_webhook", KVS: []configGetKV{{Key: "enable", Value: "off"}, {Key: "endpoint"}}},
+		{Name: "notify_webhook:1", KVS: []configGetKV{{Key: "endpoint", Value: "http://localhost:8080"}, {Key: "comment", Value: "audit hook"}}},
+	}
+	expected := "notify_webhook enable=off endpoint=\nnotify_webhook:1 endpoint=http://localhost:8080 comment=\"audit hook\""
+	if got := configGetTargetsString(targets); got != expected {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
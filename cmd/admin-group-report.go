@@ -0,0 +1,239 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+	iampolicy "github.com/minio/pkg/iam/policy"
+)
+
+var adminGroupReportCmd = cli.Command{
+	Name:         "report",
+	Usage:        "display the members of a group and the permissions its policies grant",
+	Action:       mainAdminGroupReport,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} TARGET GROUPNAME
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Review the members of group 'allcents' and the actions allowed or denied to them on each resource.
+     {{.Prompt}} {{.HelpName}} myminio allcents
+
+  2. Export the permissions granted by group 'allcents' for an access review.
+     {{.Prompt}} {{.HelpName}} --json myminio allcents
+`,
+}
+
+// groupPermission is an action allowed or denied on a resource by the
+// policies of a group.
+type groupPermission struct {
+	Effect   string   `json:"effect"`
+	Action   string   `json:"action"`
+	Resource string   `json:"resource"`
+	Policies []string `json:"policies"`
+	// Set when every statement granting it has conditions.
+	Conditional bool `json:"conditional,omitempty"`
+}
+
+// groupReportMessage container for the members and permissions of a group.
+type groupReportMessage struct {
+	Status      string            `json:"status"`
+	GroupName   string            `json:"groupName"`
+	GroupStatus string            `json:"groupStatus"`
+	Members     []string          `json:"members"`
+	Policies    []string          `json:"policies"`
+	Permissions []groupPermission `json:"permissions"`
+}
+
+func (g groupReportMessage) String() string {
+	var b strings.Builder
+	fmt.Fprintln(&b, console.Colorize("GroupMessage", "Group: "+g.GroupName))
+	fmt.Fprintln(&b, console.Colorize("GroupMessage", "Status: "+g.GroupStatus))
+	fmt.Fprintln(&b, console.Colorize("GroupMessage", "Members: "+strings.Join(g.Members, ", ")))
+	fmt.Fprintln(&b, console.Colorize("GroupMessage", "Policies: "+strings.Join(g.Policies, ", ")))
+	if len(g.Permissions) == 0 {
+		b.WriteString(console.Colorize("GroupMessage", "Permissions: none"))
+		return b.String()
+	}
+	fmt.Fprintln(&b, console.Colorize("GroupMessage", "Permissions:"))
+
+	maxAction, maxResource := len("Action"), len("Resource")
+	for _, p := range g.Permissions {
+		if len(p.Action) > maxAction {
+			maxAction = len(p.Action)
+		}
+		if len(p.Resource) > maxResource {
+			maxResource = len(p.Resource)
+		}
+	}
+	table := newPrettyTable(" | ",
+		Field{"Effect", 6},
+		Field{"Action", maxAction},
+		Field{"Resource", maxResource},
+		Field{"Policies", -1},
+	)
+	b.WriteString(console.Colorize("THeaders", "  "+table.buildRow("Effect", "Action", "Resource", "Policies")))
+	for _, p := range g.Permissions {
+		policies := strings.Join(p.Policies, ", ")
+		if p.Conditional {
+			policies += " (conditional)"
+		}
+		theme := "GroupAllow"
+		if p.Effect != "Allow" {
+			theme = "GroupDeny"
+		}
+		b.WriteString("\n" + console.Colorize(theme, "  "+table.buildRow(p.Effect, p.Action, p.Resource, policies)))
+	}
+	return b.String()
+}
+
+func (g groupReportMessage) JSON() string {
+	g.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(g, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(jsonMessageBytes)
+}
+
+// mergeGroupPermissions merges the statements of the policies into one
+// permission per effect, action and resource, listing the policies
+// granting it. Statements without resources, e.g. of admin actions,
+// are reported on the resource "*".
+func mergeGroupPermissions(policies map[string]*iampolicy.Policy) []groupPermission {
+	type permissionKey struct {
+		effect, action, resource string
+	}
+	merged := make(map[permissionKey]*groupPermission)
+	for name, p := range policies {
+		for _, statement := range p.Statements {
+			resources := []string{"*"}
+			if len(statement.Resources) > 0 {
+				resources = resources[:0]
+				for _, r := range statement.Resources.ToSlice() {
+					resources = append(resources, r.String())
+				}
+			}
+			for _, action := range statement.Actions.ToSlice() {
+				for _, resource := range resources {
+					conditional := len(statement.Conditions) > 0
+					key := permissionKey{string(statement.Effect), string(action), resource}
+					permission, ok := merged[key]
+					if !ok {
+						permission = &groupPermission{Effect: key.effect, Action: key.action, Resource: key.resource, Conditional: conditional}
+						merged[key] = permission
+					}
+					// Statements of a policy are merged in a row.
+					if n := len(permission.Policies); n == 0 || permission.Policies[n-1] != name {
+						permission.Policies = append(permission.Policies, name)
+					}
+					// Conditional only when no statement grants it unconditionally.
+					permission.Conditional = permission.Conditional && conditional
+				}
+			}
+		}
+	}
+
+	permissions := make([]groupPermission, 0, len(merged))
+	for _, permission := range merged {
+		sort.Strings(permission.Policies)
+		permissions = append(permissions, *permission)
+	}
+	// Denials first since they take precedence, then by resource and action.
+	sort.Slice(permissions, func(i, j int) bool {
+		if permissions[i].Effect != permissions[j].Effect {
+			return permissions[i].Effect == "Deny"
+		}
+		if permissions[i].Resource != permissions[j].Resource {
+			return permissions[i].Resource < permissions[j].Resource
+		}
+		return permissions[i].Action < permissions[j].Action
+	})
+	return permissions
+}
+
+// checkAdminGroupReportSyntax - validate all the passed arguments
+func checkAdminGroupReportSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(ctx, "report", 1) // last argument is exit code
+	}
+}
+
+// mainAdminGroupReport is the handle for "mc admin group report" command.
+func mainAdminGroupReport(ctx *cli.Context) error {
+	checkAdminGroupReportSyntax(ctx)
+
+	console.SetColor("GroupMessage", color.New(color.FgGreen))
+	console.SetColor("THeaders", color.New(color.Bold))
+	console.SetColor("GroupAllow", color.New(color.FgGreen))
+	console.SetColor("GroupDeny", color.New(color.FgRed))
+
+	// Get the alias parameter from cli
+	args := ctx.Args()
+	aliasedURL := args.Get(0)
+
+	// Create a new MinIO Admin Client
+	client, err := newAdminClient(aliasedURL)
+	fatalIf(err, "Unable to initialize admin connection.")
+
+	group := args.Get(1)
+	gd, e := client.GetGroupDescription(globalContext, group)
+	fatalIf(probe.NewError(e).Trace(args...), "Unable to get group info")
+
+	names := []string{}
+	policies := make(map[string]*iampolicy.Policy)
+	for _, name := range strings.Split(gd.Policy, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		pinfo, e := getPolicyInfo(client, name)
+		fatalIf(probe.NewError(e).Trace(name), "Unable to fetch policy `"+name+"`.")
+		p, e := iampolicy.ParseConfig(bytes.NewReader(pinfo.Policy))
+		fatalIf(probe.NewError(e).Trace(name), "Unable to parse policy `"+name+"`.")
+		policies[name] = p
+		names = append(names, name)
+	}
+
+	members := append([]string{}, gd.Members...)
+	sort.Strings(members)
+	printMsg(groupReportMessage{
+		GroupName:   group,
+		GroupStatus: gd.Status,
+		Members:     members,
+		Policies:    names,
+		Permissions: mergeGroupPermissions(policies),
+	})
+
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	iampolicy "github.com/minio/pkg/iam/policy"
+)
+
+func TestMergeGroupPermissions(t *testing.T) {
+	parse := func(s string) *iampolicy.Policy {
+		p, e := iampolicy.ParseConfig(strings.NewReader(s))
+		if e != nil {
+			t.Fatal(e)
+		}
+		return p
+	}
+	policies := map[string]*iampolicy.Policy{
+		"readonly": parse(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":["arn:aws:s3:::photos/*"]},{"Effect":"Allow","Action":["s3:GetObject","s3:ListBucket"],"Resource":["arn:aws:s3:::photos/*"],"Condition":{"IpAddress":{"aws:SourceIp":"10.0.0.0/8"}}}]}`),
+		"uploads":  parse(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:GetObject","s3:PutObject"],"Resource":["arn:aws:s3:::photos/*"]},{"Effect":"Deny","Action":["s3:DeleteObject"],"Resource":["arn:aws:s3:::photos/*"]}]}`),
+		"admins":   parse(`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["admin:ServerInfo"]}]}`),
+	}
+
+	expected := []groupPermission{
+		{Effect: "Deny", Action: "s3:DeleteObject", Resource: "arn:aws:s3:::photos/*", Policies: []string{"uploads"}},
+		{Effect: "Allow", Action: "admin:ServerInfo", Resource: "*", Policies: []string{"admins"}},
+		{Effect: "Allow", Action: "s3:GetObject", Resource: "arn:aws:s3:::photos/*", Policies: []string{"readonly", "uploads"}},
+		{Effect: "Allow", Action: "s3:ListBucket", Resource: "arn:aws:s3:::photos/*", Policies: []string{"readonly"}, Conditional: true},
+		{Effect: "Allow", Action: "s3:PutObject", Resource: "arn:aws:s3:::photos/*", Policies: []string{"uploads"}},
+	}
+	if permissions := mergeGroupPermissions(policies); !reflect.DeepEqual(permissions, expected) {
+		t.Errorf("expected %+v, got %+v", expected, permissions)
+	}
+}
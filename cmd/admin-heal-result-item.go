@@ -78,6 +78,25 @@ func (h hri) getReplicatedFileHCCChange() (b, a col, err error) {
 	return
 }
 
+// getHealEstimate - returns the number of erasure shards of an object
+// on missing or corrupted drives and an estimate of the data rebuilt
+// to heal them.
+func (h hri) getHealEstimate() (shards int, size int64) {
+	if h.Type != madmin.HealItemObject {
+		return 0, 0
+	}
+	for _, drive := range h.Before.Drives {
+		if drive.State == madmin.DriveStateMissing || drive.State == madmin.DriveStateCorrupt {
+			shards++
+		}
+	}
+	if shards == 0 || h.ObjectSize <= 0 || h.DataBlocks <= 0 {
+		return shards, 0
+	}
+	shardSize := (h.ObjectSize + int64(h.DataBlocks) - 1) / int64(h.DataBlocks)
+	return shards, int64(shards) * shardSize
+}
+
 func (h hri) makeHealEntityString() string {
 	switch h.Type {
 	case madmin.HealItemObject:
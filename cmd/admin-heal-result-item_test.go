@@ -0,0 +1,64 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestGetHealEstimate(t *testing.T) {
+	drives := func(states ...string) []madmin.HealDriveInfo {
+		var infos []madmin.HealDriveInfo
+		for _, state := range states {
+			infos = append(infos, madmin.HealDriveInfo{State: state})
+		}
+		return infos
+	}
+	testCases := []struct {
+		item   madmin.HealResultItem
+		shards int
+		size   int64
+	}{
+		{
+			item:   madmin.HealResultItem{Type: madmin.HealItemObject, ObjectSize: 1000, DataBlocks: 2},
+			shards: 0, size: 0,
+		},
+		{
+			item: madmin.HealResultItem{Type: madmin.HealItemObject, ObjectSize: 1001, DataBlocks: 2,
+				Before: struct {
+					Drives []madmin.HealDriveInfo `json:"drives"`
+				}{drives(madmin.DriveStateOk, madmin.DriveStateMissing, madmin.DriveStateCorrupt, madmin.DriveStateOffline)}},
+			shards: 2, size: 1002,
+		},
+		{
+			item: madmin.HealResultItem{Type: madmin.HealItemBucket,
+				Before: struct {
+					Drives []madmin.HealDriveInfo `json:"drives"`
+				}{drives(madmin.DriveStateMissing)}},
+			shards: 0, size: 0,
+		},
+	}
+	for i, testCase := range testCases {
+		shards, size := newHRI(&testCase.item).getHealEstimate()
+		if shards != testCase.shards || size != testCase.size {
+			t.Errorf("Test %d: expected %d shards and %d bytes, got %d and %d", i+1, testCase.shards, testCase.size, shards, size)
+		}
+	}
+}
@@ -94,6 +94,11 @@ type uiData struct {
 	// health color code.
 	HealthCols map[col]int64
 
+	// Estimates of a dry run: the drives examined, the objects
+	// with shards to heal and the size of the shards to rebuild.
+	DrivesExamined             map[string]struct{}
+	ObjectsToHeal, BytesToHeal int64
+
 	// channel to receive a prompt string to indicate activity on
 	// the terminal
 	CurChan (<-chan string)
@@ -120,12 +125,22 @@ func (ui *uiData) updateStats(i madmin.HealResultItem) error {
 	}
 	ui.ObjectsByOnlineDrives[afterUp]++
 
+	h := newHRI(&i)
+
+	// Update dry run estimates:
+	for _, drive := range i.Before.Drives {
+		ui.DrivesExamined[drive.Endpoint] = struct{}{}
+	}
+	if shards, size := h.getHealEstimate(); shards > 0 {
+		ui.ObjectsToHeal++
+		ui.BytesToHeal += size
+	}
+
 	// Update health color stats:
 
 	// Fetch health color after heal:
 	var err error
 	var afterCol col
-	h := newHRI(&i)
 	switch h.Type {
 	case madmin.HealItemMetadata, madmin.HealItemBucket:
 		_, afterCol, err = h.getReplicatedFileHCCChange()
@@ -236,6 +251,24 @@ func (ui *uiData) printStatsQuietly(s *madmin.HealTaskStatus) {
 	console.PrintC(healedStr)
 }
 
+func (ui *uiData) printDryRunSummary() {
+	totalObjects, totalSize, _ := ui.getProgress()
+	t := newPrettyRecord(2,
+		Row{"Summary", ""},
+		Row{"Examined", ""},
+		Row{"Drives", ""},
+		Row{"To heal", ""},
+		Row{"To rebuild", ""},
+	)
+	console.PrintC("\n" + t.buildRecord(
+		"Dry run, no object was healed:",
+		fmt.Sprintf("%s objects; %s", totalObjects, totalSize),
+		humanize.Comma(int64(len(ui.DrivesExamined))),
+		fmt.Sprintf("%s objects", humanize.Comma(ui.ObjectsToHeal)),
+		fmt.Sprintf("%s (estimated)", humanize.IBytes(uint64(ui.BytesToHeal))),
+	))
+}
+
 func (ui *uiData) printItemsJSON(s *madmin.HealTaskStatus) (err error) {
 	type healRec struct {
 		Status string `json:"status"`
@@ -309,6 +342,10 @@ func (ui *uiData) printStatsJSON(s *madmin.HealTaskStatus) {
 		ItemsHealed    int64  `json:"items_healed"`
 		Size           int64  `json:"size"`
 		ElapsedTime    int64  `json:"duration"`
+		DryRun         bool   `json:"dry_run,omitempty"`
+		DrivesExamined int    `json:"drives_examined,omitempty"`
+		ObjectsToHeal  int64  `json:"objects_to_heal,omitempty"`
+		BytesToHeal    int64  `json:"estimated_heal_size,omitempty"`
 	}
 
 	summary.Status = "success"
@@ -320,6 +357,12 @@ func (ui *uiData) printStatsJSON(s *madmin.HealTaskStatus) {
 	summary.ItemsHealed = ui.ItemsHealed
 	summary.Size = ui.BytesScanned
 	summary.ElapsedTime = int64(ui.HealDuration.Round(time.Second).Seconds())
+	if ui.HealOpts.DryRun {
+		summary.DryRun = true
+		summary.DrivesExamined = len(ui.DrivesExamined)
+		summary.ObjectsToHeal = ui.ObjectsToHeal
+		summary.BytesToHeal = ui.BytesToHeal
+	}
 
 	jBytes, err := json.MarshalIndent(summary, "", " ")
 	fatalIf(probe.NewError(err), "Unable to marshal to JSON.")
@@ -426,9 +469,12 @@ func (ui *uiData) DisplayAndFollowHealStatus(aliasedURL string) (res madmin.Heal
 			}
 
 			if res.Summary == "finished" {
-				if globalJSON {
+				switch {
+				case globalJSON:
 					ui.printStatsJSON(&res)
-				} else if globalQuiet {
+				case ui.HealOpts.DryRun:
+					ui.printDryRunSummary()
+				case globalQuiet:
 					ui.printStatsQuietly(&res)
 				}
 				return res, nil
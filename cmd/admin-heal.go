@@ -51,7 +51,7 @@ var adminHealFlags = []cli.Flag{
 	},
 	cli.BoolFlag{
 		Name:  "dry-run, n",
-		Usage: "only inspect data, but do not mutate",
+		Usage: "only inspect data and estimate the objects and data to heal, but do not mutate",
 	},
 	cli.BoolFlag{
 		Name:  "force-start, f",
@@ -96,6 +96,9 @@ EXAMPLES:
      Summary:
      =======
      No ongoing active healing.
+
+  2. Estimate the objects and data to heal under a prefix of bucket 'mybucket', without healing them:
+     {{.Prompt}} {{.HelpName}} --recursive --dry-run myminio/mybucket/myprefix
 `,
 }
 
@@ -672,6 +675,7 @@ func mainAdminHeal(ctx *cli.Context) error {
 		HealOpts:              &opts,
 		ObjectsByOnlineDrives: make(map[int]int64),
 		HealthCols:            make(map[col]int64),
+		DrivesExamined:        make(map[string]struct{}),
 		CurChan:               cursorAnimate(),
 	}
 
@@ -20,9 +20,27 @@ package cmd
 import "github.com/minio/cli"
 
 var (
-	adminFlags = []cli.Flag{}
+	adminFlags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "node",
+			Usage: "direct admin commands at the given HOST:PORT node of the cluster",
+		},
+	}
 )
 
+func init() {
+	addAdminFlags(adminCmdSubcommands)
+}
+
+// addAdminFlags adds the flags common to admin commands to the commands
+// and their subcommands.
+func addAdminFlags(cmds []cli.Command) {
+	for i := range cmds {
+		cmds[i].Flags = append(cmds[i].Flags, adminFlags...)
+		addAdminFlags(cmds[i].Subcommands)
+	}
+}
+
 const (
 	// dot represents a list item, for eg. server status - online (green) or offline (red)
 	dot = "●"
@@ -34,6 +52,7 @@ var adminCmdSubcommands = []cli.Command{
 	adminServiceCmd,
 	adminServerUpdateCmd,
 	adminInfoCmd,
+	adminTopologyCmd,
 	adminInspectCmd,
 	adminUserCmd,
 	adminGroupCmd,
@@ -52,6 +71,7 @@ var adminCmdSubcommands = []cli.Command{
 	adminBucketCmd,
 	adminTierCmd,
 	adminSpeedtestCmd,
+	adminAPICmd,
 }
 
 var adminCmd = cli.Command{
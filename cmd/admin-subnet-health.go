@@ -74,6 +74,10 @@ var adminHealthFlags = append([]cli.Flag{
 		Usage:  "include long running health report(s) (takes longer to generate the report)",
 		Hidden: false,
 	},
+	cli.StringFlag{
+		Name:  "upload",
+		Usage: "upload a previously saved health report to SUBNET",
+	},
 }, subnetCommonFlags...)
 
 var adminSubnetHealthCmd = cli.Command{
@@ -104,6 +108,9 @@ EXAMPLES:
 
   4. Generate MinIO health report for alias 'play' (https://play.min.io by default) save and upload to SUBNET manually
      {{.Prompt}} {{.HelpName}} play --airgap
+
+  5. Upload a saved MinIO health report for alias 'play' to SUBNET
+     {{.Prompt}} {{.HelpName}} play --upload play-health_20211224100000.json.gz
 `,
 }
 
@@ -197,6 +204,16 @@ func mainSubnetHealth(ctx *cli.Context) error {
 		name = alias
 	}
 
+	if filename := ctx.String("upload"); filename != "" {
+		if !uploadToSubnet {
+			fatalIf(errInvalidArgument(), "--upload is not applicable in airgap mode")
+		}
+		reqURL, headers := prepareHealthUploadURL(alias, name, filepath.Base(filename), license)
+		e = uploadHealthReport(alias, filename, reqURL, headers)
+		fatalIf(probe.NewError(e), "Unable to upload MinIO health report to SUBNET portal")
+		return nil
+	}
+
 	// Main execution
 	execAdminHealth(ctx, client, alias, license, name, uploadToSubnet)
 
@@ -311,14 +328,20 @@ func prepareHealthUploadURL(alias string, clusterName string, filename string, l
 	return reqURL, headers
 }
 
-func uploadHealthReport(alias string, filename string, reqURL string, headers map[string]string) error {
-	req, e := subnetUploadReq(reqURL, filename)
-	if e != nil {
-		return e
-	}
+const (
+	// The upload of a health report is retried upon network
+	// failures and server errors.
+	subnetUploadMaxRetries = 5
+	subnetUploadTimeout    = 30 * time.Minute
+)
 
-	resp, e := subnetReqDo(req, headers)
+func uploadHealthReport(alias string, filename string, reqURL string, headers map[string]string) error {
+	resp, e := subnetUploadWithRetry(func() (*http.Request, error) {
+		return subnetUploadReq(reqURL, filename)
+	}, headers)
 	if e != nil {
+		console.Infoln("MinIO health report saved at", filename+", upload it again with:")
+		console.Infoln("  mc admin subnet health", alias, "--upload", filename)
 		return e
 	}
 
@@ -336,6 +359,29 @@ func uploadHealthReport(alias string, filename string, reqURL string, headers ma
 	return nil
 }
 
+// subnetUploadWithRetry sends the request returned by newReq, retrying
+// with an exponential backoff upon network failures and server errors.
+// Other errors, such as an invalid license, are returned right away.
+func subnetUploadWithRetry(newReq func() (*http.Request, error), headers map[string]string) (resp string, e error) {
+	for i := 0; i < subnetUploadMaxRetries; i++ {
+		if i > 0 {
+			time.Sleep(time.Duration(1<<uint(i-1)) * time.Second)
+		}
+		var req *http.Request
+		if req, e = newReq(); e != nil {
+			return "", e
+		}
+		if resp, e = subnetReqDoWithTimeout(req, headers, subnetUploadTimeout); e == nil {
+			return resp, nil
+		}
+		var respErr subnetRespError
+		if errors.As(e, &respErr) && respErr.StatusCode < http.StatusInternalServerError {
+			return resp, e
+		}
+	}
+	return resp, e
+}
+
 func subnetUploadReq(url string, filename string) (*http.Request, error) {
 	file, e := os.Open(filename)
 	if e != nil {
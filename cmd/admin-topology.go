@@ -0,0 +1,245 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/madmin-go"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var adminTopologyCmd = cli.Command{
+	Name:         "topology",
+	Usage:        "display server pools, erasure sets and drives of a MinIO cluster",
+	Action:       mainAdminTopology,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Display the pools, erasure sets and drives of the 'play' MinIO server.
+     {{.Prompt}} {{.HelpName}} play/
+`,
+}
+
+// topologyDrive holds the state of a drive in an erasure set.
+type topologyDrive struct {
+	Index      int    `json:"index"`
+	Endpoint   string `json:"endpoint"`
+	State      string `json:"state"`
+	Healing    bool   `json:"healing,omitempty"`
+	UsedSpace  uint64 `json:"usedSpace,omitempty"`
+	TotalSpace uint64 `json:"totalSpace,omitempty"`
+}
+
+// topologySet holds the drives of an erasure set.
+type topologySet struct {
+	Index   int             `json:"index"`
+	Online  int             `json:"online"`
+	Offline int             `json:"offline"`
+	Healing int             `json:"healing"`
+	Drives  []topologyDrive `json:"drives"`
+}
+
+// topologyPool holds the erasure sets of a server pool.
+type topologyPool struct {
+	Index int           `json:"index"`
+	Sets  []topologySet `json:"sets"`
+}
+
+// adminTopologyMessage container for the cluster topology.
+type adminTopologyMessage struct {
+	Status string         `json:"status"`
+	Pools  []topologyPool `json:"pools"`
+}
+
+// JSON jsonified topology message.
+func (t adminTopologyMessage) JSON() string {
+	t.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(t, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// String renders the topology as a tree.
+//
+//	Pool 1
+//	└─ Set 1: 4/4 drives online
+//	   ├─ ● http://server1/disk1 ok
+//	   ...
+func (t adminTopologyMessage) String() string {
+	var b strings.Builder
+	for i, pool := range t.Pools {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(console.Colorize("TopologyPool", fmt.Sprintf("Pool %d", pool.Index+1)))
+		for j, set := range pool.Sets {
+			setBranch, setIndent := "├─ ", "│  "
+			if j == len(pool.Sets)-1 {
+				setBranch, setIndent = "└─ ", "   "
+			}
+			setTheme := "TopologyOk"
+			switch {
+			case set.Offline > 0:
+				setTheme = "TopologyFail"
+			case set.Healing > 0:
+				setTheme = "TopologyWarning"
+			}
+			summary := fmt.Sprintf("Set %d: %d/%d drives online", set.Index+1, set.Online, len(set.Drives))
+			if set.Healing > 0 {
+				summary += fmt.Sprintf(", %d healing", set.Healing)
+			}
+			b.WriteString("\n" + setBranch + console.Colorize(setTheme, summary))
+			for k, drive := range set.Drives {
+				driveBranch := "├─ "
+				if k == len(set.Drives)-1 {
+					driveBranch = "└─ "
+				}
+				theme := "TopologyOk"
+				state := drive.State
+				switch {
+				case drive.State != madmin.DriveStateOk:
+					theme = "TopologyFail"
+				case drive.Healing:
+					theme = "TopologyWarning"
+					state = "healing"
+				}
+				line := fmt.Sprintf("%s %s %s", console.Colorize(theme, dot), drive.Endpoint, console.Colorize(theme, state))
+				if drive.TotalSpace > 0 {
+					line += fmt.Sprintf(" (%s/%s used)", humanize.IBytes(drive.UsedSpace), humanize.IBytes(drive.TotalSpace))
+				}
+				b.WriteString("\n" + setIndent + driveBranch + line)
+			}
+		}
+	}
+	return b.String()
+}
+
+// buildTopology groups all drives reported by the servers into
+// pools and erasure sets, sorted by their respective indexes.
+func buildTopology(info madmin.InfoMessage) []topologyPool {
+	sets := make(map[int]map[int]*topologySet)
+	for _, srv := range info.Servers {
+		for _, disk := range srv.Disks {
+			if disk.PoolIndex < 0 || disk.SetIndex < 0 {
+				// Drive not assigned to any set yet.
+				continue
+			}
+			if sets[disk.PoolIndex] == nil {
+				sets[disk.PoolIndex] = make(map[int]*topologySet)
+			}
+			set := sets[disk.PoolIndex][disk.SetIndex]
+			if set == nil {
+				set = &topologySet{Index: disk.SetIndex}
+				sets[disk.PoolIndex][disk.SetIndex] = set
+			}
+			endpoint := disk.Endpoint
+			if endpoint == "" {
+				endpoint = srv.Endpoint + disk.DrivePath
+			}
+			set.Drives = append(set.Drives, topologyDrive{
+				Index:      disk.DiskIndex,
+				Endpoint:   endpoint,
+				State:      disk.State,
+				Healing:    disk.Healing,
+				UsedSpace:  disk.UsedSpace,
+				TotalSpace: disk.TotalSpace,
+			})
+			switch {
+			case disk.State != madmin.DriveStateOk:
+				set.Offline++
+			case disk.Healing:
+				set.Online++
+				set.Healing++
+			default:
+				set.Online++
+			}
+		}
+	}
+
+	var pools []topologyPool
+	for poolIdx, poolSets := range sets {
+		pool := topologyPool{Index: poolIdx}
+		for _, set := range poolSets {
+			sort.Slice(set.Drives, func(i, j int) bool {
+				return set.Drives[i].Index < set.Drives[j].Index
+			})
+			pool.Sets = append(pool.Sets, *set)
+		}
+		sort.Slice(pool.Sets, func(i, j int) bool {
+			return pool.Sets[i].Index < pool.Sets[j].Index
+		})
+		pools = append(pools, pool)
+	}
+	sort.Slice(pools, func(i, j int) bool {
+		return pools[i].Index < pools[j].Index
+	})
+	return pools
+}
+
+// checkAdminTopologySyntax - validate all the passed arguments
+func checkAdminTopologySyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "topology", 1) // last argument is exit code
+	}
+}
+
+func mainAdminTopology(ctx *cli.Context) error {
+	checkAdminTopologySyntax(ctx)
+
+	console.SetColor("TopologyPool", color.New(color.FgCyan, color.Bold))
+	console.SetColor("TopologyOk", color.New(color.FgGreen, color.Bold))
+	console.SetColor("TopologyFail", color.New(color.FgRed, color.Bold))
+	console.SetColor("TopologyWarning", color.New(color.FgYellow, color.Bold))
+
+	// Get the alias parameter from cli
+	args := ctx.Args()
+	aliasedURL := args.Get(0)
+
+	// Create a new MinIO Admin Client
+	client, err := newAdminClient(aliasedURL)
+	fatalIf(err, "Unable to initialize admin connection.")
+
+	admInfo, e := client.ServerInfo(globalContext)
+	fatalIf(probe.NewError(e).Trace(args...), "Unable to get server information.")
+
+	pools := buildTopology(admInfo)
+	if len(pools) == 0 {
+		fatalIf(errDummy().Trace(args...), "Topology is only available for MinIO servers in erasure mode.")
+	}
+
+	printMsg(adminTopologyMessage{Pools: pools})
+	return nil
+}
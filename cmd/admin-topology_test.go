@@ -0,0 +1,124 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestBuildTopology(t *testing.T) {
+	disk := func(pool, set, index int, state string, healing bool) madmin.Disk {
+		return madmin.Disk{
+			PoolIndex: pool,
+			SetIndex:  set,
+			DiskIndex: index,
+			DrivePath: "/disk" + string(rune('0'+index)),
+			State:     state,
+			Healing:   healing,
+		}
+	}
+	info := madmin.InfoMessage{
+		Servers: []madmin.ServerProperties{
+			{
+				Endpoint: "server2:9000",
+				Disks: []madmin.Disk{
+					disk(1, 0, 1, madmin.DriveStateOk, false),
+					disk(0, 1, 1, madmin.DriveStateOffline, false),
+					disk(0, 0, 1, madmin.DriveStateOk, true),
+				},
+			},
+			{
+				Endpoint: "server1:9000",
+				Disks: []madmin.Disk{
+					disk(0, 1, 0, madmin.DriveStateOk, false),
+					disk(0, 0, 0, madmin.DriveStateOk, false),
+					disk(1, 0, 0, madmin.DriveStateOk, false),
+					// Not part of any erasure set yet.
+					disk(-1, -1, -1, madmin.DriveStateUnformatted, false),
+				},
+			},
+		},
+	}
+
+	drive := func(index int, endpoint, state string, healing bool) topologyDrive {
+		return topologyDrive{Index: index, Endpoint: endpoint, State: state, Healing: healing}
+	}
+	expected := []topologyPool{
+		{Index: 0, Sets: []topologySet{
+			{Index: 0, Online: 2, Healing: 1, Drives: []topologyDrive{
+				drive(0, "server1:9000/disk0", madmin.DriveStateOk, false),
+				drive(1, "server2:9000/disk1", madmin.DriveStateOk, true),
+			}},
+			{Index: 1, Online: 1, Offline: 1, Drives: []topologyDrive{
+				drive(0, "server1:9000/disk0", madmin.DriveStateOk, false),
+				drive(1, "server2:9000/disk1", madmin.DriveStateOffline, false),
+			}},
+		}},
+		{Index: 1, Sets: []topologySet{
+			{Index: 0, Online: 2, Drives: []topologyDrive{
+				drive(0, "server1:9000/disk0", madmin.DriveStateOk, false),
+				drive(1, "server2:9000/disk1", madmin.DriveStateOk, false),
+			}},
+		}},
+	}
+
+	pools := buildTopology(info)
+	if !reflect.DeepEqual(pools, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, pools)
+	}
+
+	// Servers in FS mode don't report any erasure set.
+	if pools = buildTopology(madmin.InfoMessage{Servers: []madmin.ServerProperties{{Disks: []madmin.Disk{disk(-1, -1, 0, madmin.DriveStateOk, false)}}}}); len(pools) != 0 {
+		t.Errorf("expected no pools, got %+v", pools)
+	}
+}
+
+func TestAdminTopologyMessageString(t *testing.T) {
+	msg := adminTopologyMessage{Pools: []topologyPool{
+		{Index: 0, Sets: []topologySet{
+			{Index: 0, Online: 1, Healing: 1, Drives: []topologyDrive{
+				{Index: 0, Endpoint: "http://server1/disk1", State: madmin.DriveStateOk, UsedSpace: 1 << 30, TotalSpace: 4 << 30},
+				{Index: 1, Endpoint: "http://server2/disk1", State: madmin.DriveStateOk, Healing: true},
+			}},
+			{Index: 1, Online: 0, Offline: 1, Drives: []topologyDrive{
+				{Index: 0, Endpoint: "http://server1/disk2", State: madmin.DriveStateOffline},
+			}},
+		}},
+		{Index: 1, Sets: []topologySet{
+			{Index: 0, Online: 1, Drives: []topologyDrive{
+				{Index: 0, Endpoint: "http://server3/disk1", State: madmin.DriveStateOk},
+			}},
+		}},
+	}}
+
+	expected := `Pool 1
+├─ Set 1: 1/2 drives online, 1 healing
+│  ├─ ● http://server1/disk1 ok (1.0 GiB/4.0 GiB used)
+│  └─ ● http://server2/disk1 healing
+└─ Set 2: 0/1 drives online
+   └─ ● http://server1/disk2 offline
+Pool 2
+└─ Set 1: 1/1 drives online
+   └─ ● http://server3/disk1 ok`
+	if got := msg.String(); got != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, got)
+	}
+}
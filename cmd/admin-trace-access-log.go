@@ -0,0 +1,138 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/madmin-go"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// accessLogTimeFormat is the time format of Apache access logs.
+const accessLogTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// accessLogMessage container for a request printed as an access log
+// line in the Apache combined log format.
+type accessLogMessage struct {
+	Status     string        `json:"status"`
+	RemoteHost string        `json:"remoteHost"`
+	User       string        `json:"user"`
+	Time       time.Time     `json:"time"`
+	Method     string        `json:"method"`
+	RequestURI string        `json:"requestURI"`
+	Proto      string        `json:"proto"`
+	StatusCode int           `json:"statusCode"`
+	Bytes      int           `json:"bytes"`
+	Referer    string        `json:"referer"`
+	UserAgent  string        `json:"userAgent"`
+	RequestID  string        `json:"requestID"`
+	Duration   time.Duration `json:"duration"`
+}
+
+func (a accessLogMessage) JSON() string {
+	a.Status = "success"
+	msgBytes, e := json.MarshalIndent(a, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+func (a accessLogMessage) String() string {
+	bytes := "-"
+	if a.Bytes > 0 {
+		bytes = fmt.Sprint(a.Bytes)
+	}
+	return fmt.Sprintf("%s - %s [%s] %q %d %s %q %q",
+		accessLogField(a.RemoteHost), accessLogField(a.User), a.Time.Local().Format(accessLogTimeFormat),
+		a.Method+" "+a.RequestURI+" "+a.Proto, a.StatusCode, bytes,
+		accessLogField(a.Referer), accessLogField(a.UserAgent))
+}
+
+// accessLogField returns "-" for unknown values, as access logs do.
+func accessLogField(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// accessKeyFromAuthorization returns the access key of a signed request.
+func accessKeyFromAuthorization(auth string) string {
+	i := strings.Index(auth, "Credential=")
+	if i < 0 {
+		// Signature V2: "AWS AccessKey:Signature"
+		if strings.HasPrefix(auth, "AWS ") {
+			return strings.SplitN(strings.TrimPrefix(auth, "AWS "), ":", 2)[0]
+		}
+		return ""
+	}
+	return strings.SplitN(auth[i+len("Credential="):], "/", 2)[0]
+}
+
+// newAccessLogMessage returns the access log line of a traced request.
+func newAccessLogMessage(t madmin.TraceInfo) accessLogMessage {
+	remoteHost := t.ReqInfo.Client
+	if host, _, e := net.SplitHostPort(remoteHost); e == nil {
+		remoteHost = host
+	}
+	requestURI := t.ReqInfo.Path
+	if t.ReqInfo.RawQuery != "" {
+		requestURI += "?" + t.ReqInfo.RawQuery
+	}
+	proto := t.ReqInfo.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	return accessLogMessage{
+		RemoteHost: remoteHost,
+		User:       accessKeyFromAuthorization(t.ReqInfo.Headers.Get("Authorization")),
+		Time:       t.ReqInfo.Time,
+		Method:     t.ReqInfo.Method,
+		RequestURI: requestURI,
+		Proto:      proto,
+		StatusCode: t.RespInfo.StatusCode,
+		Bytes:      t.CallStats.OutputBytes,
+		Referer:    t.ReqInfo.Headers.Get("Referer"),
+		UserAgent:  t.ReqInfo.Headers.Get("User-Agent"),
+		RequestID:  t.RespInfo.Headers.Get("X-Amz-Request-Id"),
+		Duration:   t.CallStats.Latency,
+	}
+}
+
+// matchTraceBucket returns true if the traced request targets one of the
+// buckets, addressed in the path or as a virtual host.
+func matchTraceBucket(buckets []string, t madmin.TraceInfo) bool {
+	if len(buckets) == 0 {
+		return true
+	}
+	host := t.ReqInfo.Headers.Get("Host")
+	for _, bucket := range buckets {
+		bucket = strings.Trim(bucket, "/")
+		if t.ReqInfo.Path == "/"+bucket || strings.HasPrefix(t.ReqInfo.Path, "/"+bucket+"/") {
+			return true
+		}
+		if strings.HasPrefix(host, bucket+".") {
+			return true
+		}
+	}
+	return false
+}
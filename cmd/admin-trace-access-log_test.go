@@ -0,0 +1,74 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestAccessLogMessage(t *testing.T) {
+	trace := madmin.TraceInfo{
+		TraceType: madmin.TraceHTTP,
+		FuncName:  "s3.GetObject",
+		ReqInfo: madmin.TraceRequestInfo{
+			Time:     time.Date(2021, 10, 1, 12, 30, 0, 0, time.UTC),
+			Proto:    "HTTP/1.1",
+			Method:   http.MethodGet,
+			Path:     "/photos/2021/summer.jpg",
+			RawQuery: "versionId=1",
+			Client:   "10.0.0.1:54321",
+			Headers: http.Header{
+				"Authorization": []string{"AWS4-HMAC-SHA256 Credential=myaccesskey/20211001/us-east-1/s3/aws4_request, SignedHeaders=host, Signature=abc"},
+				"User-Agent":    []string{"MinIO (linux; amd64)"},
+			},
+		},
+		RespInfo:  madmin.TraceResponseInfo{StatusCode: http.StatusOK},
+		CallStats: madmin.TraceCallStats{OutputBytes: 1024},
+	}
+
+	msg := newAccessLogMessage(trace)
+	line := msg.String()
+	for _, expected := range []string{
+		`10.0.0.1 - myaccesskey [`,
+		`"GET /photos/2021/summer.jpg?versionId=1 HTTP/1.1" 200 1024 "-" "MinIO (linux; amd64)"`,
+	} {
+		if !strings.Contains(line, expected) {
+			t.Errorf("Expected %q in access log line %q", expected, line)
+		}
+	}
+
+	testCases := []struct {
+		buckets []string
+		match   bool
+	}{
+		{nil, true},
+		{[]string{"photos"}, true},
+		{[]string{"photo"}, false},
+		{[]string{"videos", "photos/"}, true},
+	}
+	for i, testCase := range testCases {
+		if match := matchTraceBucket(testCase.buckets, trace); match != testCase.match {
+			t.Errorf("Test %d: expected match %v, got %v", i+1, testCase.match, match)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/madmin-go"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// callFlowMaxAge is how long calls are kept waiting for the request
+// they were made for, requests are traced once completed.
+const callFlowMaxAge = time.Minute
+
+// callFlowCall is a call made while serving a request.
+type callFlowCall struct {
+	Type       madmin.TraceType `json:"type"`
+	Host       string           `json:"host"`
+	FuncName   string           `json:"api"`
+	Path       string           `json:"path"`
+	Offset     time.Duration    `json:"offset"`
+	Duration   time.Duration    `json:"duration"`
+	StatusCode int              `json:"statusCode,omitempty"`
+}
+
+// callFlowMessage container for a request and the calls made to serve it.
+type callFlowMessage struct {
+	Status     string         `json:"status"`
+	RequestID  string         `json:"requestID"`
+	Host       string         `json:"host"`
+	Time       time.Time      `json:"time"`
+	FuncName   string         `json:"api"`
+	Method     string         `json:"method"`
+	Path       string         `json:"path"`
+	StatusCode int            `json:"statusCode"`
+	Duration   time.Duration  `json:"duration"`
+	Calls      []callFlowCall `json:"calls"`
+}
+
+func (c callFlowMessage) JSON() string {
+	c.Status = "success"
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetIndent("", " ")
+	// Disable escaping special chars to display XML tags correctly
+	enc.SetEscapeHTML(false)
+
+	fatalIf(probe.NewError(enc.Encode(c)), "Unable to marshal into JSON.")
+	return buf.String()
+}
+
+func (c callFlowMessage) String() string {
+	var b = &strings.Builder{}
+
+	statusStr := fmt.Sprintf("%d %s", c.StatusCode, http.StatusText(c.StatusCode))
+	if c.StatusCode >= http.StatusBadRequest {
+		statusStr = console.Colorize("ErrStatus", statusStr)
+	} else {
+		statusStr = console.Colorize("RespStatus", statusStr)
+	}
+	fmt.Fprintf(b, "%s [%s] %s %s %s %s %s", c.Time.Local().Format(timeFormat), statusStr,
+		console.Colorize("FuncName", c.FuncName), colorizedNodeName(c.Host), c.Path,
+		console.Colorize("HeaderValue", c.Duration.Round(time.Microsecond)),
+		console.Colorize("Request", c.RequestID))
+
+	for _, call := range c.Calls {
+		callType := "INTERNAL"
+		switch call.Type {
+		case madmin.TraceStorage:
+			callType = "STORAGE"
+		case madmin.TraceOS:
+			callType = "OS"
+		}
+		fmt.Fprintf(b, "\n  %10s %10s  [%s] %s %s %s",
+			"+"+call.Offset.Round(time.Microsecond).String(),
+			console.Colorize("HeaderValue", call.Duration.Round(time.Microsecond)),
+			console.Colorize("RespStatus", callType), console.Colorize("FuncName", call.FuncName),
+			colorizedNodeName(call.Host), call.Path)
+		if call.StatusCode >= http.StatusBadRequest {
+			fmt.Fprintf(b, " %s", console.Colorize("ErrStatus", fmt.Sprintf("%d %s", call.StatusCode, http.StatusText(call.StatusCode))))
+		}
+	}
+	return b.String()
+}
+
+// isCallFlowRequest returns true for the traces of client requests,
+// other traces are calls made to serve them.
+func isCallFlowRequest(t madmin.TraceInfo) bool {
+	return t.TraceType == madmin.TraceHTTP && (strings.HasPrefix(t.FuncName, "s3.") || strings.HasPrefix(t.FuncName, "admin."))
+}
+
+// callFlowSpan returns when a traced call started, how long it took
+// and the path it accessed.
+func callFlowSpan(t madmin.TraceInfo) (start time.Time, duration time.Duration, path string) {
+	switch t.TraceType {
+	case madmin.TraceStorage:
+		return t.Time, t.StorageStats.Duration, t.StorageStats.Path
+	case madmin.TraceOS:
+		return t.Time, t.OSStats.Duration, t.OSStats.Path
+	}
+	path = t.ReqInfo.Path
+	if query, e := url.QueryUnescape(t.ReqInfo.RawQuery); e == nil && query != "" {
+		path += "?" + query
+	}
+	return t.ReqInfo.Time, t.CallStats.Latency, path
+}
+
+// callFlowKey returns the key a call is matched with the request it was
+// made for: the ID of the request when the call carries it, else the
+// name of the object it accesses, e.g. object for the storage call of
+// /disk1/bucket/object/xl.meta or /disk1/bucket/object/<data-dir>/part.1.
+func callFlowKey(t madmin.TraceInfo) (key string, byID bool) {
+	if id := t.ReqInfo.Headers.Get("X-Amz-Request-Id"); id != "" && t.TraceType == madmin.TraceHTTP {
+		return id, true
+	}
+	_, _, p := callFlowSpan(t)
+	segments := strings.Split(strings.Trim(p, "/"), "/")
+	i := len(segments) - 2
+	if i >= 1 && strings.HasPrefix(segments[len(segments)-1], "part.") {
+		i--
+	}
+	if i < 0 {
+		return "", false
+	}
+	return segments[i], false
+}
+
+// callFlowPending is a call waiting for the request it was made for.
+type callFlowPending struct {
+	seq   uint64
+	trace madmin.TraceInfo
+}
+
+// callFlowQueued orders the pending calls by arrival to evict them.
+type callFlowQueued struct {
+	seq   uint64
+	key   string
+	byID  bool
+	start time.Time
+}
+
+// callFlowTracker groups traced calls by the request they were made for,
+// calls carrying the request ID are matched by ID. Disk and OS calls
+// carry no request ID, they are matched by the object they access and
+// by time.
+type callFlowTracker struct {
+	threshold  time.Duration
+	onlyErrors bool
+
+	seq       uint64
+	byID      map[string][]callFlowPending
+	byObject  map[string][]callFlowPending
+	queue     []callFlowQueued
+	evictedAt time.Time
+}
+
+// pendingCalls returns the map holding the calls of the kind.
+func (c *callFlowTracker) pendingCalls(byID bool) map[string][]callFlowPending {
+	if c.byID == nil {
+		c.byID = make(map[string][]callFlowPending)
+		c.byObject = make(map[string][]callFlowPending)
+	}
+	if byID {
+		return c.byID
+	}
+	return c.byObject
+}
+
+// evict drops the calls no request was found for within callFlowMaxAge.
+func (c *callFlowTracker) evict(now time.Time) {
+	i := 0
+	for ; i < len(c.queue) && c.queue[i].start.Add(callFlowMaxAge).Before(now); i++ {
+		q := c.queue[i]
+		calls := c.pendingCalls(q.byID)
+		// Calls of a key are queued in order, matched ones are gone.
+		if pending := calls[q.key]; len(pending) > 0 && pending[0].seq == q.seq {
+			if len(pending) == 1 {
+				delete(calls, q.key)
+			} else {
+				calls[q.key] = pending[1:]
+			}
+		}
+	}
+	c.queue = c.queue[i:]
+}
+
+// add records a trace, and returns the call flow of a completed request
+// when it is slower than the threshold, or failed if only errors are traced.
+func (c *callFlowTracker) add(t madmin.TraceInfo) *callFlowMessage {
+	if !isCallFlowRequest(t) {
+		key, byID := callFlowKey(t)
+		if key == "" {
+			return nil
+		}
+		c.seq++
+		calls := c.pendingCalls(byID)
+		calls[key] = append(calls[key], callFlowPending{seq: c.seq, trace: t})
+		start, _, _ := callFlowSpan(t)
+		c.queue = append(c.queue, callFlowQueued{seq: c.seq, key: key, byID: byID, start: start})
+		return nil
+	}
+
+	start, duration, _ := callFlowSpan(t)
+	end := start.Add(duration)
+	if start.Sub(c.evictedAt) > callFlowMaxAge {
+		c.evict(start)
+		c.evictedAt = start
+	}
+	// Disk calls are matched by their path, e.g. /disk1/bucket/object/xl.meta
+	resource := strings.Trim(t.ReqInfo.Path, "/")
+
+	msg := &callFlowMessage{
+		RequestID:  t.RespInfo.Headers.Get("X-Amz-Request-Id"),
+		Host:       t.NodeName,
+		Time:       start,
+		FuncName:   t.FuncName,
+		Method:     t.ReqInfo.Method,
+		Path:       t.ReqInfo.Path,
+		StatusCode: t.RespInfo.StatusCode,
+		Duration:   duration,
+	}
+
+	addCall := func(p madmin.TraceInfo) {
+		pStart, pDuration, pPath := callFlowSpan(p)
+		msg.Calls = append(msg.Calls, callFlowCall{
+			Type:       p.TraceType,
+			Host:       p.NodeName,
+			FuncName:   p.FuncName,
+			Path:       pPath,
+			Offset:     pStart.Sub(start),
+			Duration:   pDuration,
+			StatusCode: p.RespInfo.StatusCode,
+		})
+	}
+	calls := c.pendingCalls(true)
+	if msg.RequestID != "" {
+		for _, p := range calls[msg.RequestID] {
+			addCall(p.trace)
+		}
+		delete(calls, msg.RequestID)
+	}
+	if object := path.Base(resource); resource != "" {
+		calls = c.pendingCalls(false)
+		var pending []callFlowPending
+		for _, p := range calls[object] {
+			pStart, _, pPath := callFlowSpan(p.trace)
+			inSpan := !pStart.Before(start) && !pStart.After(end) &&
+				(strings.Contains(pPath, "/"+resource+"/") || strings.HasSuffix(pPath, "/"+resource))
+			if !inSpan {
+				pending = append(pending, p)
+				continue
+			}
+			addCall(p.trace)
+		}
+		if len(pending) > 0 {
+			calls[object] = pending
+		} else {
+			delete(calls, object)
+		}
+	}
+
+	if duration < c.threshold || (c.onlyErrors && msg.StatusCode < http.StatusBadRequest) {
+		return nil
+	}
+	sort.SliceStable(msg.Calls, func(i, j int) bool {
+		return msg.Calls[i].Offset < msg.Calls[j].Offset
+	})
+	return msg
+}
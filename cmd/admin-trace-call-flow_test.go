@@ -0,0 +1,140 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minio/madmin-go"
+)
+
+func TestCallFlowTracker(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	storage := func(offset time.Duration, path string) madmin.TraceInfo {
+		return madmin.TraceInfo{
+			TraceType:    madmin.TraceStorage,
+			FuncName:     "storage.ReadVersion",
+			Time:         start.Add(offset),
+			StorageStats: madmin.TraceStorageStats{Path: path, Duration: time.Millisecond},
+		}
+	}
+	request := madmin.TraceInfo{
+		TraceType: madmin.TraceHTTP,
+		FuncName:  "s3.GetObject",
+		ReqInfo:   madmin.TraceRequestInfo{Time: start, Method: http.MethodGet, Path: "/bucket/object"},
+		RespInfo:  madmin.TraceResponseInfo{StatusCode: http.StatusOK},
+		CallStats: madmin.TraceCallStats{Latency: 10 * time.Millisecond},
+	}
+
+	c := &callFlowTracker{}
+	for _, trace := range []madmin.TraceInfo{
+		storage(2*time.Millisecond, "/disk2/bucket/object/xl.meta"),
+		storage(time.Millisecond, "/disk1/bucket/object/xl.meta"),
+		storage(time.Millisecond, "/disk1/bucket/other/xl.meta"),
+		storage(20*time.Millisecond, "/disk1/bucket/object/xl.meta"),
+	} {
+		if msg := c.add(trace); msg != nil {
+			t.Fatalf("Unexpected call flow for a storage call: %v", msg)
+		}
+	}
+
+	msg := c.add(request)
+	if msg == nil {
+		t.Fatal("Expected a call flow for the request")
+	}
+	if len(msg.Calls) != 2 {
+		t.Fatalf("Expected 2 calls, got %d", len(msg.Calls))
+	}
+	if msg.Calls[0].Path != "/disk1/bucket/object/xl.meta" || msg.Calls[0].Offset != time.Millisecond {
+		t.Errorf("Calls are not sorted by time: %v", msg.Calls)
+	}
+	if pending := len(c.byObject["object"]) + len(c.byObject["other"]); pending != 2 {
+		t.Errorf("Expected 2 pending calls, got %d", pending)
+	}
+
+	// Calls no request was found for are evicted.
+	later := request
+	later.ReqInfo.Time = start.Add(2 * callFlowMaxAge)
+	later.ReqInfo.Path = "/bucket/later"
+	c.add(later)
+	if len(c.byObject) != 0 || len(c.queue) != 0 {
+		t.Errorf("Expected the pending calls to be evicted, got %v", c.byObject)
+	}
+
+	c = &callFlowTracker{threshold: time.Second}
+	if msg := c.add(request); msg != nil {
+		t.Errorf("Expected requests faster than the threshold to be skipped")
+	}
+}
+
+func TestCallFlowTrackerRequestID(t *testing.T) {
+	start := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	internal := func(requestID string, offset time.Duration) madmin.TraceInfo {
+		return madmin.TraceInfo{
+			TraceType: madmin.TraceHTTP,
+			FuncName:  "peer.LoadBucketMetadata",
+			ReqInfo: madmin.TraceRequestInfo{
+				Time:    start.Add(offset),
+				Path:    "/minio/peer/v21/loadbucketmetadata",
+				Headers: http.Header{"X-Amz-Request-Id": []string{requestID}},
+			},
+			CallStats: madmin.TraceCallStats{Latency: time.Millisecond},
+		}
+	}
+	request := madmin.TraceInfo{
+		TraceType: madmin.TraceHTTP,
+		FuncName:  "s3.PutBucketPolicy",
+		ReqInfo:   madmin.TraceRequestInfo{Time: start, Method: http.MethodPut, Path: "/bucket"},
+		RespInfo: madmin.TraceResponseInfo{
+			StatusCode: http.StatusNoContent,
+			Headers:    http.Header{"X-Amz-Request-Id": []string{"16A8F2F0D1E2B3C4"}},
+		},
+		CallStats: madmin.TraceCallStats{Latency: 10 * time.Millisecond},
+	}
+
+	c := &callFlowTracker{}
+	// Calls are matched by request ID, even outside of the request span.
+	c.add(internal("16A8F2F0D1E2B3C4", 20*time.Millisecond))
+	c.add(internal("16A8F2F0D1E2B3C5", time.Millisecond))
+	msg := c.add(request)
+	if msg == nil || len(msg.Calls) != 1 || msg.Calls[0].Offset != 20*time.Millisecond {
+		t.Fatalf("Expected the call of the request, got %v", msg)
+	}
+	if _, ok := c.byID["16A8F2F0D1E2B3C4"]; ok || len(c.byID["16A8F2F0D1E2B3C5"]) != 1 {
+		t.Errorf("Expected only the call of the other request to be pending, got %v", c.byID)
+	}
+}
+
+func TestCallFlowKey(t *testing.T) {
+	testCases := []struct {
+		path string
+		key  string
+	}{
+		{"/disk1/bucket/object/xl.meta", "object"},
+		{"/disk1/bucket/prefix/object/2b5c6f1e-1d55-4c5b-9c1f-3c3fa7a4f0b1/part.1", "object"},
+		{"xl.meta", ""},
+	}
+	for i, testCase := range testCases {
+		trace := madmin.TraceInfo{TraceType: madmin.TraceStorage, StorageStats: madmin.TraceStorageStats{Path: testCase.path}}
+		if key, byID := callFlowKey(trace); key != testCase.key || byID {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.key, key)
+		}
+	}
+}
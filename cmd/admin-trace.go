@@ -74,6 +74,18 @@ var adminTraceFlags = []cli.Flag{
 		Name:  "errors, e",
 		Usage: "trace only failed requests",
 	},
+	cli.BoolFlag{
+		Name:  "call-flow",
+		Usage: "print each request with the internal, storage and OS calls made to serve it",
+	},
+	cli.StringSliceFlag{
+		Name:  "bucket",
+		Usage: "trace only requests to matching bucket",
+	},
+	cli.BoolFlag{
+		Name:  "access-log-format",
+		Usage: "print S3 requests as access log lines in the Apache combined log format",
+	},
 }
 
 var adminTraceCmd = cli.Command{
@@ -108,6 +120,12 @@ EXAMPLES:
 
   5. Show console trace for requests with '404' and '503' status code
     {{.Prompt}} {{.HelpName}} --status-code 404 --status-code 503 myminio
+
+  6. Show where slow PUT requests spend their time, with the calls made to serve each request
+    {{.Prompt}} {{.HelpName}} --call-flow --method PUT --response-threshold 100ms myminio
+
+  7. Show S3 requests to the bucket 'photos' as access log lines, appended to a file read by log analysis tools
+    {{.Prompt}} {{.HelpName}} --bucket photos --access-log-format myminio >> /var/log/minio/photos-access.log
 `,
 }
 
@@ -121,6 +139,9 @@ func checkAdminTraceSyntax(ctx *cli.Context) {
 	if len(ctx.Args()) != 1 {
 		cli.ShowCommandHelpAndExit(ctx, "trace", 1) // last argument is exit code
 	}
+	if ctx.Bool("access-log-format") && ctx.Bool("call-flow") {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "`--access-log-format` cannot be used with `--call-flow`.")
+	}
 }
 
 func printTrace(verbose bool, traceInfo madmin.ServiceTraceInfo) {
@@ -132,6 +153,10 @@ func printTrace(verbose bool, traceInfo madmin.ServiceTraceInfo) {
 }
 
 func matchTrace(ctx *cli.Context, traceInfo madmin.ServiceTraceInfo) bool {
+	if !matchTraceBucket(ctx.StringSlice("bucket"), traceInfo.Trace) {
+		return false
+	}
+
 	statusCodes := ctx.IntSlice("status-code")
 	methods := ctx.StringSlice("method")
 	funcNames := ctx.StringSlice("funcname")
@@ -216,7 +241,13 @@ func tracingOpts(ctx *cli.Context) (opts madmin.ServiceTraceOpts, e error) {
 
 	opts.OnlyErrors = ctx.Bool("errors")
 
-	if ctx.Bool("all") {
+	if ctx.Bool("access-log-format") {
+		// Access logs only show client requests.
+		opts.S3 = true
+		return
+	}
+
+	if ctx.Bool("all") || ctx.Bool("call-flow") {
 		opts.All = true // Deprecated
 
 		opts.S3 = true
@@ -289,12 +320,32 @@ func mainAdminTrace(ctx *cli.Context) error {
 	opts, e := tracingOpts(ctx)
 	fatalIf(probe.NewError(e), "Unable to start tracing")
 
+	var callFlow *callFlowTracker
+	if ctx.Bool("call-flow") {
+		// Threshold and errors only apply to requests, the server
+		// must send all the calls made to serve them.
+		callFlow = &callFlowTracker{threshold: opts.Threshold, onlyErrors: opts.OnlyErrors}
+		opts.Threshold, opts.OnlyErrors = 0, false
+	}
+
 	// Start listening on all trace activity.
 	traceCh := client.ServiceTrace(ctxt, opts)
 	for traceInfo := range traceCh {
 		if traceInfo.Err != nil {
 			fatalIf(probe.NewError(traceInfo.Err), "Unable to listen to http trace")
 		}
+		if ctx.Bool("access-log-format") {
+			if matchTrace(ctx, traceInfo) {
+				printMsg(newAccessLogMessage(traceInfo.Trace))
+			}
+			continue
+		}
+		if callFlow != nil {
+			if msg := callFlow.add(traceInfo.Trace); msg != nil && matchTrace(ctx, traceInfo) {
+				printMsg(msg)
+			}
+			continue
+		}
 		if matchTrace(ctx, traceInfo) {
 			printTrace(verbose, traceInfo)
 		}
@@ -0,0 +1,294 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/signer"
+)
+
+// aliasEndpointConfig is an additional endpoint of an alias, serving
+// the same data as the alias URL, e.g. another site of a cluster.
+type aliasEndpointConfig struct {
+	URL    string `json:"url"`
+	Weight int    `json:"weight,omitempty"`
+}
+
+const (
+	// Send requests to the alias URL, then to the healthy endpoint
+	// with the highest weight when it is down.
+	aliasEndpointFailover = "failover"
+	// Spread reads across all healthy endpoints in proportion to
+	// their weights, writes being sent as with failover.
+	aliasEndpointRoundRobin = "round-robin"
+)
+
+// aliasEndpointHealthTimeout bounds the health check of an endpoint.
+const aliasEndpointHealthTimeout = 3 * time.Second
+
+func isValidEndpointPolicy(policy string) bool {
+	switch policy {
+	case "", aliasEndpointFailover, aliasEndpointRoundRobin:
+		return true
+	}
+	return false
+}
+
+// parseAliasEndpoint parses an endpoint of the form URL[?weight=N].
+func parseAliasEndpoint(s string) (aliasEndpointConfig, *probe.Error) {
+	u, e := url.Parse(s)
+	if e != nil {
+		return aliasEndpointConfig{}, probe.NewError(e).Trace(s)
+	}
+	endpoint := aliasEndpointConfig{Weight: 1}
+	if w := u.Query().Get("weight"); w != "" {
+		endpoint.Weight, e = strconv.Atoi(w)
+		if e != nil || endpoint.Weight < 1 {
+			return aliasEndpointConfig{}, errInvalidArgument().Trace(s)
+		}
+	}
+	u.RawQuery = ""
+	endpoint.URL = trimTrailingSeparator(u.String())
+	if !isValidHostURL(endpoint.URL) {
+		return aliasEndpointConfig{}, errInvalidURL(s).Trace(s)
+	}
+	return endpoint, nil
+}
+
+// aliasEndpoints returns all endpoints of an alias, the alias URL first
+// and the others by decreasing weight.
+func aliasEndpoints(aliasCfg *aliasConfigV10) []aliasEndpointConfig {
+	endpoints := append([]aliasEndpointConfig{}, aliasCfg.Endpoints...)
+	for i := range endpoints {
+		if endpoints[i].Weight < 1 {
+			endpoints[i].Weight = 1
+		}
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i].Weight > endpoints[j].Weight
+	})
+	return append([]aliasEndpointConfig{{URL: aliasCfg.URL, Weight: 1}}, endpoints...)
+}
+
+// aliasEndpointURLs lists the other endpoints of an alias for display.
+func aliasEndpointURLs(aliasCfg aliasConfigV10) []string {
+	var urls []string
+	for _, endpoint := range aliasCfg.Endpoints {
+		u := endpoint.URL
+		if endpoint.Weight > 1 {
+			u += "?weight=" + strconv.Itoa(endpoint.Weight)
+		}
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// isAliasEndpointHealthy returns true if the endpoint answers the
+// MinIO liveness check with 200 OK.
+var isAliasEndpointHealthy = func(ctx context.Context, endpoint string) bool {
+	ctx, cancel := context.WithTimeout(ctx, aliasEndpointHealthTimeout)
+	defer cancel()
+
+	req, e := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/minio/health/live", nil)
+	if e != nil {
+		return false
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy:       http.ProxyFromEnvironment,
+			DialContext: newCustomDialContext(10*time.Second, 15*time.Second),
+			TLSClientConfig: &tls.Config{
+				RootCAs:            globalRootCAs,
+				InsecureSkipVerify: globalInsecure,
+				MinVersion:         tls.VersionTLS12,
+			},
+		},
+	}
+	resp, e := client.Do(req)
+	if e != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+// aliasEndpointSet keeps the health of the endpoints of an alias for
+// all its clients, endpoints are checked once and then marked down
+// when a request sent to them fails.
+type aliasEndpointSet struct {
+	policy    string
+	endpoints []aliasEndpointConfig
+	hosts     []string
+
+	mu   sync.Mutex
+	down []bool
+}
+
+func newAliasEndpointSet(ctx context.Context, aliasCfg *aliasConfigV10) *aliasEndpointSet {
+	set := &aliasEndpointSet{
+		policy:    aliasCfg.EndpointPolicy,
+		endpoints: aliasEndpoints(aliasCfg),
+	}
+	set.hosts = make([]string, len(set.endpoints))
+	set.down = make([]bool, len(set.endpoints))
+	var wg sync.WaitGroup
+	for i := range set.endpoints {
+		if u, e := url.Parse(set.endpoints[i].URL); e == nil {
+			set.hosts[i] = u.Host
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			set.down[i] = !isAliasEndpointHealthy(ctx, set.endpoints[i].URL)
+		}(i)
+	}
+	wg.Wait()
+	return set
+}
+
+// pick returns the index of the endpoint a request is sent to, reads
+// being spread across the healthy endpoints in proportion to their
+// weights with the round-robin policy. Other requests are sent to the
+// first healthy endpoint, the alias URL when none is.
+func (s *aliasEndpointSet) pick(isRead bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if isRead && s.policy == aliasEndpointRoundRobin {
+		var total int
+		for i, endpoint := range s.endpoints {
+			if !s.down[i] {
+				total += endpoint.Weight
+			}
+		}
+		if total > 0 {
+			n := rand.Intn(total)
+			for i, endpoint := range s.endpoints {
+				if s.down[i] {
+					continue
+				}
+				if n < endpoint.Weight {
+					return i
+				}
+				n -= endpoint.Weight
+			}
+		}
+	}
+	for i := range s.endpoints {
+		if !s.down[i] {
+			return i
+		}
+	}
+	return 0
+}
+
+// markDown stops sending requests to an endpoint.
+func (s *aliasEndpointSet) markDown(i int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down[i] = true
+}
+
+// indexOf returns the index of the endpoint of a host, -1 if none.
+func (s *aliasEndpointSet) indexOf(host string) int {
+	for i, h := range s.hosts {
+		if h == host {
+			return i
+		}
+	}
+	return -1
+}
+
+// Endpoint sets of the aliases, health checks run once per alias.
+var aliasEndpointCache = struct {
+	sync.Mutex
+	sets map[string]*aliasEndpointSet
+}{sets: make(map[string]*aliasEndpointSet)}
+
+// getAliasEndpointSet returns the endpoint set of an alias.
+func getAliasEndpointSet(ctx context.Context, alias string, aliasCfg *aliasConfigV10) *aliasEndpointSet {
+	aliasEndpointCache.Lock()
+	defer aliasEndpointCache.Unlock()
+	set, ok := aliasEndpointCache.sets[alias]
+	if !ok {
+		set = newAliasEndpointSet(ctx, aliasCfg)
+		aliasEndpointCache.sets[alias] = set
+	}
+	return set
+}
+
+// selectAliasEndpoint returns the endpoint requests to the alias are
+// sent to first, the alias URL when none of the endpoints is healthy.
+func selectAliasEndpoint(ctx context.Context, alias string, aliasCfg *aliasConfigV10) string {
+	if len(aliasCfg.Endpoints) == 0 {
+		return aliasCfg.URL
+	}
+	set := getAliasEndpointSet(ctx, alias, aliasCfg)
+	return set.endpoints[set.pick(false)].URL
+}
+
+// aliasEndpointTransport sends the requests of a client to the
+// endpoints of its alias, re-signing those sent to another host than
+// the client one. An endpoint failing to connect or answering with a
+// server error is marked down, the retries of the client being sent
+// to the next one.
+type aliasEndpointTransport struct {
+	http.RoundTripper
+	endpoints *aliasEndpointSet
+	creds     *credentials.Credentials
+}
+
+func (t aliasEndpointTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	isRead := req.Method == http.MethodGet || req.Method == http.MethodHead
+	target := t.endpoints.indexOf(req.URL.Host)
+	// Signed streaming uploads cannot be sent elsewhere, their chunks
+	// are signed from the signature of the headers.
+	if i := t.endpoints.pick(isRead); target >= 0 && i != target && req.Header.Get("X-Amz-Content-Sha256") != streamingSignV4Payload {
+		endpoint, e := url.Parse(t.endpoints.endpoints[i].URL)
+		if e != nil {
+			return nil, e
+		}
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host = endpoint.Scheme, endpoint.Host
+		req.Host = endpoint.Host
+		if region, ok := signatureV4Region(req.Header.Get("Authorization")); ok {
+			value, e := t.creds.Get()
+			if e != nil {
+				return nil, e
+			}
+			req = signer.SignV4(*req, value.AccessKeyID, value.SecretAccessKey, value.SessionToken, region)
+		}
+		target = i
+	}
+	resp, e := t.RoundTripper.RoundTrip(req)
+	if target >= 0 && req.Context().Err() == nil && (e != nil || resp.StatusCode >= http.StatusInternalServerError) {
+		t.endpoints.markDown(target)
+	}
+	return resp, e
+}
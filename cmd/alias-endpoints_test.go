@@ -0,0 +1,186 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseAliasEndpoint(t *testing.T) {
+	testCases := []struct {
+		endpoint  string
+		url       string
+		weight    int
+		expectErr bool
+	}{
+		{"https://site-b:9000", "https://site-b:9000", 1, false},
+		{"https://site-b:9000/", "https://site-b:9000", 1, false},
+		{"https://site-b:9000?weight=3", "https://site-b:9000", 3, false},
+		{"https://site-b:9000?weight=0", "", 0, true},
+		{"https://site-b:9000?weight=heavy", "", 0, true},
+		{"site-b:9000", "", 0, true},
+	}
+
+	for i, testCase := range testCases {
+		endpoint, err := parseAliasEndpoint(testCase.endpoint)
+		if testCase.expectErr != (err != nil) {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.expectErr, err)
+		}
+		if endpoint.URL != testCase.url || endpoint.Weight != testCase.weight {
+			t.Fatalf("Test %d: expected %s with weight %d, got %s with weight %d",
+				i+1, testCase.url, testCase.weight, endpoint.URL, endpoint.Weight)
+		}
+	}
+}
+
+func TestSelectAliasEndpoint(t *testing.T) {
+	defer func(healthy func(context.Context, string) bool) {
+		isAliasEndpointHealthy = healthy
+	}(isAliasEndpointHealthy)
+
+	up := map[string]bool{"https://site-b:9000": true, "https://site-c:9000": true}
+	isAliasEndpointHealthy = func(_ context.Context, endpoint string) bool {
+		return up[endpoint]
+	}
+
+	aliasCfg := &aliasConfigV10{
+		URL: "https://site-a:9000",
+		Endpoints: []aliasEndpointConfig{
+			{URL: "https://site-b:9000"},
+			{URL: "https://site-c:9000", Weight: 2},
+		},
+	}
+	// site-a is down, site-c has the highest weight.
+	if u := selectAliasEndpoint(context.Background(), "failover-test", aliasCfg); u != "https://site-c:9000" {
+		t.Fatalf("expected https://site-c:9000, got %s", u)
+	}
+
+	// The selection is kept for the next clients of the alias.
+	up["https://site-a:9000"] = true
+	if u := selectAliasEndpoint(context.Background(), "failover-test", aliasCfg); u != "https://site-c:9000" {
+		t.Fatalf("expected https://site-c:9000 to be kept, got %s", u)
+	}
+
+	// Round-robin only picks healthy endpoints.
+	aliasCfg.EndpointPolicy = aliasEndpointRoundRobin
+	up = map[string]bool{"https://site-b:9000": true}
+	if u := selectAliasEndpoint(context.Background(), "round-robin-test", aliasCfg); u != "https://site-b:9000" {
+		t.Fatalf("expected https://site-b:9000, got %s", u)
+	}
+
+	// Fall back to the alias URL when no endpoint answers.
+	up = map[string]bool{}
+	if u := selectAliasEndpoint(context.Background(), "down-test", aliasCfg); u != "https://site-a:9000" {
+		t.Fatalf("expected https://site-a:9000, got %s", u)
+	}
+}
+
+func TestIsAliasEndpointHealthy(t *testing.T) {
+	testCases := []struct {
+		status   int
+		expected bool
+	}{
+		{http.StatusOK, true},
+		{http.StatusForbidden, false},
+		{http.StatusNotFound, false},
+		{http.StatusServiceUnavailable, false},
+	}
+	for i, testCase := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/minio/health/live" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(testCase.status)
+		}))
+		if healthy := isAliasEndpointHealthy(context.Background(), server.URL); healthy != testCase.expected {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, healthy)
+		}
+		server.Close()
+	}
+}
+
+func TestAliasEndpointSetPick(t *testing.T) {
+	set := &aliasEndpointSet{
+		policy: aliasEndpointRoundRobin,
+		endpoints: []aliasEndpointConfig{
+			{URL: "https://site-a:9000", Weight: 1},
+			{URL: "https://site-b:9000", Weight: 1},
+		},
+		down: []bool{false, false},
+	}
+	picked := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		picked[set.pick(true)] = true
+		// Writes are not spread.
+		if j := set.pick(false); j != 0 {
+			t.Fatalf("expected writes to be sent to the first endpoint, got %d", j)
+		}
+	}
+	if !picked[0] || !picked[1] {
+		t.Fatalf("expected reads to be spread across both endpoints, got %v", picked)
+	}
+
+	set.markDown(0)
+	if i, j := set.pick(true), set.pick(false); i != 1 || j != 1 {
+		t.Fatalf("expected the healthy endpoint, got %d and %d", i, j)
+	}
+	set.markDown(1)
+	if i := set.pick(false); i != 0 {
+		t.Fatalf("expected the alias URL when all endpoints are down, got %d", i)
+	}
+}
+
+func TestAliasEndpointTransport(t *testing.T) {
+	var failing, healthy int
+	siteA := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failing++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer siteA.Close()
+	siteB := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		healthy++
+	}))
+	defer siteB.Close()
+
+	set := &aliasEndpointSet{
+		policy:    aliasEndpointFailover,
+		endpoints: []aliasEndpointConfig{{URL: siteA.URL, Weight: 1}, {URL: siteB.URL, Weight: 1}},
+		hosts:     []string{siteA.Listener.Addr().String(), siteB.Listener.Addr().String()},
+		down:      []bool{false, false},
+	}
+	client := &http.Client{Transport: aliasEndpointTransport{RoundTripper: http.DefaultTransport, endpoints: set}}
+
+	// The first request fails over site-a, its retry is sent to site-b.
+	for i, expected := range []int{http.StatusServiceUnavailable, http.StatusOK, http.StatusOK} {
+		resp, e := client.Get(siteA.URL + "/bucket/object")
+		if e != nil {
+			t.Fatal(e)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expected {
+			t.Fatalf("Request %d: expected status %d, got %d", i+1, expected, resp.StatusCode)
+		}
+	}
+	if failing != 1 || healthy != 2 {
+		t.Fatalf("expected 1 request to site-a and 2 to site-b, got %d and %d", failing, healthy)
+	}
+}
@@ -130,6 +130,9 @@ func listAliases(alias string, deprecated bool) (aliases []aliasMessage) {
 				AccessKey:   v.AccessKey,
 				SecretKey:   v.SecretKey,
 				API:         v.API,
+				Region:      v.Region,
+				Endpoints:   aliasEndpointURLs(v),
+				Policy:      v.EndpointPolicy,
 			}
 
 			if deprecated {
@@ -151,6 +154,9 @@ func listAliases(alias string, deprecated bool) (aliases []aliasMessage) {
 			AccessKey:   v.AccessKey,
 			SecretKey:   v.SecretKey,
 			API:         v.API,
+			Region:      v.Region,
+			Endpoints:   aliasEndpointURLs(v),
+			Policy:      v.EndpointPolicy,
 		}
 
 		if deprecated {
@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
 	"github.com/minio/mc/pkg/probe"
@@ -66,13 +68,16 @@ func mainAlias(ctx *cli.Context) error {
 type aliasMessage struct {
 	op          string
 	prettyPrint bool
-	Status      string `json:"status"`
-	Alias       string `json:"alias"`
-	URL         string `json:"URL"`
-	AccessKey   string `json:"accessKey,omitempty"`
-	SecretKey   string `json:"secretKey,omitempty"`
-	API         string `json:"api,omitempty"`
-	Path        string `json:"path,omitempty"`
+	Status      string   `json:"status"`
+	Alias       string   `json:"alias"`
+	URL         string   `json:"URL"`
+	AccessKey   string   `json:"accessKey,omitempty"`
+	SecretKey   string   `json:"secretKey,omitempty"`
+	API         string   `json:"api,omitempty"`
+	Path        string   `json:"path,omitempty"`
+	Region      string   `json:"region,omitempty"`
+	Endpoints   []string `json:"endpoints,omitempty"`
+	Policy      string   `json:"endpointPolicy,omitempty"`
 	// Deprecated field, replaced by Path
 	Lookup string `json:"lookup,omitempty"`
 }
@@ -83,21 +88,34 @@ type aliasMessage struct {
 func (h aliasMessage) String() string {
 	switch h.op {
 	case "list":
-		// Create a new pretty table with cols configuration
-		t := newPrettyRecord(2,
-			Row{"Alias", "Alias"},
-			Row{"URL", "URL"},
-			Row{"AccessKey", "AccessKey"},
-			Row{"SecretKey", "SecretKey"},
-			Row{"API", "API"},
-			Row{"Path", "Path"},
-		)
+		rows := []Row{
+			{"Alias", "Alias"},
+			{"URL", "URL"},
+			{"AccessKey", "AccessKey"},
+			{"SecretKey", "SecretKey"},
+			{"API", "API"},
+			{"Path", "Path"},
+		}
 		// Handle deprecated lookup
 		path := h.Path
 		if path == "" {
 			path = h.Lookup
 		}
-		return t.buildRecord(h.Alias, h.URL, h.AccessKey, h.SecretKey, h.API, path)
+		contents := []string{h.Alias, h.URL, h.AccessKey, h.SecretKey, h.API, path}
+		if h.Region != "" {
+			rows = append(rows, Row{"Region", "Region"})
+			contents = append(contents, h.Region)
+		}
+		if len(h.Endpoints) > 0 {
+			policy := h.Policy
+			if policy == "" {
+				policy = aliasEndpointFailover
+			}
+			rows = append(rows, Row{"Endpoints", "Endpoints"}, Row{"Policy", "Policy"})
+			contents = append(contents, strings.Join(h.Endpoints, ", "), policy)
+		}
+		// Create a new pretty table with cols configuration
+		return newPrettyRecord(2, rows...).buildRecord(contents...)
 	case "remove":
 		return console.Colorize("AliasMessage", "Removed `"+h.Alias+"` successfully.")
 	case "add": // add is deprecated
@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"net/url"
 	"os"
 	"strings"
 	"time"
@@ -30,6 +31,7 @@ import (
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
 	"github.com/minio/pkg/console"
 	"golang.org/x/crypto/ssh/terminal"
 )
@@ -46,6 +48,36 @@ var aliasSetFlags = []cli.Flag{
 		Name:  "api",
 		Usage: "API signature. Valid options are '[S3v4, S3v2]'",
 	},
+	cli.StringFlag{
+		Name:  "region",
+		Usage: "region of the server pinned in the alias, checked against the detected one, 'auto' to detect it",
+	},
+	cli.StringSliceFlag{
+		Name:  "endpoint",
+		Usage: "another endpoint serving the alias, optionally weighted as 'URL?weight=N'",
+	},
+	cli.StringFlag{
+		Name:  "endpoint-policy",
+		Usage: "how requests use the endpoints. Valid options are '[failover, round-robin]'",
+	},
+	cli.StringFlag{
+		Name:  "encrypt-with-kes",
+		Usage: "store the secret key encrypted by the KES server at this endpoint",
+	},
+	cli.StringFlag{
+		Name:  "key",
+		Usage: "name of the KES key encrypting the secret key",
+	},
+	cli.StringFlag{
+		Name:   "kes-client-cert",
+		Usage:  "client certificate authenticating to KES",
+		EnvVar: "KES_CLIENT_CERT",
+	},
+	cli.StringFlag{
+		Name:   "kes-client-key",
+		Usage:  "client private key authenticating to KES",
+		EnvVar: "KES_CLIENT_KEY",
+	},
 }
 
 var aliasSetCmd = cli.Command{
@@ -96,6 +128,38 @@ EXAMPLES:
      {{.Prompt}} echo -e "BKIKJAA5BMMU2RHO6IBB\nV8f1CwQqAcwo80UEIJEjc5gVQUSSx5ohQ9GSrr12" | \
                  {{.HelpName}} mys3 https://s3.amazonaws.com --api "s3v4" --path "off"
      {{.EnableHistory}}
+
+  6. Add MinIO service under "myminio" alias, storing the secret key encrypted by the key 'mc-config'
+     of a KES server, it is decrypted by KES whenever the alias is used.
+     {{.Prompt}} export KES_CLIENT_CERT=~/.kes/client.crt KES_CLIENT_KEY=~/.kes/client.key
+     {{.Prompt}} {{.HelpName}} myminio http://localhost:9000 --encrypt-with-kes https://kes:7373 --key mc-config
+     Enter Access Key: minio
+     Enter Secret Key: minio123
+
+  7. Add MinIO service under "myminio" alias with a second site taking over when the first is
+     unreachable. For security reasons turn off bash history momentarily.
+     {{.DisableHistory}}
+     {{.Prompt}} {{.HelpName}} myminio https://site-a:9000 minio minio123 --endpoint https://site-b:9000
+     {{.EnableHistory}}
+
+  8. Add MinIO service under "myminio" alias spreading reads across three sites, sending twice
+     as many to the third one. For security reasons turn off bash history momentarily.
+     {{.DisableHistory}}
+     {{.Prompt}} {{.HelpName}} myminio https://site-a:9000 minio minio123 --endpoint-policy round-robin \
+                 --endpoint https://site-b:9000 --endpoint "https://site-c:9000?weight=2"
+     {{.EnableHistory}}
+
+  9. Add a gateway serving the region "eu-west-2" under "mygw" alias, pinning the region instead of
+     detecting it for each bucket. For security reasons turn off bash history momentarily.
+     {{.DisableHistory}}
+     {{.Prompt}} {{.HelpName}} mygw https://gateway.example.com minio minio123 --region eu-west-2
+     {{.EnableHistory}}
+
+  10. Add MinIO service under "myminio" alias, pinning the region of its buckets when they are all
+      in the same one. For security reasons turn off bash history momentarily.
+      {{.DisableHistory}}
+      {{.Prompt}} {{.HelpName}} myminio http://localhost:9000 minio minio123 --region auto
+      {{.EnableHistory}}
 `,
 }
 
@@ -132,6 +196,33 @@ func checkAliasSetSyntax(ctx *cli.Context, accessKey string, secretKey string, d
 			"Invalid secret key `"+secretKey+"`.")
 	}
 
+	if kesEndpoint := ctx.String("encrypt-with-kes"); kesEndpoint != "" {
+		if !isValidHostURL(kesEndpoint) {
+			fatalIf(errInvalidURL(kesEndpoint), "Invalid KES endpoint.")
+		}
+		if ctx.String("key") == "" {
+			fatalIf(errInvalidArgument().Trace(kesEndpoint), "Please specify the KES key with `--key`.")
+		}
+		if ctx.String("kes-client-cert") == "" || ctx.String("kes-client-key") == "" {
+			fatalIf(errInvalidArgument().Trace(kesEndpoint),
+				"Please specify the KES client certificate and private key with `--kes-client-cert` and `--kes-client-key`.")
+		}
+	} else if ctx.String("key") != "" {
+		fatalIf(errInvalidArgument().Trace(ctx.String("key")), "`--key` can only be used with `--encrypt-with-kes`.")
+	}
+
+	for _, endpoint := range ctx.StringSlice("endpoint") {
+		_, err := parseAliasEndpoint(endpoint)
+		fatalIf(err, "Invalid endpoint `"+endpoint+"`.")
+	}
+
+	if policy := ctx.String("endpoint-policy"); !isValidEndpointPolicy(policy) {
+		fatalIf(errInvalidArgument().Trace(policy),
+			"Unrecognized endpoint policy. Valid options are `[failover, round-robin]`.")
+	} else if policy != "" && !ctx.IsSet("endpoint") {
+		fatalIf(errInvalidArgument().Trace(policy), "`--endpoint-policy` can only be used with `--endpoint`.")
+	}
+
 	if api != "" && !isValidAPI(api) { // Empty value set to default "S3v4".
 		fatalIf(errInvalidArgument().Trace(api),
 			"Unrecognized API signature. Valid options are `[S3v4, S3v2]`.")
@@ -168,9 +259,31 @@ func setAlias(alias string, aliasCfgV10 aliasConfigV10) aliasMessage {
 		SecretKey: aliasCfgV10.SecretKey,
 		API:       aliasCfgV10.API,
 		Path:      aliasCfgV10.Path,
+		Region:    aliasCfgV10.Region,
 	}
 }
 
+// encryptAliasSecretKey replaces the secret key of the alias by its
+// ciphertext when it is to be encrypted by KES.
+func encryptAliasSecretKey(ctx context.Context, cliCtx *cli.Context, aliasCfg *aliasConfigV10) *probe.Error {
+	kesEndpoint := cliCtx.String("encrypt-with-kes")
+	if kesEndpoint == "" {
+		return nil
+	}
+	aliasCfg.KES = &aliasKESConfig{
+		Endpoint:   trimTrailingSeparator(kesEndpoint),
+		Key:        cliCtx.String("key"),
+		ClientCert: kesAbsPath(cliCtx.String("kes-client-cert")),
+		ClientKey:  kesAbsPath(cliCtx.String("kes-client-key")),
+	}
+	ciphertext, err := kesEncryptSecretKey(ctx, aliasCfg)
+	if err != nil {
+		return err
+	}
+	aliasCfg.SecretKey = ciphertext
+	return nil
+}
+
 // probeS3Signature - auto probe S3 server signature: issue a Stat call
 // using v4 signature then v2 in case of failure.
 func probeS3Signature(ctx context.Context, accessKey, secretKey, url string) (string, *probe.Error) {
@@ -248,6 +361,90 @@ func BuildS3Config(ctx context.Context, url, accessKey, secretKey, api, path str
 	return s3Config, nil
 }
 
+// aliasRegionMaxBuckets bounds the buckets whose location is looked up
+// to tell whether a server holds all its buckets in a single region.
+const aliasRegionMaxBuckets = 100
+
+// detectAliasRegion - detects the region of the server, from the host
+// of an AWS regional endpoint or the location of the buckets of other
+// servers, when they are all in the same region. No region is returned
+// for the AWS global endpoint, serving buckets of all regions, for
+// servers with buckets in several regions, nor when buckets cannot be
+// listed, the region of each bucket is then detected when it is used.
+func detectAliasRegion(ctx context.Context, s3Config *Config) (string, *probe.Error) {
+	u, e := url.Parse(s3Config.HostURL)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	if isAmazon(u.Host) || isAmazonChina(u.Host) {
+		return s3utils.GetRegionFromURL(*u), nil
+	}
+
+	detectConfig := *s3Config
+	detectConfig.Region = ""
+	clnt, err := S3New(&detectConfig)
+	if err != nil {
+		return "", err
+	}
+	api := clnt.(*S3Client).api
+
+	buckets, e := api.ListBuckets(ctx)
+	if e != nil {
+		errResp := minio.ToErrorResponse(e)
+		switch {
+		case errResp.Region != "":
+			// Servers rejecting the signature region report their own.
+			return errResp.Region, nil
+		case errResp.Code == "AccessDenied":
+			return "", nil
+		}
+		return "", probe.NewError(e)
+	}
+	if len(buckets) == 0 || len(buckets) > aliasRegionMaxBuckets {
+		return "", nil
+	}
+	var region string
+	for i, bucket := range buckets {
+		location, e := api.GetBucketLocation(ctx, bucket.Name)
+		if e != nil {
+			if minio.ToErrorResponse(e).Code == "AccessDenied" {
+				return "", nil
+			}
+			return "", probe.NewError(e)
+		}
+		if i > 0 && location != region {
+			// A multi-region server, look up the region of each bucket.
+			return "", nil
+		}
+		region = location
+	}
+	return region, nil
+}
+
+// aliasRegionAuto asks 'alias set --region' to detect the region.
+const aliasRegionAuto = "auto"
+
+// pinAliasRegion returns the region pinned in the alias, none unless
+// asked for, the regions of buckets being then detected when they are
+// used. A region given is checked against the detected one.
+func pinAliasRegion(ctx context.Context, s3Config *Config, pinned string) (string, *probe.Error) {
+	if pinned == "" {
+		return "", nil
+	}
+	region, err := detectAliasRegion(ctx, s3Config)
+	if err != nil {
+		errorIf(err.Trace(s3Config.HostURL), "Unable to detect the region of `"+s3Config.HostURL+"`, it is detected for each bucket when used.")
+		region = ""
+	}
+	if pinned == aliasRegionAuto {
+		return region, nil
+	}
+	if region != "" && region != pinned {
+		return "", errRegionMismatch(pinned, region)
+	}
+	return pinned, nil
+}
+
 // fetchAliasKeys - returns the user accessKey and secretKey
 func fetchAliasKeys(args cli.Args) (string, string) {
 	accessKey := ""
@@ -318,13 +515,29 @@ func mainAliasSet(cli *cli.Context, deprecated bool) error {
 	s3Config, err := BuildS3Config(ctx, url, accessKey, secretKey, api, path)
 	fatalIf(err.Trace(cli.Args()...), "Unable to initialize new alias from the provided credentials.")
 
-	msg := setAlias(alias, aliasConfigV10{
+	aliasCfg := aliasConfigV10{
 		URL:       s3Config.HostURL,
 		AccessKey: s3Config.AccessKey,
 		SecretKey: s3Config.SecretKey,
 		API:       s3Config.Signature,
 		Path:      path,
-	}) // Add an alias with specified credentials.
+	}
+
+	aliasCfg.Region, err = pinAliasRegion(ctx, s3Config, cli.String("region"))
+	fatalIf(err.Trace(url), "Unable to initialize new alias with the region `"+cli.String("region")+"`.")
+
+	for _, e := range cli.StringSlice("endpoint") {
+		endpoint, _ := parseAliasEndpoint(e)
+		aliasCfg.Endpoints = append(aliasCfg.Endpoints, endpoint)
+	}
+	if len(aliasCfg.Endpoints) > 0 {
+		aliasCfg.EndpointPolicy = cli.String("endpoint-policy")
+	}
+
+	err = encryptAliasSecretKey(ctx, cli, &aliasCfg)
+	fatalIf(err.Trace(cli.String("encrypt-with-kes")), "Unable to encrypt the secret key with KES.")
+
+	msg := setAlias(alias, aliasCfg) // Add an alias with specified credentials.
 
 	msg.op = "set"
 	if deprecated {
@@ -0,0 +1,148 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDetectAliasRegionAmazon(t *testing.T) {
+	testCases := []struct {
+		url    string
+		region string
+	}{
+		{"https://s3.amazonaws.com", ""},
+		{"https://s3.eu-west-1.amazonaws.com", "eu-west-1"},
+		{"https://s3.dualstack.ap-south-1.amazonaws.com", "ap-south-1"},
+		{"https://s3.cn-north-1.amazonaws.com.cn", "cn-north-1"},
+	}
+	for _, testCase := range testCases {
+		region, err := detectAliasRegion(context.Background(), &Config{HostURL: testCase.url})
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", testCase.url, err)
+		}
+		if region != testCase.region {
+			t.Errorf("%s: expected region %q, got %q", testCase.url, testCase.region, region)
+		}
+	}
+}
+
+func TestDetectAliasRegion(t *testing.T) {
+	testCases := []struct {
+		locations map[string]string
+		region    string
+	}{
+		{map[string]string{}, ""},
+		{map[string]string{"photos": "eu-west-1"}, "eu-west-1"},
+		{map[string]string{"photos": "eu-west-1", "videos": "eu-west-1"}, "eu-west-1"},
+		// Buckets of several regions, the region of each bucket is used.
+		{map[string]string{"photos": "eu-west-1", "videos": "us-west-2"}, ""},
+		{map[string]string{"photos": "", "videos": "us-west-2"}, ""},
+	}
+	for i, testCase := range testCases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bucket := strings.Trim(r.URL.Path, "/")
+			if bucket == "" {
+				var buckets strings.Builder
+				for name := range testCase.locations {
+					fmt.Fprintf(&buckets, "<Bucket><Name>%s</Name><CreationDate>2021-01-01T00:00:00.000Z</CreationDate></Bucket>", name)
+				}
+				fmt.Fprintf(w, "<ListAllMyBucketsResult><Buckets>%s</Buckets></ListAllMyBucketsResult>", buckets.String())
+				return
+			}
+			location, ok := testCase.locations[bucket]
+			if !ok || !r.URL.Query().Has("location") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			fmt.Fprintf(w, "<LocationConstraint>%s</LocationConstraint>", location)
+		}))
+		region, err := detectAliasRegion(context.Background(), &Config{
+			HostURL:   server.URL,
+			AccessKey: "WLGDGYAQYIGI833EV05A",
+			SecretKey: "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF",
+			Signature: "S3v4",
+		})
+		server.Close()
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, err)
+		}
+		if region != testCase.region {
+			t.Errorf("Test %d: expected region %q, got %q", i+1, testCase.region, region)
+		}
+	}
+}
+
+func TestPinAliasRegion(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if strings.Trim(r.URL.Path, "/") == "" {
+			fmt.Fprint(w, "<ListAllMyBucketsResult><Buckets><Bucket><Name>photos</Name><CreationDate>2021-01-01T00:00:00.000Z</CreationDate></Bucket></Buckets></ListAllMyBucketsResult>")
+			return
+		}
+		fmt.Fprint(w, "<LocationConstraint>eu-west-1</LocationConstraint>")
+	}))
+	defer server.Close()
+	denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "<Error><Code>AccessDenied</Code><Message>Access Denied.</Message></Error>")
+	}))
+	defer denied.Close()
+
+	testCases := []struct {
+		url       string
+		pinned    string
+		region    string
+		detects   bool
+		expectErr bool
+	}{
+		// No region is detected unless asked for.
+		{server.URL, "", "", false, false},
+		{server.URL, "auto", "eu-west-1", true, false},
+		{server.URL, "eu-west-1", "eu-west-1", true, false},
+		{server.URL, "us-east-1", "", true, true},
+		// Credentials not allowed to list buckets leave the region unknown.
+		{denied.URL, "auto", "", false, false},
+		{denied.URL, "us-east-1", "us-east-1", false, false},
+	}
+	for i, testCase := range testCases {
+		atomic.StoreInt32(&requests, 0)
+		region, err := pinAliasRegion(context.Background(), &Config{
+			HostURL:   testCase.url,
+			AccessKey: "WLGDGYAQYIGI833EV05A",
+			SecretKey: "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF",
+			Signature: "S3v4",
+		}, testCase.pinned)
+		if testCase.expectErr != (err != nil) {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.expectErr, err)
+		}
+		if region != testCase.region {
+			t.Errorf("Test %d: expected region %q, got %q", i+1, testCase.region, region)
+		}
+		if detects := atomic.LoadInt32(&requests) > 0; detects != testCase.detects {
+			t.Errorf("Test %d: expected detection %v, got %v", i+1, testCase.detects, detects)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// nameAnonymizer hashes object names with a random salt, so listings
+// can be shared without leaking key names. Each path element is hashed
+// on its own to keep the shape of the namespace, a same name always
+// hashes the same way within one run.
+type nameAnonymizer struct {
+	salt []byte
+}
+
+func newNameAnonymizer() *nameAnonymizer {
+	salt := make([]byte, 16)
+	_, e := rand.Read(salt)
+	fatalIf(probe.NewError(e), "Unable to generate a salt to anonymize names.")
+	return &nameAnonymizer{salt: salt}
+}
+
+// hashName returns the hash of a single path element.
+func (a *nameAnonymizer) hashName(name string) string {
+	h := sha256.New()
+	h.Write(a.salt)
+	h.Write([]byte(name))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// anonymizeKey hashes every element of the key after the prefix,
+// keeping the separators.
+func (a *nameAnonymizer) anonymizeKey(prefix, key string) string {
+	if !strings.HasPrefix(key, prefix) {
+		prefix = ""
+	}
+	elements := strings.Split(strings.TrimPrefix(key, prefix), "/")
+	for i, element := range elements {
+		if element != "" {
+			elements[i] = a.hashName(element)
+		}
+	}
+	return prefix + strings.Join(elements, "/")
+}
+
+// anonymizeContent hashes the name of listed content and redacts the
+// values that may identify it, sizes and timestamps are kept.
+func (a *nameAnonymizer) anonymizeContent(prefix string, c contentMessage) contentMessage {
+	if a == nil {
+		return c
+	}
+	c.Key = a.anonymizeKey(prefix, c.Key)
+	if c.ETag != "" {
+		c.ETag = "REDACTED"
+	}
+	if c.Owner != "" {
+		c.Owner = "REDACTED"
+	}
+	c.URL = ""
+	return c
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNameAnonymizer(t *testing.T) {
+	a, b := newNameAnonymizer(), newNameAnonymizer()
+
+	testCases := []struct {
+		prefix, key string
+	}{
+		{"", "photos/2021/cat.jpg"},
+		{"photos/", "photos/2021/cat.jpg"},
+		{"photos/", "photos/2021/"},
+		{"videos/", "photos/cat.jpg"},
+	}
+	for i, testCase := range testCases {
+		anonymized := a.anonymizeKey(testCase.prefix, testCase.key)
+		if anonymized != a.anonymizeKey(testCase.prefix, testCase.key) {
+			t.Errorf("Test %d: expected a key to be anonymized the same way within a run", i+1)
+		}
+		if anonymized == b.anonymizeKey(testCase.prefix, testCase.key) {
+			t.Errorf("Test %d: expected runs to use different salts", i+1)
+		}
+		prefix := testCase.prefix
+		if !strings.HasPrefix(testCase.key, prefix) {
+			prefix = ""
+		}
+		if !strings.HasPrefix(anonymized, prefix) || strings.Count(anonymized, "/") != strings.Count(testCase.key, "/") {
+			t.Errorf("Test %d: expected the prefix and shape of %q to be kept, got %q", i+1, testCase.key, anonymized)
+		}
+		if strings.Contains(strings.TrimPrefix(anonymized, prefix), "cat") {
+			t.Errorf("Test %d: expected the names of %q to be hashed, got %q", i+1, testCase.key, anonymized)
+		}
+	}
+
+	msg := a.anonymizeContent("", contentMessage{Key: "cat.jpg", ETag: "5d41402abc4b2a76b9719d911017c592", Owner: "minio", URL: "https://play.min.io/", Size: 42})
+	if msg.ETag != "REDACTED" || msg.Owner != "REDACTED" || msg.URL != "" || msg.Size != 42 {
+		t.Errorf("unexpected anonymized content %#v", msg)
+	}
+	var none *nameAnonymizer
+	if msg := none.anonymizeContent("", contentMessage{Key: "cat.jpg"}); msg.Key != "cat.jpg" {
+		t.Errorf("expected no anonymizer to keep the key, got %q", msg.Key)
+	}
+}
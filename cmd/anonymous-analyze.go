@@ -0,0 +1,210 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/policy"
+	"github.com/minio/pkg/console"
+	"github.com/minio/pkg/wildcard"
+)
+
+// Actions which grant anonymous read access to a bucket.
+var anonymousReadActions = []string{
+	"s3:GetObject",
+	"s3:ListBucket",
+	"s3:ListBucketMultipartUploads",
+}
+
+// Actions which grant anonymous write access to a bucket.
+var anonymousWriteActions = []string{
+	"s3:PutObject",
+	"s3:DeleteObject",
+	"s3:AbortMultipartUpload",
+	"s3:ListMultipartUploadParts",
+}
+
+// anonymousStatement is a bucket policy statement granting anonymous access.
+type anonymousStatement struct {
+	Sid       string   `json:"sid,omitempty"`
+	Actions   []string `json:"actions"`
+	Resources []string `json:"resources"`
+}
+
+// anonymousAnalyzeMessage is container for the anonymous access report of a bucket.
+type anonymousAnalyzeMessage struct {
+	Status     string               `json:"status"`
+	Bucket     string               `json:"bucket"`
+	Readable   bool                 `json:"readable"`
+	Writable   bool                 `json:"writable"`
+	Rules      []anonymousRules     `json:"rules,omitempty"`
+	Statements []anonymousStatement `json:"statements,omitempty"`
+}
+
+// String colorized anonymous access report.
+func (s anonymousAnalyzeMessage) String() string {
+	if !s.Readable && !s.Writable {
+		return console.Colorize("AnonymousPrivate", "`"+s.Bucket+"` is private")
+	}
+
+	var access []string
+	if s.Readable {
+		access = append(access, "readable")
+	}
+	if s.Writable {
+		access = append(access, "writable")
+	}
+
+	var b strings.Builder
+	b.WriteString(console.Colorize("AnonymousPublic", "`"+s.Bucket+"` is publicly "+strings.Join(access, " and ")))
+	for _, rule := range s.Rules {
+		b.WriteString("\n  rule: " + rule.String())
+	}
+	for _, stmt := range s.Statements {
+		sid := stmt.Sid
+		if sid == "" {
+			sid = "<no sid>"
+		}
+		b.WriteString("\n  statement " + sid + ": " + strings.Join(stmt.Actions, ",") + " on " + strings.Join(stmt.Resources, ","))
+	}
+	return b.String()
+}
+
+// JSON jsonified anonymous access report.
+func (s anonymousAnalyzeMessage) JSON() string {
+	analyzeJSONBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(analyzeJSONBytes)
+}
+
+// matchAnyAction returns true if the action pattern matches any of the given actions.
+func matchAnyAction(pattern string, actions []string) bool {
+	for _, action := range actions {
+		if wildcard.Match(pattern, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeAnonymousPolicy inspects a bucket policy and reports the
+// statements and prefix rules which grant anonymous access.
+func analyzeAnonymousPolicy(bucket, policyJSON string) (msg anonymousAnalyzeMessage, err *probe.Error) {
+	msg = anonymousAnalyzeMessage{Status: "success", Bucket: bucket}
+	if policyJSON == "" {
+		return msg, nil
+	}
+
+	var p policy.BucketAccessPolicy
+	if e := json.Unmarshal([]byte(policyJSON), &p); e != nil {
+		return msg, probe.NewError(e)
+	}
+
+	for _, stmt := range p.Statements {
+		if stmt.Effect != "Allow" || stmt.Principal.AWS == nil || !stmt.Principal.AWS.Contains("*") {
+			continue
+		}
+		var readable, writable bool
+		for action := range stmt.Actions {
+			readable = readable || matchAnyAction(action, anonymousReadActions)
+			writable = writable || matchAnyAction(action, anonymousWriteActions)
+		}
+		if !readable && !writable {
+			continue
+		}
+		msg.Readable = msg.Readable || readable
+		msg.Writable = msg.Writable || writable
+		msg.Statements = append(msg.Statements, anonymousStatement{
+			Sid:       stmt.Sid,
+			Actions:   stmt.Actions.ToSlice(),
+			Resources: stmt.Resources.ToSlice(),
+		})
+	}
+
+	for resource, perm := range policy.GetPolicies(p.Statements, bucket, "") {
+		if perm == policy.BucketPolicyNone {
+			continue
+		}
+		msg.Rules = append(msg.Rules, anonymousRules{Resource: resource, Allow: string(perm)})
+	}
+	sort.Slice(msg.Rules, func(i, j int) bool {
+		return msg.Rules[i].Resource < msg.Rules[j].Resource
+	})
+
+	return msg, nil
+}
+
+// Run anonymous analyze command
+func runAnonymousAnalyzeCmd(args cli.Args, expectNone bool) error {
+	ctx, cancelAnonymousAnalyze := context.WithCancel(globalContext)
+	defer cancelAnonymousAnalyze()
+
+	targetURL := args.First()
+	_, bucketPath := url2Alias(targetURL)
+
+	var bucketURLs []string
+	if strings.Trim(bucketPath, "/") == "" {
+		var err *probe.Error
+		bucketURLs, err = listBucketsURLs(ctx, targetURL)
+		fatalIf(err.Trace(targetURL), "Unable to list buckets of `"+targetURL+"`.")
+	} else {
+		bucketURLs = []string{targetURL}
+	}
+
+	var exposed int
+	for _, bucketURL := range bucketURLs {
+		_, policyJSON, err := doGetAccess(ctx, bucketURL)
+		if err != nil {
+			switch err.ToGoError().(type) {
+			case APINotImplemented:
+				fatalIf(err.Trace(), "Unable to analyze anonymous access of a non S3 url `"+bucketURL+"`.")
+			default:
+				errorIf(err.Trace(bucketURL), "Unable to get anonymous access of `"+bucketURL+"`.")
+				continue
+			}
+		}
+
+		_, bucketName := url2Alias(bucketURL)
+		msg, err := analyzeAnonymousPolicy(path.Base(bucketName), policyJSON)
+		if err != nil {
+			errorIf(err.Trace(bucketURL), "Unable to parse anonymous policy of `"+bucketURL+"`.")
+			continue
+		}
+		msg.Bucket = bucketURL
+		if msg.Readable || msg.Writable {
+			exposed++
+		}
+		printMsg(msg)
+	}
+
+	if expectNone && exposed > 0 {
+		if !globalJSON {
+			console.Errorln("Found " + strconv.Itoa(exposed) + " bucket(s) with anonymous access.")
+		}
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestAnalyzeAnonymousPolicy(t *testing.T) {
+	testCases := []struct {
+		policy   string
+		readable bool
+		writable bool
+		rules    int
+		err      bool
+	}{
+		{"", false, false, 0, false},
+		{"{invalid", false, false, 0, true},
+		// Download policy set through `mc anonymous set download`.
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetBucketLocation","s3:ListBucket"],"Resource":["arn:aws:s3:::test"]},{"Effect":"Allow","Principal":{"AWS":["*"]},"Action":["s3:GetObject"],"Resource":["arn:aws:s3:::test/*"]}]}`, true, false, 1, false},
+		// Wildcard action granted to everyone.
+		{`{"Version":"2012-10-17","Statement":[{"Sid":"all","Effect":"Allow","Principal":"*","Action":["s3:*"],"Resource":["arn:aws:s3:::test/*"]}]}`, true, true, 0, false},
+		// Statements for a specific principal are not anonymous.
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::123:root"]},"Action":["s3:PutObject"],"Resource":["arn:aws:s3:::test/*"]}]}`, false, false, 0, false},
+		// Deny statements never grant access.
+		{`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"AWS":["*"]},"Action":["s3:PutObject"],"Resource":["arn:aws:s3:::test/*"]}]}`, false, false, 0, false},
+	}
+
+	for i, testCase := range testCases {
+		msg, err := analyzeAnonymousPolicy("test", testCase.policy)
+		if testCase.err != (err != nil) {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if msg.Readable != testCase.readable || msg.Writable != testCase.writable {
+			t.Fatalf("Test %d: expected readable=%v writable=%v, got readable=%v writable=%v",
+				i+1, testCase.readable, testCase.writable, msg.Readable, msg.Writable)
+		}
+		if len(msg.Rules) != testCase.rules {
+			t.Fatalf("Test %d: expected %d rules, got %d", i+1, testCase.rules, len(msg.Rules))
+		}
+	}
+}
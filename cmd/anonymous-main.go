@@ -38,6 +38,10 @@ var (
 			Name:  "recursive, r",
 			Usage: "list recursively",
 		},
+		cli.BoolFlag{
+			Name:  "expect-none",
+			Usage: "exit with an error if any bucket allows anonymous access, used with 'analyze'",
+		},
 	}
 )
 
@@ -58,6 +62,7 @@ USAGE:
   {{.HelpName}} [FLAGS] get TARGET
   {{.HelpName}} [FLAGS] get-json TARGET
   {{.HelpName}} [FLAGS] list TARGET
+  {{.HelpName}} [FLAGS] analyze TARGET
 {{if .VisibleFlags}}
 FLAGS:
   {{range .VisibleFlags}}{{.}}
@@ -95,6 +100,12 @@ EXAMPLES:
 
   9. List public object URLs recursively.
      {{.Prompt}} {{.HelpName}} --recursive links s3/shared/
+
+  10. Report all buckets on an alias which allow anonymous read or write access.
+      {{.Prompt}} {{.HelpName}} analyze s3
+
+  11. Fail a CI pipeline if any bucket on an alias allows anonymous access.
+      {{.Prompt}} {{.HelpName}} --expect-none analyze s3
 `,
 }
 
@@ -226,6 +237,11 @@ func checkAnonymousSyntax(ctx *cli.Context) {
 		if argsLength != 2 {
 			cli.ShowCommandHelpAndExit(ctx, "anonymous", 1)
 		}
+	case "analyze":
+		// Always expect an argument after analyze cmd
+		if argsLength != 2 {
+			cli.ShowCommandHelpAndExit(ctx, "anonymous", 1)
+		}
 	default:
 		cli.ShowCommandHelpAndExit(ctx, "anonymous", 1)
 	}
@@ -479,6 +495,8 @@ func mainAnonymous(ctx *cli.Context) error {
 
 	// Additional command speific theme customization.
 	console.SetColor("Anonymous", color.New(color.FgGreen, color.Bold))
+	console.SetColor("AnonymousPrivate", color.New(color.FgGreen))
+	console.SetColor("AnonymousPublic", color.New(color.FgRed, color.Bold))
 
 	switch ctx.Args().First() {
 	case "set", "set-json", "get", "get-json":
@@ -493,6 +511,9 @@ func mainAnonymous(ctx *cli.Context) error {
 	case "links":
 		// anonymous links alias/bucket/prefix
 		runAnonymousLinksCmd(ctx.Args().Tail(), ctx.Bool("recursive"))
+	case "analyze":
+		// anonymous analyze alias
+		return runAnonymousAnalyzeCmd(ctx.Args().Tail(), ctx.Bool("expect-none"))
 	default:
 		// Shows command example and exit
 		cli.ShowCommandHelpAndExit(ctx, "anonymous", 1)
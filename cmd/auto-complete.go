@@ -220,6 +220,40 @@ func (al aliasComplete) Predict(a complete.Args) (prediction []string) {
 	return
 }
 
+// featureComplete completes the names of the experimental and deprecated features
+type featureComplete struct{}
+
+func (f featureComplete) Predict(a complete.Args) (prediction []string) {
+	for _, feature := range mcFeatures {
+		if strings.HasPrefix(feature.name, a.Last) {
+			prediction = append(prediction, feature.name)
+		}
+	}
+	return prediction
+}
+
+// jobComplete completes the IDs and names of the jobs
+type jobComplete struct{}
+
+func (j jobComplete) Predict(a complete.Args) (prediction []string) {
+	defer func() {
+		sort.Strings(prediction)
+	}()
+
+	jobs, err := listJobs()
+	if err != nil {
+		return nil
+	}
+	for _, job := range jobs {
+		for _, id := range []string{job.ID, job.Name} {
+			if id != "" && strings.HasPrefix(id, a.Last) {
+				prediction = append(prediction, id)
+			}
+		}
+	}
+	return
+}
+
 var adminConfigCompleter = adminConfigComplete{}
 var s3Completer = s3Complete{}
 var aliasCompleter = aliasComplete{}
@@ -245,6 +279,7 @@ var completeCmds = map[string]complete.Predictor{
 	"/anonymous": complete.PredictOr(s3Completer, fsCompleter),
 	"/tree":      complete.PredictOr(s3Complete{deepLevel: 2}, fsCompleter),
 	"/du":        complete.PredictOr(s3Complete{deepLevel: 2}, fsCompleter),
+	"/scrub":     complete.PredictOr(s3Completer, fsCompleter),
 
 	"/retention/set":   s3Completer,
 	"/retention/clear": s3Completer,
@@ -260,6 +295,8 @@ var completeCmds = map[string]complete.Predictor{
 	"/event/add":    s3Complete{deepLevel: 2},
 	"/event/list":   s3Complete{deepLevel: 2},
 	"/event/remove": s3Complete{deepLevel: 2},
+	"/event/export": s3Complete{deepLevel: 2},
+	"/event/import": s3Complete{deepLevel: 2},
 
 	"/encrypt/set":   s3Complete{deepLevel: 2},
 	"/encrypt/info":  s3Complete{deepLevel: 2},
@@ -272,12 +309,32 @@ var completeCmds = map[string]complete.Predictor{
 	"/replicate/export": s3Complete{deepLevel: 2},
 	"/replicate/import": s3Complete{deepLevel: 2},
 	"/replicate/status": s3Complete{deepLevel: 2},
+	"/replicate/lag":    s3Complete{deepLevel: 2},
 	"/replicate/resync": s3Complete{deepLevel: 2},
 
 	"/tag/list":   s3Completer,
 	"/tag/remove": s3Completer,
 	"/tag/set":    s3Completer,
 
+	"/snapshot/create": complete.PredictOr(s3Completer, fsCompleter),
+	"/snapshot/diff":   complete.PredictFiles("*"),
+
+	"/lockfile/create": s3Completer,
+
+	"/job/start":  nil,
+	"/job/ls":     nil,
+	"/job/status": jobComplete{},
+	"/job/cancel": jobComplete{},
+	"/job/attach": jobComplete{},
+
+	"/config/features/ls":      nil,
+	"/config/features/enable":  featureComplete{},
+	"/config/features/disable": featureComplete{},
+
+	"/trash/ls":      s3Completer,
+	"/trash/restore": s3Completer,
+	"/trash/empty":   s3Completer,
+
 	"/version/info":    s3Complete{deepLevel: 2},
 	"/version/enable":  s3Complete{deepLevel: 2},
 	"/version/suspend": s3Complete{deepLevel: 2},
@@ -287,24 +344,29 @@ var completeCmds = map[string]complete.Predictor{
 	"/lock/clear":      s3Completer,
 	"/lock/info":       s3Completer,
 
+	"/share/alias":    s3Completer,
 	"/share/download": s3Completer,
 	"/share/list":     nil,
 	"/share/upload":   s3Completer,
 
-	"/ilm/ls":      s3Complete{deepLevel: 2},
-	"/ilm/add":     s3Complete{deepLevel: 2},
-	"/ilm/edit":    s3Complete{deepLevel: 2},
-	"/ilm/rm":      s3Complete{deepLevel: 2},
-	"/ilm/export":  s3Complete{deepLevel: 2},
-	"/ilm/import":  s3Complete{deepLevel: 2},
-	"/ilm/restore": s3Completer,
+	"/ilm/ls":           s3Complete{deepLevel: 2},
+	"/ilm/add":          s3Complete{deepLevel: 2},
+	"/ilm/edit":         s3Complete{deepLevel: 2},
+	"/ilm/rm":           s3Complete{deepLevel: 2},
+	"/ilm/export":       s3Complete{deepLevel: 2},
+	"/ilm/import":       s3Complete{deepLevel: 2},
+	"/ilm/restore":      s3Completer,
+	"/ilm/tier/migrate": s3Complete{deepLevel: 2},
 
 	"/undo": s3Completer,
 
+	"/foreach": nil,
+
 	// Admin API commands MinIO only.
 	"/admin/heal": s3Completer,
 
-	"/admin/info": aliasCompleter,
+	"/admin/info":     aliasCompleter,
+	"/admin/topology": aliasCompleter,
 
 	"/admin/config/get":     adminConfigCompleter,
 	"/admin/config/set":     adminConfigCompleter,
@@ -359,6 +421,7 @@ var completeCmds = map[string]complete.Predictor{
 	"/admin/group/list":    aliasCompleter,
 	"/admin/group/remove":  aliasCompleter,
 	"/admin/group/info":    aliasCompleter,
+	"/admin/group/report":  aliasCompleter,
 
 	"/admin/bucket/remote/add":       aliasCompleter,
 	"/admin/bucket/remote/edit":      aliasCompleter,
@@ -367,6 +430,8 @@ var completeCmds = map[string]complete.Predictor{
 	"/admin/bucket/remote/bandwidth": aliasCompleter,
 	"/admin/bucket/quota":            aliasCompleter,
 
+	"/admin/api/ls": aliasCompleter,
+
 	"/admin/kms/key/create": aliasCompleter,
 	"/admin/kms/key/status": aliasCompleter,
 
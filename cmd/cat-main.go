@@ -18,12 +18,18 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -44,6 +50,35 @@ var (
 			Name:  "version-id, vid",
 			Usage: "display a specific version of an object",
 		},
+		cli.BoolFlag{
+			Name:  "recursive, r",
+			Usage: "concatenate all objects under the given prefixes",
+		},
+		cli.StringFlag{
+			Name:  "sort",
+			Value: "name",
+			Usage: "order of concatenation with --recursive, one of 'name', 'time' or 'size'",
+		},
+		cli.BoolFlag{
+			Name:  "reverse",
+			Usage: "reverse the order of concatenation with --recursive",
+		},
+		cli.StringFlag{
+			Name:  "separator",
+			Usage: "write a separator between objects, escapes like '\\n' are honored, '{}' is replaced by the next object name and also writes it before the first object",
+		},
+		cli.Int64Flag{
+			Name:  "offset",
+			Usage: "start displaying at the given byte offset",
+		},
+		cli.Int64Flag{
+			Name:  "length",
+			Usage: "display at most the given number of bytes",
+		},
+		cli.BoolFlag{
+			Name:  "decompress",
+			Usage: "decompress 'gzip' and 'zstd' objects, detected from their Content-Encoding or name",
+		},
 	}
 )
 
@@ -54,7 +89,7 @@ var catCmd = cli.Command{
 	Action:       mainCat,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(catFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(catFlags, requestHeaderFlags...), ioFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -89,6 +124,18 @@ EXAMPLES:
 
   7. Display the content of a particular object version
      {{.Prompt}} {{.HelpName}} --vid "3ddac055-89a7-40fa-8cd3-530a5581b6b8" play/my-bucket/my-object
+
+  8. Display the content of an object stored in an AWS requester-pays bucket.
+     {{.Prompt}} {{.HelpName}} --request-payer requester s3/open-dataset/README.txt
+
+  9. Concatenate all log objects of a day, oldest first, with a header line before each object.
+     {{.Prompt}} {{.HelpName}} --recursive --sort time --separator '==> {} <==\n' s3/logs/2021-10-01/ | grep ERROR
+
+  10. Display 1KiB of a large object starting at byte 1048576, without downloading the rest.
+     {{.Prompt}} {{.HelpName}} --offset 1048576 --length 1024 s3/mysql-backups/backups-201810.sql
+
+  11. Search compressed logs for errors without downloading them to a temporary file.
+     {{.Prompt}} {{.HelpName}} --decompress --recursive s3/logs/2021-10-01/ | grep ERROR
 `,
 }
 
@@ -141,8 +188,88 @@ func (s prettyStdout) Write(input []byte) (int, error) {
 	return inputLen, nil
 }
 
+// catRecursiveOpts holds the options of a recursive cat.
+type catRecursiveOpts struct {
+	sortBy    string
+	reverse   bool
+	separator string
+}
+
+// catRange selects the bytes of each source to display, a zero length
+// displaying until the end.
+type catRange struct {
+	offset int64
+	length int64
+}
+
+// isSet returns true if only a part of each source is displayed.
+func (r catRange) isSet() bool {
+	return r.offset > 0 || r.length > 0
+}
+
+// size returns the number of bytes displayed of a source of the given
+// size, or -1 if the size is unknown.
+func (r catRange) size(size int64) int64 {
+	if size < 0 {
+		return -1
+	}
+	if r.offset >= size {
+		return 0
+	}
+	size -= r.offset
+	if r.length > 0 && r.length < size {
+		size = r.length
+	}
+	return size
+}
+
+// limitStdin skips the bytes of standard input before the offset and
+// returns a reader of the remaining bytes in the range.
+func (r catRange) limitStdin() (io.Reader, *probe.Error) {
+	if r.offset > 0 {
+		if _, e := io.CopyN(ioutil.Discard, os.Stdin, r.offset); e != nil && e != io.EOF {
+			return nil, probe.NewError(e)
+		}
+	}
+	if r.length > 0 {
+		return io.LimitReader(os.Stdin, r.length), nil
+	}
+	return os.Stdin, nil
+}
+
+// catCompression returns the compression of an object, 'gzip' or 'zstd', from
+// its Content-Encoding or else the extension of its name, or an empty string.
+func catCompression(name, contentEncoding string) string {
+	switch strings.ToLower(strings.TrimSpace(contentEncoding)) {
+	case "gzip", "x-gzip":
+		return "gzip"
+	case "zstd":
+		return "zstd"
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".gz", ".gzip":
+		return "gzip"
+	case ".zst", ".zstd":
+		return "zstd"
+	}
+	return ""
+}
+
+// sniffCompression returns the compression of a stream without a name,
+// like standard input, from its leading magic bytes.
+func sniffCompression(reader *bufio.Reader) string {
+	magic, _ := reader.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return "gzip"
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return "zstd"
+	}
+	return ""
+}
+
 // parseCatSyntax performs command-line input validation for cat command.
-func parseCatSyntax(ctx *cli.Context) (args []string, versionID string, timeRef time.Time) {
+func parseCatSyntax(ctx *cli.Context) (args []string, versionID string, timeRef time.Time, byteRange catRange) {
 	args = ctx.Args()
 
 	versionID = ctx.String("version-id")
@@ -162,40 +289,205 @@ func parseCatSyntax(ctx *cli.Context) (args []string, versionID string, timeRef
 		}
 	}
 
+	if ctx.Bool("recursive") {
+		if versionID != "" {
+			fatalIf(errInvalidArgument().Trace(), "You cannot specify --version-id and --recursive at the same time")
+		}
+		if len(args) == 0 {
+			fatalIf(errInvalidArgument().Trace(), "You need to pass at least one prefix if --recursive is specified")
+		}
+		for _, arg := range args {
+			if arg == "-" {
+				fatalIf(errInvalidArgument().Trace(), "Standard input cannot be read with --recursive")
+			}
+		}
+		switch ctx.String("sort") {
+		case "name", "time", "size":
+		default:
+			fatalIf(errInvalidArgument().Trace(ctx.String("sort")), "Unknown --sort value, expected one of 'name', 'time' or 'size'.")
+		}
+	} else {
+		for _, flag := range []string{"reverse", "separator"} {
+			if ctx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(), "--"+flag+" can only be used with --recursive")
+			}
+		}
+	}
+
+	byteRange = catRange{offset: ctx.Int64("offset"), length: ctx.Int64("length")}
+	if byteRange.offset < 0 {
+		fatalIf(errInvalidArgument().Trace(), "--offset cannot be negative")
+	}
+	if ctx.IsSet("length") && byteRange.length <= 0 {
+		fatalIf(errInvalidArgument().Trace(), "--length must be greater than zero")
+	}
+	if ctx.Bool("decompress") && byteRange.isSet() {
+		fatalIf(errInvalidArgument().Trace(), "--decompress cannot be used with --offset or --length")
+	}
+
 	timeRef = parseRewindFlag(rewind)
 	return
 }
 
-// catURL displays contents of a URL to stdout.
-func catURL(ctx context.Context, sourceURL, sourceVersion string, timeRef time.Time, encKeyDB map[string][]prefixSSEPair) *probe.Error {
-	var reader io.ReadCloser
+// parseCatSeparator interprets Go escape sequences like \n and \t in the separator.
+func parseCatSeparator(separator string) (string, *probe.Error) {
+	if separator == "" {
+		return "", nil
+	}
+	s, e := strconv.Unquote(`"` + strings.ReplaceAll(separator, `"`, `\"`) + `"`)
+	if e != nil {
+		return "", probe.NewError(e)
+	}
+	return s, nil
+}
+
+// sortCatContents orders the objects to concatenate by name, modification time or size.
+// Ties are broken by name so the order is stable across runs.
+func sortCatContents(contents []*ClientContent, sortBy string, reverse bool) {
+	less := func(i, j int) bool {
+		a, b := contents[i], contents[j]
+		switch sortBy {
+		case "time":
+			if !a.Time.Equal(b.Time) {
+				return a.Time.Before(b.Time)
+			}
+		case "size":
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		}
+		return a.URL.Path < b.URL.Path
+	}
+	if reverse {
+		sort.SliceStable(contents, func(i, j int) bool { return less(j, i) })
+		return
+	}
+	sort.SliceStable(contents, less)
+}
+
+// catRecursive lists all objects under the given URLs and writes their
+// contents to stdout in the requested order, separated by opts.separator.
+func catRecursive(ctx context.Context, urls []string, opts catRecursiveOpts, timeRef time.Time, byteRange catRange, decompress bool, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	type catObject struct {
+		aliasedURL string
+		content    *ClientContent
+	}
+	var objects []catObject
+	for _, url := range urls {
+		alias, _, _ := mustExpandAlias(url)
+		clnt, err := newClient(url)
+		if err != nil {
+			return err.Trace(url)
+		}
+		var contents []*ClientContent
+		for content := range clnt.List(ctx, ListOptions{Recursive: true, TimeRef: timeRef, ShowDir: DirNone}) {
+			if content.Err != nil {
+				return content.Err.Trace(url)
+			}
+			if content.Type.IsDir() || content.IsDeleteMarker {
+				continue
+			}
+			contents = append(contents, content)
+		}
+		sortCatContents(contents, opts.sortBy, opts.reverse)
+		for _, content := range contents {
+			objects = append(objects, catObject{
+				aliasedURL: filepath.ToSlash(filepath.Join(alias, content.URL.Path)),
+				content:    content,
+			})
+		}
+	}
+
+	for i, object := range objects {
+		if opts.separator != "" && (i > 0 || strings.Contains(opts.separator, "{}")) {
+			separator := strings.ReplaceAll(opts.separator, "{}", object.aliasedURL)
+			if err := catOut(strings.NewReader(separator), -1); err != nil {
+				return err.Trace(object.aliasedURL)
+			}
+		}
+		if err := catURL(ctx, object.aliasedURL, object.content.VersionID, time.Time{}, byteRange, decompress, encKeyDB); err != nil {
+			return err.Trace(object.aliasedURL)
+		}
+	}
+	return nil
+}
+
+// catURL displays the contents of a URL in the given byte range to stdout,
+// decompressing them if requested.
+func catURL(ctx context.Context, sourceURL, sourceVersion string, timeRef time.Time, byteRange catRange, decompress bool, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	var reader io.Reader
+	var compression string
 	size := int64(-1)
 	switch sourceURL {
 	case "-":
-		reader = os.Stdin
+		var err *probe.Error
+		if reader, err = byteRange.limitStdin(); err != nil {
+			return err.Trace(sourceURL)
+		}
+		if decompress {
+			br := bufio.NewReader(reader)
+			compression = sniffCompression(br)
+			reader = br
+		}
 	default:
 		var versionID = sourceVersion
-		var err *probe.Error
 		// Try to stat the object, the purpose is to:
 		// 1. extract the size of S3 object so we can check if the size of the
 		// downloaded object is equal to the original one. FS files
 		// are ignored since some of them have zero size though they
 		// have contents like files under /proc.
 		// 2. extract the version ID if rewind flag is passed
-		if client, content, err := url2Stat(ctx, sourceURL, sourceVersion, false, encKeyDB, timeRef); err == nil {
-			if sourceVersion == "" {
-				versionID = content.VersionID
+		client, content, err := url2Stat(ctx, sourceURL, sourceVersion, false, encKeyDB, timeRef)
+		if err != nil {
+			return err.Trace(sourceURL)
+		}
+		if sourceVersion == "" {
+			versionID = content.VersionID
+		}
+		if client.GetURL().Type == objectStorage {
+			size = byteRange.size(content.Size)
+			if size == 0 && byteRange.offset > 0 {
+				// Nothing to display past the end of the object.
+				return nil
 			}
-			if client.GetURL().Type == objectStorage {
-				size = content.Size
+		}
+		if decompress {
+			compression = catCompression(content.URL.Path, content.Metadata["Content-Encoding"])
+			if compression != "" {
+				// Only objects compressed by mc record their original size.
+				size = -1
+				if client.GetURL().Type == objectStorage {
+					if n, e := strconv.ParseInt(content.Metadata[compressSizeMetaKey], 10, 64); e == nil {
+						size = n
+					}
+				}
 			}
+		}
+		var readCloser io.ReadCloser
+		if byteRange.isSet() {
+			alias, _, _ := mustExpandAlias(sourceURL)
+			readCloser, err = client.Get(ctx, GetOptions{
+				SSE:         getSSE(sourceURL, encKeyDB[alias]),
+				VersionID:   versionID,
+				RangeStart:  byteRange.offset,
+				RangeLength: byteRange.length,
+			})
 		} else {
-			return err.Trace(sourceURL)
+			readCloser, err = getSourceStreamFromURL(ctx, sourceURL, versionID, encKeyDB)
 		}
-		if reader, err = getSourceStreamFromURL(ctx, sourceURL, versionID, encKeyDB); err != nil {
+		if err != nil {
 			return err.Trace(sourceURL)
 		}
-		defer reader.Close()
+		defer readCloser.Close()
+		reader = readCloser
+	}
+	if compression != "" {
+		decompressed, e := decompressReader(reader, compression)
+		if e != nil {
+			return probe.NewError(e).Trace(sourceURL)
+		}
+		defer decompressed.Close()
+		reader = decompressed
 	}
 	return catOut(reader, size).Trace(sourceURL)
 }
@@ -254,8 +546,10 @@ func mainCat(cliCtx *cli.Context) error {
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
+	setRequestHeaders(cliCtx)
+
 	// check 'cat' cli arguments.
-	args, versionID, rewind := parseCatSyntax(cliCtx)
+	args, versionID, rewind, byteRange := parseCatSyntax(cliCtx)
 
 	// Set command flags from context.
 	stdinMode := false
@@ -265,7 +559,7 @@ func mainCat(cliCtx *cli.Context) error {
 
 	// handle std input data.
 	if stdinMode {
-		fatalIf(catOut(os.Stdin, -1).Trace(), "Unable to read from standard input.")
+		fatalIf(catURL(ctx, "-", "", time.Time{}, byteRange, cliCtx.Bool("decompress"), encKeyDB).Trace(), "Unable to read from standard input.")
 		return nil
 	}
 
@@ -280,9 +574,21 @@ func mainCat(cliCtx *cli.Context) error {
 		}
 	}
 
+	if cliCtx.Bool("recursive") {
+		separator, err := parseCatSeparator(cliCtx.String("separator"))
+		fatalIf(err.Trace(cliCtx.String("separator")), "Unable to parse --separator.")
+		opts := catRecursiveOpts{
+			sortBy:    cliCtx.String("sort"),
+			reverse:   cliCtx.Bool("reverse"),
+			separator: separator,
+		}
+		fatalIf(catRecursive(ctx, args, opts, rewind, byteRange, cliCtx.Bool("decompress"), encKeyDB).Trace(args...), "Unable to concatenate objects.")
+		return nil
+	}
+
 	// Convert arguments to URLs: expand alias, fix format.
 	for _, url := range args {
-		fatalIf(catURL(ctx, url, versionID, rewind, encKeyDB).Trace(url), "Unable to read from `"+url+"`.")
+		fatalIf(catURL(ctx, url, versionID, rewind, byteRange, cliCtx.Bool("decompress"), encKeyDB).Trace(url), "Unable to read from `"+url+"`.")
 	}
 
 	return nil
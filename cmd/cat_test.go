@@ -21,7 +21,9 @@ import (
 	"bytes"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestPrettyStdout(t *testing.T) {
@@ -63,3 +65,111 @@ func TestPrettyStdout(t *testing.T) {
 		}
 	}
 }
+
+func TestParseCatSeparator(t *testing.T) {
+	testCases := []struct {
+		separator string
+		expected  string
+		success   bool
+	}{
+		{"", "", true},
+		{"---", "---", true},
+		{`\n`, "\n", true},
+		{`==> {} <==\n`, "==> {} <==\n", true},
+		{`"quoted"\t`, "\"quoted\"\t", true},
+		{`\q`, "", false},
+	}
+
+	for i, testCase := range testCases {
+		separator, err := parseCatSeparator(testCase.separator)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: expected success %v, got error %v", i+1, testCase.success, err)
+		}
+		if separator != testCase.expected {
+			t.Fatalf("Test %d: expected %q, got %q", i+1, testCase.expected, separator)
+		}
+	}
+}
+
+func TestCatRangeSize(t *testing.T) {
+	testCases := []struct {
+		byteRange catRange
+		size      int64
+		expected  int64
+	}{
+		{catRange{}, 100, 100},
+		{catRange{}, -1, -1},
+		{catRange{offset: 10}, 100, 90},
+		{catRange{offset: 10, length: 20}, 100, 20},
+		{catRange{offset: 90, length: 20}, 100, 10},
+		{catRange{offset: 100}, 100, 0},
+		{catRange{offset: 200, length: 5}, 100, 0},
+		{catRange{length: 5}, -1, -1},
+	}
+
+	for i, testCase := range testCases {
+		if got := testCase.byteRange.size(testCase.size); got != testCase.expected {
+			t.Fatalf("Test %d: expected %d, got %d", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestCatCompression(t *testing.T) {
+	testCases := []struct {
+		name            string
+		contentEncoding string
+		expected        string
+	}{
+		{"/logs/app.log", "", ""},
+		{"/logs/app.log", "gzip", "gzip"},
+		{"/logs/app.log", "x-gzip", "gzip"},
+		{"/logs/app.log", "zstd", "zstd"},
+		{"/logs/app.log.gz", "", "gzip"},
+		{"/logs/app.log.GZ", "", "gzip"},
+		{"/logs/app.log.zst", "", "zstd"},
+		{"/logs/app.log.zst", "gzip", "gzip"},
+		{"/logs/app.tar.bz2", "", ""},
+	}
+
+	for i, testCase := range testCases {
+		if got := catCompression(testCase.name, testCase.contentEncoding); got != testCase.expected {
+			t.Fatalf("Test %d: expected %q, got %q", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestSortCatContents(t *testing.T) {
+	now := time.Now()
+	newContent := func(path string, size int64, modTime time.Time) *ClientContent {
+		return &ClientContent{URL: ClientURL{Path: path}, Size: size, Time: modTime}
+	}
+
+	testCases := []struct {
+		sortBy   string
+		reverse  bool
+		expected []string
+	}{
+		{"name", false, []string{"/a", "/b", "/c", "/d"}},
+		{"name", true, []string{"/d", "/c", "/b", "/a"}},
+		{"time", false, []string{"/c", "/a", "/b", "/d"}},
+		{"size", false, []string{"/b", "/d", "/a", "/c"}},
+		{"size", true, []string{"/c", "/a", "/d", "/b"}},
+	}
+
+	for i, testCase := range testCases {
+		contents := []*ClientContent{
+			newContent("/d", 20, now.Add(time.Minute)),
+			newContent("/a", 30, now),
+			newContent("/c", 40, now.Add(-time.Minute)),
+			newContent("/b", 20, now.Add(time.Minute)),
+		}
+		sortCatContents(contents, testCase.sortBy, testCase.reverse)
+		var got []string
+		for _, content := range contents {
+			got = append(got, content.URL.Path)
+		}
+		if !reflect.DeepEqual(got, testCase.expected) {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+)
+
+var checksumFlag = cli.StringFlag{
+	Name:  "checksum",
+	Usage: "send the checksum of each uploaded part, verified by the server before storing it, valid options are '[md5]'",
+}
+
+var (
+	// Checksum algorithm sent with uploads, empty when disabled.
+	globalChecksumAlgo string
+
+	// Number of uploads verified and of checksum mismatches.
+	globalChecksumVerified   int64
+	globalChecksumMismatches int64
+)
+
+// newChecksumHash returns the hash of a checksum algorithm, nil if unknown.
+func newChecksumHash(algo string) hash.Hash {
+	switch algo {
+	case "sha256":
+		return sha256.New()
+	}
+	return nil
+}
+
+// setChecksumOptions sets the checksum algorithm from the --checksum flag.
+func setChecksumOptions(cliCtx *cli.Context) {
+	algo := strings.ToLower(cliCtx.String("checksum"))
+	switch algo {
+	case "", "md5":
+	default:
+		fatalIf(errInvalidArgument().Trace(algo), "Unrecognized checksum algorithm. Valid options are `[md5]`.")
+	}
+	globalChecksumAlgo = algo
+}
+
+// checksumReadCloser hashes the data read from the source.
+type checksumReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func newChecksumReadCloser(r io.ReadCloser, h hash.Hash) io.ReadCloser {
+	return checksumReadCloser{Reader: io.TeeReader(r, h), Closer: r}
+}
+
+// countUploadChecksum counts the result of an upload sent with its
+// checksum, a checksum rejected by the server is a transfer corruption.
+func countUploadChecksum(err *probe.Error) *probe.Error {
+	if err == nil {
+		atomic.AddInt64(&globalChecksumVerified, 1)
+		return nil
+	}
+	if minio.ToErrorResponse(err.ToGoError()).Code == "BadDigest" {
+		atomic.AddInt64(&globalChecksumMismatches, 1)
+		return probe.NewError(fmt.Errorf("%s checksum mismatch, the data was corrupted during the transfer", globalChecksumAlgo))
+	}
+	return err
+}
+
+// checksumSummaryMessage container for the checksums verified by a transfer.
+type checksumSummaryMessage struct {
+	Status     string `json:"status"`
+	Algorithm  string `json:"algorithm"`
+	Verified   int64  `json:"verified"`
+	Mismatches int64  `json:"mismatches"`
+}
+
+func (c checksumSummaryMessage) String() string {
+	msg := fmt.Sprintf("Verified %s checksum of %d object(s)", c.Algorithm, c.Verified)
+	if c.Mismatches > 0 {
+		return console.Colorize("ChecksumMismatch", msg+fmt.Sprintf(", %d object(s) corrupted during transfer.", c.Mismatches))
+	}
+	return console.Colorize("Checksum", msg+".")
+}
+
+func (c checksumSummaryMessage) JSON() string {
+	c.Status = "success"
+	if c.Mismatches > 0 {
+		c.Status = "error"
+	}
+	jsonMessageBytes, e := json.MarshalIndent(c, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// printChecksumSummary prints the checksums verified, if enabled.
+func printChecksumSummary() {
+	if globalChecksumAlgo == "" {
+		return
+	}
+	console.SetColor("Checksum", color.New(color.FgGreen, color.Bold))
+	console.SetColor("ChecksumMismatch", color.New(color.FgRed, color.Bold))
+	printMsg(checksumSummaryMessage{
+		Algorithm:  globalChecksumAlgo,
+		Verified:   atomic.LoadInt64(&globalChecksumVerified),
+		Mismatches: atomic.LoadInt64(&globalChecksumMismatches),
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+)
+
+func TestCountUploadChecksum(t *testing.T) {
+	defer func(algo string, verified, mismatches int64) {
+		globalChecksumAlgo, globalChecksumVerified, globalChecksumMismatches = algo, verified, mismatches
+	}(globalChecksumAlgo, globalChecksumVerified, globalChecksumMismatches)
+	globalChecksumAlgo, globalChecksumVerified, globalChecksumMismatches = "md5", 0, 0
+
+	otherErr := probe.NewError(errors.New("connection reset"))
+	testCases := []struct {
+		err              *probe.Error
+		expectErr        bool
+		verified, failed int64
+	}{
+		{nil, false, 1, 0},
+		{probe.NewError(minio.ErrorResponse{Code: "BadDigest", StatusCode: http.StatusBadRequest}), true, 1, 1},
+		{otherErr, true, 1, 1},
+	}
+	for i, testCase := range testCases {
+		err := countUploadChecksum(testCase.err)
+		if (err != nil) != testCase.expectErr {
+			t.Errorf("Test %d: expected error %v, got %v", i+1, testCase.expectErr, err)
+		}
+		if verified, failed := atomic.LoadInt64(&globalChecksumVerified), atomic.LoadInt64(&globalChecksumMismatches); verified != testCase.verified || failed != testCase.failed {
+			t.Errorf("Test %d: expected %d verified and %d mismatches, got %d and %d", i+1, testCase.verified, testCase.failed, verified, failed)
+		}
+	}
+}
+
+func TestPutSendsChecksum(t *testing.T) {
+	var contentMD5 string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query().Has("location"):
+			w.Write([]byte(`<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">us-east-1</LocationConstraint>`))
+		case r.Method == http.MethodPut:
+			contentMD5 = r.Header.Get("Content-Md5")
+			w.Header().Set("ETag", `"5eb63bbbe01eeed093cb22bb8f5acdc3"`)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	clnt, err := S3New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello world")
+	if _, err = clnt.Put(context.Background(), bytes.NewReader(data), int64(len(data)), nil, PutOptions{md5: true}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "XrY7u+Ae7tCTyyK7j1rNww=="; contentMD5 != expected {
+		t.Errorf("expected Content-MD5 %s, got %q", expected, contentMD5)
+	}
+}
@@ -57,7 +57,7 @@ func NewAdminFactory() func(config *Config) (*madmin.AdminClient, *probe.Error)
 
 		// Generate a hash out of s3Conf.
 		confHash := fnv.New32a()
-		confHash.Write([]byte(hostName + config.AccessKey + config.SecretKey))
+		confHash.Write([]byte(hostName + config.ServerName + config.AccessKey + config.SecretKey))
 		confSum := confHash.Sum32()
 
 		// Lookup previous cache by hash.
@@ -86,17 +86,15 @@ func NewAdminFactory() func(config *Config) (*madmin.AdminClient, *probe.Error)
 				// Can't use TLSv1.0 because of POODLE and BEAST using CBC cipher
 				// Can't use TLSv1.1 because of RC4 cipher usage
 				MinVersion: tls.VersionTLS12,
+				ServerName: config.ServerName,
 			}
 			if config.Insecure {
 				tlsConfig.InsecureSkipVerify = true
 			}
 
 			var transport http.RoundTripper = &http.Transport{
-				Proxy: ieproxy.GetProxyFunc(),
-				DialContext: (&net.Dialer{
-					Timeout:   10 * time.Second,
-					KeepAlive: 15 * time.Second,
-				}).DialContext,
+				Proxy:                 ieproxy.GetProxyFunc(),
+				DialContext:           newCustomDialContext(10*time.Second, 15*time.Second),
 				MaxIdleConnsPerHost:   256,
 				IdleConnTimeout:       90 * time.Second,
 				TLSHandshakeTimeout:   10 * time.Second,
@@ -146,7 +144,15 @@ func newAdminClient(aliasedURL string) (*madmin.AdminClient, *probe.Error) {
 		return nil, probe.NewError(fmt.Errorf("No valid configuration found for '%s' host alias", urlStrFull))
 	}
 
+	var serverName string
+	if globalAdminNode != "" {
+		if urlStrFull, serverName, err = adminNodeURL(urlStrFull, globalAdminNode); err != nil {
+			return nil, err.Trace(aliasedURL)
+		}
+	}
+
 	s3Config := NewS3Config(urlStrFull, aliasCfg)
+	s3Config.ServerName = serverName
 
 	s3Client, err := s3AdminNew(s3Config)
 	if err != nil {
@@ -155,6 +161,26 @@ func newAdminClient(aliasedURL string) (*madmin.AdminClient, *probe.Error) {
 	return s3Client, nil
 }
 
+// adminNodeURL returns the URL of an alias directed at a node of the
+// cluster, reached with the scheme and credentials of the alias. A node
+// reached by its IP is verified with the host name of the alias, which
+// the certificates of the cluster are issued for.
+func adminNodeURL(urlStr, node string) (nodeURL, serverName string, err *probe.Error) {
+	u, e := url.Parse(urlStr)
+	if e != nil {
+		return "", "", probe.NewError(e)
+	}
+	nodeHost, _, e := net.SplitHostPort(node)
+	if e != nil {
+		return "", "", probe.NewError(e)
+	}
+	if net.ParseIP(nodeHost) != nil && net.ParseIP(u.Hostname()) == nil {
+		serverName = u.Hostname()
+	}
+	u.Host = node
+	return u.String(), serverName, nil
+}
+
 // s3AdminNew returns an initialized minioAdmin structure. If debug is enabled,
 // it also enables an internal trace transport.
 var s3AdminNew = NewAdminFactory()
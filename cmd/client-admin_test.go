@@ -0,0 +1,77 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/minio/cli"
+)
+
+func TestAdminNodeURL(t *testing.T) {
+	testCases := []struct {
+		urlStr, node        string
+		nodeURL, serverName string
+		success             bool
+	}{
+		{"https://minio.example.com", "10.0.0.2:9000", "https://10.0.0.2:9000", "minio.example.com", true},
+		{"https://minio.example.com:9000/", "[fd00::2]:9000", "https://[fd00::2]:9000/", "minio.example.com", true},
+		{"https://minio.example.com", "node2.example.com:9000", "https://node2.example.com:9000", "", true},
+		{"http://10.0.0.1:9000", "10.0.0.2:9000", "http://10.0.0.2:9000", "", true},
+		{"https://minio.example.com", "10.0.0.2", "", "", false},
+	}
+	for i, testCase := range testCases {
+		nodeURL, serverName, err := adminNodeURL(testCase.urlStr, testCase.node)
+		if (err == nil) != testCase.success {
+			t.Fatalf("Test %d: expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if nodeURL != testCase.nodeURL || serverName != testCase.serverName {
+			t.Errorf("Test %d: expected %q verified as %q, got %q verified as %q", i+1, testCase.nodeURL, testCase.serverName, nodeURL, serverName)
+		}
+	}
+}
+
+func TestAdminNodeFlag(t *testing.T) {
+	hasNode := func(flags []cli.Flag) bool {
+		for _, flag := range flags {
+			if flag.GetName() == "node" {
+				return true
+			}
+		}
+		return false
+	}
+	var check func(prefix string, cmds []cli.Command)
+	check = func(prefix string, cmds []cli.Command) {
+		for _, cmd := range cmds {
+			if !hasNode(cmd.Flags) {
+				t.Errorf("expected `%s %s` to accept --node", prefix, cmd.Name)
+			}
+			check(prefix+" "+cmd.Name, cmd.Subcommands)
+		}
+	}
+	check("admin", adminCmd.Subcommands)
+
+	if hasNode(globalFlags) {
+		t.Error("expected --node not to be a global flag")
+	}
+	for _, cmd := range []cli.Command{cpCmd, lsCmd, mirrorCmd} {
+		if hasNode(cmd.Flags) {
+			t.Errorf("expected `%s` not to accept --node", cmd.Name)
+		}
+	}
+}
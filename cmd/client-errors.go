@@ -150,6 +150,13 @@ func (e TooManyLevelsSymlink) Error() string {
 	return "Requested file `" + e.Path + "` has too many levels of symlinks"
 }
 
+// UnsafeSymlink (EPERM) - restored symbolic link pointing outside of the copy target.
+type UnsafeSymlink GenericFileError
+
+func (e UnsafeSymlink) Error() string {
+	return "Symbolic link `" + e.Path + "` points outside of the copy target, it is not restored"
+}
+
 // EmptyPath (EINVAL) - invalid argument.
 type EmptyPath struct{}
 
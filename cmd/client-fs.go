@@ -22,6 +22,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -57,6 +59,9 @@ const (
 	slashSeperator   = "/"
 	metadataKey      = "X-Amz-Meta-Mc-Attrs"
 	metadataKeyS3Cmd = "X-Amz-Meta-S3cmd-Attrs"
+
+	// metadataKeySymlink holds the escaped target of a copied symbolic link.
+	metadataKeySymlink = "X-Amz-Meta-Mc-Symlink"
 )
 
 var ( // GOOS specific ignore list.
@@ -263,6 +268,88 @@ func preserveAttributes(fd *os.File, attr map[string]string) *probe.Error {
 	return nil
 }
 
+// renameNoReplace renames src to dst, failing with an error satisfying
+// os.IsExist when dst exists. Hard links fail atomically when dst exists,
+// the check before renaming is only a fallback for the file systems
+// without hard links.
+func renameNoReplace(src, dst string) error {
+	e := os.Link(src, dst)
+	if e == nil {
+		return os.Remove(src)
+	}
+	if os.IsExist(e) {
+		return e
+	}
+	if _, e = os.Lstat(dst); e == nil {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: os.ErrExist}
+	}
+	return os.Rename(src, dst)
+}
+
+// isSymlinkInRoot tells whether a symbolic link created at linkPath with
+// the target stays within root, the link directory when root is unknown.
+// Absolute targets are never restored, they could point anywhere.
+func isSymlinkInRoot(linkPath, target, root string) bool {
+	if target == "" || filepath.IsAbs(target) {
+		return false
+	}
+	linkPath, e := filepath.Abs(linkPath)
+	if e != nil {
+		return false
+	}
+	if root == "" {
+		root = filepath.Dir(linkPath)
+	} else if root, e = filepath.Abs(root); e != nil {
+		return false
+	} else if root == linkPath {
+		// A single file copied to the target.
+		root = filepath.Dir(linkPath)
+	}
+	rel, e := filepath.Rel(root, filepath.Join(filepath.Dir(linkPath), target))
+	if e != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// putSymlink creates a symbolic link in place of the object, the content
+// of the link target is read and discarded.
+func (f *fsClient) putSymlink(reader io.Reader, progress io.Reader, linkTarget string, attr map[string]string) (int64, *probe.Error) {
+	objectPath := f.PathURL.Path
+	totalWritten, e := io.Copy(ioutil.Discard, hookreader.NewHook(reader, progress))
+	if e != nil {
+		return totalWritten, probe.NewError(e)
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		closer.Close()
+	}
+
+	// Replace any existing file, like a regular put.
+	objectPartPath := objectPath + partSuffix
+	os.Remove(objectPartPath)
+	if e = os.Symlink(linkTarget, objectPartPath); e != nil {
+		err := f.toClientError(e, objectPath)
+		return totalWritten, err.Trace(objectPath)
+	}
+	defer os.Remove(objectPartPath)
+
+	if uid, e := strconv.Atoi(attr["uid"]); e == nil {
+		gid, e := strconv.Atoi(attr["gid"])
+		if e != nil {
+			gid = -1
+		}
+		if e = os.Lchown(objectPartPath, uid, gid); e != nil {
+			console.Println(console.Colorize("Error", fmt.Sprintf("unable to preserve attributes, continuing to copy the content %s\n", e)))
+		}
+	}
+
+	if e = os.Rename(objectPartPath, objectPath); e != nil {
+		err := f.toClientError(e, objectPath)
+		return totalWritten, err.Trace(objectPartPath, objectPath)
+	}
+	return totalWritten, nil
+}
+
 /// Object operations.
 
 func (f *fsClient) put(ctx context.Context, reader io.Reader, size int64, progress io.Reader, opts PutOptions) (int64, *probe.Error) {
@@ -285,6 +372,22 @@ func (f *fsClient) put(ctx context.Context, reader io.Reader, size int64, progre
 		}
 	}
 
+	// Restore symbolic links copied with their attributes.
+	if linkTarget, ok := opts.metadata[metadataKeySymlink]; ok && opts.isPreserve {
+		target, e := url.PathUnescape(linkTarget)
+		if e != nil {
+			return 0, probe.NewError(e)
+		}
+		if !isSymlinkInRoot(f.PathURL.Path, target, opts.symlinkRoot) {
+			return 0, probe.NewError(UnsafeSymlink{Path: f.PathURL.Path})
+		}
+		attr, e := parseAttribute(opts.metadata)
+		if e != nil {
+			return 0, probe.NewError(e)
+		}
+		return f.putSymlink(reader, progress, target, attr)
+	}
+
 	objectPath := f.PathURL.Path
 
 	// Write to a temporary file "object.part.minio" before commit.
@@ -353,8 +456,17 @@ func (f *fsClient) put(ctx context.Context, reader io.Reader, size int64, progre
 		}
 	}
 
-	// Safely completed put. Now commit by renaming to actual filename.
-	if e = os.Rename(objectPartPath, objectPath); e != nil {
+	// Safely completed put. Now commit by renaming to actual filename,
+	// without replacing an existing file for conditional writes.
+	if isIfNotExists(ctx) {
+		e = renameNoReplace(objectPartPath, objectPath)
+		if os.IsExist(e) {
+			return totalWritten, probe.NewError(ObjectAlreadyExists{Object: objectPath})
+		}
+	} else {
+		e = os.Rename(objectPartPath, objectPath)
+	}
+	if e != nil {
 		err := f.toClientError(e, objectPath)
 		return totalWritten, err.Trace(objectPartPath, objectPath)
 	}
@@ -423,7 +535,13 @@ func (f *fsClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *pr
 		err := f.toClientError(e, f.PathURL.Path)
 		return nil, err.Trace(f.PathURL.Path)
 	}
-	return fileData, nil
+	if opts.RangeStart > 0 {
+		if _, e = fileData.Seek(opts.RangeStart, io.SeekStart); e != nil {
+			fileData.Close()
+			return nil, probe.NewError(e).Trace(f.PathURL.Path)
+		}
+	}
+	return limitReadCloser(fileData, opts.RangeLength), nil
 }
 
 // Check if the given error corresponds to ENOTEMPTY for unix
@@ -907,7 +1025,7 @@ func (f *fsClient) listRecursiveInRoutine(contentCh chan *ClientContent, isMetad
 		filePrefix = pathURL.Path
 	}
 	// walks invokes our custom function.
-	e := xfilepath.Walk(dirName, visitFS)
+	e := parallelWalk(dirName, globalWalkWorkers, visitFS)
 	if e != nil {
 		contentCh <- &ClientContent{
 			Err: probe.NewError(e),
@@ -1083,6 +1201,13 @@ func (f *fsClient) Stat(ctx context.Context, opts StatOptions) (content *ClientC
 			content.Metadata[k] = v
 		}
 		content.Metadata[metadataKey] = fileAttr
+		// Symbolic links are copied with the content of their target, the
+		// link is restored when copied back with attributes.
+		if lst, e := os.Lstat(path); e == nil && lst.Mode()&os.ModeSymlink != 0 {
+			if linkTarget, e := os.Readlink(path); e == nil {
+				content.Metadata[metadataKeySymlink] = url.PathEscape(linkTarget)
+			}
+		}
 	}
 
 	return content, nil
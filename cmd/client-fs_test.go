@@ -20,8 +20,10 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -330,6 +332,23 @@ func (s *TestSuite) TestGetRange(c *C) {
 	_, e = results.Write(buf)
 	c.Assert(e, IsNil)
 	c.Assert([]byte("hello"), DeepEquals, results.Bytes())
+
+	for _, testCase := range []struct {
+		start, length int64
+		expected      string
+	}{
+		{6, 0, "world"},
+		{2, 3, "llo"},
+		{6, 100, "world"},
+		{0, 4, "hell"},
+	} {
+		rc, err := fsClient.Get(context.Background(), GetOptions{RangeStart: testCase.start, RangeLength: testCase.length})
+		c.Assert(err, IsNil)
+		got, e := ioutil.ReadAll(rc)
+		c.Assert(e, IsNil)
+		c.Assert(rc.Close(), IsNil)
+		c.Assert(string(got), Equals, testCase.expected)
+	}
 }
 
 // Test stat file.
@@ -383,3 +402,79 @@ func (s *TestSuite) TestCopy(c *C) {
 	err = fsClientTarget.Copy(context.Background(), sourcePath, CopyOptions{size: int64(len(data))}, nil)
 	c.Assert(err, IsNil)
 }
+
+// Test restoring preserved symbolic links.
+func (s *TestSuite) TestPutSymlink(c *C) {
+	root, e := ioutil.TempDir(os.TempDir(), "fs-")
+	c.Assert(e, IsNil)
+	defer os.RemoveAll(root)
+
+	testCases := []struct {
+		name, target string
+		restored     bool
+	}{
+		{"dir/link", "../file", true},
+		{"dir/link", "sibling", true},
+		{"dir/link", "../../outside", false},
+		{"link", "../outside", false},
+		{"dir/link", "/etc/passwd", false},
+	}
+	for i, testCase := range testCases {
+		linkPath := filepath.Join(root, fmt.Sprintf("%d", i), testCase.name)
+		fsClient, err := fsNew(linkPath)
+		c.Assert(err, IsNil)
+		_, err = fsClient.Put(context.Background(), bytes.NewReader(nil), 0, nil, PutOptions{
+			metadata:    map[string]string{metadataKeySymlink: url.PathEscape(testCase.target)},
+			isPreserve:  true,
+			symlinkRoot: filepath.Join(root, fmt.Sprintf("%d", i)),
+		})
+		if !testCase.restored {
+			c.Assert(err, NotNil, Commentf("Test %d", i+1))
+			_, ok := err.ToGoError().(UnsafeSymlink)
+			c.Assert(ok, Equals, true, Commentf("Test %d: %v", i+1, err))
+			_, e = os.Lstat(linkPath)
+			c.Assert(os.IsNotExist(e), Equals, true, Commentf("Test %d", i+1))
+			continue
+		}
+		c.Assert(err, IsNil, Commentf("Test %d", i+1))
+		target, e := os.Readlink(linkPath)
+		c.Assert(e, IsNil)
+		c.Assert(target, Equals, testCase.target)
+	}
+
+	// A link copied alone may only point next to it.
+	c.Assert(isSymlinkInRoot(filepath.Join(root, "link"), "file", filepath.Join(root, "link")), Equals, true)
+	c.Assert(isSymlinkInRoot(filepath.Join(root, "link"), "../file", filepath.Join(root, "link")), Equals, false)
+	c.Assert(isSymlinkInRoot(filepath.Join(root, "link"), "../file", ""), Equals, false)
+}
+
+// Test conditional writes never replace an existing file.
+func (s *TestSuite) TestPutIfNotExists(c *C) {
+	root := c.MkDir()
+	objectPath := filepath.Join(root, "dir", "object")
+	fsClient, err := fsNew(objectPath)
+	c.Assert(err, IsNil)
+
+	ctx := withIfNotExists(context.Background())
+	_, err = fsClient.Put(ctx, bytes.NewReader([]byte("first")), 5, nil, PutOptions{})
+	c.Assert(err, IsNil)
+
+	_, err = fsClient.Put(ctx, bytes.NewReader([]byte("second")), 6, nil, PutOptions{})
+	c.Assert(err, NotNil)
+	_, ok := err.ToGoError().(ObjectAlreadyExists)
+	c.Assert(ok, Equals, true, Commentf("%v", err))
+	c.Assert(isErrPreconditionFailed(err), Equals, true)
+
+	data, e := ioutil.ReadFile(objectPath)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "first")
+	_, e = os.Stat(objectPath + partSuffix)
+	c.Assert(os.IsNotExist(e), Equals, true)
+
+	// Unconditional writes still replace the file.
+	_, err = fsClient.Put(context.Background(), bytes.NewReader([]byte("second")), 6, nil, PutOptions{})
+	c.Assert(err, IsNil)
+	data, e = ioutil.ReadFile(objectPath)
+	c.Assert(e, IsNil)
+	c.Assert(string(data), Equals, "second")
+}
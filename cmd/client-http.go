@@ -0,0 +1,426 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-ieproxy"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+	"github.com/minio/minio-go/v7/pkg/replication"
+)
+
+// httpURLClient reads a resource served at a plain HTTP(S) URL, which is
+// not an alias, e.g. to copy a download into a bucket with cp.
+type httpURLClient struct {
+	targetURL *ClientURL
+	urlStr    string
+	client    *http.Client
+	userAgent string
+}
+
+const (
+	// Attempts to resume an interrupted download with a ranged GET.
+	httpMaxRetries = 5
+	// Delay before the first retry, doubled with each retry.
+	httpRetryUnit = time.Second
+)
+
+// httpNew returns a client reading the resource at an HTTP(S) URL.
+func httpNew(urlStr string) (Client, *probe.Error) {
+	return &httpURLClient{
+		targetURL: newClientURL(urlStr),
+		urlStr:    urlStr,
+		userAgent: "MinIO (" + runtime.GOOS + "; " + runtime.GOARCH + ") mc/" + ReleaseTag,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:       ieproxy.GetProxyFunc(),
+				DialContext: newCustomDialContext(10*time.Second, 15*time.Second),
+				TLSClientConfig: &tls.Config{
+					RootCAs:            globalRootCAs,
+					InsecureSkipVerify: globalInsecure,
+					MinVersion:         tls.VersionTLS12,
+				},
+			},
+		},
+	}, nil
+}
+
+func (c *httpURLClient) notImplemented(api string) *probe.Error {
+	return probe.NewError(APINotImplemented{API: api, APIType: "HTTP(S) URL"})
+}
+
+// do sends a request for the resource from offset, a ranged request with
+// the ifRange validator is only served partially if the resource did not
+// change since it was validated.
+func (c *httpURLClient) do(ctx context.Context, method string, offset int64, rangeEnd, ifRange string) (*http.Response, *probe.Error) {
+	req, e := http.NewRequestWithContext(ctx, method, c.urlStr, nil)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(c.urlStr)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	if offset > 0 || rangeEnd != "" {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%s", offset, rangeEnd))
+		if ifRange != "" {
+			req.Header.Set("If-Range", ifRange)
+		}
+	}
+	resp, e := c.client.Do(req)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(c.urlStr)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, probe.NewError(ObjectMissing{}).Trace(c.urlStr)
+		}
+		return nil, probe.NewError(fmt.Errorf("%s %s: %s", method, c.urlStr, resp.Status))
+	}
+	return resp, nil
+}
+
+// Stat - returns the size and modification time of the resource, taken
+// from a HEAD request or a GET of its first byte if HEAD is not allowed,
+// e.g. on presigned URLs.
+func (c *httpURLClient) Stat(ctx context.Context, opts StatOptions) (*ClientContent, *probe.Error) {
+	size := int64(-1)
+	resp, err := c.do(ctx, http.MethodHead, 0, "", "")
+	if err == nil {
+		resp.Body.Close()
+		size = resp.ContentLength
+	} else {
+		resp, err = c.do(ctx, http.MethodGet, 0, "0", "")
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusPartialContent {
+			// Content-Range: bytes 0-0/SIZE
+			if i := strings.LastIndex(resp.Header.Get("Content-Range"), "/"); i >= 0 {
+				size, _ = strconv.ParseInt(resp.Header.Get("Content-Range")[i+1:], 10, 64)
+			}
+		} else {
+			size = resp.ContentLength
+		}
+	}
+	if size < 0 {
+		return nil, probe.NewError(fmt.Errorf("`%s` does not report its size with Content-Length", c.urlStr))
+	}
+
+	content := &ClientContent{
+		URL:          *c.targetURL,
+		Size:         size,
+		Type:         os.FileMode(0664),
+		ETag:         strings.Trim(resp.Header.Get("ETag"), "\""),
+		Metadata:     map[string]string{},
+		UserMetadata: map[string]string{},
+	}
+	if t, e := http.ParseTime(resp.Header.Get("Last-Modified")); e == nil {
+		content.Time = t
+	} else {
+		content.Time = time.Now()
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		content.Metadata["Content-Type"] = contentType
+	}
+	return content, nil
+}
+
+// Get - returns a reader of the resource, resuming with ranged GETs
+// when the download is interrupted.
+func (c *httpURLClient) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
+	resp, err := c.do(ctx, http.MethodGet, opts.RangeStart, "", "")
+	if err != nil {
+		return nil, err
+	}
+	size := resp.ContentLength
+	if opts.RangeStart > 0 {
+		if resp.StatusCode != http.StatusPartialContent {
+			resp.Body.Close()
+			return nil, probe.NewError(fmt.Errorf("%s does not support ranged requests", c.urlStr))
+		}
+		if size >= 0 {
+			size += opts.RangeStart
+		}
+	}
+	reader := &httpRangeReader{ctx: ctx, clnt: c, body: resp.Body, offset: opts.RangeStart, size: size, validator: httpValidator(resp.Header)}
+	return limitReadCloser(reader, opts.RangeLength), nil
+}
+
+// httpValidator returns the value of If-Range identifying the version of
+// a resource, its strong ETag or else its modification time. Weak ETags
+// cannot be used with If-Range.
+func httpValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// httpRangeReader reads the body of a GET, reopening it from the last
+// offset read on failure as long as the resource did not change.
+type httpRangeReader struct {
+	ctx       context.Context
+	clnt      *httpURLClient
+	body      io.ReadCloser
+	offset    int64
+	size      int64
+	validator string
+	retries   int
+}
+
+func (r *httpRangeReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			resp, err := r.clnt.do(r.ctx, http.MethodGet, r.offset, "", r.validator)
+			if err != nil {
+				if r.retry() {
+					continue
+				}
+				return 0, err.ToGoError()
+			}
+			if resp.StatusCode != http.StatusPartialContent {
+				// With If-Range the whole resource is sent when it changed.
+				resp.Body.Close()
+				return 0, fmt.Errorf("%s changed while downloading it or does not support resuming downloads with ranged requests", r.clnt.urlStr)
+			}
+			r.body = resp.Body
+		}
+
+		n, e := r.body.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.retries = 0
+		}
+		if e == io.EOF && r.size >= 0 && r.offset < r.size {
+			e = io.ErrUnexpectedEOF
+		}
+		if e == nil || e == io.EOF {
+			return n, e
+		}
+		r.body.Close()
+		r.body = nil
+		// Without a validator the resumed download might splice two
+		// different versions of the resource.
+		if r.validator == "" || !r.retry() {
+			return n, e
+		}
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// retry waits before the next attempt, returns false once all attempts failed.
+func (r *httpRangeReader) retry() bool {
+	if r.retries >= httpMaxRetries || r.ctx.Err() != nil {
+		return false
+	}
+	select {
+	case <-time.After(httpRetryUnit << r.retries):
+	case <-r.ctx.Done():
+		return false
+	}
+	r.retries++
+	return true
+}
+
+func (r *httpRangeReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
+
+// List - an HTTP(S) URL which is not an alias can only be read.
+func (c *httpURLClient) List(ctx context.Context, opts ListOptions) <-chan *ClientContent {
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: *c.targetURL, Err: errInvalidAliasedURL(c.urlStr)}
+	close(contentCh)
+	return contentCh
+}
+
+// Put - an HTTP(S) URL which is not an alias can only be read.
+func (c *httpURLClient) Put(ctx context.Context, reader io.Reader, size int64, progress io.Reader, opts PutOptions) (int64, *probe.Error) {
+	return 0, errInvalidAliasedURL(c.urlStr).Trace(c.urlStr)
+}
+
+// GetURL - returns the URL of the resource.
+func (c *httpURLClient) GetURL() ClientURL {
+	return *c.targetURL
+}
+
+// AddUserAgent - adds the application to the user agent of requests.
+func (c *httpURLClient) AddUserAgent(app, version string) {
+	c.userAgent += " " + app + "/" + version
+}
+
+func (c *httpURLClient) MakeBucket(ctx context.Context, region string, ignoreExisting, withLock bool) *probe.Error {
+	return c.notImplemented("MakeBucket")
+}
+
+func (c *httpURLClient) RemoveBucket(ctx context.Context, forceRemove bool) *probe.Error {
+	return c.notImplemented("RemoveBucket")
+}
+
+func (c *httpURLClient) SetObjectLockConfig(ctx context.Context, mode minio.RetentionMode, validity uint64, unit minio.ValidityUnit) *probe.Error {
+	return c.notImplemented("SetObjectLockConfig")
+}
+
+func (c *httpURLClient) GetObjectLockConfig(ctx context.Context) (string, minio.RetentionMode, uint64, minio.ValidityUnit, *probe.Error) {
+	return "", "", 0, "", c.notImplemented("GetObjectLockConfig")
+}
+
+func (c *httpURLClient) GetAccess(ctx context.Context) (string, string, *probe.Error) {
+	return "", "", c.notImplemented("GetBucketPolicy")
+}
+
+func (c *httpURLClient) GetAccessRules(ctx context.Context) (map[string]string, *probe.Error) {
+	return nil, c.notImplemented("GetBucketPolicy")
+}
+
+func (c *httpURLClient) SetAccess(ctx context.Context, access string, isJSON bool) *probe.Error {
+	return c.notImplemented("SetBucketPolicy")
+}
+
+func (c *httpURLClient) Copy(ctx context.Context, source string, opts CopyOptions, progress io.Reader) *probe.Error {
+	return c.notImplemented("CopyObject")
+}
+
+func (c *httpURLClient) Select(ctx context.Context, expression string, sse encrypt.ServerSide, opts SelectObjectOpts) (io.ReadCloser, *probe.Error) {
+	return nil, c.notImplemented("SelectObjectContent")
+}
+
+func (c *httpURLClient) PutObjectRetention(ctx context.Context, versionID string, mode minio.RetentionMode, retainUntilDate time.Time, bypassGovernance bool) *probe.Error {
+	return c.notImplemented("PutObjectRetention")
+}
+
+func (c *httpURLClient) GetObjectRetention(ctx context.Context, versionID string) (minio.RetentionMode, time.Time, *probe.Error) {
+	return "", time.Time{}, c.notImplemented("GetObjectRetention")
+}
+
+func (c *httpURLClient) PutObjectLegalHold(ctx context.Context, versionID string, hold minio.LegalHoldStatus) *probe.Error {
+	return c.notImplemented("PutObjectLegalHold")
+}
+
+func (c *httpURLClient) GetObjectLegalHold(ctx context.Context, versionID string) (minio.LegalHoldStatus, *probe.Error) {
+	return "", c.notImplemented("GetObjectLegalHold")
+}
+
+func (c *httpURLClient) ShareDownload(ctx context.Context, versionID string, expires time.Duration) (string, *probe.Error) {
+	return "", c.notImplemented("ShareDownload")
+}
+
+func (c *httpURLClient) ShareUpload(ctx context.Context, startsWith bool, expires time.Duration, contentType string) (string, map[string]string, *probe.Error) {
+	return "", nil, c.notImplemented("ShareUpload")
+}
+
+func (c *httpURLClient) Watch(ctx context.Context, options WatchOptions) (*WatchObject, *probe.Error) {
+	return nil, c.notImplemented("Watch")
+}
+
+func (c *httpURLClient) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *ClientContent) <-chan RemoveResult {
+	resultCh := make(chan RemoveResult)
+	go func() {
+		defer close(resultCh)
+		for range contentCh {
+			resultCh <- RemoveResult{Err: c.notImplemented("Remove")}
+		}
+	}()
+	return resultCh
+}
+
+func (c *httpURLClient) GetTags(ctx context.Context, versionID string) (map[string]string, *probe.Error) {
+	return nil, c.notImplemented("GetObjectTagging")
+}
+
+func (c *httpURLClient) SetTags(ctx context.Context, versionID, tags string) *probe.Error {
+	return c.notImplemented("PutObjectTagging")
+}
+
+func (c *httpURLClient) DeleteTags(ctx context.Context, versionID string) *probe.Error {
+	return c.notImplemented("DeleteObjectTagging")
+}
+
+func (c *httpURLClient) GetLifecycle(ctx context.Context) (*lifecycle.Configuration, *probe.Error) {
+	return nil, c.notImplemented("GetBucketLifecycle")
+}
+
+func (c *httpURLClient) SetLifecycle(ctx context.Context, config *lifecycle.Configuration) *probe.Error {
+	return c.notImplemented("PutBucketLifecycle")
+}
+
+func (c *httpURLClient) GetVersion(ctx context.Context) (minio.BucketVersioningConfiguration, *probe.Error) {
+	return minio.BucketVersioningConfiguration{}, c.notImplemented("GetBucketVersioning")
+}
+
+func (c *httpURLClient) SetVersion(ctx context.Context, status string) *probe.Error {
+	return c.notImplemented("PutBucketVersioning")
+}
+
+func (c *httpURLClient) GetReplication(ctx context.Context) (replication.Config, *probe.Error) {
+	return replication.Config{}, c.notImplemented("GetBucketReplication")
+}
+
+func (c *httpURLClient) SetReplication(ctx context.Context, cfg *replication.Config, opts replication.Options) *probe.Error {
+	return c.notImplemented("PutBucketReplication")
+}
+
+func (c *httpURLClient) RemoveReplication(ctx context.Context) *probe.Error {
+	return c.notImplemented("DeleteBucketReplication")
+}
+
+func (c *httpURLClient) GetReplicationMetrics(ctx context.Context) (replication.Metrics, *probe.Error) {
+	return replication.Metrics{}, c.notImplemented("GetReplicationMetrics")
+}
+
+func (c *httpURLClient) ResetReplication(ctx context.Context, before time.Duration, arn string) (replication.ResyncTargetsInfo, *probe.Error) {
+	return replication.ResyncTargetsInfo{}, c.notImplemented("ResetBucketReplication")
+}
+
+func (c *httpURLClient) GetEncryption(ctx context.Context) (string, string, *probe.Error) {
+	return "", "", c.notImplemented("GetBucketEncryption")
+}
+
+func (c *httpURLClient) SetEncryption(ctx context.Context, algorithm, kmsKeyID string) *probe.Error {
+	return c.notImplemented("PutBucketEncryption")
+}
+
+func (c *httpURLClient) DeleteEncryption(ctx context.Context) *probe.Error {
+	return c.notImplemented("DeleteBucketEncryption")
+}
+
+func (c *httpURLClient) GetBucketInfo(ctx context.Context) (BucketInfo, *probe.Error) {
+	return BucketInfo{}, c.notImplemented("GetBucketInfo")
+}
+
+func (c *httpURLClient) Restore(ctx context.Context, versionID string, days int) *probe.Error {
+	return c.notImplemented("RestoreObject")
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestHTTPURLClientResume(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	modTime := time.Date(2022, 4, 21, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		changed  bool
+		expected []byte
+	}{
+		{false, data},
+		// The resource changes while it is downloaded, the download
+		// fails instead of splicing two versions of it.
+		{true, nil},
+	}
+
+	for i, testCase := range testCases {
+		var requests int
+		var ifRange []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.Method == http.MethodHead {
+				w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+				w.Header().Set("Content-Type", "application/x-iso9660-image")
+				w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+				return
+			}
+			if requests == 2 {
+				// Interrupt the first download half way.
+				w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+				w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+				w.Write(data[:len(data)/2])
+				w.(http.Flusher).Flush()
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+				return
+			}
+			ifRange = append(ifRange, r.Header.Get("If-Range"))
+			serveModTime := modTime
+			if testCase.changed {
+				serveModTime = modTime.Add(time.Hour)
+			}
+			http.ServeContent(w, r, "big.iso", serveModTime, bytes.NewReader(data))
+		}))
+
+		clnt, err := httpNew(server.URL + "/big.iso")
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := clnt.Stat(context.Background(), StatOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content.Size != int64(len(data)) || content.Metadata["Content-Type"] != "application/x-iso9660-image" {
+			t.Fatalf("Test %d: unexpected stat %d %v", i+1, content.Size, content.Metadata)
+		}
+
+		reader, err := clnt.Get(context.Background(), GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, e := ioutil.ReadAll(reader)
+		reader.Close()
+		server.Close()
+		if testCase.expected == nil {
+			if e == nil {
+				t.Errorf("Test %d: expected the download of a changed resource to fail", i+1)
+			}
+		} else {
+			if e != nil {
+				t.Fatalf("Test %d: %v", i+1, e)
+			}
+			if !bytes.Equal(got, testCase.expected) {
+				t.Errorf("Test %d: expected %d bytes, got %d different bytes", i+1, len(testCase.expected), len(got))
+			}
+		}
+		if requests != 3 {
+			t.Errorf("Test %d: expected the download to resume with a ranged request, got %d requests", i+1, requests)
+		}
+		if len(ifRange) != 1 || ifRange[0] != modTime.Format(http.TimeFormat) {
+			t.Errorf("Test %d: expected the resumed request to be conditional on the modification time, got %q", i+1, ifRange)
+		}
+	}
+}
+
+func TestNewClientRealURL(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	saveMcConfig(newMcConfig())
+	loadMcConfig = loadMcConfigFactory()
+
+	urlStr := "https://releases.example.com/big.iso"
+	if _, err := newClient(urlStr); err == nil {
+		t.Errorf("expected a real URL which is not an alias to be rejected")
+	}
+	clnt, err := newSourceClient(urlStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := clnt.(*httpURLClient); !ok {
+		t.Errorf("expected a copy source at a real URL to be read over HTTP(S), got %T", clnt)
+	}
+}
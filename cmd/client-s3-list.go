@@ -0,0 +1,104 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// listAPIHosts remembers the ListObjects API version detected per host.
+var listAPIHosts = struct {
+	sync.Mutex
+	versions map[string]string
+}{versions: make(map[string]string)}
+
+// getListAPI returns the ListObjects API version to use with a host,
+// or an empty string until it is detected.
+func getListAPI(host string) string {
+	if globalListAPI != "" {
+		return globalListAPI
+	}
+	if isGoogle(host) {
+		// Google Cloud S3 layer doesn't implement ListObjectsV2 implementation
+		// https://github.com/minio/mc/issues/3073
+		return "v1"
+	}
+	listAPIHosts.Lock()
+	defer listAPIHosts.Unlock()
+	return listAPIHosts.versions[host]
+}
+
+func setListAPI(host, version string) {
+	listAPIHosts.Lock()
+	defer listAPIHosts.Unlock()
+	listAPIHosts.versions[host] = version
+}
+
+// isListV2NotSupported returns true if the error of a first
+// ListObjectsV2 page shows the server only implements V1.
+func isListV2NotSupported(e error) bool {
+	errResp := minio.ToErrorResponse(e)
+	return errResp.Code == "NotImplemented" || errResp.StatusCode == http.StatusNotImplemented
+}
+
+// listObjectsAdaptive lists with ListObjectsV2, and lists again with V1
+// when the first page shows V2 is not implemented, as with some legacy
+// gateways. The detected version is used for the next listings of the host.
+func (c *S3Client) listObjectsAdaptive(ctx context.Context, bucket string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	host := c.targetURL.Host
+	objectCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectCh)
+
+		v2Ch := c.api.ListObjects(ctx, bucket, opts)
+		first, ok := <-v2Ch
+		if ok && first.Err != nil && isListV2NotSupported(first.Err) {
+			// Drain the V2 listing, it stops after its error.
+			for range v2Ch {
+			}
+			setListAPI(host, "v1")
+			opts.WithMetadata, opts.UseV1 = false, true
+			for object := range c.api.ListObjects(ctx, bucket, opts) {
+				select {
+				case objectCh <- object:
+				case <-ctx.Done():
+					return
+				}
+			}
+			return
+		}
+		if !ok {
+			return
+		}
+		if first.Err == nil {
+			setListAPI(host, "v2")
+		}
+		for object := first; ok; object, ok = <-v2Ch {
+			select {
+			case objectCh <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return objectCh
+}
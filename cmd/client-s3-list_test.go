@@ -0,0 +1,65 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// listV1Handler serves a bucket listing with ListObjects V1 only.
+type listV1Handler struct{}
+
+func (h listV1Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("list-type") == "2" {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+	w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated><Contents><Key>object</Key><Size>12</Size></Contents></ListBucketResult>`))
+}
+
+func TestListObjectsAdaptive(t *testing.T) {
+	server := httptest.NewServer(listV1Handler{})
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for content := range s3c.List(globalContext, ListOptions{ShowDir: DirNone}) {
+		if content.Err != nil {
+			t.Fatal(content.Err)
+		}
+		keys = append(keys, content.URL.Path)
+	}
+	if len(keys) != 1 || keys[0] != "/bucket/object" {
+		t.Errorf("Expected /bucket/object to be listed, got %v", keys)
+	}
+	if v := getListAPI(s3c.GetURL().Host); v != "v1" {
+		t.Errorf("Expected ListObjects V1 to be detected, got %q", v)
+	}
+}
@@ -20,13 +20,15 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"hash/fnv"
 	"io"
-	"net"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
@@ -38,6 +40,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/minio/mc/pkg/hookreader"
 	"github.com/minio/mc/pkg/httptracer"
 	"github.com/minio/mc/pkg/probe"
 	minio "github.com/minio/minio-go/v7"
@@ -123,7 +126,7 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 		}
 		// Generate a hash out of s3Conf.
 		confHash := fnv.New32a()
-		confHash.Write([]byte(hostName + config.AccessKey + config.SecretKey + config.SessionToken))
+		confHash.Write([]byte(hostName + config.AccessKey + config.SecretKey + config.SessionToken + config.Region))
 		confSum := confHash.Sum32()
 
 		// Lookup previous cache by hash.
@@ -145,11 +148,8 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 				transport = config.Transport
 			} else {
 				tr := &http.Transport{
-					Proxy: http.ProxyFromEnvironment,
-					DialContext: (&net.Dialer{
-						Timeout:   10 * time.Second,
-						KeepAlive: 15 * time.Second,
-					}).DialContext,
+					Proxy:                 http.ProxyFromEnvironment,
+					DialContext:           newCustomDialContext(10*time.Second, 15*time.Second),
 					MaxIdleConnsPerHost:   256,
 					IdleConnTimeout:       90 * time.Second,
 					TLSHandshakeTimeout:   10 * time.Second,
@@ -188,6 +188,16 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 				transport = tr
 			}
 
+			// Bandwidth limits are looked up on every request.
+			transport = rateLimitedTransport{transport}
+
+			// Headers set with --header and --request-payer.
+			transport = requestHeaderTransport{RoundTripper: transport, creds: creds}
+
+			if config.endpoints != nil {
+				transport = aliasEndpointTransport{RoundTripper: transport, endpoints: config.endpoints, creds: creds}
+			}
+
 			if config.Debug {
 				if strings.EqualFold(config.Signature, "S3v4") {
 					transport = httptracer.GetNewTraceTransport(newTraceV4(), transport)
@@ -199,10 +209,16 @@ func newFactory() func(config *Config) (Client, *probe.Error) {
 			// Not found. Instantiate a new MinIO
 			var e error
 
+			// The region pinned in the alias, MC_REGION overrides it.
+			region := config.Region
+			if envRegion := os.Getenv("MC_REGION"); envRegion != "" {
+				region = envRegion
+			}
+
 			options := minio.Options{
 				Creds:        creds,
 				Secure:       useTLS,
-				Region:       os.Getenv("MC_REGION"),
+				Region:       region,
 				BucketLookup: config.Lookup,
 				Transport:    transport,
 			}
@@ -474,6 +490,50 @@ func (c *S3Client) ListNotificationConfigs(ctx context.Context, arn string) ([]N
 	return configs, nil
 }
 
+// SetNotificationConfigs - Replace all notification configs of the bucket
+func (c *S3Client) SetNotificationConfigs(ctx context.Context, configs []NotificationConfig) *probe.Error {
+	bucket, _ := c.url2BucketAndObject()
+
+	var mb notification.Configuration
+	for _, config := range configs {
+		fields := strings.Split(config.Arn, ":")
+		if len(fields) != 6 {
+			return errInvalidArgument().Trace(config.Arn)
+		}
+		nc := notification.NewConfig(notification.NewArn(fields[1], fields[2], fields[3], fields[4], fields[5]))
+		nc.ID = config.ID
+		for _, event := range config.Events {
+			nc.AddEvents(notification.EventType(event))
+		}
+		if config.Prefix != "" {
+			nc.AddFilterPrefix(config.Prefix)
+		}
+		if config.Suffix != "" {
+			nc.AddFilterSuffix(config.Suffix)
+		}
+
+		var added bool
+		switch fields[2] {
+		case "sns":
+			added = mb.AddTopic(nc)
+		case "sqs":
+			added = mb.AddQueue(nc)
+		case "lambda":
+			added = mb.AddLambda(nc)
+		default:
+			return errInvalidArgument().Trace(fields[2])
+		}
+		if !added {
+			return errInvalidArgument().Trace("Overlapping configs", config.Arn)
+		}
+	}
+
+	if e := c.api.SetBucketNotification(ctx, bucket, mb); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
 // Supported content types
 var supportedContentTypes = []string{
 	"csv",
@@ -806,11 +866,20 @@ func (c *S3Client) Watch(ctx context.Context, options WatchOptions) (*WatchObjec
 func (c *S3Client) Get(ctx context.Context, opts GetOptions) (io.ReadCloser, *probe.Error) {
 	bucket, object := c.url2BucketAndObject()
 
-	reader, e := c.api.GetObject(ctx, bucket, object,
-		minio.GetObjectOptions{
-			ServerSideEncryption: opts.SSE,
-			VersionID:            opts.VersionID,
-		})
+	getOpts := minio.GetObjectOptions{
+		ServerSideEncryption: opts.SSE,
+		VersionID:            opts.VersionID,
+	}
+	if opts.RangeStart > 0 || opts.RangeLength > 0 {
+		var end int64
+		if opts.RangeLength > 0 {
+			end = opts.RangeStart + opts.RangeLength - 1
+		}
+		if e := getOpts.SetRange(opts.RangeStart, end); e != nil {
+			return nil, probe.NewError(e)
+		}
+	}
+	reader, e := c.api.GetObject(ctx, bucket, object, getOpts)
 	if e != nil {
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse.Code == "NoSuchBucket" {
@@ -927,6 +996,11 @@ func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progre
 	if bucket == "" {
 		return 0, probe.NewError(BucketNameEmpty{})
 	}
+	if !putOpts.disableMultipart {
+		if err := checkMultipartSize(putOpts.multipartSize, size); err != nil {
+			return 0, err.Trace(c.targetURL.String())
+		}
+	}
 
 	metadata := make(map[string]string, len(putOpts.metadata))
 	for k, v := range putOpts.metadata {
@@ -1023,7 +1097,15 @@ func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progre
 		opts.SendContentMd5 = true
 	}
 
-	ui, e := c.api.PutObject(ctx, bucket, object, reader, size, opts)
+	var ui minio.UploadInfo
+	var e error
+	if putOpts.resume != nil && !opts.DisableMultipart && !opts.SendContentMd5 && size > 0 {
+		ui, e = c.putObjectResumable(ctx, bucket, object, reader, size, putOpts.resume, opts)
+	} else if putOpts.spoolDir != "" && !opts.DisableMultipart && !opts.SendContentMd5 && size < 0 {
+		ui, e = c.putObjectSpooled(ctx, bucket, object, reader, putOpts.spoolDir, opts)
+	} else {
+		ui, e = c.api.PutObject(ctx, bucket, object, reader, size, opts)
+	}
 	if e != nil {
 		errResponse := minio.ToErrorResponse(e)
 		if errResponse.Code == "UnexpectedEOF" || e == io.EOF {
@@ -1065,6 +1147,284 @@ func (c *S3Client) Put(ctx context.Context, reader io.Reader, size int64, progre
 	return ui.Size, nil
 }
 
+// putObjectResumable uploads the object in multiple parts from a
+// source read at offsets, continuing the incomplete upload recorded by
+// the copy session if the source is unchanged. Only the parts matching
+// the MD5 of the source data are kept. Unlike PutObject, the incomplete
+// upload is kept on failure so that it can be continued.
+func (c *S3Client) putObjectResumable(ctx context.Context, bucket, object string, reader io.Reader, size int64, resume *multipartResume, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	readerAt, ok := reader.(io.ReaderAt)
+	if !ok {
+		return c.api.PutObject(ctx, bucket, object, reader, size, opts)
+	}
+	totalParts, partSize, lastPartSize, e := minio.OptimalPartInfo(size, opts.PartSize)
+	if e != nil {
+		return minio.UploadInfo{}, e
+	}
+	if totalParts <= 1 {
+		return c.api.PutObject(ctx, bucket, object, reader, size, opts)
+	}
+	partLength := func(partNumber int) int64 {
+		if partNumber == totalParts {
+			return lastPartSize
+		}
+		return partSize
+	}
+
+	core := minio.Core{Client: c.api}
+	var (
+		uploadID string
+		parts    []minio.CompletePart
+	)
+	if prev := resume.previous; prev.UploadID != "" && prev.Size == size && prev.ModTime.Equal(resume.modTime) {
+		uploadID = prev.UploadID
+		if parts, e = verifiedUploadParts(ctx, core, bucket, object, uploadID, readerAt, partLength, opts.ServerSideEncryption); e != nil {
+			if minio.ToErrorResponse(e).Code != "NoSuchUpload" {
+				return minio.UploadInfo{}, e
+			}
+			uploadID = ""
+		}
+	}
+	if uploadID == "" {
+		if uploadID, e = core.NewMultipartUpload(ctx, bucket, object, opts); e != nil {
+			return minio.UploadInfo{}, e
+		}
+		resume.record(resumableUpload{UploadID: uploadID, Size: size, ModTime: resume.modTime})
+	}
+
+	// Account for the data of the parts already uploaded.
+	done := len(parts)
+	skip := int64(done) * partSize
+	if skip > 0 && opts.Progress != nil {
+		if _, e = io.CopyN(ioutil.Discard, opts.Progress, skip); e != nil {
+			return minio.UploadInfo{}, e
+		}
+	}
+
+	threads := int(opts.NumThreads)
+	if threads <= 0 {
+		threads = 4
+	}
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		uploadErr error
+	)
+	parts = append(parts, make([]minio.CompletePart, totalParts-len(parts))...)
+	sem := make(chan struct{}, threads)
+	for partNumber := done + 1; partNumber <= totalParts; partNumber++ {
+		sem <- struct{}{}
+		mu.Lock()
+		failed := uploadErr != nil
+		mu.Unlock()
+		if failed {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(partNumber int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			length := partLength(partNumber)
+			section := io.NewSectionReader(readerAt, int64(partNumber-1)*partSize, length)
+			part, e := core.PutObjectPart(uploadCtx, bucket, object, uploadID, partNumber,
+				hookreader.NewHook(section, opts.Progress), length, "", "", opts.ServerSideEncryption)
+			mu.Lock()
+			defer mu.Unlock()
+			if e != nil {
+				if uploadErr == nil {
+					uploadErr = e
+					cancel()
+				}
+				return
+			}
+			parts[partNumber-1] = minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag}
+		}(partNumber)
+	}
+	wg.Wait()
+	if uploadErr != nil {
+		return minio.UploadInfo{}, uploadErr
+	}
+
+	etag, e := core.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, opts)
+	if e != nil {
+		return minio.UploadInfo{}, e
+	}
+	resume.record(resumableUpload{})
+	return minio.UploadInfo{Bucket: bucket, Key: object, ETag: etag, Size: size}, nil
+}
+
+// putObjectSpooled uploads a stream of unknown size in parts, each part
+// being spooled to a temporary file of dir before it is uploaded instead
+// of being buffered in memory, which saves memory with large parts. At
+// most opts.NumThreads parts are spooled and uploaded at the same time.
+func (c *S3Client) putObjectSpooled(ctx context.Context, bucket, object string, reader io.Reader, dir string, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	_, partSize, _, e := minio.OptimalPartInfo(-1, opts.PartSize)
+	if e != nil {
+		return minio.UploadInfo{}, e
+	}
+	threads := int(opts.NumThreads)
+	if threads < 1 {
+		threads = 4
+	}
+
+	spool := func() (*os.File, int64, error) {
+		f, e := ioutil.TempFile(dir, "mc-pipe-")
+		if e != nil {
+			return nil, 0, e
+		}
+		n, e := io.CopyN(f, reader, partSize)
+		if e != nil && e != io.EOF {
+			f.Close()
+			os.Remove(f.Name())
+			return nil, 0, e
+		}
+		return f, n, nil
+	}
+	release := func(f *os.File) {
+		f.Close()
+		os.Remove(f.Name())
+	}
+
+	f, n, e := spool()
+	if e != nil {
+		return minio.UploadInfo{}, e
+	}
+	if n < partSize {
+		// The whole stream fits in a single part.
+		defer release(f)
+		return c.api.PutObject(ctx, bucket, object, io.NewSectionReader(f, 0, n), n, opts)
+	}
+
+	core := minio.Core{Client: c.api}
+	uploadID, e := core.NewMultipartUpload(ctx, bucket, object, opts)
+	if e != nil {
+		release(f)
+		return minio.UploadInfo{}, e
+	}
+
+	uploadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		parts     []minio.CompletePart
+		uploaded  int64
+		uploadErr error
+	)
+	setErr := func(e error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if uploadErr == nil {
+			uploadErr = e
+			cancel()
+		}
+	}
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return uploadErr != nil
+	}
+
+	sem := make(chan struct{}, threads)
+	for partNumber := 1; ; partNumber++ {
+		sem <- struct{}{}
+		if partNumber > 1 {
+			if failed() {
+				<-sem
+				break
+			}
+			if f, n, e = spool(); e != nil {
+				<-sem
+				setErr(e)
+				break
+			}
+			if n == 0 {
+				release(f)
+				<-sem
+				break
+			}
+		}
+		wg.Add(1)
+		go func(partNumber int, f *os.File, n int64) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer release(f)
+			part, e := core.PutObjectPart(uploadCtx, bucket, object, uploadID, partNumber,
+				io.NewSectionReader(f, 0, n), n, "", "", opts.ServerSideEncryption)
+			if e != nil {
+				setErr(e)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			parts = append(parts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+			uploaded += n
+			if opts.Progress != nil {
+				io.CopyN(ioutil.Discard, opts.Progress, n)
+			}
+		}(partNumber, f, n)
+		if n < partSize {
+			break
+		}
+	}
+	wg.Wait()
+
+	if uploadErr != nil {
+		core.AbortMultipartUpload(context.Background(), bucket, object, uploadID)
+		return minio.UploadInfo{Size: uploaded}, uploadErr
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	etag, e := core.CompleteMultipartUpload(ctx, bucket, object, uploadID, parts, opts)
+	if e != nil {
+		return minio.UploadInfo{Size: uploaded}, e
+	}
+	return minio.UploadInfo{Bucket: bucket, Key: object, ETag: etag, Size: uploaded}, nil
+}
+
+// verifiedUploadParts returns the leading parts of an incomplete upload
+// whose size and ETag match the MD5 of the source data at their offset.
+// The ETag of parts encrypted with SSE-C or SSE-KMS is not an MD5, so no
+// part of such an upload is kept.
+func verifiedUploadParts(ctx context.Context, core minio.Core, bucket, object, uploadID string, source io.ReaderAt, partLength func(int) int64, sse encrypt.ServerSide) ([]minio.CompletePart, error) {
+	var parts []minio.CompletePart
+	var offset int64
+	partNumberMarker := 0
+	for {
+		listResult, e := core.ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, 1000)
+		if e != nil {
+			return nil, e
+		}
+		// The upload exists, but its parts cannot be verified.
+		if sse != nil && sse.Type() != encrypt.S3 {
+			return nil, nil
+		}
+		for _, part := range listResult.ObjectParts {
+			length := partLength(part.PartNumber)
+			if part.PartNumber != len(parts)+1 || part.Size != length {
+				return parts, nil
+			}
+			hash := md5.New()
+			if _, e = io.Copy(hash, io.NewSectionReader(source, offset, length)); e != nil {
+				return nil, e
+			}
+			if hex.EncodeToString(hash.Sum(nil)) != strings.Trim(part.ETag, `"`) {
+				return parts, nil
+			}
+			parts = append(parts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+			offset += length
+		}
+		if !listResult.IsTruncated {
+			return parts, nil
+		}
+		partNumberMarker = listResult.NextPartNumberMarker
+	}
+}
+
 // Remove incomplete uploads.
 func (c *S3Client) removeIncompleteObjects(ctx context.Context, bucket string, objectsCh <-chan minio.ObjectInfo) <-chan minio.RemoveObjectResult {
 	removeObjectErrorCh := make(chan minio.RemoveObjectResult)
@@ -1160,12 +1520,13 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 					for removeStatus := range statusCh {
 						if removeStatus.Err != nil {
 							resultCh <- RemoveResult{
-								BucketName: bucket,
-								Err:        probe.NewError(removeStatus.Err),
+								BucketName:         prevBucket,
+								RemoveObjectResult: removeStatus,
+								Err:                probe.NewError(removeStatus.Err),
 							}
 						} else {
 							resultCh <- RemoveResult{
-								BucketName:         bucket,
+								BucketName:         prevBucket,
 								RemoveObjectResult: removeStatus,
 							}
 						}
@@ -1203,11 +1564,18 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 							VersionID: objectVersionID,
 						}:
 							sent = true
-						case removeStatus := <-statusCh:
+						case removeStatus, ok := <-statusCh:
+							if !ok {
+								// The bulk removal stopped, e.g. on an
+								// invalid bucket name already reported.
+								sent = true
+								continue
+							}
 							if removeStatus.Err != nil {
 								resultCh <- RemoveResult{
-									BucketName: bucket,
-									Err:        probe.NewError(removeStatus.Err),
+									BucketName:         bucket,
+									RemoveObjectResult: removeStatus,
+									Err:                probe.NewError(removeStatus.Err),
 								}
 							} else {
 								resultCh <- RemoveResult{
@@ -1246,10 +1614,13 @@ func (c *S3Client) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isB
 					// it is too generic. We have the object's name and vid.
 					// Adding the object's name and version id into the error msg
 					resultCh <- RemoveResult{
-						Err: probe.NewError(removeStatus.Err),
+						BucketName:         prevBucket,
+						RemoveObjectResult: removeStatus,
+						Err:                probe.NewError(removeStatus.Err),
 					}
 				} else {
 					resultCh <- RemoveResult{
+						BucketName:         prevBucket,
 						RemoveObjectResult: removeStatus,
 					}
 				}
@@ -1429,17 +1800,20 @@ func (c *S3Client) SetAccess(ctx context.Context, bucketPolicy string, isJSON bo
 }
 
 // listObjectWrapper - select ObjectList mode depending on arguments
-func (c *S3Client) listObjectWrapper(ctx context.Context, bucket, object string, isRecursive bool, timeRef time.Time, withVersions, withDeleteMarkers bool, metadata bool, maxKeys int) <-chan minio.ObjectInfo {
+func (c *S3Client) listObjectWrapper(ctx context.Context, bucket, object string, isRecursive bool, timeRef time.Time, withVersions, withDeleteMarkers bool, metadata bool, maxKeys int, startAfter string) <-chan minio.ObjectInfo {
 	if !timeRef.IsZero() || withVersions {
 		return c.listVersions(ctx, bucket, object, isRecursive, timeRef, withVersions, withDeleteMarkers)
 	}
 
-	if isGoogle(c.targetURL.Host) {
-		// Google Cloud S3 layer doesn't implement ListObjectsV2 implementation
-		// https://github.com/minio/mc/issues/3073
-		return c.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, UseV1: true, MaxKeys: maxKeys})
+	opts := minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, WithMetadata: metadata, MaxKeys: maxKeys, StartAfter: startAfter}
+	switch getListAPI(c.targetURL.Host) {
+	case "v1":
+		opts.WithMetadata, opts.UseV1 = false, true
+		return c.api.ListObjects(ctx, bucket, opts)
+	case "v2":
+		return c.api.ListObjects(ctx, bucket, opts)
 	}
-	return c.api.ListObjects(ctx, bucket, minio.ListObjectsOptions{Prefix: object, Recursive: isRecursive, WithMetadata: metadata, MaxKeys: maxKeys})
+	return c.listObjectsAdaptive(ctx, bucket, opts)
 }
 
 func (c *S3Client) statIncompleteUpload(ctx context.Context, bucket, object string) (*ClientContent, *probe.Error) {
@@ -1448,7 +1822,7 @@ func (c *S3Client) statIncompleteUpload(ctx context.Context, bucket, object stri
 	// Prefix to pass to minio-go listing in order to fetch a given object/directory
 	prefix := strings.TrimRight(object, string(c.targetURL.Separator))
 
-	for objectMultipartInfo := range c.api.ListIncompleteUploads(ctx, bucket, prefix, nonRecursive) {
+	for objectMultipartInfo := range c.listIncompleteUploads(ctx, bucket, prefix, nonRecursive) {
 		if objectMultipartInfo.Err != nil {
 			return nil, probe.NewError(objectMultipartInfo.Err)
 		}
@@ -1536,7 +1910,7 @@ func (c *S3Client) Stat(ctx context.Context, opts StatOptions) (*ClientContent,
 	// Prefix to pass to minio-go listing in order to fetch if a prefix exists
 	prefix := strings.TrimRight(object, string(c.targetURL.Separator))
 
-	for objectStat := range c.listObjectWrapper(ctx, bucket, prefix, nonRecursive, opts.timeRef, false, false, false, 1) {
+	for objectStat := range c.listObjectWrapper(ctx, bucket, prefix, nonRecursive, opts.timeRef, false, false, false, 1, "") {
 		if objectStat.Err != nil {
 			return nil, probe.NewError(objectStat.Err)
 		}
@@ -1650,6 +2024,19 @@ func (c *S3Client) url2BucketAndObject() (bucketName, objectName string) {
 	return url2BucketAndObject(c.targetURL, c.virtualStyle)
 }
 
+// startAfterKey returns the key of the bucket after which a listing
+// starts, or an empty key if startAfter is not in the bucket.
+func (c *S3Client) startAfterKey(bucket, startAfter string) string {
+	if startAfter == "" {
+		return ""
+	}
+	b, o := c.splitPath(startAfter)
+	if b != bucket {
+		return ""
+	}
+	return o
+}
+
 // splitPath split path into bucket and object.
 func (c *S3Client) splitPath(path string) (bucketName, objectName string) {
 	path = strings.TrimPrefix(path, string(c.targetURL.Separator))
@@ -1836,6 +2223,48 @@ func (c *S3Client) unversionedList(ctx context.Context, contentCh chan *ClientCo
 	}
 }
 
+// listIncompleteUploads lists the incomplete uploads like
+// ListIncompleteUploads, with their size which it leaves unset, the
+// total size of the parts uploaded so far.
+func (c *S3Client) listIncompleteUploads(ctx context.Context, bucket, prefix string, recursive bool) <-chan minio.ObjectMultipartInfo {
+	uploadsCh := make(chan minio.ObjectMultipartInfo)
+	go func() {
+		defer close(uploadsCh)
+		for upload := range c.api.ListIncompleteUploads(ctx, bucket, prefix, recursive) {
+			if upload.Err == nil && upload.UploadID != "" {
+				upload.Size, upload.Err = c.incompleteUploadSize(ctx, bucket, upload.Key, upload.UploadID)
+			}
+			select {
+			case uploadsCh <- upload:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return uploadsCh
+}
+
+// incompleteUploadSize returns the total size of the parts of an
+// incomplete upload.
+func (c *S3Client) incompleteUploadSize(ctx context.Context, bucket, object, uploadID string) (int64, error) {
+	core := minio.Core{Client: c.api}
+	var size int64
+	partNumberMarker := 0
+	for {
+		result, e := core.ListObjectParts(ctx, bucket, object, uploadID, partNumberMarker, 1000)
+		if e != nil {
+			return 0, e
+		}
+		for _, part := range result.ObjectParts {
+			size += part.Size
+		}
+		if !result.IsTruncated {
+			return size, nil
+		}
+		partNumberMarker = result.NextPartNumberMarker
+	}
+}
+
 func (c *S3Client) listIncompleteInRoutine(ctx context.Context, contentCh chan *ClientContent, opts ListOptions) {
 	// get bucket and object from URL.
 	b, o := c.url2BucketAndObject()
@@ -1850,7 +2279,7 @@ func (c *S3Client) listIncompleteInRoutine(ctx context.Context, contentCh chan *
 		}
 		isRecursive := false
 		for _, bucket := range buckets {
-			for object := range c.api.ListIncompleteUploads(ctx, bucket.Name, o, isRecursive) {
+			for object := range c.listIncompleteUploads(ctx, bucket.Name, o, isRecursive) {
 				if object.Err != nil {
 					contentCh <- &ClientContent{
 						Err: probe.NewError(object.Err),
@@ -1871,6 +2300,7 @@ func (c *S3Client) listIncompleteInRoutine(ctx context.Context, contentCh chan *
 					content.URL = url
 					content.Size = object.Size
 					content.Time = object.Initiated
+					content.UploadID = object.UploadID
 					content.Type = os.ModeTemporary
 				}
 				contentCh <- content
@@ -1878,7 +2308,7 @@ func (c *S3Client) listIncompleteInRoutine(ctx context.Context, contentCh chan *
 		}
 	default:
 		isRecursive := false
-		for object := range c.api.ListIncompleteUploads(ctx, b, o, isRecursive) {
+		for object := range c.listIncompleteUploads(ctx, b, o, isRecursive) {
 			if object.Err != nil {
 				contentCh <- &ClientContent{
 					Err: probe.NewError(object.Err),
@@ -1899,6 +2329,7 @@ func (c *S3Client) listIncompleteInRoutine(ctx context.Context, contentCh chan *
 				content.URL = url
 				content.Size = object.Size
 				content.Time = object.Initiated
+				content.UploadID = object.UploadID
 				content.Type = os.ModeTemporary
 			}
 			contentCh <- content
@@ -1925,7 +2356,7 @@ func (c *S3Client) listIncompleteRecursiveInRoutine(ctx context.Context, content
 				contentCh <- c.bucketInfo2ClientContent(bucket)
 			}
 
-			for object := range c.api.ListIncompleteUploads(ctx, bucket.Name, o, isRecursive) {
+			for object := range c.listIncompleteUploads(ctx, bucket.Name, o, isRecursive) {
 				if object.Err != nil {
 					contentCh <- &ClientContent{
 						Err: probe.NewError(object.Err),
@@ -1938,6 +2369,7 @@ func (c *S3Client) listIncompleteRecursiveInRoutine(ctx context.Context, content
 				content.URL = url
 				content.Size = object.Size
 				content.Time = object.Initiated
+				content.UploadID = object.UploadID
 				content.Type = os.ModeTemporary
 				contentCh <- content
 			}
@@ -1948,7 +2380,7 @@ func (c *S3Client) listIncompleteRecursiveInRoutine(ctx context.Context, content
 		}
 	default:
 		isRecursive := true
-		for object := range c.api.ListIncompleteUploads(ctx, b, o, isRecursive) {
+		for object := range c.listIncompleteUploads(ctx, b, o, isRecursive) {
 			if object.Err != nil {
 				contentCh <- &ClientContent{
 					Err: probe.NewError(object.Err),
@@ -1962,6 +2394,7 @@ func (c *S3Client) listIncompleteRecursiveInRoutine(ctx context.Context, content
 			content.URL = url
 			content.Size = object.Size
 			content.Time = object.Initiated
+			content.UploadID = object.UploadID
 			content.Type = os.ModeTemporary
 			contentCh <- content
 		}
@@ -2015,8 +2448,17 @@ func (c *S3Client) objectInfo2ClientContent(bucket string, entry minio.ObjectInf
 	content.Metadata = map[string]string{}
 	content.UserMetadata = map[string]string{}
 	content.ReplicationStatus = entry.ReplicationStatus
+	content.Owner = entry.Owner.DisplayName
+	if content.Owner == "" {
+		content.Owner = entry.Owner.ID
+	}
 	for k, v := range entry.UserMetadata {
 		content.UserMetadata[k] = v
+		// Listings with metadata report the replication status among
+		// user metadata.
+		if content.ReplicationStatus == "" && strings.EqualFold(k, "X-Amz-Replication-Status") {
+			content.ReplicationStatus = v
+		}
 	}
 	for k := range entry.Metadata {
 		content.Metadata[k] = entry.Metadata.Get(k)
@@ -2085,7 +2527,7 @@ func (c *S3Client) listInRoutine(ctx context.Context, contentCh chan *ClientCont
 		contentCh <- content
 	default:
 		isRecursive := false
-		for object := range c.listObjectWrapper(ctx, b, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1) {
+		for object := range c.listObjectWrapper(ctx, b, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, c.startAfterKey(b, opts.StartAfter)) {
 			if object.Err != nil {
 				contentCh <- &ClientContent{
 					Err: probe.NewError(object.Err),
@@ -2144,7 +2586,7 @@ func (c *S3Client) listRecursiveInRoutine(ctx context.Context, contentCh chan *C
 			}
 
 			isRecursive := true
-			for object := range c.listObjectWrapper(ctx, bucket.Name, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1) {
+			for object := range c.listObjectWrapper(ctx, bucket.Name, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, c.startAfterKey(bucket.Name, opts.StartAfter)) {
 				if object.Err != nil {
 					contentCh <- &ClientContent{
 						Err: probe.NewError(object.Err),
@@ -2160,7 +2602,7 @@ func (c *S3Client) listRecursiveInRoutine(ctx context.Context, contentCh chan *C
 		}
 	default:
 		isRecursive := true
-		for object := range c.listObjectWrapper(ctx, b, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1) {
+		for object := range c.listObjectWrapper(ctx, b, o, isRecursive, time.Time{}, false, false, opts.WithMetadata, -1, c.startAfterKey(b, opts.StartAfter)) {
 			if object.Err != nil {
 				contentCh <- &ClientContent{
 					Err: probe.NewError(object.Err),
@@ -21,10 +21,18 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"strconv"
+	"sync"
+	"time"
 
 	minio "github.com/minio/minio-go/v7"
 	. "gopkg.in/check.v1"
@@ -240,6 +248,202 @@ func (s *TestSuite) TestObjectOperations(c *C) {
 	}
 }
 
+// Test uploads of unknown size spooled to disk.
+func (s *TestSuite) TestPutSpooled(c *C) {
+	object := objectHandler{
+		resource: "/bucket/object",
+	}
+	server := httptest.NewServer(object)
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + object.resource
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, IsNil)
+
+	spoolDir := c.MkDir()
+	for _, size := range []int{12, 5*1024*1024 + 12} {
+		data := bytes.Repeat([]byte("a"), size)
+		n, err := s3c.Put(context.Background(), bytes.NewReader(data), -1, nil, PutOptions{
+			multipartSize: 5 * 1024 * 1024,
+			spoolDir:      spoolDir,
+		})
+		c.Assert(err, IsNil)
+		c.Assert(n, Equals, int64(size))
+
+		// The spooled parts are removed once uploaded.
+		entries, e := ioutil.ReadDir(spoolDir)
+		c.Assert(e, IsNil)
+		c.Assert(entries, HasLen, 0)
+	}
+}
+
+// multipartHandler is an http.Handler keeping the parts of multipart
+// uploads of a single object in memory.
+type multipartHandler struct {
+	mutex    sync.Mutex
+	uploads  map[string]map[int][]byte
+	putParts []int
+	object   []byte
+}
+
+func (h *multipartHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	query := r.URL.Query()
+	uploadID := query.Get("uploadId")
+	if _, ok := query["location"]; ok {
+		fmt.Fprint(w, "<LocationConstraint></LocationConstraint>")
+		return
+	}
+	if _, ok := query["uploads"]; ok && r.Method == "GET" {
+		fmt.Fprint(w, "<ListMultipartUploadsResult><Bucket>bucket</Bucket><IsTruncated>false</IsTruncated>")
+		for id := 1; id <= len(h.uploads); id++ {
+			fmt.Fprintf(w, "<Upload><Key>object</Key><UploadId>upload-%d</UploadId></Upload>", id)
+		}
+		fmt.Fprint(w, "</ListMultipartUploadsResult>")
+		return
+	}
+	if _, ok := query["uploads"]; ok && r.Method == "POST" {
+		uploadID = fmt.Sprintf("upload-%d", len(h.uploads)+1)
+		h.uploads[uploadID] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+		return
+	}
+	parts, ok := h.uploads[uploadID]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, "<Error><Code>NoSuchUpload</Code><Message>The specified upload does not exist.</Message></Error>")
+		return
+	}
+	etag := func(data []byte) string {
+		sum := md5.Sum(data)
+		return `"` + hex.EncodeToString(sum[:]) + `"`
+	}
+	switch r.Method {
+	case "PUT":
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		data, _ := ioutil.ReadAll(r.Body)
+		parts[partNumber] = data
+		h.putParts = append(h.putParts, partNumber)
+		w.Header().Set("ETag", etag(data))
+	case "GET":
+		fmt.Fprint(w, "<ListPartsResult><Bucket>bucket</Bucket><Key>object</Key><IsTruncated>false</IsTruncated>")
+		for partNumber := 1; partNumber <= len(parts); partNumber++ {
+			fmt.Fprintf(w, "<Part><PartNumber>%d</PartNumber><ETag>%s</ETag><Size>%d</Size></Part>",
+				partNumber, etag(parts[partNumber]), len(parts[partNumber]))
+		}
+		fmt.Fprint(w, "</ListPartsResult>")
+	case "POST":
+		var complete struct {
+			Parts []minio.CompletePart `xml:"Part"`
+		}
+		if e := xml.NewDecoder(r.Body).Decode(&complete); e != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		h.object = nil
+		for _, part := range complete.Parts {
+			h.object = append(h.object, parts[part.PartNumber]...)
+		}
+		delete(h.uploads, uploadID)
+		fmt.Fprint(w, "<CompleteMultipartUploadResult><Bucket>bucket</Bucket><Key>object</Key><ETag>\"etag-3\"</ETag></CompleteMultipartUploadResult>")
+	}
+}
+
+// Test uploads of a copy session continuing their incomplete upload.
+func (s *TestSuite) TestPutResumable(c *C) {
+	const partSize = 5 * 1024 * 1024
+	data := make([]byte, 2*partSize+10)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	modTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		// Parts of the incomplete upload-1, the second of them corrupted.
+		incompleteParts int
+		previous        resumableUpload
+		expectedPuts    []int
+	}{
+		// No upload recorded by the session.
+		{0, resumableUpload{}, []int{1, 2, 3}},
+		// The verified first part is kept, the corrupted second one is uploaded again.
+		{2, resumableUpload{UploadID: "upload-1", Size: int64(len(data)), ModTime: modTime}, []int{2, 3}},
+		// The source changed since the upload started.
+		{2, resumableUpload{UploadID: "upload-1", Size: int64(len(data)), ModTime: modTime.Add(time.Second)}, []int{1, 2, 3}},
+		// The upload recorded does not exist anymore.
+		{0, resumableUpload{UploadID: "upload-1", Size: int64(len(data)), ModTime: modTime}, []int{1, 2, 3}},
+	}
+	for i, tc := range testCases {
+		handler := &multipartHandler{uploads: make(map[string]map[int][]byte)}
+		if tc.incompleteParts > 0 {
+			handler.uploads["upload-1"] = map[int][]byte{
+				1: data[:partSize],
+				2: bytes.Repeat([]byte("x"), partSize),
+			}
+		}
+		server := httptest.NewServer(handler)
+
+		conf := new(Config)
+		conf.HostURL = server.URL + "/bucket/object"
+		conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+		conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+		conf.Signature = "S3v4"
+		s3c, err := S3New(conf)
+		c.Assert(err, IsNil)
+
+		var recorded []resumableUpload
+		n, err := s3c.Put(context.Background(), bytes.NewReader(data), int64(len(data)), nil, PutOptions{
+			multipartSize:    partSize,
+			multipartThreads: 2,
+			resume: &multipartResume{
+				previous: tc.previous,
+				modTime:  modTime,
+				record:   func(upload resumableUpload) { recorded = append(recorded, upload) },
+			},
+		})
+		server.Close()
+		c.Assert(err, IsNil, Commentf("Test %d", i+1))
+		c.Assert(n, Equals, int64(len(data)))
+		c.Assert(handler.object, DeepEquals, data, Commentf("Test %d", i+1))
+
+		sort.Ints(handler.putParts)
+		c.Assert(handler.putParts, DeepEquals, tc.expectedPuts, Commentf("Test %d", i+1))
+		// The upload is forgotten by the session once complete.
+		c.Assert(recorded[len(recorded)-1], Equals, resumableUpload{})
+	}
+}
+
+// Test the size of incomplete uploads, the total size of their parts.
+func (s *TestSuite) TestListIncompleteSize(c *C) {
+	handler := &multipartHandler{uploads: map[string]map[int][]byte{
+		"upload-1": {1: make([]byte, 10), 2: make([]byte, 5)},
+		"upload-2": {1: make([]byte, 7)},
+	}}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	s3c, err := S3New(conf)
+	c.Assert(err, IsNil)
+
+	var sizes []int64
+	for content := range s3c.List(context.Background(), ListOptions{Incomplete: true, Recursive: true, ShowDir: DirNone}) {
+		c.Assert(content.Err, IsNil)
+		sizes = append(sizes, content.Size)
+	}
+	c.Assert(sizes, DeepEquals, []int64{15, 7})
+}
+
 var testSelectCompressionTypeCases = []struct {
 	opts            SelectObjectOpts
 	object          string
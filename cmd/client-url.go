@@ -201,6 +201,23 @@ func url2Stat(ctx context.Context, urlStr, versionID string, fileAttr bool, encK
 	return client, content, nil
 }
 
+// sourceURL2Stat returns stat info for the URL of a copy source, which
+// may also be a real URL which is not an alias.
+func sourceURL2Stat(ctx context.Context, urlStr, versionID string, fileAttr bool, encKeyDB map[string][]prefixSSEPair, timeRef time.Time) (client Client, content *ClientContent, err *probe.Error) {
+	client, err = newSourceClient(urlStr)
+	if err != nil {
+		return nil, nil, err.Trace(urlStr)
+	}
+	alias, _ := url2Alias(urlStr)
+	sse := getSSE(urlStr, encKeyDB[alias])
+
+	content, err = client.Stat(ctx, StatOptions{preserve: fileAttr, sse: sse, timeRef: timeRef, versionID: versionID})
+	if err != nil {
+		return nil, nil, err.Trace(urlStr)
+	}
+	return client, content, nil
+}
+
 // firstURL2Stat returns the stat info of the first object having the specified prefix
 func firstURL2Stat(ctx context.Context, prefix string, timeRef time.Time) (client Client, content *ClientContent, err *probe.Error) {
 	client, err = newClient(prefix)
@@ -47,6 +47,26 @@ const (
 type GetOptions struct {
 	SSE       encrypt.ServerSide
 	VersionID string
+	// RangeStart and RangeLength select a byte range of the object,
+	// a zero RangeLength reading until the end.
+	RangeStart  int64
+	RangeLength int64
+}
+
+// limitedReadCloser is an io.ReadCloser reading at most a given
+// number of bytes from the underlying reader.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// limitReadCloser limits rc to length bytes, a zero length returning
+// rc unchanged.
+func limitReadCloser(rc io.ReadCloser, length int64) io.ReadCloser {
+	if length <= 0 {
+		return rc
+	}
+	return limitedReadCloser{Reader: io.LimitReader(rc, length), Closer: rc}
 }
 
 // PutOptions holds options for PUT operation
@@ -58,6 +78,29 @@ type PutOptions struct {
 	storageClass          string
 	multipartSize         uint64
 	multipartThreads      uint
+	resume                *multipartResume
+	// Directory spooling the parts of uploads of unknown size.
+	spoolDir string
+	// Directory preserved symbolic links must point into.
+	symlinkRoot string
+}
+
+// resumableUpload is an incomplete multipart upload started by a copy
+// session, with the size and modification time of its source.
+type resumableUpload struct {
+	UploadID string    `json:"uploadId"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"modTime"`
+}
+
+// multipartResume continues the incomplete upload of an object recorded
+// by its copy session, as long as the source is unchanged.
+type multipartResume struct {
+	previous resumableUpload
+	modTime  time.Time
+	// record saves the upload started in the session, or
+	// removes it when given a zero value once it completes.
+	record func(resumableUpload)
 }
 
 // StatOptions holds options of the HEAD operation
@@ -79,6 +122,9 @@ type ListOptions struct {
 	TimeRef           time.Time
 	ShowDir           DirOpt
 	Count             int
+	// StartAfter is the path of a content, as found in the listed
+	// URLs, after which a listing supporting it starts.
+	StartAfter string
 }
 
 // CopyOptions holds options for copying operation
@@ -183,6 +229,7 @@ type ClientContent struct {
 	Metadata     map[string]string
 	UserMetadata map[string]string
 	ETag         string
+	Owner        string
 	Expires      time.Time
 
 	Expiration       time.Time
@@ -195,12 +242,17 @@ type ClientContent struct {
 	LegalHoldEnabled  bool
 	LegalHold         string
 	VersionID         string
+	UploadID          string // only set for incomplete uploads
 	IsDeleteMarker    bool
 	IsLatest          bool
 	ReplicationStatus string
 
 	Restore *minio.RestoreInfo
 
+	// Checksums stored with the object keyed by algorithm, only
+	// set when explicitly asked for.
+	Checksums map[string]string
+
 	Err *probe.Error
 }
 
@@ -211,12 +263,17 @@ type Config struct {
 	SessionToken string
 	Signature    string
 	HostURL      string
+	Region       string
 	AppName      string
 	AppVersion   string
 	Debug        bool
 	Insecure     bool
+	ServerName   string // Verified in TLS instead of the host of HostURL.
 	Lookup       minio.BucketLookupType
 	Transport    *http.Transport
+
+	// Endpoints of the alias requests are spread across.
+	endpoints *aliasEndpointSet
 }
 
 // SelectObjectOpts - opts entered for select API
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/base64"
 	"errors"
+	"hash"
 	"io"
 	"net/http"
 	"os"
@@ -152,7 +153,7 @@ func getSourceStreamMetadataFromURL(ctx context.Context, aliasedURL, versionID s
 		return nil, nil, err.Trace(aliasedURL)
 	}
 	if !timeRef.IsZero() {
-		_, content, err := url2Stat(ctx, aliasedURL, "", false, nil, timeRef)
+		_, content, err := url2Stat(ctx, aliasedURL, "", false, encKeyDB, timeRef)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -332,7 +333,7 @@ func putTargetStream(ctx context.Context, alias, urlStr, mode, until, legalHold
 }
 
 // putTargetStreamWithURL writes to URL from reader. If length=-1, read until EOF.
-func putTargetStreamWithURL(urlStr string, reader io.Reader, size int64, opts PutOptions) (int64, *probe.Error) {
+func putTargetStreamWithURL(ctx context.Context, urlStr string, reader io.Reader, size int64, opts PutOptions) (int64, *probe.Error) {
 	alias, urlStrFull, _, err := expandAlias(urlStr)
 	if err != nil {
 		return 0, err.Trace(alias, urlStr)
@@ -342,7 +343,7 @@ func putTargetStreamWithURL(urlStr string, reader io.Reader, size int64, opts Pu
 		opts.metadata = map[string]string{}
 	}
 	opts.metadata["Content-Type"] = contentType
-	return putTargetStream(context.Background(), alias, urlStrFull, "", "", "", reader, size, nil, opts)
+	return putTargetStream(ctx, alias, urlStrFull, "", "", "", reader, size, nil, opts)
 }
 
 // copySourceToTargetURL copies to targetURL from source.
@@ -490,8 +491,10 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 		metadata[http.CanonicalHeaderKey(k)] = v
 	}
 
-	// Optimize for server side copy if the host is same.
-	if sourceAlias == targetAlias {
+	// Optimize for server side copy if the host is same, plain HTTP(S)
+	// URLs have no alias either and are downloaded to local targets.
+	// Objects pinned by a lockfile are streamed to verify their checksum.
+	if urls.SHA256 == "" && isServerSideCopy(sourceAlias, targetAlias, sourceURL, targetURL) {
 		// preserve new metadata and save existing ones.
 		if preserve {
 			currentMetadata, err := getAllMetadata(ctx, sourceAlias, sourceURL.String(), srcSSE, urls)
@@ -565,6 +568,36 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 		}
 		defer reader.Close()
 
+		// Free the source connection before a slow upload.
+		if isStreamViaDisk(sourceURL, targetURL) {
+			var tmpFile io.ReadCloser
+			tmpFile, err = spillToDisk(reader, length)
+			if err != nil {
+				return urls.WithError(err.Trace(sourceURL.String()))
+			}
+			defer tmpFile.Close()
+			reader.Close()
+			reader = tmpFile
+		}
+
+		// Hash the source to verify it against the lockfile pinning it.
+		var lockChecksum hash.Hash
+		if urls.SHA256 != "" {
+			lockChecksum = newChecksumHash("sha256")
+			reader = newChecksumReadCloser(reader, lockChecksum)
+		}
+
+		// Compress or decompress the stream on the fly.
+		var transcoded bool
+		reader, length, transcoded, err = transcodeStream(reader, length, sourceURL, targetURL, metadata, progress)
+		if err != nil {
+			return urls.WithError(err.Trace(sourceURL.String()))
+		}
+		if transcoded {
+			defer reader.Close()
+			progress = nil
+		}
+
 		// Get metadata from target content as well
 		for k, v := range urls.TargetContent.Metadata {
 			metadata[http.CanonicalHeaderKey(k)] = v
@@ -584,29 +617,48 @@ func uploadSourceToTargetURL(ctx context.Context, urls URLs, progress io.Reader,
 			}
 		}
 
+		if globalMultipartSize > 0 {
+			multipartSize = globalMultipartSize
+		}
+
 		multipartThreads, e := strconv.Atoi(env.Get("MC_UPLOAD_MULTIPART_THREADS", "4"))
 		if e != nil {
 			return urls.WithError(probe.NewError(e))
 		}
+		if globalMultipartThreads > 0 {
+			multipartThreads = int(globalMultipartThreads)
+		}
 
 		putOpts := PutOptions{
 			metadata:         filterMetadata(metadata),
 			sse:              tgtSSE,
 			storageClass:     urls.TargetContent.StorageClass,
-			md5:              urls.MD5,
+			md5:              urls.MD5 || globalChecksumAlgo == "md5",
 			disableMultipart: urls.DisableMultipart,
 			isPreserve:       preserve,
 			multipartSize:    multipartSize,
 			multipartThreads: uint(multipartThreads),
+			symlinkRoot:      urls.targetRoot,
+		}
+		if urls.resumeSession != nil {
+			putOpts.resume = urls.resumeSession.multipartResume(targetURL.String(), urls.SourceContent.Time)
 		}
 
-		if isReadAt(reader) {
+		if isReadAt(reader) || length < 0 {
 			_, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
 				legalHold, reader, length, progress, putOpts)
 		} else {
 			_, err = putTargetStream(ctx, targetAlias, targetURL.String(), mode, until,
 				legalHold, io.LimitReader(reader, length), length, progress, putOpts)
 		}
+		if err == nil && lockChecksum != nil {
+			if err = verifyLockfileChecksum(sourceURL.String(), urls.SHA256, lockChecksum.Sum(nil)); err != nil {
+				errorIf(removeLockfileTarget(ctx, targetAlias, targetURL.String()), "Unable to remove the corrupted copy `"+targetURL.String()+"`.")
+			}
+		}
+		if globalChecksumAlgo != "" && targetURL.Type == objectStorage {
+			err = countUploadChecksum(err)
+		}
 	}
 	if err != nil {
 		return urls.WithError(err.Trace(sourceURL.String()))
@@ -625,6 +677,10 @@ func newClientFromAlias(alias, urlStr string) (Client, *probe.Error) {
 	}
 
 	if hostCfg == nil {
+		// A plain HTTP(S) URL, only its resource can be read.
+		if urlRgx.MatchString(urlStr) {
+			return httpNew(urlStr)
+		}
 		// No matching host config. So we treat it like a
 		// filesystem.
 		fsClient, fsErr := fsNew(urlStr)
@@ -659,3 +715,17 @@ func newClient(aliasedURL string) (Client, *probe.Error) {
 	}
 	return newClientFromAlias(alias, urlStrFull)
 }
+
+// newSourceClient gives a new client interface for a copy source, unlike
+// newClient a real URL which is not an alias is accepted to read the
+// resource it serves, e.g. to copy a download.
+func newSourceClient(aliasedURL string) (Client, *probe.Error) {
+	_, _, hostCfg, err := expandAlias(aliasedURL)
+	if err != nil {
+		return nil, err.Trace(aliasedURL)
+	}
+	if hostCfg == nil && urlRgx.MatchString(aliasedURL) {
+		return httpNew(aliasedURL)
+	}
+	return newClient(aliasedURL)
+}
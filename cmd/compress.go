@@ -0,0 +1,283 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/hookreader"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var compressFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "compress",
+		Usage: "compress objects while uploading them, valid options are '[zstd, gzip]'",
+	},
+	cli.StringSliceFlag{
+		Name:  "compress-extensions",
+		Usage: "compress objects with the extension, can be repeated, defaults to common uncompressed formats",
+	},
+	cli.StringSliceFlag{
+		Name:  "compress-exclude",
+		Usage: "do not compress objects matching the pattern, can be repeated, defaults to common compressed formats",
+	},
+}
+
+// Metadata recording the size of an object before it was compressed.
+const compressSizeMetaKey = "X-Amz-Meta-Mc-Uncompressed-Size"
+
+// Uncompressed formats which are compressed by default.
+var defaultCompressExtensions = []string{".txt", ".log", ".csv", ".json", ".tar", ".xml", ".bin"}
+
+// Already compressed formats which are not compressed again by default.
+var defaultCompressExclude = []string{
+	"*.gz", "*.tgz", "*.zst", "*.bz2", "*.xz", "*.lz4", "*.zip", "*.7z", "*.rar",
+	"*.jpg", "*.jpeg", "*.png", "*.gif", "*.webp", "*.mp3", "*.mp4", "*.mkv", "*.mov", "*.webm",
+}
+
+var (
+	// Compression of uploaded objects, empty when disabled.
+	globalCompress string
+
+	// Extensions of object names which are compressed.
+	globalCompressExtensions []string
+
+	// Patterns of object names which are uploaded as is.
+	globalCompressExclude []string
+)
+
+// Compressed uploads up to this size are kept in memory, larger
+// ones are spooled to a temporary file.
+const compressMemoryLimit = 16 << 20
+
+// setCompressOptions sets the compression of uploads from the --compress flags.
+func setCompressOptions(cliCtx *cli.Context) {
+	algo := strings.ToLower(cliCtx.String("compress"))
+	switch algo {
+	case "", "zstd", "gzip":
+	default:
+		fatalIf(errInvalidArgument().Trace(algo), "Unrecognized compression. Valid options are `[zstd, gzip]`.")
+	}
+	if algo == "" && (cliCtx.IsSet("compress-exclude") || cliCtx.IsSet("compress-extensions")) {
+		fatalIf(errInvalidArgument(), "`--compress-exclude` and `--compress-extensions` can only be used with `--compress`.")
+	}
+	globalCompress = algo
+	globalCompressExtensions = defaultCompressExtensions
+	if cliCtx.IsSet("compress-extensions") {
+		globalCompressExtensions = nil
+		for _, ext := range cliCtx.StringSlice("compress-extensions") {
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			globalCompressExtensions = append(globalCompressExtensions, strings.ToLower(ext))
+		}
+	}
+	globalCompressExclude = defaultCompressExclude
+	if cliCtx.IsSet("compress-exclude") {
+		globalCompressExclude = cliCtx.StringSlice("compress-exclude")
+	}
+}
+
+// compressReader returns the stream compressed with algo.
+func compressReader(reader io.Reader, algo string) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		var w io.WriteCloser
+		var e error
+		switch algo {
+		case "zstd":
+			w, e = zstd.NewWriter(pw)
+		default:
+			w = gzip.NewWriter(pw)
+		}
+		if e == nil {
+			if _, e = io.Copy(w, reader); e == nil {
+				e = w.Close()
+			}
+		}
+		pw.CloseWithError(e)
+	}()
+	return pr
+}
+
+// isCompressible tells whether an object of that name is compressed
+// while uploading it.
+func isCompressible(name string) bool {
+	if matchExcludeOptions(globalCompressExclude, name) {
+		return false
+	}
+	ext := strings.ToLower(path.Ext(name))
+	for _, compressExt := range globalCompressExtensions {
+		if ext == compressExt {
+			return true
+		}
+	}
+	return false
+}
+
+// spoolWriter keeps the written bytes in memory until they exceed
+// compressMemoryLimit, and in a temporary file afterwards.
+type spoolWriter struct {
+	buf  bytes.Buffer
+	file *os.File
+	size int64
+}
+
+func (s *spoolWriter) Write(p []byte) (int, error) {
+	if s.file == nil && s.buf.Len()+len(p) > compressMemoryLimit {
+		f, e := ioutil.TempFile("", "mc-compress-")
+		if e != nil {
+			return 0, e
+		}
+		s.file = f
+		if _, e = s.buf.WriteTo(f); e != nil {
+			return 0, e
+		}
+	}
+	var n int
+	var e error
+	if s.file != nil {
+		n, e = s.file.Write(p)
+	} else {
+		n, e = s.buf.Write(p)
+	}
+	s.size += int64(n)
+	return n, e
+}
+
+// reader returns the spooled bytes, the temporary file is removed once closed.
+func (s *spoolWriter) reader() (io.ReadCloser, error) {
+	if s.file == nil {
+		return ioutil.NopCloser(bytes.NewReader(s.buf.Bytes())), nil
+	}
+	if _, e := s.file.Seek(0, io.SeekStart); e != nil {
+		s.discard()
+		return nil, e
+	}
+	return tempFileReader{s.file}, nil
+}
+
+// discard removes the temporary file, if any.
+func (s *spoolWriter) discard() {
+	if s.file != nil {
+		tempFileReader{s.file}.Close()
+	}
+}
+
+// compressToSpool compresses the stream with algo ahead of the upload,
+// so that it is sent with its exact length rather than as a stream of
+// unknown size.
+func compressToSpool(reader io.Reader, algo string) (io.ReadCloser, int64, error) {
+	spool := &spoolWriter{}
+	compressed := compressReader(reader, algo)
+	defer compressed.Close()
+	if _, e := io.Copy(spool, compressed); e != nil {
+		spool.discard()
+		return nil, 0, e
+	}
+	spooled, e := spool.reader()
+	if e != nil {
+		return nil, 0, e
+	}
+	return spooled, spool.size, nil
+}
+
+// decompressReader returns the stream decompressed with algo.
+func decompressReader(reader io.Reader, algo string) (io.ReadCloser, error) {
+	switch algo {
+	case "zstd":
+		zr, e := zstd.NewReader(reader)
+		if e != nil {
+			return nil, e
+		}
+		return zr.IOReadCloser(), nil
+	case "gzip":
+		return gzip.NewReader(reader)
+	}
+	return nil, errInvalidArgument().Trace(algo).ToGoError()
+}
+
+// transcodeReadCloser reads the transcoded stream and closes the source.
+type transcodeReadCloser struct {
+	io.ReadCloser
+	source io.Closer
+}
+
+func (t transcodeReadCloser) Close() error {
+	t.ReadCloser.Close()
+	return t.source.Close()
+}
+
+// transcodeStream compresses a stream uploaded with --compress, or
+// decompresses an object compressed by mc and downloaded to the local
+// filesystem. It returns the new stream, its length, and whether it was
+// transcoded, the progress is then already accounted against the bytes
+// read from the source.
+func transcodeStream(reader io.ReadCloser, length int64, sourceURL, targetURL ClientURL, metadata map[string]string, progress io.Reader) (io.ReadCloser, int64, bool, *probe.Error) {
+	encoding := metadata["Content-Encoding"]
+	switch {
+	case globalCompress != "" && targetURL.Type == objectStorage && encoding == "":
+		if !isCompressible(path.Base(sourceURL.Path)) {
+			return reader, length, false, nil
+		}
+		compressed, size, e := compressToSpool(hookreader.NewHook(io.LimitReader(reader, length), progress), globalCompress)
+		if e != nil {
+			return nil, 0, false, probe.NewError(e).Trace(sourceURL.String())
+		}
+		metadata["Content-Encoding"] = globalCompress
+		metadata[compressSizeMetaKey] = strconv.FormatInt(length, 10)
+		return transcodeReadCloser{compressed, reader}, size, true, nil
+	case targetURL.Type == fileSystem && sourceURL.Type == objectStorage && metadata[compressSizeMetaKey] != "":
+		size, e := strconv.ParseInt(metadata[compressSizeMetaKey], 10, 64)
+		if e != nil {
+			return nil, 0, false, probe.NewError(e).Trace(sourceURL.String())
+		}
+		decompressed, e := decompressReader(hookreader.NewHook(reader, progress), encoding)
+		if e != nil {
+			return nil, 0, false, probe.NewError(e).Trace(sourceURL.String())
+		}
+		delete(metadata, "Content-Encoding")
+		delete(metadata, compressSizeMetaKey)
+		return transcodeReadCloser{decompressed, reader}, size, true, nil
+	}
+	return reader, length, false, nil
+}
+
+// isCompressedCopy tells whether the listed target object is the
+// compressed upload of a source object of the given size, which mirror
+// must not consider as differing.
+func isCompressedCopy(target *ClientContent, size int64) bool {
+	if target == nil {
+		return false
+	}
+	recorded := target.UserMetadata[compressSizeMetaKey]
+	if recorded == "" {
+		recorded = target.Metadata[compressSizeMetaKey]
+	}
+	return recorded == strconv.FormatInt(size, 10)
+}
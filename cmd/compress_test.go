@@ -0,0 +1,146 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestCompressReader(t *testing.T) {
+	data := []byte(strings.Repeat("mc compresses uploads on the fly\n", 1000))
+	for _, algo := range []string{"zstd", "gzip"} {
+		compressed, err := ioutil.ReadAll(compressReader(bytes.NewReader(data), algo))
+		if err != nil {
+			t.Fatalf("%s: %v", algo, err)
+		}
+		if len(compressed) >= len(data) {
+			t.Errorf("%s: expected compressed size below %d, got %d", algo, len(data), len(compressed))
+		}
+		reader, err := decompressReader(bytes.NewReader(compressed), algo)
+		if err != nil {
+			t.Fatalf("%s: %v", algo, err)
+		}
+		got, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("%s: %v", algo, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s: decompressed data differs from the original", algo)
+		}
+	}
+}
+
+func TestTranscodeStream(t *testing.T) {
+	defer func(algo string, extensions, exclude []string) {
+		globalCompress, globalCompressExtensions, globalCompressExclude = algo, extensions, exclude
+	}(globalCompress, globalCompressExtensions, globalCompressExclude)
+	globalCompress, globalCompressExtensions, globalCompressExclude = "zstd", defaultCompressExtensions, []string{"audit-*"}
+
+	data := []byte("hello world")
+	local := *newClientURL("/tmp/logs/app.log")
+	remote := ClientURL{Type: objectStorage, Path: "/bucket/app.log"}
+
+	testCases := []struct {
+		source, target ClientURL
+		metadata       map[string]string
+		transcoded     bool
+	}{
+		{local, remote, map[string]string{}, true},
+		{*newClientURL("/tmp/logs/app.log.gz"), remote, map[string]string{}, false},
+		{*newClientURL("/tmp/logs/app.jpg"), remote, map[string]string{}, false},
+		{*newClientURL("/tmp/logs/audit-1.log"), remote, map[string]string{}, false},
+		{local, remote, map[string]string{"Content-Encoding": "gzip"}, false},
+		{remote, local, map[string]string{}, false},
+		{local, local, map[string]string{}, false},
+	}
+	for i, testCase := range testCases {
+		reader, length, transcoded, err := transcodeStream(ioutil.NopCloser(bytes.NewReader(data)), int64(len(data)),
+			testCase.source, testCase.target, testCase.metadata, nil)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if transcoded != testCase.transcoded {
+			t.Errorf("Test %d: expected transcoded %v, got %v", i+1, testCase.transcoded, transcoded)
+		}
+		if !transcoded {
+			continue
+		}
+		compressed, e := ioutil.ReadAll(reader)
+		if e != nil {
+			t.Fatalf("Test %d: %v", i+1, e)
+		}
+		if length != int64(len(compressed)) || testCase.metadata[compressSizeMetaKey] != "11" {
+			t.Errorf("Test %d: expected length %d and the original size recorded, got %d and %v", i+1, len(compressed), length, testCase.metadata)
+		}
+		reader.Close()
+	}
+}
+
+func TestCompressToSpool(t *testing.T) {
+	// Incompressible data larger than the memory limit is spooled to disk.
+	data := make([]byte, compressMemoryLimit+1<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+	for _, size := range []int{1 << 10, len(data)} {
+		reader, length, err := compressToSpool(bytes.NewReader(data[:size]), "gzip")
+		if err != nil {
+			t.Fatalf("%d: %v", size, err)
+		}
+		_, onDisk := reader.(tempFileReader)
+		if onDisk != (size > compressMemoryLimit) {
+			t.Errorf("%d: expected spooled to disk %v, got %v", size, size > compressMemoryLimit, onDisk)
+		}
+		compressed, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("%d: %v", size, err)
+		}
+		reader.Close()
+		if int64(len(compressed)) != length {
+			t.Errorf("%d: expected length %d, got %d", size, len(compressed), length)
+		}
+		decompressed, err := decompressReader(bytes.NewReader(compressed), "gzip")
+		if err != nil {
+			t.Fatalf("%d: %v", size, err)
+		}
+		got, err := ioutil.ReadAll(decompressed)
+		if err != nil || !bytes.Equal(got, data[:size]) {
+			t.Errorf("%d: decompressed data differs from the original: %v", size, err)
+		}
+	}
+}
+
+func TestIsCompressedCopy(t *testing.T) {
+	testCases := []struct {
+		target   *ClientContent
+		expected bool
+	}{
+		{nil, false},
+		{&ClientContent{}, false},
+		{&ClientContent{UserMetadata: map[string]string{compressSizeMetaKey: "11"}}, true},
+		{&ClientContent{Metadata: map[string]string{compressSizeMetaKey: "11"}}, true},
+		{&ClientContent{UserMetadata: map[string]string{compressSizeMetaKey: "12"}}, false},
+	}
+	for i, testCase := range testCases {
+		if got := isCompressedCopy(testCase.target, 11); got != testCase.expected {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
@@ -32,6 +32,7 @@ var configCmd = cli.Command{
 	Flags:           globalFlags,
 	Subcommands: []cli.Command{
 		configHostCmd,
+		configFeaturesCmd,
 	},
 }
 
@@ -69,6 +70,7 @@ var configHostAddCmd = cli.Command{
 	ShortName: "a",
 	Usage:     "add a new host to configuration file",
 	Action: func(cli *cli.Context) error {
+		checkFeature("config-host")
 		return mainAliasSet(cli, true)
 	},
 	Before:          setGlobalsFromContext,
@@ -81,6 +83,7 @@ var configHostListCmd = cli.Command{
 	ShortName: "ls",
 	Usage:     "list hosts in configuration file",
 	Action: func(cli *cli.Context) error {
+		checkFeature("config-host")
 		return mainAliasList(cli, true)
 	},
 	Before:          setGlobalsFromContext,
@@ -93,6 +96,7 @@ var configHostRemoveCmd = cli.Command{
 	ShortName: "rm",
 	Usage:     "remove a host from configuration file",
 	Action: func(cli *cli.Context) error {
+		checkFeature("config-host")
 		return mainAliasRemove(cli, true)
 	},
 	Before:          setGlobalsFromContext,
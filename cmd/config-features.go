@@ -0,0 +1,229 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var configFeaturesCmd = cli.Command{
+	Name:  "features",
+	Usage: "enable or disable experimental and deprecated features",
+	Action: func(ctx *cli.Context) error {
+		cli.ShowCommandHelp(ctx, ctx.Args().First())
+		return nil
+	},
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	HideHelpCommand: true,
+	Subcommands: []cli.Command{
+		configFeaturesListCmd,
+		configFeaturesEnableCmd,
+		configFeaturesDisableCmd,
+	},
+}
+
+var configFeaturesListCmd = cli.Command{
+	Name:         "ls",
+	Usage:        "list experimental and deprecated features",
+	Action:       mainConfigFeaturesList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}}
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+ENVIRONMENT VARIABLES:
+  MC_FEATURES:  comma separated features to enable, or to disable when prefixed with '-', for one command
+
+EXAMPLES:
+  1. List the features and whether they are enabled.
+     {{.Prompt}} {{.HelpName}}
+`,
+}
+
+var configFeaturesEnableCmd = cli.Command{
+	Name:         "enable",
+	Usage:        "enable experimental or deprecated features",
+	Action:       mainConfigFeaturesEnable,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} FEATURE [FEATURE...]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Enable the experimental two-way mirror.
+     {{.Prompt}} {{.HelpName}} two-way-mirror
+
+  2. Run a single command with the experimental background jobs, without enabling them.
+     {{.Prompt}} MC_FEATURES=background-jobs mc job start mirror s3/photos/ play/photos/
+`,
+}
+
+var configFeaturesDisableCmd = cli.Command{
+	Name:         "disable",
+	Usage:        "disable experimental or deprecated features",
+	Action:       mainConfigFeaturesDisable,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} FEATURE [FEATURE...]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Disable the deprecated 'mc mirror --force', to make sure no script still uses it.
+     {{.Prompt}} {{.HelpName}} mirror-force
+`,
+}
+
+// featureMessage container for a feature.
+type featureMessage struct {
+	Status      string `json:"status"`
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	Enabled     bool   `json:"enabled"`
+	Usage       string `json:"usage"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+func (f featureMessage) String() string {
+	enabled := console.Colorize("FeatureDisabled", "disabled")
+	if f.Enabled {
+		enabled = console.Colorize("FeatureEnabled", "enabled ")
+	}
+	usage := f.Usage
+	if f.Replacement != "" {
+		usage += ", replaced by " + f.Replacement
+	}
+	return fmt.Sprintf("%s %-12s %s %s", console.Colorize("Feature", fmt.Sprintf("%-16s", f.Name)), f.State, enabled, usage)
+}
+
+func (f featureMessage) JSON() string {
+	f.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(f, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// featureSetMessage is printed when a feature is enabled or disabled.
+type featureSetMessage struct {
+	Status  string `json:"status"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (f featureSetMessage) String() string {
+	if f.Enabled {
+		return fmt.Sprintf("Enabled feature `%s`.", console.Colorize("Feature", f.Name))
+	}
+	return fmt.Sprintf("Disabled feature `%s`.", console.Colorize("Feature", f.Name))
+}
+
+func (f featureSetMessage) JSON() string {
+	f.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(f, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// setFeatureColors sets the colors of the feature messages.
+func setFeatureColors() {
+	console.SetColor("Feature", color.New(color.Bold))
+	console.SetColor("FeatureEnabled", color.New(color.FgGreen))
+	console.SetColor("FeatureDisabled", color.New(color.FgRed))
+}
+
+func mainConfigFeaturesList(cliCtx *cli.Context) error {
+	if len(cliCtx.Args()) != 0 {
+		cli.ShowCommandHelpAndExit(cliCtx, "ls", 1) // last argument is exit code
+	}
+	setFeatureColors()
+
+	for _, f := range mcFeatures {
+		printMsg(featureMessage{
+			Name:        f.name,
+			State:       f.state,
+			Enabled:     isFeatureEnabled(f),
+			Usage:       f.usage,
+			Replacement: f.replacement,
+		})
+	}
+	return nil
+}
+
+func mainConfigFeaturesEnable(cliCtx *cli.Context) error {
+	return setFeatures(cliCtx, "enable", true)
+}
+
+func mainConfigFeaturesDisable(cliCtx *cli.Context) error {
+	return setFeatures(cliCtx, "disable", false)
+}
+
+// setFeatures enables or disables the features passed as arguments in the config file.
+func setFeatures(cliCtx *cli.Context, cmdName string, enabled bool) error {
+	if len(cliCtx.Args()) == 0 {
+		cli.ShowCommandHelpAndExit(cliCtx, cmdName, 1) // last argument is exit code
+	}
+	setFeatureColors()
+
+	for _, name := range cliCtx.Args() {
+		if _, ok := findFeature(name); !ok {
+			fatalIf(errInvalidArgument().Trace(name), "Unknown feature `"+name+"`, see `mc config features ls`.")
+		}
+	}
+
+	mcCfg, err := loadMcConfig()
+	fatalIf(err.Trace(globalMCConfigVersion), "Unable to load config `"+mustGetMcConfigPath()+"`.")
+	if mcCfg.Features == nil {
+		mcCfg.Features = make(map[string]bool)
+	}
+	for _, name := range cliCtx.Args() {
+		mcCfg.Features[name] = enabled
+	}
+	fatalIf(saveMcConfig(mcCfg).Trace(), "Unable to save config `"+mustGetMcConfigPath()+"`.")
+
+	for _, name := range cliCtx.Args() {
+		printMsg(featureSetMessage{Name: name, Enabled: enabled})
+	}
+	return nil
+}
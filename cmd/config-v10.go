@@ -46,12 +46,54 @@ type aliasConfigV10 struct {
 	Path         string `json:"path"`
 	License      string `json:"license,omitempty"`
 	APIKey       string `json:"apiKey,omitempty"`
+	Region       string `json:"region,omitempty"`
+
+	// KES encrypting the secret key, stored as ciphertext when set.
+	KES *aliasKESConfig `json:"kes,omitempty"`
+
+	// Other endpoints serving the alias and how requests are spread
+	// across them, 'failover' or 'round-robin'.
+	Endpoints      []aliasEndpointConfig `json:"endpoints,omitempty"`
+	EndpointPolicy string                `json:"endpointPolicy,omitempty"`
+
+	// Health of the endpoints, set when the alias is used.
+	endpointSet *aliasEndpointSet
 }
 
 // configV10 config version.
 type configV10 struct {
 	Version string                    `json:"version"`
 	Aliases map[string]aliasConfigV10 `json:"aliases"`
+
+	// Experimental and deprecated features enabled or disabled by the user.
+	Features map[string]bool `json:"features,omitempty"`
+
+	// Command run when a long running command completes.
+	OnComplete *onCompleteV10 `json:"onComplete,omitempty"`
+
+	// Files skipped by cp and mirror unless the command line says otherwise.
+	Exclude *excludeV10 `json:"exclude,omitempty"`
+
+	// Search predicates of mc find saved by name.
+	FindProfiles map[string]findProfileV10 `json:"findProfiles,omitempty"`
+}
+
+// excludeV10 sets the defaults of the --exclude-hidden and
+// --exclude-system flags.
+type excludeV10 struct {
+	Hidden bool `json:"hidden,omitempty"`
+	System bool `json:"system,omitempty"`
+}
+
+// findProfileV10 holds the values of the find flags of a saved
+// profile, by flag name.
+type findProfileV10 map[string][]string
+
+// onCompleteV10 is the hook run with a JSON summary on its standard
+// input when a command running longer than After completes.
+type onCompleteV10 struct {
+	Command string `json:"command"`
+	After   string `json:"after,omitempty"`
 }
 
 // newConfigV10 - new config version.
@@ -195,6 +195,14 @@ func getAliasConfig(alias string) (*aliasConfigV10, *probe.Error) {
 // mustGetHostConfig retrieves host specific configuration such as access keys, signature type.
 func mustGetHostConfig(alias string) *aliasConfigV10 {
 	aliasCfg, _ := getAliasConfig(alias)
+	if aliasCfg != nil && aliasCfg.KES != nil {
+		err := kesDecryptSecretKey(globalContext, aliasCfg)
+		fatalIf(err.Trace(alias), "Unable to decrypt the secret key of `"+alias+"` with KES.")
+	}
+	if aliasCfg != nil && len(aliasCfg.Endpoints) > 0 {
+		aliasCfg.endpointSet = getAliasEndpointSet(globalContext, alias, aliasCfg)
+		aliasCfg.URL = selectAliasEndpoint(globalContext, alias, aliasCfg)
+	}
 	// If alias is not found,
 	// look for it in the environment variable.
 	if aliasCfg == nil {
@@ -211,10 +219,23 @@ var (
 	hostKeyTokens = regexp.MustCompile("^(https?://)(.*?):(.*?):(.*)@(.*?)$")
 )
 
+// unescapeUserInfo decodes a credential of a MC_HOST_<alias> value marked
+// as escaped, the credentials which are not validly escaped are kept as is.
+func unescapeUserInfo(s string) string {
+	if u, e := url.PathUnescape(s); e == nil {
+		return u
+	}
+	return s
+}
+
 // parse url usually obtained from env.
 func parseEnvURLStr(envURL string) (*url.URL, string, string, string, *probe.Error) {
 	var accessKey, secretKey, sessionToken string
 	var parsedURL string
+	// Credentials are taken as is unless marked as escaped, they may
+	// contain a literal '%'.
+	escaped := strings.HasSuffix(envURL, mcEnvHostEscaped)
+	envURL = strings.TrimSuffix(envURL, mcEnvHostEscaped)
 	if hostKeyTokens.MatchString(envURL) {
 		parts := hostKeyTokens.FindStringSubmatch(envURL)
 		if len(parts) != 6 {
@@ -233,6 +254,11 @@ func parseEnvURLStr(envURL string) (*url.URL, string, string, string, *probe.Err
 		secretKey = parts[3]
 		parsedURL = fmt.Sprintf("%s%s", parts[1], parts[4])
 	}
+	if escaped {
+		accessKey = unescapeUserInfo(accessKey)
+		secretKey = unescapeUserInfo(secretKey)
+		sessionToken = unescapeUserInfo(sessionToken)
+	}
 	var u *url.URL
 	var e error
 	if parsedURL != "" {
@@ -261,6 +287,9 @@ func parseEnvURLStr(envURL string) (*url.URL, string, string, string, *probe.Err
 const (
 	mcEnvHostPrefix = "MC_HOST_"
 	mcEnvConfigFile = "MC_CONFIG_ENV_FILE"
+	// Suffix of the MC_HOST_<alias> values whose credentials are
+	// URL escaped, e.g. by 'mc share alias'.
+	mcEnvHostEscaped = "#escaped"
 )
 
 var aliasToConfigMap = make(map[string]*aliasConfigV10)
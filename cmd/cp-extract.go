@@ -0,0 +1,323 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// archiveEntryName cleans the name of an archive entry so that it
+// always stays below the target prefix, it returns false for entries
+// pointing outside of the archive.
+func archiveEntryName(name string) (string, bool) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	for _, elem := range strings.Split(name, "/") {
+		if elem == ".." {
+			return "", false
+		}
+	}
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" || name == "." {
+		return "", false
+	}
+	return name, true
+}
+
+// newTarReader detects the compression of a tar stream from its
+// leading magic bytes and returns a reader over the plain tar.
+func newTarReader(reader io.Reader) (*tar.Reader, error) {
+	br := bufio.NewReader(reader)
+	magic, e := br.Peek(4)
+	if e != nil && e != io.EOF {
+		return nil, e
+	}
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gr, e := gzip.NewReader(br)
+		if e != nil {
+			return nil, e
+		}
+		return tar.NewReader(gr), nil
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, e := zstd.NewReader(br)
+		if e != nil {
+			return nil, e
+		}
+		return tar.NewReader(zr.IOReadCloser()), nil
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		return tar.NewReader(bzip2.NewReader(br)), nil
+	}
+	return tar.NewReader(br), nil
+}
+
+// archiveEntry describes a file or folder stored in an archive.
+type archiveEntry struct {
+	Name    string
+	Size    int64
+	Mode    os.FileMode
+	ModTime time.Time
+}
+
+// walkTar calls fn with each entry of the tar stream and its content.
+func walkTar(reader io.Reader, fn func(archiveEntry, io.Reader) *probe.Error) *probe.Error {
+	tr, e := newTarReader(reader)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	for {
+		hdr, e := tr.Next()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return probe.NewError(e)
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeDir {
+			continue
+		}
+		entry := archiveEntry{Name: hdr.Name, Size: hdr.Size, Mode: hdr.FileInfo().Mode(), ModTime: hdr.ModTime}
+		if err := fn(entry, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// walkZip calls fn with each entry of the zip archive and its content,
+// zip needs random access since its index is stored at the end of the
+// archive, only the index and the entries read by fn are fetched. The
+// entries are not opened when listOnly is set, fn gets a nil reader.
+func walkZip(reader io.ReaderAt, size int64, listOnly bool, fn func(archiveEntry, io.Reader) *probe.Error) *probe.Error {
+	zr, e := zip.NewReader(reader, size)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if !mode.IsRegular() && !mode.IsDir() {
+			continue
+		}
+		entry := archiveEntry{Name: f.Name, Size: int64(f.UncompressedSize64), Mode: mode, ModTime: f.Modified}
+		if listOnly {
+			if err := fn(entry, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		rc, e := f.Open()
+		if e != nil {
+			return probe.NewError(e).Trace(f.Name)
+		}
+		err := fn(entry, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkArchive reads the tar or zip archive at sourceURL, '-' being
+// stdin, and calls fn with each of its entries. Only the index of zip
+// archives is read when listOnly is set.
+func walkArchive(ctx context.Context, sourceURL string, isZip, listOnly bool, encKeyDB map[string][]prefixSSEPair, fn func(archiveEntry, io.Reader) *probe.Error) *probe.Error {
+	if sourceURL == "-" {
+		if isZip {
+			return probe.NewError(errors.New("zip archives cannot be read from stdin, their index is stored at the end of the archive"))
+		}
+		return walkTar(os.Stdin, fn)
+	}
+
+	_, content, err := url2Stat(ctx, sourceURL, "", false, encKeyDB, time.Time{})
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	if content.Type.IsDir() {
+		return errInvalidArgument().Trace(sourceURL)
+	}
+
+	reader, err := getSourceStreamFromURL(ctx, sourceURL, "", encKeyDB)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	defer reader.Close()
+
+	if isZip {
+		readerAt, ok := reader.(io.ReaderAt)
+		if !ok {
+			return probe.NewError(errors.New("source does not support random access")).Trace(sourceURL)
+		}
+		return walkZip(readerAt, content.Size, listOnly, fn)
+	}
+	return walkTar(reader, fn)
+}
+
+// archiveExtractor copies the files of an archive one by one to the
+// target prefix, either objects or local files.
+type archiveExtractor struct {
+	sourceURL, targetURL string
+	encKeyDB             map[string][]prefixSSEPair
+	opts                 PutOptions
+	pg                   ProgressReader
+	totalSize            int64
+	totalCount           int64
+}
+
+// put copies a single archive entry.
+func (x *archiveExtractor) put(ctx context.Context, entry archiveEntry, reader io.Reader) *probe.Error {
+	if entry.Mode.IsDir() {
+		return nil
+	}
+	entryName, ok := archiveEntryName(entry.Name)
+	if !ok {
+		return errInvalidArgument().Trace(x.sourceURL, entry.Name)
+	}
+	entryURL := urlJoinPath(x.targetURL, entryName)
+	alias, urlStr, _, err := expandAlias(entryURL)
+	if err != nil {
+		return err.Trace(entryURL)
+	}
+
+	opts := x.opts
+	opts.sse = getSSE(entryURL, x.encKeyDB[alias])
+	opts.metadata = make(map[string]string, len(x.opts.metadata))
+	for k, v := range x.opts.metadata {
+		opts.metadata[k] = v
+	}
+
+	x.totalCount++
+	x.totalSize += entry.Size
+	x.pg.SetTotal(x.totalSize)
+	x.printMsg(copyMessage{
+		Source:     x.sourceURL + "/" + entryName,
+		Target:     entryURL,
+		Size:       entry.Size,
+		TotalCount: x.totalCount,
+		TotalSize:  x.totalSize,
+	})
+
+	if _, err = putTargetStream(ctx, alias, urlStr, "", "", "", reader, entry.Size, x.pg, opts); err != nil {
+		return err.Trace(entryURL)
+	}
+
+	// Extracted local files keep the permissions and the
+	// modification time recorded in the archive.
+	if newClientURL(urlStr).Type == fileSystem {
+		if e := os.Chmod(urlStr, entry.Mode.Perm()); e != nil {
+			return probe.NewError(e).Trace(urlStr)
+		}
+		if !entry.ModTime.IsZero() {
+			if e := os.Chtimes(urlStr, entry.ModTime, entry.ModTime); e != nil {
+				return probe.NewError(e).Trace(urlStr)
+			}
+		}
+	}
+	return nil
+}
+
+func (x *archiveExtractor) printMsg(msg message) {
+	if progressReader, ok := x.pg.(*progressBar); ok {
+		console.Eraseline()
+		printMsg(msg)
+		progressReader.Update()
+		return
+	}
+	printMsg(msg)
+}
+
+// checkCopyExtractSyntax validates the arguments of cp --untar and --unzip.
+func checkCopyExtractSyntax(cliCtx *cli.Context) {
+	args := cliCtx.Args()
+	if len(args) != 2 {
+		cli.ShowCommandHelpAndExit(cliCtx, "cp", 1) // last argument is exit code.
+	}
+	if cliCtx.Bool("untar") && cliCtx.Bool("unzip") {
+		fatalIf(errInvalidArgument().Trace(args...), "`--untar` cannot be used with `--unzip`.")
+	}
+	for _, flag := range []string{"recursive", "files-from", "continue", "resume", "rewind", "version-id", "preserve", "metadata-file"} {
+		if cliCtx.IsSet(flag) {
+			fatalIf(errInvalidArgument().Trace(args...), "`--untar` and `--unzip` cannot be used with `--"+flag+"`.")
+		}
+	}
+	if cliCtx.Bool("unzip") && args[0] == "-" {
+		fatalIf(errInvalidArgument().Trace(args...), "zip archives cannot be read from stdin, their index is stored at the end of the archive.")
+	}
+}
+
+// copyExtract extracts the archive SOURCE into objects or local files
+// below TARGET, streaming it without temporary files.
+func copyExtract(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair, userMetaMap map[string]string) error {
+	checkCopyExtractSyntax(cliCtx)
+	args := cliCtx.Args()
+
+	x := &archiveExtractor{
+		sourceURL: args[0],
+		targetURL: args[1],
+		encKeyDB:  encKeyDB,
+		opts: PutOptions{
+			metadata:         userMetaMap,
+			storageClass:     cliCtx.String("storage-class"),
+			disableMultipart: cliCtx.Bool("disable-multipart"),
+			md5:              cliCtx.Bool("md5"),
+			multipartSize:    globalMultipartSize,
+			multipartThreads: globalMultipartThreads,
+		},
+	}
+	if !globalQuiet && !globalJSON {
+		x.pg = newProgressBar(0)
+	} else {
+		x.pg = newAccounter(0)
+	}
+
+	err := walkArchive(ctx, x.sourceURL, cliCtx.Bool("unzip"), false, encKeyDB, func(entry archiveEntry, reader io.Reader) *probe.Error {
+		return x.put(ctx, entry, reader)
+	})
+
+	if progressReader, ok := x.pg.(*progressBar); ok {
+		if progressReader.ProgressBar.Get() > 0 {
+			progressReader.ProgressBar.Finish()
+		}
+	} else if accntReader, ok := x.pg.(*accounter); ok {
+		printMsg(accntReader.Stat())
+	}
+
+	if err != nil {
+		if !globalQuiet && !globalJSON {
+			console.Eraseline()
+		}
+		errorIf(err.Trace(x.sourceURL), "Unable to extract `"+x.sourceURL+"`.")
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
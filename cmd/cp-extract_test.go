@@ -0,0 +1,191 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestArchiveEntryName(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{"a.txt", "a.txt", true},
+		{"dir/a.txt", "dir/a.txt", true},
+		{"./dir//a.txt", "dir/a.txt", true},
+		{"/etc/passwd", "etc/passwd", true},
+		{"dir\\a.txt", "dir/a.txt", true},
+		{"../a.txt", "", false},
+		{"dir/../../a.txt", "", false},
+		{"dir\\..\\a.txt", "", false},
+		{".", "", false},
+		{"", "", false},
+	}
+	for i, testCase := range testCases {
+		name, ok := archiveEntryName(testCase.name)
+		if name != testCase.expected || ok != testCase.ok {
+			t.Errorf("Test %d: expected (%q, %v), got (%q, %v)", i+1, testCase.expected, testCase.ok, name, ok)
+		}
+	}
+}
+
+func TestNewTarReader(t *testing.T) {
+	var plain bytes.Buffer
+	tw := tar.NewWriter(&plain)
+	data := []byte("hello world")
+	if err := tw.WriteHeader(&tar.Header{Name: "dir/hello.txt", Mode: 0o644, Size: int64(len(data))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write(plain.Bytes())
+	gw.Close()
+
+	var zstded bytes.Buffer
+	zw, err := zstd.NewWriter(&zstded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw.Write(plain.Bytes())
+	zw.Close()
+
+	for name, archive := range map[string][]byte{"tar": plain.Bytes(), "gzip": gzipped.Bytes(), "zstd": zstded.Bytes()} {
+		tr, err := newTarReader(bytes.NewReader(archive))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		hdr, err := tr.Next()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if hdr.Name != "dir/hello.txt" {
+			t.Errorf("%s: expected entry dir/hello.txt, got %s", name, hdr.Name)
+		}
+		got, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("%s: expected %q, got %q", name, data, got)
+		}
+	}
+}
+
+func TestWalkZip(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	if _, err := zw.Create("dir/"); err != nil {
+		t.Fatal(err)
+	}
+	w, err := zw.Create("dir/hello.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("hello world"))
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	var content []byte
+	werr := walkZip(bytes.NewReader(archive.Bytes()), int64(archive.Len()), false, func(entry archiveEntry, reader io.Reader) *probe.Error {
+		names = append(names, entry.Name)
+		if !entry.Mode.IsDir() {
+			content, _ = ioutil.ReadAll(reader)
+		}
+		return nil
+	})
+	if werr != nil {
+		t.Fatal(werr)
+	}
+	if !reflect.DeepEqual(names, []string{"dir/", "dir/hello.txt"}) {
+		t.Errorf("expected entries [dir/ dir/hello.txt], got %v", names)
+	}
+	if string(content) != "hello world" {
+		t.Errorf("expected content %q, got %q", "hello world", content)
+	}
+}
+
+// zipReadTracker records the offsets read from a zip archive.
+type zipReadTracker struct {
+	*bytes.Reader
+	offsets []int64
+}
+
+func (z *zipReadTracker) ReadAt(p []byte, off int64) (int, error) {
+	z.offsets = append(z.offsets, off)
+	return z.Reader.ReadAt(p, off)
+}
+
+func TestWalkZipListOnly(t *testing.T) {
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		// Stored uncompressed for the index to be far from the entries.
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(bytes.Repeat([]byte(name), 1000))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := &zipReadTracker{Reader: bytes.NewReader(archive.Bytes())}
+	var names []string
+	werr := walkZip(reader, int64(archive.Len()), true, func(entry archiveEntry, r io.Reader) *probe.Error {
+		if r != nil {
+			t.Errorf("expected no reader for %s when listing", entry.Name)
+		}
+		names = append(names, entry.Name)
+		return nil
+	})
+	if werr != nil {
+		t.Fatal(werr)
+	}
+	if !reflect.DeepEqual(names, []string{"a.txt", "b.txt"}) {
+		t.Errorf("unexpected entries %v", names)
+	}
+	// The entries are stored first, only the index at the end is read.
+	for _, off := range reader.offsets {
+		if off == 0 {
+			t.Errorf("expected the entries not to be opened, read offsets %v", reader.offsets)
+			break
+		}
+	}
+}
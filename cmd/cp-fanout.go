@@ -0,0 +1,300 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// fanOutBufferSize is the size of the chunks read from the source
+// and handed to all the targets at once.
+const fanOutBufferSize = 1 << 20
+
+var errFanOutTargetsFailed = errors.New("all targets failed")
+
+// fanOutWriter writes the data read once from a source to several
+// targets concurrently, a target failing is dropped and the others
+// keep receiving the data.
+type fanOutWriter struct {
+	writers []io.Writer
+	errs    []error
+}
+
+func newFanOutWriter(writers ...io.Writer) *fanOutWriter {
+	return &fanOutWriter{writers: writers, errs: make([]error, len(writers))}
+}
+
+func (w *fanOutWriter) Write(p []byte) (int, error) {
+	var wg sync.WaitGroup
+	for i, writer := range w.writers {
+		if w.errs[i] != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, writer io.Writer) {
+			defer wg.Done()
+			n, e := writer.Write(p)
+			if e == nil && n < len(p) {
+				e = io.ErrShortWrite
+			}
+			w.errs[i] = e
+		}(i, writer)
+	}
+	wg.Wait()
+	for _, e := range w.errs {
+		if e == nil {
+			return len(p), nil
+		}
+	}
+	return 0, errFanOutTargetsFailed
+}
+
+// fanOutCopier copies each source object to all the targets, reading
+// it only once.
+type fanOutCopier struct {
+	encKeyDB   map[string][]prefixSSEPair
+	opts       PutOptions
+	pg         ProgressReader
+	totalSize  int64
+	totalCount int64
+}
+
+// copy streams the source of urls[0] to the target of every URLs, it
+// returns false when the copy failed for at least one of the targets.
+func (f *fanOutCopier) copy(ctx context.Context, urls []URLs) bool {
+	source := urls[0].SourceContent
+	sourceAlias := urls[0].SourceAlias
+	sourceURL := source.URL.String()
+	sourcePath := filepath.ToSlash(filepath.Join(sourceAlias, source.URL.Path))
+	displaySource := sourceAlias + source.URL.Path
+	if sourceAlias == "" {
+		displaySource = source.URL.Path
+	}
+
+	reader, metadata, err := getSourceStream(ctx, sourceAlias, sourceURL, "", true, getSSE(sourcePath, f.encKeyDB[sourceAlias]), false)
+	if err != nil {
+		errorIf(err.Trace(displaySource), "Unable to read `"+displaySource+"`.")
+		return false
+	}
+	defer reader.Close()
+	for k, v := range f.opts.metadata {
+		metadata[k] = v
+	}
+
+	f.totalSize += source.Size
+	f.pg.SetTotal(f.totalSize)
+
+	pipes := make([]*io.PipeWriter, len(urls))
+	writers := make([]io.Writer, len(urls))
+	errs := make([]*probe.Error, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		pr, pw := io.Pipe()
+		pipes[i], writers[i] = pw, pw
+
+		targetPath := filepath.ToSlash(filepath.Join(u.TargetAlias, u.TargetContent.URL.Path))
+		opts := f.opts
+		opts.sse = getSSE(targetPath, f.encKeyDB[u.TargetAlias])
+		opts.metadata = make(map[string]string, len(metadata))
+		for k, v := range metadata {
+			opts.metadata[k] = v
+		}
+
+		wg.Add(1)
+		go func(i int, targetAlias, targetURL string, opts PutOptions) {
+			defer wg.Done()
+			_, errs[i] = putTargetStream(ctx, targetAlias, targetURL, "", "", "", pr, source.Size, nil, opts)
+			if errs[i] != nil {
+				pr.CloseWithError(errs[i].ToGoError())
+				return
+			}
+			pr.Close()
+		}(i, u.TargetAlias, u.TargetContent.URL.String(), opts)
+	}
+
+	_, e := io.CopyBuffer(newFanOutWriter(writers...), io.TeeReader(reader, progressWriter{f.pg}), make([]byte, fanOutBufferSize))
+	for _, pw := range pipes {
+		pw.CloseWithError(e)
+	}
+	wg.Wait()
+
+	ok := true
+	for i, u := range urls {
+		displayTarget := u.TargetAlias + u.TargetContent.URL.Path
+		if errs[i] == nil && e != nil {
+			errs[i] = probe.NewError(e)
+		}
+		if errs[i] != nil {
+			ok = false
+			f.printError(errs[i].Trace(displaySource, displayTarget), "Unable to copy `"+displaySource+"` to `"+displayTarget+"`.")
+			continue
+		}
+		f.totalCount++
+		f.printMsg(copyMessage{
+			Source:     displaySource,
+			Target:     displayTarget,
+			Size:       source.Size,
+			TotalCount: f.totalCount,
+			TotalSize:  f.totalSize,
+		})
+	}
+	return ok
+}
+
+func (f *fanOutCopier) printMsg(msg message) {
+	if progressReader, ok := f.pg.(*progressBar); ok {
+		console.Eraseline()
+		printMsg(msg)
+		progressReader.Update()
+		return
+	}
+	printMsg(msg)
+}
+
+func (f *fanOutCopier) printError(err *probe.Error, msg string) {
+	if _, ok := f.pg.(*progressBar); ok {
+		console.Eraseline()
+	}
+	errorIf(err, msg)
+}
+
+// progressWriter advances a progress reader with the bytes written
+// to it, so the source is accounted once whatever the target count.
+type progressWriter struct {
+	pg ProgressReader
+}
+
+func (w progressWriter) Write(p []byte) (int, error) {
+	return w.pg.Read(p)
+}
+
+// fanOutURLs returns the URLs to copy the source object to each
+// target, recursive copies keep the layout below the source folder.
+func fanOutURLs(ctx context.Context, sourceAlias string, sourceURL ClientURL, content *ClientContent, targetURLs []string, isRecursive bool, encKeyDB map[string][]prefixSSEPair) []URLs {
+	urls := make([]URLs, len(targetURLs))
+	for i, targetURL := range targetURLs {
+		targetAlias, expandedURL, _ := mustExpandAlias(targetURL)
+		switch {
+		case isRecursive:
+			urls[i] = makeCopyContentTypeC(sourceAlias, sourceURL, content, targetAlias, expandedURL, encKeyDB)
+		case isAliasURLDir(ctx, targetURL, encKeyDB, time.Time{}):
+			urls[i] = makeCopyContentTypeB(sourceAlias, content, targetAlias, expandedURL, encKeyDB)
+		default:
+			urls[i] = makeCopyContentTypeA(sourceAlias, content, targetAlias, expandedURL, encKeyDB)
+		}
+	}
+	return urls
+}
+
+// checkCopyFanOutSyntax validates the arguments of cp --fan-out.
+func checkCopyFanOutSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
+	args := cliCtx.Args()
+	if len(args) < 3 {
+		cli.ShowCommandHelpAndExit(cliCtx, "cp", 1) // last argument is exit code.
+	}
+	for _, flag := range []string{"untar", "unzip", "files-from", "continue", "resume", "rewind", "version-id", "older-than", "newer-than", "preserve", "metadata-file", rmFlag, rdFlag, lhFlag} {
+		if cliCtx.IsSet(flag) {
+			fatalIf(errInvalidArgument().Trace(args...), "`--fan-out` cannot be used with `--"+flag+"`.")
+		}
+	}
+	for _, arg := range args {
+		if arg == "-" {
+			fatalIf(errInvalidArgument().Trace(args...), "`--fan-out` cannot read from stdin or write to stdout.")
+		}
+	}
+	if !cliCtx.Bool("recursive") {
+		_, content, err := url2Stat(ctx, args[0], "", false, encKeyDB, time.Time{})
+		fatalIf(err.Trace(args[0]), "Unable to stat source `"+args[0]+"`.")
+		if content.Type.IsDir() {
+			fatalIf(errSourceIsDir(args[0]).Trace(args[0]), "To copy a folder requires --recursive flag.")
+		}
+	}
+}
+
+// copyFanOut copies SOURCE to every TARGET, each source object is read
+// once and streamed to all the targets in parallel.
+func copyFanOut(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair, userMetaMap map[string]string) error {
+	checkCopyFanOutSyntax(ctx, cliCtx, encKeyDB)
+	args := cliCtx.Args()
+	sourceURL, targetURLs := args[0], args[1:]
+	isRecursive := cliCtx.Bool("recursive")
+
+	f := &fanOutCopier{
+		encKeyDB: encKeyDB,
+		opts: PutOptions{
+			metadata:         userMetaMap,
+			storageClass:     cliCtx.String("storage-class"),
+			disableMultipart: cliCtx.Bool("disable-multipart"),
+			md5:              cliCtx.Bool("md5"),
+			multipartSize:    globalMultipartSize,
+			multipartThreads: globalMultipartThreads,
+		},
+	}
+	if !globalQuiet && !globalJSON {
+		f.pg = newProgressBar(0)
+	} else {
+		f.pg = newAccounter(0)
+	}
+
+	sourceAlias, _, _ := mustExpandAlias(sourceURL)
+	clnt, err := newClient(sourceURL)
+	fatalIf(err.Trace(sourceURL), "Unable to initialize `"+sourceURL+"`.")
+
+	failed := false
+	if !isRecursive {
+		_, content, err := url2Stat(ctx, sourceURL, "", false, encKeyDB, time.Time{})
+		fatalIf(err.Trace(sourceURL), "Unable to stat source `"+sourceURL+"`.")
+		failed = !f.copy(ctx, fanOutURLs(ctx, sourceAlias, clnt.GetURL(), content, targetURLs, false, encKeyDB))
+	} else {
+		for content := range clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+			if content.Err != nil {
+				f.printError(content.Err.Trace(sourceURL), "Unable to list `"+sourceURL+"`.")
+				failed = true
+				continue
+			}
+			if !content.Type.IsRegular() {
+				continue
+			}
+			if !f.copy(ctx, fanOutURLs(ctx, sourceAlias, clnt.GetURL(), content, targetURLs, true, encKeyDB)) {
+				failed = true
+			}
+		}
+	}
+
+	if progressReader, ok := f.pg.(*progressBar); ok {
+		if progressReader.ProgressBar.Get() > 0 {
+			progressReader.ProgressBar.Finish()
+		}
+	} else if accntReader, ok := f.pg.(*accounter); ok {
+		printMsg(accntReader.Stat())
+	}
+
+	if failed {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// failingWriter accepts limit bytes and fails afterwards.
+type failingWriter struct {
+	bytes.Buffer
+	limit int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > w.limit {
+		return 0, errors.New("target failed")
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestFanOutWriter(t *testing.T) {
+	data := strings.Repeat("0123456789", 10)
+	testCases := []struct {
+		limits   []int
+		expected []bool
+		fails    bool
+	}{
+		{[]int{1000}, []bool{true}, false},
+		{[]int{1000, 1000, 1000}, []bool{true, true, true}, false},
+		{[]int{1000, 15, 1000}, []bool{true, false, true}, false},
+		{[]int{5, 1000}, []bool{false, true}, false},
+		{[]int{5, 15}, []bool{false, false}, true},
+	}
+	for i, testCase := range testCases {
+		targets := make([]*failingWriter, len(testCase.limits))
+		writers := make([]io.Writer, len(testCase.limits))
+		for j, limit := range testCase.limits {
+			targets[j] = &failingWriter{limit: limit}
+			writers[j] = targets[j]
+		}
+		w := newFanOutWriter(writers...)
+		_, e := io.CopyBuffer(w, strings.NewReader(data), make([]byte, 10))
+		if (e != nil) != testCase.fails {
+			t.Errorf("Test %d: expected failure %v, got %v", i+1, testCase.fails, e)
+		}
+		for j, target := range targets {
+			if ok := w.errs[j] == nil && target.String() == data; ok != testCase.expected[j] {
+				t.Errorf("Test %d: target %d expected complete %v, got %v", i+1, j+1, testCase.expected[j], ok)
+			}
+		}
+	}
+}
@@ -21,11 +21,15 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	jsoniter "github.com/json-iterator/go"
@@ -70,13 +74,21 @@ var (
 			Name:  "attr",
 			Usage: "add custom metadata for the object",
 		},
+		cli.StringFlag{
+			Name:  "metadata-file",
+			Usage: "YAML file setting the content-type, cache-control and metadata of the objects matching each pattern",
+		},
 		cli.BoolFlag{
 			Name:  "continue, c",
 			Usage: "create or resume copy session",
 		},
+		cli.StringFlag{
+			Name:  "resume",
+			Usage: "resume the interrupted copy session with the given ID",
+		},
 		cli.BoolFlag{
 			Name:  "preserve, a",
-			Usage: "preserve filesystem attributes (mode, ownership, timestamps)",
+			Usage: "preserve filesystem attributes (mode, ownership, timestamps, symbolic links)",
 		},
 		cli.BoolFlag{
 			Name:  "disable-multipart",
@@ -102,9 +114,61 @@ var (
 			Name:  lhFlag,
 			Usage: "apply legal hold to the copied object (on, off)",
 		},
+		cli.BoolFlag{
+			Name:  "if-not-exists",
+			Usage: "copy only if the object does not exist on target",
+		},
+		cli.BoolFlag{
+			Name:  "if-newer",
+			Usage: "copy only if the source is newer than the object on target",
+		},
+		cli.BoolFlag{
+			Name:  "if-etag-differs",
+			Usage: "copy only if the source ETag differs from the object on target",
+		},
+		cli.StringSliceFlag{
+			Name:  "include-tag",
+			Usage: "copy only objects carrying the tag key[=value], can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude-tag",
+			Usage: "skip objects carrying the tag key[=value], can be repeated",
+		},
+		cli.BoolFlag{
+			Name:  "preflight",
+			Usage: "verify required permissions on source and target before copying",
+		},
+		checksumFlag,
+		cli.StringFlag{
+			Name:  "files-from",
+			Usage: "copy only the objects listed one per line in the file, relative to the source, '-' reads from stdin",
+		},
+		cli.StringFlag{
+			Name:  "lockfile",
+			Usage: "download the exact object versions pinned by 'mc lockfile create' into TARGET, verifying their checksums",
+		},
+		cli.BoolFlag{
+			Name:  "untar",
+			Usage: "extract the tar, tar.gz, tar.zst or tar.bz2 archive SOURCE into objects or files under TARGET, '-' reads it from stdin",
+		},
+		cli.BoolFlag{
+			Name:  "unzip",
+			Usage: "extract the zip archive SOURCE into objects or files under TARGET",
+		},
+		cli.BoolFlag{
+			Name:  "fan-out",
+			Usage: "copy SOURCE to every TARGET given after it, reading each object once and uploading it to all targets in parallel",
+		},
+		cli.BoolFlag{
+			Name:  "disable-server-copy",
+			Usage: "download and upload objects through mc even when source and target are on the same cluster",
+		},
 	}
 )
 
+// --include and --exclude patterns of cp.
+var cpGlobFilterFlags = append(newGlobFilterFlags(&globFilter{}), excludeDefaultFlags...)
+
 var rmFlag = "retention-mode"
 var rdFlag = "retention-duration"
 var lhFlag = "legal-hold"
@@ -119,12 +183,13 @@ var cpCmd = cli.Command{
 	Action:       mainCopy,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(cpFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(append(append(append(append(append(append(append(append(cpFlags, cpGlobFilterFlags...), sizeFilterFlags...), transferLimitFlags...), cpuLimitFlags...), walkFlags...), streamFlags...), multipartFlags...), compressFlags...), requestHeaderFlags...), retryFlags...), ioFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
 USAGE:
   {{.HelpName}} [FLAGS] SOURCE [SOURCE...] TARGET
+  {{.HelpName}} [FLAGS] --fan-out SOURCE TARGET [TARGET...]
 
 FLAGS:
   {{range .VisibleFlags}}{{.}}
@@ -195,6 +260,105 @@ EXAMPLES:
   20. Set tags to the uploaded objects
       {{.Prompt}} {{.HelpName}} -r --tags "category=prod&type=backup" ./data/ play/another-bucket/
 
+  21. Copy all objects of a bucket except the ones tagged with "retention=legal".
+      {{.Prompt}} {{.HelpName}} -r --exclude-tag "retention=legal" s3/mybucket/ play/migrated/
+
+  22. Verify the credentials allow reading the source and writing to the target before a long copy.
+      {{.Prompt}} {{.HelpName}} --preflight -r s3/mybucket/ play/backup/
+
+  23. Upload a file only if it is not already present on the target, the command can be safely retried.
+      {{.Prompt}} {{.HelpName}} --if-not-exists backup.tgz play/mybucket/
+
+  24. Copy only the objects which are newer than or differ from their counterpart on the target.
+      {{.Prompt}} {{.HelpName}} -r --if-newer --if-etag-differs s3/mybucket/ play/mybucket/
+
+  25. Resume an interrupted copy session by its ID, continuing partially uploaded objects.
+      {{.Prompt}} {{.HelpName}} --resume cp-4a1b0c9e2d8f7a6b5c4d3e2f1a0b9c8d
+
+  26. Copy a bucket to another site, limiting the bandwidth used by all parallel transfers.
+      {{.Prompt}} {{.HelpName}} -r --limit-download 100MiB/s --limit-upload 50MiB/s s3/mybucket/ play/mybucket/
+
+  27. Copy only the parquet files found under a deep prefix, the first matching pattern decides.
+      {{.Prompt}} {{.HelpName}} -r --include "*.parquet" --exclude "*" s3/datalake/2021/ ~/parquet/
+
+  28. Copy a folder except the objects under 'tmp/' and the hidden files.
+      {{.Prompt}} {{.HelpName}} -r --exclude "tmp/*" --exclude ".*" ~/project/ play/mybucket/project/
+
+  29. Copy a bucket to another site, objects are streamed between both sites without touching the
+      local disk, using at most 4 upload threads buffering parts of 32MiB each.
+      {{.Prompt}} {{.HelpName}} -r --part-size 32MiB s3/mybucket/ play/mybucket/
+
+  30. Copy a bucket to a slow target, downloading each object to a temporary file before uploading it.
+      {{.Prompt}} {{.HelpName}} -r --via-disk s3/mybucket/ archive/mybucket/
+
+  31. Copy the CSV files found by 'mc find' with a single command, keeping their path under the source.
+      {{.Prompt}} mc find s3/mybucket/logs/ --name "*.csv" | {{.HelpName}} --files-from - s3/mybucket/logs/ ~/logs/
+
+  32. Copy the objects listed in a file by their path relative to the source.
+      {{.Prompt}} {{.HelpName}} --files-from keys.txt s3/mybucket/ play/mybucket/
+
+  33. Copy a folder, having the server verify the MD5 checksum of each uploaded part.
+      {{.Prompt}} {{.HelpName}} -r --checksum md5 ~/backups/ play/backups/
+
+  34. Back up a folder to an object storage with its ownership, permissions and symbolic links, and restore it.
+      Symbolic links with an absolute target, or pointing outside of the restored folder, are not restored.
+      {{.Prompt}} {{.HelpName}} --recursive -a /etc/myapp/ play/backups/myapp/
+      {{.Prompt}} {{.HelpName}} --recursive -a play/backups/myapp/ /etc/myapp/
+
+  35. Copy a large file over a high-latency link, uploading 16 parts of 128MiB in parallel.
+      {{.Prompt}} {{.HelpName}} --part-size 128MiB --concurrent-parts 16 ~/videos/movie.mkv play/videos/
+
+  36. Copy a file served over HTTPS into a bucket, streaming it without writing it to local disk.
+      {{.Prompt}} {{.HelpName}} https://releases.ubuntu.com/22.04/ubuntu-22.04-live-server-amd64.iso play/isos/
+
+  37. Upload each file of a compressed tarball read from stdin, and of a zip archive, as individual objects.
+      {{.Prompt}} curl -s https://example.com/site.tar.gz | {{.HelpName}} --untar - play/www/
+      {{.Prompt}} {{.HelpName}} --unzip ~/Downloads/dataset.zip play/datasets/
+
+  38. Copy a folder of logs, compressing each file with zstd while uploading it, and download it back decompressed.
+      {{.Prompt}} {{.HelpName}} -r --compress zstd ~/logs/ play/logs/
+      {{.Prompt}} {{.HelpName}} -r play/logs/ ~/restored-logs/
+
+  39. Download a build artifact and extract it on the fly into a local folder.
+      {{.Prompt}} {{.HelpName}} --untar play/artifacts/release.tar.gz ./release/
+
+  40. Download an object from an AWS requester-pays bucket, the transfer is billed to your account.
+      {{.Prompt}} {{.HelpName}} --request-payer requester s3/open-dataset/2021/data.csv ~/data/
+
+  41. Copy a folder over a flaky link, retrying each failed object up to 10 times on network errors and throttling.
+      {{.Prompt}} {{.HelpName}} --recursive --retry 10 --retry-max-wait 2m --retry-on network,throttle ~/data/ s3/backups/data/
+
+  42. Copy a folder on a shared host using at most 8 CPUs of the first NUMA node.
+      {{.Prompt}} {{.HelpName}} --recursive --max-cpu 8 --cpu-affinity 0-31 ~/data/ s3/backups/data/
+
+  43. Copy a folder between two aliases of the same cluster through mc instead of a server side copy.
+      {{.Prompt}} {{.HelpName}} --recursive --disable-server-copy play/photos/ play-admin/archive/photos/
+
+  44. Copy only the media files larger than 100MiB from a folder.
+      {{.Prompt}} {{.HelpName}} --recursive --larger-than 100MiB ~/media/ s3/media/
+
+  45. Copy a local folder holding millions of files, reading 16 folders in parallel.
+      {{.Prompt}} {{.HelpName}} --recursive --walk-workers 16 /mnt/dataset/ s3/dataset/
+
+  46. Upload a static website, setting the content type and caching of each kind of file from a metadata file.
+      {{.Prompt}} cat site-metadata.yaml
+      - pattern: "*.html"
+        content-type: text/html; charset=utf-8
+        cache-control: no-cache
+      - pattern: "assets/*"
+        cache-control: public, max-age=31536000, immutable
+        metadata:
+          release: "2021.11"
+      {{.Prompt}} {{.HelpName}} --recursive --metadata-file site-metadata.yaml ~/site/public/ s3/www/
+
+  47. Seed the same dataset into three regions, reading each file once and uploading it to all of them in parallel.
+      {{.Prompt}} {{.HelpName}} --recursive --fan-out ~/dataset/ us-east/datasets/ eu-west/datasets/ ap-south/datasets/
+
+  48. Back up a home folder without its dotfiles and the files created by the operating system.
+      {{.Prompt}} {{.HelpName}} --recursive --exclude-hidden --exclude-system ~/ s3/backups/home/
+
+  49. Download in CI the exact versions of the build artifacts pinned by 'mc lockfile create', whatever changed in the bucket since.
+      {{.Prompt}} {{.HelpName}} --lockfile mc.lock ./artifacts/
 `,
 }
 
@@ -206,16 +370,24 @@ type copyMessage struct {
 	Size       int64  `json:"size"`
 	TotalCount int64  `json:"totalCount"`
 	TotalSize  int64  `json:"totalSize"`
+	Skipped    string `json:"skipped,omitempty"`
+	Event      string `json:"event,omitempty"`
 }
 
 // String colorized copy message
 func (c copyMessage) String() string {
+	if c.Skipped != "" {
+		return console.Colorize("Copy", fmt.Sprintf("`%s` -> `%s` skipped, %s", c.Source, c.Target, c.Skipped))
+	}
 	return console.Colorize("Copy", fmt.Sprintf("`%s` -> `%s`", c.Source, c.Target))
 }
 
 // JSON jsonified copy message
 func (c copyMessage) JSON() string {
 	c.Status = "success"
+	if c.Skipped != "" {
+		c.Status = "skipped"
+	}
 	copyMessageBytes, e := json.MarshalIndent(c, "", " ")
 	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
 
@@ -236,13 +408,202 @@ type ProgressReader interface {
 	Progress
 }
 
+// copyConditions holds the --if-* flags under which an object is copied.
+type copyConditions struct {
+	ifNotExists   bool
+	ifNewer       bool
+	ifETagDiffers bool
+}
+
+// copyFlags holds the flags applied to every copied object.
+type copyFlags struct {
+	storageClass      string
+	retentionMode     string
+	retentionDuration string
+	legalHold         string
+	tags              string
+	userMetadata      map[string]string
+	metadataMap       metadataMap
+	preserve          bool
+	md5               bool
+	disableMultipart  bool
+	conditions        copyConditions
+}
+
+func newCopyFlags(cliCtx *cli.Context) copyFlags {
+	userMetadata, _ := getMetaDataEntry(cliCtx.String("attr"))
+	return copyFlags{
+		metadataMap:       mustLoadMetadataFile(cliCtx.String("metadata-file")),
+		storageClass:      cliCtx.String("storage-class"),
+		retentionMode:     cliCtx.String(rmFlag),
+		retentionDuration: cliCtx.String(rdFlag),
+		legalHold:         cliCtx.String(lhFlag),
+		tags:              cliCtx.String("tags"),
+		userMetadata:      userMetadata,
+		preserve:          cliCtx.Bool("preserve"),
+		md5:               cliCtx.Bool("md5"),
+		disableMultipart:  cliCtx.Bool("disable-multipart"),
+		conditions: copyConditions{
+			ifNotExists:   cliCtx.Bool("if-not-exists"),
+			ifNewer:       cliCtx.Bool("if-newer"),
+			ifETagDiffers: cliCtx.Bool("if-etag-differs"),
+		},
+	}
+}
+
+func newCopyFlagsFromSession(session *sessionV8) copyFlags {
+	return copyFlags{
+		storageClass:      session.Header.CommandStringFlags["storage-class"],
+		retentionMode:     session.Header.CommandStringFlags[rmFlag],
+		retentionDuration: session.Header.CommandStringFlags[rdFlag],
+		legalHold:         session.Header.CommandStringFlags[lhFlag],
+		tags:              session.Header.CommandStringFlags["tags"],
+		userMetadata:      session.Header.UserMetaData,
+		metadataMap:       mustLoadMetadataFile(session.Header.CommandStringFlags["metadata-file"]),
+		preserve:          session.Header.CommandBoolFlags["preserve"],
+		md5:               session.Header.CommandBoolFlags["md5"],
+		disableMultipart:  session.Header.CommandBoolFlags["disable-multipart"],
+		conditions: copyConditions{
+			ifNotExists:   session.Header.CommandBoolFlags["if-not-exists"],
+			ifNewer:       session.Header.CommandBoolFlags["if-newer"],
+			ifETagDiffers: session.Header.CommandBoolFlags["if-etag-differs"],
+		},
+	}
+}
+
+// Flags setting the global transfer options, saved in copy sessions and
+// set again when they are resumed.
+var (
+	copySessionTransferFlags      = []string{"limit-upload", "limit-download", "part-size", "buffer-size", "concurrent-parts", "checksum", "compress", "retry", "retry-max-wait", "retry-on"}
+	copySessionTransferSliceFlags = []string{"compress-extensions", "compress-exclude"}
+	copySessionTransferBoolFlags  = []string{"via-disk", "disable-server-copy"}
+)
+
+// saveTransferFlags saves the transfer flags set on the command line in
+// the session.
+func saveTransferFlags(session *sessionV8, cliCtx *cli.Context) {
+	for _, name := range copySessionTransferFlags {
+		if cliCtx.IsSet(name) {
+			session.Header.CommandStringFlags[name] = cliCtx.String(name)
+		}
+	}
+	for _, name := range copySessionTransferSliceFlags {
+		if cliCtx.IsSet(name) {
+			session.Header.CommandStringFlags[name] = strings.Join(cliCtx.StringSlice(name), "\n")
+		}
+	}
+	for _, name := range copySessionTransferBoolFlags {
+		if cliCtx.IsSet(name) {
+			session.Header.CommandBoolFlags[name] = cliCtx.Bool(name)
+		}
+	}
+}
+
+// setTransferOptionsFromSession sets the global transfer options with
+// the flags saved in the session, those given when resuming it taking
+// precedence.
+func setTransferOptionsFromSession(session *sessionV8, cliCtx *cli.Context) {
+	set := flag.NewFlagSet("cp", flag.ContinueOnError)
+	for _, flags := range [][]cli.Flag{cpFlags, transferLimitFlags, streamFlags, multipartFlags, compressFlags, retryFlags} {
+		for _, f := range flags {
+			f.Apply(set)
+		}
+	}
+	setFlag := func(name, value string) {
+		fatalIf(probe.NewError(set.Set(name, value)).Trace(name, value), "Unable to restore the flags of the session.")
+	}
+	for _, name := range copySessionTransferFlags {
+		if cliCtx.IsSet(name) {
+			setFlag(name, cliCtx.String(name))
+		} else if value, ok := session.Header.CommandStringFlags[name]; ok {
+			setFlag(name, value)
+		}
+	}
+	for _, name := range copySessionTransferSliceFlags {
+		values := cliCtx.StringSlice(name)
+		if value, ok := session.Header.CommandStringFlags[name]; ok && !cliCtx.IsSet(name) {
+			values = strings.Split(value, "\n")
+		}
+		for _, value := range values {
+			setFlag(name, value)
+		}
+	}
+	for _, name := range copySessionTransferBoolFlags {
+		if cliCtx.IsSet(name) {
+			setFlag(name, strconv.FormatBool(cliCtx.Bool(name)))
+		} else if value, ok := session.Header.CommandBoolFlags[name]; ok {
+			setFlag(name, strconv.FormatBool(value))
+		}
+	}
+
+	transferCtx := cli.NewContext(nil, set, nil)
+	setTransferLimits(transferCtx)
+	setStreamOptions(transferCtx)
+	setMultipartOptions(transferCtx)
+	setChecksumOptions(transferCtx)
+	setCompressOptions(transferCtx)
+	setRetryPolicy(transferCtx)
+	setServerCopyOptions(transferCtx)
+}
+
+// isEmpty returns true if objects are copied unconditionally.
+func (c copyConditions) isEmpty() bool {
+	return !c.ifNotExists && !c.ifNewer && !c.ifETagDiffers
+}
+
+// skipReason compares the source with the object on target and returns
+// why the copy is to be skipped, an empty reason if it must be copied.
+func (c copyConditions) skipReason(ctx context.Context, cpURLs URLs, encKeyDB map[string][]prefixSSEPair) (string, *probe.Error) {
+	if c.isEmpty() || c.ifNotExists {
+		// With --if-not-exists the upload itself is conditional,
+		// there is no window for a concurrent writer as with a stat.
+		return "", nil
+	}
+	targetURL := cpURLs.TargetContent.URL.String()
+	if cpURLs.TargetAlias != "" {
+		targetURL = urlJoinPath(cpURLs.TargetAlias, cpURLs.TargetContent.URL.Path)
+	}
+	_, targetContent, err := url2Stat(ctx, targetURL, "", false, encKeyDB, time.Time{})
+	if err != nil {
+		switch err.ToGoError().(type) {
+		case ObjectMissing, PathNotFound, BucketDoesNotExist:
+			return "", nil
+		}
+		return "", err.Trace(targetURL)
+	}
+
+	if c.ifNewer && !cpURLs.SourceContent.Time.After(targetContent.Time) {
+		return "target is newer", nil
+	}
+	if c.ifETagDiffers {
+		sourceETag := strings.Trim(cpURLs.SourceContent.ETag, "\"")
+		if sourceETag != "" && sourceETag == strings.Trim(targetContent.ETag, "\"") {
+			return "target has the same ETag", nil
+		}
+	}
+	return "", nil
+}
+
 // doCopy - Copy a single file from source to destination
-func doCopy(ctx context.Context, cpURLs URLs, pg ProgressReader, encKeyDB map[string][]prefixSSEPair, isMvCmd bool, preserve bool) URLs {
+func doCopy(ctx context.Context, cpURLs URLs, pg ProgressReader, encKeyDB map[string][]prefixSSEPair, isMvCmd bool, preserve bool, conditions copyConditions) URLs {
 	if cpURLs.Error != nil {
 		cpURLs.Error = cpURLs.Error.Trace()
 		return cpURLs
 	}
 
+	skipped, err := conditions.skipReason(ctx, cpURLs, encKeyDB)
+	if err != nil {
+		return cpURLs.WithError(err)
+	}
+	if skipped != "" {
+		printCopySkipped(cpURLs, pg, skipped)
+		return doCopyFake(ctx, cpURLs, pg)
+	}
+	if conditions.ifNotExists {
+		// Refused by the target when the object exists.
+		ctx = withIfNotExists(ctx)
+	}
+
 	sourceAlias := cpURLs.SourceAlias
 	sourceURL := cpURLs.SourceContent.URL
 	targetAlias := cpURLs.TargetAlias
@@ -260,17 +621,55 @@ func doCopy(ctx context.Context, cpURLs URLs, pg ProgressReader, encKeyDB map[st
 			Size:       length,
 			TotalCount: cpURLs.TotalCount,
 			TotalSize:  cpURLs.TotalSize,
+			Event:      transferEventName("start"),
 		})
 	}
 
-	urls := uploadSourceToTargetURL(ctx, cpURLs, pg, encKeyDB, preserve)
-	if isMvCmd && urls.Error == nil {
+	progress := newObjectTransfer(cpURLs, pg)
+	var renamed bool
+	urls := retryTransfer(ctx, cpURLs, progress, func(msg message) {
+		if _, ok := pg.(*progressBar); ok {
+			console.Eraseline()
+		}
+		printMsg(msg)
+	}, func(progress io.Reader) URLs {
+		// Renaming would replace a target which must not be overwritten.
+		if isMvCmd && !conditions.ifNotExists && renameLocalFile(cpURLs) {
+			// Moved without copying the data, account for it anyway.
+			renamed = true
+			io.CopyN(ioutil.Discard, progress, length)
+			return cpURLs.WithError(nil)
+		}
+		return uploadSourceToTargetURL(ctx, cpURLs, progress, encKeyDB, preserve)
+	})
+	if conditions.ifNotExists && isErrPreconditionFailed(urls.Error) {
+		printCopySkipped(cpURLs, pg, "target exists")
+		urls.Error = nil
+	}
+	transferDone(progress, urls.Error)
+	if isMvCmd && urls.Error == nil && !renamed {
 		rmManager.add(ctx, sourceAlias, sourceURL.String())
 	}
 
 	return urls
 }
 
+// printCopySkipped prints the object not copied for the given reason.
+func printCopySkipped(cpURLs URLs, pg ProgressReader, reason string) {
+	if _, ok := pg.(*progressBar); ok {
+		return
+	}
+	printMsg(copyMessage{
+		Source:     filepath.ToSlash(filepath.Join(cpURLs.SourceAlias, cpURLs.SourceContent.URL.Path)),
+		Target:     filepath.ToSlash(filepath.Join(cpURLs.TargetAlias, cpURLs.TargetContent.URL.Path)),
+		Size:       cpURLs.SourceContent.Size,
+		TotalCount: cpURLs.TotalCount,
+		TotalSize:  cpURLs.TotalSize,
+		Skipped:    reason,
+		Event:      transferEventName("skip"),
+	})
+}
+
 // doCopyFake - Perform a fake copy to update the progress bar appropriately.
 func doCopyFake(ctx context.Context, cpURLs URLs, pg Progress) URLs {
 	if progressReader, ok := pg.(*progressBar); ok {
@@ -281,7 +680,8 @@ func doCopyFake(ctx context.Context, cpURLs URLs, pg Progress) URLs {
 }
 
 // doPrepareCopyURLs scans the source URL and prepares a list of objects for copying.
-func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy context.CancelFunc) (totalBytes, totalObjects int64) {
+// Objects which cannot be prepared are reported and skipped.
+func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy context.CancelFunc) (totalBytes, totalObjects int64, failed bool) {
 	// Separate source and target. 'cp' can take only one target,
 	// but any number of sources.
 	sourceURLs := session.Header.CommandArgs[:len(session.Header.CommandArgs)-1]
@@ -297,6 +697,13 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 	encrypt := session.Header.CommandStringFlags["encrypt"]
 	encKeyDB, err := parseAndValidateEncryptionKeys(encryptKeys, encrypt)
 	fatalIf(err, "Unable to parse encryption keys.")
+	tagFilter, err := newObjectTagFilter([]string{session.Header.CommandStringFlags["include-tag"]},
+		[]string{session.Header.CommandStringFlags["exclude-tag"]})
+	fatalIf(err, "Unable to parse tag filters.")
+	sizeFilter, err := newObjectSizeFilter(session.Header.CommandStringFlags["larger-than"],
+		session.Header.CommandStringFlags["smaller-than"])
+	fatalIf(err, "Unable to parse size filters.")
+	patternFilter := parseGlobFilter(session.Header.CommandStringFlags["glob-filter"])
 
 	// Create a session data file to store the processed URLs.
 	dataFP := session.NewDataWriter()
@@ -306,7 +713,20 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 		scanBar = scanBarFactory()
 	}
 
-	URLsCh := prepareCopyURLs(ctx, sourceURLs, targetURL, isRecursive, encKeyDB, olderThan, newerThan, parseRewindFlag(rewind), versionID)
+	var URLsCh chan URLs
+	if lockfileName := session.Header.CommandStringFlags["lockfile"]; lockfileName != "" {
+		l, err := readLockfile(lockfileName)
+		fatalIf(err.Trace(lockfileName), "Unable to read the lockfile.")
+		URLsCh, err = prepareCopyURLsFromLockfile(ctx, l, targetURL, encKeyDB)
+		fatalIf(err.Trace(lockfileName), "Unable to find the objects pinned by the lockfile.")
+	} else if filesFrom := session.Header.CommandStringFlags["files-from"]; filesFrom != "" {
+		list, err := openFilesFrom(filesFrom)
+		fatalIf(err.Trace(filesFrom), "Unable to open the list of objects to copy.")
+		defer list.Close()
+		URLsCh = prepareCopyURLsFromList(ctx, sourceURLs[0], targetURL, list, encKeyDB, olderThan, newerThan, tagFilter, sizeFilter, patternFilter)
+	} else {
+		URLsCh = prepareCopyURLs(ctx, sourceURLs, targetURL, isRecursive, encKeyDB, olderThan, newerThan, parseRewindFlag(rewind), versionID, tagFilter, sizeFilter, patternFilter)
+	}
 	done := false
 	for !done {
 		select {
@@ -325,6 +745,7 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 				} else {
 					errorIf(cpURLs.Error.Trace(), "Unable to prepare URL for copying.")
 				}
+				failed = true
 				break
 			}
 
@@ -361,7 +782,10 @@ func doPrepareCopyURLs(ctx context.Context, session *sessionV8, cancelCopy conte
 
 func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.Context, session *sessionV8, encKeyDB map[string][]prefixSSEPair, isMvCmd bool) error {
 	var isCopied func(string) bool
+	var checkpoint *sessionCheckpoint
 	var totalObjects, totalBytes int64
+	// prepareFailed is set when objects to copy could not be prepared.
+	var prepareFailed bool
 
 	var cpURLsCh = make(chan URLs, 10000)
 
@@ -375,8 +799,18 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 		pg = newAccounter(totalBytes)
 	}
 
-	sourceURLs := cli.Args()[:len(cli.Args())-1]
-	targetURL := cli.Args()[len(cli.Args())-1] // Last one is target
+	args := cli.Args()
+	if session != nil {
+		args = session.Header.CommandArgs
+	}
+	sourceURLs := args[:len(args)-1]
+	targetURL := args[len(args)-1] // Last one is target
+
+	// Flags of a session resumed by its ID are only known to the session.
+	flags := newCopyFlags(cli)
+	if session != nil && cli.String("resume") != "" {
+		flags = newCopyFlagsFromSession(session)
+	}
 
 	tgtClnt, err := newClient(targetURL)
 	fatalIf(err, "Unable to initialize `"+targetURL+"`.")
@@ -391,9 +825,10 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 		// isCopied returns true if an object has been already copied
 		// or not. This is useful when we resume from a session.
 		isCopied = isLastFactory(session.Header.LastCopied)
+		checkpoint = newSessionCheckpoint()
 
 		if !session.HasData() {
-			totalBytes, totalObjects = doPrepareCopyURLs(ctx, session, cancelCopy)
+			totalBytes, totalObjects, prepareFailed = doPrepareCopyURLs(ctx, session, cancelCopy)
 		} else {
 			totalBytes, totalObjects = session.Header.TotalBytes, session.Header.TotalObjects
 		}
@@ -426,11 +861,34 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 		newerThan := cli.String("newer-than")
 		rewind := cli.String("rewind")
 		versionID := cli.String("version-id")
+		tagFilter, err := newObjectTagFilter(cli.StringSlice("include-tag"), cli.StringSlice("exclude-tag"))
+		fatalIf(err, "Unable to parse tag filters.")
+		sizeFilter, err := newObjectSizeFilter(cli.String("larger-than"), cli.String("smaller-than"))
+		fatalIf(err, "Unable to parse size filters.")
+		patternFilter := getGlobFilter(cli)
+
+		var URLsCh chan URLs
+		// The objects of a list are prepared independently of each other.
+		var isList bool
+		if lockfileName := cli.String("lockfile"); lockfileName != "" {
+			l, err := readLockfile(lockfileName)
+			fatalIf(err.Trace(lockfileName), "Unable to read the lockfile.")
+			URLsCh, err = prepareCopyURLsFromLockfile(ctx, l, targetURL, encKeyDB)
+			fatalIf(err.Trace(lockfileName), "Unable to find the objects pinned by the lockfile.")
+		} else if filesFrom := cli.String("files-from"); filesFrom != "" {
+			isList = true
+			list, err := openFilesFrom(filesFrom)
+			fatalIf(err.Trace(filesFrom), "Unable to open the list of objects to copy.")
+			defer list.Close()
+			URLsCh = prepareCopyURLsFromList(ctx, sourceURLs[0], targetURL, list, encKeyDB, olderThan, newerThan, tagFilter, sizeFilter, patternFilter)
+		} else {
+			URLsCh = prepareCopyURLs(ctx, sourceURLs, targetURL, isRecursive,
+				encKeyDB, olderThan, newerThan, parseRewindFlag(rewind), versionID, tagFilter, sizeFilter, patternFilter)
+		}
 
 		go func() {
 			totalBytes := int64(0)
-			for cpURLs := range prepareCopyURLs(ctx, sourceURLs, targetURL, isRecursive,
-				encKeyDB, olderThan, newerThan, parseRewindFlag(rewind), versionID) {
+			for cpURLs := range URLsCh {
 				if cpURLs.Error != nil {
 					// Print in new line and adjust to top so that we
 					// don't print over the ongoing scan bar
@@ -445,6 +903,10 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 						errorIf(cpURLs.Error.Trace(),
 							"Unable to start copying.")
 					}
+					prepareFailed = true
+					if isList {
+						continue
+					}
 					break
 				} else {
 					totalBytes += cpURLs.SourceContent.Size
@@ -462,6 +924,10 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 
 	parallel := newParallelManager(statusCh)
 
+	// Patterns of the metadata file match paths relative to the target.
+	_, expandedTargetURL, _ := mustExpandAlias(targetURL)
+	metadataRoot := filepath.ToSlash(newClientURL(expandedTargetURL).Path)
+
 	go func() {
 		gracefulStop := func() {
 			parallel.stopAndWait()
@@ -492,36 +958,45 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 				cpURLs.TargetContent.UserMetadata = make(map[string]string)
 
 				// Check and handle storage class if passed in command line args
-				if storageClass := cli.String("storage-class"); storageClass != "" {
-					cpURLs.TargetContent.StorageClass = storageClass
+				if flags.storageClass != "" {
+					cpURLs.TargetContent.StorageClass = flags.storageClass
 				}
 
-				if rm := cli.String(rmFlag); rm != "" {
-					cpURLs.TargetContent.RetentionMode = rm
+				if flags.retentionMode != "" {
+					cpURLs.TargetContent.RetentionMode = flags.retentionMode
 					cpURLs.TargetContent.RetentionEnabled = true
 				}
-				if rd := cli.String(rdFlag); rd != "" {
-					cpURLs.TargetContent.RetentionDuration = rd
+				if flags.retentionDuration != "" {
+					cpURLs.TargetContent.RetentionDuration = flags.retentionDuration
 				}
-				if lh := cli.String(lhFlag); lh != "" {
-					cpURLs.TargetContent.LegalHold = strings.ToUpper(lh)
+				if flags.legalHold != "" {
+					cpURLs.TargetContent.LegalHold = strings.ToUpper(flags.legalHold)
 					cpURLs.TargetContent.LegalHoldEnabled = true
 				}
 
-				if tags := cli.String("tags"); tags != "" {
-					cpURLs.TargetContent.Metadata["X-Amz-Tagging"] = tags
+				if flags.tags != "" {
+					cpURLs.TargetContent.Metadata["X-Amz-Tagging"] = flags.tags
 				}
 
-				preserve := cli.Bool("preserve")
-				if cli.String("attr") != "" {
-					userMetaMap, _ := getMetaDataEntry(cli.String("attr"))
-					for metadataKey, metaDataVal := range userMetaMap {
-						cpURLs.TargetContent.UserMetadata[metadataKey] = metaDataVal
-					}
+				preserve := flags.preserve
+				for metadataKey, metaDataVal := range flags.userMetadata {
+					cpURLs.TargetContent.UserMetadata[metadataKey] = metaDataVal
+				}
+				for metadataKey, metaDataVal := range flags.metadataMap.metadata(metadataRelPath(metadataRoot, filepath.ToSlash(cpURLs.TargetContent.URL.Path))) {
+					cpURLs.TargetContent.UserMetadata[metadataKey] = metaDataVal
 				}
 
-				cpURLs.MD5 = cli.Bool("md5") || withLock
-				cpURLs.DisableMultipart = cli.Bool("disable-multipart")
+				conditions := flags.conditions
+
+				cpURLs.MD5 = flags.md5 || withLock
+				cpURLs.DisableMultipart = flags.disableMultipart
+				// Keep incomplete uploads of a session to continue them on resume.
+				cpURLs.resumeSession = session
+				cpURLs.targetRoot = newClientURL(targetURL).Path
+
+				if checkpoint != nil {
+					checkpoint.queue(cpURLs.SourceContent.URL.String())
+				}
 
 				// Verify if previously copied, notify progress bar.
 				if isCopied != nil && isCopied(cpURLs.SourceContent.URL.String()) {
@@ -530,13 +1005,24 @@ func doCopySession(ctx context.Context, cancelCopy context.CancelFunc, cli *cli.
 					}, 0)
 				} else {
 					parallel.queueTask(func() URLs {
-						return doCopy(ctx, cpURLs, pg, encKeyDB, isMvCmd, preserve)
+						return doCopy(ctx, cpURLs, pg, encKeyDB, isMvCmd, preserve, conditions)
 					}, cpURLs.SourceContent.Size)
 				}
 			}
 		}
 	}()
 
+	// saveCheckpoint records the progress of the session once an object is done.
+	saveCheckpoint := func(cpURLs URLs) {
+		if checkpoint == nil {
+			return
+		}
+		if lastCopied := checkpoint.complete(cpURLs.SourceContent.URL.String()); lastCopied != "" {
+			session.Header.LastCopied = lastCopied
+			session.Save()
+		}
+	}
+
 	var retErr error
 	errSeen := false
 	cpAllFilesErr := true
@@ -561,10 +1047,7 @@ loop:
 				break loop
 			}
 			if cpURLs.Error == nil {
-				if session != nil {
-					session.Header.LastCopied = cpURLs.SourceContent.URL.String()
-					session.Save()
-				}
+				saveCheckpoint(cpURLs)
 				cpAllFilesErr = false
 			} else {
 
@@ -580,6 +1063,7 @@ loop:
 					fmt.Sprintf("Failed to copy `%s`.", cpURLs.SourceContent.URL.String()))
 				if isErrIgnored(cpURLs.Error) {
 					cpAllFilesErr = false
+					saveCheckpoint(cpURLs)
 					continue loop
 				}
 
@@ -614,13 +1098,48 @@ loop:
 		}
 	} else {
 		if accntReader, ok := pg.(*accounter); ok {
-			printMsg(accntReader.Stat())
+			printMsg(summarizeTransfer(accntReader.Stat()))
 		}
 	}
+	printChecksumSummary()
 
+	if prepareFailed && retErr == nil {
+		retErr = exitStatus(globalErrorExitStatus)
+	}
 	return retErr
 }
 
+// resumeCopySession resumes an interrupted copy session by its ID,
+// with the arguments and flags the session was started with.
+func resumeCopySession(ctx context.Context, cancelCopy context.CancelFunc, cliCtx *cli.Context, sessionID string) error {
+	if cliCtx.NArg() > 0 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "Arguments cannot be specified with --resume, the ones of the session are used.")
+	}
+	if !isSessionExists(sessionID) {
+		fatalIf(errInvalidArgument().Trace(sessionID), "Session `"+sessionID+"` not found.")
+	}
+	session, err := loadSessionV8(sessionID)
+	fatalIf(err.Trace(sessionID), "Unable to load session.")
+	if session.Header.CommandType != "cp" {
+		fatalIf(errInvalidArgument().Trace(sessionID), "Session `"+sessionID+"` is not a copy session.")
+	}
+
+	// Relative paths are relative to the folder the session was started in.
+	if e := os.Chdir(session.Header.RootPath); e != nil {
+		fatalIf(probe.NewError(e), "Unable to change to the session folder `"+session.Header.RootPath+"`.")
+	}
+
+	encKeyDB, err := parseAndValidateEncryptionKeys(session.Header.CommandStringFlags["encrypt-key"],
+		session.Header.CommandStringFlags["encrypt"])
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	setTransferOptionsFromSession(session, cliCtx)
+
+	e := doCopySession(ctx, cancelCopy, cliCtx, session, encKeyDB, false)
+	session.Delete()
+	return e
+}
+
 // mainCopy is the entry point for cp command.
 func mainCopy(cliCtx *cli.Context) error {
 	ctx, cancelCopy := context.WithCancel(globalContext)
@@ -630,6 +1149,18 @@ func mainCopy(cliCtx *cli.Context) error {
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
+	setCPULimits(cliCtx)
+	setWalkWorkers(cliCtx)
+	setTransferLimits(cliCtx)
+	setStreamOptions(cliCtx)
+	setMultipartOptions(cliCtx)
+	setChecksumOptions(cliCtx)
+	setCompressOptions(cliCtx)
+	setRequestHeaders(cliCtx)
+	setRetryPolicy(cliCtx)
+	setServerCopyOptions(cliCtx)
+	setTransferEvents()
+
 	// Parse metadata.
 	userMetaMap := make(map[string]string)
 	if cliCtx.String("attr") != "" {
@@ -637,9 +1168,34 @@ func mainCopy(cliCtx *cli.Context) error {
 		fatalIf(err, "Unable to parse attribute %v", cliCtx.String("attr"))
 	}
 
+	if cliCtx.Bool("untar") || cliCtx.Bool("unzip") {
+		return copyExtract(ctx, cliCtx, encKeyDB, userMetaMap)
+	}
+
+	if cliCtx.Bool("fan-out") {
+		return copyFanOut(ctx, cliCtx, encKeyDB, userMetaMap)
+	}
+
+	if sessionID := cliCtx.String("resume"); sessionID != "" {
+		return resumeCopySession(ctx, cancelCopy, cliCtx, sessionID)
+	}
+
 	// check 'copy' cli arguments.
 	checkCopySyntax(ctx, cliCtx, encKeyDB, false)
 
+	if cliCtx.Bool("preflight") {
+		args := cliCtx.Args()
+		sourceURLs := args[:len(args)-1]
+		targetURL := args[len(args)-1]
+		targetIsDir := cliCtx.Bool("recursive") || len(args) > 2 || isAliasURLDir(ctx, targetURL, encKeyDB, time.Time{})
+		if lockfileName := cliCtx.String("lockfile"); lockfileName != "" {
+			l, err := readLockfile(lockfileName)
+			fatalIf(err.Trace(lockfileName), "Unable to read the lockfile.")
+			sourceURLs, targetIsDir = []string{l.Source}, true
+		}
+		runPreflight(ctx, sourceURLs, targetURL, targetIsDir, encKeyDB)
+	}
+
 	// Additional command specific theme customization.
 	console.SetColor("Copy", color.New(color.FgGreen, color.Bold))
 
@@ -653,6 +1209,15 @@ func mainCopy(cliCtx *cli.Context) error {
 	retentionDuration := cliCtx.String(rdFlag)
 	legalHold := strings.ToUpper(cliCtx.String(lhFlag))
 	tags := cliCtx.String("tags")
+	metadataFile := cliCtx.String("metadata-file")
+	if metadataFile != "" {
+		// Resumed sessions read the file again, wherever they are resumed.
+		metadataFile, _ = filepath.Abs(metadataFile)
+	}
+	lockfileName := cliCtx.String("lockfile")
+	if lockfileName != "" {
+		lockfileName, _ = filepath.Abs(lockfileName)
+	}
 	sseKeys := os.Getenv("MC_ENCRYPT_KEY")
 	if key := cliCtx.String("encrypt-key"); key != "" {
 		sseKeys = key
@@ -679,8 +1244,16 @@ func mainCopy(cliCtx *cli.Context) error {
 			session.Header.CommandStringFlags["version-id"] = versionID
 			session.Header.CommandStringFlags["older-than"] = olderThan
 			session.Header.CommandStringFlags["newer-than"] = newerThan
+			session.Header.CommandStringFlags["larger-than"] = cliCtx.String("larger-than")
+			session.Header.CommandStringFlags["smaller-than"] = cliCtx.String("smaller-than")
 			session.Header.CommandStringFlags["storage-class"] = storageClass
 			session.Header.CommandStringFlags["tags"] = tags
+			session.Header.CommandStringFlags["include-tag"] = strings.Join(cliCtx.StringSlice("include-tag"), "&")
+			session.Header.CommandStringFlags["exclude-tag"] = strings.Join(cliCtx.StringSlice("exclude-tag"), "&")
+			session.Header.CommandStringFlags["glob-filter"] = getGlobFilter(cliCtx).String()
+			session.Header.CommandStringFlags["files-from"] = cliCtx.String("files-from")
+			session.Header.CommandStringFlags["lockfile"] = lockfileName
+			session.Header.CommandStringFlags["metadata-file"] = metadataFile
 			session.Header.CommandStringFlags[rmFlag] = retentionMode
 			session.Header.CommandStringFlags[rdFlag] = retentionDuration
 			session.Header.CommandStringFlags[lhFlag] = legalHold
@@ -694,6 +1267,10 @@ func mainCopy(cliCtx *cli.Context) error {
 			session.Header.UserMetaData = userMetaMap
 			session.Header.CommandBoolFlags["md5"] = cliCtx.Bool("md5")
 			session.Header.CommandBoolFlags["disable-multipart"] = cliCtx.Bool("disable-multipart")
+			session.Header.CommandBoolFlags["if-not-exists"] = cliCtx.Bool("if-not-exists")
+			session.Header.CommandBoolFlags["if-newer"] = cliCtx.Bool("if-newer")
+			session.Header.CommandBoolFlags["if-etag-differs"] = cliCtx.Bool("if-etag-differs")
+			saveTransferFlags(session, cliCtx)
 
 			var e error
 			if session.Header.RootPath, e = os.Getwd(); e != nil {
@@ -18,8 +18,16 @@
 package cmd
 
 import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+
+	"github.com/minio/cli"
 )
 
 func TestParseMetaData(t *testing.T) {
@@ -74,3 +82,84 @@ func TestParseMetaData(t *testing.T) {
 		}
 	}
 }
+
+func TestPrepareCopyURLsFromList(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	source, target := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.txt", " b.txt"} {
+		if e := ioutil.WriteFile(filepath.Join(source, name), []byte(name), 0o600); e != nil {
+			t.Fatal(e)
+		}
+	}
+	list := strings.NewReader("a.txt\nmissing.txt\n b.txt\r\ndir/\n\n")
+	var copied []string
+	var failed int
+	for cpURLs := range prepareCopyURLsFromList(context.Background(), source, target, list, nil, "", "", objectTagFilter{}, objectSizeFilter{}, globFilter{}) {
+		if cpURLs.Error != nil {
+			failed++
+			continue
+		}
+		copied = append(copied, filepath.Base(cpURLs.TargetContent.URL.Path))
+	}
+	sort.Strings(copied)
+	if expected := []string{" b.txt", "a.txt"}; !reflect.DeepEqual(copied, expected) {
+		t.Errorf("expected %q to be copied, got %q", expected, copied)
+	}
+	if failed != 1 {
+		t.Errorf("expected the missing object to be sent as an error, got %d errors", failed)
+	}
+}
+
+func TestCopySessionTransferFlags(t *testing.T) {
+	defer func(upload, download *rateLimiter, compress string, extensions, exclude []string, partSize uint64, viaDisk bool, policy *retryPolicy) {
+		globalUploadLimiter, globalDownloadLimiter = upload, download
+		globalCompress, globalCompressExtensions, globalCompressExclude = compress, extensions, exclude
+		globalMultipartSize, globalStreamViaDisk, globalRetryPolicy = partSize, viaDisk, policy
+	}(globalUploadLimiter, globalDownloadLimiter, globalCompress, globalCompressExtensions, globalCompressExclude,
+		globalMultipartSize, globalStreamViaDisk, globalRetryPolicy)
+
+	newContext := func(args ...string) *cli.Context {
+		set := flag.NewFlagSet("cp", flag.ContinueOnError)
+		for _, flags := range [][]cli.Flag{cpFlags, transferLimitFlags, streamFlags, multipartFlags, compressFlags, retryFlags} {
+			for _, f := range flags {
+				f.Apply(set)
+			}
+		}
+		if e := set.Parse(args); e != nil {
+			t.Fatal(e)
+		}
+		return cli.NewContext(nil, set, nil)
+	}
+
+	session := &sessionV8{Header: &sessionV8Header{
+		CommandStringFlags: make(map[string]string),
+		CommandBoolFlags:   make(map[string]bool),
+	}}
+	saveTransferFlags(session, newContext("--limit-upload", "1MiB", "--limit-download", "2MiB", "--part-size", "16MiB",
+		"--compress", "zstd", "--compress-exclude", "*.raw", "--compress-exclude", "logs/*", "--retry", "3", "--via-disk"))
+
+	// The globals as set by a resumed copy, before the session is loaded.
+	globalUploadLimiter, globalDownloadLimiter, globalRetryPolicy = nil, nil, nil
+	globalCompress, globalMultipartSize, globalStreamViaDisk = "", 0, false
+
+	// Flags given when resuming take precedence.
+	setTransferOptionsFromSession(session, newContext("--limit-download", "4MiB"))
+	if globalUploadLimiter == nil || globalUploadLimiter.rate != 1<<20 {
+		t.Errorf("expected the upload limit of the session, got %+v", globalUploadLimiter)
+	}
+	if globalDownloadLimiter == nil || globalDownloadLimiter.rate != 4<<20 {
+		t.Errorf("expected the download limit given when resuming, got %+v", globalDownloadLimiter)
+	}
+	if globalMultipartSize != 16<<20 || !globalStreamViaDisk {
+		t.Errorf("expected 16MiB parts via disk, got %d %v", globalMultipartSize, globalStreamViaDisk)
+	}
+	if globalCompress != "zstd" || !reflect.DeepEqual(globalCompressExclude, []string{"*.raw", "logs/*"}) {
+		t.Errorf("expected zstd excluding *.raw and logs/*, got %s %v", globalCompress, globalCompressExclude)
+	}
+	if globalRetryPolicy == nil || globalRetryPolicy.retries != 3 {
+		t.Errorf("expected 3 retries, got %+v", globalRetryPolicy)
+	}
+}
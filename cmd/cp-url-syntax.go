@@ -29,6 +29,11 @@ import (
 )
 
 func checkCopySyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair, isMvCmd bool) {
+	if !isMvCmd && cliCtx.String("lockfile") != "" {
+		checkCopySyntaxLockfile(cliCtx)
+		return
+	}
+
 	if len(cliCtx.Args()) < 2 {
 		if isMvCmd {
 			cli.ShowCommandHelpAndExit(cliCtx, "mv", 1) // last argument is exit code.
@@ -48,6 +53,13 @@ func checkCopySyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 	timeRef := parseRewindFlag(cliCtx.String("rewind"))
 	versionID := cliCtx.String("version-id")
 
+	mustLoadMetadataFile(cliCtx.String("metadata-file"))
+
+	if cliCtx.String("files-from") != "" {
+		checkCopySyntaxFilesFrom(cliCtx, srcURLs, versionID)
+		return
+	}
+
 	if versionID != "" && len(srcURLs) > 1 {
 		fatalIf(errDummy().Trace(cliCtx.Args()...), "Unable to pass --version flag with multiple copy sources arguments.")
 	}
@@ -124,6 +136,32 @@ func checkCopySyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 	}
 }
 
+// checkCopySyntaxFilesFrom verifies the arguments of a copy of listed objects.
+func checkCopySyntaxFilesFrom(cliCtx *cli.Context, srcURLs []string, versionID string) {
+	if len(srcURLs) != 1 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "`--files-from` requires a single source folder, listed objects are relative to it.")
+	}
+	if versionID != "" || cliCtx.String("rewind") != "" {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "`--files-from` cannot be used with `--version-id` or `--rewind`.")
+	}
+}
+
+// checkCopySyntaxLockfile verifies the arguments of a copy of the objects
+// pinned by a lockfile, their source is recorded in the lockfile.
+func checkCopySyntaxLockfile(cliCtx *cli.Context) {
+	if len(cliCtx.Args()) != 1 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "`--lockfile` requires a single target folder, the source is recorded in the lockfile.")
+	}
+	for _, flag := range []string{"version-id", "rewind", "files-from", "older-than", "newer-than"} {
+		if cliCtx.String(flag) != "" {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "`--lockfile` cannot be used with `--"+flag+"`, the lockfile pins the objects to copy.")
+		}
+	}
+	if _, err := readLockfile(cliCtx.String("lockfile")); err != nil {
+		fatalIf(err.Trace(cliCtx.String("lockfile")), "Unable to read the lockfile.")
+	}
+}
+
 // checkCopySyntaxTypeA verifies if the source and target are valid file arguments.
 func checkCopySyntaxTypeA(ctx context.Context, srcURL, versionID string, tgtURL string, keys map[string][]prefixSSEPair, isMvCmd bool, timeRef time.Time) {
 	_, srcContent, err := url2Stat(ctx, srcURL, versionID, false, keys, timeRef)
@@ -18,9 +18,15 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/minio/mc/pkg/probe"
@@ -59,7 +65,7 @@ func guessCopyURLType(ctx context.Context, sourceURLs []string, targetURL string
 		var sourceContent *ClientContent
 		sourceURL := sourceURLs[0]
 		if !isRecursive {
-			_, sourceContent, err = url2Stat(ctx, sourceURL, versionID, false, keys, timeRef)
+			_, sourceContent, err = sourceURL2Stat(ctx, sourceURL, versionID, false, keys, timeRef)
 		} else {
 			_, sourceContent, err = firstURL2Stat(ctx, sourceURL, timeRef)
 		}
@@ -97,7 +103,7 @@ func prepareCopyURLsTypeA(ctx context.Context, sourceURL, sourceVersion string,
 	// Find alias and expanded clientURL.
 	targetAlias, targetURL, _ := mustExpandAlias(targetURL)
 
-	_, sourceContent, err := url2Stat(ctx, sourceURL, sourceVersion, false, encKeyDB, time.Time{})
+	_, sourceContent, err := sourceURL2Stat(ctx, sourceURL, sourceVersion, false, encKeyDB, time.Time{})
 	if err != nil {
 		// Source does not exist or insufficient privileges.
 		return URLs{Error: err.Trace(sourceURL)}
@@ -130,7 +136,7 @@ func prepareCopyURLsTypeB(ctx context.Context, sourceURL, sourceVersion string,
 	// Find alias and expanded clientURL.
 	targetAlias, targetURL, _ := mustExpandAlias(targetURL)
 
-	_, sourceContent, err := url2Stat(ctx, sourceURL, sourceVersion, false, encKeyDB, time.Time{})
+	_, sourceContent, err := sourceURL2Stat(ctx, sourceURL, sourceVersion, false, encKeyDB, time.Time{})
 	if err != nil {
 		// Source does not exist or insufficient privileges.
 		return URLs{Error: err.Trace(sourceURL)}
@@ -158,7 +164,7 @@ func makeCopyContentTypeB(sourceAlias string, sourceContent *ClientContent, targ
 
 // SINGLE SOURCE - Type C: copy(d1..., d2) -> []copy(d1/f, d1/d2/f) -> []A
 // prepareCopyRecursiveURLTypeC - prepares target and source clientURLs for copying.
-func prepareCopyURLsTypeC(ctx context.Context, sourceURL, targetURL string, isRecursive bool, timeRef time.Time, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
+func prepareCopyURLsTypeC(ctx context.Context, sourceURL, targetURL string, isRecursive bool, timeRef time.Time, encKeyDB map[string][]prefixSSEPair, patternFilter globFilter) <-chan URLs {
 	// Extract alias before fiddling with the clientURL.
 	sourceAlias, _, _ := mustExpandAlias(sourceURL)
 	// Find alias and expanded clientURL.
@@ -185,6 +191,14 @@ func prepareCopyURLsTypeC(ctx context.Context, sourceURL, targetURL string, isRe
 				continue
 			}
 
+			// Skip objects not matching --include and --exclude if specified
+			if !patternFilter.isEmpty() {
+				relPath := strings.TrimPrefix(filepath.ToSlash(sourceContent.URL.Path), filepath.ToSlash(sourceClient.GetURL().Path))
+				if !patternFilter.match(relPath) {
+					continue
+				}
+			}
+
 			// All OK.. We can proceed. Type B: source is a file, target is a folder and exists.
 			copyURLsCh <- makeCopyContentTypeC(sourceAlias, sourceClient.GetURL(), sourceContent, targetAlias, targetURL, encKeyDB)
 		}
@@ -207,12 +221,12 @@ func makeCopyContentTypeC(sourceAlias string, sourceURL ClientURL, sourceContent
 
 // MULTI-SOURCE - Type D: copy([](f|d...), d) -> []B
 // prepareCopyURLsTypeE - prepares target and source clientURLs for copying.
-func prepareCopyURLsTypeD(ctx context.Context, sourceURLs []string, targetURL string, isRecursive bool, timeRef time.Time, encKeyDB map[string][]prefixSSEPair) <-chan URLs {
+func prepareCopyURLsTypeD(ctx context.Context, sourceURLs []string, targetURL string, isRecursive bool, timeRef time.Time, encKeyDB map[string][]prefixSSEPair, patternFilter globFilter) <-chan URLs {
 	copyURLsCh := make(chan URLs)
 	go func(sourceURLs []string, targetURL string, copyURLsCh chan URLs) {
 		defer close(copyURLsCh)
 		for _, sourceURL := range sourceURLs {
-			for cpURLs := range prepareCopyURLsTypeC(ctx, sourceURL, targetURL, isRecursive, timeRef, encKeyDB) {
+			for cpURLs := range prepareCopyURLsTypeC(ctx, sourceURL, targetURL, isRecursive, timeRef, encKeyDB, patternFilter) {
 				copyURLsCh <- cpURLs
 			}
 		}
@@ -221,7 +235,7 @@ func prepareCopyURLsTypeD(ctx context.Context, sourceURLs []string, targetURL st
 }
 
 // prepareCopyURLs - prepares target and source clientURLs for copying.
-func prepareCopyURLs(ctx context.Context, sourceURLs []string, targetURL string, isRecursive bool, encKeyDB map[string][]prefixSSEPair, olderThan, newerThan string, timeRef time.Time, versionID string) chan URLs {
+func prepareCopyURLs(ctx context.Context, sourceURLs []string, targetURL string, isRecursive bool, encKeyDB map[string][]prefixSSEPair, olderThan, newerThan string, timeRef time.Time, versionID string, tagFilter objectTagFilter, sizeFilter objectSizeFilter, patternFilter globFilter) chan URLs {
 	copyURLsCh := make(chan URLs)
 	go func(sourceURLs []string, targetURL string, copyURLsCh chan URLs, encKeyDB map[string][]prefixSSEPair, timeRef time.Time) {
 		defer close(copyURLsCh)
@@ -229,16 +243,23 @@ func prepareCopyURLs(ctx context.Context, sourceURLs []string, targetURL string,
 		fatalIf(err.Trace(), "Unable to guess the type of copy operation.")
 
 		switch cpType {
-		case copyURLsTypeA:
-			copyURLsCh <- prepareCopyURLsTypeA(ctx, sourceURLs[0], cpVersion, targetURL, encKeyDB)
-		case copyURLsTypeB:
-			copyURLsCh <- prepareCopyURLsTypeB(ctx, sourceURLs[0], cpVersion, targetURL, encKeyDB)
+		case copyURLsTypeA, copyURLsTypeB:
+			var cpURLs URLs
+			if cpType == copyURLsTypeA {
+				cpURLs = prepareCopyURLsTypeA(ctx, sourceURLs[0], cpVersion, targetURL, encKeyDB)
+			} else {
+				cpURLs = prepareCopyURLsTypeB(ctx, sourceURLs[0], cpVersion, targetURL, encKeyDB)
+			}
+			// A single object is matched by its name.
+			if cpURLs.Error != nil || patternFilter.match(path.Base(filepath.ToSlash(cpURLs.SourceContent.URL.Path))) {
+				copyURLsCh <- cpURLs
+			}
 		case copyURLsTypeC:
-			for cURLs := range prepareCopyURLsTypeC(ctx, sourceURLs[0], targetURL, isRecursive, timeRef, encKeyDB) {
+			for cURLs := range prepareCopyURLsTypeC(ctx, sourceURLs[0], targetURL, isRecursive, timeRef, encKeyDB, patternFilter) {
 				copyURLsCh <- cURLs
 			}
 		case copyURLsTypeD:
-			for cURLs := range prepareCopyURLsTypeD(ctx, sourceURLs, targetURL, isRecursive, timeRef, encKeyDB) {
+			for cURLs := range prepareCopyURLsTypeD(ctx, sourceURLs, targetURL, isRecursive, timeRef, encKeyDB, patternFilter) {
 				copyURLsCh <- cURLs
 			}
 		default:
@@ -246,20 +267,139 @@ func prepareCopyURLs(ctx context.Context, sourceURLs []string, targetURL string,
 		}
 	}(sourceURLs, targetURL, copyURLsCh, encKeyDB, timeRef)
 
+	return filterCopyURLs(ctx, copyURLsCh, olderThan, newerThan, tagFilter, sizeFilter)
+}
+
+// listWorkers is the number of objects of a list looked up in parallel.
+const listWorkers = 16
+
+// prepareCopyURLsFromList - prepares target and source clientURLs for copying
+// the objects listed one per line by their path relative to the source. The
+// listed objects which cannot be found are sent as errors.
+func prepareCopyURLsFromList(ctx context.Context, sourceURL, targetURL string, list io.Reader, encKeyDB map[string][]prefixSSEPair, olderThan, newerThan string, tagFilter objectTagFilter, sizeFilter objectSizeFilter, patternFilter globFilter) chan URLs {
+	copyURLsCh := make(chan URLs)
+	keysCh := make(chan string)
+
+	var wg sync.WaitGroup
+	for i := 0; i < listWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keysCh {
+				cpURLs := prepareCopyURLsTypeA(ctx, urlJoinPath(sourceURL, key), "", urlJoinPath(targetURL, key), encKeyDB)
+				select {
+				case copyURLsCh <- cpURLs:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(keysCh)
+			wg.Wait()
+			close(copyURLsCh)
+		}()
+		sourcePrefix := strings.TrimSuffix(sourceURL, "/") + "/"
+		scanner := bufio.NewScanner(list)
+		for scanner.Scan() {
+			// Listed URLs, e.g. from 'mc find', are made relative to the source.
+			key := strings.TrimSuffix(scanner.Text(), "\r")
+			key = strings.TrimPrefix(strings.TrimPrefix(key, sourcePrefix), "/")
+			if key == "" || strings.HasSuffix(key, "/") || !patternFilter.match(key) {
+				continue
+			}
+			select {
+			case keysCh <- key:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if e := scanner.Err(); e != nil {
+			select {
+			case copyURLsCh <- URLs{Error: probe.NewError(e)}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return filterCopyURLs(ctx, copyURLsCh, olderThan, newerThan, tagFilter, sizeFilter)
+}
+
+// openFilesFrom opens the list of objects to copy, '-' being stdin.
+func openFilesFrom(name string) (io.ReadCloser, *probe.Error) {
+	if name == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	f, e := os.Open(name)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return f, nil
+}
+
+// listedObject is an object, or one of its versions, listed in
+// the file of --files-from.
+type listedObject struct {
+	url       string
+	versionID string
+}
+
+// parseListedObject parses a line of the file of --files-from, the
+// URL of an object relative to prefix when set, optionally followed
+// by a tab and a version id. Blank lines, comments starting with '#'
+// and folders are skipped.
+func parseListedObject(line, prefix string) (object listedObject, ok bool) {
+	tokens := strings.SplitN(strings.TrimSuffix(line, "\r"), "\t", 2)
+	name := tokens[0]
+	if name == "" || strings.HasPrefix(name, "#") || strings.HasSuffix(name, "/") {
+		return object, false
+	}
+	if len(tokens) == 2 {
+		object.versionID = strings.TrimSpace(tokens[1])
+	}
+	object.url = name
+	if prefix != "" {
+		object.url = urlJoinPath(prefix, strings.TrimPrefix(name, "/"))
+	}
+	return object, true
+}
+
+// filterCopyURLs - skips objects not matching --older-than, --newer-than,
+// --larger-than, --smaller-than, --include-tag and --exclude-tag if specified.
+func filterCopyURLs(ctx context.Context, copyURLsCh <-chan URLs, olderThan, newerThan string, tagFilter objectTagFilter, sizeFilter objectSizeFilter) chan URLs {
 	finalCopyURLsCh := make(chan URLs)
 	go func() {
 		defer close(finalCopyURLsCh)
 		for cpURLs := range copyURLsCh {
 			// Skip objects older than --older-than parameter if specified
-			if olderThan != "" && isOlder(cpURLs.SourceContent.Time, olderThan) {
+			if cpURLs.Error == nil && olderThan != "" && isOlder(cpURLs.SourceContent.Time, olderThan) {
 				continue
 			}
 
 			// Skip objects newer than --newer-than parameter if specified
-			if newerThan != "" && isNewer(cpURLs.SourceContent.Time, newerThan) {
+			if cpURLs.Error == nil && newerThan != "" && isNewer(cpURLs.SourceContent.Time, newerThan) {
 				continue
 			}
 
+			// Skip objects not matching --larger-than and --smaller-than if specified
+			if cpURLs.Error == nil && !sizeFilter.match(cpURLs.SourceContent.Size) {
+				continue
+			}
+
+			// Skip objects not matching --include-tag and --exclude-tag if specified
+			if cpURLs.Error == nil && !tagFilter.isEmpty() {
+				matched, err := tagFilter.matchContent(ctx, cpURLs.SourceAlias, cpURLs.SourceContent)
+				if err != nil {
+					errorIf(err, "Unable to fetch tags, skipping `%s`.", cpURLs.SourceContent.URL)
+					continue
+				}
+				if !matched {
+					continue
+				}
+			}
+
 			finalCopyURLsCh <- cpURLs
 		}
 	}()
@@ -0,0 +1,154 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// CPU usage flags of the transfer commands.
+var cpuLimitFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "max-cpu",
+		Usage: "limit the CPUs used by transfers, defaults to the CPU quota of the container",
+	},
+	cli.StringFlag{
+		Name:  "cpu-affinity",
+		Usage: "pin transfers to a list of CPUs, e.g. '0-15,64-79' to stay on one NUMA node (linux only)",
+	},
+}
+
+const (
+	cgroupV2CPUFile       = "/sys/fs/cgroup/cpu.max"
+	cgroupV1CPUQuotaFile  = "/sys/fs/cgroup/cpu/cpu.cfs_quota_us"
+	cgroupV1CPUPeriodFile = "/sys/fs/cgroup/cpu/cpu.cfs_period_us"
+)
+
+// parseCgroupCPULimit returns the number of CPUs allowed by a CFS quota
+// and period, rounded up. 0 is returned if no quota is set.
+func parseCgroupCPULimit(quota, period string) int {
+	quota, period = strings.TrimSpace(quota), strings.TrimSpace(period)
+	if quota == "" || quota == "max" || quota == "-1" {
+		return 0
+	}
+	q, e := strconv.ParseFloat(quota, 64)
+	if e != nil || q <= 0 {
+		return 0
+	}
+	p, e := strconv.ParseFloat(period, 64)
+	if e != nil || p <= 0 {
+		return 0
+	}
+	return int(math.Ceil(q / p))
+}
+
+// cgroupCPULimit returns the CPU quota of the container mc runs in, or 0
+// when there is none. Both cgroup v2 and v1 layouts are looked up.
+func cgroupCPULimit() int {
+	if runtime.GOOS != "linux" {
+		return 0
+	}
+	if buf, e := ioutil.ReadFile(cgroupV2CPUFile); e == nil {
+		fields := strings.Fields(string(buf))
+		if len(fields) == 2 {
+			return parseCgroupCPULimit(fields[0], fields[1])
+		}
+		return 0
+	}
+	quota, e := ioutil.ReadFile(cgroupV1CPUQuotaFile)
+	if e != nil {
+		return 0
+	}
+	period, e := ioutil.ReadFile(cgroupV1CPUPeriodFile)
+	if e != nil {
+		return 0
+	}
+	return parseCgroupCPULimit(string(quota), string(period))
+}
+
+// parseCPUList parses a list of CPUs in the format of taskset and
+// cpuset, e.g. "0-3,8,10-11", and returns the sorted unique CPUs.
+func parseCPUList(list string) ([]int, *probe.Error) {
+	seen := make(map[int]bool)
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, probe.NewError(fmt.Errorf("empty CPU in list `%s`", list))
+		}
+		first, last := part, part
+		if i := strings.Index(part, "-"); i >= 0 {
+			first, last = part[:i], part[i+1:]
+		}
+		start, e := strconv.Atoi(first)
+		if e != nil || start < 0 {
+			return nil, probe.NewError(fmt.Errorf("invalid CPU `%s`", first))
+		}
+		end, e := strconv.Atoi(last)
+		if e != nil || end < start {
+			return nil, probe.NewError(fmt.Errorf("invalid CPU range `%s`", part))
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			seen[cpu] = true
+		}
+	}
+	cpus := make([]int, 0, len(seen))
+	for cpu := range seen {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// setCPULimits pins the process to the --cpu-affinity CPUs and limits
+// the CPUs used by Go and the initial transfer workers to --max-cpu. The
+// limit defaults to the container CPU quota and the pinned CPUs.
+func setCPULimits(cliCtx *cli.Context) {
+	maxCPU := runtime.NumCPU()
+	if limit := cgroupCPULimit(); limit > 0 && limit < maxCPU {
+		maxCPU = limit
+	}
+
+	if list := cliCtx.String("cpu-affinity"); list != "" {
+		cpus, err := parseCPUList(list)
+		fatalIf(err.Trace(list), "Unable to parse --cpu-affinity.")
+		fatalIf(probe.NewError(setCPUAffinity(cpus)).Trace(list), "Unable to pin transfers to CPUs `"+list+"`.")
+		if len(cpus) < maxCPU {
+			maxCPU = len(cpus)
+		}
+	}
+
+	if cliCtx.IsSet("max-cpu") {
+		n := cliCtx.Int("max-cpu")
+		if n < 1 {
+			fatalIf(errInvalidArgument().Trace(cliCtx.String("max-cpu")), "--max-cpu must be at least 1.")
+		}
+		maxCPU = n
+	}
+
+	runtime.GOMAXPROCS(maxCPU)
+	defaultWorkerFactor = maxCPU
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCPUList(t *testing.T) {
+	testCases := []struct {
+		list     string
+		expected []int
+		success  bool
+	}{
+		{"0", []int{0}, true},
+		{"0-3", []int{0, 1, 2, 3}, true},
+		{"8,0-2,2", []int{0, 1, 2, 8}, true},
+		{" 4 , 6-7", []int{4, 6, 7}, true},
+		{"", nil, false},
+		{"0,,1", nil, false},
+		{"3-1", nil, false},
+		{"-1", nil, false},
+		{"a-b", nil, false},
+	}
+
+	for i, testCase := range testCases {
+		cpus, err := parseCPUList(testCase.list)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: expected success %v, got error %v", i+1, testCase.success, err)
+		}
+		if testCase.success && !reflect.DeepEqual(cpus, testCase.expected) {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, testCase.expected, cpus)
+		}
+	}
+}
+
+func TestParseCgroupCPULimit(t *testing.T) {
+	testCases := []struct {
+		quota, period string
+		expected      int
+	}{
+		{"max", "100000", 0},
+		{"-1\n", "100000\n", 0},
+		{"200000", "100000", 2},
+		{"150000\n", "100000\n", 2},
+		{"50000", "100000", 1},
+		{"100000", "0", 0},
+		{"abc", "100000", 0},
+	}
+
+	for i, testCase := range testCases {
+		if n := parseCgroupCPULimit(testCase.quota, testCase.period); n != testCase.expected {
+			t.Errorf("Test %d: expected %d CPUs, got %d", i+1, testCase.expected, n)
+		}
+	}
+}
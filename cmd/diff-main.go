@@ -20,9 +20,12 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
@@ -32,7 +35,20 @@ import (
 
 // diff specific flags.
 var (
-	diffFlags = []cli.Flag{}
+	diffFlags = []cli.Flag{
+		cli.BoolFlag{
+			Name:  "summary",
+			Usage: "print only a summary of the differences found",
+		},
+	}
+)
+
+// diff exit status codes, zero is returned when FIRST and SECOND are identical.
+const (
+	// Differences were found between FIRST and SECOND.
+	diffDifferentExitStatus = 1
+	// Comparison could not be completed.
+	diffErrorExitStatus = 2
 )
 
 // Compute differences in object name, size, and date between two buckets.
@@ -60,12 +76,20 @@ LEGEND:
   > - object is only in destination.
   ! - newer object is in source.
 
+EXIT STATUS:
+  0 - FIRST and SECOND are identical.
+  1 - differences were found.
+  2 - an error occurred, the comparison may be incomplete.
+
 EXAMPLES:
   1. Compare a local folder with a folder on Amazon S3 cloud storage.
      {{.Prompt}} {{.HelpName}} ~/Photos s3/mybucket/Photos
 
   2. Compare two folders on a local filesystem.
      {{.Prompt}} {{.HelpName}} ~/Photos /Media/Backup/Photos
+
+  3. Verify a backup and print a JSON summary of differences per type.
+     {{.Prompt}} {{.HelpName}} --summary --json s3/mybucket/Photos play/backup/Photos
 `,
 }
 
@@ -115,13 +139,88 @@ func (d diffMessage) JSON() string {
 	return string(diffJSONBytes)
 }
 
+// diffSummaryMessage json container for the summary of a diff
+type diffSummaryMessage struct {
+	Status      string           `json:"status"`
+	FirstURL    string           `json:"first"`
+	SecondURL   string           `json:"second"`
+	Identical   bool             `json:"identical"`
+	Total       int64            `json:"total"`
+	Differences map[string]int64 `json:"differences"`
+	DriftBytes  int64            `json:"driftBytes"`
+	Errors      int64            `json:"errors"`
+}
+
+// add accounts a single difference into the summary.
+func (d *diffSummaryMessage) add(diffMsg diffMessage) {
+	if diffMsg.Error != nil {
+		d.Errors++
+		return
+	}
+	if diffMsg.Diff == differInNone {
+		return
+	}
+	d.Total++
+	d.Differences[diffMsg.Diff.String()]++
+	// Drift is the amount of data which needs to be transferred
+	// or removed to make SECOND identical to FIRST.
+	switch {
+	case diffMsg.firstContent != nil:
+		d.DriftBytes += diffMsg.firstContent.Size
+	case diffMsg.secondContent != nil:
+		d.DriftBytes += diffMsg.secondContent.Size
+	}
+}
+
+// String colorized diff summary message
+func (d diffSummaryMessage) String() string {
+	if d.Identical {
+		return console.Colorize("DiffMessage", "No differences found between `"+d.FirstURL+"` and `"+d.SecondURL+"`.")
+	}
+	var kinds []string
+	for kind := range d.Differences {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	var counts []string
+	for _, kind := range kinds {
+		counts = append(counts, fmt.Sprintf("%s: %d", kind, d.Differences[kind]))
+	}
+	msg := fmt.Sprintf("Found %d difference(s) between `%s` and `%s`", d.Total, d.FirstURL, d.SecondURL)
+	if len(counts) > 0 {
+		msg += " (" + strings.Join(counts, ", ") + ")"
+	}
+	msg += ", drift " + humanize.IBytes(uint64(d.DriftBytes)) + "."
+	if d.Errors > 0 {
+		msg += fmt.Sprintf(" %d error(s) occurred, comparison may be incomplete.", d.Errors)
+	}
+	return console.Colorize("DiffSize", msg)
+}
+
+// JSON jsonified diff summary message
+func (d diffSummaryMessage) JSON() string {
+	d.Status = "success"
+	summaryJSONBytes, e := json.MarshalIndent(d, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal diff summary of `"+d.FirstURL+"` and `"+d.SecondURL+"`.")
+	return string(summaryJSONBytes)
+}
+
+// diffFatalIf is similar to fatalIf but honors the exit status contract of diff.
+func diffFatalIf(err *probe.Error, msg string) {
+	if err == nil {
+		return
+	}
+	errorIf(err, msg)
+	os.Exit(diffErrorExitStatus)
+}
+
 func checkDiffSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	if len(cliCtx.Args()) != 2 {
-		cli.ShowCommandHelpAndExit(cliCtx, "diff", 1) // last argument is exit code
+		cli.ShowCommandHelpAndExit(cliCtx, "diff", diffErrorExitStatus) // last argument is exit code
 	}
 	for _, arg := range cliCtx.Args() {
 		if strings.TrimSpace(arg) == "" {
-			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "Unable to validate empty argument.")
+			diffFatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "Unable to validate empty argument.")
 		}
 	}
 	URLs := cliCtx.Args()
@@ -133,12 +232,12 @@ func checkDiffSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 	// Verify if firstURL is accessible.
 	_, firstContent, err := url2Stat(ctx, firstURL, "", false, encKeyDB, time.Time{})
 	if err != nil {
-		fatalIf(err.Trace(firstURL), fmt.Sprintf("Unable to stat '%s'.", firstURL))
+		diffFatalIf(err.Trace(firstURL), fmt.Sprintf("Unable to stat '%s'.", firstURL))
 	}
 
 	// Verify if its a directory.
 	if !firstContent.Type.IsDir() {
-		fatalIf(errInvalidArgument().Trace(firstURL), fmt.Sprintf("`%s` is not a folder.", firstURL))
+		diffFatalIf(errInvalidArgument().Trace(firstURL), fmt.Sprintf("`%s` is not a folder.", firstURL))
 	}
 
 	// Verify if secondURL is accessible.
@@ -146,18 +245,25 @@ func checkDiffSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 	if err != nil {
 		// Destination doesn't exist is okay.
 		if _, ok := err.ToGoError().(ObjectMissing); !ok {
-			fatalIf(err.Trace(secondURL), fmt.Sprintf("Unable to stat '%s'.", secondURL))
+			diffFatalIf(err.Trace(secondURL), fmt.Sprintf("Unable to stat '%s'.", secondURL))
 		}
 	}
 
 	// Verify if its a directory.
 	if err == nil && !secondContent.Type.IsDir() {
-		fatalIf(errInvalidArgument().Trace(secondURL), fmt.Sprintf("`%s` is not a folder.", secondURL))
+		diffFatalIf(errInvalidArgument().Trace(secondURL), fmt.Sprintf("`%s` is not a folder.", secondURL))
 	}
 }
 
-// doDiffMain runs the diff.
-func doDiffMain(ctx context.Context, firstURL, secondURL string) error {
+// doDiffMain runs the diff and returns an exit status error
+// whenever differences are found or the comparison failed.
+func doDiffMain(ctx context.Context, firstURL, secondURL string, summary bool) error {
+	summaryMsg := diffSummaryMessage{
+		FirstURL:    firstURL,
+		SecondURL:   secondURL,
+		Differences: make(map[string]int64),
+	}
+
 	// Source and targets are always directories
 	sourceSeparator := string(newClientURL(firstURL).Separator)
 	if !strings.HasSuffix(firstURL, sourceSeparator) {
@@ -174,26 +280,40 @@ func doDiffMain(ctx context.Context, firstURL, secondURL string) error {
 
 	firstClient, err := newClientFromAlias(firstAlias, firstURL)
 	if err != nil {
-		fatalIf(err.Trace(firstAlias, firstURL, secondAlias, secondURL),
+		diffFatalIf(err.Trace(firstAlias, firstURL, secondAlias, secondURL),
 			fmt.Sprintf("Failed to diff '%s' and '%s'", firstURL, secondURL))
 	}
 
 	secondClient, err := newClientFromAlias(secondAlias, secondURL)
 	if err != nil {
-		fatalIf(err.Trace(firstAlias, firstURL, secondAlias, secondURL),
+		diffFatalIf(err.Trace(firstAlias, firstURL, secondAlias, secondURL),
 			fmt.Sprintf("Failed to diff '%s' and '%s'", firstURL, secondURL))
 	}
 
 	// Diff first and second urls.
 	for diffMsg := range objectDifference(ctx, firstClient, secondClient, firstURL, secondURL, true) {
+		summaryMsg.add(diffMsg)
 		if diffMsg.Error != nil {
 			errorIf(diffMsg.Error, "Unable to calculate objects difference.")
 			// Ignore error and proceed to next object.
 			continue
 		}
-		printMsg(diffMsg)
+		if !summary {
+			printMsg(diffMsg)
+		}
 	}
 
+	summaryMsg.Identical = summaryMsg.Total == 0 && summaryMsg.Errors == 0
+	if summary {
+		printMsg(summaryMsg)
+	}
+
+	switch {
+	case summaryMsg.Errors > 0:
+		return exitStatus(diffErrorExitStatus)
+	case summaryMsg.Total > 0:
+		return exitStatus(diffDifferentExitStatus)
+	}
 	return nil
 }
 
@@ -204,7 +324,7 @@ func mainDiff(cliCtx *cli.Context) error {
 
 	// Parse encryption keys per command.
 	encKeyDB, err := getEncKeys(cliCtx)
-	fatalIf(err, "Unable to parse encryption keys.")
+	diffFatalIf(err, "Unable to parse encryption keys.")
 
 	// check 'diff' cli arguments.
 	checkDiffSyntax(ctx, cliCtx, encKeyDB)
@@ -222,5 +342,5 @@ func mainDiff(cliCtx *cli.Context) error {
 	firstURL := URLs.Get(0)
 	secondURL := URLs.Get(1)
 
-	return doDiffMain(ctx, firstURL, secondURL)
+	return doDiffMain(ctx, firstURL, secondURL, cliCtx.Bool("summary"))
 }
@@ -0,0 +1,132 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+)
+
+func TestDiffSummaryAdd(t *testing.T) {
+	content := func(size int64) *ClientContent { return &ClientContent{Size: size} }
+	diffs := []diffMessage{
+		{Diff: differInNone, firstContent: content(1), secondContent: content(1)},
+		{Diff: differInFirst, firstContent: content(10)},
+		{Diff: differInSecond, secondContent: content(20)},
+		{Diff: differInSize, firstContent: content(30), secondContent: content(5)},
+		{Diff: differInSize, firstContent: content(40), secondContent: content(50)},
+		{Error: errDummy()},
+	}
+	summary := diffSummaryMessage{Differences: make(map[string]int64)}
+	for _, diff := range diffs {
+		summary.add(diff)
+	}
+	expected := map[string]int64{"only-in-first": 1, "only-in-second": 1, "size": 2}
+	if !reflect.DeepEqual(summary.Differences, expected) {
+		t.Errorf("expected differences %v, got %v", expected, summary.Differences)
+	}
+	if summary.Total != 4 {
+		t.Errorf("expected 4 differences, got %d", summary.Total)
+	}
+	// Drift is the size of the data in FIRST, or in SECOND when only there.
+	if summary.DriftBytes != 100 {
+		t.Errorf("expected a drift of 100 bytes, got %d", summary.DriftBytes)
+	}
+	if summary.Errors != 1 {
+		t.Errorf("expected 1 error, got %d", summary.Errors)
+	}
+}
+
+func TestDoDiffMainExitStatus(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	saveMcConfig(newMcConfig())
+	loadMcConfig = loadMcConfigFactory()
+
+	defer func(output io.Writer, json bool) { color.Output, globalJSON = output, json }(color.Output, globalJSON)
+	globalJSON = true
+
+	writeFiles := func(dir string, files map[string]string) string {
+		for name, data := range files {
+			if e := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); e != nil {
+				t.Fatal(e)
+			}
+		}
+		return dir
+	}
+
+	testCases := []struct {
+		first, second map[string]string
+		exitStatus    int
+		differences   map[string]int64
+		drift         int64
+	}{
+		{map[string]string{}, map[string]string{}, 0, map[string]int64{}, 0},
+		{map[string]string{"a": "a"}, map[string]string{"a": "a"}, 0, map[string]int64{}, 0},
+		{
+			map[string]string{"a": "a", "b": "bb", "d": "dddd"},
+			map[string]string{"a": "a", "c": "ccc", "d": "d"},
+			diffDifferentExitStatus,
+			map[string]int64{"only-in-first": 1, "only-in-second": 1, "size": 1},
+			2 + 3 + 4,
+		},
+	}
+
+	for i, testCase := range testCases {
+		first := writeFiles(t.TempDir(), testCase.first)
+		second := writeFiles(t.TempDir(), testCase.second)
+
+		var buf bytes.Buffer
+		color.Output = &buf
+		e := doDiffMain(context.Background(), first, second, true)
+		status := 0
+		if e != nil {
+			exitErr, ok := e.(cli.ExitCoder)
+			if !ok {
+				t.Fatalf("Test %d: unexpected error %v", i+1, e)
+			}
+			status = exitErr.ExitCode()
+		}
+		if status != testCase.exitStatus {
+			t.Errorf("Test %d: expected exit status %d, got %d", i+1, testCase.exitStatus, status)
+		}
+
+		var summary diffSummaryMessage
+		if e = json.Unmarshal(buf.Bytes(), &summary); e != nil {
+			t.Fatalf("Test %d: expected a single JSON summary, got %q: %v", i+1, buf.String(), e)
+		}
+		if summary.Identical != (testCase.exitStatus == 0) {
+			t.Errorf("Test %d: expected identical %v, got %v", i+1, testCase.exitStatus == 0, summary.Identical)
+		}
+		if !reflect.DeepEqual(summary.Differences, testCase.differences) {
+			t.Errorf("Test %d: expected differences %v, got %v", i+1, testCase.differences, summary.Differences)
+		}
+		if summary.DriftBytes != testCase.drift {
+			t.Errorf("Test %d: expected a drift of %d bytes, got %d", i+1, testCase.drift, summary.DriftBytes)
+		}
+	}
+}
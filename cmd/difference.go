@@ -41,6 +41,8 @@ const (
 	differInFirst                    // only in source (FIRST)
 	differInSecond                   // only in target (SECOND)
 	differInAASourceMTime            // differs in active-active source modtime
+	differInETag                     // differs in etag
+	differInChecksum                 // differs in checksum
 )
 
 func (d differType) String() string {
@@ -53,6 +55,10 @@ func (d differType) String() string {
 		return "metadata"
 	case differInAASourceMTime:
 		return "mm-source-mtime"
+	case differInETag:
+		return "etag"
+	case differInChecksum:
+		return "checksum"
 	case differInType:
 		return "type"
 	case differInFirst:
@@ -161,17 +167,18 @@ func metadataEqual(m1, m2 map[string]string) bool {
 }
 
 func objectDifference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool) (diffCh chan diffMessage) {
-	return difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, isMetadata, true, false, DirNone)
+	return difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, isMetadata, true, false, DirNone, mirrorCompareMtime)
 }
 
 func dirDifference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string) (diffCh chan diffMessage) {
-	return difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, false, false, true, DirFirst)
+	return difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, false, false, true, DirFirst, mirrorCompareMtime)
 }
 
-func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, isRecursive, returnSimilar bool, dirOpt DirOpt, diffCh chan<- diffMessage) *probe.Error {
+func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, isRecursive, returnSimilar bool, dirOpt DirOpt, compare mirrorCompare, diffCh chan<- diffMessage) *probe.Error {
 	// Set default values for listing.
-	srcCh := sourceClnt.List(ctx, ListOptions{Recursive: isRecursive, WithMetadata: isMetadata, ShowDir: dirOpt})
-	tgtCh := targetClnt.List(ctx, ListOptions{Recursive: isRecursive, WithMetadata: isMetadata, ShowDir: dirOpt})
+	srcCh := sourceClnt.List(ctx, ListOptions{Recursive: isRecursive, WithMetadata: isMetadata || compare.needsMetadata(), ShowDir: dirOpt})
+	// Compressed uploads are recognized from the metadata of the target.
+	tgtCh := targetClnt.List(ctx, ListOptions{Recursive: isRecursive, WithMetadata: isMetadata || globalCompress != "" || compare.needsMetadata(), ShowDir: dirOpt})
 
 	srcCtnt, srcOk := <-srcCh
 	tgtCtnt, tgtOk := <-tgtCh
@@ -268,39 +275,37 @@ func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sour
 				}
 				continue
 			}
+			diff := differInNone
 			if srcSize != tgtSize {
 				// Regular files differing in size.
-				diffCh <- diffMessage{
-					FirstURL:      srcCtnt.URL.String(),
-					SecondURL:     tgtCtnt.URL.String(),
-					Diff:          differInSize,
-					firstContent:  srcCtnt,
-					secondContent: tgtCtnt,
+				diff = differInSize
+			} else if srcType.IsRegular() {
+				var err *probe.Error
+				if diff, err = compare.differ(srcCtnt, tgtCtnt); err != nil {
+					diffCh <- diffMessage{Error: err.Trace(sourceURL, targetURL)}
+					diff = differInUnknown
 				}
 			} else if activeActiveModTimeUpdated(srcCtnt, tgtCtnt) {
-				diffCh <- diffMessage{
-					FirstURL:      srcCtnt.URL.String(),
-					SecondURL:     tgtCtnt.URL.String(),
-					Diff:          differInAASourceMTime,
-					firstContent:  srcCtnt,
-					secondContent: tgtCtnt,
-				}
-			} else if isMetadata &&
+				diff = differInAASourceMTime
+			}
+			if diff == differInNone && isMetadata &&
 				!metadataEqual(srcCtnt.UserMetadata, tgtCtnt.UserMetadata) &&
 				!metadataEqual(srcCtnt.Metadata, tgtCtnt.Metadata) {
-
 				// Regular files user requesting additional metadata to same file.
+				diff = differInMetadata
+			}
+			if diff != differInNone && diff != differInUnknown {
 				diffCh <- diffMessage{
 					FirstURL:      srcCtnt.URL.String(),
 					SecondURL:     tgtCtnt.URL.String(),
-					Diff:          differInMetadata,
+					Diff:          diff,
 					firstContent:  srcCtnt,
 					secondContent: tgtCtnt,
 				}
 			}
 
 			// No differ
-			if returnSimilar {
+			if diff == differInNone && returnSimilar {
 				diffCh <- diffMessage{
 					FirstURL:      srcCtnt.URL.String(),
 					SecondURL:     tgtCtnt.URL.String(),
@@ -328,14 +333,14 @@ func differenceInternal(ctx context.Context, sourceClnt, targetClnt Client, sour
 
 // objectDifference function finds the difference between all objects
 // recursively in sorted order from source and target.
-func difference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, isRecursive, returnSimilar bool, dirOpt DirOpt) (diffCh chan diffMessage) {
+func difference(ctx context.Context, sourceClnt, targetClnt Client, sourceURL, targetURL string, isMetadata bool, isRecursive, returnSimilar bool, dirOpt DirOpt, compare mirrorCompare) (diffCh chan diffMessage) {
 	diffCh = make(chan diffMessage, 10000)
 
 	go func() {
 		defer close(diffCh)
 
 		err := differenceInternal(ctx, sourceClnt, targetClnt, sourceURL, targetURL,
-			isMetadata, isRecursive, returnSimilar, dirOpt, diffCh)
+			isMetadata, isRecursive, returnSimilar, dirOpt, compare, diffCh)
 		if err != nil {
 			// handle this specifically for filesystem related errors.
 			switch err.ToGoError().(type) {
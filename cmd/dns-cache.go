@@ -0,0 +1,410 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// Durations successful lookups are cached for when the resolver
+	// does not report the TTL of the records, and at most.
+	dnsCacheDefaultTTL = time.Minute
+	dnsCacheMaxTTL     = 10 * time.Minute
+	// Duration failed DNS lookups are cached for.
+	dnsCacheNegativeTTL = 5 * time.Second
+	// Duration past their expiry expired entries are still used for when
+	// the resolver fails.
+	dnsCacheMaxStale = time.Hour
+
+	// Delay before the next address is dialed while the previous attempt
+	// is still pending, as recommended by RFC 8305.
+	happyEyeballsDelay = 250 * time.Millisecond
+
+	// File of the mc config folder persisting the DNS cache across runs.
+	dnsCacheFile = "dns-cache.json"
+)
+
+// Addresses of hosts set with --resolve, used instead of DNS.
+var globalResolveOverrides map[string][]string
+
+// parseResolveOverrides parses the HOST:IP values of --resolve.
+func parseResolveOverrides(values []string) (map[string][]string, error) {
+	overrides := make(map[string][]string, len(values))
+	for _, value := range values {
+		i := strings.Index(value, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid --resolve %q, expected HOST:IP", value)
+		}
+		host, ip := strings.ToLower(value[:i]), strings.Trim(value[i+1:], "[]")
+		if net.ParseIP(ip) == nil {
+			return nil, fmt.Errorf("invalid --resolve %q, %q is not an IP address", value, ip)
+		}
+		overrides[host] = append(overrides[host], ip)
+	}
+	return overrides, nil
+}
+
+type dnsCacheEntry struct {
+	Addrs   []string  `json:"addrs"`
+	Expires time.Time `json:"expires"`
+	err     error
+}
+
+// usable tells if the entry can still be used when the resolver fails.
+func (e dnsCacheEntry) usable(now time.Time) bool {
+	return e.err == nil && now.Before(e.Expires.Add(dnsCacheMaxStale))
+}
+
+// dnsCache caches the addresses of hosts for the TTL of their records,
+// successful lookups are kept in the mc config folder so that later runs
+// skip slow resolvers.
+type dnsCache struct {
+	mu         sync.Mutex
+	entries    map[string]dnsCacheEntry
+	path       string
+	lookupHost func(ctx context.Context, host string) ([]string, time.Duration, error)
+}
+
+func newDNSCache(path string) *dnsCache {
+	c := &dnsCache{
+		entries:    make(map[string]dnsCacheEntry),
+		path:       path,
+		lookupHost: lookupHostTTL,
+	}
+	if path != "" {
+		if data, e := ioutil.ReadFile(path); e == nil {
+			json.Unmarshal(data, &c.entries)
+		}
+		now := time.Now()
+		for host, entry := range c.entries {
+			if !entry.usable(now) {
+				delete(c.entries, host)
+			}
+		}
+	}
+	return c
+}
+
+// lookup returns the addresses of host, from the cache while they are
+// fresh. An expired entry is still used when the resolver fails, up to
+// dnsCacheMaxStale past its expiry.
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && now.Before(entry.Expires) {
+		return entry.Addrs, entry.err
+	}
+
+	addrs, ttl, e := c.lookupHost(ctx, host)
+	if e != nil {
+		if ok && entry.usable(now) {
+			return entry.Addrs, nil
+		}
+		if dnsErr, isDNSErr := e.(*net.DNSError); isDNSErr && dnsErr.IsNotFound {
+			c.mu.Lock()
+			c.entries[host] = dnsCacheEntry{Expires: now.Add(dnsCacheNegativeTTL), err: e}
+			c.mu.Unlock()
+		}
+		return nil, e
+	}
+
+	if ttl < 0 {
+		ttl = dnsCacheDefaultTTL
+	}
+	if ttl > dnsCacheMaxTTL {
+		ttl = dnsCacheMaxTTL
+	}
+	c.mu.Lock()
+	c.entries[host] = dnsCacheEntry{Addrs: addrs, Expires: now.Add(ttl)}
+	c.save()
+	c.mu.Unlock()
+	return addrs, nil
+}
+
+// save writes the successful lookups which can still be used, the caller
+// holds the lock.
+func (c *dnsCache) save() {
+	if c.path == "" {
+		return
+	}
+	now := time.Now()
+	entries := make(map[string]dnsCacheEntry, len(c.entries))
+	for host, entry := range c.entries {
+		if entry.usable(now) {
+			entries[host] = entry
+		}
+	}
+	data, e := json.Marshal(entries)
+	if e != nil {
+		return
+	}
+	tmpFile := c.path + ".tmp." + fmt.Sprint(os.Getpid())
+	if e = ioutil.WriteFile(tmpFile, data, 0o600); e != nil {
+		return
+	}
+	if e = os.Rename(tmpFile, c.path); e != nil {
+		os.Remove(tmpFile)
+	}
+}
+
+// lookupHostTTL resolves host and returns the lowest TTL of the records
+// answered by the DNS servers, or -1 when they were not seen, e.g. for
+// hosts of /etc/hosts or with the resolver of the system.
+func lookupHostTTL(ctx context.Context, host string) ([]string, time.Duration, error) {
+	ttl := &dnsTTL{seconds: -1}
+	dialer := &net.Dialer{}
+	resolver := &net.Resolver{
+		// The system resolver of macOS and Windows knows about the DNS
+		// servers of VPNs, the TTL is then not known.
+		PreferGo: runtime.GOOS != "darwin" && runtime.GOOS != "windows",
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			conn, e := dialer.DialContext(ctx, network, address)
+			if e != nil {
+				return nil, e
+			}
+			return &dnsTTLConn{Conn: conn, stream: !strings.HasPrefix(network, "udp"), ttl: ttl}, nil
+		},
+	}
+	addrs, e := resolver.LookupHost(ctx, host)
+	return addrs, ttl.get(), e
+}
+
+// dnsTTL is the lowest TTL of the answers of the DNS lookups of a host,
+// the A and AAAA records are queried in parallel.
+type dnsTTL struct {
+	mu      sync.Mutex
+	seconds int64
+}
+
+func (t *dnsTTL) observe(seconds uint32) {
+	t.mu.Lock()
+	if t.seconds < 0 || int64(seconds) < t.seconds {
+		t.seconds = int64(seconds)
+	}
+	t.mu.Unlock()
+}
+
+func (t *dnsTTL) get() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seconds < 0 {
+		return -1
+	}
+	return time.Duration(t.seconds) * time.Second
+}
+
+// dnsTTLConn reads the TTLs of the answers received on the connection of
+// the resolver to a DNS server. Messages over TCP are prefixed with their
+// length.
+type dnsTTLConn struct {
+	net.Conn
+	stream bool
+	buf    []byte
+	ttl    *dnsTTL
+}
+
+func (c *dnsTTLConn) Read(p []byte) (int, error) {
+	n, e := c.Conn.Read(p)
+	if n > 0 {
+		if !c.stream {
+			c.parse(p[:n])
+		} else {
+			c.buf = append(c.buf, p[:n]...)
+			for len(c.buf) >= 2 {
+				size := 2 + (int(c.buf[0])<<8 | int(c.buf[1]))
+				if len(c.buf) < size {
+					break
+				}
+				c.parse(c.buf[2:size])
+				c.buf = c.buf[size:]
+			}
+		}
+	}
+	return n, e
+}
+
+// parse observes the TTLs of the answers of a DNS message.
+func (c *dnsTTLConn) parse(msg []byte) {
+	var parser dnsmessage.Parser
+	if _, e := parser.Start(msg); e != nil {
+		return
+	}
+	if e := parser.SkipAllQuestions(); e != nil {
+		return
+	}
+	for {
+		header, e := parser.AnswerHeader()
+		if e != nil {
+			return
+		}
+		switch header.Type {
+		case dnsmessage.TypeA, dnsmessage.TypeAAAA, dnsmessage.TypeCNAME:
+			c.ttl.observe(header.TTL)
+		}
+		if e = parser.SkipAnswer(); e != nil {
+			return
+		}
+	}
+}
+
+var (
+	globalDNSCache     *dnsCache
+	globalDNSCacheOnce sync.Once
+)
+
+func getDNSCache() *dnsCache {
+	globalDNSCacheOnce.Do(func() {
+		path := ""
+		if dir, err := getMcConfigDir(); err == nil {
+			path = filepath.Join(dir, dnsCacheFile)
+		}
+		globalDNSCache = newDNSCache(path)
+	})
+	return globalDNSCache
+}
+
+// happyEyeballsOrder interleaves IPv6 and IPv4 addresses starting with
+// the family of the first address, only keeping those of the network.
+func happyEyeballsOrder(network string, addrs []string) []string {
+	var primary, fallback []string
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			continue
+		}
+		isIPv4 := ip.To4() != nil
+		if (network == "tcp4" && !isIPv4) || (network == "tcp6" && isIPv4) {
+			continue
+		}
+		if len(primary) == 0 || (net.ParseIP(primary[0]).To4() != nil) == isIPv4 {
+			primary = append(primary, addr)
+		} else {
+			fallback = append(fallback, addr)
+		}
+	}
+	ordered := make([]string, 0, len(primary)+len(fallback))
+	for i := 0; i < len(primary) || i < len(fallback); i++ {
+		if i < len(primary) {
+			ordered = append(ordered, primary[i])
+		}
+		if i < len(fallback) {
+			ordered = append(ordered, fallback[i])
+		}
+	}
+	return ordered
+}
+
+// dialHappyEyeballs dials the addresses in turn, starting the next one
+// when the previous attempt failed or is still pending after a delay,
+// and returns the first connection established.
+func dialHappyEyeballs(ctx context.Context, dialer *net.Dialer, network string, addrs []string, port string) (net.Conn, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	results := make(chan dialResult, len(addrs))
+	next, pending := 0, 0
+	var delay <-chan time.Time
+	dialNext := func() {
+		addr := net.JoinHostPort(addrs[next], port)
+		go func() {
+			conn, e := dialer.DialContext(ctx, network, addr)
+			results <- dialResult{conn, e}
+		}()
+		next++
+		pending++
+		delay = nil
+		if next < len(addrs) {
+			delay = time.After(happyEyeballsDelay)
+		}
+	}
+
+	var firstErr error
+	dialNext()
+	for pending > 0 {
+		select {
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				// Close the connections of the slower attempts.
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if late := <-results; late.conn != nil {
+							late.conn.Close()
+						}
+					}
+				}(pending)
+				return result.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = result.err
+			}
+			if next < len(addrs) {
+				dialNext()
+			}
+		case <-delay:
+			dialNext()
+		}
+	}
+	return nil, firstErr
+}
+
+// newCustomDialContext returns the dialer of all mc clients, resolving
+// host names through --resolve and the DNS cache and racing IPv6 and
+// IPv4 addresses.
+func newCustomDialContext(timeout, keepAlive time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Timeout:   timeout,
+		KeepAlive: keepAlive,
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, e := net.SplitHostPort(addr)
+		if e != nil || net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+		addrs, ok := globalResolveOverrides[strings.ToLower(host)]
+		if !ok {
+			if addrs, e = getDNSCache().lookup(ctx, host); e != nil {
+				return nil, e
+			}
+		}
+		addrs = happyEyeballsOrder(network, addrs)
+		if len(addrs) == 0 {
+			return nil, &net.DNSError{Err: "no suitable address found", Name: host}
+		}
+		return dialHappyEyeballs(ctx, dialer, network, addrs, port)
+	}
+}
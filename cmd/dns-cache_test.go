@@ -0,0 +1,201 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestParseResolveOverrides(t *testing.T) {
+	overrides, err := parseResolveOverrides([]string{"play.min.io:10.0.0.1", "Play.min.io:[::1]", "s3.local:192.168.1.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]string{
+		"play.min.io": {"10.0.0.1", "::1"},
+		"s3.local":    {"192.168.1.5"},
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("expected %v, got %v", expected, overrides)
+	}
+	for _, value := range []string{"play.min.io", ":10.0.0.1", "play.min.io:localhost"} {
+		if _, err = parseResolveOverrides([]string{value}); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestHappyEyeballsOrder(t *testing.T) {
+	addrs := []string{"::1", "::2", "10.0.0.1", "10.0.0.2", "10.0.0.3"}
+	testCases := []struct {
+		network  string
+		expected []string
+	}{
+		{"tcp", []string{"::1", "10.0.0.1", "::2", "10.0.0.2", "10.0.0.3"}},
+		{"tcp4", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"tcp6", []string{"::1", "::2"}},
+	}
+	for i, testCase := range testCases {
+		if ordered := happyEyeballsOrder(testCase.network, addrs); !reflect.DeepEqual(ordered, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, ordered)
+		}
+	}
+}
+
+func TestDNSCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dnsCacheFile)
+	lookups := 0
+	fail := false
+	ttl := time.Duration(-1)
+	c := newDNSCache(path)
+	c.lookupHost = func(ctx context.Context, host string) ([]string, time.Duration, error) {
+		lookups++
+		if fail {
+			return nil, 0, errors.New("resolver unavailable")
+		}
+		return []string{"10.0.0.1"}, ttl, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		addrs, err := c.lookup(context.Background(), "play.min.io")
+		if err != nil || !reflect.DeepEqual(addrs, []string{"10.0.0.1"}) {
+			t.Fatalf("unexpected lookup result %v, %v", addrs, err)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("expected 1 lookup, got %d", lookups)
+	}
+	if expires := time.Until(c.entries["play.min.io"].Expires); expires > dnsCacheDefaultTTL {
+		t.Errorf("expected an unknown TTL to default to %s, expires in %s", dnsCacheDefaultTTL, expires)
+	}
+
+	// The TTL of the records is honored, up to dnsCacheMaxTTL.
+	testCases := []struct {
+		ttl      time.Duration
+		expected time.Duration
+	}{
+		{0, 0},
+		{30 * time.Second, 30 * time.Second},
+		{24 * time.Hour, dnsCacheMaxTTL},
+	}
+	for i, testCase := range testCases {
+		ttl = testCase.ttl
+		delete(c.entries, "play.min.io")
+		before := time.Now()
+		if _, err := c.lookup(context.Background(), "play.min.io"); err != nil {
+			t.Fatal(err)
+		}
+		if expires := c.entries["play.min.io"].Expires.Sub(before); expires < testCase.expected || expires > testCase.expected+time.Second {
+			t.Errorf("Test %d: expected the entry to expire in %s, got %s", i+1, testCase.expected, expires)
+		}
+	}
+
+	// A stale entry is used when the resolver fails, but not for longer
+	// than dnsCacheMaxStale.
+	fail = true
+	c.entries["play.min.io"] = dnsCacheEntry{Addrs: []string{"10.0.0.1"}, Expires: time.Now().Add(-time.Second)}
+	if addrs, err := c.lookup(context.Background(), "play.min.io"); err != nil || len(addrs) != 1 {
+		t.Errorf("expected the stale entry, got %v, %v", addrs, err)
+	}
+	c.entries["play.min.io"] = dnsCacheEntry{Addrs: []string{"10.0.0.1"}, Expires: time.Now().Add(-dnsCacheMaxStale - time.Second)}
+	if addrs, err := c.lookup(context.Background(), "play.min.io"); err == nil {
+		t.Errorf("expected an entry stale for too long to be ignored, got %v", addrs)
+	}
+
+	// Successful lookups are persisted for the next runs.
+	fail = false
+	ttl = time.Minute
+	if _, err := c.lookup(context.Background(), "play.min.io"); err != nil {
+		t.Fatal(err)
+	}
+	if addrs, ok := newDNSCache(path).entries["play.min.io"]; !ok || !reflect.DeepEqual(addrs.Addrs, []string{"10.0.0.1"}) {
+		t.Errorf("expected the persisted entry, got %v", addrs)
+	}
+	c.entries["play.min.io"] = dnsCacheEntry{Addrs: []string{"10.0.0.1"}, Expires: time.Now().Add(-dnsCacheMaxStale - time.Second)}
+	c.save()
+	if _, ok := newDNSCache(path).entries["play.min.io"]; ok {
+		t.Errorf("expected an entry stale for too long not to be persisted")
+	}
+}
+
+func TestDNSTTLConn(t *testing.T) {
+	builder := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true})
+	builder.StartQuestions()
+	name := dnsmessage.MustNewName("play.min.io.")
+	builder.Question(dnsmessage.Question{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET})
+	builder.StartAnswers()
+	for _, ttl := range []uint32{300, 42} {
+		header := dnsmessage.ResourceHeader{Name: name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl}
+		builder.AResource(header, dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}})
+	}
+	msg, err := builder.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stream := range []bool{false, true} {
+		server, client := net.Pipe()
+		go func() {
+			data := msg
+			if stream {
+				data = append([]byte{byte(len(msg) >> 8), byte(len(msg))}, msg...)
+			}
+			server.Write(data)
+			server.Close()
+		}()
+		ttl := &dnsTTL{seconds: -1}
+		conn := &dnsTTLConn{Conn: client, stream: stream, ttl: ttl}
+		// Messages over TCP may be read in several parts.
+		buf := make([]byte, 512)
+		if stream {
+			buf = buf[:7]
+		}
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				break
+			}
+		}
+		if got := ttl.get(); got != 42*time.Second {
+			t.Errorf("stream %v: expected the lowest TTL of 42s, got %s", stream, got)
+		}
+	}
+}
+
+func TestDialHappyEyeballs(t *testing.T) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	// 127.0.0.2 refuses the connection, the next address is then dialed.
+	conn, err := dialHappyEyeballs(context.Background(), &net.Dialer{Timeout: time.Second}, "tcp", []string{"127.0.0.2", "127.0.0.1"}, port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
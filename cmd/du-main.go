@@ -21,6 +21,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -49,7 +50,19 @@ var (
 		},
 		cli.BoolFlag{
 			Name:  "versions",
-			Usage: "include all object versions",
+			Usage: "include all object versions, reporting noncurrent versions and delete markers separately",
+		},
+		cli.BoolFlag{
+			Name:  "watch",
+			Usage: "sample the usage periodically and print the ingest and delete rates",
+		},
+		cli.StringFlag{
+			Name:  "interval",
+			Usage: "interval between two samples with --watch, e.g. 30s (default 1m)",
+		},
+		cli.BoolFlag{
+			Name:  "breakdown",
+			Usage: "report the size and number of objects of each storage class and tier",
 		},
 	}
 )
@@ -84,24 +97,113 @@ EXAMPLES:
   3. Summarize disk usage of 'jazz-songs' bucket at a fixed date/time
      {{.Prompt}} {{.HelpName}} --rewind "2020.01.01" s3/jazz-songs/
 
-  4. Summarize disk usage of 'jazz-songs' bucket with all objects versions
+  4. Summarize disk usage of 'jazz-songs' bucket with all objects versions, showing the space used by noncurrent versions.
      {{.Prompt}} {{.HelpName}} --versions s3/jazz-songs/
+
+  5. Monitor the write rate of a bucket during a migration, sampling every minute.
+     {{.Prompt}} {{.HelpName}} --watch --interval 1m s3/jazz-songs
+
+  6. Summarize disk usage of 'jazz-songs' bucket by storage class, showing the data transitioned to remote tiers.
+     {{.Prompt}} {{.HelpName}} --breakdown s3/jazz-songs
 `,
 }
 
+// duClassUsage is the usage of a storage class, or of a remote tier
+// objects were transitioned to.
+type duClassUsage struct {
+	Size    int64 `json:"size"`
+	Objects int64 `json:"objects"`
+}
+
+// duVersionsUsage is the usage of the noncurrent versions of a prefix,
+// the objects kept by versioning once overwritten or deleted.
+type duVersionsUsage struct {
+	NoncurrentSize     int64 `json:"noncurrentSize"`
+	NoncurrentVersions int64 `json:"noncurrentVersions"`
+	DeleteMarkers      int64 `json:"deleteMarkers"`
+}
+
+// duPrefixUsage is the usage of a prefix, by storage class with --breakdown
+// and of its noncurrent versions with --versions.
+type duPrefixUsage struct {
+	size     int64
+	classes  map[string]duClassUsage
+	versions *duVersionsUsage
+}
+
+// addVersion adds a listed version, the latest versions being objects.
+func (u *duPrefixUsage) addVersion(content *ClientContent) {
+	switch {
+	case content.IsDeleteMarker:
+		u.versions.DeleteMarkers++
+	case content.VersionID != "" && !content.IsLatest:
+		u.versions.NoncurrentSize += content.Size
+		u.versions.NoncurrentVersions++
+	}
+}
+
+func (u *duPrefixUsage) addObject(storageClass string, size int64) {
+	u.size += size
+	if u.classes == nil {
+		return
+	}
+	if storageClass == "" {
+		storageClass = "STANDARD"
+	}
+	class := u.classes[storageClass]
+	class.Size += size
+	class.Objects++
+	u.classes[storageClass] = class
+}
+
+func (u *duPrefixUsage) add(o duPrefixUsage) {
+	u.size += o.size
+	if u.versions != nil && o.versions != nil {
+		u.versions.NoncurrentSize += o.versions.NoncurrentSize
+		u.versions.NoncurrentVersions += o.versions.NoncurrentVersions
+		u.versions.DeleteMarkers += o.versions.DeleteMarkers
+	}
+	for storageClass, usage := range o.classes {
+		class := u.classes[storageClass]
+		class.Size += usage.Size
+		class.Objects += usage.Objects
+		u.classes[storageClass] = class
+	}
+}
+
 // Structured message depending on the type of console.
 type duMessage struct {
-	Prefix string `json:"prefix"`
-	Size   int64  `json:"size"`
-	Status string `json:"status"`
+	Prefix    string                  `json:"prefix"`
+	Size      int64                   `json:"size"`
+	Breakdown map[string]duClassUsage `json:"breakdown,omitempty"`
+	Versions  *duVersionsUsage        `json:"versions,omitempty"`
+	Status    string                  `json:"status"`
 }
 
 // Colorized message for console printing.
 func (r duMessage) String() string {
 	humanSize := strings.Join(strings.Fields(humanize.IBytes(uint64(r.Size))), "")
 
-	return fmt.Sprintf("%s\t%s", console.Colorize("Size", humanSize),
+	msg := fmt.Sprintf("%s\t%s", console.Colorize("Size", humanSize),
 		console.Colorize("Prefix", r.Prefix))
+
+	storageClasses := make([]string, 0, len(r.Breakdown))
+	for storageClass := range r.Breakdown {
+		storageClasses = append(storageClasses, storageClass)
+	}
+	sort.Strings(storageClasses)
+	for _, storageClass := range storageClasses {
+		usage := r.Breakdown[storageClass]
+		humanSize = strings.Join(strings.Fields(humanize.IBytes(uint64(usage.Size))), "")
+		msg += fmt.Sprintf("\n  %s\t%-16s %d object(s)", console.Colorize("Size", humanSize),
+			console.Colorize("StorageClass", storageClass), usage.Objects)
+	}
+	if r.Versions != nil {
+		humanSize = strings.Join(strings.Fields(humanize.IBytes(uint64(r.Versions.NoncurrentSize))), "")
+		msg += fmt.Sprintf("\n  %s\t%-16s %d version(s), %d delete marker(s)", console.Colorize("Size", humanSize),
+			console.Colorize("Noncurrent", "noncurrent"), r.Versions.NoncurrentVersions, r.Versions.DeleteMarkers)
+	}
+	return msg
 }
 
 // JSON'ified message for scripting.
@@ -111,7 +213,15 @@ func (r duMessage) JSON() string {
 	return string(msgBytes)
 }
 
-func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool, depth int, encKeyDB map[string][]prefixSSEPair) (int64, error) {
+func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions, breakdown bool, depth int, encKeyDB map[string][]prefixSSEPair) (duPrefixUsage, error) {
+	var usage duPrefixUsage
+	if breakdown {
+		usage.classes = make(map[string]duClassUsage)
+	}
+	if withVersions {
+		usage.versions = &duVersionsUsage{}
+	}
+
 	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
 	if !strings.HasSuffix(targetURL, "/") {
 		targetURL += "/"
@@ -120,7 +230,7 @@ func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool
 	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
 	if pErr != nil {
 		errorIf(pErr.Trace(urlStr), "Failed to summarize disk usage `"+urlStr+"`.")
-		return 0, exitStatus(globalErrorExitStatus) // End of journey.
+		return usage, exitStatus(globalErrorExitStatus) // End of journey.
 	}
 
 	// No disk usage details below this level,
@@ -130,10 +240,10 @@ func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool
 	contentCh := clnt.List(ctx, ListOptions{
 		TimeRef:           timeRef,
 		WithOlderVersions: withVersions,
+		WithDeleteMarkers: withVersions,
 		Recursive:         recursive,
 		ShowDir:           DirFirst,
 	})
-	size := int64(0)
 	for content := range contentCh {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
@@ -145,7 +255,7 @@ func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool
 				continue
 			}
 			errorIf(content.Err.Trace(urlStr), "Failed to find disk usage of `"+urlStr+"` recursively.")
-			return 0, exitStatus(globalErrorExitStatus)
+			return usage, exitStatus(globalErrorExitStatus)
 		}
 		if content.URL.String() == targetURL {
 			continue
@@ -161,13 +271,18 @@ func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool
 			if targetAlias != "" {
 				subDirAlias = targetAlias + "/" + content.URL.Path
 			}
-			used, err := du(ctx, subDirAlias, timeRef, withVersions, depth, encKeyDB)
+			used, err := du(ctx, subDirAlias, timeRef, withVersions, breakdown, depth, encKeyDB)
 			if err != nil {
-				return 0, err
+				return usage, err
+			}
+			usage.add(used)
+		} else if !content.Type.IsDir() {
+			if usage.versions != nil {
+				usage.addVersion(content)
+			}
+			if !content.IsDeleteMarker {
+				usage.addObject(content.StorageClass, content.Size)
 			}
-			size += used
-		} else {
-			size += content.Size
 		}
 	}
 
@@ -178,13 +293,15 @@ func du(ctx context.Context, urlStr string, timeRef time.Time, withVersions bool
 		}
 
 		printMsg(duMessage{
-			Prefix: strings.Trim(u.Path, "/"),
-			Size:   size,
-			Status: "success",
+			Prefix:    strings.Trim(u.Path, "/"),
+			Size:      usage.size,
+			Breakdown: usage.classes,
+			Versions:  usage.versions,
+			Status:    "success",
 		})
 	}
 
-	return size, nil
+	return usage, nil
 }
 
 // main for du command.
@@ -197,6 +314,8 @@ func mainDu(cliCtx *cli.Context) error {
 	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
 	console.SetColor("Prefix", color.New(color.FgCyan, color.Bold))
 	console.SetColor("Size", color.New(color.FgYellow))
+	console.SetColor("StorageClass", color.New(color.FgMagenta))
+	console.SetColor("Noncurrent", color.New(color.FgRed))
 
 	ctx, cancelRm := context.WithCancel(globalContext)
 	defer cancelRm()
@@ -220,13 +339,25 @@ func mainDu(cliCtx *cli.Context) error {
 	withVersions := cliCtx.Bool("versions")
 	timeRef := parseRewindFlag(cliCtx.String("rewind"))
 
+	breakdown := cliCtx.Bool("breakdown")
+
+	if cliCtx.Bool("watch") {
+		if !timeRef.IsZero() {
+			fatalIf(errInvalidArgument(), "--watch cannot be used with --rewind.")
+		}
+		if breakdown {
+			fatalIf(errInvalidArgument(), "--watch cannot be used with --breakdown.")
+		}
+		return duWatch(ctx, cliCtx, withVersions)
+	}
+
 	var duErr error
 	for _, urlStr := range cliCtx.Args() {
 		if !isAliasURLDir(ctx, urlStr, nil, time.Time{}) {
 			fatalIf(errInvalidArgument().Trace(urlStr), fmt.Sprintf("Source `%s` is not a folder. Only folders are supported by 'du' command.", urlStr))
 		}
 
-		if _, err := du(ctx, urlStr, timeRef, withVersions, depth, encKeyDB); duErr == nil {
+		if _, err := du(ctx, urlStr, timeRef, withVersions, breakdown, depth, encKeyDB); duErr == nil {
 			duErr = err
 		}
 	}
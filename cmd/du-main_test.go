@@ -0,0 +1,80 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDuPrefixUsage(t *testing.T) {
+	usage := duPrefixUsage{classes: make(map[string]duClassUsage)}
+	usage.addObject("", 100)
+	usage.addObject("STANDARD", 50)
+
+	sub := duPrefixUsage{classes: make(map[string]duClassUsage)}
+	sub.addObject("WARM-TIER", 1000)
+	sub.addObject("STANDARD", 10)
+	usage.add(sub)
+
+	if usage.size != 1160 {
+		t.Errorf("expected size 1160, got %d", usage.size)
+	}
+	expected := map[string]duClassUsage{
+		"STANDARD":  {Size: 160, Objects: 3},
+		"WARM-TIER": {Size: 1000, Objects: 1},
+	}
+	if !reflect.DeepEqual(usage.classes, expected) {
+		t.Errorf("expected %v, got %v", expected, usage.classes)
+	}
+
+	// Without --breakdown only the size is summed.
+	total := duPrefixUsage{}
+	total.addObject("STANDARD", 10)
+	total.add(duPrefixUsage{size: 5})
+	if total.size != 15 || total.classes != nil {
+		t.Errorf("expected size 15 without classes, got %d and %v", total.size, total.classes)
+	}
+}
+
+func TestDuPrefixUsageVersions(t *testing.T) {
+	usage := duPrefixUsage{versions: &duVersionsUsage{}}
+	for _, content := range []*ClientContent{
+		{VersionID: "v3", IsLatest: true, Size: 100},
+		{VersionID: "v2", Size: 80},
+		{VersionID: "v1", Size: 60},
+		{VersionID: "v5", IsLatest: true, IsDeleteMarker: true},
+		{VersionID: "v4", Size: 10},
+		// Objects of unversioned buckets are current.
+		{Size: 5},
+	} {
+		usage.addVersion(content)
+		if !content.IsDeleteMarker {
+			usage.addObject(content.StorageClass, content.Size)
+		}
+	}
+	usage.add(duPrefixUsage{size: 40, versions: &duVersionsUsage{NoncurrentSize: 40, NoncurrentVersions: 2, DeleteMarkers: 1}})
+
+	if usage.size != 295 {
+		t.Errorf("expected size 295, got %d", usage.size)
+	}
+	expected := duVersionsUsage{NoncurrentSize: 190, NoncurrentVersions: 5, DeleteMarkers: 2}
+	if *usage.versions != expected {
+		t.Errorf("expected %+v, got %+v", expected, *usage.versions)
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// Default interval between two samples of du --watch.
+const defaultDuWatchInterval = time.Minute
+
+// duUsage is a sample of the usage of a prefix. The objects written
+// since the previous sample are counted apart to tell writes from
+// deletes.
+type duUsage struct {
+	Time       time.Time
+	Objects    int64
+	Size       int64
+	NewObjects int64
+	NewSize    int64
+}
+
+// duSample lists the prefix recursively and returns its usage, counting
+// the objects modified after since as new.
+func duSample(ctx context.Context, urlStr string, since time.Time, withVersions bool) (duUsage, *probe.Error) {
+	usage := duUsage{Time: time.Now()}
+	targetAlias, targetURL, _ := mustExpandAlias(urlStr)
+	if !strings.HasSuffix(targetURL, "/") {
+		targetURL += "/"
+	}
+	clnt, err := newClientFromAlias(targetAlias, targetURL)
+	if err != nil {
+		return usage, err
+	}
+	for content := range clnt.List(ctx, ListOptions{
+		WithOlderVersions: withVersions,
+		Recursive:         true,
+		ShowDir:           DirNone,
+	}) {
+		if content.Err != nil {
+			switch content.Err.ToGoError().(type) {
+			case BrokenSymlink, TooManyLevelsSymlink, PathNotFound, ObjectOnGlacier:
+				continue
+			}
+			return usage, content.Err.Trace(urlStr)
+		}
+		if content.Type.IsDir() || content.IsDeleteMarker {
+			continue
+		}
+		usage.Objects++
+		usage.Size += content.Size
+		if content.Time.After(since) {
+			usage.NewObjects++
+			usage.NewSize += content.Size
+		}
+	}
+	return usage, nil
+}
+
+// duWatchMessage holds a sample of du --watch and the changes since the
+// previous sample. Deleted objects include the overwritten ones.
+type duWatchMessage struct {
+	Status         string    `json:"status"`
+	Prefix         string    `json:"prefix"`
+	Time           time.Time `json:"time"`
+	Objects        int64     `json:"objects"`
+	Size           int64     `json:"size"`
+	ObjectsDelta   int64     `json:"objectsDelta"`
+	SizeDelta      int64     `json:"sizeDelta"`
+	IngestObjects  int64     `json:"ingestObjects"`
+	IngestSize     int64     `json:"ingestSize"`
+	IngestRate     float64   `json:"ingestRate"`
+	DeletedObjects int64     `json:"deletedObjects"`
+	DeletedSize    int64     `json:"deletedSize"`
+	DeleteRate     float64   `json:"deleteRate"`
+	first          bool
+}
+
+// newDuWatchMessage returns the message of the current sample, with the
+// rates computed against the previous one when any.
+func newDuWatchMessage(prefix string, prev *duUsage, cur duUsage) duWatchMessage {
+	msg := duWatchMessage{
+		Prefix:  prefix,
+		Time:    cur.Time,
+		Objects: cur.Objects,
+		Size:    cur.Size,
+		first:   prev == nil,
+	}
+	if prev == nil {
+		return msg
+	}
+	msg.ObjectsDelta = cur.Objects - prev.Objects
+	msg.SizeDelta = cur.Size - prev.Size
+	msg.IngestObjects = cur.NewObjects
+	msg.IngestSize = cur.NewSize
+	msg.DeletedObjects = prev.Objects + cur.NewObjects - cur.Objects
+	msg.DeletedSize = prev.Size + cur.NewSize - cur.Size
+	// Objects written and deleted in the same interval are never seen.
+	if msg.DeletedObjects < 0 {
+		msg.DeletedObjects = 0
+	}
+	if msg.DeletedSize < 0 {
+		msg.DeletedSize = 0
+	}
+	if elapsed := cur.Time.Sub(prev.Time).Seconds(); elapsed > 0 {
+		msg.IngestRate = float64(msg.IngestSize) / elapsed
+		msg.DeleteRate = float64(msg.DeletedSize) / elapsed
+	}
+	return msg
+}
+
+// signedIBytes formats a size change with its sign.
+func signedIBytes(n int64) string {
+	if n < 0 {
+		return "-" + humanize.IBytes(uint64(-n))
+	}
+	return "+" + humanize.IBytes(uint64(n))
+}
+
+func (m duWatchMessage) String() string {
+	line := fmt.Sprintf("[%s] %s\t%s, %d objects", m.Time.Format(printDate),
+		console.Colorize("Prefix", m.Prefix), console.Colorize("Size", humanize.IBytes(uint64(m.Size))), m.Objects)
+	if m.first {
+		return line
+	}
+	return line + fmt.Sprintf(" (%s, %+d objects), ingest %s/s (%d objects), delete %s/s (%d objects)",
+		signedIBytes(m.SizeDelta), m.ObjectsDelta,
+		humanize.IBytes(uint64(m.IngestRate)), m.IngestObjects,
+		humanize.IBytes(uint64(m.DeleteRate)), m.DeletedObjects)
+}
+
+func (m duWatchMessage) JSON() string {
+	m.Status = "success"
+	msgBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// duWatch samples the usage of the prefixes every interval, printing the
+// changes since the previous sample, until the command is interrupted.
+func duWatch(ctx context.Context, cliCtx *cli.Context, withVersions bool) error {
+	interval := defaultDuWatchInterval
+	if v := cliCtx.String("interval"); v != "" {
+		d, e := time.ParseDuration(v)
+		fatalIf(probe.NewError(e).Trace(v), "Unable to parse --interval.")
+		if d <= 0 {
+			fatalIf(errInvalidArgument().Trace(v), "--interval must be positive.")
+		}
+		interval = d
+	}
+
+	urls := cliCtx.Args()
+	prev := make([]*duUsage, len(urls))
+	for {
+		for i, urlStr := range urls {
+			since := time.Now()
+			if prev[i] != nil {
+				since = prev[i].Time
+			}
+			usage, err := duSample(ctx, urlStr, since, withVersions)
+			if err != nil {
+				errorIf(err.Trace(urlStr), "Failed to find disk usage of `"+urlStr+"`.")
+				continue
+			}
+			printMsg(newDuWatchMessage(strings.TrimSuffix(urlStr, "/"), prev[i], usage))
+			prev[i] = &usage
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
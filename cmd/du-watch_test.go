@@ -0,0 +1,60 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewDuWatchMessage(t *testing.T) {
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	prev := duUsage{Time: start, Objects: 100, Size: 1000}
+	testCases := []struct {
+		cur                         duUsage
+		objectsDelta, sizeDelta     int64
+		deletedObjects, deletedSize int64
+		ingestRate, deleteRate      float64
+	}{
+		// 10 new objects of 10 bytes, nothing deleted.
+		{duUsage{Time: start.Add(10 * time.Second), Objects: 110, Size: 1100, NewObjects: 10, NewSize: 100}, 10, 100, 0, 0, 10, 0},
+		// 20 objects deleted.
+		{duUsage{Time: start.Add(10 * time.Second), Objects: 80, Size: 800}, -20, -200, 20, 200, 0, 20},
+		// 5 objects overwritten with larger ones.
+		{duUsage{Time: start.Add(10 * time.Second), Objects: 100, Size: 1050, NewObjects: 5, NewSize: 100}, 0, 50, 5, 50, 10, 5},
+	}
+	for i, testCase := range testCases {
+		msg := newDuWatchMessage("s3/bucket", &prev, testCase.cur)
+		if msg.ObjectsDelta != testCase.objectsDelta || msg.SizeDelta != testCase.sizeDelta {
+			t.Errorf("Test %d: expected deltas %d objects and %d bytes, got %d and %d",
+				i+1, testCase.objectsDelta, testCase.sizeDelta, msg.ObjectsDelta, msg.SizeDelta)
+		}
+		if msg.DeletedObjects != testCase.deletedObjects || msg.DeletedSize != testCase.deletedSize {
+			t.Errorf("Test %d: expected %d objects and %d bytes deleted, got %d and %d",
+				i+1, testCase.deletedObjects, testCase.deletedSize, msg.DeletedObjects, msg.DeletedSize)
+		}
+		if msg.IngestRate != testCase.ingestRate || msg.DeleteRate != testCase.deleteRate {
+			t.Errorf("Test %d: expected rates %v and %v, got %v and %v",
+				i+1, testCase.ingestRate, testCase.deleteRate, msg.IngestRate, msg.DeleteRate)
+		}
+	}
+
+	if msg := newDuWatchMessage("s3/bucket", nil, prev); !msg.first || msg.ObjectsDelta != 0 {
+		t.Errorf("Expected the first sample to have no deltas, got %+v", msg)
+	}
+}
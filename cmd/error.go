@@ -78,6 +78,7 @@ func fatal(err *probe.Error, msg string, data ...interface{}) {
 			console.Fatalln(probe.NewError(e))
 		}
 		console.Println(string(json))
+		notifyCompletion(globalErrorExitStatus, err.ToGoError())
 		console.Fatalln()
 	}
 
@@ -114,6 +115,7 @@ func fatal(err *probe.Error, msg string, data ...interface{}) {
 		}
 	}
 
+	notifyCompletion(globalErrorExitStatus, err.ToGoError())
 	console.Fatalln(fmt.Sprintf("%s %s", msg, errmsg))
 }
 
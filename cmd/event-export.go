@@ -0,0 +1,142 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var eventExportFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "output, o",
+		Usage: "write the notification configuration to a file instead of STDOUT",
+	},
+}
+
+var eventExportCmd = cli.Command{
+	Name:         "export",
+	Usage:        "export bucket notifications in JSON format",
+	Action:       mainEventExport,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(eventExportFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Exports all notification configurations of a bucket, with their ARNs, events and filters,
+  in JSON format. The output can be imported into any bucket with 'mc event import'.
+
+EXAMPLES:
+  1. Export notification configurations of 'mybucket' to 'events.json' file.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket -o events.json
+
+  2. Print notification configurations of 'mybucket' to STDOUT.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket
+
+  3. Copy notification configurations of 'mybucket' to 'otherbucket' on another cluster.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket | mc event import otherminio/otherbucket
+`,
+}
+
+// eventExportMessage container for exported notification configurations.
+type eventExportMessage struct {
+	Status string               `json:"status"`
+	Target string               `json:"target"`
+	Output string               `json:"output,omitempty"`
+	Config []NotificationConfig `json:"config"`
+}
+
+func (e eventExportMessage) String() string {
+	if e.Output != "" {
+		return console.Colorize("EventExport", "Notification configuration of `"+e.Target+"` exported to `"+e.Output+"`.")
+	}
+	return string(marshalEventConfig(e.Config))
+}
+
+func (e eventExportMessage) JSON() string {
+	e.Status = "success"
+	msgBytes, err := json.MarshalIndent(e, "", " ")
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// marshalEventConfig returns the notification configurations as read
+// by 'mc event import'.
+func marshalEventConfig(configs []NotificationConfig) []byte {
+	if configs == nil {
+		configs = []NotificationConfig{}
+	}
+	configBytes, e := json.MarshalIndent(configs, "", " ")
+	fatalIf(probe.NewError(e), "Unable to export notification configuration.")
+	return configBytes
+}
+
+// checkEventExportSyntax - validate all the passed arguments
+func checkEventExportSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "export", 1) // last argument is exit code
+	}
+}
+
+func mainEventExport(cliCtx *cli.Context) error {
+	ctx, cancelEventExport := context.WithCancel(globalContext)
+	defer cancelEventExport()
+
+	console.SetColor("EventExport", color.New(color.FgGreen, color.Bold))
+
+	checkEventExportSyntax(cliCtx)
+
+	urlStr := cliCtx.Args().Get(0)
+	client, err := newClient(urlStr)
+	fatalIf(err.Trace(urlStr), "Unable to parse the provided url.")
+
+	s3Client, ok := client.(*S3Client)
+	if !ok {
+		fatalIf(errDummy().Trace(urlStr), "The provided url doesn't point to a S3 server.")
+	}
+
+	configs, err := s3Client.ListNotificationConfigs(ctx, "")
+	fatalIf(err.Trace(urlStr), "Unable to list notifications on the specified bucket.")
+
+	output := cliCtx.String("output")
+	if output != "" {
+		e := os.WriteFile(output, append(marshalEventConfig(configs), '\n'), 0o644)
+		fatalIf(probe.NewError(e).Trace(output), "Unable to write notification configuration.")
+	}
+
+	printMsg(eventExportMessage{
+		Target: urlStr,
+		Output: output,
+		Config: configs,
+	})
+	return nil
+}
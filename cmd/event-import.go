@@ -0,0 +1,159 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var eventImportCmd = cli.Command{
+	Name:         "import",
+	Usage:        "import bucket notifications in JSON format",
+	Action:       mainEventImport,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} TARGET [FILE]
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Replaces all notification configurations of a bucket with the ones exported by 'mc event export',
+  with or without --json, read from FILE or from STDIN. The ARNs must be configured on the target server.
+
+EXAMPLES:
+  1. Set notification configurations of 'mybucket' to the ones in 'events.json' file.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket events.json
+
+  2. Set notification configurations of 'mybucket' from STDIN.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket < events.json
+
+  3. Copy notification configurations of 'mybucket' to 'otherbucket' using the JSON output of export.
+     {{.Prompt}} mc event export --json myminio/mybucket | {{.HelpName}} otherminio/otherbucket
+`,
+}
+
+// eventImportMessage container for imported notification configurations.
+type eventImportMessage struct {
+	Status string `json:"status"`
+	Target string `json:"target"`
+	Count  int    `json:"count"`
+}
+
+func (e eventImportMessage) String() string {
+	return console.Colorize("EventImport", fmt.Sprintf("%d notification configuration(s) imported successfully to `%s`.", e.Count, e.Target))
+}
+
+func (e eventImportMessage) JSON() string {
+	e.Status = "success"
+	msgBytes, err := json.MarshalIndent(e, "", " ")
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// readEventConfig reads notification configurations exported by
+// 'mc event export', either as a plain list or as the message printed
+// with --json.
+func readEventConfig(reader io.Reader) ([]NotificationConfig, *probe.Error) {
+	data, e := ioutil.ReadAll(reader)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	var configs []NotificationConfig
+	if data = bytes.TrimSpace(data); len(data) > 0 && data[0] == '{' {
+		var msg eventExportMessage
+		if e = json.Unmarshal(data, &msg); e != nil {
+			return nil, probe.NewError(e)
+		}
+		configs = msg.Config
+	} else if e = json.Unmarshal(data, &configs); e != nil {
+		return nil, probe.NewError(e)
+	}
+	for _, config := range configs {
+		if config.Arn == "" || len(config.Events) == 0 {
+			return nil, errInvalidArgument().Trace(config.ID)
+		}
+	}
+	return configs, nil
+}
+
+// checkEventImportSyntax - validate all the passed arguments
+func checkEventImportSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 && len(ctx.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(ctx, "import", 1) // last argument is exit code
+	}
+}
+
+func mainEventImport(cliCtx *cli.Context) error {
+	ctx, cancelEventImport := context.WithCancel(globalContext)
+	defer cancelEventImport()
+
+	console.SetColor("EventImport", color.New(color.FgGreen, color.Bold))
+
+	checkEventImportSyntax(cliCtx)
+
+	args := cliCtx.Args()
+	urlStr := args.Get(0)
+
+	var reader io.Reader = os.Stdin
+	if len(args) == 2 {
+		f, e := os.Open(args.Get(1))
+		fatalIf(probe.NewError(e).Trace(args.Get(1)), "Unable to open notification configuration.")
+		defer f.Close()
+		reader = f
+	}
+
+	configs, err := readEventConfig(reader)
+	fatalIf(err.Trace(args...), "Unable to read notification configuration.")
+	if len(configs) == 0 {
+		// Abort here, importing nothing would remove all notifications of the bucket.
+		fatalIf(errDummy().Trace(args...), "The provided notification configuration is empty, aborting. Use 'mc event remove --force' to remove all notifications.")
+	}
+
+	client, err := newClient(urlStr)
+	fatalIf(err.Trace(urlStr), "Unable to parse the provided url.")
+
+	s3Client, ok := client.(*S3Client)
+	if !ok {
+		fatalIf(errDummy().Trace(urlStr), "The provided url doesn't point to a S3 server.")
+	}
+
+	fatalIf(s3Client.SetNotificationConfigs(ctx, configs).Trace(urlStr), "Unable to import notifications on the specified bucket.")
+
+	printMsg(eventImportMessage{
+		Target: urlStr,
+		Count:  len(configs),
+	})
+	return nil
+}
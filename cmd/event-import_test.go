@@ -0,0 +1,77 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEventConfigRoundTrip(t *testing.T) {
+	configs := []NotificationConfig{
+		{ID: "1", Arn: "arn:minio:sqs::1:webhook", Events: []string{"s3:ObjectCreated:*"}, Prefix: "photos/", Suffix: ".jpg"},
+		{ID: "2", Arn: "arn:minio:sqs::2:amqp", Events: []string{"s3:ObjectRemoved:*", "s3:ObjectAccessed:Get"}},
+	}
+	msg := eventExportMessage{Target: "myminio/mybucket", Config: configs}
+
+	testCases := []struct {
+		name string
+		data string
+	}{
+		{"plain", msg.String()},
+		{"json", msg.JSON()},
+		{"marshal", string(marshalEventConfig(configs))},
+	}
+	for _, testCase := range testCases {
+		got, err := readEventConfig(strings.NewReader(testCase.data))
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", testCase.name, err)
+		}
+		if !reflect.DeepEqual(got, configs) {
+			t.Errorf("%s: expected %v, got %v", testCase.name, configs, got)
+		}
+	}
+}
+
+func TestReadEventConfig(t *testing.T) {
+	testCases := []struct {
+		data    string
+		count   int
+		success bool
+	}{
+		{`[]`, 0, true},
+		{` {"status":"success","target":"a/b","config":[]}`, 0, true},
+		{`[{"arn":"arn:minio:sqs::1:webhook","events":["s3:ObjectCreated:*"]}]`, 1, true},
+		{`{"config":[{"arn":"arn:minio:sqs::1:webhook","events":["s3:ObjectCreated:*"]}]}`, 1, true},
+		{`[{"arn":"","events":["s3:ObjectCreated:*"]}]`, 0, false},
+		{`[{"arn":"arn:minio:sqs::1:webhook"}]`, 0, false},
+		{`{"config":[{"arn":"arn:minio:sqs::1:webhook"}]}`, 0, false},
+		{`not json`, 0, false},
+	}
+	for i, testCase := range testCases {
+		configs, err := readEventConfig(bytes.NewBufferString(testCase.data))
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if len(configs) != testCase.count {
+			t.Errorf("Test %d: expected %d configs, got %d", i+1, testCase.count, len(configs))
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/minio/pkg/env"
+)
+
+const (
+	// Experimental features are disabled until enabled by the user.
+	featureExperimental = "experimental"
+	// Deprecated features stay enabled until disabled by the user or removed.
+	featureDeprecated = "deprecated"
+
+	// Comma separated features to enable, or to disable with a '-' prefix,
+	// overriding the config file for one command.
+	mcEnvFeatures = "MC_FEATURES"
+)
+
+// mcFeature is a part of mc shipped behind a feature flag.
+type mcFeature struct {
+	name        string
+	usage       string
+	state       string
+	replacement string
+}
+
+// All experimental and deprecated features. A feature becoming stable
+// is removed from the list, as is a deprecated feature once removed.
+var mcFeatures = []mcFeature{
+	{
+		name:  "two-way-mirror",
+		usage: "mc mirror --two-way",
+		state: featureExperimental,
+	},
+	{
+		name:  "background-jobs",
+		usage: "mc job",
+		state: featureExperimental,
+	},
+	{
+		name:        "config-host",
+		usage:       "mc config host",
+		state:       featureDeprecated,
+		replacement: "mc alias",
+	},
+	{
+		name:        "mirror-force",
+		usage:       "mc mirror --force",
+		state:       featureDeprecated,
+		replacement: "mc mirror --overwrite",
+	},
+}
+
+// Features already warned about by this process.
+var warnedFeatures sync.Map
+
+// findFeature returns the feature of the given name.
+func findFeature(name string) (mcFeature, bool) {
+	for _, f := range mcFeatures {
+		if f.name == name {
+			return f, true
+		}
+	}
+	return mcFeature{}, false
+}
+
+// envFeatures parses the MC_FEATURES value into enabled and disabled features.
+func envFeatures(value string) map[string]bool {
+	features := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+		case strings.HasPrefix(name, "-"):
+			features[strings.TrimPrefix(name, "-")] = false
+		default:
+			features[name] = true
+		}
+	}
+	return features
+}
+
+// isFeatureEnabled tells if a feature is enabled by MC_FEATURES, else by
+// the config file, else by default when deprecated.
+func isFeatureEnabled(f mcFeature) bool {
+	if enabled, ok := envFeatures(env.Get(mcEnvFeatures, ""))[f.name]; ok {
+		return enabled
+	}
+	if cfg, err := loadMcConfig(); err == nil {
+		if enabled, ok := cfg.Features[f.name]; ok {
+			return enabled
+		}
+	}
+	return f.state == featureDeprecated
+}
+
+// checkFeature exits if the feature is disabled, and else warns once that it
+// is experimental or deprecated. Nothing is ever reported outside of mc.
+func checkFeature(name string) {
+	f, ok := findFeature(name)
+	if !ok {
+		return
+	}
+	if !isFeatureEnabled(f) {
+		switch f.state {
+		case featureExperimental:
+			fatalIf(errDummy().Trace(f.name), "`%s` is experimental, enable it with `mc config features enable %s`.", f.usage, f.name)
+		default:
+			fatalIf(errDummy().Trace(f.name), "`%s` is deprecated and disabled, please use `%s` instead.", f.usage, f.replacement)
+		}
+	}
+	if _, warned := warnedFeatures.LoadOrStore(f.name, true); warned {
+		return
+	}
+	errorIf(errDummy().Trace(f.name), featureWarning(f))
+}
+
+// featureWarning returns the warning printed when a feature is used.
+func featureWarning(f mcFeature) string {
+	if f.state == featureExperimental {
+		return fmt.Sprintf("`%s` is experimental, its behavior may change in future releases.", f.usage)
+	}
+	return fmt.Sprintf("`%s` is deprecated and will be removed in a future release, please use `%s` instead.", f.usage, f.replacement)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvFeatures(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected map[string]bool
+	}{
+		{"", map[string]bool{}},
+		{"two-way-mirror", map[string]bool{"two-way-mirror": true}},
+		{" two-way-mirror , -config-host,,", map[string]bool{"two-way-mirror": true, "config-host": false}},
+	}
+
+	for i, testCase := range testCases {
+		if features := envFeatures(testCase.value); !reflect.DeepEqual(features, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, features)
+		}
+	}
+}
+
+func TestIsFeatureEnabled(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	experimental, _ := findFeature("two-way-mirror")
+	deprecated, _ := findFeature("config-host")
+
+	// Defaults without a config file.
+	if isFeatureEnabled(experimental) || !isFeatureEnabled(deprecated) {
+		t.Fatal("expected experimental features disabled and deprecated features enabled by default")
+	}
+
+	cfg := newConfigV10()
+	cfg.Features = map[string]bool{"two-way-mirror": true, "config-host": false}
+	if err := saveMcConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if !isFeatureEnabled(experimental) || isFeatureEnabled(deprecated) {
+		t.Fatal("expected the features of the config file")
+	}
+
+	// MC_FEATURES overrides the config file.
+	t.Setenv(mcEnvFeatures, "-two-way-mirror,config-host")
+	if isFeatureEnabled(experimental) || !isFeatureEnabled(deprecated) {
+		t.Fatal("expected the features of MC_FEATURES")
+	}
+}
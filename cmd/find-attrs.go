@@ -0,0 +1,197 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/wildcard"
+)
+
+// findAttrWorkers is the number of objects whose tags or metadata are
+// fetched concurrently.
+const findAttrWorkers = 16
+
+// findAttrFilter matches objects on their tags and user metadata, the
+// values being wildcard patterns.
+type findAttrFilter struct {
+	tags     map[string]string
+	metadata map[string]string
+}
+
+// parseFindAttrs parses the key=value predicates of --tag or --metadata.
+func parseFindAttrs(flag string, values []string) (map[string]string, *probe.Error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	attrs := make(map[string]string, len(values))
+	for _, value := range values {
+		kv := strings.SplitN(value, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, probe.NewError(fmt.Errorf("invalid --%s `%s`, expected key=value", flag, value))
+		}
+		attrs[kv[0]] = kv[1]
+	}
+	return attrs, nil
+}
+
+// newFindAttrFilter returns the filter of the --tag and --metadata
+// predicates, nil when there is none.
+func newFindAttrFilter(tags, metadata []string) (*findAttrFilter, *probe.Error) {
+	f := &findAttrFilter{}
+	var err *probe.Error
+	if f.tags, err = parseFindAttrs("tag", tags); err != nil {
+		return nil, err
+	}
+	if f.metadata, err = parseFindAttrs("metadata", metadata); err != nil {
+		return nil, err
+	}
+	if f.tags == nil && f.metadata == nil {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// lookupMetadata returns the value of the metadata key, regardless of
+// its case and with or without the X-Amz-Meta- prefix.
+func lookupMetadata(content *ClientContent, key string) (string, bool) {
+	key = http.CanonicalHeaderKey(key)
+	for _, name := range []string{key, strings.TrimPrefix(key, "X-Amz-Meta-")} {
+		for k, v := range content.UserMetadata {
+			if http.CanonicalHeaderKey(k) == name {
+				return v, true
+			}
+		}
+	}
+	for _, name := range []string{key, "X-Amz-Meta-" + key} {
+		for k, v := range content.Metadata {
+			if http.CanonicalHeaderKey(k) == name {
+				return v, true
+			}
+		}
+	}
+	return "", false
+}
+
+// match reports whether the tags and the metadata of an object satisfy
+// all the predicates.
+func (f *findAttrFilter) match(tags map[string]string, content *ClientContent) bool {
+	for k, pattern := range f.tags {
+		v, ok := tags[k]
+		if !ok || !wildcard.Match(pattern, v) {
+			return false
+		}
+	}
+	for k, pattern := range f.metadata {
+		v, ok := lookupMetadata(content, k)
+		if !ok || !wildcard.Match(pattern, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// fetch reads the tags and the metadata of an object, only the ones
+// needed by the predicates.
+func (f *findAttrFilter) fetch(ctx context.Context, alias string, content *ClientContent, encKeyDB map[string][]prefixSSEPair) (map[string]string, *ClientContent, *probe.Error) {
+	urlStr := content.URL.String()
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return nil, nil, err.Trace(alias, urlStr)
+	}
+	var tags map[string]string
+	if f.tags != nil {
+		if tags, err = clnt.GetTags(ctx, content.VersionID); err != nil {
+			return nil, nil, err.Trace(urlStr)
+		}
+	}
+	stat := content
+	if f.metadata != nil {
+		objectPath := filepath.ToSlash(filepath.Join(alias, content.URL.Path))
+		stat, err = clnt.Stat(ctx, StatOptions{versionID: content.VersionID, sse: getSSE(objectPath, encKeyDB[alias])})
+		if err != nil {
+			return nil, nil, err.Trace(urlStr)
+		}
+	}
+	return tags, stat, nil
+}
+
+// filterFindAttrs drops the listed objects which don't match the --tag
+// and --metadata predicates. Only the objects matching the other
+// predicates are fetched, findAttrWorkers at a time, and the listing
+// order is preserved.
+func filterFindAttrs(ctxCtx context.Context, ctx *findContext, contents <-chan *ClientContent) <-chan *ClientContent {
+	pending := make(chan chan *ClientContent, findAttrWorkers)
+	filtered := make(chan *ClientContent)
+
+	go func() {
+		defer close(pending)
+		for content := range contents {
+			result := make(chan *ClientContent, 1)
+			select {
+			case pending <- result:
+			case <-ctxCtx.Done():
+				return
+			}
+			if content.Err != nil {
+				result <- content
+				continue
+			}
+			if content.Type.IsDir() || !matchFind(ctx, contentMessage{
+				Key:  getAliasedPath(ctx, content.URL.String()),
+				Time: content.Time.Local(),
+				Size: content.Size,
+			}) {
+				result <- nil
+				continue
+			}
+			go func(content *ClientContent) {
+				tags, stat, err := ctx.attrs.fetch(ctxCtx, ctx.targetAlias, content, ctx.encKeyDB)
+				if err != nil {
+					errorIf(err, "Unable to read the tags or metadata of `%s`.", getAliasedPath(ctx, content.URL.String()))
+					result <- nil
+					return
+				}
+				if !ctx.attrs.match(tags, stat) {
+					result <- nil
+					return
+				}
+				result <- content
+			}(content)
+		}
+	}()
+
+	go func() {
+		defer close(filtered)
+		for result := range pending {
+			if content := <-result; content != nil {
+				select {
+				case filtered <- content:
+				case <-ctxCtx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return filtered
+}
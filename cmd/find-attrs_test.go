@@ -0,0 +1,84 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestNewFindAttrFilter(t *testing.T) {
+	testCases := []struct {
+		tags, metadata []string
+		isNil          bool
+		fails          bool
+	}{
+		{nil, nil, true, false},
+		{[]string{"retention=expired"}, nil, false, false},
+		{nil, []string{"team=data*"}, false, false},
+		{[]string{"empty="}, nil, false, false},
+		{[]string{"retention"}, nil, true, true},
+		{nil, []string{"=value"}, true, true},
+	}
+	for i, testCase := range testCases {
+		f, err := newFindAttrFilter(testCase.tags, testCase.metadata)
+		if (err != nil) != testCase.fails {
+			t.Fatalf("Test %d: expected failure %v, got %v", i+1, testCase.fails, err)
+		}
+		if (f == nil) != testCase.isNil {
+			t.Errorf("Test %d: expected nil filter %v, got %v", i+1, testCase.isNil, f)
+		}
+	}
+}
+
+func TestFindAttrFilterMatch(t *testing.T) {
+	content := &ClientContent{
+		UserMetadata: map[string]string{"Team": "data-eng"},
+		Metadata: map[string]string{
+			"Content-Type":       "application/json",
+			"X-Amz-Meta-Project": "lake",
+		},
+	}
+	tags := map[string]string{"retention": "expired", "owner": "ops"}
+	testCases := []struct {
+		tags, metadata []string
+		match          bool
+	}{
+		{[]string{"retention=expired"}, nil, true},
+		{[]string{"retention=expired", "owner=ops"}, nil, true},
+		{[]string{"retention=expired", "owner=dev"}, nil, false},
+		{[]string{"retention=exp*"}, nil, true},
+		{[]string{"Retention=expired"}, nil, false},
+		{[]string{"missing=*"}, nil, false},
+		{nil, []string{"team=data*"}, true},
+		{nil, []string{"x-amz-meta-team=data-eng"}, true},
+		{nil, []string{"project=lake"}, true},
+		{nil, []string{"content-type=application/*"}, true},
+		{nil, []string{"team=ops"}, false},
+		{[]string{"owner=ops"}, []string{"team=data-eng"}, true},
+		{[]string{"owner=ops"}, []string{"team=ops"}, false},
+	}
+	for i, testCase := range testCases {
+		f, err := newFindAttrFilter(testCase.tags, testCase.metadata)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if match := f.match(tags, content); match != testCase.match {
+			t.Errorf("Test %d: expected match %v, got %v", i+1, testCase.match, match)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/google/shlex"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// runFindExec runs the --exec command line for a matching object and
+// returns its standard output and error.
+func runFindExec(ctx context.Context, split []string, fileContent contentMessage) (string, string, error) {
+	args := make([]string, len(split))
+	for i, arg := range split {
+		args[i] = stringsReplace(ctx, arg, fileContent)
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	var out bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	e := cmd.Run()
+	return out.String(), stderr.String(), e
+}
+
+// findExecPool runs the --exec command line of the matching objects on
+// a bounded number of workers, a failing command doesn't stop the
+// others and the failures are counted for the final exit status.
+type findExecPool struct {
+	split   []string
+	objects chan contentMessage
+	wg      sync.WaitGroup
+
+	mu         sync.Mutex
+	total      int64
+	failed     int64
+	exitStatus int
+}
+
+func newFindExecPool(ctx context.Context, args string, workers int) (*findExecPool, *probe.Error) {
+	split, e := shlex.Split(args)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(args)
+	}
+	if len(split) == 0 {
+		return nil, probe.NewError(fmt.Errorf("empty command line")).Trace(args)
+	}
+	p := &findExecPool{
+		split:   split,
+		objects: make(chan contentMessage, workers),
+	}
+	for i := 0; i < workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for fileContent := range p.objects {
+				p.run(ctx, fileContent)
+			}
+		}()
+	}
+	return p, nil
+}
+
+func (p *findExecPool) run(ctx context.Context, fileContent contentMessage) {
+	out, stderr, e := runFindExec(ctx, p.split, fileContent)
+
+	// Print the whole output of a command at once so that the
+	// outputs of concurrent commands don't interleave.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.total++
+	console.PrintC(out)
+	if e != nil {
+		p.failed++
+		if stderr = strings.TrimSpace(stderr); stderr != "" {
+			console.Println(console.Colorize("FindExecErr", stderr))
+		}
+		console.Println(console.Colorize("FindExecErr", e.Error()))
+		if status := getExitStatus(e); status > p.exitStatus {
+			p.exitStatus = status
+		}
+	}
+}
+
+// submit queues the command of a matching object, it blocks while all
+// the workers are busy.
+func (p *findExecPool) submit(fileContent contentMessage) {
+	p.objects <- fileContent
+}
+
+// wait waits for the queued commands and returns the highest exit
+// status of the failed ones, 0 when all succeeded.
+func (p *findExecPool) wait() int {
+	close(p.objects)
+	p.wg.Wait()
+	if p.failed > 0 {
+		console.Println(console.Colorize("FindExecErr", fmt.Sprintf("%d of %d commands failed.", p.failed, p.total)))
+	}
+	return p.exitStatus
+}
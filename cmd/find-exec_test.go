@@ -0,0 +1,58 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestFindExecPool(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	testCases := []struct {
+		keys       []string
+		exitStatus int
+		failed     int64
+	}{
+		{[]string{"a", "b", "c"}, 0, 0},
+		{[]string{"a", "fail-2", "b", "fail-3", "c"}, 3, 2},
+		{nil, 0, 0},
+	}
+	for i, testCase := range testCases {
+		p, err := newFindExecPool(context.Background(), `sh -c "case {base} in fail-*) exit ${0#fail-};; esac" {base}`, 2)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		for _, key := range testCase.keys {
+			p.submit(contentMessage{Key: "bucket/" + key})
+		}
+		if status := p.wait(); status != testCase.exitStatus {
+			t.Errorf("Test %d: expected exit status %d, got %d", i+1, testCase.exitStatus, status)
+		}
+		if p.total != int64(len(testCase.keys)) || p.failed != testCase.failed {
+			t.Errorf("Test %d: expected %d/%d failed, got %d/%d", i+1, testCase.failed, len(testCase.keys), p.failed, p.total)
+		}
+	}
+
+	if _, err := newFindExecPool(context.Background(), `echo "unterminated`, 2); err == nil {
+		t.Errorf("expected an invalid command line to fail")
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"maze.io/x/duration"
+)
+
+// inventoryManifest is the manifest.json written along with the data
+// files of a bucket inventory report.
+type inventoryManifest struct {
+	SourceBucket      string `json:"sourceBucket"`
+	DestinationBucket string `json:"destinationBucket"`
+	FileFormat        string `json:"fileFormat"`
+	FileSchema        string `json:"fileSchema"`
+	Files             []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+
+	// Alias or URL of the bucket holding the data files.
+	destURL string
+	// Position of each column of FileSchema.
+	columns map[string]int
+}
+
+// loadInventoryManifest reads the inventory manifest at urlStr, the
+// data files are expected in the destination bucket on the same alias.
+func loadInventoryManifest(ctx context.Context, urlStr string, encKeyDB map[string][]prefixSSEPair) (*inventoryManifest, *probe.Error) {
+	alias, _, _, err := expandAlias(urlStr)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	if alias == "" {
+		return nil, errInvalidArgument().Trace(urlStr)
+	}
+	reader, err := getSourceStreamFromURL(ctx, urlStr, "", encKeyDB)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	defer reader.Close()
+	data, e := ioutil.ReadAll(reader)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(urlStr)
+	}
+	m, err := parseInventoryManifest(data)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	m.destURL = alias + "/" + strings.TrimPrefix(m.DestinationBucket, "arn:aws:s3:::")
+	return m, nil
+}
+
+// parseInventoryManifest parses and validates the JSON content of an
+// inventory manifest.
+func parseInventoryManifest(data []byte) (*inventoryManifest, *probe.Error) {
+	m := &inventoryManifest{}
+	if e := json.Unmarshal(data, m); e != nil {
+		return nil, probe.NewError(e)
+	}
+	m.FileFormat = strings.ToUpper(m.FileFormat)
+	if m.FileFormat != "CSV" && m.FileFormat != "JSON" {
+		return nil, probe.NewError(fmt.Errorf("unsupported inventory format `%s`, only CSV and JSON can be queried", m.FileFormat))
+	}
+	m.columns = make(map[string]int)
+	for i, column := range strings.Split(m.FileSchema, ",") {
+		m.columns[strings.TrimSpace(column)] = i
+	}
+	if _, ok := m.columns["Key"]; !ok {
+		return nil, probe.NewError(fmt.Errorf("inventory schema `%s` has no `Key` column", m.FileSchema))
+	}
+	if m.DestinationBucket == "" || len(m.Files) == 0 {
+		return nil, probe.NewError(fmt.Errorf("inventory manifest lists no data files"))
+	}
+	return m, nil
+}
+
+// column returns the S3 Select reference of an inventory column, CSV
+// inventories have no header so their columns are positional.
+func (m *inventoryManifest) column(name string) (string, bool) {
+	i, ok := m.columns[name]
+	if !ok {
+		return "", false
+	}
+	if m.FileFormat == "CSV" {
+		return "s._" + strconv.Itoa(i+1), true
+	}
+	return "s." + name, true
+}
+
+// inventoryFields are the columns read back for each matching object,
+// in the order of the query projection.
+var inventoryFields = []string{"Key", "Size", "LastModifiedDate", "StorageClass", "IsLatest", "IsDeleteMarker"}
+
+// query builds the S3 Select query returning the objects under
+// prefix in bucket, along with the columns it projects. Name, size and
+// date predicates are pushed to the server as a coarse filter, every row
+// is still matched by find afterwards. The keys of CSV inventories are
+// URL-encoded and matched by prefix once decoded.
+func (m *inventoryManifest) query(ctx *findContext, bucket, prefix string, now time.Time) (string, []string, *probe.Error) {
+	var fields, projection []string
+	for _, name := range inventoryFields {
+		if ref, ok := m.column(name); ok {
+			fields = append(fields, name)
+			projection = append(projection, ref)
+		}
+	}
+
+	key, _ := m.column("Key")
+	var where []string
+	if ref, ok := m.column("Bucket"); ok {
+		where = append(where, ref+" = "+sqlQuote(bucket))
+	}
+	// Keys of CSV inventories are URL-encoded, they are only matched
+	// once decoded.
+	if m.FileFormat != "CSV" {
+		if prefix != "" {
+			where = append(where, key+" LIKE "+sqlQuote(escapeLike(prefix)+"%")+` ESCAPE '\'`)
+		}
+		if ctx.namePattern != "" {
+			where = append(where, key+" LIKE "+sqlQuote("%"+globToLike(ctx.namePattern, true)+"%")+` ESCAPE '\'`)
+		}
+		if ctx.pathPattern != "" {
+			where = append(where, key+" LIKE "+sqlQuote("%"+globToLike(ctx.pathPattern, false))+` ESCAPE '\'`)
+		}
+	}
+	if ctx.largerSize > 0 || ctx.smallerSize > 0 {
+		size, ok := m.column("Size")
+		if !ok {
+			return "", nil, probe.NewError(fmt.Errorf("inventory schema `%s` has no `Size` column", m.FileSchema))
+		}
+		if ctx.largerSize > 0 {
+			where = append(where, fmt.Sprintf("CAST(%s AS INT) > %d", size, ctx.largerSize))
+		}
+		if ctx.smallerSize > 0 {
+			where = append(where, fmt.Sprintf("CAST(%s AS INT) < %d", size, ctx.smallerSize))
+		}
+	}
+	if ctx.olderThan != "" || ctx.newerThan != "" {
+		date, ok := m.column("LastModifiedDate")
+		if !ok {
+			return "", nil, probe.NewError(fmt.Errorf("inventory schema `%s` has no `LastModifiedDate` column", m.FileSchema))
+		}
+		for _, cond := range []struct{ ref, op string }{{ctx.olderThan, "<="}, {ctx.newerThan, ">"}} {
+			if cond.ref == "" {
+				continue
+			}
+			d, e := duration.ParseDuration(cond.ref)
+			if e != nil {
+				return "", nil, probe.NewError(e).Trace(cond.ref)
+			}
+			at := now.Add(-time.Duration(d)).UTC().Format(time.RFC3339)
+			where = append(where, fmt.Sprintf("TO_TIMESTAMP(%s) %s TO_TIMESTAMP(%s)", date, cond.op, sqlQuote(at)))
+		}
+	}
+
+	query := "SELECT " + strings.Join(projection, ", ") + " FROM S3Object s"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	return query, fields, nil
+}
+
+// objectKey returns the object key of an inventory row, decoding the
+// URL-encoded keys of CSV inventories.
+func (m *inventoryManifest) objectKey(row map[string]string) (string, error) {
+	if m.FileFormat != "CSV" {
+		return row["Key"], nil
+	}
+	return url.QueryUnescape(row["Key"])
+}
+
+// listInventory queries every data file of the inventory and sends the
+// current version of the matching objects under the find target.
+func listInventory(ctxCtx context.Context, ctx *findContext, encKeyDB map[string][]prefixSSEPair) <-chan *ClientContent {
+	contentCh := make(chan *ClientContent)
+	go func() {
+		defer close(contentCh)
+
+		targetURL := ctx.clnt.GetURL()
+		separator := string(targetURL.Separator)
+		parts := splitStr(strings.TrimPrefix(targetURL.Path, separator), separator, 2)
+		bucket, prefix := parts[0], parts[1]
+
+		m := ctx.inventory
+		query, fields, err := m.query(ctx, bucket, prefix, UTCNow())
+		if err != nil {
+			contentCh <- &ClientContent{Err: err}
+			return
+		}
+		selOpts := SelectObjectOpts{
+			InputSerOpts:  map[string]map[string]string{strings.ToLower(m.FileFormat): {}},
+			OutputSerOpts: map[string]map[string]string{"csv": {}},
+		}
+		if m.FileFormat == "CSV" {
+			selOpts.InputSerOpts["csv"][fileHeaderType] = "NONE"
+		} else {
+			selOpts.InputSerOpts["json"][typeJSONType] = "LINES"
+		}
+
+		for _, file := range m.Files {
+			dataURL := m.destURL + "/" + file.Key
+			if err := selectInventory(ctxCtx, dataURL, query, fields, encKeyDB, selOpts, func(row map[string]string) {
+				if row["IsLatest"] == "false" || row["IsDeleteMarker"] == "true" {
+					return
+				}
+				key, e := m.objectKey(row)
+				if e != nil {
+					contentCh <- &ClientContent{Err: probe.NewError(e).Trace(dataURL, row["Key"])}
+					return
+				}
+				if !strings.HasPrefix(key, prefix) {
+					return
+				}
+				content := &ClientContent{
+					URL:          targetURL.Clone(),
+					StorageClass: row["StorageClass"],
+				}
+				content.URL.Path = separator + bucket + separator + key
+				content.Size, _ = strconv.ParseInt(row["Size"], 10, 64)
+				content.Time, _ = time.Parse(time.RFC3339Nano, row["LastModifiedDate"])
+				contentCh <- content
+			}); err != nil {
+				contentCh <- &ClientContent{Err: err.Trace(dataURL)}
+				return
+			}
+		}
+	}()
+	return contentCh
+}
+
+// selectInventory runs the query on an inventory data file and calls
+// fn with the projected fields of each returned row.
+func selectInventory(ctx context.Context, dataURL, query string, fields []string, encKeyDB map[string][]prefixSSEPair, selOpts SelectObjectOpts, fn func(map[string]string)) *probe.Error {
+	alias, _, _, err := expandAlias(dataURL)
+	if err != nil {
+		return err
+	}
+	clnt, err := newClient(dataURL)
+	if err != nil {
+		return err
+	}
+	reader, err := clnt.Select(ctx, query, getSSE(dataURL, encKeyDB[alias]), selOpts)
+	if err != nil {
+		return err.Trace(query)
+	}
+	defer reader.Close()
+
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = len(fields)
+	for {
+		record, e := r.Read()
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return probe.NewError(e)
+		}
+		row := make(map[string]string, len(fields))
+		for i, name := range fields {
+			row[name] = record[i]
+		}
+		fn(row)
+	}
+}
+
+// sqlQuote returns s as an S3 Select string literal.
+func sqlQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+// escapeLike escapes the LIKE wildcards of s with a backslash.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// globToLike converts a wildcard pattern to a LIKE pattern matching at
+// least the same names, character classes are only honored as a single
+// character when classes is set.
+func globToLike(pattern string, classes bool) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == '*':
+			b.WriteByte('%')
+		case c == '?':
+			b.WriteByte('_')
+		case c == '\\' && classes && i+1 < len(pattern):
+			i++
+			b.WriteString(escapeLike(pattern[i : i+1]))
+		case c == '[' && classes:
+			if end := strings.IndexByte(pattern[i+1:], ']'); end >= 0 {
+				b.WriteByte('_')
+				i += end + 1
+				continue
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteString(escapeLike(string(c)))
+		}
+	}
+	return b.String()
+}
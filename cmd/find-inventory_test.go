@@ -0,0 +1,126 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInventoryManifest(t *testing.T) {
+	testCases := []struct {
+		manifest string
+		success  bool
+	}{
+		{`{"destinationBucket":"arn:aws:s3:::reports","fileFormat":"CSV","fileSchema":"Bucket, Key, Size","files":[{"key":"data/1.csv.gz"}]}`, true},
+		{`{"destinationBucket":"reports","fileFormat":"json","fileSchema":"Bucket, Key","files":[{"key":"data/1.json"}]}`, true},
+		{`{"destinationBucket":"reports","fileFormat":"ORC","fileSchema":"Bucket, Key","files":[{"key":"data/1.orc"}]}`, false},
+		{`{"destinationBucket":"reports","fileFormat":"CSV","fileSchema":"Bucket, Size","files":[{"key":"data/1.csv"}]}`, false},
+		{`{"destinationBucket":"reports","fileFormat":"CSV","fileSchema":"Bucket, Key","files":[]}`, false},
+		{`{"fileFormat":`, false},
+	}
+	for i, testCase := range testCases {
+		_, err := parseInventoryManifest([]byte(testCase.manifest))
+		if success := err == nil; success != testCase.success {
+			t.Errorf("Test %d: expected success %t, got %v", i+1, testCase.success, err)
+		}
+	}
+}
+
+func TestInventoryQuery(t *testing.T) {
+	now := time.Date(2021, time.November, 10, 0, 0, 0, 0, time.UTC)
+	csvManifest, err := parseInventoryManifest([]byte(`{"destinationBucket":"reports","fileFormat":"CSV","fileSchema":"Bucket, Key, Size, LastModifiedDate","files":[{"key":"1.csv"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonManifest, err := parseInventoryManifest([]byte(`{"destinationBucket":"reports","fileFormat":"JSON","fileSchema":"Key","files":[{"key":"1.json"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		m       *inventoryManifest
+		ctx     *findContext
+		prefix  string
+		query   string
+		fields  int
+		success bool
+	}{
+		{csvManifest, &findContext{}, "", "SELECT s._2, s._3, s._4 FROM S3Object s WHERE s._1 = 'bucket'", 3, true},
+		{csvManifest, &findContext{namePattern: "*.jp?", largerSize: 1024}, "photo_1/", `SELECT s._2, s._3, s._4 FROM S3Object s WHERE s._1 = 'bucket' AND CAST(s._3 AS INT) > 1024`, 3, true},
+		{jsonManifest, &findContext{namePattern: "*.jp?"}, "photo_1/", `SELECT s.Key FROM S3Object s WHERE s.Key LIKE 'photo\_1/%' ESCAPE '\' AND s.Key LIKE '%%.jp_%' ESCAPE '\'`, 1, true},
+		{csvManifest, &findContext{newerThan: "7d"}, "", "SELECT s._2, s._3, s._4 FROM S3Object s WHERE s._1 = 'bucket' AND TO_TIMESTAMP(s._4) > TO_TIMESTAMP('2021-11-03T00:00:00Z')", 3, true},
+		{jsonManifest, &findContext{pathPattern: "it's/*"}, "", `SELECT s.Key FROM S3Object s WHERE s.Key LIKE '%it''s/%' ESCAPE '\'`, 1, true},
+		{jsonManifest, &findContext{smallerSize: 10}, "", "", 0, false},
+		{jsonManifest, &findContext{olderThan: "1d"}, "", "", 0, false},
+	}
+	for i, testCase := range testCases {
+		query, fields, err := testCase.m.query(testCase.ctx, "bucket", testCase.prefix, now)
+		if success := err == nil; success != testCase.success {
+			t.Fatalf("Test %d: expected success %t, got %v", i+1, testCase.success, err)
+		}
+		if query != testCase.query || len(fields) != testCase.fields {
+			t.Errorf("Test %d: expected %q with %d fields, got %q with %d fields", i+1, testCase.query, testCase.fields, query, len(fields))
+		}
+	}
+}
+
+func TestInventoryObjectKey(t *testing.T) {
+	csvManifest := &inventoryManifest{FileFormat: "CSV"}
+	jsonManifest := &inventoryManifest{FileFormat: "JSON"}
+	testCases := []struct {
+		m       *inventoryManifest
+		key     string
+		decoded string
+		success bool
+	}{
+		{csvManifest, "photos/a.jpg", "photos/a.jpg", true},
+		{csvManifest, "my+photos/%C3%A9t%C3%A9%2B1.jpg", "my photos/été+1.jpg", true},
+		{csvManifest, "bad%zz", "", false},
+		{jsonManifest, "my+photos/a%20b.jpg", "my+photos/a%20b.jpg", true},
+	}
+	for i, testCase := range testCases {
+		decoded, e := testCase.m.objectKey(map[string]string{"Key": testCase.key})
+		if success := e == nil; success != testCase.success {
+			t.Fatalf("Test %d: expected success %t, got %v", i+1, testCase.success, e)
+		}
+		if decoded != testCase.decoded {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.decoded, decoded)
+		}
+	}
+}
+
+func TestGlobToLike(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		classes bool
+		like    string
+	}{
+		{"*.txt", true, "%.txt"},
+		{"file_?.[ch]", true, `file\__._`},
+		{"[abc", true, "[abc"},
+		{`\*.md`, true, `*.md`},
+		{"a[1]*", false, "a[1]%"},
+		{"100%", false, `100\%`},
+	}
+	for i, testCase := range testCases {
+		if like := globToLike(testCase.pattern, testCase.classes); like != testCase.like {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.like, like)
+		}
+	}
+}
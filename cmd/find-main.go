@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"context"
+	"regexp"
 	"strings"
 	"time"
 
@@ -36,6 +37,10 @@ var (
 			Name:  "exec",
 			Usage: "spawn an external process for each matching object (see FORMAT)",
 		},
+		cli.IntFlag{
+			Name:  "exec-workers",
+			Usage: "run --exec for up to N objects concurrently, carrying on when a command fails",
+		},
 		cli.StringFlag{
 			Name:  "ignore",
 			Usage: "exclude objects matching the wildcard pattern",
@@ -60,9 +65,25 @@ var (
 			Name:  "print",
 			Usage: "print in custom format to STDOUT (see FORMAT)",
 		},
+		cli.BoolFlag{
+			Name:  "print0",
+			Usage: "terminate each printed name with a NUL character instead of a newline, for 'xargs -0'",
+		},
 		cli.StringFlag{
 			Name:  "regex",
-			Usage: "match directory and object name with PCRE regex pattern",
+			Usage: "match the object path below the search root with an RE2 regular expression",
+		},
+		cli.StringFlag{
+			Name:  "iregex",
+			Usage: "like --regex but matching regardless of case",
+		},
+		cli.StringSliceFlag{
+			Name:  "tag",
+			Usage: "match objects tagged with key=value, the value being a wildcard pattern, all the tags given must match",
+		},
+		cli.StringSliceFlag{
+			Name:  "metadata",
+			Usage: "match objects with the user metadata key=value, the value being a wildcard pattern, all the metadata given must match",
 		},
 		cli.StringFlag{
 			Name:  "larger",
@@ -80,6 +101,22 @@ var (
 			Name:  "watch",
 			Usage: "monitor a specified path for newly created object(s)",
 		},
+		cli.BoolFlag{
+			Name:  "anonymize",
+			Usage: "hash object names below the target, keeping sizes and timestamps",
+		},
+		cli.StringFlag{
+			Name:  "inventory",
+			Usage: "query the bucket inventory with this manifest using S3 Select instead of listing",
+		},
+		cli.StringFlag{
+			Name:  "profile",
+			Usage: "search with the predicates saved in this profile, flags on the command line take precedence",
+		},
+		cli.StringFlag{
+			Name:  "save-profile",
+			Usage: "save the search predicates of the command line in this profile of the config, without searching",
+		},
 	}
 )
 
@@ -154,9 +191,49 @@ EXAMPLES:
 
   10. List all objects up to 3 levels sub-directory deep under "s3/bucket".
       {{.Prompt}} {{.HelpName}} s3/bucket --maxdepth 3
+
+  11. Find all objects larger than 1GB under "s3/bucket" with hashed names, to share them without the key names.
+      {{.Prompt}} {{.HelpName}} s3/bucket --larger 1GB --anonymize --json
+
+  12. Find all ".mp4" objects larger than 1GB under "s3/bucket/videos" from its CSV inventory, without listing the bucket.
+      {{.Prompt}} {{.HelpName}} s3/bucket/videos --name "*.mp4" --larger 1GB --inventory s3/reports/bucket/daily/2021-11-01T01-00Z/manifest.json
+
+  13. Find the parquet files of the first week of March 2021 in a date partitioned bucket, whatever the case of their extension.
+      {{.Prompt}} {{.HelpName}} s3/datalake --iregex "^year=2021/month=03/day=0[1-7]/[^/]+\.parquet$"
+
+  14. Find all objects tagged "retention=expired" under "s3/archive" uploaded by a team whose name starts with "data".
+      {{.Prompt}} {{.HelpName}} s3/archive --tag retention=expired --metadata "team=data*"
+
+  15. Tag all the log objects of 2020 as expired, running 32 commands at a time.
+      {{.Prompt}} {{.HelpName}} s3/logs --path "2020/*" --exec "mc tag set {} retention=expired" --exec-workers 32
+
+  16. Compress the local log files older than 30 days, whatever characters their names contain.
+      {{.Prompt}} {{.HelpName}} ~/logs --name "*.log" --older-than 30d --print0 | xargs -0 gzip --
+
+  17. Save the search for expired logs as the profile "expired-logs", then run it on two buckets.
+      {{.Prompt}} {{.HelpName}} --name "*.log" --older-than 30d --tag retention=expired --save-profile expired-logs
+      {{.Prompt}} {{.HelpName}} s3/logs --profile expired-logs
+      {{.Prompt}} {{.HelpName}} s3/archive --profile expired-logs --larger 1GB
 `,
 }
 
+// parseFindRegex compiles the --regex or --iregex pattern, the latter
+// matching regardless of case.
+func parseFindRegex(regex, iregex string) (*regexp.Regexp, *probe.Error) {
+	pattern := regex
+	if iregex != "" {
+		pattern = "(?i)" + iregex
+	}
+	if pattern == "" {
+		return nil, nil
+	}
+	re, e := regexp.Compile(pattern)
+	if e != nil {
+		return nil, probe.NewError(e).Trace(pattern)
+	}
+	return re, nil
+}
+
 // checkFindSyntax - validate the passed arguments
 func checkFindSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	args := cliCtx.Args()
@@ -172,6 +249,52 @@ func checkFindSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[stri
 		}
 	}
 
+	if cliCtx.Bool("anonymize") && (cliCtx.String("exec") != "" || cliCtx.String("print") != "") {
+		fatalIf(errInvalidArgument().Trace(args...), "`--anonymize` cannot be used with `--exec` or `--print`.")
+	}
+
+	if cliCtx.Bool("print0") && (cliCtx.String("exec") != "" || globalJSON) {
+		fatalIf(errInvalidArgument().Trace(args...), "`--print0` cannot be used with `--exec` or `--json`.")
+	}
+
+	if cliCtx.IsSet("exec-workers") {
+		if cliCtx.String("exec") == "" {
+			fatalIf(errInvalidArgument().Trace(args...), "`--exec-workers` can only be used with `--exec`.")
+		}
+		if cliCtx.Bool("watch") {
+			fatalIf(errInvalidArgument().Trace(args...), "`--exec-workers` cannot be used with `--watch`.")
+		}
+		if cliCtx.Int("exec-workers") <= 0 {
+			fatalIf(errInvalidArgument().Trace(args...), "`--exec-workers` must be a positive number.")
+		}
+	}
+
+	if cliCtx.String("regex") != "" && cliCtx.String("iregex") != "" {
+		fatalIf(errInvalidArgument().Trace(args...), "`--regex` cannot be used with `--iregex`.")
+	}
+
+	if len(cliCtx.StringSlice("tag")) > 0 || len(cliCtx.StringSlice("metadata")) > 0 {
+		if cliCtx.Bool("watch") {
+			fatalIf(errInvalidArgument().Trace(args...), "`--tag` and `--metadata` cannot be used with `--watch`.")
+		}
+		_, _, hostCfg, err := expandAlias(args[0])
+		fatalIf(err.Trace(args[0]), "Unable to expand alias.")
+		if hostCfg == nil {
+			fatalIf(errInvalidArgument().Trace(args...), "`--tag` and `--metadata` require object storage as the target.")
+		}
+	}
+
+	if cliCtx.String("inventory") != "" {
+		if cliCtx.Bool("watch") {
+			fatalIf(errInvalidArgument().Trace(args...), "`--inventory` cannot be used with `--watch`.")
+		}
+		_, _, hostCfg, err := expandAlias(args[0])
+		fatalIf(err.Trace(args[0]), "Unable to expand alias.")
+		if hostCfg == nil || len(splitStr(strings.TrimPrefix(args[0], "/"), "/", 2)[1]) == 0 {
+			fatalIf(errInvalidArgument().Trace(args...), "`--inventory` requires a bucket on object storage as the target.")
+		}
+	}
+
 	// Extract input URLs and validate.
 	for _, url := range args {
 		_, _, err := url2Stat(ctx, url, "", false, encKeyDB, time.Time{})
@@ -194,20 +317,26 @@ type findContext struct {
 	ignorePattern string
 	namePattern   string
 	pathPattern   string
-	regexPattern  string
+	regex         *regexp.Regexp
 	maxDepth      uint
 	printFmt      string
+	print0        bool
 	olderThan     string
 	newerThan     string
 	largerSize    uint64
 	smallerSize   uint64
 	watch         bool
+	anonymizer    *nameAnonymizer
+	inventory     *inventoryManifest
+	attrs         *findAttrFilter
+	execPool      *findExecPool
 
 	// Internal values
 	targetAlias   string
 	targetURL     string
 	targetFullURL string
 	clnt          Client
+	encKeyDB      map[string][]prefixSSEPair
 }
 
 // mainFind - handler for mc find commands
@@ -223,6 +352,14 @@ func mainFind(cliCtx *cli.Context) error {
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
+	if name := cliCtx.String("profile"); name != "" {
+		applyFindProfile(cliCtx, name)
+	}
+	if name := cliCtx.String("save-profile"); name != "" {
+		saveFindProfile(cliCtx, name)
+		return nil
+	}
+
 	checkFindSyntax(ctx, cliCtx, encKeyDB)
 
 	args := cliCtx.Args()
@@ -235,6 +372,29 @@ func mainFind(cliCtx *cli.Context) error {
 	clnt, err := newClient(args[0])
 	fatalIf(err.Trace(args...), "Unable to initialize `"+args[0]+"`.")
 
+	var anonymizer *nameAnonymizer
+	if cliCtx.Bool("anonymize") {
+		anonymizer = newNameAnonymizer()
+	}
+
+	var inventory *inventoryManifest
+	if inventoryURL := cliCtx.String("inventory"); inventoryURL != "" {
+		inventory, err = loadInventoryManifest(ctx, inventoryURL, encKeyDB)
+		fatalIf(err.Trace(inventoryURL), "Unable to load the inventory manifest `"+inventoryURL+"`.")
+	}
+
+	regex, err := parseFindRegex(cliCtx.String("regex"), cliCtx.String("iregex"))
+	fatalIf(err, "Unable to parse the regular expression.")
+
+	attrs, err := newFindAttrFilter(cliCtx.StringSlice("tag"), cliCtx.StringSlice("metadata"))
+	fatalIf(err, "Unable to parse the tag and metadata predicates.")
+
+	var execPool *findExecPool
+	if workers := cliCtx.Int("exec-workers"); workers > 0 {
+		execPool, err = newFindExecPool(ctx, cliCtx.String("exec"), workers)
+		fatalIf(err, "Unable to parse --exec.")
+	}
+
 	var olderThan, newerThan string
 
 	if cliCtx.String("older-than") != "" {
@@ -272,18 +432,24 @@ func mainFind(cliCtx *cli.Context) error {
 		maxDepth:      cliCtx.Uint("maxdepth"),
 		execCmd:       cliCtx.String("exec"),
 		printFmt:      cliCtx.String("print"),
+		print0:        cliCtx.Bool("print0"),
 		namePattern:   cliCtx.String("name"),
 		pathPattern:   cliCtx.String("path"),
-		regexPattern:  cliCtx.String("regex"),
+		regex:         regex,
 		ignorePattern: cliCtx.String("ignore"),
 		olderThan:     olderThan,
 		newerThan:     newerThan,
 		largerSize:    largerSize,
 		smallerSize:   smallerSize,
 		watch:         cliCtx.Bool("watch"),
+		anonymizer:    anonymizer,
+		inventory:     inventory,
+		attrs:         attrs,
+		execPool:      execPool,
 		targetAlias:   targetAlias,
 		targetURL:     args[0],
 		targetFullURL: targetFullURL,
 		clnt:          clnt,
+		encKeyDB:      encKeyDB,
 	})
 }
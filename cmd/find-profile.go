@@ -0,0 +1,148 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// findProfileFlags are the find flags saved in a profile, the
+// search predicates.
+var findProfileFlags = []string{
+	"name", "path", "regex", "iregex", "ignore", "tag", "metadata",
+	"larger", "smaller", "older-than", "newer-than", "maxdepth",
+}
+
+// findProfileMessage is printed when a find profile is saved.
+type findProfileMessage struct {
+	Status  string         `json:"status"`
+	Profile string         `json:"profile"`
+	Flags   findProfileV10 `json:"flags"`
+}
+
+// String colorized find profile message.
+func (f findProfileMessage) String() string {
+	return console.Colorize("Find", "Saved find profile `"+f.Profile+"`.")
+}
+
+// JSON jsonified find profile message.
+func (f findProfileMessage) JSON() string {
+	f.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(f, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// newFindProfile returns the search predicates of the command line.
+func newFindProfile(cliCtx *cli.Context) findProfileV10 {
+	profile := findProfileV10{}
+	for _, flag := range findProfileFlags {
+		switch flag {
+		case "tag", "metadata":
+			if values := cliCtx.StringSlice(flag); len(values) > 0 {
+				profile[flag] = values
+			}
+		case "maxdepth":
+			if cliCtx.Uint(flag) > 0 {
+				profile[flag] = []string{cliCtx.String(flag)}
+			}
+		default:
+			if value := cliCtx.String(flag); value != "" {
+				profile[flag] = []string{value}
+			}
+		}
+	}
+	return profile
+}
+
+// validate parses the values of the profile which would make
+// a search using it fail.
+func (p findProfileV10) validate() *probe.Error {
+	var regex, iregex string
+	if values := p["regex"]; len(values) > 0 {
+		regex = values[0]
+	}
+	if values := p["iregex"]; len(values) > 0 {
+		iregex = values[0]
+	}
+	if _, err := parseFindRegex(regex, iregex); err != nil {
+		return err
+	}
+	if _, err := newFindAttrFilter(p["tag"], p["metadata"]); err != nil {
+		return err
+	}
+	for _, flag := range []string{"larger", "smaller"} {
+		for _, value := range p[flag] {
+			if _, e := humanize.ParseBytes(value); e != nil {
+				return probe.NewError(e).Trace(value)
+			}
+		}
+	}
+	return nil
+}
+
+// apply sets the flags of the profile not already set on the
+// command line, which takes precedence.
+func (p findProfileV10) apply(cliCtx *cli.Context) *probe.Error {
+	for _, flag := range findProfileFlags {
+		if cliCtx.IsSet(flag) {
+			continue
+		}
+		for _, value := range p[flag] {
+			if e := cliCtx.Set(flag, value); e != nil {
+				return probe.NewError(e).Trace(flag, value)
+			}
+		}
+	}
+	return nil
+}
+
+// applyFindProfile sets the flags saved in the profile name.
+func applyFindProfile(cliCtx *cli.Context, name string) {
+	mcCfg, err := loadMcConfig()
+	fatalIf(err.Trace(globalMCConfigVersion), "Unable to load config `"+mustGetMcConfigPath()+"`.")
+	profile, ok := mcCfg.FindProfiles[name]
+	if !ok {
+		fatalIf(errInvalidArgument().Trace(name), "Find profile `"+name+"` not found in config `"+mustGetMcConfigPath()+"`.")
+	}
+	fatalIf(profile.apply(cliCtx), "Unable to apply find profile `"+name+"`.")
+}
+
+// saveFindProfile saves the search predicates of the command line,
+// including those of an applied profile, as the profile name.
+func saveFindProfile(cliCtx *cli.Context, name string) {
+	profile := newFindProfile(cliCtx)
+	if len(profile) == 0 {
+		fatalIf(errInvalidArgument().Trace(name), "No search predicates to save in find profile `"+name+"`.")
+	}
+	fatalIf(profile.validate(), "Unable to save find profile `"+name+"`.")
+
+	mcCfg, err := loadMcConfig()
+	fatalIf(err.Trace(globalMCConfigVersion), "Unable to load config `"+mustGetMcConfigPath()+"`.")
+	if mcCfg.FindProfiles == nil {
+		mcCfg.FindProfiles = make(map[string]findProfileV10)
+	}
+	mcCfg.FindProfiles[name] = profile
+	fatalIf(saveMcConfig(mcCfg).Trace(), "Unable to save config `"+mustGetMcConfigPath()+"`.")
+
+	printMsg(findProfileMessage{Profile: name, Flags: profile})
+}
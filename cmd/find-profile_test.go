@@ -0,0 +1,75 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+
+	"github.com/minio/cli"
+)
+
+func newFindTestContext(t *testing.T, args ...string) *cli.Context {
+	set := flag.NewFlagSet("find", flag.ContinueOnError)
+	for _, f := range findFlags {
+		f.Apply(set)
+	}
+	if e := set.Parse(args); e != nil {
+		t.Fatal(e)
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestFindProfile(t *testing.T) {
+	profile := findProfileV10{
+		"name":       {"*.log"},
+		"older-than": {"30d"},
+		"tag":        {"retention=expired", "team=data"},
+		"maxdepth":   {"2"},
+	}
+	if err := profile.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flags of the command line take precedence over the profile.
+	cliCtx := newFindTestContext(t, "--name", "*.txt", "--larger", "1GB")
+	if err := profile.apply(cliCtx); err != nil {
+		t.Fatal(err)
+	}
+	expected := findProfileV10{
+		"name":       {"*.txt"},
+		"older-than": {"30d"},
+		"tag":        {"retention=expired", "team=data"},
+		"maxdepth":   {"2"},
+		"larger":     {"1GB"},
+	}
+	if got := newFindProfile(cliCtx); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	for _, invalid := range []findProfileV10{
+		{"regex": {"("}},
+		{"smaller": {"1XB"}},
+		{"tag": {"retention"}},
+	} {
+		if err := invalid.validate(); err == nil {
+			t.Errorf("expected %v to be invalid", invalid)
+		}
+	}
+}
@@ -18,12 +18,10 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"regexp"
 	"strconv"
 	"strings"
 	"syscall"
@@ -94,13 +92,6 @@ func pathMatch(pattern, path string) bool {
 	return wildcard.Match(pattern, path)
 }
 
-// regexMatch reports whether path matches the regex pattern.
-func regexMatch(pattern, path string) bool {
-	matched, e := regexp.MatchString(pattern, path)
-	errorIf(probe.NewError(e).Trace(pattern), "Unable to regex match with input pattern.")
-	return matched
-}
-
 func getExitStatus(err error) int {
 	if err == nil {
 		return 0
@@ -124,23 +115,16 @@ func execFind(ctx context.Context, args string, fileContent contentMessage) {
 	if len(split) == 0 {
 		return
 	}
-	for i, arg := range split {
-		split[i] = stringsReplace(ctx, arg, fileContent)
-	}
-	cmd := exec.Command(split[0], split[1:]...)
-	var out bytes.Buffer
-	var stderr bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		if stderr.Len() > 0 {
-			console.Println(console.Colorize("FindExecErr", strings.TrimSpace(stderr.String())))
+	out, stderr, err := runFindExec(ctx, split, fileContent)
+	if err != nil {
+		if stderr = strings.TrimSpace(stderr); stderr != "" {
+			console.Println(console.Colorize("FindExecErr", stderr))
 		}
 		console.Println(console.Colorize("FindExecErr", err.Error()))
 		// Return exit status of the command run
 		os.Exit(getExitStatus(err))
 	}
-	console.PrintC(out.String())
+	console.PrintC(out)
 }
 
 // watchFind - enables listening on the input path, listens for all file/object
@@ -249,7 +233,17 @@ func find(ctxCtx context.Context, ctx *findContext, fileContent contentMessage)
 	if ctx.printFmt != "" {
 		fileContent.Key = stringsReplace(ctxCtx, ctx.printFmt, fileContent)
 	}
-	printMsg(findMessage{fileContent})
+	printFind(ctx, fileContent)
+}
+
+// printFind prints a matching object, NUL terminated with --print0.
+func printFind(ctx *findContext, fileContent contentMessage) {
+	msg := findMessage{ctx.anonymizer.anonymizeContent(ctx.targetURL, fileContent)}
+	if ctx.print0 {
+		printNulTerminated(msg.Key)
+		return
+	}
+	printMsg(msg)
 }
 
 // doFind - find is main function body which interprets and executes
@@ -262,8 +256,18 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 
 	var prevKeyName string
 
+	var contents <-chan *ClientContent
+	if ctx.inventory != nil {
+		contents = listInventory(ctxCtx, ctx, ctx.encKeyDB)
+	} else {
+		contents = ctx.clnt.List(globalContext, ListOptions{Recursive: true, ShowDir: DirFirst})
+	}
+	if ctx.attrs != nil {
+		contents = filterFindAttrs(ctxCtx, ctx, contents)
+	}
+
 	// iterate over all content which is within the given directory
-	for content := range ctx.clnt.List(globalContext, ListOptions{Recursive: true, ShowDir: DirFirst}) {
+	for content := range contents {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
 			// handle this specifically for filesystem related errors.
@@ -302,6 +306,10 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 		prevKeyName = fileKeyName
 
 		// proceed to either exec, format the output string.
+		if ctx.execPool != nil {
+			ctx.execPool.submit(fileContent)
+			continue
+		}
 		if ctx.execCmd != "" {
 			execFind(ctxCtx, ctx.execCmd, fileContent)
 			continue
@@ -310,7 +318,13 @@ func doFind(ctxCtx context.Context, ctx *findContext) error {
 			fileContent.Key = stringsReplace(ctxCtx, ctx.printFmt, fileContent)
 		}
 
-		printMsg(findMessage{fileContent})
+		printFind(ctx, fileContent)
+	}
+
+	if ctx.execPool != nil {
+		if status := ctx.execPool.wait(); status != 0 {
+			return exitStatus(status)
+		}
 	}
 
 	// Success, notice watch will execute in defer only if enabled and this call
@@ -406,8 +420,8 @@ func matchFind(ctx *findContext, fileContent contentMessage) (match bool) {
 	if match && ctx.pathPattern != "" {
 		match = pathMatch(ctx.pathPattern, path)
 	}
-	if match && ctx.regexPattern != "" {
-		match = regexMatch(ctx.regexPattern, path)
+	if match && ctx.regex != nil {
+		match = ctx.regex.MatchString(path)
 	}
 	if match && ctx.olderThan != "" {
 		match = !isOlder(fileContent.Time, ctx.olderThan)
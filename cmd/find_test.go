@@ -20,6 +20,7 @@ package cmd
 import (
 	"context"
 	"os/exec"
+	"regexp"
 	"runtime"
 	"strings"
 	"testing"
@@ -54,7 +55,7 @@ func TestMatchFind(t *testing.T) {
 			clnt: &S3Client{
 				targetURL: &ClientURL{},
 			},
-			regexPattern: `^(\d+\.){3}\d+$`,
+			regex: regexp.MustCompile(`^(\d+\.){3}\d+$`),
 		},
 		{
 			clnt: &S3Client{
@@ -331,7 +332,11 @@ func TestFindMatch(t *testing.T) {
 					!test.match, test.pattern, test.flagName, test.filePath)
 			}
 		case "regex":
-			testMatch := regexMatch(test.pattern, test.filePath)
+			re, err := parseFindRegex(test.pattern, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			testMatch := re.MatchString(test.filePath)
 			if testMatch != test.match {
 				t.Fatalf("Unexpected result %t, with pattern %s, flag %s and filepath %s \n",
 					!test.match, test.pattern, test.flagName, test.filePath)
@@ -420,6 +425,39 @@ func TestStringReplace(t *testing.T) {
 	}
 }
 
+// Tests --regex and --iregex parsing, parseFindRegex() function
+func TestParseFindRegex(t *testing.T) {
+	testCases := []struct {
+		regex, iregex string
+		path          string
+		match         bool
+		fails         bool
+	}{
+		{`^year=2021/month=0[1-3]/`, "", "year=2021/month=02/a.parquet", true, false},
+		{`^year=2021/month=0[1-3]/`, "", "year=2021/month=04/a.parquet", false, false},
+		{`\.PARQUET$`, "", "year=2021/a.parquet", false, false},
+		{"", `\.PARQUET$`, "year=2021/a.parquet", true, false},
+		{"", `^YEAR=\d{4}/`, "year=2021/a.parquet", true, false},
+		{`(?<=a)b`, "", "", false, true},
+		{"", `[a-`, "", false, true},
+	}
+	for i, testCase := range testCases {
+		re, err := parseFindRegex(testCase.regex, testCase.iregex)
+		if (err != nil) != testCase.fails {
+			t.Fatalf("Test %d: expected failure %v, got %v", i+1, testCase.fails, err)
+		}
+		if err != nil {
+			continue
+		}
+		if match := re.MatchString(testCase.path); match != testCase.match {
+			t.Errorf("Test %d: expected match %v, got %v", i+1, testCase.match, match)
+		}
+	}
+	if re, err := parseFindRegex("", ""); re != nil || err != nil {
+		t.Errorf("expected no regex, got %v, %v", re, err)
+	}
+}
+
 // Tests exit status, getExitStatus() function
 func TestGetExitStatus(t *testing.T) {
 	if runtime.GOOS != "linux" {
@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"strings"
+
 	"github.com/minio/cli"
 )
 
@@ -48,6 +50,30 @@ var globalFlags = []cli.Flag{
 		Name:  "insecure",
 		Usage: "disable SSL certificate verification",
 	},
+	cli.StringFlag{
+		Name:   "list-api",
+		Usage:  "force the ListObjects API version (values: `v1`, `v2`), detected per host by default",
+		EnvVar: "MC_LIST_API",
+	},
+	cli.StringFlag{
+		Name:  "query",
+		Usage: "print only the fields of the JSON output selected by a JMESPath expression, implies --json",
+	},
+	cli.StringSliceFlag{
+		Name:   "resolve",
+		Usage:  "resolve HOST to IP instead of using DNS, as HOST:IP, can be repeated",
+		EnvVar: "MC_RESOLVE",
+	},
+	cli.StringFlag{
+		Name:   "notify-exec",
+		Usage:  "run a command with a JSON summary on its standard input once a long command completes",
+		EnvVar: "MC_NOTIFY_EXEC",
+	},
+	cli.StringFlag{
+		Name:   "notify-after",
+		Usage:  "run the --notify-exec command only for commands running longer than this duration (default: 1m)",
+		EnvVar: "MC_NOTIFY_AFTER",
+	},
 }
 
 // Flags common across all I/O commands such as cp, mirror, stat, pipe etc.
@@ -57,3 +83,23 @@ var ioFlags = []cli.Flag{
 		Usage: "encrypt/decrypt objects (using server-side encryption with customer provided keys)",
 	},
 }
+
+// globalFlagsWithout returns the global flags except the named ones, for
+// commands which define a flag of their own under the same name.
+func globalFlagsWithout(names ...string) []cli.Flag {
+	flags := make([]cli.Flag, 0, len(globalFlags))
+	for _, flag := range globalFlags {
+		name := strings.TrimSpace(strings.Split(flag.GetName(), ",")[0])
+		skip := false
+		for _, n := range names {
+			if name == n {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			flags = append(flags, flag)
+		}
+	}
+	return flags
+}
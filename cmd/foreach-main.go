@@ -0,0 +1,316 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// foreachAliasPlaceholder is replaced by the alias in the arguments of
+// the command run by foreach.
+const foreachAliasPlaceholder = "{alias}"
+
+var foreachFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "aliases",
+		Usage: "comma separated list of aliases to run the command against, wildcards like 'prod-*' are expanded",
+	},
+	cli.IntFlag{
+		Name:  "parallel",
+		Usage: "number of aliases to run the command against concurrently",
+		Value: 8,
+	},
+}
+
+var foreachCmd = cli.Command{
+	Name:           "foreach",
+	Usage:          "run a command against several aliases in parallel",
+	Action:         mainForeach,
+	OnUsageError:   onUsageError,
+	Before:         setGlobalsFromContext,
+	SkipArgReorder: true,
+	Flags:          append(foreachFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} --aliases ALIAS[,ALIAS...] [FLAGS] -- COMMAND [COMMAND FLAGS] ARGUMENTS
+
+  Every {alias} in the arguments of COMMAND is replaced by the alias the command
+  runs against. The output of each command is printed once it completes, each
+  line tagged with its alias. With --json the JSON messages of each command are
+  merged in a single message per alias.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Show the server information of two clusters.
+     {{.Prompt}} {{.HelpName}} --aliases prod-eu,prod-us -- admin info {alias}
+
+  2. Check the replication status of a bucket on every production cluster, four at a time, in JSON.
+     {{.Prompt}} {{.HelpName}} --json --parallel 4 --aliases 'prod-*' -- replicate status {alias}/photos
+`,
+}
+
+// foreachMessage holds the result of the command run against an alias.
+type foreachMessage struct {
+	Status   string            `json:"status"`
+	Alias    string            `json:"alias"`
+	ExitCode int               `json:"exitCode"`
+	Output   []json.RawMessage `json:"output,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	text     string
+	width    int
+}
+
+func (f foreachMessage) String() string {
+	var b strings.Builder
+	tag := console.Colorize("ForeachAlias", fmt.Sprintf("%-*s |", f.width, f.Alias))
+	if f.Status == "error" {
+		tag = console.Colorize("ForeachFailed", fmt.Sprintf("%-*s |", f.width, f.Alias))
+	}
+	for _, line := range strings.Split(strings.TrimRight(f.text, "\n"), "\n") {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(tag + " " + line)
+	}
+	if f.Error != "" {
+		b.WriteString("\n" + tag + " " + console.Colorize("ForeachFailed", f.Error))
+	}
+	return b.String()
+}
+
+func (f foreachMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(f, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// foreachSummaryMessage counts the aliases the command succeeded and
+// failed against.
+type foreachSummaryMessage struct {
+	Status    string   `json:"status"`
+	Succeeded int      `json:"succeeded"`
+	Failed    []string `json:"failed,omitempty"`
+}
+
+func (f foreachSummaryMessage) String() string {
+	if len(f.Failed) == 0 {
+		return console.Colorize("ForeachAlias", fmt.Sprintf("Succeeded on %d aliases.", f.Succeeded))
+	}
+	return console.Colorize("ForeachFailed", fmt.Sprintf("Succeeded on %d aliases, failed on %d: %s.", f.Succeeded, len(f.Failed), strings.Join(f.Failed, ", ")))
+}
+
+func (f foreachSummaryMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(f, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// expandForeachAliases returns the aliases matching the comma separated
+// patterns, in the order of the patterns and without duplicates.
+func expandForeachAliases(patterns string, configured []string) ([]string, *probe.Error) {
+	sort.Strings(configured)
+	seen := make(map[string]bool)
+	var aliases []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		matched := false
+		for _, alias := range configured {
+			if ok, e := path.Match(pattern, alias); e != nil {
+				return nil, probe.NewError(e).Trace(pattern)
+			} else if !ok {
+				continue
+			}
+			matched = true
+			if !seen[alias] {
+				seen[alias] = true
+				aliases = append(aliases, alias)
+			}
+		}
+		if !matched {
+			return nil, probe.NewError(fmt.Errorf("no alias matches `%s`", pattern))
+		}
+	}
+	if len(aliases) == 0 {
+		return nil, probe.NewError(errors.New("no alias given"))
+	}
+	return aliases, nil
+}
+
+// foreachArgs returns the arguments of the command run against alias.
+func foreachArgs(args []string, alias string) []string {
+	aliasArgs := make([]string, len(args))
+	for i, arg := range args {
+		aliasArgs[i] = strings.ReplaceAll(arg, foreachAliasPlaceholder, alias)
+	}
+	return aliasArgs
+}
+
+// parseForeachOutput splits the JSON output of a command into its
+// messages, the text following the last valid message is returned.
+func parseForeachOutput(output []byte) ([]json.RawMessage, string) {
+	var msgs []json.RawMessage
+	var end int64
+	dec := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var msg json.RawMessage
+		if e := dec.Decode(&msg); e != nil {
+			break
+		}
+		msgs = append(msgs, msg)
+		end = dec.InputOffset()
+	}
+	return msgs, strings.TrimSpace(string(output[end:]))
+}
+
+// runForeach runs the command against alias and collects its output.
+func runForeach(args []string, alias string) foreachMessage {
+	msg := foreachMessage{Status: "success", Alias: alias}
+	cmdArgs := foreachArgs(args, alias)
+	if globalJSON {
+		cmdArgs = append([]string{"--json"}, cmdArgs...)
+	} else {
+		cmdArgs = append([]string{"--no-color"}, cmdArgs...)
+	}
+
+	var output bytes.Buffer
+	cmd := jobCommand(cmdArgs...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	e := cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(e, &exitErr):
+		msg.Status = "error"
+		msg.ExitCode = exitErr.ExitCode()
+	case e != nil:
+		msg.Status = "error"
+		msg.ExitCode = globalErrorExitStatus
+		msg.Error = e.Error()
+	}
+
+	if globalJSON {
+		var rest string
+		msg.Output, rest = parseForeachOutput(output.Bytes())
+		if rest != "" && msg.Error == "" {
+			msg.Error = rest
+		}
+	} else {
+		msg.text = output.String()
+	}
+	return msg
+}
+
+// mainForeach is the entry point for the foreach command.
+func mainForeach(cliCtx *cli.Context) error {
+	args := cliCtx.Args()
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	if len(args) == 0 || !cliCtx.IsSet("aliases") {
+		cli.ShowCommandHelpAndExit(cliCtx, "foreach", 1) // last argument is exit code
+	}
+	parallel := cliCtx.Int("parallel")
+	if parallel <= 0 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("parallel")), "--parallel must be a positive number.")
+	}
+	if !strings.Contains(strings.Join(args, " "), foreachAliasPlaceholder) {
+		fatalIf(errInvalidArgument().Trace(args...), "The command must refer to the alias with "+foreachAliasPlaceholder+".")
+	}
+
+	console.SetColor("ForeachAlias", color.New(color.FgCyan, color.Bold))
+	console.SetColor("ForeachFailed", color.New(color.FgRed, color.Bold))
+
+	conf, err := loadMcConfig()
+	fatalIf(err.Trace(globalMCConfigVersion), "Unable to load config version `"+globalMCConfigVersion+"`.")
+	configured := make([]string, 0, len(conf.Aliases))
+	for alias := range conf.Aliases {
+		configured = append(configured, alias)
+	}
+	aliases, err := expandForeachAliases(cliCtx.String("aliases"), configured)
+	fatalIf(err, "Unable to find the aliases to run the command against.")
+
+	aliasCh := make(chan string)
+	resultCh := make(chan foreachMessage)
+	var wg sync.WaitGroup
+	for i := 0; i < parallel && i < len(aliases); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for alias := range aliasCh {
+				resultCh <- runForeach(args, alias)
+			}
+		}()
+	}
+	go func() {
+		for _, alias := range aliases {
+			aliasCh <- alias
+		}
+		close(aliasCh)
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	width := 0
+	for _, alias := range aliases {
+		if len(alias) > width {
+			width = len(alias)
+		}
+	}
+
+	summary := foreachSummaryMessage{Status: "success"}
+	for msg := range resultCh {
+		msg.width = width
+		printMsg(msg)
+		if msg.Status == "error" {
+			summary.Failed = append(summary.Failed, msg.Alias)
+		} else {
+			summary.Succeeded++
+		}
+	}
+	if len(summary.Failed) > 0 {
+		summary.Status = "error"
+		sort.Strings(summary.Failed)
+	}
+	printMsg(summary)
+
+	if len(summary.Failed) > 0 {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
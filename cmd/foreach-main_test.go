@@ -0,0 +1,81 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandForeachAliases(t *testing.T) {
+	configured := []string{"play", "prod-us", "prod-eu", "local"}
+	testCases := []struct {
+		patterns string
+		expected []string
+		fails    bool
+	}{
+		{"play", []string{"play"}, false},
+		{"prod-us,play", []string{"prod-us", "play"}, false},
+		{"prod-*", []string{"prod-eu", "prod-us"}, false},
+		{"prod-us, prod-*", []string{"prod-us", "prod-eu"}, false},
+		{"*", []string{"local", "play", "prod-eu", "prod-us"}, false},
+		{"staging", nil, true},
+		{"play,[", nil, true},
+		{" , ", nil, true},
+	}
+	for i, testCase := range testCases {
+		aliases, err := expandForeachAliases(testCase.patterns, configured)
+		if (err != nil) != testCase.fails {
+			t.Fatalf("Test %d: expected failure %v, got %v", i+1, testCase.fails, err)
+		}
+		if !reflect.DeepEqual(aliases, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, aliases)
+		}
+	}
+}
+
+func TestForeachArgs(t *testing.T) {
+	args := []string{"ls", "--json", "{alias}/bucket", "{alias}-{alias}"}
+	expected := []string{"ls", "--json", "play/bucket", "play-play"}
+	if got := foreachArgs(args, "play"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if args[2] != "{alias}/bucket" {
+		t.Errorf("arguments were modified: %v", args)
+	}
+}
+
+func TestParseForeachOutput(t *testing.T) {
+	testCases := []struct {
+		output string
+		count  int
+		rest   string
+	}{
+		{"", 0, ""},
+		{"{\n \"status\": \"success\"\n}\n", 1, ""},
+		{"{\"a\":1}\n{\"b\":\n 2}\n", 2, ""},
+		{"{\"a\":1}\nmc: <ERROR> unable to connect\n", 1, "mc: <ERROR> unable to connect"},
+		{"panic: oops\n", 0, "panic: oops"},
+	}
+	for i, testCase := range testCases {
+		msgs, rest := parseForeachOutput([]byte(testCase.output))
+		if len(msgs) != testCase.count || rest != testCase.rest {
+			t.Errorf("Test %d: expected (%d, %q), got (%d, %q)", i+1, testCase.count, testCase.rest, len(msgs), rest)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minio/cli"
+	xfilepath "github.com/minio/filepath"
+)
+
+// Local directory walk flag of the transfer commands.
+var walkFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "walk-workers",
+		Usage: "number of local folders read in parallel when listing a local source (default 1)",
+	},
+}
+
+// Number of local folders read in parallel by recursive listings.
+var globalWalkWorkers = 1
+
+// Folders read ahead by each walk worker, bounding the memory used by
+// listings not yet consumed.
+const walkReadAheadPerWorker = 64
+
+// setWalkWorkers sets the parallelism of local listings from --walk-workers.
+func setWalkWorkers(cliCtx *cli.Context) {
+	if !cliCtx.IsSet("walk-workers") {
+		return
+	}
+	workers := cliCtx.Int("walk-workers")
+	if workers < 1 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("walk-workers")), "--walk-workers must be at least 1.")
+	}
+	globalWalkWorkers = workers
+}
+
+// walkDir is a folder listing read ahead by a walk worker.
+type walkDir struct {
+	done chan struct{}
+	fis  []os.FileInfo
+	err  error
+}
+
+// parallelWalker walks a folder tree in the lexical order of
+// xfilepath.Walk, while workers read the folders met next ahead of it.
+type parallelWalker struct {
+	walkFn   xfilepath.WalkFunc
+	maxAhead int
+
+	mu      sync.Mutex
+	ahead   map[string]*walkDir
+	queue   chan string
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// parallelWalk walks the tree at root like xfilepath.Walk, reading up to
+// workers folders in parallel. The order of the walk is unchanged, which
+// listings compared against sorted object listings rely on.
+func parallelWalk(root string, workers int, walkFn xfilepath.WalkFunc) error {
+	if workers <= 1 {
+		return xfilepath.Walk(root, walkFn)
+	}
+	info, e := os.Lstat(root)
+	if e != nil {
+		return walkFn(root, nil, e)
+	}
+
+	w := &parallelWalker{
+		walkFn:   walkFn,
+		maxAhead: workers * walkReadAheadPerWorker,
+		ahead:    make(map[string]*walkDir),
+	}
+	w.queue = make(chan string, w.maxAhead)
+	for i := 0; i < workers; i++ {
+		w.wg.Add(1)
+		go w.worker()
+	}
+	defer w.stop()
+	return w.walk(root, info)
+}
+
+// worker reads the queued folders, then queues their sub folders.
+func (w *parallelWalker) worker() {
+	defer w.wg.Done()
+	for path := range w.queue {
+		w.mu.Lock()
+		d := w.ahead[path]
+		w.mu.Unlock()
+		d.fis, d.err = walkReadDir(path)
+		close(d.done)
+		if d.err == nil {
+			w.readAhead(path, d.fis)
+		}
+	}
+}
+
+// readAhead queues the sub folders of path while the read ahead limit allows.
+func (w *parallelWalker) readAhead(path string, fis []os.FileInfo) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, fi := range fis {
+		if w.stopped || len(w.ahead) >= w.maxAhead {
+			return
+		}
+		if !fi.IsDir() {
+			continue
+		}
+		dir := filepath.Join(path, fi.Name())
+		if _, ok := w.ahead[dir]; ok {
+			continue
+		}
+		w.ahead[dir] = &walkDir{done: make(chan struct{})}
+		// Never blocks, the queue holds as many folders as read ahead.
+		w.queue <- dir
+	}
+}
+
+// readDir returns the listing of a folder, read ahead or read now.
+func (w *parallelWalker) readDir(path string) ([]os.FileInfo, error) {
+	w.mu.Lock()
+	d, ok := w.ahead[path]
+	w.mu.Unlock()
+	if !ok {
+		fis, e := walkReadDir(path)
+		if e == nil {
+			w.readAhead(path, fis)
+		}
+		return fis, e
+	}
+	<-d.done
+	w.mu.Lock()
+	delete(w.ahead, path)
+	w.mu.Unlock()
+	return d.fis, d.err
+}
+
+// skip forgets the folders read ahead under a skipped folder.
+func (w *parallelWalker) skip(path string) {
+	prefix := path + string(os.PathSeparator)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for dir, d := range w.ahead {
+		if dir != path && !strings.HasPrefix(dir, prefix) {
+			continue
+		}
+		select {
+		case <-d.done:
+			delete(w.ahead, dir)
+		default:
+			// Still being read, it is forgotten when the walk stops.
+		}
+	}
+}
+
+// stop waits for the workers to finish the folders being read.
+func (w *parallelWalker) stop() {
+	w.mu.Lock()
+	w.stopped = true
+	close(w.queue)
+	w.mu.Unlock()
+	w.wg.Wait()
+}
+
+// walk follows the logic of xfilepath.Walk.
+func (w *parallelWalker) walk(path string, info os.FileInfo) error {
+	e := w.walkFn(path, info, nil)
+	if e != nil {
+		if info.Mode().IsDir() && e == xfilepath.ErrSkipDir {
+			w.skip(path)
+			return nil
+		}
+		if info.Mode().IsRegular() && e == xfilepath.ErrSkipFile {
+			return nil
+		}
+		return e
+	}
+
+	if !info.IsDir() {
+		return nil
+	}
+
+	fis, e := w.readDir(path)
+	if e != nil {
+		return w.walkFn(path, info, e)
+	}
+	for _, fi := range fis {
+		if e = w.walk(filepath.Join(path, fi.Name()), fi); e != nil {
+			if e == xfilepath.ErrSkipDir || e == xfilepath.ErrSkipFile {
+				return nil
+			}
+			return e
+		}
+	}
+	return nil
+}
+
+// walkReadDir reads a folder sorted like xfilepath.Walk, folder names
+// being compared with a trailing separator.
+func walkReadDir(dirname string) ([]os.FileInfo, error) {
+	f, e := os.Open(dirname)
+	if e != nil {
+		return nil, e
+	}
+	defer f.Close()
+	fis, e := f.Readdir(-1)
+	if e != nil {
+		return nil, e
+	}
+	walkName := func(fi os.FileInfo) string {
+		if fi.IsDir() {
+			return fi.Name() + string(os.PathSeparator)
+		}
+		return fi.Name()
+	}
+	sort.Slice(fis, func(i, j int) bool {
+		return walkName(fis[i]) < walkName(fis[j])
+	})
+	return fis, nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	xfilepath "github.com/minio/filepath"
+)
+
+func TestParallelWalk(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"a-b", "a.txt", "a/b/c", "a/b/d", "a/bb", "a/c/skip/x",
+		"b/1", "b/2/3/4/5", "b.d/z", "skip/x", "z",
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if e := os.MkdirAll(filepath.Dir(path), 0o755); e != nil {
+			t.Fatal(e)
+		}
+		if e := os.WriteFile(path, []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	walk := func(workers int) []string {
+		var paths []string
+		e := parallelWalk(root, workers, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() && info.Name() == "skip" {
+				return xfilepath.ErrSkipDir
+			}
+			paths = append(paths, path)
+			return nil
+		})
+		if e != nil {
+			t.Fatal(e)
+		}
+		return paths
+	}
+
+	expected := walk(1)
+	for _, workers := range []int{2, 4, 64} {
+		if got := walk(workers); !reflect.DeepEqual(got, expected) {
+			t.Errorf("workers %d: expected %v, got %v", workers, expected, got)
+		}
+	}
+}
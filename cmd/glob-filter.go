@@ -0,0 +1,208 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"path"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/pkg/wildcard"
+)
+
+// globRule is a single --include or --exclude pattern.
+type globRule struct {
+	pattern string
+	include bool
+}
+
+// globFilter selects objects by their path with --include and --exclude
+// patterns applied in the order of the command line, the first matching
+// pattern decides, objects matching no pattern are selected.
+type globFilter struct {
+	rules []globRule
+}
+
+// globFilterFlag is the value of --include and --exclude, both flags
+// share the same filter to keep the order of the patterns.
+type globFilterFlag struct {
+	filter  *globFilter
+	include bool
+}
+
+// Set adds the pattern to the filter, called for each flag in order.
+func (f *globFilterFlag) Set(pattern string) error {
+	f.filter.rules = append(f.filter.rules, globRule{pattern: pattern, include: f.include})
+	return nil
+}
+
+func (f *globFilterFlag) String() string {
+	return ""
+}
+
+// newGlobFilterFlags returns the --include and --exclude flags sharing
+// the same filter.
+func newGlobFilterFlags(filter *globFilter) []cli.Flag {
+	return []cli.Flag{
+		cli.GenericFlag{
+			Name:  "include",
+			Usage: "copy only object(s) matching the pattern, can be repeated and combined with --exclude",
+			Value: &globFilterFlag{filter: filter, include: true},
+		},
+		cli.GenericFlag{
+			Name:  "exclude",
+			Usage: "skip object(s) matching the pattern, can be repeated and combined with --include",
+			Value: &globFilterFlag{filter: filter},
+		},
+	}
+}
+
+// Files and folders created by operating systems, skipped with
+// --exclude-system.
+var (
+	systemFileNames   = []string{".DS_Store", "._*", "Thumbs.db", "ehthumbs.db", "desktop.ini"}
+	systemFolderNames = []string{"lost+found", ".Trashes", ".Spotlight-V100", ".fseventsd", "$RECYCLE.BIN", "System Volume Information"}
+)
+
+// excludeDefaultFlags skip hidden and system files, both default to
+// the "exclude" setting of the configuration and can be turned off for
+// a command with --exclude-hidden=false.
+var excludeDefaultFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "exclude-hidden",
+		Usage: "skip hidden files and folders, whose name starts with '.'",
+	},
+	cli.BoolFlag{
+		Name:  "exclude-system",
+		Usage: "skip files and folders created by operating systems, such as .DS_Store, Thumbs.db and lost+found",
+	},
+}
+
+// defaultExcludePatterns returns the patterns of --exclude-hidden and
+// --exclude-system, a pattern with a '/' matching the whole relative
+// path as with --exclude.
+func defaultExcludePatterns(cliCtx *cli.Context) []string {
+	var hidden, system bool
+	if loadMcConfig != nil {
+		if mcCfg, err := loadMcConfig(); err == nil && mcCfg.Exclude != nil {
+			hidden, system = mcCfg.Exclude.Hidden, mcCfg.Exclude.System
+		}
+	}
+	if cliCtx.IsSet("exclude-hidden") {
+		hidden = cliCtx.Bool("exclude-hidden")
+	}
+	if cliCtx.IsSet("exclude-system") {
+		system = cliCtx.Bool("exclude-system")
+	}
+	return excludePatterns(hidden, system)
+}
+
+// excludePatterns returns the patterns skipping hidden files, system
+// files or both.
+func excludePatterns(hidden, system bool) []string {
+	var patterns []string
+	if hidden {
+		patterns = append(patterns, "/.*", "*/.*")
+	}
+	if system {
+		patterns = append(patterns, systemFileNames...)
+		for _, name := range systemFolderNames {
+			patterns = append(patterns, "/"+name+"/*", "*/"+name+"/*")
+		}
+	}
+	return patterns
+}
+
+// wholePathPatterns converts the patterns of defaultExcludePatterns for
+// the --exclude of mirror, which always match the whole relative path.
+func wholePathPatterns(patterns []string) []string {
+	converted := make([]string, 0, 2*len(patterns))
+	for _, pattern := range patterns {
+		if strings.Contains(pattern, "/") {
+			converted = append(converted, strings.TrimPrefix(pattern, "/"))
+			continue
+		}
+		converted = append(converted, pattern, "*/"+pattern)
+	}
+	return converted
+}
+
+// getGlobFilter returns the filter of --include and --exclude flags,
+// the hidden and system files skipped by default come first so that
+// they can't be included again.
+func getGlobFilter(cliCtx *cli.Context) globFilter {
+	if f, ok := cliCtx.Generic("include").(*globFilterFlag); ok {
+		var filter globFilter
+		for _, pattern := range defaultExcludePatterns(cliCtx) {
+			filter.rules = append(filter.rules, globRule{pattern: pattern})
+		}
+		filter.rules = append(filter.rules, f.filter.rules...)
+		return filter
+	}
+	return globFilter{}
+}
+
+// parseGlobFilter parses a filter saved with String.
+func parseGlobFilter(s string) globFilter {
+	var f globFilter
+	for _, line := range strings.Split(s, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		f.rules = append(f.rules, globRule{pattern: line[1:], include: line[0] == '+'})
+	}
+	return f
+}
+
+// String returns the patterns as lines prefixed with '+' for an
+// included pattern or '-' for an excluded one.
+func (f globFilter) String() string {
+	lines := make([]string, 0, len(f.rules))
+	for _, rule := range f.rules {
+		if rule.include {
+			lines = append(lines, "+"+rule.pattern)
+		} else {
+			lines = append(lines, "-"+rule.pattern)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isEmpty returns true if no pattern is specified.
+func (f globFilter) isEmpty() bool {
+	return len(f.rules) == 0
+}
+
+// match returns true if the object of the slash separated path relative
+// to the source is selected. A pattern with a '/' is matched against the
+// whole relative path, otherwise against the object name only.
+func (f globFilter) match(name string) bool {
+	name = strings.TrimPrefix(name, "/")
+	for _, rule := range f.rules {
+		subject := path.Base(name)
+		pattern := rule.pattern
+		if strings.Contains(pattern, "/") {
+			subject = name
+			pattern = strings.TrimPrefix(pattern, "/")
+		}
+		if wildcard.Match(pattern, subject) {
+			return rule.include
+		}
+	}
+	return true
+}
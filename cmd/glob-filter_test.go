@@ -0,0 +1,94 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestGlobFilterMatch(t *testing.T) {
+	testCases := []struct {
+		filter string
+		name   string
+		match  bool
+	}{
+		{"", "a/b.txt", true},
+		{"+*.parquet\n-*", "2021/01/data.parquet", true},
+		{"+*.parquet\n-*", "2021/01/data.csv", false},
+		{"-*\n+*.parquet", "2021/01/data.parquet", false},
+		{"-tmp/*", "tmp/a/b.txt", false},
+		{"-tmp/*", "src/tmp/b.txt", true},
+		{"-/tmp/*", "/tmp/b.txt", false},
+		{"-.*", "src/.hidden", false},
+		{"-.*", "src/visible", true},
+	}
+
+	for i, testCase := range testCases {
+		filter := parseGlobFilter(testCase.filter)
+		if filter.String() != testCase.filter {
+			t.Fatalf("Test %d: expected %q, got %q", i+1, testCase.filter, filter.String())
+		}
+		if match := filter.match(testCase.name); match != testCase.match {
+			t.Fatalf("Test %d: expected %t, got %t", i+1, testCase.match, match)
+		}
+	}
+}
+
+func TestExcludePatterns(t *testing.T) {
+	testCases := []struct {
+		hidden, system bool
+		name           string
+		match          bool
+	}{
+		{false, false, ".git/config", true},
+		{true, false, ".git/config", false},
+		{true, false, "src/.git/config", false},
+		{true, false, "src/.env", false},
+		{true, false, ".env", false},
+		{true, false, "src/main.go", true},
+		{true, false, "src/v1.2/main.go", true},
+		{true, false, ".DS_Store", false},
+		{false, true, ".env", true},
+		{false, true, "photos/.DS_Store", false},
+		{false, true, "photos/._IMG_0001.JPG", false},
+		{false, true, "photos/Thumbs.db", false},
+		{false, true, "lost+found/#1234", false},
+		{false, true, "disk/lost+found/#1234", false},
+		{false, true, "$RECYCLE.BIN/S-1-5-21/desktop.ini", false},
+		{false, true, "photos/IMG_0001.JPG", true},
+		{false, true, "photos/lost+found.txt", true},
+		{true, true, "src/.git/config", false},
+		{true, true, "photos/Thumbs.db", false},
+	}
+	for i, testCase := range testCases {
+		patterns := excludePatterns(testCase.hidden, testCase.system)
+
+		var filter globFilter
+		for _, pattern := range patterns {
+			filter.rules = append(filter.rules, globRule{pattern: pattern})
+		}
+		if match := filter.match(testCase.name); match != testCase.match {
+			t.Errorf("Test %d: expected cp to select %q: %v, got %v", i+1, testCase.name, testCase.match, match)
+		}
+
+		excluded := matchExcludeOptions(wholePathPatterns(patterns), testCase.name)
+		if excluded == testCase.match {
+			t.Errorf("Test %d: expected mirror to select %q: %v, got %v", i+1, testCase.name, testCase.match, !excluded)
+		}
+	}
+}
@@ -21,8 +21,12 @@ package cmd
 import (
 	"context"
 	"crypto/x509"
+	"fmt"
+	"net"
 	"net/url"
+	"time"
 
+	"github.com/jmespath/go-jmespath"
 	"github.com/minio/cli"
 	"github.com/minio/pkg/console"
 )
@@ -64,10 +68,18 @@ var (
 	globalInsecure       = false  // Insecure flag set via command line
 	globalDevMode        = false  // dev flag set via command line
 	globalSubnetProxyURL *url.URL // Proxy to be used for communication with subnet
+	globalAdminNode      = ""     // Cluster node admin commands are directed at
+	globalListAPI        = ""     // ListObjects API version forced via command line
+	globalNotifyExec     = ""     // Command run once a long command completes
+	globalNotifyAfter    = ""     // Minimum duration of a command to run globalNotifyExec
+	globalCommandName    = ""     // Full name of the running command
 
 	globalContext, globalCancel = context.WithCancel(context.Background())
 )
 
+// globalQuery selects the fields of the JSON output to print, set via --query.
+var globalQuery *jmespath.JMESPath
+
 var (
 	// Terminal width
 	globalTermWidth int
@@ -98,6 +110,23 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 	quiet := ctx.IsSet("quiet") || ctx.GlobalIsSet("quiet")
 	debug := ctx.IsSet("debug") || ctx.GlobalIsSet("debug")
 	json := ctx.IsSet("json") || ctx.GlobalIsSet("json")
+
+	// sql defines its own --query for the SQL expression.
+	var query string
+	if ctx.Command.Name != "sql" {
+		query = ctx.String("query")
+	}
+	if query == "" {
+		query = ctx.GlobalString("query")
+	}
+	if query != "" {
+		expr, e := jmespath.Compile(query)
+		if e != nil {
+			return fmt.Errorf("invalid --query %q: %w", query, e)
+		}
+		globalQuery = expr
+		json = true
+	}
 	noColor := ctx.IsSet("no-color") || ctx.GlobalIsSet("no-color")
 	insecure := ctx.IsSet("insecure") || ctx.GlobalIsSet("insecure")
 	devMode := ctx.IsSet("dev") || ctx.GlobalIsSet("dev")
@@ -113,6 +142,63 @@ func setGlobalsFromContext(ctx *cli.Context) error {
 		}
 	}
 
+	node := ctx.String("node")
+	if node == "" {
+		node = ctx.GlobalString("node")
+	}
+	if node != "" {
+		if _, _, e = net.SplitHostPort(node); e != nil {
+			return fmt.Errorf("invalid --node %q, expected HOST:PORT: %w", node, e)
+		}
+		globalAdminNode = node
+	}
+
+	listAPI := ctx.String("list-api")
+	if listAPI == "" {
+		listAPI = ctx.GlobalString("list-api")
+	}
+	switch listAPI {
+	case "", "v1", "v2":
+		if listAPI != "" {
+			globalListAPI = listAPI
+		}
+	default:
+		return fmt.Errorf("invalid --list-api %q, expected v1 or v2", listAPI)
+	}
+
+	resolve := ctx.StringSlice("resolve")
+	if len(resolve) == 0 {
+		resolve = ctx.GlobalStringSlice("resolve")
+	}
+	if len(resolve) > 0 {
+		if globalResolveOverrides, e = parseResolveOverrides(resolve); e != nil {
+			return e
+		}
+	}
+
+	if ctx.Command.HelpName != "" {
+		globalCommandName = ctx.Command.HelpName
+	}
+
+	notifyExec := ctx.String("notify-exec")
+	if notifyExec == "" {
+		notifyExec = ctx.GlobalString("notify-exec")
+	}
+	if notifyExec != "" {
+		globalNotifyExec = notifyExec
+	}
+
+	notifyAfter := ctx.String("notify-after")
+	if notifyAfter == "" {
+		notifyAfter = ctx.GlobalString("notify-after")
+	}
+	if notifyAfter != "" {
+		if _, e = time.ParseDuration(notifyAfter); e != nil {
+			return fmt.Errorf("invalid --notify-after %q: %w", notifyAfter, e)
+		}
+		globalNotifyAfter = notifyAfter
+	}
+
 	setGlobals(quiet, debug, json, noColor, insecure, devMode, proxyURL)
 	return nil
 }
@@ -87,6 +87,9 @@ EXAMPLES:
 
   4. Display the first lines of a specific object version.
      {{.Prompt}} {{.HelpName}} --version-id "3ddac055-89a7-40fa-8cd3-530a5581b6b8" s3/json-data/population.json
+
+  5. Display the first 5 lines of an encrypted object as it was 7 days earlier.
+     {{.Prompt}} {{.HelpName}} -n 5 --rewind 7d --encrypt-key "s3/json-data=32byteslongsecretkeymustbegiven1" s3/json-data/population.json
 `,
 }
 
@@ -31,6 +31,7 @@ var ilmSubcommands = []cli.Command{
 	ilmExportCmd,
 	ilmImportCmd,
 	ilmRestoreCmd,
+	ilmTierCmd,
 }
 
 var ilmCmd = cli.Command{
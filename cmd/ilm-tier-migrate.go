@@ -0,0 +1,273 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/cmd/ilm"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/pkg/console"
+)
+
+var ilmTierMigrateFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "from",
+		Usage: "remote tier currently referenced by the lifecycle rules",
+	},
+	cli.StringFlag{
+		Name:  "to",
+		Usage: "remote tier the lifecycle rules should reference instead",
+	},
+	cli.BoolFlag{
+		Name:  "retransition",
+		Usage: "rewrite objects already transitioned to the old tier, so the updated rules transition them to the new tier",
+	},
+}
+
+var ilmTierMigrateCmd = cli.Command{
+	Name:         "migrate",
+	Usage:        "point lifecycle rules at another remote tier",
+	Action:       mainILMTierMigrate,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(append(ilmTierMigrateFlags, ioFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} --from TIER --to TIER [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Update the transition and noncurrent version transition of every lifecycle rule referencing a
+  remote tier to reference another one. TARGET is a bucket, or an alias to update all its buckets.
+
+  With '--retransition', all versions of objects already transitioned to the old tier are rewritten
+  in place with their version ID, modification time, metadata and encryption, the updated rules then
+  transition them to the new tier at the same age. Objects encrypted with customer provided keys
+  need the keys passed with '--encrypt-key'.
+
+EXAMPLES:
+  1. Point all lifecycle rules of all buckets on alias 'myminio' at the tier WARM-NEW instead of WARM-OLD.
+     {{.Prompt}} {{.HelpName}} --from WARM-OLD --to WARM-NEW myminio
+
+  2. Point the lifecycle rules of mybucket at the tier WARM-NEW and move its transitioned objects there.
+     {{.Prompt}} {{.HelpName}} --from WARM-OLD --to WARM-NEW --retransition myminio/mybucket
+
+  3. Same as above, for a bucket with objects encrypted with a customer provided key.
+     {{.Prompt}} {{.HelpName}} --from WARM-OLD --to WARM-NEW --retransition --encrypt-key "myminio/mybucket=32byteslongsecretkeymustbegiven1" myminio/mybucket
+`,
+}
+
+type ilmTierMigrateMessage struct {
+	Status         string   `json:"status"`
+	Bucket         string   `json:"bucket"`
+	From           string   `json:"from"`
+	To             string   `json:"to"`
+	Rules          []string `json:"rules"`
+	Retransitioned int64    `json:"retransitioned,omitempty"`
+}
+
+func (i ilmTierMigrateMessage) String() string {
+	if len(i.Rules) == 0 {
+		return console.Colorize(ilmThemeRow, "No lifecycle rule of `"+i.Bucket+"` references `"+i.From+"`.")
+	}
+	msg := fmt.Sprintf("Updated %d lifecycle rule(s) of `%s` from `%s` to `%s`.", len(i.Rules), i.Bucket, i.From, i.To)
+	if i.Retransitioned > 0 {
+		msg += fmt.Sprintf(" Rewrote %d transitioned object(s).", i.Retransitioned)
+	}
+	return console.Colorize(ilmThemeResultSuccess, msg)
+}
+
+func (i ilmTierMigrateMessage) JSON() string {
+	msgBytes, e := json.MarshalIndent(i, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+func checkILMTierMigrateSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "migrate", globalErrorExitStatus)
+	}
+	from, to := ctx.String("from"), ctx.String("to")
+	if from == "" || to == "" {
+		fatalIf(errInvalidArgument(), "Please specify the remote tiers with `--from` and `--to`.")
+	}
+	if strings.EqualFold(from, to) {
+		fatalIf(errInvalidArgument().Trace(from, to), "`--from` and `--to` must be different tiers.")
+	}
+}
+
+// retransitionHeaders returns the headers an object version is rewritten
+// with, its metadata and content headers, and the same encryption.
+func retransitionHeaders(info minio.ObjectInfo, sse encrypt.ServerSide) map[string]string {
+	h := make(http.Header)
+	h.Set("X-Amz-Metadata-Directive", "REPLACE")
+	h.Set("X-Amz-Storage-Class", "STANDARD")
+	for k, v := range info.UserMetadata {
+		h.Set("X-Amz-Meta-"+k, v)
+	}
+	for _, k := range []string{"Content-Type", "Content-Encoding", "Content-Disposition", "Content-Language", "Cache-Control", "Expires"} {
+		if v := info.Metadata.Get(k); v != "" {
+			h.Set(k, v)
+		}
+	}
+	switch {
+	case sse != nil && sse.Type() == encrypt.SSEC:
+		sse.Marshal(h)
+		encrypt.SSECopy(sse).Marshal(h)
+	case info.Metadata.Get("X-Amz-Server-Side-Encryption") == "aws:kms":
+		kms, e := encrypt.NewSSEKMS(info.Metadata.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"), nil)
+		if e == nil {
+			kms.Marshal(h)
+		}
+	case info.Metadata.Get("X-Amz-Server-Side-Encryption") != "":
+		encrypt.NewSSE().Marshal(h)
+	}
+	headers := make(map[string]string, len(h))
+	for k := range h {
+		headers[k] = h.Get(k)
+	}
+	return headers
+}
+
+// retransitionVersion rewrites in place an object version transitioned to
+// a remote tier. The version is rewritten the way MinIO replicates it,
+// keeping its ID and modification time, so the lifecycle rules transition
+// it to their new tier at the same age.
+func retransitionVersion(ctx context.Context, clnt *S3Client, versionID string, sse encrypt.ServerSide) *probe.Error {
+	bucket, object := clnt.url2BucketAndObject()
+	info, e := clnt.api.StatObject(ctx, bucket, object, minio.StatObjectOptions{
+		VersionID:            versionID,
+		ServerSideEncryption: sse,
+	})
+	if e != nil {
+		return probe.NewError(e)
+	}
+	srcOpts := minio.CopySrcOptions{Bucket: bucket, Object: object, VersionID: versionID}
+	dstOpts := minio.PutObjectOptions{
+		Internal: minio.AdvancedPutOptions{
+			SourceVersionID:    versionID,
+			SourceMTime:        info.LastModified,
+			ReplicationRequest: true,
+		},
+	}
+	core := minio.Core{Client: clnt.api}
+	if _, e = core.CopyObject(ctx, bucket, object, bucket, object, retransitionHeaders(info, sse), srcOpts, dstOpts); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// retransitionObjects rewrites in place all object versions of the bucket
+// transitioned to the tier, returns the number of versions rewritten.
+func retransitionObjects(ctx context.Context, bucketURL, tier string, encKeyDB map[string][]prefixSSEPair) (int64, *probe.Error) {
+	alias, urlStr, _ := mustExpandAlias(bucketURL)
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return 0, err.Trace(bucketURL)
+	}
+
+	var count int64
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, WithOlderVersions: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			return count, content.Err.Trace(bucketURL)
+		}
+		if content.IsDeleteMarker || !strings.EqualFold(content.StorageClass, tier) {
+			continue
+		}
+		objectPath := content.URL.Path
+		objectClnt, err := newClientFromAlias(alias, content.URL.String())
+		if err != nil {
+			return count, err.Trace(objectPath)
+		}
+		s3Clnt, ok := objectClnt.(*S3Client)
+		if !ok {
+			return count, errInvalidArgument().Trace(bucketURL)
+		}
+		sse := getSSE(urlJoinPath(alias, objectPath), encKeyDB[alias])
+		if err = retransitionVersion(ctx, s3Clnt, content.VersionID, sse); err != nil {
+			errorIf(err.Trace(objectPath, content.VersionID), "Unable to rewrite `"+objectPath+"` ("+content.VersionID+").")
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+func mainILMTierMigrate(cliCtx *cli.Context) error {
+	ctx, cancelILMTierMigrate := context.WithCancel(globalContext)
+	defer cancelILMTierMigrate()
+
+	checkILMTierMigrateSyntax(cliCtx)
+	setILMDisplayColorScheme()
+
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	targetURL := cliCtx.Args().Get(0)
+	from, to := strings.ToUpper(cliCtx.String("from")), strings.ToUpper(cliCtx.String("to"))
+
+	var bucketURLs []string
+	if _, bucketPath := url2Alias(targetURL); strings.Trim(bucketPath, "/") == "" {
+		bucketURLs, err = listBucketsURLs(ctx, targetURL)
+		fatalIf(err.Trace(targetURL), "Unable to list buckets of `"+targetURL+"`.")
+	} else {
+		bucketURLs = []string{targetURL}
+	}
+
+	for _, bucketURL := range bucketURLs {
+		client, err := newClient(bucketURL)
+		fatalIf(err.Trace(bucketURL), "Unable to initialize client for "+bucketURL+".")
+
+		ilmCfg, err := client.GetLifecycle(ctx)
+		if err != nil {
+			errorIf(err.Trace(bucketURL), "Unable to fetch lifecycle rules of `"+bucketURL+"`.")
+			continue
+		}
+
+		msg := ilmTierMigrateMessage{
+			Status: "success",
+			Bucket: bucketURL,
+			From:   from,
+			To:     to,
+			Rules:  ilm.ReplaceILMTier(ilmCfg, from, to),
+		}
+		if len(msg.Rules) > 0 {
+			err = client.SetLifecycle(ctx, ilmCfg)
+			fatalIf(err.Trace(bucketURL), "Unable to set lifecycle rules of `"+bucketURL+"`.")
+
+			if cliCtx.Bool("retransition") {
+				msg.Retransitioned, err = retransitionObjects(ctx, bucketURL, from, encKeyDB)
+				fatalIf(err.Trace(bucketURL), "Unable to rewrite the objects of `"+bucketURL+"` transitioned to `"+from+"`.")
+			}
+		}
+		printMsg(msg)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+func TestRetransitionHeaders(t *testing.T) {
+	ssec, e := encrypt.NewSSEC([]byte("32byteslongsecretkeymustbegiven1"))
+	if e != nil {
+		t.Fatal(e)
+	}
+	info := func(h map[string]string) minio.ObjectInfo {
+		metadata := make(http.Header)
+		for k, v := range h {
+			metadata.Set(k, v)
+		}
+		return minio.ObjectInfo{Metadata: metadata, UserMetadata: map[string]string{"Owner": "ops"}}
+	}
+	testCases := []struct {
+		info     minio.ObjectInfo
+		sse      encrypt.ServerSide
+		expected map[string]string
+	}{
+		{info(map[string]string{"Content-Type": "text/plain"}), nil, map[string]string{
+			"Content-Type": "text/plain",
+		}},
+		{info(map[string]string{"X-Amz-Server-Side-Encryption": "AES256"}), nil, map[string]string{
+			"X-Amz-Server-Side-Encryption": "AES256",
+		}},
+		{info(map[string]string{"X-Amz-Server-Side-Encryption": "aws:kms", "X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id": "my-key"}), nil, map[string]string{
+			"X-Amz-Server-Side-Encryption":                "aws:kms",
+			"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id": "my-key",
+		}},
+		{info(map[string]string{"X-Amz-Server-Side-Encryption-Customer-Algorithm": "AES256"}), ssec, map[string]string{
+			"X-Amz-Server-Side-Encryption-Customer-Algorithm":             "AES256",
+			"X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm": "AES256",
+		}},
+	}
+	for i, testCase := range testCases {
+		headers := retransitionHeaders(testCase.info, testCase.sse)
+		testCase.expected["X-Amz-Metadata-Directive"] = "REPLACE"
+		testCase.expected["X-Amz-Storage-Class"] = "STANDARD"
+		testCase.expected["X-Amz-Meta-Owner"] = "ops"
+		for k, v := range testCase.expected {
+			if headers[k] != v {
+				t.Errorf("Test %d: expected %s: %q, got %q", i+1, k, v, headers[k])
+			}
+		}
+	}
+}
+
+func TestRetransitionVersion(t *testing.T) {
+	const versionID = "3e8e9a58-5a73-4c52-b6bf-c3bbe0e2a8f1"
+	modTime := time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+
+	var copyReq *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Query().Has("location"):
+			w.Write([]byte(`<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">us-east-1</LocationConstraint>`))
+		case r.Method == http.MethodHead:
+			if r.URL.Query().Get("versionId") != versionID {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Last-Modified", modTime.Format(http.TimeFormat))
+			w.Header().Set("ETag", `"d41d8cd98f00b204e9800998ecf8427e"`)
+			w.Header().Set("X-Amz-Storage-Class", "WARM-OLD")
+			w.Header().Set("X-Amz-Server-Side-Encryption", "AES256")
+			w.Header().Set("X-Amz-Meta-Owner", "ops")
+			w.Header().Set("Content-Type", "text/plain")
+		case r.Method == http.MethodPut:
+			copyReq = r
+			w.Write([]byte(`<CopyObjectResult><ETag>"d41d8cd98f00b204e9800998ecf8427e"</ETag></CopyObjectResult>`))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	conf := new(Config)
+	conf.HostURL = server.URL + "/bucket/object"
+	conf.AccessKey = "WLGDGYAQYIGI833EV05A"
+	conf.SecretKey = "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF"
+	conf.Signature = "S3v4"
+	clnt, err := S3New(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = retransitionVersion(context.Background(), clnt.(*S3Client), versionID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if copyReq == nil {
+		t.Fatal("expected the version to be copied")
+	}
+	expected := map[string]string{
+		"X-Amz-Copy-Source":                  "bucket/object?versionId=" + versionID,
+		"X-Minio-Source-Mtime":               modTime.Format(time.RFC3339Nano),
+		"X-Amz-Storage-Class":                "STANDARD",
+		"X-Amz-Server-Side-Encryption":       "AES256",
+		"X-Amz-Meta-Owner":                   "ops",
+		"X-Amz-Metadata-Directive":           "REPLACE",
+		"X-Minio-Source-Replication-Request": "",
+	}
+	for k, v := range expected {
+		if _, ok := copyReq.Header[http.CanonicalHeaderKey(k)]; !ok || copyReq.Header.Get(k) != v {
+			t.Errorf("expected %s: %q, got %q", k, v, copyReq.Header.Get(k))
+		}
+	}
+	if got := copyReq.URL.Query().Get("versionId"); got != versionID {
+		t.Errorf("expected the version %s to be rewritten, got %q", versionID, got)
+	}
+}
@@ -100,6 +100,30 @@ func RemoveILMRule(lfcCfg *lifecycle.Configuration, ilmID string) (*lifecycle.Co
 	return lfcCfg, nil
 }
 
+// ReplaceILMTier - Point all transitions to the remote tier `from` in the configuration
+// at the remote tier `to`, returns the IDs of the updated rules.
+func ReplaceILMTier(lfcCfg *lifecycle.Configuration, from, to string) []string {
+	var ids []string
+	if lfcCfg == nil {
+		return ids
+	}
+	for i, rule := range lfcCfg.Rules {
+		updated := false
+		if strings.EqualFold(rule.Transition.StorageClass, from) {
+			lfcCfg.Rules[i].Transition.StorageClass = to
+			updated = true
+		}
+		if strings.EqualFold(rule.NoncurrentVersionTransition.StorageClass, from) {
+			lfcCfg.Rules[i].NoncurrentVersionTransition.StorageClass = to
+			updated = true
+		}
+		if updated {
+			ids = append(ids, rule.ID)
+		}
+	}
+	return ids
+}
+
 // LifecycleOptions is structure to encapsulate
 type LifecycleOptions struct {
 	ID                                   string
@@ -0,0 +1,82 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+var jobAttachCmd = cli.Command{
+	Name:         "attach",
+	Usage:        "follow the output of a job",
+	Action:       mainJobAttach,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} JOB
+
+  JOB is the ID or the name of the job. The output is printed until the job
+  ends, interrupting attach leaves the job running.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Follow the output of the job 'migration'.
+     {{.Prompt}} {{.HelpName}} migration
+`,
+}
+
+// Interval between two reads of the output of a running job.
+const jobAttachInterval = 500 * time.Millisecond
+
+func mainJobAttach(cliCtx *cli.Context) error {
+	j := checkJobSyntax(cliCtx, "attach")
+
+	f, e := os.Open(j.logFile())
+	fatalIf(probe.NewError(e), "Unable to open the job output.")
+	defer f.Close()
+
+	for {
+		// Read the state before the output to print all of it once done.
+		current, err := loadJob(j.ID)
+		fatalIf(err, "Unable to load the job.")
+		_, e = io.Copy(os.Stdout, f)
+		fatalIf(probe.NewError(e), "Unable to read the job output.")
+		if current.state() != jobRunning {
+			if current.ExitCode != 0 {
+				return exitStatus(current.ExitCode)
+			}
+			return nil
+		}
+		select {
+		case <-globalContext.Done():
+			return nil
+		case <-time.After(jobAttachInterval):
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var jobCancelCmd = cli.Command{
+	Name:         "cancel",
+	Usage:        "cancel a running job",
+	Action:       mainJobCancel,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} JOB
+
+  JOB is the ID or the name of the job.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Cancel the job 'migration'.
+     {{.Prompt}} {{.HelpName}} migration
+`,
+}
+
+// jobCancelMessage is printed when a job is canceled.
+type jobCancelMessage struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+}
+
+func (j jobCancelMessage) String() string {
+	return fmt.Sprintf("Canceled job `%s`.", console.Colorize("JobID", j.ID))
+}
+
+func (j jobCancelMessage) JSON() string {
+	j.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(j, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+func mainJobCancel(cliCtx *cli.Context) error {
+	setJobColors()
+	j := checkJobSyntax(cliCtx, "cancel")
+	if j.state() != jobRunning {
+		fatalIf(errInvalidArgument().Trace(j.ID), "The job `"+j.ID+"` is "+j.state()+".")
+	}
+
+	// The supervisor records the job as canceled when the command exits.
+	fatalIf(probe.NewError(ioutil.WriteFile(j.cancelFile(), nil, 0o600)), "Unable to cancel the job.")
+	pid := j.PID
+	if pid == 0 {
+		// The command is not started yet.
+		pid = j.SupervisorPID
+	}
+	if pid != 0 {
+		fatalIf(probe.NewError(terminateProcess(pid)), "Unable to cancel the job.")
+	}
+	printMsg(jobCancelMessage{ID: j.ID})
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var jobListCmd = cli.Command{
+	Name:         "ls",
+	Usage:        "list the jobs",
+	Action:       mainJobList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}}
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List the running and finished jobs.
+     {{.Prompt}} {{.HelpName}}
+`,
+}
+
+// jobListMessage container for a job.
+type jobListMessage struct {
+	Status    string    `json:"status"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	State     string    `json:"state"`
+	Command   string    `json:"command"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+func (j jobListMessage) String() string {
+	name := j.Name
+	if name == "" {
+		name = "-"
+	}
+	return fmt.Sprintf("%s %s %-12s %s mc %s", console.Colorize("Time", "["+j.StartedAt.Local().Format(printDate)+"]"),
+		console.Colorize("JobID", j.ID), name, console.Colorize(jobStateColor(j.State), fmt.Sprintf("%-11s", j.State)), j.Command)
+}
+
+func (j jobListMessage) JSON() string {
+	j.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(j, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// jobStateColor returns the color theme of a job state.
+func jobStateColor(state string) string {
+	switch state {
+	case jobRunning, jobCompleted:
+		return "JobOK"
+	}
+	return "JobKO"
+}
+
+// setJobColors sets the colors of the job messages.
+func setJobColors() {
+	console.SetColor("Time", color.New(color.FgGreen))
+	console.SetColor("JobID", color.New(color.Bold))
+	console.SetColor("JobOK", color.New(color.FgGreen))
+	console.SetColor("JobKO", color.New(color.FgRed))
+}
+
+func mainJobList(cliCtx *cli.Context) error {
+	if len(cliCtx.Args()) != 0 {
+		cli.ShowCommandHelpAndExit(cliCtx, "ls", 1) // last argument is exit code
+	}
+	setJobColors()
+
+	jobs, err := listJobs()
+	fatalIf(err, "Unable to list the jobs.")
+	for _, j := range jobs {
+		printMsg(jobListMessage{
+			ID:        j.ID,
+			Name:      j.Name,
+			State:     j.state(),
+			Command:   strings.Join(j.Args, " "),
+			StartedAt: j.StartedAt,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// jobFormatVersion is the version of the job file format.
+const jobFormatVersion = "1"
+
+var jobSubcommands = []cli.Command{
+	jobStartCmd,
+	jobListCmd,
+	jobStatusCmd,
+	jobCancelCmd,
+	jobAttachCmd,
+	jobRunCmd,
+}
+
+var jobCmd = cli.Command{
+	Name:            "job",
+	Usage:           "run transfers as background jobs surviving the terminal",
+	Action:          mainJob,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	HideHelpCommand: true,
+	Subcommands:     jobSubcommands,
+}
+
+func mainJob(ctx *cli.Context) error {
+	commandNotFound(ctx, jobSubcommands)
+	return nil
+}
+
+// Commands which can run as jobs.
+var jobCommands = map[string]bool{
+	"cp":     true,
+	"mirror": true,
+	"mv":     true,
+}
+
+// Status of a job.
+const (
+	jobRunning     = "running"
+	jobCompleted   = "completed"
+	jobFailed      = "failed"
+	jobCanceled    = "canceled"
+	jobInterrupted = "interrupted"
+)
+
+// jobInfo is the state of a job, saved in <config>/jobs/ID/job.json
+// next to the output of the command. It is written by "job start" and
+// then only by the "job run" process supervising the command.
+type jobInfo struct {
+	Version       string     `json:"version"`
+	ID            string     `json:"id"`
+	Name          string     `json:"name,omitempty"`
+	Args          []string   `json:"args"`
+	Status        string     `json:"status"`
+	SupervisorPID int        `json:"supervisorPid,omitempty"`
+	PID           int        `json:"pid,omitempty"`
+	ExitCode      int        `json:"exitCode"`
+	StartedAt     time.Time  `json:"startedAt"`
+	FinishedAt    *time.Time `json:"finishedAt,omitempty"`
+}
+
+// getJobsDir returns the folder of the jobs.
+func getJobsDir() string {
+	return filepath.Join(mustGetMcConfigDir(), "jobs")
+}
+
+func (j *jobInfo) dir() string {
+	return filepath.Join(getJobsDir(), j.ID)
+}
+
+// logFile returns the file receiving the output of the command.
+func (j *jobInfo) logFile() string {
+	return filepath.Join(j.dir(), "output.log")
+}
+
+// cancelFile returns the file marking a job canceled by the user.
+func (j *jobInfo) cancelFile() string {
+	return filepath.Join(j.dir(), "cancel")
+}
+
+// isCanceled tells if the job was canceled by the user.
+func (j *jobInfo) isCanceled() bool {
+	_, e := os.Stat(j.cancelFile())
+	return e == nil
+}
+
+func (j *jobInfo) save() *probe.Error {
+	data, e := json.MarshalIndent(j, "", " ")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.MkdirAll(j.dir(), 0o700); e != nil {
+		return probe.NewError(e)
+	}
+	file := filepath.Join(j.dir(), "job.json")
+	if e = ioutil.WriteFile(file+".tmp", data, 0o600); e != nil {
+		return probe.NewError(e)
+	}
+	return probe.NewError(os.Rename(file+".tmp", file))
+}
+
+func loadJob(id string) (*jobInfo, *probe.Error) {
+	data, e := ioutil.ReadFile(filepath.Join(getJobsDir(), id, "job.json"))
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	j := &jobInfo{}
+	if e = json.Unmarshal(data, j); e != nil {
+		return nil, probe.NewError(e).Trace(id)
+	}
+	return j, nil
+}
+
+// listJobs returns all jobs, oldest first.
+func listJobs() ([]*jobInfo, *probe.Error) {
+	entries, e := ioutil.ReadDir(getJobsDir())
+	if e != nil {
+		if os.IsNotExist(e) {
+			return nil, nil
+		}
+		return nil, probe.NewError(e)
+	}
+	var jobs []*jobInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		j, err := loadJob(entry.Name())
+		if err != nil {
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		return jobs[i].StartedAt.Before(jobs[k].StartedAt)
+	})
+	return jobs, nil
+}
+
+// findJob returns the job with the given ID, or the most recent job with
+// the given name.
+func findJob(idOrName string) (*jobInfo, *probe.Error) {
+	jobs, err := listJobs()
+	if err != nil {
+		return nil, err
+	}
+	var found *jobInfo
+	for _, j := range jobs {
+		if j.ID == idOrName {
+			return j, nil
+		}
+		if j.Name == idOrName {
+			found = j
+		}
+	}
+	if found == nil {
+		return nil, probe.NewError(errors.New("no job found with ID or name `" + idOrName + "`"))
+	}
+	return found, nil
+}
+
+// state returns the status of the job, telling apart the jobs whose
+// supervisor disappeared without recording their end, e.g. on reboot.
+func (j *jobInfo) state() string {
+	if j.Status == jobRunning && j.SupervisorPID != 0 && !processAlive(j.SupervisorPID) {
+		return jobInterrupted
+	}
+	return j.Status
+}
+
+// lastJobEvent returns the last JSON record printed by the command.
+func lastJobEvent(logFile string) json.RawMessage {
+	f, e := os.Open(logFile)
+	if e != nil {
+		return nil
+	}
+	defer f.Close()
+
+	// The last records are enough, skip the beginning of large logs.
+	const tailSize = 64 << 10
+	if st, e := f.Stat(); e == nil && st.Size() > tailSize {
+		f.Seek(st.Size()-tailSize, io.SeekStart)
+	}
+	var last json.RawMessage
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64<<10), tailSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if strings.HasPrefix(string(line), "{") && json.Valid(line) {
+			last = append(json.RawMessage{}, line...)
+		}
+	}
+	return last
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestFindJob(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	jobs := []*jobInfo{
+		{Version: jobFormatVersion, ID: "aaaaaaaa", Name: "nightly", Args: []string{"mirror", "a", "b"}, Status: jobCompleted, StartedAt: start},
+		{Version: jobFormatVersion, ID: "bbbbbbbb", Name: "nightly", Args: []string{"mirror", "a", "b"}, Status: jobFailed, StartedAt: start.Add(time.Hour)},
+		{Version: jobFormatVersion, ID: "cccccccc", Args: []string{"cp", "a", "b"}, Status: jobRunning, StartedAt: start.Add(-time.Hour)},
+	}
+	for _, j := range jobs {
+		if err := j.save(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listed, err := listJobs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 3 || listed[0].ID != "cccccccc" || listed[2].ID != "bbbbbbbb" {
+		t.Errorf("Expected the jobs sorted by start time, got %v", listed)
+	}
+
+	testCases := []struct {
+		idOrName string
+		id       string
+	}{
+		{"aaaaaaaa", "aaaaaaaa"},
+		{"cccccccc", "cccccccc"},
+		// The most recent job with the name.
+		{"nightly", "bbbbbbbb"},
+		{"weekly", ""},
+	}
+	for i, testCase := range testCases {
+		j, err := findJob(testCase.idOrName)
+		if testCase.id == "" {
+			if err == nil {
+				t.Errorf("Test %d: expected no job, got %s", i+1, j.ID)
+			}
+			continue
+		}
+		if err != nil || j.ID != testCase.id {
+			t.Errorf("Test %d: expected job %s, got %v (%v)", i+1, testCase.id, j, err)
+		}
+	}
+
+	// A running job without supervisor was interrupted.
+	j := listed[0]
+	if j.state() != jobRunning {
+		t.Errorf("Expected a starting job to be running, got %s", j.state())
+	}
+	j.SupervisorPID = os.Getpid()
+	if j.state() != jobRunning {
+		t.Errorf("Expected a supervised job to be running, got %s", j.state())
+	}
+	j.SupervisorPID = 1 << 30
+	if j.state() != jobInterrupted {
+		t.Errorf("Expected a job without supervisor to be interrupted, got %s", j.state())
+	}
+}
+
+func TestLastJobEvent(t *testing.T) {
+	logFile := t.TempDir() + "/output.log"
+	output := `{"status":"success","event":"start"}
+mc: <ERROR> not JSON
+{"status":"success","event":"summary","objects":2}
+`
+	if e := ioutil.WriteFile(logFile, []byte(output), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if event := string(lastJobEvent(logFile)); event != `{"status":"success","event":"summary","objects":2}` {
+		t.Errorf("Unexpected last event %s", event)
+	}
+	if event := lastJobEvent(logFile + ".missing"); event != nil {
+		t.Errorf("Expected no event without output, got %s", event)
+	}
+}
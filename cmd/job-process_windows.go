@@ -0,0 +1,63 @@
+//go:build windows
+// +build windows
+
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+const (
+	windowsDetachedProcess = 0x00000008
+	windowsStillActive     = 259
+)
+
+// detachProcess runs the command without console, to survive the
+// terminal it was started from.
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | windowsDetachedProcess,
+	}
+}
+
+// processAlive tells if a process with the given ID is running.
+func processAlive(pid int) bool {
+	h, e := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if e != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if e = syscall.GetExitCodeProcess(h, &code); e != nil {
+		return false
+	}
+	return code == windowsStillActive
+}
+
+// terminateProcess stops a process, Windows has no signal to ask it to.
+func terminateProcess(pid int) error {
+	p, e := os.FindProcess(pid)
+	if e != nil {
+		return e
+	}
+	return p.Kill()
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var jobStartCmd = cli.Command{
+	Name:           "start",
+	Usage:          "start a cp, mv or mirror command as a background job",
+	Action:         mainJobStart,
+	OnUsageError:   onUsageError,
+	Before:         setGlobalsFromContext,
+	SkipArgReorder: true,
+	Flags: append([]cli.Flag{
+		cli.StringFlag{
+			Name:  "name",
+			Usage: "name of the job, usable instead of its ID",
+		},
+	}, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] COMMAND [COMMAND FLAGS] SOURCE TARGET
+
+  The job keeps running when the terminal is closed. Its output is recorded
+  in JSON and can be followed with 'mc job attach'. Jobs are experimental and
+  must be enabled first with 'mc config features enable background-jobs'.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Mirror a bucket in the background as the job 'migration'.
+     {{.Prompt}} {{.HelpName}} --name migration mirror --overwrite s3/photos play/photos
+
+  2. Copy a folder in the background, with a job ID to follow it.
+     {{.Prompt}} {{.HelpName}} cp --recursive ~/backups/ play/backups/
+`,
+}
+
+// jobRunCmd supervises the command of a job, it is started by "job start".
+var jobRunCmd = cli.Command{
+	Name:   "run",
+	Usage:  "run the command of a job",
+	Action: mainJobRun,
+	Before: setGlobalsFromContext,
+	Flags:  globalFlags,
+	Hidden: true,
+}
+
+// jobStartMessage is printed when a job is started.
+type jobStartMessage struct {
+	Status string `json:"status"`
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Log    string `json:"log"`
+}
+
+func (j jobStartMessage) String() string {
+	return console.Colorize("JobStart", fmt.Sprintf("Started job `%s`, follow it with `mc job attach %s`.", j.ID, j.ID))
+}
+
+func (j jobStartMessage) JSON() string {
+	j.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(j, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// jobCommand returns the command running the arguments of a job with the
+// configuration of this command.
+func jobCommand(args ...string) *exec.Cmd {
+	exe, e := os.Executable()
+	fatalIf(probe.NewError(e), "Unable to find the mc executable.")
+	return exec.Command(exe, append([]string{"--config-dir", mustGetMcConfigDir()}, args...)...)
+}
+
+func mainJobStart(cliCtx *cli.Context) error {
+	args := cliCtx.Args()
+	if len(args) < 2 {
+		cli.ShowCommandHelpAndExit(cliCtx, "start", 1) // last argument is exit code
+	}
+	if !jobCommands[args[0]] {
+		fatalIf(errInvalidArgument().Trace(args[0]), "Only cp, mv and mirror can run as jobs.")
+	}
+	checkFeature("background-jobs")
+	console.SetColor("JobStart", color.New(color.FgGreen, color.Bold))
+
+	name := cliCtx.String("name")
+	if name != "" {
+		if j, err := findJob(name); err == nil && j.state() == jobRunning {
+			fatalIf(errInvalidArgument().Trace(name), "The job `"+j.ID+"` named `"+name+"` is still running.")
+		}
+	}
+
+	j := &jobInfo{
+		Version:   jobFormatVersion,
+		ID:        strings.ToLower(newRandomID(8)),
+		Name:      name,
+		Args:      args,
+		Status:    jobRunning,
+		StartedAt: UTCNow(),
+	}
+	fatalIf(j.save(), "Unable to save the job.")
+
+	logFile, e := os.OpenFile(j.logFile(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	fatalIf(probe.NewError(e), "Unable to create the job output.")
+	defer logFile.Close()
+
+	cmd := jobCommand("job", "run", j.ID)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detachProcess(cmd)
+	if e = cmd.Start(); e != nil {
+		j.Status = jobFailed
+		j.ExitCode = globalErrorExitStatus
+		errorIf(j.save(), "Unable to save the job.")
+		fatalIf(probe.NewError(e), "Unable to start the job.")
+	}
+	cmd.Process.Release()
+
+	printMsg(jobStartMessage{ID: j.ID, Name: j.Name, Log: j.logFile()})
+	return nil
+}
+
+func mainJobRun(cliCtx *cli.Context) error {
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, "run", 1) // last argument is exit code
+	}
+	j, err := loadJob(cliCtx.Args().Get(0))
+	fatalIf(err, "Unable to load the job.")
+
+	cmd := jobCommand(append([]string{"--json"}, j.Args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	var e error
+	if !j.isCanceled() {
+		if e = cmd.Start(); e == nil {
+			j.SupervisorPID = os.Getpid()
+			j.PID = cmd.Process.Pid
+			errorIf(j.save(), "Unable to save the job.")
+			e = cmd.Wait()
+		}
+	}
+
+	j.Status = jobCompleted
+	var exitErr *exec.ExitError
+	switch {
+	case errors.As(e, &exitErr):
+		j.Status = jobFailed
+		j.ExitCode = exitErr.ExitCode()
+	case e != nil:
+		errorIf(probe.NewError(e), "Unable to run the job.")
+		j.Status = jobFailed
+		j.ExitCode = globalErrorExitStatus
+	}
+	if j.isCanceled() {
+		j.Status = jobCanceled
+	}
+	finishedAt := time.Now().UTC()
+	j.FinishedAt = &finishedAt
+	fatalIf(j.save(), "Unable to save the job.")
+	return nil
+}
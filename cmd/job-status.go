@@ -0,0 +1,122 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var jobStatusCmd = cli.Command{
+	Name:         "status",
+	Usage:        "show the status and the last event of a job",
+	Action:       mainJobStatus,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        globalFlags,
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} JOB
+
+  JOB is the ID or the name of the job.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Show the progress of the job 'migration'.
+     {{.Prompt}} {{.HelpName}} migration
+`,
+}
+
+// jobStatusMessage container for the status of a job.
+type jobStatusMessage struct {
+	Status     string          `json:"status"`
+	ID         string          `json:"id"`
+	Name       string          `json:"name,omitempty"`
+	State      string          `json:"state"`
+	Command    string          `json:"command"`
+	PID        int             `json:"pid,omitempty"`
+	ExitCode   int             `json:"exitCode"`
+	StartedAt  time.Time       `json:"startedAt"`
+	FinishedAt *time.Time      `json:"finishedAt,omitempty"`
+	Log        string          `json:"log"`
+	LastEvent  json.RawMessage `json:"lastEvent,omitempty"`
+}
+
+func (j jobStatusMessage) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "ID      : %s\n", console.Colorize("JobID", j.ID))
+	if j.Name != "" {
+		fmt.Fprintf(&b, "Name    : %s\n", j.Name)
+	}
+	fmt.Fprintf(&b, "State   : %s\n", console.Colorize(jobStateColor(j.State), j.State))
+	fmt.Fprintf(&b, "Command : mc %s\n", j.Command)
+	fmt.Fprintf(&b, "Started : %s\n", j.StartedAt.Local().Format(printDate))
+	if j.FinishedAt != nil {
+		fmt.Fprintf(&b, "Finished: %s (exit code %d)\n", j.FinishedAt.Local().Format(printDate), j.ExitCode)
+	}
+	fmt.Fprintf(&b, "Log     : %s", j.Log)
+	if len(j.LastEvent) > 0 {
+		fmt.Fprintf(&b, "\nLast    : %s", string(j.LastEvent))
+	}
+	return b.String()
+}
+
+func (j jobStatusMessage) JSON() string {
+	j.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(j, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// checkJobSyntax validates the arguments of the subcommands taking a job.
+func checkJobSyntax(cliCtx *cli.Context, name string) *jobInfo {
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, name, 1) // last argument is exit code
+	}
+	j, err := findJob(cliCtx.Args().Get(0))
+	fatalIf(err, "Unable to find the job.")
+	return j
+}
+
+func mainJobStatus(cliCtx *cli.Context) error {
+	setJobColors()
+	j := checkJobSyntax(cliCtx, "status")
+	printMsg(jobStatusMessage{
+		ID:         j.ID,
+		Name:       j.Name,
+		State:      j.state(),
+		Command:    strings.Join(j.Args, " "),
+		PID:        j.PID,
+		ExitCode:   j.ExitCode,
+		StartedAt:  j.StartedAt,
+		FinishedAt: j.FinishedAt,
+		Log:        j.logFile(),
+		LastEvent:  lastJobEvent(j.logFile()),
+	})
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// aliasKESConfig is the KES server encrypting the secret key of an alias.
+type aliasKESConfig struct {
+	Endpoint   string `json:"endpoint"`
+	Key        string `json:"key"`
+	ClientCert string `json:"clientCert"`
+	ClientKey  string `json:"clientKey"`
+}
+
+// kesSecretKeys caches the secret keys decrypted by KES, by context
+// and ciphertext.
+var kesSecretKeys = struct {
+	sync.Mutex
+	plaintext map[string]string
+}{plaintext: make(map[string]string)}
+
+// kesContext binds a ciphertext to the alias credentials, preventing
+// it from being used as the secret key of another alias.
+func kesContext(aliasCfg *aliasConfigV10) []byte {
+	return []byte(aliasCfg.URL + "\x00" + aliasCfg.AccessKey)
+}
+
+// kesCall sends a request to a KES key API and decodes its response.
+func kesCall(ctx context.Context, cfg *aliasKESConfig, api string, request, response interface{}) *probe.Error {
+	cert, e := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+	if e != nil {
+		return probe.NewError(e).Trace(cfg.ClientCert, cfg.ClientKey)
+	}
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				Certificates:       []tls.Certificate{cert},
+				RootCAs:            globalRootCAs,
+				InsecureSkipVerify: globalInsecure,
+				MinVersion:         tls.VersionTLS12,
+			},
+		},
+	}
+
+	body, e := json.Marshal(request)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/") + "/v1/key/" + api + "/" + url.PathEscape(cfg.Key)
+	req, e := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if e != nil {
+		return probe.NewError(e)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, e := client.Do(req)
+	if e != nil {
+		return probe.NewError(e).Trace(endpoint)
+	}
+	defer resp.Body.Close()
+
+	respBody, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return probe.NewError(e).Trace(endpoint)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var kesErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &kesErr) != nil || kesErr.Message == "" {
+			kesErr.Message = strings.TrimSpace(string(respBody))
+		}
+		return probe.NewError(fmt.Errorf("KES %s: %s", resp.Status, kesErr.Message)).Trace(endpoint)
+	}
+	if e = json.Unmarshal(respBody, response); e != nil {
+		return probe.NewError(e).Trace(endpoint)
+	}
+	return nil
+}
+
+// kesEncryptSecretKey encrypts the secret key of the alias with KES and
+// returns the base64 encoded ciphertext to store instead.
+func kesEncryptSecretKey(ctx context.Context, aliasCfg *aliasConfigV10) (string, *probe.Error) {
+	request := struct {
+		Plaintext []byte `json:"plaintext"`
+		Context   []byte `json:"context"`
+	}{[]byte(aliasCfg.SecretKey), kesContext(aliasCfg)}
+	var response struct {
+		Ciphertext []byte `json:"ciphertext"`
+	}
+	if err := kesCall(ctx, aliasCfg.KES, "encrypt", request, &response); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(response.Ciphertext), nil
+}
+
+// kesDecryptSecretKey replaces the encrypted secret key of the alias by
+// its plaintext, decrypted by KES once per process.
+func kesDecryptSecretKey(ctx context.Context, aliasCfg *aliasConfigV10) *probe.Error {
+	kesSecretKeys.Lock()
+	defer kesSecretKeys.Unlock()
+
+	cacheKey := string(kesContext(aliasCfg)) + "\x00" + aliasCfg.SecretKey
+	if plaintext, ok := kesSecretKeys.plaintext[cacheKey]; ok {
+		aliasCfg.SecretKey = plaintext
+		return nil
+	}
+
+	ciphertext, e := base64.StdEncoding.DecodeString(aliasCfg.SecretKey)
+	if e != nil {
+		return probe.NewError(errors.New("secret key is not encrypted by KES"))
+	}
+	request := struct {
+		Ciphertext []byte `json:"ciphertext"`
+		Context    []byte `json:"context"`
+	}{ciphertext, kesContext(aliasCfg)}
+	var response struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := kesCall(ctx, aliasCfg.KES, "decrypt", request, &response); err != nil {
+		return err
+	}
+	kesSecretKeys.plaintext[cacheKey] = string(response.Plaintext)
+	aliasCfg.SecretKey = string(response.Plaintext)
+	return nil
+}
+
+// kesAbsPath returns the absolute path of a client certificate or key,
+// the alias being used from any working directory.
+func kesAbsPath(path string) string {
+	if absPath, e := filepath.Abs(path); e == nil {
+		return absPath
+	}
+	return path
+}
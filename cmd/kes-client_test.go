@@ -0,0 +1,223 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"flag"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/cli"
+)
+
+// newKESTestServer starts a KES stub sealing the plaintext with its
+// context, the key "mc-config" being the only one known.
+func newKESTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Plaintext  []byte `json:"plaintext"`
+			Ciphertext []byte `json:"ciphertext"`
+			Context    []byte `json:"context"`
+		}
+		if e := json.NewDecoder(r.Body).Decode(&request); e != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/key/encrypt/mc-config":
+			ciphertext := append(append([]byte("sealed:"), request.Context...), append([]byte{0xff}, request.Plaintext...)...)
+			json.NewEncoder(w).Encode(map[string][]byte{"ciphertext": ciphertext})
+		case "/v1/key/decrypt/mc-config":
+			sealed := append(append([]byte("sealed:"), request.Context...), 0xff)
+			if !bytes.HasPrefix(request.Ciphertext, sealed) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"message":"ciphertext is not authentic"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string][]byte{"plaintext": request.Ciphertext[len(sealed):]})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"key does not exist"}`))
+		}
+	}))
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	prevRootCAs := globalRootCAs
+	globalRootCAs = rootCAs
+	t.Cleanup(func() {
+		globalRootCAs = prevRootCAs
+		server.Close()
+	})
+	return server
+}
+
+// writeKESClientCert writes a self-signed client certificate and its
+// private key, returning their paths.
+func writeKESClientCert(t *testing.T) (string, string) {
+	key, e := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if e != nil {
+		t.Fatal(e)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mc"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, e := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if e != nil {
+		t.Fatal(e)
+	}
+	keyDER, e := x509.MarshalECPrivateKey(key)
+	if e != nil {
+		t.Fatal(e)
+	}
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if e = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if e = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	return certFile, keyFile
+}
+
+func TestKESSecretKey(t *testing.T) {
+	server := newKESTestServer(t)
+	certFile, keyFile := writeKESClientCert(t)
+	kes := &aliasKESConfig{Endpoint: server.URL, Key: "mc-config", ClientCert: certFile, ClientKey: keyFile}
+
+	aliasCfg := &aliasConfigV10{URL: "https://site-a:9000", AccessKey: "minio", SecretKey: "minio123", KES: kes}
+	ciphertext, err := kesEncryptSecretKey(context.Background(), aliasCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ciphertext == "" || strings.Contains(ciphertext, "minio123") {
+		t.Fatalf("expected a base64 encoded ciphertext, got %q", ciphertext)
+	}
+
+	// The ciphertext is bound to the alias URL and access key.
+	testCases := []struct {
+		aliasCfg  aliasConfigV10
+		plaintext string
+		expectErr bool
+	}{
+		{aliasConfigV10{URL: "https://site-a:9000", AccessKey: "minio", SecretKey: ciphertext}, "minio123", false},
+		// Cached plaintexts are bound as well.
+		{aliasConfigV10{URL: "https://site-a:9000", AccessKey: "minio", SecretKey: ciphertext}, "minio123", false},
+		{aliasConfigV10{URL: "https://site-b:9000", AccessKey: "minio", SecretKey: ciphertext}, "", true},
+		{aliasConfigV10{URL: "https://site-a:9000", AccessKey: "admin", SecretKey: ciphertext}, "", true},
+		{aliasConfigV10{URL: "https://site-a:9000", AccessKey: "minio", SecretKey: "minio123"}, "", true},
+		{aliasConfigV10{URL: "https://site-a:9000", AccessKey: "minio", SecretKey: "not base64!"}, "", true},
+	}
+	for i, testCase := range testCases {
+		aliasCfg := testCase.aliasCfg
+		aliasCfg.KES = kes
+		err := kesDecryptSecretKey(context.Background(), &aliasCfg)
+		if testCase.expectErr != (err != nil) {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.expectErr, err)
+		}
+		if err == nil && aliasCfg.SecretKey != testCase.plaintext {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.plaintext, aliasCfg.SecretKey)
+		}
+	}
+}
+
+func TestKESCallErrors(t *testing.T) {
+	server := newKESTestServer(t)
+	certFile, keyFile := writeKESClientCert(t)
+
+	var response struct{}
+	err := kesCall(context.Background(), &aliasKESConfig{Endpoint: server.URL, Key: "unknown", ClientCert: certFile, ClientKey: keyFile},
+		"encrypt", struct{}{}, &response)
+	if err == nil || !strings.Contains(err.ToGoError().Error(), "key does not exist") {
+		t.Fatalf("expected the KES error message, got %v", err)
+	}
+
+	err = kesCall(context.Background(), &aliasKESConfig{Endpoint: server.URL, Key: "mc-config", ClientCert: certFile, ClientKey: filepath.Join(t.TempDir(), "missing.key")},
+		"encrypt", struct{}{}, &response)
+	if err == nil {
+		t.Fatal("expected an error without the client private key")
+	}
+}
+
+func TestAliasSetEncryptWithKES(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	saveMcConfig(newMcConfig())
+	loadMcConfig = loadMcConfigFactory()
+
+	server := newKESTestServer(t)
+	certFile, keyFile := writeKESClientCert(t)
+
+	set := flag.NewFlagSet("set", flag.ContinueOnError)
+	for _, f := range aliasSetFlags {
+		f.Apply(set)
+	}
+	if e := set.Parse([]string{"--encrypt-with-kes", server.URL + "/", "--key", "mc-config",
+		"--kes-client-cert", certFile, "--kes-client-key", keyFile}); e != nil {
+		t.Fatal(e)
+	}
+	aliasCfg := aliasConfigV10{URL: "https://site-a:9000", AccessKey: "minio", SecretKey: "minio123", API: "S3v4", Path: "auto"}
+	if err := encryptAliasSecretKey(context.Background(), cli.NewContext(nil, set, nil), &aliasCfg); err != nil {
+		t.Fatal(err)
+	}
+	if aliasCfg.KES == nil || aliasCfg.KES.Endpoint != server.URL || aliasCfg.SecretKey == "minio123" {
+		t.Fatalf("expected the secret key encrypted by %s, got %+v", server.URL, aliasCfg)
+	}
+	setAlias("kes-test", aliasCfg)
+
+	loadMcConfig = loadMcConfigFactory()
+	if hostCfg := mustGetHostConfig("kes-test"); hostCfg == nil || hostCfg.SecretKey != "minio123" {
+		t.Fatalf("expected the decrypted secret key, got %+v", hostCfg)
+	}
+
+	// The plaintext is never written back to the config.
+	data, e := ioutil.ReadFile(mustGetMcConfigPath())
+	if e != nil {
+		t.Fatal(e)
+	}
+	if bytes.Contains(data, []byte("minio123")) {
+		t.Fatalf("expected only the ciphertext in the config, got %s", data)
+	}
+	mcCfg, err := loadMcConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secretKey := mcCfg.Aliases["kes-test"].SecretKey; secretKey != aliasCfg.SecretKey {
+		t.Fatalf("expected the loaded config to keep the ciphertext, got %q", secretKey)
+	}
+}
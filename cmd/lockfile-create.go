@@ -0,0 +1,248 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// lockfileWorkers is the number of objects read in parallel to compute
+// their checksums.
+const lockfileWorkers = 8
+
+var lockfileCreateFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "output, o",
+		Value: "mc.lock",
+		Usage: "file to write the lockfile to, '-' writes it to stdout",
+	},
+}
+
+var lockfileCreateCmd = cli.Command{
+	Name:         "create",
+	Usage:        "record the versions and checksums of all objects under a prefix",
+	Action:       mainLockfileCreate,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(append(lockfileCreateFlags, ioFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] SOURCE
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  A lockfile pins the latest version of every object under SOURCE along with
+  its SHA-256 checksum, computed by reading the object. 'mc cp --lockfile'
+  downloads exactly these versions and verifies their checksums, however the
+  bucket changed since. Objects of buckets without versioning are verified but
+  cannot be pinned.
+
+EXAMPLES:
+  1. Pin the build artifacts under the prefix 'v1.2.0/' of bucket 'artifacts' into 'mc.lock'.
+     {{.Prompt}} {{.HelpName}} myminio/artifacts/v1.2.0/
+
+  2. Pin the objects of bucket 'datasets' into 'datasets.lock'.
+     {{.Prompt}} {{.HelpName}} myminio/datasets -o datasets.lock
+`,
+}
+
+// lockfileCreateMessage container for a created lockfile.
+type lockfileCreateMessage struct {
+	Status  string `json:"status"`
+	URL     string `json:"url"`
+	File    string `json:"file"`
+	Objects int    `json:"objects"`
+	Size    int64  `json:"size"`
+}
+
+func (l lockfileCreateMessage) String() string {
+	return console.Colorize("Lockfile", fmt.Sprintf("Pinned %d object(s), %s, of `%s` into `%s`.",
+		l.Objects, humanize.IBytes(uint64(l.Size)), l.URL, l.File))
+}
+
+func (l lockfileCreateMessage) JSON() string {
+	l.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(l, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+func checkLockfileCreateSyntax(cliCtx *cli.Context) {
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, "create", 1) // last argument is exit code
+	}
+	_, _, hostCfg, err := expandAlias(cliCtx.Args().Get(0))
+	fatalIf(err.Trace(cliCtx.Args()...), "Unable to expand alias.")
+	if hostCfg == nil {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "Lockfiles can only pin objects of an object storage.")
+	}
+	if cliCtx.String("output") == "" {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "Please specify the lockfile with --output.")
+	}
+}
+
+// listLockfileEntries lists the latest version of every object under urlStr.
+func listLockfileEntries(ctx context.Context, urlStr string) ([]lockfileEntry, *probe.Error) {
+	clnt, err := newClient(urlStr)
+	if err != nil {
+		return nil, err.Trace(urlStr)
+	}
+	separator := string(clnt.GetURL().Separator)
+	basePath := clnt.GetURL().Path
+
+	var entries []lockfileEntry
+	for content := range clnt.List(ctx, ListOptions{
+		Recursive:         true,
+		WithOlderVersions: true,
+		WithDeleteMarkers: true,
+		ShowDir:           DirNone,
+	}) {
+		if content.Err != nil {
+			return nil, content.Err.Trace(urlStr)
+		}
+		// Deleted objects and older versions are not downloaded.
+		if content.Type.IsDir() || content.IsDeleteMarker || !content.IsLatest {
+			continue
+		}
+		key := strings.TrimPrefix(strings.TrimPrefix(content.URL.Path, basePath), separator)
+		entries = append(entries, lockfileEntry{
+			Key:          key,
+			VersionID:    content.VersionID,
+			Size:         content.Size,
+			ETag:         content.ETag,
+			LastModified: content.Time.UTC(),
+		})
+	}
+	return entries, nil
+}
+
+// hashLockfileEntries reads the pinned version of every entry to record
+// its SHA-256 checksum.
+func hashLockfileEntries(ctx context.Context, urlStr string, entries []lockfileEntry, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr *probe.Error
+	)
+	indexes := make(chan int)
+	for i := 0; i < lockfileWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sum, err := hashLockfileEntry(ctx, urlJoinPath(urlStr, entries[i].Key), entries[i].VersionID, encKeyDB)
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					continue
+				}
+				entries[i].SHA256 = sum
+			}
+		}()
+	}
+	for i := range entries {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(indexes)
+	wg.Wait()
+	return firstErr
+}
+
+func hashLockfileEntry(ctx context.Context, urlStr, versionID string, encKeyDB map[string][]prefixSSEPair) (string, *probe.Error) {
+	reader, err := getSourceStreamFromURL(ctx, urlStr, versionID, encKeyDB)
+	if err != nil {
+		return "", err.Trace(urlStr)
+	}
+	defer reader.Close()
+	h := sha256.New()
+	if _, e := io.Copy(h, reader); e != nil {
+		return "", probe.NewError(e).Trace(urlStr)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func mainLockfileCreate(cliCtx *cli.Context) error {
+	ctx, cancelLockfileCreate := context.WithCancel(globalContext)
+	defer cancelLockfileCreate()
+
+	console.SetColor("Lockfile", color.New(color.FgGreen, color.Bold))
+
+	checkLockfileCreateSyntax(cliCtx)
+	// Keys of the lockfile are relative to the prefix as a folder.
+	urlStr := strings.TrimSuffix(cliCtx.Args().Get(0), "/") + "/"
+	filename := cliCtx.String("output")
+
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	entries, err := listLockfileEntries(ctx, urlStr)
+	fatalIf(err, "Unable to list the objects of `"+urlStr+"`.")
+	if len(entries) == 0 {
+		fatalIf(errInvalidArgument().Trace(urlStr), "No objects found under `"+urlStr+"`.")
+	}
+	fatalIf(hashLockfileEntries(ctx, urlStr, entries, encKeyDB), "Unable to compute the checksums of `"+urlStr+"`.")
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	fatalIf(writeLockfile(filename, lockfile{
+		Version:   lockfileFormatVersion,
+		Source:    urlStr,
+		CreatedAt: time.Now().UTC(),
+		Objects:   entries,
+	}).Trace(filename), "Unable to write the lockfile.")
+
+	if filename != "-" {
+		var size int64
+		for _, entry := range entries {
+			size += entry.Size
+		}
+		printMsg(lockfileCreateMessage{
+			URL:     urlStr,
+			File:    filename,
+			Objects: len(entries),
+			Size:    size,
+		})
+	}
+	return nil
+}
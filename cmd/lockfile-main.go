@@ -0,0 +1,203 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// lockfileFormatVersion is the version of the lockfile format.
+const lockfileFormatVersion = "1"
+
+var lockfileSubcommands = []cli.Command{
+	lockfileCreateCmd,
+}
+
+var lockfileCmd = cli.Command{
+	Name:            "lockfile",
+	Usage:           "pin the exact object versions under a prefix for reproducible downloads",
+	Action:          mainLockfile,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	HideHelpCommand: true,
+	Subcommands:     lockfileSubcommands,
+}
+
+func mainLockfile(ctx *cli.Context) error {
+	commandNotFound(ctx, lockfileSubcommands)
+	return nil
+}
+
+// lockfile pins the objects under a prefix to their versions and content.
+type lockfile struct {
+	Version   string          `json:"version"`
+	Source    string          `json:"source"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Objects   []lockfileEntry `json:"objects"`
+}
+
+// lockfileEntry is an object pinned by a lockfile, its key is relative
+// to the source of the lockfile.
+type lockfileEntry struct {
+	Key          string    `json:"key"`
+	VersionID    string    `json:"versionId,omitempty"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified time.Time `json:"lastModified"`
+	SHA256       string    `json:"sha256"`
+}
+
+// validate rejects lockfiles which would not download exactly the
+// pinned objects under the target.
+func (l lockfile) validate() *probe.Error {
+	if l.Version != lockfileFormatVersion {
+		return errInvalidArgument().Trace("unsupported lockfile version " + l.Version)
+	}
+	if l.Source == "" {
+		return errInvalidArgument().Trace("lockfile has no source")
+	}
+	keys := make(map[string]struct{}, len(l.Objects))
+	for _, entry := range l.Objects {
+		key := entry.Key
+		if key == "" || path.IsAbs(key) || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+			return errInvalidArgument().Trace("invalid key `" + key + "`")
+		}
+		if _, ok := keys[key]; ok {
+			return errInvalidArgument().Trace("duplicate key `" + key + "`")
+		}
+		keys[key] = struct{}{}
+		if sum, e := hex.DecodeString(entry.SHA256); e != nil || len(sum) != 32 {
+			return errInvalidArgument().Trace("invalid SHA-256 checksum of `" + key + "`")
+		}
+	}
+	return nil
+}
+
+// readLockfile reads and validates a lockfile.
+func readLockfile(filename string) (*lockfile, *probe.Error) {
+	data, e := ioutil.ReadFile(filename)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	var l lockfile
+	if e = json.Unmarshal(data, &l); e != nil {
+		return nil, probe.NewError(e).Trace(filename)
+	}
+	if err := l.validate(); err != nil {
+		return nil, err.Trace(filename)
+	}
+	return &l, nil
+}
+
+// writeLockfile writes a lockfile indented so that it can be reviewed
+// and diffed in version control, '-' writes it to stdout.
+func writeLockfile(filename string, l lockfile) *probe.Error {
+	data, e := json.MarshalIndent(l, "", "  ")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	data = append(data, '\n')
+	if filename == "-" {
+		_, e = os.Stdout.Write(data)
+		return probe.NewError(e)
+	}
+	// Do not leave a truncated lockfile behind.
+	tmpFile := filename + ".tmp"
+	if e = ioutil.WriteFile(tmpFile, data, 0o644); e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.Rename(tmpFile, filename); e != nil {
+		os.Remove(tmpFile)
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// prepareCopyURLsFromLockfile prepares the copy of the objects pinned by
+// a lockfile under targetURL. A lockfile is all or nothing, all pinned
+// versions are found before any of them is copied.
+func prepareCopyURLsFromLockfile(ctx context.Context, l *lockfile, targetURL string, encKeyDB map[string][]prefixSSEPair) (chan URLs, *probe.Error) {
+	pinned := make([]URLs, 0, len(l.Objects))
+	for _, entry := range l.Objects {
+		sourceURL := urlJoinPath(l.Source, entry.Key)
+		cpURLs := prepareCopyURLsTypeA(ctx, sourceURL, entry.VersionID, urlJoinPath(targetURL, entry.Key), encKeyDB)
+		if entry.VersionID != "" {
+			sourceURL += " (" + entry.VersionID + ")"
+		}
+		if cpURLs.Error != nil {
+			return nil, cpURLs.Error.Trace(sourceURL)
+		}
+		if cpURLs.SourceContent.Size != entry.Size {
+			return nil, probe.NewError(fmt.Errorf("size of `%s` does not match the lockfile", sourceURL))
+		}
+		cpURLs.SourceContent.VersionID = entry.VersionID
+		cpURLs.SHA256 = entry.SHA256
+		pinned = append(pinned, cpURLs)
+	}
+
+	copyURLsCh := make(chan URLs)
+	go func() {
+		defer close(copyURLsCh)
+		for _, cpURLs := range pinned {
+			select {
+			case copyURLsCh <- cpURLs:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return copyURLsCh, nil
+}
+
+// verifyLockfileChecksum compares the SHA-256 checksum of a downloaded
+// object with the one pinned by the lockfile.
+func verifyLockfileChecksum(urlStr, expected string, actual []byte) *probe.Error {
+	if hex.EncodeToString(actual) != expected {
+		return probe.NewError(fmt.Errorf("sha256 checksum mismatch, locked %s but downloaded %s", expected, hex.EncodeToString(actual))).Trace(urlStr)
+	}
+	return nil
+}
+
+// removeLockfileTarget removes a copy whose checksum does not match the
+// lockfile, not to leave a corrupted object behind.
+func removeLockfileTarget(ctx context.Context, alias, urlStr string) *probe.Error {
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: clnt.GetURL()}
+	close(contentCh)
+	for result := range clnt.Remove(ctx, false, false, false, contentCh) {
+		if result.Err != nil {
+			return result.Err.Trace(urlStr)
+		}
+	}
+	return nil
+}
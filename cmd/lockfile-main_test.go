@@ -0,0 +1,155 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLockfileValidate(t *testing.T) {
+	sum := sha256.Sum256([]byte("hello"))
+	checksum := hex.EncodeToString(sum[:])
+	testCases := []struct {
+		keys    []string
+		sha256  string
+		version string
+		success bool
+	}{
+		{[]string{"a.txt", "dir/b.txt"}, checksum, lockfileFormatVersion, true},
+		{[]string{"a.txt"}, checksum, "2", false},
+		{[]string{"a.txt", "a.txt"}, checksum, lockfileFormatVersion, false},
+		{[]string{""}, checksum, lockfileFormatVersion, false},
+		{[]string{"/etc/passwd"}, checksum, lockfileFormatVersion, false},
+		{[]string{"../escape"}, checksum, lockfileFormatVersion, false},
+		{[]string{"dir/../../escape"}, checksum, lockfileFormatVersion, false},
+		{[]string{"dir/"}, checksum, lockfileFormatVersion, false},
+		{[]string{"a.txt"}, "", lockfileFormatVersion, false},
+		{[]string{"a.txt"}, checksum[:10], lockfileFormatVersion, false},
+	}
+	for i, testCase := range testCases {
+		l := lockfile{Version: testCase.version, Source: "play/bucket/prefix/"}
+		for _, key := range testCase.keys {
+			l.Objects = append(l.Objects, lockfileEntry{Key: key, SHA256: testCase.sha256})
+		}
+		if err := l.validate(); (err == nil) != testCase.success {
+			t.Errorf("Test %d: expected success %v, got %v", i+1, testCase.success, err)
+		}
+	}
+}
+
+func TestLockfileReadWrite(t *testing.T) {
+	sum := sha256.Sum256([]byte("hello"))
+	expected := lockfile{
+		Version:   lockfileFormatVersion,
+		Source:    "play/bucket/prefix/",
+		CreatedAt: time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC),
+		Objects: []lockfileEntry{{
+			Key:          "dir/a.txt",
+			VersionID:    "5d3c7b1e-9a52-4a8e-b5f0-1a2b3c4d5e6f",
+			Size:         5,
+			ETag:         "5d41402abc4b2a76b9719d911017c592",
+			LastModified: time.Date(2021, 11, 30, 8, 0, 0, 0, time.UTC),
+			SHA256:       hex.EncodeToString(sum[:]),
+		}},
+	}
+	filename := filepath.Join(t.TempDir(), "mc.lock")
+	if err := writeLockfile(filename, expected); err != nil {
+		t.Fatal(err)
+	}
+	l, err := readLockfile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*l, expected) {
+		t.Errorf("expected %+v, got %+v", expected, *l)
+	}
+
+	if err = verifyLockfileChecksum("a.txt", expected.Objects[0].SHA256, sum[:]); err != nil {
+		t.Errorf("expected matching checksum, got %v", err)
+	}
+	other := sha256.Sum256([]byte("world"))
+	if err = verifyLockfileChecksum("a.txt", expected.Objects[0].SHA256, other[:]); err == nil {
+		t.Errorf("expected checksum mismatch")
+	}
+}
+
+func TestPrepareCopyURLsFromLockfile(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	source, target := t.TempDir(), t.TempDir()
+	if e := ioutil.WriteFile(filepath.Join(source, "a.txt"), []byte("hello"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	sum := sha256.Sum256([]byte("hello"))
+	testCases := []struct {
+		entry   lockfileEntry
+		success bool
+	}{
+		{lockfileEntry{Key: "a.txt", Size: 5, SHA256: hex.EncodeToString(sum[:])}, true},
+		{lockfileEntry{Key: "a.txt", Size: 6, SHA256: hex.EncodeToString(sum[:])}, false},
+		{lockfileEntry{Key: "missing.txt", Size: 5, SHA256: hex.EncodeToString(sum[:])}, false},
+	}
+	for i, testCase := range testCases {
+		// The pinned objects are all checked before any is copied.
+		l := &lockfile{Version: lockfileFormatVersion, Source: source + "/", Objects: []lockfileEntry{testCases[0].entry, testCase.entry}}
+		urlsCh, err := prepareCopyURLsFromLockfile(context.Background(), l, target, nil)
+		if (err == nil) != testCase.success {
+			t.Fatalf("Test %d: expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if err != nil {
+			continue
+		}
+		var count int
+		for cpURLs := range urlsCh {
+			if cpURLs.SHA256 != testCase.entry.SHA256 {
+				t.Errorf("Test %d: expected the checksum of the lockfile, got %q", i+1, cpURLs.SHA256)
+			}
+			count++
+		}
+		if count != 2 {
+			t.Errorf("Test %d: expected 2 objects to copy, got %d", i+1, count)
+		}
+	}
+}
+
+func TestRemoveLockfileTarget(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	target := filepath.Join(t.TempDir(), "a.txt")
+	if e := ioutil.WriteFile(target, []byte("corrupted"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	if err := removeLockfileTarget(context.Background(), "", target); err != nil {
+		t.Fatal(err)
+	}
+	if _, e := os.Stat(target); !os.IsNotExist(e) {
+		t.Errorf("expected the corrupted copy to be removed, got %v", e)
+	}
+}
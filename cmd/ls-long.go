@@ -0,0 +1,216 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/template"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// Columns of the long listing, in their default order.
+const (
+	lsColumnTime         = "time"
+	lsColumnSize         = "size"
+	lsColumnStorageClass = "storage-class"
+	lsColumnETag         = "etag"
+	lsColumnOwner        = "owner"
+	lsColumnReplication  = "replication"
+	lsColumnVersionID    = "version-id"
+	lsColumnKey          = "key"
+)
+
+var lsColumnNames = []string{
+	lsColumnTime, lsColumnSize, lsColumnStorageClass, lsColumnETag,
+	lsColumnOwner, lsColumnReplication, lsColumnVersionID, lsColumnKey,
+}
+
+// parseListColumns returns the columns of the long listing from the
+// --columns value, all columns but the version ID being the default,
+// which is shown when versions are listed.
+func parseListColumns(value string, withVersions bool) ([]string, *probe.Error) {
+	if value == "" {
+		var columns []string
+		for _, name := range lsColumnNames {
+			if name != lsColumnVersionID || withVersions {
+				columns = append(columns, name)
+			}
+		}
+		return columns, nil
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		valid := false
+		for _, column := range lsColumnNames {
+			if name == column {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, probe.NewError(fmt.Errorf("unknown column `%s`, valid columns are %s", name, strings.Join(lsColumnNames, ", ")))
+		}
+		if !seen[name] {
+			seen[name] = true
+			columns = append(columns, name)
+		}
+	}
+	return columns, nil
+}
+
+// lsFormat is how the listed entries are printed, the default listing
+// if none of its fields are set.
+type lsFormat struct {
+	// Columns of the long listing.
+	columns []string
+	// Template executed for each entry.
+	template *template.Template
+	// Print only the names, NUL terminated.
+	print0 bool
+}
+
+// withReplication returns true if the replication status of the entries
+// is printed, it is only listed with the metadata of the objects.
+func (f lsFormat) withReplication() bool {
+	if f.template != nil {
+		return strings.Contains(f.template.Root.String(), "ReplicationStatus")
+	}
+	for _, column := range f.columns {
+		if column == lsColumnReplication {
+			return true
+		}
+	}
+	return false
+}
+
+// withOwner returns true if the owner of the entries is printed.
+func (f lsFormat) withOwner() bool {
+	if f.template != nil {
+		return true
+	}
+	for _, column := range f.columns {
+		if column == lsColumnOwner {
+			return true
+		}
+	}
+	return false
+}
+
+// print prints a listed entry.
+func (f lsFormat) print(msg contentMessage) {
+	if f.print0 {
+		printNulTerminated(msg.Key)
+		return
+	}
+	msg.columns = f.columns
+	msg.template = f.template
+	printMsg(msg)
+}
+
+// parseListTemplate parses the --format template, executed with the
+// fields of the JSON listing, e.g. '{{.Key}} {{.Size}}'.
+func parseListTemplate(format string) (*template.Template, *probe.Error) {
+	tmpl, e := template.New("ls").Option("missingkey=error").Parse(format)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	// Unknown fields are only reported once executed.
+	if e = tmpl.Execute(ioutil.Discard, contentMessage{}); e != nil {
+		return nil, probe.NewError(e)
+	}
+	return tmpl, nil
+}
+
+// templateString returns the entry formatted by the --format template.
+func (c contentMessage) templateString() string {
+	var buf bytes.Buffer
+	if e := c.template.Execute(&buf, c); e != nil {
+		return e.Error()
+	}
+	return buf.String()
+}
+
+// longString returns the long listing line of a content message.
+func (c contentMessage) longString() string {
+	orNone := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+
+	var fields []string
+	for _, column := range c.columns {
+		switch column {
+		case lsColumnTime:
+			fields = append(fields, console.Colorize("Time", fmt.Sprintf("[%s]", c.Time.Format(printDate))))
+		case lsColumnSize:
+			fields = append(fields, console.Colorize("Size", fmt.Sprintf("%7s", strings.Join(strings.Fields(humanize.IBytes(uint64(c.Size))), ""))))
+		case lsColumnStorageClass:
+			fields = append(fields, fmt.Sprintf("%-12s", orNone(c.StorageClass)))
+		case lsColumnETag:
+			fields = append(fields, console.Colorize("ETag", fmt.Sprintf("%-32s", orNone(c.ETag))))
+		case lsColumnOwner:
+			fields = append(fields, console.Colorize("Owner", fmt.Sprintf("%-12s", orNone(c.Owner))))
+		case lsColumnReplication:
+			fields = append(fields, fmt.Sprintf("%-9s", orNone(c.ReplicationStatus)))
+		case lsColumnVersionID:
+			if c.VersionID == "" {
+				fields = append(fields, fmt.Sprintf("%-36s", "-"))
+				break
+			}
+			version := console.Colorize("VersionID", fmt.Sprintf("%-36s", c.VersionID)) + console.Colorize("VersionOrd", fmt.Sprintf(" v%d", c.VersionOrd))
+			if c.IsDeleteMarker {
+				version += console.Colorize("DEL", " DEL")
+			} else {
+				version += console.Colorize("PUT", " PUT")
+			}
+			fields = append(fields, version)
+		case lsColumnKey:
+			if c.Filetype == "folder" {
+				fields = append(fields, console.Colorize("Dir", c.Key))
+			} else {
+				fields = append(fields, console.Colorize("File", c.Key))
+			}
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// setListOwners fills the owner of local files, the owner of objects
+// being returned by the listing itself.
+func setListOwners(ctnts []*ClientContent) {
+	for _, c := range ctnts {
+		if c.Owner != "" || c.URL.Type != fileSystem {
+			continue
+		}
+		if fi, e := os.Lstat(c.URL.Path); e == nil {
+			c.Owner = fileOwner(fi)
+		}
+	}
+}
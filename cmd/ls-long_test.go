@@ -0,0 +1,144 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestParseListColumns(t *testing.T) {
+	testCases := []struct {
+		value        string
+		withVersions bool
+		expected     []string
+		success      bool
+	}{
+		{"", false, []string{"time", "size", "storage-class", "etag", "owner", "replication", "key"}, true},
+		{"", true, []string{"time", "size", "storage-class", "etag", "owner", "replication", "version-id", "key"}, true},
+		{"key,size", false, []string{"key", "size"}, true},
+		{" ETag , key,etag", false, []string{"etag", "key"}, true},
+		{"size,foo", false, nil, false},
+		{",", false, nil, false},
+	}
+
+	for i, testCase := range testCases {
+		columns, err := parseListColumns(testCase.value, testCase.withVersions)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: expected success %v, got error %v", i+1, testCase.success, err)
+		}
+		if !reflect.DeepEqual(columns, testCase.expected) {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, testCase.expected, columns)
+		}
+	}
+}
+
+func TestContentMessageLongString(t *testing.T) {
+	c := contentMessage{
+		Filetype:          "file",
+		Time:              time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC),
+		Size:              2048,
+		Key:               "photos/a.jpg",
+		ETag:              "d41d8cd98f00b204e9800998ecf8427e",
+		StorageClass:      "STANDARD",
+		ReplicationStatus: "COMPLETED",
+		VersionID:         "v1",
+		VersionOrd:        2,
+	}
+
+	testCases := []struct {
+		columns  []string
+		expected []string
+	}{
+		{[]string{"size", "owner", "key"}, []string{"2.0KiB", "-", "photos/a.jpg"}},
+		{[]string{"storage-class", "replication", "etag"}, []string{"STANDARD", "COMPLETED", "d41d8cd98f00b204e9800998ecf8427e"}},
+		{[]string{"version-id", "key"}, []string{"v1", "v2", "PUT", "photos/a.jpg"}},
+	}
+
+	for i, testCase := range testCases {
+		c.columns = testCase.columns
+		if fields := strings.Fields(c.String()); !reflect.DeepEqual(fields, testCase.expected) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, fields)
+		}
+	}
+}
+
+func TestContentMessageTemplateString(t *testing.T) {
+	msg := contentMessage{
+		Key:          "photos/2021/cat.jpg",
+		Size:         1024,
+		ETag:         "5d41402abc4b2a76b9719d911017c592",
+		StorageClass: "STANDARD",
+		Time:         time.Date(2021, 11, 30, 8, 0, 0, 0, time.UTC),
+	}
+	testCases := []struct {
+		format   string
+		expected string
+		success  bool
+	}{
+		{"{{.Key}},{{.Size}},{{.ETag}},{{.StorageClass}}", "photos/2021/cat.jpg,1024,5d41402abc4b2a76b9719d911017c592,STANDARD", true},
+		{"{{.Time.Unix}} {{if .IsDeleteMarker}}DEL{{else}}PUT{{end}}", "1638259200 PUT", true},
+		{"{{.Key", "", false},
+		{"{{.Name}}", "", false},
+	}
+	for i, testCase := range testCases {
+		tmpl, err := parseListTemplate(testCase.format)
+		if (err == nil) != testCase.success {
+			t.Fatalf("Test %d: expected success %v, got %v", i+1, testCase.success, err)
+		}
+		if err != nil {
+			continue
+		}
+		msg.template = tmpl
+		if got := msg.String(); got != testCase.expected {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestListFormatWithReplication(t *testing.T) {
+	testCases := []struct {
+		columns  string
+		format   string
+		expected bool
+	}{
+		{"time,size,key", "", false},
+		{"size,replication,key", "", true},
+		{"", "{{.Key}} {{.Size}}", false},
+		{"", "{{.Key}} {{.ReplicationStatus}}", true},
+	}
+	for i, testCase := range testCases {
+		var format lsFormat
+		var err *probe.Error
+		if testCase.format != "" {
+			format.template, err = parseListTemplate(testCase.format)
+		} else {
+			format.columns, err = parseListColumns(testCase.columns, false)
+		}
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if got := format.withReplication(); got != testCase.expected {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
@@ -53,6 +53,54 @@ var (
 			Name:  "summarize",
 			Usage: "display summary information (number of objects, total size)",
 		},
+		cli.BoolFlag{
+			Name:  "anonymize",
+			Usage: "hash object names and redact etags, keeping sizes and timestamps",
+		},
+		cli.BoolFlag{
+			Name:  "archive",
+			Usage: "list the files stored in tar or zip archives, zip archives are listed without downloading them",
+		},
+		cli.BoolFlag{
+			Name:  "long, l",
+			Usage: "list with the storage class, etag, owner and replication status of objects",
+		},
+		cli.StringFlag{
+			Name:  "columns",
+			Usage: "comma separated columns of the long listing: time, size, storage-class, etag, owner, replication, version-id and key",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "print each entry with a Go template of the fields of the JSON listing, e.g. '{{.Key}},{{.Size}}'",
+		},
+		cli.StringFlag{
+			Name:  "after",
+			Usage: "list only the versions modified at or after the date or duration before now",
+		},
+		cli.StringFlag{
+			Name:  "before",
+			Usage: "list only the versions modified before the date or duration before now",
+		},
+		cli.IntFlag{
+			Name:  "latest-n",
+			Usage: "list only the N latest versions of each object, requires --versions",
+		},
+		cli.StringFlag{
+			Name:  "sort",
+			Usage: "sort by 'name', 'size', largest first, or 'time', newest first, once listed",
+		},
+		cli.BoolFlag{
+			Name:  "reverse",
+			Usage: "reverse the order of the listing",
+		},
+		cli.BoolFlag{
+			Name:  "print0",
+			Usage: "print only the names, each terminated with a NUL character instead of a newline, for 'xargs -0'",
+		},
+		cli.StringFlag{
+			Name:  "continue-from",
+			Usage: "resume an interrupted listing after this key, as printed by the listing or its JSON summary, mirror resumes with --state-db instead",
+		},
 	}
 )
 
@@ -63,7 +111,7 @@ var lsCmd = cli.Command{
 	Action:       mainList,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(lsFlags, globalFlags...),
+	Flags:        append(append(append(lsFlags, requestHeaderFlags...), ioFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -102,6 +150,42 @@ EXAMPLES:
 
   9. List all objects on mybucket, summarize the number of objects and total size.
      {{.Prompt}} {{.HelpName}} --summarize s3/mybucket/
+
+  10. List all objects on mybucket with hashed names, to share the namespace layout without the key names.
+     {{.Prompt}} {{.HelpName}} --recursive --anonymize --json s3/mybucket/
+
+  11. List the files stored in a zip archive and in a compressed tarball kept on Amazon S3.
+     {{.Prompt}} {{.HelpName}} --archive s3/artifacts/release.zip
+     {{.Prompt}} {{.HelpName}} --archive s3/artifacts/release.tar.gz
+
+  12. List the objects of an AWS requester-pays bucket.
+     {{.Prompt}} {{.HelpName}} --request-payer requester s3/open-dataset/
+
+  13. List the objects of mybucket with their storage class, etag, owner and replication status.
+     {{.Prompt}} {{.HelpName}} -l s3/mybucket/
+
+  14. List the size, etag and name of all object versions of mybucket.
+     {{.Prompt}} {{.HelpName}} --versions --columns size,etag,version-id,key s3/mybucket/
+
+  15. Count the lines of all objects of mybucket, whatever characters their names contain.
+     {{.Prompt}} {{.HelpName}} --recursive --print0 s3/mybucket/ | xargs -0 -I{} mc cat s3/mybucket/{} | wc -l
+
+  16. List the 10 largest objects of mybucket, and its objects from the oldest to the newest.
+     {{.Prompt}} {{.HelpName}} --recursive --sort size s3/mybucket/ | head -10
+     {{.Prompt}} {{.HelpName}} --recursive --sort time --reverse s3/mybucket/
+
+  17. List the 3 latest versions of the objects of mybucket modified during an incident, and in the last hour.
+     {{.Prompt}} {{.HelpName}} --versions --after 2021.11.30T09:00 --before 2021.11.30T11:30 --latest-n 3 s3/mybucket/
+     {{.Prompt}} {{.HelpName}} --versions --after 1h s3/mybucket/
+
+  18. List the key, size, etag and storage class of the objects of mybucket as CSV.
+     {{.Prompt}} {{.HelpName}} --recursive --format '{{"{{.Key}},{{.Size}},{{.ETag}},{{.StorageClass}}"}}' s3/mybucket/
+
+  19. Resume an interrupted recursive listing of mybucket after the last key it printed.
+     {{.Prompt}} {{.HelpName}} --recursive --continue-from photos/2021/12/31/IMG_0042.jpg s3/mybucket/
+
+  20. List the files stored in a zip archive encrypted with a customer provided key.
+     {{.Prompt}} {{.HelpName}} --archive --encrypt-key "s3/artifacts/=32byteslongsecretkeymustbegiven1" s3/artifacts/release.zip
 `,
 }
 
@@ -114,33 +198,39 @@ var rewindSupportedFormat = []string{
 
 // Parse rewind flag while considering the system local time zone
 func parseRewindFlag(rewind string) (timeRef time.Time) {
-	if rewind != "" {
+	return parseTimeFlag("rewind", rewind)
+}
+
+// parseTimeFlag parses the value of the named flag, a date or a duration
+// before now in the formats of --rewind.
+func parseTimeFlag(name, value string) (timeRef time.Time) {
+	if value != "" {
 		location, e := time.LoadLocation("Local")
 		if e != nil {
 			return
 		}
 
 		for _, format := range rewindSupportedFormat {
-			if t, e := time.ParseInLocation(format, rewind, location); e == nil {
+			if t, e := time.ParseInLocation(format, value, location); e == nil {
 				timeRef = t
 				break
 			}
 		}
 
 		if timeRef.IsZero() {
-			// rewind is not parsed, check if it is a duration instead
-			if duration, e := duration.ParseDuration(rewind); e == nil {
+			// value is not parsed, check if it is a duration instead
+			if duration, e := duration.ParseDuration(value); e == nil {
 				if duration < 0 {
 					fatalIf(probe.NewError(errors.New("negative duration is not supported")),
-						"Unable to parse --rewind argument")
+						"Unable to parse --"+name+" argument")
 				}
 				timeRef = time.Now().Add(-time.Duration(duration))
 			}
 		}
 
 		if timeRef.IsZero() {
-			// rewind argument still not parsed, error out
-			fatalIf(probe.NewError(errors.New("unknown format")), "Unable to parse --rewind argument")
+			// value still not parsed, error out
+			fatalIf(probe.NewError(errors.New("unknown format")), "Unable to parse --"+name+" argument")
 		}
 	}
 	return
@@ -163,6 +253,49 @@ func checkListSyntax(ctx context.Context, cliCtx *cli.Context) ([]string, bool,
 	withOlderVersions := cliCtx.Bool("versions")
 	isSummary := cliCtx.Bool("summarize")
 
+	if cliCtx.Bool("archive") {
+		for _, flag := range []string{"rewind", "versions", "incomplete", "anonymize", "long", "columns", "format", "print0", "sort", "reverse", "after", "before", "latest-n", "continue-from"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(args...), "`--archive` cannot be used with `--"+flag+"`.")
+			}
+		}
+	}
+
+	if cliCtx.IsSet("format") {
+		for _, flag := range []string{"long", "columns", "print0"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(args...), "`--format` cannot be used with `--"+flag+"`.")
+			}
+		}
+		if globalJSON {
+			fatalIf(errInvalidArgument().Trace(args...), "`--format` cannot be used with `--json`.")
+		}
+	}
+
+	if cliCtx.Bool("print0") {
+		for _, flag := range []string{"long", "columns", "summarize"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(args...), "`--print0` cannot be used with `--"+flag+"`.")
+			}
+		}
+		if globalJSON {
+			fatalIf(errInvalidArgument().Trace(args...), "`--print0` cannot be used with `--json`.")
+		}
+	}
+
+	if cliCtx.IsSet("latest-n") {
+		if !withOlderVersions {
+			fatalIf(errInvalidArgument().Trace(args...), "`--latest-n` requires `--versions`.")
+		}
+		if cliCtx.Int("latest-n") <= 0 {
+			fatalIf(errInvalidArgument().Trace(args...), "`--latest-n` must be a positive number.")
+		}
+	}
+
+	if cliCtx.String("continue-from") != "" && len(args) > 1 {
+		fatalIf(errInvalidArgument().Trace(args...), "`--continue-from` can only be used to list a single URL.")
+	}
+
 	timeRef := parseRewindFlag(cliCtx.String("rewind"))
 	if timeRef.IsZero() && withOlderVersions {
 		timeRef = time.Now().UTC()
@@ -186,11 +319,60 @@ func mainList(cliCtx *cli.Context) error {
 	console.SetColor("Size", color.New(color.FgYellow))
 	console.SetColor("Time", color.New(color.FgGreen))
 	console.SetColor("Summarize", color.New(color.Bold))
+	console.SetColor("ETag", color.New(color.FgHiBlack))
+	console.SetColor("Owner", color.New(color.FgMagenta))
+
+	setRequestHeaders(cliCtx)
 
 	// check 'ls' cliCtx arguments.
 	args, isRecursive, isIncomplete, isSummary, timeRef, withOlderVersions := checkListSyntax(ctx, cliCtx)
 
+	format := lsFormat{print0: cliCtx.Bool("print0")}
+	if cliCtx.Bool("long") || cliCtx.IsSet("columns") {
+		var err *probe.Error
+		format.columns, err = parseListColumns(cliCtx.String("columns"), withOlderVersions || !timeRef.IsZero())
+		fatalIf(err.Trace(cliCtx.String("columns")), "Unable to parse --columns.")
+	}
+	if cliCtx.IsSet("format") {
+		var err *probe.Error
+		format.template, err = parseListTemplate(cliCtx.String("format"))
+		fatalIf(err.Trace(cliCtx.String("format")), "Unable to parse --format.")
+	}
+
+	var anonymizer *nameAnonymizer
+	if cliCtx.Bool("anonymize") {
+		anonymizer = newNameAnonymizer()
+	}
+
+	var filter *lsVersionFilter
+	if cliCtx.IsSet("after") || cliCtx.IsSet("before") || cliCtx.IsSet("latest-n") {
+		filter = &lsVersionFilter{
+			after:   parseTimeFlag("after", cliCtx.String("after")),
+			before:  parseTimeFlag("before", cliCtx.String("before")),
+			latestN: cliCtx.Int("latest-n"),
+		}
+		if !filter.after.IsZero() && !filter.before.IsZero() && !filter.after.Before(filter.before) {
+			fatalIf(errInvalidArgument().Trace(cliCtx.String("after"), cliCtx.String("before")), "`--after` must be earlier than `--before`.")
+		}
+	}
+
+	sortBy := cliCtx.String("sort")
+	if sortBy == "" && cliCtx.Bool("reverse") {
+		sortBy = "name"
+	}
+
 	var cErr error
+	if cliCtx.Bool("archive") {
+		encKeyDB, err := getEncKeys(cliCtx)
+		fatalIf(err, "Unable to parse encryption keys.")
+		for _, targetURL := range args {
+			if e := doListArchive(ctx, targetURL, isSummary, encKeyDB); e != nil {
+				cErr = e
+			}
+		}
+		return cErr
+	}
+
 	for _, targetURL := range args {
 		clnt, err := newClient(targetURL)
 		fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
@@ -203,7 +385,12 @@ func mainList(cliCtx *cli.Context) error {
 				fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
 			}
 		}
-		if e := doList(ctx, clnt, isRecursive, isIncomplete, isSummary, timeRef, withOlderVersions); e != nil {
+		var sorter *lsSorter
+		if sortBy != "" {
+			sorter, err = newLsSorter(sortBy, cliCtx.Bool("reverse"))
+			fatalIf(err.Trace(sortBy), "Unable to parse --sort, valid options are `[name, size, time]`.")
+		}
+		if e := doList(ctx, clnt, isRecursive, isIncomplete, isSummary, timeRef, withOlderVersions, cliCtx.String("continue-from"), anonymizer, format, sorter, filter); e != nil {
 			cErr = e
 		}
 	}
@@ -0,0 +1,203 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// lsSortRunSize is the number of listed entries sorted in memory, larger
+// listings are sorted in runs spilled to disk and merged.
+var lsSortRunSize = 100000
+
+// lsSortKeys are the orders supported by --sort.
+var lsSortKeys = []string{"name", "size", "time"}
+
+// lsSorter sorts the entries of a listing by name, by size, largest
+// first, or by time, newest first, like 'ls -S' and 'ls -t'.
+type lsSorter struct {
+	by      string
+	reverse bool
+
+	run  []contentMessage
+	runs []*os.File
+}
+
+func newLsSorter(by string, reverse bool) (*lsSorter, *probe.Error) {
+	for _, key := range lsSortKeys {
+		if by == key {
+			return &lsSorter{by: by, reverse: reverse}, nil
+		}
+	}
+	return nil, errInvalidArgument().Trace(by)
+}
+
+// less orders two entries, ties are ordered by name.
+func (s *lsSorter) less(a, b contentMessage) bool {
+	switch {
+	case s.by == "size" && a.Size != b.Size:
+		return (a.Size > b.Size) != s.reverse
+	case s.by == "time" && !a.Time.Equal(b.Time):
+		return a.Time.After(b.Time) != s.reverse
+	case a.Key != b.Key:
+		return (a.Key < b.Key) != s.reverse
+	}
+	return false
+}
+
+// Add adds an entry to the listing to sort.
+func (s *lsSorter) Add(msg contentMessage) *probe.Error {
+	s.run = append(s.run, msg)
+	if len(s.run) < lsSortRunSize {
+		return nil
+	}
+	return s.spill()
+}
+
+// spill writes the sorted run to a temporary file.
+func (s *lsSorter) spill() *probe.Error {
+	f, e := ioutil.TempFile("", "mc-ls-sort-")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	s.runs = append(s.runs, f)
+
+	sort.SliceStable(s.run, func(i, j int) bool { return s.less(s.run[i], s.run[j]) })
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, msg := range s.run {
+		if e = enc.Encode(msg); e != nil {
+			return probe.NewError(e)
+		}
+	}
+	if e = w.Flush(); e != nil {
+		return probe.NewError(e)
+	}
+	if _, e = f.Seek(0, 0); e != nil {
+		return probe.NewError(e)
+	}
+	s.run = s.run[:0]
+	return nil
+}
+
+// Flush calls fn with all added entries in order and releases the runs.
+func (s *lsSorter) Flush(fn func(contentMessage)) *probe.Error {
+	defer s.Close()
+	if len(s.runs) == 0 {
+		sort.SliceStable(s.run, func(i, j int) bool { return s.less(s.run[i], s.run[j]) })
+		for _, msg := range s.run {
+			fn(msg)
+		}
+		s.run = nil
+		return nil
+	}
+	if len(s.run) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+
+	h := &lsSortHeap{sorter: s}
+	for i, f := range s.runs {
+		head := &lsSortRun{index: i, dec: json.NewDecoder(bufio.NewReader(f))}
+		ok, err := head.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.runs = append(h.runs, head)
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 0 {
+		head := h.runs[0]
+		fn(head.msg)
+		ok, err := head.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// Close removes the runs spilled to disk.
+func (s *lsSorter) Close() {
+	for _, f := range s.runs {
+		f.Close()
+		os.Remove(f.Name())
+	}
+	s.runs = nil
+}
+
+// lsSortRun is a sorted run read back from disk.
+type lsSortRun struct {
+	index int
+	dec   *json.Decoder
+	msg   contentMessage
+}
+
+func (r *lsSortRun) next() (bool, *probe.Error) {
+	r.msg = contentMessage{}
+	if !r.dec.More() {
+		return false, nil
+	}
+	if e := r.dec.Decode(&r.msg); e != nil {
+		return false, probe.NewError(e)
+	}
+	return true, nil
+}
+
+// lsSortHeap merges the sorted runs.
+type lsSortHeap struct {
+	sorter *lsSorter
+	runs   []*lsSortRun
+}
+
+func (h lsSortHeap) Len() int      { return len(h.runs) }
+func (h lsSortHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+
+// Less keeps equal entries in the order they were added.
+func (h lsSortHeap) Less(i, j int) bool {
+	a, b := h.runs[i], h.runs[j]
+	if h.sorter.less(a.msg, b.msg) {
+		return true
+	}
+	return !h.sorter.less(b.msg, a.msg) && a.index < b.index
+}
+
+func (h *lsSortHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*lsSortRun)) }
+
+func (h *lsSortHeap) Pop() interface{} {
+	old := h.runs
+	n := len(old)
+	x := old[n-1]
+	h.runs = old[:n-1]
+	return x
+}
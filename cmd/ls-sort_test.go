@@ -0,0 +1,84 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLsSorter(t *testing.T) {
+	now := time.Date(2021, 12, 1, 10, 0, 0, 0, time.UTC)
+	var msgs []contentMessage
+	for i, key := range []string{"d", "a", "f", "b", "e", "c", "a"} {
+		msgs = append(msgs, contentMessage{
+			Key:       key,
+			Size:      int64((i * 7) % 5),
+			Time:      now.Add(time.Duration(i%3) * time.Hour),
+			VersionID: string(rune('0' + i)),
+		})
+	}
+	keys := func(msgs []contentMessage) (keys []string) {
+		for _, msg := range msgs {
+			keys = append(keys, msg.Key+msg.VersionID)
+		}
+		return keys
+	}
+
+	defer func(runSize int) { lsSortRunSize = runSize }(lsSortRunSize)
+	testCases := []struct {
+		by       string
+		reverse  bool
+		expected []string
+	}{
+		// Versions of the same object stay in the listed order.
+		{"name", false, []string{"a1", "a6", "b3", "c5", "d0", "e4", "f2"}},
+		{"name", true, []string{"f2", "e4", "d0", "c5", "b3", "a1", "a6"}},
+		// Largest first, then by name.
+		{"size", false, []string{"f2", "e4", "a1", "a6", "b3", "c5", "d0"}},
+		// Newest first, then by name.
+		{"time", false, []string{"c5", "f2", "a1", "e4", "a6", "b3", "d0"}},
+		{"time", true, []string{"d0", "b3", "a6", "e4", "a1", "f2", "c5"}},
+	}
+	for _, runSize := range []int{100, 2} {
+		lsSortRunSize = runSize
+		for i, testCase := range testCases {
+			sorter, err := newLsSorter(testCase.by, testCase.reverse)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, msg := range msgs {
+				if err = sorter.Add(msg); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var sorted []contentMessage
+			if err = sorter.Flush(func(msg contentMessage) { sorted = append(sorted, msg) }); err != nil {
+				t.Fatal(err)
+			}
+			if got := keys(sorted); !reflect.DeepEqual(got, testCase.expected) {
+				t.Errorf("Test %d (run size %d): expected %v, got %v", i+1, runSize, testCase.expected, got)
+			}
+		}
+	}
+
+	if _, err := newLsSorter("owner", false); err == nil {
+		t.Errorf("expected an error sorting by owner")
+	}
+}
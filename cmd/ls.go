@@ -20,9 +20,11 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"path/filepath"
 	"sort"
 	"strings"
+	"text/template"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
@@ -50,10 +52,24 @@ type contentMessage struct {
 	VersionOrd     int    `json:"versionOrdinal,omitempty"`
 	VersionIndex   int    `json:"versionIndex,omitempty"`
 	IsDeleteMarker bool   `json:"isDeleteMarker,omitempty"`
+
+	StorageClass      string `json:"storageClass,omitempty"`
+	Owner             string `json:"owner,omitempty"`
+	ReplicationStatus string `json:"replicationStatus,omitempty"`
+
+	// Columns of the long listing, or its template, if requested.
+	columns  []string
+	template *template.Template
 }
 
 // String colorized string message.
 func (c contentMessage) String() string {
+	if c.template != nil {
+		return c.templateString()
+	}
+	if len(c.columns) > 0 {
+		return c.longString()
+	}
 	message := console.Colorize("Time", fmt.Sprintf("[%s]", c.Time.Format(printDate)))
 	message += console.Colorize("Size", fmt.Sprintf("%7s", strings.Join(strings.Fields(humanize.IBytes(uint64(c.Size))), "")))
 	fileDesc := ""
@@ -101,15 +117,21 @@ func getKey(c *ClientContent) string {
 	return getOSDependantKey(c.URL.Path, c.Type.IsDir())
 }
 
-// Generate printable listing from a list of sorted client
-// contents, the latest created content comes first.
-func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAllVersions bool) (msgs []contentMessage) {
+// listPrefixPath returns the path trimmed from the contents listed
+// from clntURL to print their keys.
+func listPrefixPath(clntURL ClientURL) string {
 	prefixPath := clntURL.Path
 	prefixPath = filepath.ToSlash(prefixPath)
 	if !strings.HasSuffix(prefixPath, "/") {
 		prefixPath = prefixPath[:strings.LastIndex(prefixPath, "/")+1]
 	}
-	prefixPath = strings.TrimPrefix(prefixPath, "./")
+	return strings.TrimPrefix(prefixPath, "./")
+}
+
+// Generate printable listing from a list of sorted client
+// contents, the latest created content comes first.
+func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAllVersions bool) (msgs []contentMessage) {
+	prefixPath := listPrefixPath(clntURL)
 
 	nrVersions := len(ctnts)
 
@@ -138,6 +160,9 @@ func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAll
 		contentMsg.Key = getKey(c)
 		contentMsg.VersionID = c.VersionID
 		contentMsg.IsDeleteMarker = c.IsDeleteMarker
+		contentMsg.StorageClass = c.StorageClass
+		contentMsg.Owner = c.Owner
+		contentMsg.ReplicationStatus = c.ReplicationStatus
 		contentMsg.VersionOrd = nrVersions - i
 		// URL is empty by default
 		// Set it to either relative dir (host) or public url (remote)
@@ -152,6 +177,34 @@ func generateContentMessages(clntURL ClientURL, ctnts []*ClientContent, printAll
 	return
 }
 
+// lsVersionFilter filters the listed versions of each object.
+type lsVersionFilter struct {
+	after, before time.Time
+	latestN       int
+}
+
+// apply keeps the versions modified in [after, before), and only the
+// latestN of them, versions being sorted from the latest.
+func (f *lsVersionFilter) apply(msgs []contentMessage) []contentMessage {
+	if f == nil {
+		return msgs
+	}
+	filtered := msgs[:0]
+	for _, msg := range msgs {
+		if f.latestN > 0 && len(filtered) == f.latestN {
+			break
+		}
+		if !f.after.IsZero() && msg.Time.Before(f.after) {
+			continue
+		}
+		if !f.before.IsZero() && !msg.Time.Before(f.before) {
+			continue
+		}
+		filtered = append(filtered, msg)
+	}
+	return filtered
+}
+
 func sortObjectVersions(ctntVersions []*ClientContent) {
 	// Sort versions
 	sort.Slice(ctntVersions, func(i, j int) bool {
@@ -167,8 +220,9 @@ func sortObjectVersions(ctntVersions []*ClientContent) {
 
 // summaryMessage container for summary message structure
 type summaryMessage struct {
-	TotalObjects int64 `json:"totalObjects"`
-	TotalSize    int64 `json:"totalSize"`
+	TotalObjects int64  `json:"totalObjects"`
+	TotalSize    int64  `json:"totalSize"`
+	LastKey      string `json:"lastKey,omitempty"`
 }
 
 // String colorized string message
@@ -186,32 +240,56 @@ func (s summaryMessage) JSON() string {
 }
 
 // Pretty print the list of versions belonging to one object
-func printObjectVersions(clntURL ClientURL, ctntVersions []*ClientContent, printAllVersions, isSummary bool) {
+func printObjectVersions(clntURL ClientURL, ctntVersions []*ClientContent, printAllVersions, isSummary bool, anonymizer *nameAnonymizer, format lsFormat, sorter *lsSorter, filter *lsVersionFilter) *probe.Error {
 	sortObjectVersions(ctntVersions)
-	msgs := generateContentMessages(clntURL, ctntVersions, printAllVersions)
+	if format.withOwner() {
+		setListOwners(ctntVersions)
+	}
+	msgs := filter.apply(generateContentMessages(clntURL, ctntVersions, printAllVersions))
 	for _, msg := range msgs {
-		printMsg(msg)
+		msg = anonymizer.anonymizeContent("", msg)
+		if sorter != nil {
+			if err := sorter.Add(msg); err != nil {
+				return err
+			}
+			continue
+		}
+		format.print(msg)
 	}
+	return nil
 }
 
-// doList - list all entities inside a folder.
-func doList(ctx context.Context, clnt Client, isRecursive, isIncomplete, isSummary bool, timeRef time.Time, withOlderVersions bool) error {
+// doList - list all entities inside a folder in the given format,
+// sorted once listed if a sorter is passed and only the versions
+// matching the filter if a filter is passed. The listing starts
+// after the key continueFrom if not empty.
+func doList(ctx context.Context, clnt Client, isRecursive, isIncomplete, isSummary bool, timeRef time.Time, withOlderVersions bool, continueFrom string, anonymizer *nameAnonymizer, format lsFormat, sorter *lsSorter, filter *lsVersionFilter) error {
 
 	var (
 		lastPath          string
+		lastKey           string
+		completedKey      string
+		startAfter        string
 		perObjectVersions []*ClientContent
 		cErr              error
 		totalSize         int64
 		totalObjects      int64
 	)
 
+	prefixPath := listPrefixPath(clnt.GetURL())
+	if continueFrom != "" {
+		startAfter = prefixPath + continueFrom
+	}
+
 	for content := range clnt.List(ctx, ListOptions{
 		Recursive:         isRecursive,
 		Incomplete:        isIncomplete,
 		TimeRef:           timeRef,
 		WithOlderVersions: withOlderVersions || !timeRef.IsZero(),
 		WithDeleteMarkers: true,
+		WithMetadata:      format.withReplication(),
 		ShowDir:           DirNone,
+		StartAfter:        startAfter,
 	}) {
 		if content.Err != nil {
 			switch content.Err.ToGoError().(type) {
@@ -238,9 +316,21 @@ func doList(ctx context.Context, clnt Client, isRecursive, isIncomplete, isSumma
 			continue
 		}
 
+		// Not all listings start after the key by themselves.
+		contentPath := filepath.ToSlash(content.URL.Path)
+		if startAfter != "" && contentPath <= startAfter {
+			continue
+		}
+
 		if lastPath != content.URL.Path {
 			// Print any object in the current list before reinitializing it
-			printObjectVersions(clnt.GetURL(), perObjectVersions, withOlderVersions, isSummary)
+			if err := printObjectVersions(clnt.GetURL(), perObjectVersions, withOlderVersions, isSummary, anonymizer, format, sorter, filter); err != nil {
+				sorter.Close()
+				errorIf(err.Trace(clnt.GetURL().String()), "Unable to sort the listing.")
+				return exitStatus(globalErrorExitStatus)
+			}
+			completedKey = lastKey
+			lastKey = strings.TrimPrefix(contentPath, prefixPath)
 			lastPath = content.URL.Path
 			perObjectVersions = []*ClientContent{}
 		}
@@ -250,14 +340,66 @@ func doList(ctx context.Context, clnt Client, isRecursive, isIncomplete, isSumma
 		totalObjects++
 	}
 
-	printObjectVersions(clnt.GetURL(), perObjectVersions, withOlderVersions, isSummary)
+	// The versions of the last key may not all be listed when the listing
+	// failed, they are printed once it is resumed after the previous key.
+	var err *probe.Error
+	if cErr != nil && withOlderVersions {
+		lastKey = completedKey
+	} else {
+		err = printObjectVersions(clnt.GetURL(), perObjectVersions, withOlderVersions, isSummary, anonymizer, format, sorter, filter)
+	}
+	if err == nil && sorter != nil {
+		err = sorter.Flush(format.print)
+	}
+	if err != nil {
+		sorter.Close()
+		errorIf(err.Trace(clnt.GetURL().String()), "Unable to sort the listing.")
+		return exitStatus(globalErrorExitStatus)
+	}
 
-	if isSummary {
+	// A failed listing reports its last key in JSON to be resumed.
+	if isSummary || (globalJSON && cErr != nil && lastKey != "") {
 		printMsg(summaryMessage{
 			TotalObjects: totalObjects,
 			TotalSize:    totalSize,
+			LastKey:      lastKey,
 		})
 	}
 
 	return cErr
 }
+
+// doListArchive lists the files and folders stored in the tar or zip
+// archive at targetURL, reading only the index of zip archives.
+func doListArchive(ctx context.Context, targetURL string, isSummary bool, encKeyDB map[string][]prefixSSEPair) error {
+	var totalSize, totalObjects int64
+	isZip := strings.EqualFold(filepath.Ext(targetURL), ".zip")
+	err := walkArchive(ctx, targetURL, isZip, true, encKeyDB, func(entry archiveEntry, _ io.Reader) *probe.Error {
+		msg := contentMessage{
+			Status:   "success",
+			Filetype: "file",
+			Time:     entry.ModTime.Local(),
+			Size:     entry.Size,
+			Key:      entry.Name,
+		}
+		if entry.Mode.IsDir() {
+			msg.Filetype = "folder"
+		} else {
+			totalSize += entry.Size
+			totalObjects++
+		}
+		printMsg(msg)
+		return nil
+	})
+	if err != nil {
+		errorIf(err.Trace(targetURL), "Unable to list archive `"+targetURL+"`.")
+		return exitStatus(globalErrorExitStatus)
+	}
+	if isSummary {
+		printMsg(summaryMessage{
+			TotalObjects: totalObjects,
+			TotalSize:    totalSize,
+		})
+	}
+	return nil
+}
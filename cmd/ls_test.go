@@ -16,3 +16,143 @@
 // along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
 package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestLsVersionFilter(t *testing.T) {
+	now := time.Date(2021, 11, 30, 12, 0, 0, 0, time.UTC)
+	// Versions of an object, sorted from the latest.
+	var msgs []contentMessage
+	for i := 0; i < 5; i++ {
+		msgs = append(msgs, contentMessage{Key: "obj", VersionOrd: 5 - i, Time: now.Add(-time.Duration(i) * time.Hour)})
+	}
+	testCases := []struct {
+		filter   *lsVersionFilter
+		expected []int
+	}{
+		{nil, []int{5, 4, 3, 2, 1}},
+		{&lsVersionFilter{latestN: 2}, []int{5, 4}},
+		{&lsVersionFilter{after: now.Add(-2 * time.Hour)}, []int{5, 4, 3}},
+		{&lsVersionFilter{before: now.Add(-2 * time.Hour)}, []int{2, 1}},
+		{&lsVersionFilter{after: now.Add(-3 * time.Hour), before: now}, []int{4, 3, 2}},
+		{&lsVersionFilter{after: now.Add(-3 * time.Hour), before: now, latestN: 1}, []int{4}},
+		{&lsVersionFilter{after: now.Add(time.Hour)}, nil},
+	}
+	for i, testCase := range testCases {
+		var ords []int
+		for _, msg := range testCase.filter.apply(append([]contentMessage{}, msgs...)) {
+			ords = append(ords, msg.VersionOrd)
+		}
+		if !reflect.DeepEqual(ords, testCase.expected) {
+			t.Errorf("Test %d: expected versions %v, got %v", i+1, testCase.expected, ords)
+		}
+	}
+}
+
+func TestListPrefixPath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+	}{
+		{"/mybucket/", "/mybucket/"},
+		{"/mybucket/photos/", "/mybucket/photos/"},
+		{"/mybucket/photos/2021", "/mybucket/photos/"},
+		{"./dir/", "dir/"},
+		{"file", ""},
+	}
+	for i, testCase := range testCases {
+		if prefixPath := listPrefixPath(ClientURL{Path: testCase.path}); prefixPath != testCase.expected {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.expected, prefixPath)
+		}
+	}
+}
+
+// listTestClient lists versions of objects, failing after them if err is set.
+type listTestClient struct {
+	Client
+	contents []*ClientContent
+	err      *probe.Error
+}
+
+func (c *listTestClient) GetURL() ClientURL {
+	return ClientURL{Type: objectStorage, Path: "/bucket/", Separator: '/'}
+}
+
+func (c *listTestClient) List(ctx context.Context, opts ListOptions) <-chan *ClientContent {
+	contentCh := make(chan *ClientContent, len(c.contents)+1)
+	for _, content := range c.contents {
+		contentCh <- content
+	}
+	if c.err != nil {
+		contentCh <- &ClientContent{Err: c.err}
+	}
+	close(contentCh)
+	return contentCh
+}
+
+func TestDoListVersionsLastKey(t *testing.T) {
+	defer func(output io.Writer, json bool) { color.Output, globalJSON = output, json }(color.Output, globalJSON)
+	globalJSON = true
+
+	version := func(key, versionID string) *ClientContent {
+		return &ClientContent{URL: ClientURL{Type: objectStorage, Path: "/bucket/" + key, Separator: '/'}, VersionID: versionID}
+	}
+	contents := []*ClientContent{version("a", "1"), version("a", "2"), version("b", "1")}
+
+	testCases := []struct {
+		err      *probe.Error
+		listed   []string
+		lastKey  string
+		exitCode bool
+	}{
+		{nil, []string{"a 1", "a 2", "b 1"}, "", false},
+		// The versions of b after the failure are not listed, the
+		// listing resumes after a to list all versions of b.
+		{probe.NewError(errors.New("connection reset")), []string{"a 1", "a 2"}, "a", true},
+	}
+	for i, testCase := range testCases {
+		var buf bytes.Buffer
+		color.Output = &buf
+		clnt := &listTestClient{contents: contents, err: testCase.err}
+		e := doList(context.Background(), clnt, true, false, false, time.Now(), true, "", nil, lsFormat{}, nil, nil)
+		if (e != nil) != testCase.exitCode {
+			t.Fatalf("Test %d: expected failure %v, got %v", i+1, testCase.exitCode, e)
+		}
+		var listed []string
+		var lastKey string
+		decoder := json.NewDecoder(&buf)
+		for {
+			var msg struct {
+				Status    string `json:"status"`
+				Key       string `json:"key"`
+				VersionID string `json:"versionId"`
+				LastKey   string `json:"lastKey"`
+			}
+			if decoder.Decode(&msg) != nil {
+				break
+			}
+			if msg.LastKey != "" {
+				lastKey = msg.LastKey
+			} else if msg.Status == "success" {
+				listed = append(listed, msg.Key+" "+msg.VersionID)
+			}
+		}
+		sort.Strings(listed)
+		if !reflect.DeepEqual(listed, testCase.listed) || lastKey != testCase.lastKey {
+			t.Errorf("Test %d: expected %v listed and last key %q, got %v and %q", i+1, testCase.listed, testCase.lastKey, listed, lastKey)
+		}
+	}
+}
@@ -138,10 +138,18 @@ func Main(args []string) {
 	// Monitor OS exit signals and cancel the global context in such case
 	go trapSignals(os.Interrupt, syscall.SIGTERM, syscall.SIGKILL)
 
+	// Run the notify hook, if any, before exiting with a status.
+	cli.OsExiter = func(code int) {
+		notifyCompletion(code, nil)
+		os.Exit(code)
+	}
+
 	// Run the app - exit on error.
 	if err := registerApp(appName).Run(args); err != nil {
+		notifyCompletion(globalErrorExitStatus, err)
 		os.Exit(1)
 	}
+	notifyCompletion(0, nil)
 }
 
 // Function invoked when invalid flag is passed
@@ -437,10 +445,16 @@ var appCmds = []cli.Command{
 	mvCmd,
 	treeCmd,
 	duCmd,
+	jobCmd,
+	foreachCmd,
 	retentionCmd,
 	legalHoldCmd,
 	diffCmd,
+	scrubCmd,
+	snapshotCmd,
+	lockfileCmd,
 	rmCmd,
+	trashCmd,
 	versionCmd,
 	ilmCmd,
 	encryptCmd,
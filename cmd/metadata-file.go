@@ -0,0 +1,126 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"errors"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/wildcard"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// metadataRule sets the headers and user metadata of the objects
+// matching a pattern of a metadata file.
+type metadataRule struct {
+	Pattern      string            `yaml:"pattern"`
+	ContentType  string            `yaml:"content-type,omitempty"`
+	CacheControl string            `yaml:"cache-control,omitempty"`
+	Metadata     map[string]string `yaml:"metadata,omitempty"`
+}
+
+// metadataMap is the list of rules of a metadata file, a YAML list of
+// rules with a pattern and the content-type, cache-control and user
+// metadata to set. All the rules matching an object apply in order, a
+// later rule overriding the values set by an earlier one.
+type metadataMap []metadataRule
+
+// loadMetadataFile reads and validates the metadata file at path.
+func loadMetadataFile(filePath string) (metadataMap, *probe.Error) {
+	data, e := ioutil.ReadFile(filePath)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	return parseMetadataMap(data)
+}
+
+// mustLoadMetadataFile loads the metadata file at path if any, exiting
+// when it cannot be used.
+func mustLoadMetadataFile(filePath string) metadataMap {
+	if filePath == "" {
+		return nil
+	}
+	m, err := loadMetadataFile(filePath)
+	fatalIf(err.Trace(filePath), "Unable to load the metadata file `"+filePath+"`.")
+	return m
+}
+
+// parseMetadataMap parses the YAML content of a metadata file.
+func parseMetadataMap(data []byte) (metadataMap, *probe.Error) {
+	var m metadataMap
+	if e := yaml.UnmarshalStrict(data, &m); e != nil {
+		return nil, probe.NewError(e)
+	}
+	for _, rule := range m {
+		if rule.Pattern == "" {
+			return nil, probe.NewError(errors.New("every rule of a metadata file needs a pattern"))
+		}
+		if rule.ContentType == "" && rule.CacheControl == "" && len(rule.Metadata) == 0 {
+			return nil, probe.NewError(errors.New("rule `" + rule.Pattern + "` sets no metadata"))
+		}
+	}
+	return m, nil
+}
+
+// metadataRelPath returns the slash separated path of a copied object
+// relative to the target of the copy, its name when copied alone.
+func metadataRelPath(targetRoot, objectPath string) string {
+	targetRoot = strings.TrimSuffix(targetRoot, "/")
+	if rel := strings.TrimPrefix(objectPath, targetRoot+"/"); rel != objectPath && rel != "" {
+		return rel
+	}
+	return path.Base(objectPath)
+}
+
+// matchMetadataPattern returns true if the object path, relative to the
+// target of the copy, matches the pattern. A pattern with a '/' is
+// matched against the whole relative path, otherwise against the object
+// name only.
+func matchMetadataPattern(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		return wildcard.Match(pattern, path.Base(name))
+	}
+	return wildcard.Match(strings.TrimPrefix(pattern, "/"), strings.TrimPrefix(name, "/"))
+}
+
+// metadata returns the headers and user metadata of the rules matching
+// the object path, nil when no rule matches.
+func (m metadataMap) metadata(name string) map[string]string {
+	var metadata map[string]string
+	for _, rule := range m {
+		if !matchMetadataPattern(rule.Pattern, name) {
+			continue
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		if rule.ContentType != "" {
+			metadata["Content-Type"] = rule.ContentType
+		}
+		if rule.CacheControl != "" {
+			metadata["Cache-Control"] = rule.CacheControl
+		}
+		for k, v := range rule.Metadata {
+			metadata[k] = v
+		}
+	}
+	return metadata
+}
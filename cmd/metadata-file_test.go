@@ -0,0 +1,86 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMetadataMap(t *testing.T) {
+	m, err := parseMetadataMap([]byte(`
+- pattern: "*"
+  cache-control: max-age=60
+- pattern: "*.html"
+  content-type: text/html; charset=utf-8
+  cache-control: no-cache
+- pattern: "assets/*.css"
+  content-type: text/css
+  metadata:
+    release: "2021.11"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name     string
+		metadata map[string]string
+	}{
+		{"index.html", map[string]string{"Content-Type": "text/html; charset=utf-8", "Cache-Control": "no-cache"}},
+		{"docs/index.html", map[string]string{"Content-Type": "text/html; charset=utf-8", "Cache-Control": "no-cache"}},
+		{"robots.txt", map[string]string{"Cache-Control": "max-age=60"}},
+		{"assets/main.css", map[string]string{"Content-Type": "text/css", "Cache-Control": "max-age=60", "release": "2021.11"}},
+		// Patterns with a '/' match from the target of the copy.
+		{"vendor/assets/main.css", map[string]string{"Cache-Control": "max-age=60"}},
+		{"myassets/main.css", map[string]string{"Cache-Control": "max-age=60"}},
+	}
+	for _, testCase := range testCases {
+		if metadata := m.metadata(testCase.name); !reflect.DeepEqual(metadata, testCase.metadata) {
+			t.Errorf("%s: expected %v, got %v", testCase.name, testCase.metadata, metadata)
+		}
+	}
+
+	for _, data := range []string{
+		"- content-type: text/plain\n",
+		"- pattern: \"*\"\n",
+		"- pattern: \"*\"\n  content-typ: text/plain\n",
+		"pattern: \"*\"\n",
+	} {
+		if _, err := parseMetadataMap([]byte(data)); err == nil {
+			t.Errorf("expected an error parsing %q", data)
+		}
+	}
+}
+
+func TestMetadataRelPath(t *testing.T) {
+	testCases := []struct {
+		root, objectPath, rel string
+	}{
+		{"/www", "/www/assets/main.css", "assets/main.css"},
+		{"/www/", "/www/index.html", "index.html"},
+		{"/home/user/site", "/home/user/site/assets/main.css", "assets/main.css"},
+		// A single object copied to its target.
+		{"/www/index.html", "/www/index.html", "index.html"},
+		{"/www", "/www2/index.html", "index.html"},
+	}
+	for _, testCase := range testCases {
+		if rel := metadataRelPath(testCase.root, testCase.objectPath); rel != testCase.rel {
+			t.Errorf("%s in %s: expected %q, got %q", testCase.objectPath, testCase.root, testCase.rel, rel)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// mirrorCompare is how mirror detects that an object of the same
+// name and size on source and target has changed.
+type mirrorCompare string
+
+const (
+	// Objects of the same size are identical.
+	mirrorCompareSize mirrorCompare = "size"
+	// Objects modified later on source differ, the default.
+	mirrorCompareMtime mirrorCompare = "mtime"
+	// Objects with different ETags differ.
+	mirrorCompareETag mirrorCompare = "etag"
+	// Objects with different MD5 checksums differ, local files are hashed.
+	mirrorCompareChecksum mirrorCompare = "checksum"
+)
+
+// parseMirrorCompare validates the value of --compare.
+func parseMirrorCompare(s string) (mirrorCompare, *probe.Error) {
+	switch c := mirrorCompare(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+		return mirrorCompareMtime, nil
+	case mirrorCompareSize, mirrorCompareMtime, mirrorCompareETag, mirrorCompareChecksum:
+		return c, nil
+	}
+	return mirrorCompareMtime, errInvalidArgument().Trace(s)
+}
+
+// needsMetadata reports whether the listings need the metadata of the
+// objects, which tells if their ETags are MD5 sums.
+func (c mirrorCompare) needsMetadata() bool {
+	return c == mirrorCompareETag || c == mirrorCompareChecksum
+}
+
+// differ compares the content of source and target objects of the same
+// name and size. ETags and checksums fall back to the modification time
+// when they cannot be compared, i.e. unless both ETags are MD5 sums, which
+// is not the case for multipart uploads or SSE-KMS and SSE-C encryption.
+func (c mirrorCompare) differ(src, tgt *ClientContent) (differType, *probe.Error) {
+	switch c {
+	case mirrorCompareSize:
+		return differInNone, nil
+	case mirrorCompareETag:
+		srcSum, srcOK := scrubExpectedMD5(src)
+		tgtSum, tgtOK := scrubExpectedMD5(tgt)
+		if srcOK && tgtOK {
+			if srcSum != tgtSum {
+				return differInETag, nil
+			}
+			return differInNone, nil
+		}
+	case mirrorCompareChecksum:
+		// The target is checked first so that local files are only
+		// hashed when their sum can be compared.
+		tgtSum, tgtOK, err := contentMD5(tgt)
+		if err != nil {
+			return differInUnknown, err.Trace(tgt.URL.String())
+		}
+		if !tgtOK {
+			break
+		}
+		srcSum, srcOK, err := contentMD5(src)
+		if err != nil {
+			return differInUnknown, err.Trace(src.URL.String())
+		}
+		if srcOK {
+			if srcSum != tgtSum {
+				return differInChecksum, nil
+			}
+			return differInNone, nil
+		}
+	}
+	if activeActiveModTimeUpdated(src, tgt) {
+		return differInAASourceMTime, nil
+	}
+	return differInNone, nil
+}
+
+// contentMD5 returns the MD5 sum of content, local files are hashed and
+// objects only have one when their ETag is one.
+func contentMD5(content *ClientContent) (string, bool, *probe.Error) {
+	if content.URL.Type != fileSystem {
+		sum, ok := scrubExpectedMD5(content)
+		return sum, ok, nil
+	}
+	f, e := os.Open(content.URL.Path)
+	if e != nil {
+		return "", false, probe.NewError(e)
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, e = io.Copy(h, f); e != nil {
+		return "", false, probe.NewError(e)
+	}
+	return hex.EncodeToString(h.Sum(nil)), true, nil
+}
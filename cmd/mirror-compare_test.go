@@ -0,0 +1,103 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseMirrorCompare(t *testing.T) {
+	testCases := []struct {
+		value   string
+		compare mirrorCompare
+		success bool
+	}{
+		{"", mirrorCompareMtime, true},
+		{"size", mirrorCompareSize, true},
+		{" ETag ", mirrorCompareETag, true},
+		{"checksum", mirrorCompareChecksum, true},
+		{"sha256", mirrorCompareMtime, false},
+	}
+	for i, testCase := range testCases {
+		compare, err := parseMirrorCompare(testCase.value)
+		if success := err == nil; success != testCase.success || compare != testCase.compare {
+			t.Errorf("Test %d: expected %q (success %t), got %q: %v", i+1, testCase.compare, testCase.success, compare, err)
+		}
+	}
+}
+
+func TestMirrorCompareDiffer(t *testing.T) {
+	dir := t.TempDir()
+	older, newer := time.Now().Add(-time.Hour), time.Now()
+	localFile := func(name string, data []byte, modTime time.Time) *ClientContent {
+		path := filepath.Join(dir, name)
+		if e := ioutil.WriteFile(path, data, 0o644); e != nil {
+			t.Fatal(e)
+		}
+		return &ClientContent{URL: *newClientURL(path), Size: int64(len(data)), Time: modTime}
+	}
+	object := func(etag string, modTime time.Time) *ClientContent {
+		return &ClientContent{URL: *newClientURL("https://play.min.io/bucket/object"), Size: 5, ETag: etag, Time: modTime}
+	}
+	encrypted := func(etag string, modTime time.Time, key, value string) *ClientContent {
+		content := object(etag, modTime)
+		content.UserMetadata = map[string]string{key: value}
+		return content
+	}
+	sum := md5.Sum([]byte("hello"))
+	helloETag := hex.EncodeToString(sum[:])
+	sum = md5.Sum([]byte("hellO"))
+	otherETag := hex.EncodeToString(sum[:])
+
+	testCases := []struct {
+		compare  mirrorCompare
+		src, tgt *ClientContent
+		diff     differType
+	}{
+		{mirrorCompareSize, localFile("a", []byte("hello"), newer), object("", older), differInNone},
+		{mirrorCompareMtime, localFile("b", []byte("hello"), newer), object("", older), differInAASourceMTime},
+		{mirrorCompareMtime, localFile("c", []byte("hello"), older), object("", newer), differInNone},
+		{mirrorCompareETag, object("\""+helloETag+"\"", newer), object(helloETag, older), differInNone},
+		{mirrorCompareETag, object(helloETag, older), object(otherETag, newer), differInETag},
+		// ETags of multipart uploads are not MD5 sums.
+		{mirrorCompareETag, object(helloETag, newer), object(otherETag+"-2", older), differInAASourceMTime},
+		{mirrorCompareETag, object(otherETag+"-2", newer), object(otherETag+"-2", older), differInAASourceMTime},
+		{mirrorCompareChecksum, localFile("d", []byte("hello"), newer), object(helloETag, older), differInNone},
+		{mirrorCompareChecksum, localFile("e", []byte("hellO"), older), object(helloETag, newer), differInChecksum},
+		{mirrorCompareChecksum, localFile("f", []byte("hello"), newer), localFile("g", []byte("hellO"), older), differInChecksum},
+		// The ETags of SSE-KMS and SSE-C encrypted objects are not MD5 sums.
+		{mirrorCompareChecksum, localFile("h", []byte("hellO"), newer), encrypted(helloETag, older, "X-Amz-Server-Side-Encryption", "aws:kms"), differInAASourceMTime},
+		{mirrorCompareChecksum, localFile("i", []byte("hellO"), older), encrypted(helloETag, newer, "X-Amz-Server-Side-Encryption-Customer-Algorithm", "AES256"), differInNone},
+		{mirrorCompareChecksum, localFile("j", []byte("hellO"), older), encrypted(helloETag, newer, "X-Amz-Server-Side-Encryption", "AES256"), differInChecksum},
+		{mirrorCompareChecksum, localFile("k", []byte("hellO"), newer), object(helloETag+"-1", older), differInAASourceMTime},
+	}
+	for i, testCase := range testCases {
+		diff, err := testCase.compare.differ(testCase.src, testCase.tgt)
+		if err != nil {
+			t.Fatalf("Test %d: %v", i+1, err)
+		}
+		if diff != testCase.diff {
+			t.Errorf("Test %d: expected %q, got %q", i+1, testCase.diff, diff)
+		}
+	}
+}
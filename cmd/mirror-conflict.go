@@ -0,0 +1,75 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// mirrorConflict is the strategy used by mirror when an object exists
+// on both source and target but differs.
+type mirrorConflict string
+
+const (
+	// Overwrite the target unconditionally, the default with --overwrite.
+	mirrorConflictOverwrite mirrorConflict = ""
+	// Overwrite the target only if the source was modified later.
+	mirrorConflictNewerWins mirrorConflict = "newer-wins"
+	// Overwrite the target only if the source is larger.
+	mirrorConflictLargerWins mirrorConflict = "larger-wins"
+	// Overwrite the target only if the ETags differ.
+	mirrorConflictETag mirrorConflict = "etag"
+	// Never overwrite the target.
+	mirrorConflictSkip mirrorConflict = "skip"
+	// Report the object as failed without overwriting the target.
+	mirrorConflictFail mirrorConflict = "fail"
+)
+
+// parseMirrorConflict validates the value of --conflict.
+func parseMirrorConflict(s string) (mirrorConflict, *probe.Error) {
+	switch c := mirrorConflict(strings.ToLower(strings.TrimSpace(s))); c {
+	case mirrorConflictOverwrite, mirrorConflictNewerWins, mirrorConflictLargerWins,
+		mirrorConflictETag, mirrorConflictSkip, mirrorConflictFail:
+		return c, nil
+	}
+	return mirrorConflictOverwrite, errInvalidArgument().Trace(s)
+}
+
+// resolve returns true if source should overwrite target, an error is
+// returned for objects that must be reported as conflicts.
+func (c mirrorConflict) resolve(source, target *ClientContent) (bool, *probe.Error) {
+	switch c {
+	case mirrorConflictNewerWins:
+		return source.Time.After(target.Time), nil
+	case mirrorConflictLargerWins:
+		return source.Size > target.Size, nil
+	case mirrorConflictETag:
+		// Copy when either ETag is unknown, e.g. on a local filesystem.
+		if source.ETag == "" || target.ETag == "" {
+			return true, nil
+		}
+		return strings.Trim(source.ETag, "\"") != strings.Trim(target.ETag, "\""), nil
+	case mirrorConflictSkip:
+		return false, nil
+	case mirrorConflictFail:
+		return false, errMirrorConflict(target.URL.String())
+	}
+	return true, nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMirrorConflictResolve(t *testing.T) {
+	now := time.Now()
+	older := &ClientContent{Time: now.Add(-time.Hour), Size: 10, ETag: "\"abc\""}
+	newer := &ClientContent{Time: now, Size: 5, ETag: "abc"}
+	unknown := &ClientContent{Time: now, Size: 5}
+
+	testCases := []struct {
+		conflict       string
+		source, target *ClientContent
+		overwrite      bool
+		expectErr      bool
+	}{
+		{"", older, newer, true, false},
+		{"newer-wins", newer, older, true, false},
+		{"newer-wins", older, newer, false, false},
+		{"larger-wins", older, newer, true, false},
+		{"Larger-Wins", newer, older, false, false},
+		{"etag", older, newer, false, false},
+		{"etag", unknown, newer, true, false},
+		{"skip", newer, older, false, false},
+		{"fail", newer, older, false, true},
+	}
+
+	for i, testCase := range testCases {
+		conflict, perr := parseMirrorConflict(testCase.conflict)
+		if perr != nil {
+			t.Fatalf("Test %d: unexpected parse error: %v", i+1, perr)
+		}
+		overwrite, perr := conflict.resolve(testCase.source, testCase.target)
+		if testCase.expectErr != (perr != nil) {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.expectErr, perr)
+		}
+		if overwrite != testCase.overwrite {
+			t.Fatalf("Test %d: expected overwrite %v, got %v", i+1, testCase.overwrite, overwrite)
+		}
+	}
+
+	if _, perr := parseMirrorConflict("oldest-wins"); perr == nil {
+		t.Fatal("expected an error for an unknown strategy")
+	}
+}
@@ -20,6 +20,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
 	"math/rand"
 	"net/http"
 	"path"
@@ -66,6 +67,10 @@ var (
 			Name:  "remove",
 			Usage: "remove extraneous object(s) on target",
 		},
+		cli.BoolFlag{
+			Name:  "delete-excluded",
+			Usage: "with --remove, also remove object(s) on target that match --exclude patterns",
+		},
 		cli.StringFlag{
 			Name:  "region",
 			Usage: "specify region when creating new bucket(s) on target",
@@ -75,6 +80,14 @@ var (
 			Name:  "preserve, a",
 			Usage: "preserve file(s)/object(s) attributes and bucket(s) policy/locking configuration(s) on target bucket(s)",
 		},
+		cli.BoolFlag{
+			Name:  "preserve-all",
+			Usage: "same as --preserve, also copying object tags, retention and legal hold",
+		},
+		cli.StringFlag{
+			Name:  "preserve-attrs",
+			Usage: "comma separated object attributes to copy (values: `tags`, `retention`, `legalhold`)",
+		},
 		cli.BoolFlag{
 			Name:  "md5",
 			Usage: "force all upload(s) to calculate md5sum checksum",
@@ -120,6 +133,48 @@ var (
 			Name:  "monitoring-address",
 			Usage: "if specified, a new prometheus endpoint will be created to report mirroring activity. (eg: localhost:8081)",
 		},
+		cli.StringSliceFlag{
+			Name:  "include-tag",
+			Usage: "mirror only objects carrying the tag key[=value], can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude-tag",
+			Usage: "skip objects carrying the tag key[=value], can be repeated",
+		},
+		cli.BoolFlag{
+			Name:  "preflight",
+			Usage: "verify required permissions on source and target before mirroring",
+		},
+		checksumFlag,
+		maxOpsFlag,
+		cli.StringFlag{
+			Name:  "watch-state",
+			Usage: "name of a state kept in the mc config folder, letting a restarted --watch resume without listing the target",
+		},
+		cli.BoolFlag{
+			Name:  "two-way",
+			Usage: "propagate changes in both directions since the last two-way mirror, reporting conflicts",
+		},
+		cli.StringFlag{
+			Name:  "state-file",
+			Usage: "file recording the last two-way mirror, defaults to a file in the mc config folder",
+		},
+		cli.StringFlag{
+			Name:  "state-db",
+			Usage: "name of a local database recording mirrored objects, later runs with the same name only list the source and resume an interrupted listing",
+		},
+		cli.StringFlag{
+			Name:  "report",
+			Usage: "write a JSON report of the transferred, skipped, deleted and failed objects to a file",
+		},
+		cli.StringFlag{
+			Name:  "conflict",
+			Usage: "strategy for objects differing on target with --overwrite: 'newer-wins', 'larger-wins', 'etag', 'skip' or 'fail'",
+		},
+		cli.StringFlag{
+			Name:  "compare",
+			Usage: "detect changed objects of the same size by 'size', 'mtime', 'etag' or 'checksum' (default: mtime)",
+		},
 	}
 )
 
@@ -130,7 +185,7 @@ var mirrorCmd = cli.Command{
 	Action:       mainMirror,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(mirrorFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(append(append(append(append(append(append(append(mirrorFlags, excludeDefaultFlags...), sizeFilterFlags...), transferLimitFlags...), cpuLimitFlags...), walkFlags...), streamFlags...), multipartFlags...), compressFlags...), retryFlags...), ioFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -197,6 +252,78 @@ EXAMPLES:
   16. Cross mirror between sites in a active-active deployment.
       Site-A: {{.Prompt}} {{.HelpName}} --active-active siteA siteB
       Site-B: {{.Prompt}} {{.HelpName}} --active-active siteB siteA
+
+  17. Mirror a bucket but never copy objects tagged with "retention=legal".
+      {{.Prompt}} {{.HelpName}} --exclude-tag "retention=legal" s3/photos/ play/archive/
+
+  18. Verify the credentials allow listing and reading the source and writing, tagging and removing
+      objects on the target before mirroring.
+      {{.Prompt}} {{.HelpName}} --preflight --remove s3/photos/ play/archive/
+
+  19. Mirror a local folder to a bucket without using more than 50MiB/s of upload bandwidth.
+      {{.Prompt}} {{.HelpName}} --limit-upload 50MiB/s ~/backups/ play/backups/
+
+  20. Synchronize a local folder and a bucket in both directions, objects changed on both sides since
+      the last run are reported as conflicts and left untouched. This experimental feature must be
+      enabled first with 'mc config features enable two-way-mirror'.
+      {{.Prompt}} {{.HelpName}} --two-way --remove ~/documents/ play/documents/
+
+  21. Mirror a bucket to another site through a host with little memory, objects are streamed
+      without touching the local disk using at most 4 upload threads buffering parts of 8MiB each.
+      {{.Prompt}} {{.HelpName}} --part-size 8MiB s3/mybucket/ play/mybucket/
+
+  22. Mirror a local folder to a bucket, having the server verify the MD5 checksum of each uploaded part.
+      {{.Prompt}} {{.HelpName}} --checksum md5 ~/photos/ play/photos/
+
+  23. Mirror a large bucket every night, comparing the source listing against the objects mirrored by
+      the previous runs instead of listing the target again. An interrupted run resumes the listing where it stopped.
+      {{.Prompt}} {{.HelpName}} --state-db nightly-photos --remove s3/photos/ play/photos/
+
+  24. Mirror a locked bucket to another site, copying the tags, retention and legal hold of each object.
+      {{.Prompt}} {{.HelpName}} --preserve-all s3/records/ play/records/
+
+  25. Mirror a bucket to another site, copying the tags of each object.
+      {{.Prompt}} {{.HelpName}} --preserve-attrs tags s3/photos/ play/photos/
+
+  26. Mirror a folder from a host with little memory, uploading each object in 8MiB parts, 2 at a time.
+      {{.Prompt}} {{.HelpName}} --part-size 8MiB --concurrent-parts 2 ~/photos/ play/photos/
+
+  27. Mirror a bucket, overwriting objects on the target only when the source copy is newer.
+      {{.Prompt}} {{.HelpName}} --overwrite --conflict newer-wins s3/photos/ play/photos/
+
+  28. Mirror a folder compressing with gzip the log and JSON files, except the audit logs.
+      {{.Prompt}} {{.HelpName}} --compress gzip --compress-extensions .log --compress-extensions .json --compress-exclude "audit-*" ~/data/ play/data/
+
+  29. Keep mirroring a bucket. When the command is restarted, the pending changes are replayed and the source
+      is compared against the objects mirrored so far instead of the target, including the removals with --remove.
+      {{.Prompt}} {{.HelpName}} --watch --watch-state photos-dr s3/photos/ play/photos/
+
+  30. Mirror a bucket from a production cluster, sending at most 200 requests per second.
+      {{.Prompt}} {{.HelpName}} --max-ops 200 s3/photos/ play/photos/
+
+  31. Mirror a bucket from a CI job, failing fast on the first error of an object.
+      {{.Prompt}} {{.HelpName}} --retry 0 s3/artifacts/ play/artifacts/
+
+  32. Mirror a bucket without its temporary files, removing temporary files already present on the target.
+      {{.Prompt}} {{.HelpName}} --remove --exclude "*.tmp" --delete-excluded s3/data/ play/data/
+
+  33. Mirror a bucket on a shared 128-core host without using more than 16 CPUs.
+      {{.Prompt}} {{.HelpName}} --max-cpu 16 s3/data/ play/data/
+
+  34. Mirror only the small metadata files of a bucket, smaller than 1MiB.
+      {{.Prompt}} {{.HelpName}} --smaller-than 1MiB s3/media/ play/media-index/
+
+  35. Mirror a bucket and archive a report of every transferred, skipped, deleted and failed object.
+      {{.Prompt}} {{.HelpName}} --remove --report mirror-2021-11-20.json s3/records/ play/records/
+
+  36. Mirror a local folder holding tens of millions of files, reading 32 folders in parallel.
+      {{.Prompt}} {{.HelpName}} --walk-workers 32 /mnt/archive/ s3/archive/
+
+  37. Mirror a local folder, hashing the files to overwrite the objects whose content changed without a change in size.
+      {{.Prompt}} {{.HelpName}} --overwrite --compare checksum ~/photos/ s3/photos/
+
+  38. Mirror a shared drive without its hidden files, and remove the ones mirrored before from the target.
+      {{.Prompt}} {{.HelpName}} --remove --exclude-hidden --exclude-system --delete-excluded /mnt/shared/ s3/shared/
 `,
 }
 
@@ -265,6 +392,7 @@ type mirrorMessage struct {
 	Size       int64  `json:"size"`
 	TotalCount int64  `json:"totalCount"`
 	TotalSize  int64  `json:"totalSize"`
+	Event      string `json:"event,omitempty"`
 }
 
 // String colorized mirror message
@@ -459,14 +587,23 @@ func (mj *mirrorJob) doMirror(ctx context.Context, sURLs URLs) URLs {
 		Size:       length,
 		TotalCount: sURLs.TotalCount,
 		TotalSize:  sURLs.TotalSize,
+		Event:      transferEventName("start"),
 	})
 	sURLs.MD5 = mj.opts.md5
 	sURLs.DisableMultipart = mj.opts.disableMultipart
 
 	now := time.Now()
-	ret := mirrorSourceToTargetURL(ctx, sURLs, mj.status, mj.opts.encKeyDB, mj.opts.isOverwrite)
+	progress := newObjectTransfer(sURLs, mj.status)
+	ret := retryTransfer(ctx, sURLs, progress, mj.status.PrintMsg, func(progress io.Reader) URLs {
+		return mirrorSourceToTargetURL(ctx, sURLs, progress, mj.opts.encKeyDB, mj.opts.isOverwrite)
+	})
+	if ret.Error == nil && !mj.opts.preserveAttrs.isEmpty() {
+		ret.Error = preserveObjectAttrs(ctx, sURLs, mj.opts.preserveAttrs)
+	}
+	transferDone(progress, ret.Error)
+	ret.duration = time.Since(now)
 	if ret.Error == nil {
-		durationMs := time.Since(now) / time.Millisecond
+		durationMs := ret.duration / time.Millisecond
 		mirrorReplicationDurations.With(prometheus.Labels{"object_size": convertSizeToTag(sURLs.SourceContent.Size)}).Observe(float64(durationMs))
 	}
 	return ret
@@ -482,6 +619,8 @@ func (mj *mirrorJob) monitorMirrorStatus() (errDuringMirror bool) {
 		// Update prometheus fields
 		mirrorTotalOps.Inc()
 
+		mj.opts.report.record(sURLs)
+
 		if sURLs.Error != nil {
 			mirrorFailedOps.Inc()
 			switch {
@@ -511,6 +650,14 @@ func (mj *mirrorJob) monitorMirrorStatus() (errDuringMirror bool) {
 			}
 		}
 
+		if sURLs.Error == nil && mj.opts.stateDB != nil && !mj.opts.isFake {
+			if sURLs.SourceContent != nil {
+				mj.opts.stateDB.recordSource(sURLs.SourceContent)
+			} else if sURLs.TargetContent != nil {
+				mj.opts.stateDB.forgetTarget(sURLs.TargetContent)
+			}
+		}
+
 		if sURLs.SourceContent != nil {
 			mirrorTotalUploadedBytes.Add(float64(sURLs.SourceContent.Size))
 		} else if sURLs.TargetContent != nil {
@@ -591,9 +738,17 @@ func (mj *mirrorJob) watchMirrorEvents(ctx context.Context, events []EventInfo)
 				// to avoid copying it.
 				continue
 			}
-			mj.parallel.queueTask(func() URLs {
+			matched, err := mj.opts.tagFilter.matchContent(ctx, sourceAlias, mirrorURL.SourceContent)
+			if err != nil {
+				mj.statusCh <- mirrorURL.WithError(err)
+				continue
+			}
+			if !matched {
+				continue
+			}
+			mj.parallel.queueTask(mj.watchTask(event, func() URLs {
 				return mj.doMirrorWatch(ctx, targetPath, tgtSSE, mirrorURL)
-			}, mirrorURL.SourceContent.Size)
+			}), mirrorURL.SourceContent.Size)
 		} else if event.Type == notification.ObjectRemovedDelete {
 			if strings.Contains(event.UserAgent, uaMirrorAppName) {
 				continue
@@ -610,9 +765,9 @@ func (mj *mirrorJob) watchMirrorEvents(ctx context.Context, events []EventInfo)
 			mirrorURL.TotalCount = mj.status.GetCounts()
 			mirrorURL.TotalSize = mj.status.Get()
 			if mirrorURL.TargetContent != nil && mj.opts.isRemove && mj.opts.activeActive {
-				mj.parallel.queueTask(func() URLs {
+				mj.parallel.queueTask(mj.watchTask(event, func() URLs {
 					return mj.doRemove(ctx, mirrorURL)
-				}, 0)
+				}), 0)
 			}
 		} else if event.Type == notification.BucketCreatedAll {
 			mirrorURL := URLs{
@@ -637,8 +792,27 @@ func (mj *mirrorJob) watchMirrorEvents(ctx context.Context, events []EventInfo)
 	}
 }
 
+// watchTask records the event in the watch state until task mirrored it.
+func (mj *mirrorJob) watchTask(event EventInfo, task func() URLs) func() URLs {
+	if mj.opts.watchState == nil || mj.opts.isFake {
+		return task
+	}
+	key := mj.opts.watchState.queued(event)
+	return func() URLs {
+		urls := task()
+		if urls.Error == nil || isErrIgnored(urls.Error) {
+			mj.opts.watchState.done(key)
+		}
+		return urls
+	}
+}
+
 // this goroutine will watch for notifications, and add modified objects to the queue
 func (mj *mirrorJob) watchMirror(ctx context.Context, stopParallel func()) {
+	// Replay the events left pending when a previous watch stopped.
+	if mj.opts.watchState != nil && !mj.opts.isFake {
+		mj.watchMirrorEvents(ctx, mj.opts.watchState.pendingEvents())
+	}
 	for {
 		select {
 		case events, ok := <-mj.watcher.Events():
@@ -696,9 +870,24 @@ func (mj *mirrorJob) startMirror(ctx context.Context, cancelMirror context.Cance
 
 			if sURLs.SourceContent != nil {
 				if isOlder(sURLs.SourceContent.Time, mj.opts.olderThan) {
+					mj.opts.report.skip(sURLs.SourceAlias, sURLs.SourceContent, "older than --older-than")
 					continue
 				}
 				if isNewer(sURLs.SourceContent.Time, mj.opts.newerThan) {
+					mj.opts.report.skip(sURLs.SourceAlias, sURLs.SourceContent, "newer than --newer-than")
+					continue
+				}
+				if !mj.opts.sizeFilter.match(sURLs.SourceContent.Size) {
+					mj.opts.report.skip(sURLs.SourceAlias, sURLs.SourceContent, "size filtered")
+					continue
+				}
+				matched, err := mj.opts.tagFilter.matchContent(ctx, sURLs.SourceAlias, sURLs.SourceContent)
+				if err != nil {
+					mj.statusCh <- sURLs.WithError(err)
+					continue
+				}
+				if !matched {
+					mj.opts.report.skip(sURLs.SourceAlias, sURLs.SourceContent, "tag filtered")
 					continue
 				}
 			}
@@ -716,9 +905,13 @@ func (mj *mirrorJob) startMirror(ctx context.Context, cancelMirror context.Cance
 			sURLs.TotalSize = mj.status.Get()
 
 			if sURLs.SourceContent != nil {
-				mj.parallel.queueTask(func() URLs {
+				task := func() URLs {
 					return mj.doMirror(ctx, sURLs)
-				}, sURLs.SourceContent.Size)
+				}
+				if mj.opts.watchState != nil {
+					task = mj.watchTask(mirrorWatchEvent(sURLs.SourceAlias, sURLs.SourceContent), task)
+				}
+				mj.parallel.queueTask(task, sURLs.SourceContent.Size)
 			} else if sURLs.TargetContent != nil && mj.opts.isRemove {
 				mj.parallel.queueTask(func() URLs {
 					return mj.doRemove(ctx, sURLs)
@@ -878,30 +1071,71 @@ func runMirror(ctx context.Context, cancelMirror context.CancelFunc, srcURL, dst
 	isRemove := cli.Bool("remove")
 
 	// preserve is also expected to be overwritten if necessary
-	isMetadata := cli.Bool("a") || len(userMetadata) > 0
+	isMetadata := cli.Bool("a") || cli.Bool("preserve-all") || len(userMetadata) > 0
 	
 
+	tagFilter, err := newObjectTagFilter(cli.StringSlice("include-tag"), cli.StringSlice("exclude-tag"))
+	fatalIf(err, "Unable to parse tag filters.")
+
+	sizeFilter, err := newObjectSizeFilter(cli.String("larger-than"), cli.String("smaller-than"))
+	fatalIf(err, "Unable to parse size filters.")
+
+	preserveAttrs, err := parseMirrorPreserveAttrs(cli.String("preserve-attrs"), cli.Bool("preserve-all"))
+	fatalIf(err, "Unable to parse object attributes to preserve.")
+
+	conflict, err := parseMirrorConflict(cli.String("conflict"))
+	fatalIf(err, "Unable to parse the conflict strategy.")
+
+	compare, err := parseMirrorCompare(cli.String("compare"))
+	fatalIf(err, "Unable to parse the comparison mode.")
+
 	mopts := mirrorOptions{
 		isFake:           cli.Bool("fake"),
 		isRemove:         isRemove,
+		deleteExcluded:   cli.Bool("delete-excluded"),
 		isOverwrite:      isOverwrite,
 		isWatch:          isWatch,
 		isMetadata:       isMetadata,
 		md5:              cli.Bool("md5"),
 		disableMultipart: cli.Bool("disable-multipart"),
-		excludeOptions:   cli.StringSlice("exclude"),
+		excludeOptions:   append(cli.StringSlice("exclude"), wholePathPatterns(defaultExcludePatterns(cli))...),
+		tagFilter:        tagFilter,
+		sizeFilter:       sizeFilter,
 		olderThan:        cli.String("older-than"),
 		newerThan:        cli.String("newer-than"),
 		storageClass:     cli.String("storage-class"),
 		userMetadata:     userMetadata,
 		encKeyDB:         encKeyDB,
 		activeActive:     isWatch,
+		preserveAttrs:    preserveAttrs,
+		conflict:         conflict,
+		compare:          compare,
+	}
+
+	if path := cli.String("report"); path != "" {
+		mopts.report, err = newMirrorReport(path, srcURL, dstURL, mopts.isFake)
+		fatalIf(err, "Unable to create the mirror report `"+path+"`.")
+	}
+
+	if name := cli.String("state-db"); name != "" {
+		mopts.stateDB, err = loadMirrorStateDB(name, srcURL, dstURL, mopts.isFake)
+		fatalIf(err, "Unable to load the mirror state database `"+name+"`.")
+	}
+
+	if name := cli.String("watch-state"); name != "" {
+		mopts.watchState, err = loadMirrorWatchState(name, srcURL, dstURL, mopts.isFake)
+		fatalIf(err, "Unable to load the mirror watch state `"+name+"`.")
+		// The mirrored objects are recorded as with --state-db.
+		mopts.stateDB = mopts.watchState.objects
+		saveCtx, cancelSave := context.WithCancel(ctx)
+		defer cancelSave()
+		go mopts.watchState.saveEvery(saveCtx, 5*time.Second)
 	}
 
 	// Create a new mirror job and execute it
 	mj := newMirrorJob(srcURL, dstURL, mopts)
 
-	preserve := cli.Bool("preserve")
+	preserve := cli.Bool("preserve") || cli.Bool("preserve-all")
 
 	createDstBuckets := dstClt.GetURL().Type == objectStorage && dstClt.GetURL().Path == string(dstClt.GetURL().Separator)
 	mirrorSrcBuckets := srcClt.GetURL().Type == objectStorage && srcClt.GetURL().Path == string(srcClt.GetURL().Separator)
@@ -986,7 +1220,15 @@ func runMirror(ctx context.Context, cancelMirror context.CancelFunc, srcURL, dst
 		}
 	}
 
-	return mj.mirror(ctx, cancelMirror)
+	errorDetected := mj.mirror(ctx, cancelMirror)
+	fatalIf(mj.opts.report.close(), "Unable to write the mirror report `"+cli.String("report")+"`.")
+	if mj.opts.stateDB != nil {
+		errorIf(mj.opts.stateDB.close(), "Unable to save the mirror state database.")
+	}
+	if mj.opts.watchState != nil && !mj.opts.isFake {
+		errorIf(mj.opts.watchState.save(), "Unable to save the mirror watch state.")
+	}
+	return errorDetected
 }
 
 // Main entry point for mirror command.
@@ -1001,9 +1243,28 @@ func mainMirror(cliCtx *cli.Context) error {
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
+	setCPULimits(cliCtx)
+	setWalkWorkers(cliCtx)
+	setTransferLimits(cliCtx)
+	setStreamOptions(cliCtx)
+	setMultipartOptions(cliCtx)
+	setChecksumOptions(cliCtx)
+	setCompressOptions(cliCtx)
+	setOpsLimit(cliCtx)
+	setRetryPolicy(cliCtx)
+	setTransferEvents()
+
 	// check 'mirror' cli arguments.
 	srcURL, tgtURL := checkMirrorSyntax(ctx, cliCtx, encKeyDB)
 
+	if cliCtx.Bool("two-way") {
+		return runTwoWayMirror(ctx, srcURL, tgtURL, cliCtx, encKeyDB)
+	}
+
+	if cliCtx.Bool("preflight") {
+		runPreflight(ctx, []string{srcURL}, tgtURL, true, encKeyDB)
+	}
+
 	if prometheusAddress := cliCtx.String("monitoring-address"); prometheusAddress != "" {
 		http.Handle("/metrics", promhttp.Handler())
 		go func() {
@@ -1026,6 +1287,7 @@ func mainMirror(cliCtx *cli.Context) error {
 				time.Sleep(time.Duration(r.Float64() * float64(2*time.Second)))
 				continue
 			}
+			printChecksumSummary()
 			if errorDetected {
 				return exitStatus(globalErrorExitStatus)
 			}
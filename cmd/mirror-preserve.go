@@ -0,0 +1,129 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+)
+
+// mirrorPreserveAttrs lists the object attributes copied by mirror
+// along with the object content.
+type mirrorPreserveAttrs struct {
+	tags, retention, legalHold bool
+}
+
+func (p mirrorPreserveAttrs) isEmpty() bool {
+	return !p.tags && !p.retention && !p.legalHold
+}
+
+// parseMirrorPreserveAttrs parses a comma separated list of object
+// attributes, all of them are preserved with --preserve-all.
+func parseMirrorPreserveAttrs(list string, all bool) (p mirrorPreserveAttrs, err *probe.Error) {
+	if all {
+		return mirrorPreserveAttrs{tags: true, retention: true, legalHold: true}, nil
+	}
+	for _, attr := range strings.Split(list, ",") {
+		switch strings.ToLower(strings.TrimSpace(attr)) {
+		case "":
+		case "tags":
+			p.tags = true
+		case "retention":
+			p.retention = true
+		case "legalhold":
+			p.legalHold = true
+		default:
+			return p, errInvalidArgument().Trace(attr)
+		}
+	}
+	return p, nil
+}
+
+// isNoObjectLockErr returns true when the object has no retention or
+// legal hold, or its bucket has object locking disabled.
+func isNoObjectLockErr(err *probe.Error) bool {
+	switch minio.ToErrorResponse(err.ToGoError()).Code {
+	case "NoSuchObjectLockConfiguration", "ObjectLockConfigurationNotFoundError", "InvalidRequest":
+		return true
+	}
+	return false
+}
+
+// preserveObjectAttrs copies the tags, retention and legal hold of the
+// source object to the mirrored object, filesystems have none of them.
+func preserveObjectAttrs(ctx context.Context, sURLs URLs, attrs mirrorPreserveAttrs) *probe.Error {
+	if sURLs.SourceContent.URL.Type != objectStorage || sURLs.TargetContent.URL.Type != objectStorage {
+		return nil
+	}
+	sourceURL := sURLs.SourceContent.URL.String()
+	targetURL := sURLs.TargetContent.URL.String()
+	sourceClnt, err := newClientFromAlias(sURLs.SourceAlias, sourceURL)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	targetClnt, err := newClientFromAlias(sURLs.TargetAlias, targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	versionID := sURLs.SourceContent.VersionID
+
+	if attrs.tags {
+		tagMap, err := sourceClnt.GetTags(ctx, versionID)
+		if err != nil {
+			return err.Trace(sourceURL)
+		}
+		if len(tagMap) > 0 {
+			t, e := tags.MapToObjectTags(tagMap)
+			if e != nil {
+				return probe.NewError(e).Trace(sourceURL)
+			}
+			if err = targetClnt.SetTags(ctx, "", t.String()); err != nil {
+				return err.Trace(targetURL)
+			}
+		}
+	}
+
+	if attrs.retention {
+		mode, until, err := sourceClnt.GetObjectRetention(ctx, versionID)
+		if err != nil && !isNoObjectLockErr(err) {
+			return err.Trace(sourceURL)
+		}
+		if err == nil && mode != "" && !until.IsZero() {
+			if err = targetClnt.PutObjectRetention(ctx, "", mode, until, false); err != nil {
+				return err.Trace(targetURL)
+			}
+		}
+	}
+
+	if attrs.legalHold {
+		hold, err := sourceClnt.GetObjectLegalHold(ctx, versionID)
+		if err != nil && !isNoObjectLockErr(err) {
+			return err.Trace(sourceURL)
+		}
+		if err == nil && hold == minio.LegalHoldEnabled {
+			if err = targetClnt.PutObjectLegalHold(ctx, "", hold); err != nil {
+				return err.Trace(targetURL)
+			}
+		}
+	}
+	return nil
+}
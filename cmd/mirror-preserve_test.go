@@ -0,0 +1,44 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "testing"
+
+func TestParseMirrorPreserveAttrs(t *testing.T) {
+	testCases := []struct {
+		list     string
+		all      bool
+		expected mirrorPreserveAttrs
+		success  bool
+	}{
+		{"", false, mirrorPreserveAttrs{}, true},
+		{"", true, mirrorPreserveAttrs{tags: true, retention: true, legalHold: true}, true},
+		{"tags", false, mirrorPreserveAttrs{tags: true}, true},
+		{"retention, LegalHold", false, mirrorPreserveAttrs{retention: true, legalHold: true}, true},
+		{"tags,acl", false, mirrorPreserveAttrs{}, false},
+	}
+	for i, testCase := range testCases {
+		attrs, err := parseMirrorPreserveAttrs(testCase.list, testCase.all)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: expected success %v, got error %v", i+1, testCase.success, err)
+		}
+		if err == nil && attrs != testCase.expected {
+			t.Errorf("Test %d: expected %+v, got %+v", i+1, testCase.expected, attrs)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Status of the objects listed in a mirror report.
+const (
+	mirrorReportTransferred = "transferred"
+	mirrorReportSkipped     = "skipped"
+	mirrorReportDeleted     = "deleted"
+	mirrorReportFailed      = "failed"
+)
+
+// mirrorReportObject is an object listed in a mirror report.
+type mirrorReportObject struct {
+	Status     string `json:"status"`
+	Source     string `json:"source,omitempty"`
+	Target     string `json:"target,omitempty"`
+	Size       int64  `json:"size"`
+	DurationMs int64  `json:"durationMs,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// mirrorReportTotal counts the objects of a status in a mirror report.
+type mirrorReportTotal struct {
+	Count int64 `json:"count"`
+	Size  int64 `json:"size"`
+}
+
+// mirrorReport writes the objects transferred, skipped, deleted and
+// failed by a mirror to a JSON file, as they are met so that the
+// report of large mirrors is not held in memory. The file is written
+// under a temporary name and renamed once complete.
+type mirrorReport struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	w       *bufio.Writer
+	objects int64
+	totals  map[string]*mirrorReportTotal
+	err     error
+}
+
+// newMirrorReport starts the report of a mirror from source to target.
+func newMirrorReport(path, source, target string, fake bool) (*mirrorReport, *probe.Error) {
+	path, e := filepath.Abs(path)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	file, e := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	r := &mirrorReport{
+		path: path,
+		file: file,
+		w:    bufio.NewWriter(file),
+		totals: map[string]*mirrorReportTotal{
+			mirrorReportTransferred: {},
+			mirrorReportSkipped:     {},
+			mirrorReportDeleted:     {},
+			mirrorReportFailed:      {},
+		},
+	}
+	r.printf(`{"source":%s,"target":%s,"fake":%t,"startTime":%s,"objects":[`,
+		jsonString(source), jsonString(target), fake, jsonString(UTCNow().Format(time.RFC3339Nano)))
+	if r.err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, probe.NewError(r.err)
+	}
+	return r, nil
+}
+
+// jsonString quotes s as a JSON string.
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+// printf writes to the report, keeping the first error met.
+func (r *mirrorReport) printf(format string, args ...interface{}) {
+	if r.err == nil {
+		_, r.err = fmt.Fprintf(r.w, format, args...)
+	}
+}
+
+// add lists an object in the report, a nil report is ignored.
+func (r *mirrorReport) add(object mirrorReportObject) {
+	if r == nil {
+		return
+	}
+	b, e := json.Marshal(object)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if e != nil && r.err == nil {
+		r.err = e
+	}
+	separator := ","
+	if r.objects == 0 {
+		separator = ""
+	}
+	r.printf("%s\n%s", separator, b)
+	r.objects++
+	r.totals[object.Status].Count++
+	r.totals[object.Status].Size += object.Size
+}
+
+// skip lists a source object skipped for the given reason.
+func (r *mirrorReport) skip(alias string, content *ClientContent, reason string) {
+	if r == nil || content == nil {
+		return
+	}
+	r.add(mirrorReportObject{
+		Status: mirrorReportSkipped,
+		Source: filepath.ToSlash(filepath.Join(alias, content.URL.Path)),
+		Size:   content.Size,
+		Reason: reason,
+	})
+}
+
+// record lists the outcome of a transfer or removal.
+func (r *mirrorReport) record(sURLs URLs) {
+	if r == nil {
+		return
+	}
+	object := mirrorReportObject{
+		Status:     mirrorReportTransferred,
+		DurationMs: int64(sURLs.duration / time.Millisecond),
+	}
+	if sURLs.SourceContent != nil {
+		object.Source = filepath.ToSlash(filepath.Join(sURLs.SourceAlias, sURLs.SourceContent.URL.Path))
+		object.Size = sURLs.SourceContent.Size
+	} else if sURLs.TargetContent != nil {
+		object.Status = mirrorReportDeleted
+		object.Size = sURLs.TargetContent.Size
+	}
+	if sURLs.TargetContent != nil {
+		object.Target = filepath.ToSlash(filepath.Join(sURLs.TargetAlias, sURLs.TargetContent.URL.Path))
+	}
+	if sURLs.Error != nil {
+		object.Status = mirrorReportFailed
+		if sURLs.SourceContent != nil && isErrIgnored(sURLs.Error) {
+			object.Status = mirrorReportSkipped
+		}
+		object.Reason = sURLs.Error.ToGoError().Error()
+	}
+	r.add(object)
+}
+
+// close completes the report and moves it to its final name.
+func (r *mirrorReport) close() *probe.Error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	summary, e := json.Marshal(r.totals)
+	if e != nil && r.err == nil {
+		r.err = e
+	}
+	r.printf("\n],\"summary\":%s,\"endTime\":%s}\n", summary, jsonString(UTCNow().Format(time.RFC3339Nano)))
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	if r.err == nil {
+		r.err = r.file.Sync()
+	}
+	if e := r.file.Close(); r.err == nil {
+		r.err = e
+	}
+	if r.err == nil {
+		r.err = os.Rename(r.file.Name(), r.path)
+	}
+	if r.err != nil {
+		os.Remove(r.file.Name())
+		return probe.NewError(r.err)
+	}
+	return nil
+}
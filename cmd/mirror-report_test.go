@@ -0,0 +1,93 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestMirrorReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	report, err := newMirrorReport(path, "src/bucket", "dst/bucket", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, e := os.Stat(path); !os.IsNotExist(e) {
+		t.Fatalf("report should not be written before it is complete, got %v", e)
+	}
+
+	content := func(path string, size int64) *ClientContent {
+		return &ClientContent{URL: *newClientURL(path), Size: size}
+	}
+	report.record(URLs{
+		SourceAlias: "src", SourceContent: content("/bucket/a", 10),
+		TargetAlias: "dst", TargetContent: content("/bucket/a", 0),
+	})
+	report.skip("src", content("/bucket/b", 20), "identical on target")
+	report.record(URLs{TargetAlias: "dst", TargetContent: content("/bucket/c", 30)})
+	report.record(URLs{
+		SourceAlias: "src", SourceContent: content("/bucket/d", 40),
+		TargetAlias: "dst", TargetContent: content("/bucket/d", 0),
+		Error: probe.NewError(errors.New("access denied")),
+	})
+	if err = report.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, e := os.ReadFile(path)
+	if e != nil {
+		t.Fatal(e)
+	}
+	var got struct {
+		Source  string                       `json:"source"`
+		Target  string                       `json:"target"`
+		Objects []mirrorReportObject         `json:"objects"`
+		Summary map[string]mirrorReportTotal `json:"summary"`
+	}
+	if e = json.Unmarshal(data, &got); e != nil {
+		t.Fatalf("invalid report %s: %v", data, e)
+	}
+	expectedObjects := []mirrorReportObject{
+		{Status: mirrorReportTransferred, Source: "src/bucket/a", Target: "dst/bucket/a", Size: 10},
+		{Status: mirrorReportSkipped, Source: "src/bucket/b", Size: 20, Reason: "identical on target"},
+		{Status: mirrorReportDeleted, Target: "dst/bucket/c", Size: 30},
+		{Status: mirrorReportFailed, Source: "src/bucket/d", Target: "dst/bucket/d", Size: 40, Reason: "access denied"},
+	}
+	if !reflect.DeepEqual(got.Objects, expectedObjects) {
+		t.Errorf("expected objects %+v, got %+v", expectedObjects, got.Objects)
+	}
+	expectedSummary := map[string]mirrorReportTotal{
+		mirrorReportTransferred: {Count: 1, Size: 10},
+		mirrorReportSkipped:     {Count: 1, Size: 20},
+		mirrorReportDeleted:     {Count: 1, Size: 30},
+		mirrorReportFailed:      {Count: 1, Size: 40},
+	}
+	if !reflect.DeepEqual(got.Summary, expectedSummary) {
+		t.Errorf("expected summary %+v, got %+v", expectedSummary, got.Summary)
+	}
+	if got.Source != "src/bucket" || got.Target != "dst/bucket" {
+		t.Errorf("unexpected source %q and target %q", got.Source, got.Target)
+	}
+}
@@ -0,0 +1,475 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// mirrorStateDBVersion is the version of the mirror state database.
+	mirrorStateDBVersion = "2"
+
+	// mirrorStateDBBatch is the number of changes written to the
+	// database in a single transaction.
+	mirrorStateDBBatch = 1000
+)
+
+var (
+	mirrorStateInfoBucket    = []byte("info")
+	mirrorStateObjectsBucket = []byte("objects")
+)
+
+// mirrorStateEntry is the state of a source object.
+type mirrorStateEntry struct {
+	// Pass is the last source listing which found the object.
+	Pass uint64 `json:"pass"`
+	// Fingerprint identifies the content present on the target.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Pending is set while the object is sent to the target.
+	Pending bool `json:"pending,omitempty"`
+}
+
+// mirrorStateDB records the fingerprint of the source objects mirrored
+// by the previous runs of a named mirror session. Once populated, the
+// next runs only list the source and copy the objects whose fingerprint
+// changed, without listing the target at all.
+//
+// S3 cannot list the objects changed since a point in time, so each
+// pass still lists the whole source. The position of the listing is
+// recorded, an interrupted run resumes the listing where it stopped,
+// and the objects missing from the source are only removed once a
+// listing completed.
+type mirrorStateDB struct {
+	mu     sync.Mutex
+	db     *bolt.DB
+	dryRun bool
+	closed bool
+	err    *probe.Error
+
+	// pass numbers the source listings, cursor is the last object
+	// listed by an incomplete one.
+	pass   uint64
+	cursor string
+
+	// changes holds the entries not written yet, nil for a removal.
+	changes map[string]*mirrorStateEntry
+
+	sourcePrefix string
+	targetPrefix string
+}
+
+// getMirrorStateDBFile returns the file of a named mirror state database.
+func getMirrorStateDBFile(name string) string {
+	return filepath.Join(mustGetMcConfigDir(), "mirror", "state-"+name+".db")
+}
+
+// loadMirrorStateDB opens the state database of a mirror session, or
+// creates an empty one for a new session. Changes are never written to
+// the database of a dry run.
+func loadMirrorStateDB(name, sourceURL, targetURL string, dryRun bool) (*mirrorStateDB, *probe.Error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, errInvalidArgument().Trace(name)
+	}
+	file := getMirrorStateDBFile(name)
+	if e := os.MkdirAll(filepath.Dir(file), 0o700); e != nil {
+		return nil, probe.NewError(e)
+	}
+	boltDB, e := bolt.Open(file, 0o600, &bolt.Options{Timeout: time.Second})
+	if e == bolt.ErrTimeout {
+		return nil, probe.NewError(errors.New("the database is used by another mirror")).Trace(file)
+	}
+	if e != nil {
+		return nil, probe.NewError(e).Trace(file)
+	}
+
+	db := &mirrorStateDB{
+		db:      boltDB,
+		dryRun:  dryRun,
+		changes: make(map[string]*mirrorStateEntry),
+	}
+	e = boltDB.Update(func(tx *bolt.Tx) error {
+		info, e := tx.CreateBucketIfNotExists(mirrorStateInfoBucket)
+		if e != nil {
+			return e
+		}
+		if _, e = tx.CreateBucketIfNotExists(mirrorStateObjectsBucket); e != nil {
+			return e
+		}
+		if info.Get([]byte("version")) == nil {
+			for key, value := range map[string]string{"version": mirrorStateDBVersion, "source": sourceURL, "target": targetURL} {
+				if e = info.Put([]byte(key), []byte(value)); e != nil {
+					return e
+				}
+			}
+			return nil
+		}
+		if version := string(info.Get([]byte("version"))); version != mirrorStateDBVersion {
+			return fmt.Errorf("unsupported database version `%s`", version)
+		}
+		if source, target := string(info.Get([]byte("source"))), string(info.Get([]byte("target"))); source != sourceURL || target != targetURL {
+			return fmt.Errorf("the database records a mirror from `%s` to `%s`", source, target)
+		}
+		if pass := info.Get([]byte("pass")); pass != nil {
+			if db.pass, e = strconv.ParseUint(string(pass), 10, 64); e != nil {
+				return e
+			}
+		}
+		db.cursor = string(info.Get([]byte("cursor")))
+		return nil
+	})
+	if e != nil {
+		boltDB.Close()
+		return nil, probe.NewError(e).Trace(file)
+	}
+
+	_, db.sourcePrefix, _ = mustExpandAlias(sourceURL)
+	if separator := string(newClientURL(db.sourcePrefix).Separator); !strings.HasSuffix(db.sourcePrefix, separator) {
+		db.sourcePrefix += separator
+	}
+	_, db.targetPrefix, _ = mustExpandAlias(targetURL)
+	if separator := string(newClientURL(db.targetPrefix).Separator); !strings.HasSuffix(db.targetPrefix, separator) {
+		db.targetPrefix += separator
+	}
+	return db, nil
+}
+
+// isEmpty returns true until a first mirror populated the database.
+func (db *mirrorStateDB) isEmpty() bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if len(db.changes) > 0 {
+		return false
+	}
+	empty := true
+	db.db.View(func(tx *bolt.Tx) error {
+		key, _ := tx.Bucket(mirrorStateObjectsBucket).Cursor().First()
+		empty = key == nil
+		return nil
+	})
+	return empty
+}
+
+// lookup returns the entry of an object, the caller holds the lock.
+func (db *mirrorStateDB) lookup(key string) *mirrorStateEntry {
+	if entry, ok := db.changes[key]; ok {
+		return entry
+	}
+	var entry *mirrorStateEntry
+	db.db.View(func(tx *bolt.Tx) error {
+		if data := tx.Bucket(mirrorStateObjectsBucket).Get([]byte(key)); data != nil {
+			entry = &mirrorStateEntry{}
+			if json.Unmarshal(data, entry) != nil {
+				entry = nil
+			}
+		}
+		return nil
+	})
+	return entry
+}
+
+// change buffers the new entry of an object, the caller holds the lock.
+func (db *mirrorStateDB) change(key string, entry *mirrorStateEntry) {
+	if key == "" {
+		return
+	}
+	db.changes[key] = entry
+	if len(db.changes) >= mirrorStateDBBatch && db.err == nil {
+		db.err = db.flush()
+	}
+}
+
+// flush writes the buffered changes and the listing position in a
+// single transaction, the caller holds the lock.
+func (db *mirrorStateDB) flush() *probe.Error {
+	if db.dryRun {
+		return nil
+	}
+	e := db.db.Update(func(tx *bolt.Tx) error {
+		objects := tx.Bucket(mirrorStateObjectsBucket)
+		for key, entry := range db.changes {
+			if entry == nil {
+				if e := objects.Delete([]byte(key)); e != nil {
+					return e
+				}
+				continue
+			}
+			data, e := json.Marshal(entry)
+			if e != nil {
+				return e
+			}
+			if e = objects.Put([]byte(key), data); e != nil {
+				return e
+			}
+		}
+		info := tx.Bucket(mirrorStateInfoBucket)
+		if e := info.Put([]byte("pass"), []byte(strconv.FormatUint(db.pass, 10))); e != nil {
+			return e
+		}
+		return info.Put([]byte("cursor"), []byte(db.cursor))
+	})
+	if e != nil {
+		return probe.NewError(e)
+	}
+	db.changes = make(map[string]*mirrorStateEntry)
+	return nil
+}
+
+// unchanged returns true if the source object was mirrored as is.
+func (db *mirrorStateDB) unchanged(key string, content *ClientContent) bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	entry := db.lookup(key)
+	return entry != nil && !entry.Pending && entry.Fingerprint == twoWayFingerprint(content)
+}
+
+// beginListing starts a new pass over the source, or resumes the
+// incomplete one. It returns the position to resume the listing from.
+func (db *mirrorStateDB) beginListing() string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.cursor == "" {
+		db.pass++
+	}
+	return db.cursor
+}
+
+// listed records an object found by the current listing, pending when
+// it is sent to the target.
+func (db *mirrorStateDB) listed(key string, content *ClientContent, pending bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	entry := &mirrorStateEntry{}
+	if old := db.lookup(key); old != nil {
+		*entry = *old
+	}
+	entry.Pass = db.pass
+	entry.Pending = entry.Pending || pending
+	db.cursor = content.URL.Path
+	db.change(key, entry)
+}
+
+// endListing records the current listing is complete.
+func (db *mirrorStateDB) endListing() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.cursor = ""
+}
+
+// pendingKeys returns the objects of the current listing which were
+// sent to the target but not mirrored yet.
+func (db *mirrorStateDB) pendingKeys() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var keys []string
+	for key, entry := range db.changes {
+		if entry != nil && entry.Pending && entry.Pass == db.pass {
+			keys = append(keys, key)
+		}
+	}
+	db.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(mirrorStateObjectsBucket).ForEach(func(key, data []byte) error {
+			if _, ok := db.changes[string(key)]; ok {
+				return nil
+			}
+			var entry mirrorStateEntry
+			if json.Unmarshal(data, &entry) == nil && entry.Pending && entry.Pass == db.pass {
+				keys = append(keys, string(key))
+			}
+			return nil
+		})
+	})
+	return keys
+}
+
+// staleKeys returns up to limit objects, sorted after the given key,
+// which were not found by the current listing.
+func (db *mirrorStateDB) staleKeys(after string, limit int) []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	var keys []string
+	db.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(mirrorStateObjectsBucket).Cursor()
+		key, data := cursor.Seek([]byte(after))
+		if key != nil && string(key) == after {
+			key, data = cursor.Next()
+		}
+		for ; key != nil && len(keys) < limit; key, data = cursor.Next() {
+			entry, ok := db.changes[string(key)]
+			if !ok {
+				entry = &mirrorStateEntry{}
+				if json.Unmarshal(data, entry) != nil {
+					continue
+				}
+			}
+			if entry != nil && entry.Pass < db.pass {
+				keys = append(keys, string(key))
+			}
+		}
+		return nil
+	})
+	return keys
+}
+
+// recordSource records a source object present on the target.
+func (db *mirrorStateDB) recordSource(content *ClientContent) {
+	key := strings.TrimPrefix(content.URL.String(), db.sourcePrefix)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.change(key, &mirrorStateEntry{Pass: db.pass, Fingerprint: twoWayFingerprint(content)})
+}
+
+// forgetTarget forgets a target object removed by the mirror.
+func (db *mirrorStateDB) forgetTarget(content *ClientContent) {
+	db.forget(strings.TrimPrefix(content.URL.String(), db.targetPrefix))
+}
+
+func (db *mirrorStateDB) forget(key string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.change(key, nil)
+}
+
+// sync writes the buffered changes.
+func (db *mirrorStateDB) sync() *probe.Error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed || db.err != nil {
+		return db.err
+	}
+	return db.flush()
+}
+
+// close writes the remaining changes and closes the database.
+func (db *mirrorStateDB) close() *probe.Error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil
+	}
+	db.closed = true
+	err := db.err
+	if err == nil {
+		err = db.flush()
+	}
+	if e := db.db.Close(); e != nil && err == nil {
+		err = probe.NewError(e)
+	}
+	return err
+}
+
+// deltaSourceStateDB lists the source only and sends the objects changed
+// since the previous mirror, and the objects removed from the source
+// when removal is requested. An interrupted listing is resumed, with the
+// objects it sent but did not mirror.
+func deltaSourceStateDB(ctx context.Context, sourceURL, targetURL string, opts mirrorOptions, URLsCh chan<- URLs) {
+	defer close(URLsCh)
+
+	db := opts.stateDB
+	sourceAlias, _, _ := mustExpandAlias(sourceURL)
+	targetAlias, _, _ := mustExpandAlias(targetURL)
+
+	sourceClnt, err := newClientFromAlias(sourceAlias, db.sourcePrefix)
+	if err != nil {
+		URLsCh <- URLs{Error: err.Trace(sourceAlias, db.sourcePrefix)}
+		return
+	}
+
+	sendCopy := func(key string, content *ClientContent) {
+		URLsCh <- URLs{
+			SourceAlias:   sourceAlias,
+			SourceContent: content,
+			TargetAlias:   targetAlias,
+			TargetContent: &ClientContent{URL: *newClientURL(urlJoinPath(db.targetPrefix, key))},
+		}
+	}
+
+	// Listings ignoring the resume position send the objects again.
+	resent := make(map[string]struct{})
+	startAfter := db.beginListing()
+	if startAfter != "" {
+		for _, key := range db.pendingKeys() {
+			clnt, err := newClientFromAlias(sourceAlias, urlJoinPath(db.sourcePrefix, key))
+			if err == nil {
+				var content *ClientContent
+				if content, err = clnt.Stat(ctx, StatOptions{}); err == nil {
+					resent[key] = struct{}{}
+					sendCopy(key, content)
+					continue
+				}
+			}
+			// An object removed since is removed by the next listing.
+			switch err.ToGoError().(type) {
+			case ObjectMissing, PathNotFound:
+			default:
+				URLsCh <- URLs{Error: err.Trace(sourceURL, key), ErrorCond: differInUnknown}
+			}
+		}
+	}
+
+	for content := range sourceClnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone, StartAfter: startAfter}) {
+		if content.Err != nil {
+			// Objects of a failed listing must not be removed.
+			URLsCh <- URLs{Error: content.Err.Trace(sourceURL), ErrorCond: differInUnknown}
+			return
+		}
+		key := strings.TrimPrefix(content.URL.String(), db.sourcePrefix)
+		if _, ok := resent[key]; ok {
+			continue
+		}
+		changed := !matchExcludeOptions(opts.excludeOptions, key) && !db.unchanged(key, content)
+		db.listed(key, content, changed)
+		if changed {
+			sendCopy(key, content)
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	db.endListing()
+
+	for after := ""; ; {
+		keys := db.staleKeys(after, mirrorStateDBBatch)
+		if len(keys) == 0 {
+			return
+		}
+		for _, key := range keys {
+			if !opts.isRemove && !opts.isFake {
+				db.forget(key)
+				continue
+			}
+			URLsCh <- URLs{
+				TargetAlias:   targetAlias,
+				TargetContent: &ClientContent{URL: *newClientURL(urlJoinPath(db.targetPrefix, key))},
+			}
+		}
+		after = keys[len(keys)-1]
+	}
+}
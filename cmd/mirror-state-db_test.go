@@ -0,0 +1,129 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// runDeltaSourceStateDB returns the keys copied and removed by a mirror
+// of the state database.
+func runDeltaSourceStateDB(ctx context.Context, t *testing.T, src, dst string, opts mirrorOptions) (copied, removed []string) {
+	URLsCh := make(chan URLs)
+	go deltaSourceStateDB(ctx, src, dst, opts, URLsCh)
+	for u := range URLsCh {
+		if u.Error != nil {
+			t.Fatal(u.Error)
+		}
+		if u.SourceContent != nil {
+			copied = append(copied, filepath.Base(u.SourceContent.URL.Path))
+			continue
+		}
+		removed = append(removed, filepath.Base(u.TargetContent.URL.Path))
+	}
+	sort.Strings(copied)
+	sort.Strings(removed)
+	return copied, removed
+}
+
+func TestMirrorStateDB(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	src, dst := t.TempDir(), t.TempDir()
+	writeFile := func(name, data string) {
+		if e := ioutil.WriteFile(filepath.Join(src, name), []byte(data), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		writeFile(name, name)
+	}
+
+	db, err := loadMirrorStateDB("test", src, dst, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !db.isEmpty() {
+		t.Fatal("expected a new database to be empty")
+	}
+	opts := mirrorOptions{isRemove: true, stateDB: db}
+	// A first mirror records every object.
+	copied, _ := runDeltaSourceStateDB(context.Background(), t, src, dst, opts)
+	for _, name := range copied {
+		clnt, err := newClient(filepath.Join(src, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := clnt.Stat(context.Background(), StatOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		db.recordSource(content)
+	}
+	if err = db.close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loadMirrorStateDB("test", src, "/other", false); err == nil {
+		t.Fatal("expected a database of another mirror to be refused")
+	}
+
+	writeFile("b", "bb")
+	if e := os.Remove(filepath.Join(src, "c")); e != nil {
+		t.Fatal(e)
+	}
+	if db, err = loadMirrorStateDB("test", src, dst, false); err != nil {
+		t.Fatal(err)
+	}
+	if db.isEmpty() {
+		t.Fatal("expected the database to be saved")
+	}
+	opts.stateDB = db
+	copied, removed := runDeltaSourceStateDB(context.Background(), t, src, dst, opts)
+	if !reflect.DeepEqual(copied, []string{"b"}) || !reflect.DeepEqual(removed, []string{"c"}) {
+		t.Fatalf("expected b to be copied and c to be removed, got %v and %v", copied, removed)
+	}
+	// The copy of b and the removal of c are interrupted, before the
+	// listing completed.
+	db.cursor = filepath.Join(src, "b")
+	if err = db.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if db, err = loadMirrorStateDB("test", src, dst, false); err != nil {
+		t.Fatal(err)
+	}
+	opts.stateDB = db
+	if keys := db.pendingKeys(); !reflect.DeepEqual(keys, []string{"b"}) {
+		t.Fatalf("expected b to be pending, got %v", keys)
+	}
+	copied, removed = runDeltaSourceStateDB(context.Background(), t, src, dst, opts)
+	if !reflect.DeepEqual(copied, []string{"b"}) || !reflect.DeepEqual(removed, []string{"c"}) {
+		t.Fatalf("expected b to be copied and c to be removed again, got %v and %v", copied, removed)
+	}
+	if err = db.close(); err != nil {
+		t.Fatal(err)
+	}
+}
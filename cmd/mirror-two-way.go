@@ -0,0 +1,537 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+// twoWayStateVersion is the version of the two-way mirror state file.
+const twoWayStateVersion = "1"
+
+// twoWayStateSaveInterval is how often the state is saved during a run,
+// so that an interrupted run does not copy the same objects again.
+const twoWayStateSaveInterval = 30 * time.Second
+
+// twoWayObject is the fingerprint of an object on both sides at the
+// last two-way mirror.
+type twoWayObject struct {
+	First  string `json:"first"`
+	Second string `json:"second"`
+}
+
+// twoWayState records the objects in sync between both sides of a
+// two-way mirror, to tell changes from removals on the next run.
+type twoWayState struct {
+	Version string                  `json:"version"`
+	First   string                  `json:"first"`
+	Second  string                  `json:"second"`
+	Updated time.Time               `json:"updated"`
+	Objects map[string]twoWayObject `json:"objects"`
+
+	mu   sync.Mutex
+	file string
+}
+
+// getTwoWayStateFile returns the default state file of a two-way mirror.
+func getTwoWayStateFile(first, second string) string {
+	return filepath.Join(mustGetMcConfigDir(), "mirror", getHash("two-way", []string{first, second})+".json")
+}
+
+func loadTwoWayState(file, first, second string) (*twoWayState, *probe.Error) {
+	state := &twoWayState{
+		Version: twoWayStateVersion,
+		First:   first,
+		Second:  second,
+		Objects: make(map[string]twoWayObject),
+		file:    file,
+	}
+	data, e := ioutil.ReadFile(file)
+	if os.IsNotExist(e) {
+		return state, nil
+	}
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	if e = json.Unmarshal(data, state); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if state.First != first || state.Second != second {
+		return nil, errInvalidArgument().Trace(file, state.First, state.Second)
+	}
+	if state.Objects == nil {
+		state.Objects = make(map[string]twoWayObject)
+	}
+	return state, nil
+}
+
+func (s *twoWayState) get(key string) (twoWayObject, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	obj, ok := s.Objects[key]
+	return obj, ok
+}
+
+func (s *twoWayState) set(key string, obj twoWayObject) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Objects[key] = obj
+}
+
+func (s *twoWayState) forget(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Objects, key)
+}
+
+// save writes the state file atomically.
+func (s *twoWayState) save() *probe.Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Updated = UTCNow()
+	data, e := json.MarshalIndent(s, "", " ")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.MkdirAll(filepath.Dir(s.file), 0o700); e != nil {
+		return probe.NewError(e)
+	}
+	tmpFile := s.file + ".tmp"
+	if e = ioutil.WriteFile(tmpFile, data, 0o600); e != nil {
+		return probe.NewError(e)
+	}
+	return probe.NewError(os.Rename(tmpFile, s.file))
+}
+
+// twoWayFingerprint identifies the content of an object on one side.
+func twoWayFingerprint(content *ClientContent) string {
+	if content.ETag != "" {
+		return fmt.Sprintf("%d:%s", content.Size, content.ETag)
+	}
+	return fmt.Sprintf("%d:%d", content.Size, content.Time.UnixNano())
+}
+
+// twoWayContentMD5 returns the MD5 checksum of an object, local files
+// are hashed. It is empty when unknown, e.g. for multipart or encrypted
+// uploads whose ETag is not an MD5 checksum.
+func twoWayContentMD5(content *ClientContent) string {
+	sum, ok, err := contentMD5(content)
+	if err != nil || !ok {
+		return ""
+	}
+	return sum
+}
+
+// twoWaySameContent returns true if both objects are identical, compared
+// by their MD5 checksums when both are known, by their modification time
+// otherwise.
+func twoWaySameContent(first, second *ClientContent) bool {
+	if first.Size != second.Size {
+		return false
+	}
+	// Hash the first side only when the second has a comparable checksum.
+	if secondSum := twoWayContentMD5(second); secondSum != "" {
+		if firstSum := twoWayContentMD5(first); firstSum != "" {
+			return firstSum == secondSum
+		}
+	}
+	return !first.Time.IsZero() && first.Time.Unix() == second.Time.Unix()
+}
+
+// twoWayAction is how an object is reconciled.
+type twoWayAction int
+
+const (
+	twoWayNone twoWayAction = iota
+	twoWayCopyToSecond
+	twoWayCopyToFirst
+	twoWayRemoveFromFirst
+	twoWayRemoveFromSecond
+	twoWayRecord
+	twoWayForget
+	twoWayConflict
+)
+
+// planTwoWay decides how to reconcile an object present on the sides
+// with a non nil content, given its state at the last sync if any.
+// Removals are only propagated when remove is set, otherwise the
+// removed object is copied back.
+func planTwoWay(first, second *ClientContent, last *twoWayObject, remove bool) twoWayAction {
+	changedFirst := first != nil && (last == nil || twoWayFingerprint(first) != last.First)
+	changedSecond := second != nil && (last == nil || twoWayFingerprint(second) != last.Second)
+
+	switch {
+	case first == nil && second == nil:
+		return twoWayForget
+	case first != nil && second != nil:
+		switch {
+		case !changedFirst && !changedSecond:
+			return twoWayNone
+		case last != nil && changedFirst && !changedSecond:
+			return twoWayCopyToSecond
+		case last != nil && !changedFirst && changedSecond:
+			return twoWayCopyToFirst
+		case twoWaySameContent(first, second):
+			return twoWayRecord
+		}
+		return twoWayConflict
+	case second == nil:
+		if last == nil {
+			return twoWayCopyToSecond
+		}
+		if changedFirst {
+			// Modified on the first side, removed on the second.
+			return twoWayConflict
+		}
+		if remove {
+			return twoWayRemoveFromFirst
+		}
+		return twoWayCopyToSecond
+	default:
+		if last == nil {
+			return twoWayCopyToFirst
+		}
+		if changedSecond {
+			// Modified on the second side, removed on the first.
+			return twoWayConflict
+		}
+		if remove {
+			return twoWayRemoveFromSecond
+		}
+		return twoWayCopyToFirst
+	}
+}
+
+// mirrorConflictMessage container for an object changed on both sides.
+type mirrorConflictMessage struct {
+	Status string `json:"status"`
+	First  string `json:"first"`
+	Second string `json:"second"`
+	Reason string `json:"reason"`
+}
+
+func (m mirrorConflictMessage) String() string {
+	return console.Colorize("MirrorConflict", fmt.Sprintf("Conflict between `%s` and `%s`, %s.", m.First, m.Second, m.Reason))
+}
+
+func (m mirrorConflictMessage) JSON() string {
+	m.Status = "conflict"
+	jsonMessageBytes, e := json.MarshalIndent(m, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// twoWayConflictReason describes why an object cannot be reconciled.
+func twoWayConflictReason(first, second *ClientContent) string {
+	switch {
+	case first == nil:
+		return "removed from the first and modified on the second"
+	case second == nil:
+		return "modified on the first and removed from the second"
+	}
+	return "modified on both sides"
+}
+
+// twoWaySide is one side of a two-way mirror.
+type twoWaySide struct {
+	alias   string
+	url     string
+	objects map[string]*ClientContent
+}
+
+// listTwoWaySide lists all objects of a side by their path relative to it.
+func listTwoWaySide(ctx context.Context, aliasedURL string, excludeOptions []string) (*twoWaySide, *probe.Error) {
+	alias, urlStr, _ := mustExpandAlias(aliasedURL)
+	clnt, err := newClientFromAlias(alias, urlStr)
+	if err != nil {
+		return nil, err.Trace(aliasedURL)
+	}
+	separator := string(clnt.GetURL().Separator)
+	if !strings.HasSuffix(urlStr, separator) {
+		urlStr += separator
+	}
+	side := &twoWaySide{alias: alias, url: urlStr, objects: make(map[string]*ClientContent)}
+	basePath := newClientURL(urlStr).Path
+
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			switch content.Err.ToGoError().(type) {
+			case PathNotFound, BucketDoesNotExist, ObjectMissing:
+				// The side is empty, it is created on the first copy.
+				continue
+			}
+			return nil, content.Err.Trace(aliasedURL)
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		key := filepath.ToSlash(strings.TrimPrefix(content.URL.Path, basePath))
+		if matchExcludeOptions(excludeOptions, key) {
+			continue
+		}
+		side.objects[key] = content
+	}
+	return side, nil
+}
+
+// twoWayURLs returns the URLs copying the object of key from one side to the other.
+func twoWayURLs(from, to *twoWaySide, key string) URLs {
+	return URLs{
+		SourceAlias:   from.alias,
+		SourceContent: from.objects[key],
+		TargetAlias:   to.alias,
+		TargetContent: &ClientContent{
+			URL:          *newClientURL(urlJoinPath(to.url, key)),
+			Metadata:     map[string]string{},
+			UserMetadata: map[string]string{},
+		},
+	}
+}
+
+// removeTwoWayObject removes the object of key from a side.
+func removeTwoWayObject(ctx context.Context, side *twoWaySide, key string) *probe.Error {
+	content := side.objects[key]
+	clnt, err := newClientFromAlias(side.alias, content.URL.String())
+	if err != nil {
+		return err
+	}
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: content.URL}
+	close(contentCh)
+	for result := range clnt.Remove(ctx, false, false, false, contentCh) {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+// statTwoWayObject returns the fingerprint of the object of key on a side.
+func statTwoWayObject(ctx context.Context, side *twoWaySide, key string, encKeyDB map[string][]prefixSSEPair) (string, *probe.Error) {
+	_, content, err := url2Stat(ctx, side.alias+newClientURL(urlJoinPath(side.url, key)).Path, "", false, encKeyDB, time.Time{})
+	if err != nil {
+		return "", err
+	}
+	return twoWayFingerprint(content), nil
+}
+
+// runTwoWayMirror reconciles both sides, copying changes made on each
+// side since the last run to the other one.
+func runTwoWayMirror(ctx context.Context, firstURL, secondURL string, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) error {
+	console.SetColor("MirrorConflict", color.New(color.FgYellow, color.Bold))
+
+	isFake := cliCtx.Bool("fake")
+	isRemove := cliCtx.Bool("remove")
+
+	// Local folders are recorded by their absolute path, to find the
+	// state again from any working directory.
+	if newClientURL(secondURL).Type == fileSystem && !filepath.IsAbs(secondURL) {
+		if absURL, e := filepath.Abs(secondURL); e == nil {
+			secondURL = absURL
+		}
+	}
+
+	stateFile := cliCtx.String("state-file")
+	if stateFile == "" {
+		stateFile = getTwoWayStateFile(firstURL, secondURL)
+	}
+	state, err := loadTwoWayState(stateFile, firstURL, secondURL)
+	fatalIf(err.Trace(stateFile), "Unable to load the two-way mirror state.")
+
+	first, err := listTwoWaySide(ctx, firstURL, cliCtx.StringSlice("exclude"))
+	fatalIf(err, "Unable to list `"+firstURL+"`.")
+	second, err := listTwoWaySide(ctx, secondURL, cliCtx.StringSlice("exclude"))
+	fatalIf(err, "Unable to list `"+secondURL+"`.")
+
+	keys := make(map[string]struct{})
+	for key := range first.objects {
+		keys[key] = struct{}{}
+	}
+	for key := range second.objects {
+		keys[key] = struct{}{}
+	}
+	for key := range state.Objects {
+		keys[key] = struct{}{}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	var pg ProgressReader
+	if !globalQuiet && !globalJSON {
+		pg = newProgressBar(0)
+	} else {
+		pg = newAccounter(0)
+	}
+	printTwoWayMsg := func(msg message) {
+		if progressReader, ok := pg.(*progressBar); ok {
+			if isFake {
+				printMsg(msg)
+			} else if _, isMirror := msg.(mirrorMessage); !isMirror {
+				console.Eraseline()
+				printMsg(msg)
+				progressReader.Update()
+			}
+			return
+		}
+		printMsg(msg)
+	}
+
+	statusCh := make(chan URLs)
+	parallel := newParallelManager(statusCh)
+	var totalBytes int64
+
+	// copyObject copies the object of key and records both fingerprints.
+	copyObject := func(from, to *twoWaySide, key string) URLs {
+		urls := twoWayURLs(from, to, key)
+		printTwoWayMsg(mirrorMessage{
+			Source: from.alias + urls.SourceContent.URL.Path,
+			Target: to.alias + urls.TargetContent.URL.Path,
+			Size:   urls.SourceContent.Size,
+		})
+		if isFake {
+			return urls.WithError(nil)
+		}
+		urls = uploadSourceToTargetURL(ctx, urls, pg, encKeyDB, false)
+		if urls.Error != nil {
+			return urls
+		}
+		fingerprint, err := statTwoWayObject(ctx, to, key, encKeyDB)
+		if err != nil {
+			return urls.WithError(err.Trace(key))
+		}
+		obj := twoWayObject{First: twoWayFingerprint(urls.SourceContent), Second: fingerprint}
+		if from == second {
+			obj = twoWayObject{First: fingerprint, Second: twoWayFingerprint(urls.SourceContent)}
+		}
+		state.set(key, obj)
+		return urls
+	}
+
+	// removeObject removes the object of key from a side.
+	removeObject := func(side *twoWaySide, key string) URLs {
+		content := side.objects[key]
+		urls := URLs{TargetAlias: side.alias, TargetContent: content}
+		printTwoWayMsg(rmMessage{Key: side.alias + content.URL.Path, Size: content.Size})
+		if isFake {
+			return urls.WithError(nil)
+		}
+		if err := removeTwoWayObject(ctx, side, key); err != nil {
+			return urls.WithError(err.Trace(key))
+		}
+		state.forget(key)
+		return urls
+	}
+
+	conflicts := 0
+	go func() {
+		defer func() {
+			parallel.stopAndWait()
+			close(statusCh)
+		}()
+		for _, key := range sortedKeys {
+			if ctx.Err() != nil {
+				return
+			}
+			key := key
+			firstContent, secondContent := first.objects[key], second.objects[key]
+			var last *twoWayObject
+			if obj, ok := state.get(key); ok {
+				last = &obj
+			}
+
+			switch planTwoWay(firstContent, secondContent, last, isRemove) {
+			case twoWayCopyToSecond:
+				totalBytes += firstContent.Size
+				pg.SetTotal(totalBytes)
+				parallel.queueTask(func() URLs { return copyObject(first, second, key) }, firstContent.Size)
+			case twoWayCopyToFirst:
+				totalBytes += secondContent.Size
+				pg.SetTotal(totalBytes)
+				parallel.queueTask(func() URLs { return copyObject(second, first, key) }, secondContent.Size)
+			case twoWayRemoveFromFirst:
+				parallel.queueTask(func() URLs { return removeObject(first, key) }, 0)
+			case twoWayRemoveFromSecond:
+				parallel.queueTask(func() URLs { return removeObject(second, key) }, 0)
+			case twoWayRecord:
+				state.set(key, twoWayObject{First: twoWayFingerprint(firstContent), Second: twoWayFingerprint(secondContent)})
+			case twoWayForget:
+				state.forget(key)
+			case twoWayConflict:
+				conflicts++
+				printTwoWayMsg(mirrorConflictMessage{
+					First:  first.alias + newClientURL(urlJoinPath(first.url, key)).Path,
+					Second: second.alias + newClientURL(urlJoinPath(second.url, key)).Path,
+					Reason: twoWayConflictReason(firstContent, secondContent),
+				})
+			}
+		}
+	}()
+
+	var retErr error
+	lastSave := time.Now()
+	for urls := range statusCh {
+		if urls.Error != nil {
+			if !globalQuiet && !globalJSON {
+				console.Eraseline()
+			}
+			errorIf(urls.Error, "Unable to mirror.")
+			retErr = exitStatus(globalErrorExitStatus)
+		}
+		if !isFake && time.Since(lastSave) > twoWayStateSaveInterval {
+			errorIf(state.save().Trace(stateFile), "Unable to save the two-way mirror state.")
+			lastSave = time.Now()
+		}
+	}
+
+	if progressReader, ok := pg.(*progressBar); ok {
+		if progressReader.ProgressBar.Get() > 0 {
+			progressReader.ProgressBar.Finish()
+		}
+	} else if accntReader, ok := pg.(*accounter); ok {
+		printMsg(accntReader.Stat())
+	}
+
+	// The state only records reconciled objects, it is saved even when
+	// the run is interrupted.
+	if !isFake {
+		fatalIf(state.save().Trace(stateFile), "Unable to save the two-way mirror state.")
+	}
+	if conflicts > 0 {
+		errorIf(errDummy().Trace(firstURL, secondURL),
+			fmt.Sprintf("%d conflict(s) found, resolve them by updating or removing the object on one side.", conflicts))
+		retErr = exitStatus(globalErrorExitStatus)
+	}
+	return retErr
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlanTwoWay(t *testing.T) {
+	a := &ClientContent{Size: 10, ETag: "0cc175b9c0f1b6a831c399e269772661"}
+	b := &ClientContent{Size: 10, ETag: "92eb5ffee6ae2fec3ad71c777531578f"}
+	aCopy := &ClientContent{Size: 10, ETag: "0cc175b9c0f1b6a831c399e269772661"}
+	synced := &twoWayObject{First: twoWayFingerprint(a), Second: twoWayFingerprint(aCopy)}
+
+	testCases := []struct {
+		first, second *ClientContent
+		last          *twoWayObject
+		remove        bool
+		action        twoWayAction
+	}{
+		// First mirror.
+		{a, nil, nil, false, twoWayCopyToSecond},
+		{nil, a, nil, false, twoWayCopyToFirst},
+		{a, aCopy, nil, false, twoWayRecord},
+		{a, b, nil, false, twoWayConflict},
+		// Unchanged since the last mirror.
+		{a, aCopy, synced, false, twoWayNone},
+		{nil, nil, synced, false, twoWayForget},
+		// Changed on one side.
+		{b, aCopy, synced, false, twoWayCopyToSecond},
+		{a, b, synced, false, twoWayCopyToFirst},
+		// Changed on both sides.
+		{b, &ClientContent{Size: 10, ETag: "92eb5ffee6ae2fec3ad71c777531578f"}, synced, false, twoWayRecord},
+		{b, &ClientContent{Size: 12, ETag: "4a8a08f09d37b73795649038408b5f33"}, synced, false, twoWayConflict},
+		// Removed on one side.
+		{a, nil, synced, true, twoWayRemoveFromFirst},
+		{nil, aCopy, synced, true, twoWayRemoveFromSecond},
+		{a, nil, synced, false, twoWayCopyToSecond},
+		{nil, aCopy, synced, false, twoWayCopyToFirst},
+		// Removed on one side and changed on the other.
+		{b, nil, synced, true, twoWayConflict},
+		{nil, b, synced, true, twoWayConflict},
+	}
+
+	for i, testCase := range testCases {
+		action := planTwoWay(testCase.first, testCase.second, testCase.last, testCase.remove)
+		if action != testCase.action {
+			t.Fatalf("Test %d: expected %d, got %d", i+1, testCase.action, action)
+		}
+	}
+}
+
+func TestTwoWaySameContent(t *testing.T) {
+	dir, e := ioutil.TempDir("", "mc-two-way-")
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer os.RemoveAll(dir)
+	localPath := filepath.Join(dir, "hello")
+	if e = ioutil.WriteFile(localPath, []byte("hello world"), 0o600); e != nil {
+		t.Fatal(e)
+	}
+	local := &ClientContent{URL: *newClientURL(localPath), Size: 11}
+
+	mtime := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	md5Sum := "5eb63bbbe01eeed093cb22bb8f5acdc3"
+	testCases := []struct {
+		first, second *ClientContent
+		same          bool
+	}{
+		{local, &ClientContent{Size: 11, ETag: md5Sum}, true},
+		{local, &ClientContent{Size: 11, ETag: "b10a8db164e0754105b7a99be72e3fe5"}, false},
+		{local, &ClientContent{Size: 12, ETag: md5Sum}, false},
+		// ETags which are not MD5 checksums fall back to the modification time.
+		{&ClientContent{Size: 11}, &ClientContent{Size: 11}, false},
+		{&ClientContent{Size: 11, ETag: md5Sum, Time: mtime}, &ClientContent{Size: 11, ETag: "9b2cf535f27731c974343645a3985328-2", Time: mtime}, true},
+		{&ClientContent{Size: 11, ETag: md5Sum, Time: mtime}, &ClientContent{Size: 11, ETag: "9b2cf535f27731c974343645a3985328-2", Time: mtime.Add(time.Hour)}, false},
+		{&ClientContent{Size: 11, ETag: md5Sum, Time: mtime}, &ClientContent{Size: 11, ETag: md5Sum, Time: mtime, Metadata: map[string]string{"X-Amz-Server-Side-Encryption": "aws:kms"}}, true},
+		{&ClientContent{Size: 11, ETag: md5Sum}, &ClientContent{Size: 11, ETag: md5Sum, Metadata: map[string]string{"X-Amz-Server-Side-Encryption": "aws:kms"}}, false},
+	}
+	for i, testCase := range testCases {
+		if same := twoWaySameContent(testCase.first, testCase.second); same != testCase.same {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.same, same)
+		}
+	}
+}
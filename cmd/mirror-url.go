@@ -45,10 +45,68 @@ func checkMirrorSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[st
 	srcURL = URLs[0]
 	tgtURL = URLs[1]
 
-	if cliCtx.Bool("force") && cliCtx.Bool("remove") {
-		errorIf(errInvalidArgument().Trace(URLs...), "`--force` is deprecated, please use `--overwrite` instead with `--remove` for the same functionality.")
-	} else if cliCtx.Bool("force") {
-		errorIf(errInvalidArgument().Trace(URLs...), "`--force` is deprecated, please use `--overwrite` instead for the same functionality.")
+	if cliCtx.Bool("force") {
+		checkFeature("mirror-force")
+	}
+
+	if cliCtx.Bool("two-way") {
+		checkFeature("two-way-mirror")
+		for _, flag := range []string{"watch", "active-active", "multi-master", "older-than", "newer-than", "larger-than", "smaller-than", "include-tag", "exclude-tag"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(URLs...), "`--two-way` cannot be used with `--"+flag+"`.")
+			}
+		}
+	} else if cliCtx.IsSet("state-file") {
+		fatalIf(errInvalidArgument().Trace(URLs...), "`--state-file` can only be used with `--two-way`.")
+	}
+
+	if cliCtx.IsSet("state-db") {
+		for _, flag := range []string{"watch", "active-active", "multi-master", "two-way"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(URLs...), "`--state-db` cannot be used with `--"+flag+"`.")
+			}
+		}
+	}
+
+	if cliCtx.Bool("delete-excluded") {
+		if !cliCtx.Bool("remove") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--delete-excluded` can only be used with `--remove`.")
+		}
+		if len(cliCtx.StringSlice("exclude")) == 0 && len(defaultExcludePatterns(cliCtx)) == 0 {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--delete-excluded` can only be used with `--exclude`, `--exclude-hidden` or `--exclude-system`.")
+		}
+		for _, flag := range []string{"two-way", "state-db", "watch-state"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(URLs...), "`--delete-excluded` cannot be used with `--"+flag+"`.")
+			}
+		}
+	}
+
+	if cliCtx.IsSet("report") {
+		for _, flag := range []string{"watch", "active-active", "multi-master", "two-way"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(URLs...), "`--report` cannot be used with `--"+flag+"`.")
+			}
+		}
+	}
+
+	if cliCtx.IsSet("watch-state") && !cliCtx.Bool("watch") && !cliCtx.Bool("active-active") && !cliCtx.Bool("multi-master") {
+		fatalIf(errInvalidArgument().Trace(URLs...), "`--watch-state` can only be used with `--watch`.")
+	}
+
+	if cliCtx.IsSet("conflict") {
+		if !cliCtx.Bool("overwrite") && !cliCtx.Bool("force") {
+			fatalIf(errInvalidArgument().Trace(URLs...), "`--conflict` can only be used with `--overwrite`.")
+		}
+		for _, flag := range []string{"watch", "active-active", "multi-master", "two-way"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(URLs...), "`--conflict` cannot be used with `--"+flag+"`.")
+			}
+		}
+	}
+
+	if _, err := parseMirrorCompare(cliCtx.String("compare")); err != nil {
+		fatalIf(err.Trace(URLs...), "Unrecognized comparison mode. Valid options are `[size, mtime, etag, checksum]`.")
 	}
 
 	_, expandedSourcePath, _ := mustExpandAlias(srcURL)
@@ -98,6 +156,15 @@ func matchExcludeOptions(excludeOptions []string, srcSuffix string) bool {
 	return false
 }
 
+// isExcludedTarget returns true if an existing target object matches the
+// exclude patterns, folders are never reported.
+func isExcludedTarget(excludeOptions []string, tgtSuffix string, tgtContent *ClientContent) bool {
+	if tgtContent == nil || tgtContent.Type.IsDir() || tgtSuffix == "" {
+		return false
+	}
+	return matchExcludeOptions(excludeOptions, tgtSuffix)
+}
+
 func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mirrorOptions, URLsCh chan<- URLs) {
 	// source and targets are always directories
 	sourceSeparator := string(newClientURL(sourceURL).Separator)
@@ -128,31 +195,51 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 	}
 
 	// List both source and target, compare and return values through channel.
-	for diffMsg := range objectDifference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, opts.isMetadata) {
+	// Identical objects are needed as well when excluded objects are purged
+	// or listed in the report.
+	returnSimilar := opts.deleteExcluded || opts.report != nil
+	diffCh := difference(ctx, sourceClnt, targetClnt, sourceURL, targetURL, opts.isMetadata, true, returnSimilar, DirNone, opts.compare)
+	for diffMsg := range diffCh {
 		if diffMsg.Error != nil {
 			// Send all errors through the channel
 			URLsCh <- URLs{Error: diffMsg.Error, ErrorCond: differInUnknown}
 			continue
 		}
 
+		tgtSuffix := strings.TrimPrefix(diffMsg.SecondURL, targetURL)
+		// Remove the target object if it matches the Exclude options provided
+		// and excluded objects must be purged from the target
+		if opts.deleteExcluded && isExcludedTarget(opts.excludeOptions, tgtSuffix, diffMsg.secondContent) {
+			URLsCh <- URLs{
+				TargetAlias:   targetAlias,
+				TargetContent: diffMsg.secondContent,
+			}
+			continue
+		}
+
 		srcSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
 		// Skip the source object if it matches the Exclude options provided
 		if matchExcludeOptions(opts.excludeOptions, srcSuffix) {
+			opts.report.skip(sourceAlias, diffMsg.firstContent, "excluded")
 			continue
 		}
 
-		tgtSuffix := strings.TrimPrefix(diffMsg.SecondURL, targetURL)
 		// Skip the target object if it matches the Exclude options provided
 		if matchExcludeOptions(opts.excludeOptions, tgtSuffix) {
+			opts.report.skip(sourceAlias, diffMsg.firstContent, "excluded")
 			continue
 		}
 
 		switch diffMsg.Diff {
 		case differInNone:
-			// No difference, continue.
+			opts.report.skip(sourceAlias, diffMsg.firstContent, "identical on target")
+			// No difference, remember the object is mirrored.
+			if opts.stateDB != nil && !opts.isFake {
+				opts.stateDB.recordSource(diffMsg.firstContent)
+			}
 		case differInType:
 			URLsCh <- URLs{Error: errInvalidTarget(diffMsg.SecondURL)}
-		case differInSize, differInMetadata, differInAASourceMTime:
+		case differInSize, differInMetadata, differInAASourceMTime, differInETag, differInChecksum:
 			if !opts.isOverwrite && !opts.isFake && !opts.activeActive {
 				// Size or time or etag differs but --overwrite not set.
 				URLsCh <- URLs{
@@ -162,6 +249,25 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 				continue
 			}
 
+			if diffMsg.Diff == differInSize && globalCompress != "" && diffMsg.firstContent != nil &&
+				isCompressedCopy(diffMsg.secondContent, diffMsg.firstContent.Size) {
+				// The target is the compressed upload of the source.
+				opts.report.skip(sourceAlias, diffMsg.firstContent, "compressed on target")
+				continue
+			}
+
+			if diffMsg.firstContent != nil && diffMsg.secondContent != nil {
+				overwrite, err := opts.conflict.resolve(diffMsg.firstContent, diffMsg.secondContent)
+				if err != nil {
+					URLsCh <- URLs{Error: err, ErrorCond: diffMsg.Diff}
+					continue
+				}
+				if !overwrite {
+					opts.report.skip(sourceAlias, diffMsg.firstContent, "kept by the conflict strategy")
+					continue
+				}
+			}
+
 			sourceSuffix := strings.TrimPrefix(diffMsg.FirstURL, sourceURL)
 			// Either available only in source or size differs and force is set
 			targetPath := urlJoinPath(targetURL, sourceSuffix)
@@ -172,6 +278,7 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 				SourceContent: sourceContent,
 				TargetAlias:   targetAlias,
 				TargetContent: targetContent,
+				targetRoot:    newClientURL(targetURL).Path,
 			}
 		case differInFirst:
 			// Only in first, always copy.
@@ -184,6 +291,7 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 				SourceContent: sourceContent,
 				TargetAlias:   targetAlias,
 				TargetContent: targetContent,
+				targetRoot:    newClientURL(targetURL).Path,
 			}
 		case differInSecond:
 			if !opts.isRemove && !opts.isFake {
@@ -205,17 +313,30 @@ func deltaSourceTarget(ctx context.Context, sourceURL, targetURL string, opts mi
 type mirrorOptions struct {
 	isFake, isOverwrite, activeActive bool
 	isWatch, isRemove, isMetadata     bool
+	deleteExcluded                    bool
+	report                            *mirrorReport
 	excludeOptions                    []string
+	tagFilter                         objectTagFilter
+	sizeFilter                        objectSizeFilter
 	encKeyDB                          map[string][]prefixSSEPair
 	md5, disableMultipart             bool
 	olderThan, newerThan              string
 	storageClass                      string
 	userMetadata                      map[string]string
+	stateDB                           *mirrorStateDB
+	preserveAttrs                     mirrorPreserveAttrs
+	conflict                          mirrorConflict
+	compare                           mirrorCompare
+	watchState                        *mirrorWatchState
 }
 
 // Prepares urls that need to be copied or removed based on requested options.
 func prepareMirrorURLs(ctx context.Context, sourceURL string, targetURL string, opts mirrorOptions) <-chan URLs {
 	URLsCh := make(chan URLs)
+	if opts.stateDB != nil && !opts.stateDB.isEmpty() {
+		go deltaSourceStateDB(ctx, sourceURL, targetURL, opts, URLsCh)
+		return URLsCh
+	}
 	go deltaSourceTarget(ctx, sourceURL, targetURL, opts, URLsCh)
 	return URLsCh
 }
@@ -0,0 +1,85 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestDeltaSourceTargetDeleteExcluded(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	src, dst := t.TempDir(), t.TempDir()
+	files := map[string]string{
+		filepath.Join(src, "a.txt"): "a",
+		filepath.Join(src, "b.tmp"): "b",
+		filepath.Join(dst, "a.txt"): "a",
+		filepath.Join(dst, "b.tmp"): "x",
+		filepath.Join(dst, "c.tmp"): "c",
+		filepath.Join(dst, "d.txt"): "d",
+	}
+	for name, data := range files {
+		if e := ioutil.WriteFile(name, []byte(data), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	testCases := []struct {
+		deleteExcluded bool
+		removed        []string
+	}{
+		{false, []string{"d.txt"}},
+		{true, []string{"b.tmp", "c.tmp", "d.txt"}},
+	}
+
+	for i, testCase := range testCases {
+		opts := mirrorOptions{
+			isRemove:       true,
+			deleteExcluded: testCase.deleteExcluded,
+			excludeOptions: []string{"*.tmp"},
+		}
+		URLsCh := make(chan URLs)
+		go deltaSourceTarget(context.Background(), src, dst, opts, URLsCh)
+		var removed []string
+		for u := range URLsCh {
+			if u.Error != nil {
+				t.Fatalf("Test %d: %v", i+1, u.Error)
+			}
+			if u.SourceContent != nil {
+				t.Fatalf("Test %d: unexpected copy of %s", i+1, u.SourceContent.URL.Path)
+			}
+			removed = append(removed, filepath.Base(u.TargetContent.URL.Path))
+		}
+		sort.Strings(removed)
+		if !reflect.DeepEqual(removed, testCase.removed) {
+			t.Errorf("Test %d: expected %v to be removed, got %v", i+1, testCase.removed, removed)
+		}
+	}
+
+	if _, e := os.Stat(filepath.Join(dst, "c.tmp")); e != nil {
+		t.Errorf("expected preparing the mirror to leave the target untouched, %v", e)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/notification"
+)
+
+// mirrorWatchStateVersion is the version of the mirror watch state.
+const mirrorWatchStateVersion = "1"
+
+// mirrorWatchState records the progress of a named mirror --watch: the
+// events queued but not yet mirrored, and the state database of the
+// mirrored source objects. A restarted watch replays these events and
+// compares the source listing against the database instead of the
+// target, so objects moved in with an older modification time and
+// objects removed while the watch was stopped are mirrored too.
+type mirrorWatchState struct {
+	Version string               `json:"version"`
+	Source  string               `json:"source"`
+	Target  string               `json:"target"`
+	Pending map[string]EventInfo `json:"pending"`
+
+	mu      sync.Mutex
+	file    string
+	dirty   bool
+	objects *mirrorStateDB
+}
+
+// getMirrorWatchStateFile returns the file of a named mirror watch state.
+func getMirrorWatchStateFile(name string) string {
+	return filepath.Join(mustGetMcConfigDir(), "mirror", "watch-"+name+".json")
+}
+
+// loadMirrorWatchState loads the state of a watch mirror, or returns an
+// empty one for a new watch.
+func loadMirrorWatchState(name, sourceURL, targetURL string, dryRun bool) (*mirrorWatchState, *probe.Error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, errInvalidArgument().Trace(name)
+	}
+	s := &mirrorWatchState{
+		Version: mirrorWatchStateVersion,
+		Source:  sourceURL,
+		Target:  targetURL,
+		Pending: make(map[string]EventInfo),
+		file:    getMirrorWatchStateFile(name),
+	}
+	data, e := ioutil.ReadFile(s.file)
+	if e != nil && !os.IsNotExist(e) {
+		return nil, probe.NewError(e)
+	}
+	if e == nil {
+		if e = json.Unmarshal(data, s); e != nil {
+			return nil, probe.NewError(e).Trace(s.file)
+		}
+		if s.Source != sourceURL || s.Target != targetURL {
+			return nil, probe.NewError(fmt.Errorf("the state records a mirror from `%s` to `%s`", s.Source, s.Target))
+		}
+		if s.Pending == nil {
+			s.Pending = make(map[string]EventInfo)
+		}
+	}
+	objects, err := loadMirrorStateDB("watch-"+name, sourceURL, targetURL, dryRun)
+	if err != nil {
+		return nil, err.Trace(name)
+	}
+	s.objects = objects
+	return s, nil
+}
+
+// queued records a pending event and returns its key.
+func (s *mirrorWatchState) queued(event EventInfo) string {
+	key := string(event.Type) + ":" + event.Path
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Pending[key] = event
+	s.dirty = true
+	return key
+}
+
+// done forgets a pending event once mirrored.
+func (s *mirrorWatchState) done(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.Pending, key)
+	s.dirty = true
+}
+
+// pendingEvents returns the pending events, oldest first.
+func (s *mirrorWatchState) pendingEvents() []EventInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]EventInfo, 0, len(s.Pending))
+	for _, event := range s.Pending {
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+	return events
+}
+
+// save writes the state atomically when it changed, with the changes
+// of its state database.
+func (s *mirrorWatchState) save() *probe.Error {
+	if s.objects != nil {
+		if err := s.objects.sync(); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty {
+		return nil
+	}
+	data, e := json.Marshal(s)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.MkdirAll(filepath.Dir(s.file), 0o700); e != nil {
+		return probe.NewError(e)
+	}
+	tmpFile := s.file + ".tmp"
+	if e = ioutil.WriteFile(tmpFile, data, 0o600); e != nil {
+		return probe.NewError(e)
+	}
+	if e = os.Rename(tmpFile, s.file); e != nil {
+		return probe.NewError(e)
+	}
+	s.dirty = false
+	return nil
+}
+
+// saveEvery saves the state periodically until ctx is canceled.
+func (s *mirrorWatchState) saveEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			errorIf(s.save(), "Unable to save the mirror watch state.")
+		}
+	}
+}
+
+// mirrorWatchEvent returns the event of a source object created, with
+// the path the watcher reports for it.
+func mirrorWatchEvent(sourceAlias string, content *ClientContent) EventInfo {
+	eventPath := content.URL.String()
+	if sourceAlias == "" {
+		if absPath, e := filepath.Abs(eventPath); e == nil {
+			eventPath = absPath
+		}
+	}
+	return EventInfo{
+		Time:         content.Time.UTC().Format(time.RFC3339Nano),
+		Size:         content.Size,
+		UserMetadata: content.UserMetadata,
+		Path:         eventPath,
+		Type:         notification.ObjectCreatedPut,
+	}
+}
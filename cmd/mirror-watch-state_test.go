@@ -0,0 +1,97 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/notification"
+)
+
+func TestMirrorWatchState(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	src, dst := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a", "b"} {
+		if e := ioutil.WriteFile(filepath.Join(src, name), []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+
+	s, err := loadMirrorWatchState("test", src, dst, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := s.queued(EventInfo{Path: filepath.Join(src, "a"), Type: notification.ObjectCreatedPut, Time: "2021-06-01T10:00:00Z"})
+	s.queued(EventInfo{Path: filepath.Join(src, "b"), Type: notification.ObjectCreatedPut, Time: "2021-06-01T11:00:00Z"})
+	s.queued(EventInfo{Path: filepath.Join(src, "c"), Type: notification.ObjectRemovedDelete, Time: "2021-06-01T10:30:00Z"})
+	s.done(done)
+	// The watch mirrored both objects.
+	for _, name := range []string{"a", "b"} {
+		clnt, err := newClient(filepath.Join(src, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := clnt.Stat(context.Background(), StatOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.objects.recordSource(content)
+	}
+	if err = s.save(); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.objects.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// While the watch is stopped, an object is moved in with an old
+	// modification time and another is removed.
+	moved := filepath.Join(src, "moved")
+	if e := ioutil.WriteFile(moved, []byte("moved"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+	old := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+	if e := os.Chtimes(moved, old, old); e != nil {
+		t.Fatal(e)
+	}
+	if e := os.Remove(filepath.Join(src, "a")); e != nil {
+		t.Fatal(e)
+	}
+
+	if s, err = loadMirrorWatchState("test", src, dst, false); err != nil {
+		t.Fatal(err)
+	}
+	defer s.objects.close()
+	events := s.pendingEvents()
+	if len(events) != 2 || events[0].Path != filepath.Join(src, "c") || events[1].Path != filepath.Join(src, "b") {
+		t.Errorf("expected the pending events c then b, got %v", events)
+	}
+	copied, removed := runDeltaSourceStateDB(context.Background(), t, src, dst, mirrorOptions{isRemove: true, stateDB: s.objects})
+	if !reflect.DeepEqual(copied, []string{"moved"}) || !reflect.DeepEqual(removed, []string{"a"}) {
+		t.Errorf("expected moved to be copied and a to be removed, got %v and %v", copied, removed)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+const (
+	// maxMultipartPartSize is the largest part size accepted by S3.
+	maxMultipartPartSize = 5 * humanize.GiByte
+	// maxMultipartParts is the largest number of parts of an upload
+	// accepted by S3.
+	maxMultipartParts = 10000
+)
+
+// Flags tuning multipart uploads.
+var multipartFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "part-size",
+		Usage: "size of each part of multipart uploads, e.g. 64MiB, computed from the object size by default",
+	},
+	cli.IntFlag{
+		Name:  "concurrent-parts",
+		Value: 4,
+		Usage: "number of parts of an object uploaded in parallel",
+	},
+}
+
+var (
+	// Part size of multipart uploads, zero to let the size of
+	// the object decide.
+	globalMultipartSize uint64
+	// Number of parts uploaded in parallel, zero for the default.
+	globalMultipartThreads uint
+)
+
+// setMultipartOptions sets the global multipart options from the
+// --part-size and --concurrent-parts flags, --buffer-size being the
+// former name of --part-size.
+func setMultipartOptions(cliCtx *cli.Context) {
+	size := cliCtx.String("part-size")
+	if bufferSize := cliCtx.String("buffer-size"); bufferSize != "" {
+		if size != "" {
+			fatalIf(errInvalidArgument().Trace(size), "--part-size cannot be used with --buffer-size.")
+		}
+		size = bufferSize
+	}
+	if size != "" {
+		partSize, e := humanize.ParseBytes(size)
+		fatalIf(probe.NewError(e).Trace(size), "Unable to parse --part-size.")
+		if partSize < minStreamBufferSize || partSize > maxMultipartPartSize {
+			fatalIf(errInvalidArgument().Trace(size), "--part-size must be between "+humanize.IBytes(minStreamBufferSize)+" and "+humanize.IBytes(maxMultipartPartSize)+".")
+		}
+		globalMultipartSize = partSize
+	}
+	if cliCtx.IsSet("concurrent-parts") {
+		threads := cliCtx.Int("concurrent-parts")
+		if threads < 1 {
+			fatalIf(errInvalidArgument().Trace(cliCtx.String("concurrent-parts")), "--concurrent-parts must be at least 1.")
+		}
+		globalMultipartThreads = uint(threads)
+	}
+}
+
+// checkMultipartSize verifies an object of the given size fits in the
+// parts of a multipart upload, an unknown size or part size always do.
+func checkMultipartSize(partSize uint64, size int64) *probe.Error {
+	if partSize == 0 || size < 0 || uint64(size) <= partSize*maxMultipartParts {
+		return nil
+	}
+	return probe.NewError(fmt.Errorf("part size %s is too small for an object of %s, an upload has at most %d parts",
+		humanize.IBytes(partSize), humanize.IBytes(uint64(size)), maxMultipartParts))
+}
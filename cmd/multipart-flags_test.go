@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/minio/cli"
+)
+
+func TestCheckMultipartSize(t *testing.T) {
+	testCases := []struct {
+		partSize uint64
+		size     int64
+		success  bool
+	}{
+		{0, 1 << 40, true},
+		{5 << 20, -1, true},
+		{5 << 20, 10000 * 5 << 20, true},
+		{5 << 20, 10000*5<<20 + 1, false},
+		{64 << 20, 1 << 40, false},
+		{128 << 20, 1 << 40, true},
+	}
+	for i, testCase := range testCases {
+		err := checkMultipartSize(testCase.partSize, testCase.size)
+		if success := err == nil; success != testCase.success {
+			t.Errorf("Test %d: expected success %t, got %v", i+1, testCase.success, err)
+		}
+	}
+}
+
+func TestSetMultipartOptions(t *testing.T) {
+	defer func(size uint64) { globalMultipartSize = size }(globalMultipartSize)
+
+	testCases := []struct {
+		args     []string
+		expected uint64
+	}{
+		{[]string{"--part-size", "16MiB"}, 16 << 20},
+		{[]string{"--buffer-size", "32MiB"}, 32 << 20},
+	}
+	for i, testCase := range testCases {
+		set := flag.NewFlagSet("cp", flag.ContinueOnError)
+		for _, f := range append(streamFlags, multipartFlags...) {
+			f.Apply(set)
+		}
+		if e := set.Parse(testCase.args); e != nil {
+			t.Fatal(e)
+		}
+		globalMultipartSize = 0
+		setMultipartOptions(cli.NewContext(nil, set, nil))
+		if globalMultipartSize != testCase.expected {
+			t.Errorf("Test %d: expected part size %d, got %d", i+1, testCase.expected, globalMultipartSize)
+		}
+	}
+}
@@ -141,6 +141,9 @@ EXAMPLES:
 
   16. Move a text file to an object storage and disable multipart upload feature.
       {{.Prompt}} {{.HelpName}} --disable-multipart myobject.txt play/mybucket
+
+  17. Reorganize a bucket, objects are copied by the server and removed in batches without being downloaded.
+      {{.Prompt}} {{.HelpName}} --recursive play/mybucket/2021/ play/mybucket/archive/2021/
 `,
 }
 
@@ -0,0 +1,144 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/google/shlex"
+	"github.com/minio/mc/pkg/probe"
+)
+
+const (
+	// Commands running for less than this do not run the notify hook.
+	defaultNotifyAfter = time.Minute
+	// Maximum time given to the notify hook before exiting.
+	notifyHookTimeout = time.Minute
+)
+
+var (
+	// globalStartTime is when mc started, used to compute the
+	// duration of the command in the completion summary.
+	globalStartTime = time.Now()
+
+	notifyOnce sync.Once
+)
+
+// completionSummary is the JSON document passed on the standard input
+// of the notify hook.
+type completionSummary struct {
+	Command   string    `json:"command"`
+	Status    string    `json:"status"`
+	ExitCode  int       `json:"exitCode"`
+	Error     string    `json:"error,omitempty"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Duration  float64   `json:"duration"`
+}
+
+// getNotifyHook returns the command to run on completion along with
+// the minimum duration of the commands it is run for, --notify-exec
+// and --notify-after take precedence over the config file.
+func getNotifyHook() (command string, after time.Duration, err *probe.Error) {
+	command, afterStr := globalNotifyExec, globalNotifyAfter
+	if loadMcConfig != nil {
+		if mcCfg, err := loadMcConfig(); err == nil && mcCfg.OnComplete != nil {
+			if command == "" {
+				command = mcCfg.OnComplete.Command
+			}
+			if afterStr == "" {
+				afterStr = mcCfg.OnComplete.After
+			}
+		}
+	}
+	after = defaultNotifyAfter
+	if afterStr != "" {
+		d, e := time.ParseDuration(afterStr)
+		if e != nil {
+			return "", 0, probe.NewError(e).Trace(afterStr)
+		}
+		after = d
+	}
+	return command, after, nil
+}
+
+// notifyCompletion runs the notify hook, if any, once the command exits
+// with exitCode after running longer than the configured duration.
+func notifyCompletion(exitCode int, cause error) {
+	notifyOnce.Do(func() {
+		if globalCommandName == "" {
+			return
+		}
+		command, after, err := getNotifyHook()
+		if err != nil {
+			errorIf(err, "Unable to parse the duration of the notify hook.")
+			return
+		}
+		endTime := time.Now()
+		if command == "" || endTime.Sub(globalStartTime) < after {
+			return
+		}
+		summary := completionSummary{
+			Command:   globalCommandName,
+			Status:    "success",
+			ExitCode:  exitCode,
+			StartTime: globalStartTime,
+			EndTime:   endTime,
+			Duration:  endTime.Sub(globalStartTime).Seconds(),
+		}
+		if exitCode != 0 {
+			summary.Status = "error"
+		}
+		if cause != nil {
+			summary.Error = cause.Error()
+		}
+		errorIf(runNotifyHook(command, summary).Trace(command), "Unable to run the notify hook.")
+	})
+}
+
+// runNotifyHook runs command with the JSON summary on its standard input,
+// its output goes to the standard error to keep the command output intact.
+func runNotifyHook(command string, summary completionSummary) *probe.Error {
+	args, e := shlex.Split(command)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if len(args) == 0 {
+		return probe.NewError(errors.New("empty notify command"))
+	}
+	data, e := json.Marshal(summary)
+	if e != nil {
+		return probe.NewError(e)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyHookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	return probe.NewError(cmd.Run())
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestGetNotifyHook(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+	defer func() { globalNotifyExec, globalNotifyAfter = "", "" }()
+
+	command, after, err := getNotifyHook()
+	if err != nil || command != "" || after != defaultNotifyAfter {
+		t.Fatalf("expected no hook by default, got %q after %s: %v", command, after, err)
+	}
+
+	cfg := newConfigV10()
+	cfg.OnComplete = &onCompleteV10{Command: "notify-send mc", After: "1h"}
+	if err = saveMcConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	command, after, err = getNotifyHook()
+	if err != nil || command != "notify-send mc" || after != time.Hour {
+		t.Fatalf("expected the hook of the config file, got %q after %s: %v", command, after, err)
+	}
+
+	// --notify-exec and --notify-after override the config file.
+	globalNotifyExec, globalNotifyAfter = "pager-hook", "30s"
+	command, after, err = getNotifyHook()
+	if err != nil || command != "pager-hook" || after != 30*time.Second {
+		t.Fatalf("expected the hook of the flags, got %q after %s: %v", command, after, err)
+	}
+}
+
+func TestRunNotifyHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	output := filepath.Join(t.TempDir(), "summary.json")
+	summary := completionSummary{
+		Command:   "mc cp",
+		Status:    "error",
+		ExitCode:  1,
+		Error:     "access denied",
+		StartTime: time.Date(2021, time.November, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2021, time.November, 1, 2, 0, 0, 0, time.UTC),
+		Duration:  7200,
+	}
+	if err := runNotifyHook(`sh -c "cat > `+output+`"`, summary); err != nil {
+		t.Fatal(err)
+	}
+	data, e := ioutil.ReadFile(output)
+	if e != nil {
+		t.Fatal(e)
+	}
+	var got completionSummary
+	if e = json.Unmarshal(data, &got); e != nil {
+		t.Fatal(e)
+	}
+	if got != summary {
+		t.Errorf("expected %+v, got %+v", summary, got)
+	}
+
+	if err := runNotifyHook("sh -c 'exit 3'", summary); err == nil {
+		t.Error("expected the exit status of the hook to be reported")
+	}
+}
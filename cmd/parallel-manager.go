@@ -37,7 +37,8 @@ const (
 	monitorPeriod = 4 * time.Second
 )
 
-// Number of workers added per bandwidth monitoring.
+// Number of workers started first and added per bandwidth monitoring,
+// lowered by --max-cpu.
 var defaultWorkerFactor = runtime.GOMAXPROCS(0)
 
 // A task is a copy/mirror action that needs to be executed
@@ -273,8 +274,8 @@ func newParallelManager(resultCh chan URLs) *ParallelManager {
 		maxMem:        availableMemory(),
 	}
 
-	// Start with runtime.NumCPU().
-	for i := 0; i < runtime.NumCPU(); i++ {
+	// Start with one worker per usable CPU.
+	for i := 0; i < defaultWorkerFactor; i++ {
 		p.addWorker()
 	}
 
@@ -18,11 +18,21 @@
 package cmd
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
 	"os"
 	"syscall"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
 	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
 )
 
 var (
@@ -43,6 +53,18 @@ var (
 			Name:  "tags",
 			Usage: "apply one or more tags to the uploaded objects",
 		},
+		cli.BoolFlag{
+			Name:  "if-not-exists",
+			Usage: "write the object only if it does not exist on target",
+		},
+		cli.StringFlag{
+			Name:  "buffer-dir",
+			Usage: "spool the parts of the upload to this directory instead of memory, to save memory with large parts",
+		},
+		cli.BoolFlag{
+			Name:  "sha256",
+			Usage: "print the SHA-256 checksum of the stream once uploaded and record it in the 'mc-sha256' tag of the object",
+		},
 	}
 )
 
@@ -53,7 +75,7 @@ var pipeCmd = cli.Command{
 	Action:       mainPipe,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(pipeFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(append(pipeFlags, multipartFlags...), pipeRetryFlags...), ioFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -88,10 +110,76 @@ EXAMPLES:
 
   7. Set tags to the uploaded objects
       {{.Prompt}} tar cvf - . | {{.HelpName}} --tags "category=prod&type=backup" play/mybucket/backup.tar
+
+  8. Stream a large backup from a host with little memory, uploading it in 16MiB parts.
+      {{.Prompt}} tar cvf - . | {{.HelpName}} --part-size 16MiB play/mybucket/backup.tar
+
+  9. Stream a backup from a CI job, failing on the first error instead of retrying.
+      {{.Prompt}} tar cvf - . | {{.HelpName}} --retry 0 play/mybucket/backup.tar
+
+  10. Write a lock object, leaving it untouched if another host created it first.
+      {{.Prompt}} hostname | {{.HelpName}} --if-not-exists play/mybucket/locks/nightly-backup
+
+  11. Stream a long database dump from a host with little memory, spooling up to 8 parts of 64MiB on disk.
+      {{.Prompt}} pg_dump mydb | {{.HelpName}} --buffer-dir /var/tmp --part-size 64MiB --concurrent-parts 8 --retry 10 play/mybucket/mydb.sql
+
+  12. Stream a backup and record its SHA-256 checksum, without reading the object back.
+      {{.Prompt}} tar cvf - . | {{.HelpName}} --sha256 play/mybucket/backup.tar >> backups.sha256
 `,
 }
 
-func pipe(targetURL string, encKeyDB map[string][]prefixSSEPair, storageClass string, meta map[string]string) *probe.Error {
+// pipeMessage is printed when stdin is not written to the target.
+type pipeMessage struct {
+	Status  string `json:"status"`
+	Target  string `json:"target"`
+	Skipped string `json:"skipped"`
+}
+
+func (p pipeMessage) String() string {
+	return console.Colorize("Pipe", fmt.Sprintf("`%s` skipped, %s", p.Target, p.Skipped))
+}
+
+func (p pipeMessage) JSON() string {
+	p.Status = "skipped"
+	pipeMessageBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(pipeMessageBytes)
+}
+
+// Tag recording the SHA-256 checksum of a piped object.
+const pipeChecksumTag = "mc-sha256"
+
+// pipeChecksumMessage is printed with the checksum of the uploaded stream.
+type pipeChecksumMessage struct {
+	Status string `json:"status"`
+	Target string `json:"target"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// String prints the checksum in the format of sha256sum.
+func (p pipeChecksumMessage) String() string {
+	return fmt.Sprintf("%s  %s", p.SHA256, p.Target)
+}
+
+func (p pipeChecksumMessage) JSON() string {
+	p.Status = "success"
+	pipeMessageBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(pipeMessageBytes)
+}
+
+// pipeChecksumTags adds the checksum tag to the tags of the uploaded object.
+func pipeChecksumTags(tags, sum string) string {
+	if tags == "" {
+		return pipeChecksumTag + "=" + sum
+	}
+	return tags + "&" + pipeChecksumTag + "=" + sum
+}
+
+func pipe(targetURL string, encKeyDB map[string][]prefixSSEPair, storageClass string, meta map[string]string, ifNotExists bool, bufferDir string, withChecksum bool) *probe.Error {
 	if targetURL == "" {
 		// When no target is specified, pipe cat's stdin to stdout.
 		return catOut(os.Stdin, -1).Trace()
@@ -99,15 +187,57 @@ func pipe(targetURL string, encKeyDB map[string][]prefixSSEPair, storageClass st
 	alias, _ := url2Alias(targetURL)
 	sseKey := getSSE(targetURL, encKeyDB[alias])
 
+	ctx := context.Background()
+	if ifNotExists {
+		_, _, err := url2Stat(ctx, targetURL, "", false, encKeyDB, time.Time{})
+		if err == nil {
+			printMsg(pipeMessage{Target: targetURL, Skipped: "target exists"})
+			return nil
+		}
+		switch err.ToGoError().(type) {
+		case ObjectMissing, PathNotFound:
+		default:
+			return err.Trace(targetURL)
+		}
+		// Guard against an object created since the target was checked.
+		ctx = withIfNotExists(ctx)
+	}
+
 	// Stream from stdin to multiple objects until EOF.
 	// Ignore size, since os.Stat() would not return proper size all the time
 	// for local filesystem for example /proc files.
 	opts := PutOptions{
-		sse:          sseKey,
-		storageClass: storageClass,
-		metadata:     meta,
+		sse:              sseKey,
+		storageClass:     storageClass,
+		metadata:         meta,
+		multipartSize:    globalMultipartSize,
+		multipartThreads: globalMultipartThreads,
+		spoolDir:         bufferDir,
+	}
+	var reader io.Reader = os.Stdin
+	var checksum hash.Hash
+	if withChecksum {
+		checksum = sha256.New()
+		reader = io.TeeReader(reader, checksum)
+	}
+	size, err := putTargetStreamWithURL(ctx, targetURL, reader, -1, opts)
+	if ifNotExists && isErrPreconditionFailed(err) {
+		printMsg(pipeMessage{Target: targetURL, Skipped: "target exists"})
+		return nil
+	}
+	if err == nil && checksum != nil {
+		sum := hex.EncodeToString(checksum.Sum(nil))
+		clnt, err := newClient(targetURL)
+		if err != nil {
+			return err.Trace(targetURL)
+		}
+		if clnt.GetURL().Type == objectStorage {
+			if err = clnt.SetTags(ctx, "", pipeChecksumTags(meta["X-Amz-Tagging"], sum)); err != nil {
+				return err.Trace(targetURL)
+			}
+		}
+		printMsg(pipeChecksumMessage{Target: targetURL, Size: size, SHA256: sum})
 	}
-	_, err := putTargetStreamWithURL(targetURL, os.Stdin, -1, opts)
 	// TODO: See if this check is necessary.
 	switch e := err.ToGoError().(type) {
 	case *os.PathError:
@@ -124,6 +254,27 @@ func checkPipeSyntax(ctx *cli.Context) {
 	if len(ctx.Args()) > 1 {
 		cli.ShowCommandHelpAndExit(ctx, "pipe", 1) // last argument is exit code.
 	}
+	if ctx.Bool("if-not-exists") && len(ctx.Args()) == 0 {
+		fatalIf(errInvalidArgument(), "`--if-not-exists` requires a target.")
+	}
+	if ctx.Bool("sha256") && len(ctx.Args()) == 0 {
+		fatalIf(errInvalidArgument(), "`--sha256` requires a target.")
+	}
+	if bufferDir := ctx.String("buffer-dir"); bufferDir != "" {
+		if len(ctx.Args()) == 0 {
+			fatalIf(errInvalidArgument(), "`--buffer-dir` requires a target.")
+		}
+		clnt, err := newClient(ctx.Args().First())
+		fatalIf(err.Trace(ctx.Args().First()), "Unable to initialize target `"+ctx.Args().First()+"`.")
+		if clnt.GetURL().Type != objectStorage {
+			fatalIf(errInvalidArgument().Trace(ctx.Args().First()), "`--buffer-dir` can only be used with an object storage target.")
+		}
+		st, e := os.Stat(bufferDir)
+		if e == nil && !st.IsDir() {
+			e = fmt.Errorf("%s is not a directory", bufferDir)
+		}
+		fatalIf(probe.NewError(e).Trace(bufferDir), "Unable to use `"+bufferDir+"` as --buffer-dir.")
+	}
 }
 
 // mainPipe is the main entry point for pipe command.
@@ -134,6 +285,9 @@ func mainPipe(ctx *cli.Context) error {
 
 	// validate pipe input arguments.
 	checkPipeSyntax(ctx)
+	console.SetColor("Pipe", color.New(color.FgGreen, color.Bold))
+	setMultipartOptions(ctx)
+	setRequestRetries(ctx)
 
 	var meta = map[string]string{}
 	if attr := ctx.String("attr"); attr != "" {
@@ -144,12 +298,12 @@ func mainPipe(ctx *cli.Context) error {
 		meta["X-Amz-Tagging"] = tags
 	}
 	if len(ctx.Args()) == 0 {
-		err = pipe("", nil, ctx.String("storage-class"), meta)
+		err = pipe("", nil, ctx.String("storage-class"), meta, false, "", false)
 		fatalIf(err.Trace("stdout"), "Unable to write to one or more targets.")
 	} else {
 		// extract URLs.
 		URLs := ctx.Args()
-		err = pipe(URLs[0], encKeyDB, ctx.String("storage-class"), meta)
+		err = pipe(URLs[0], encKeyDB, ctx.String("storage-class"), meta, ctx.Bool("if-not-exists"), ctx.String("buffer-dir"), ctx.Bool("sha256"))
 		fatalIf(err.Trace(URLs[0]), "Unable to write to one or more targets.")
 	}
 
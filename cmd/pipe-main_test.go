@@ -0,0 +1,38 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "testing"
+
+func TestPipeChecksumTags(t *testing.T) {
+	const sum = "5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"
+	testCases := []struct {
+		tags     string
+		expected string
+	}{
+		{"", "mc-sha256=" + sum},
+		{"type=backup", "type=backup&mc-sha256=" + sum},
+		{"category=prod&type=backup", "category=prod&type=backup&mc-sha256=" + sum},
+	}
+
+	for i, testCase := range testCases {
+		if got := pipeChecksumTags(testCase.tags, sum); got != testCase.expected {
+			t.Fatalf("Test %d: expected %q, got %q", i+1, testCase.expected, got)
+		}
+	}
+}
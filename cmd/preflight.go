@@ -0,0 +1,248 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/google/uuid"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+)
+
+// preflightObjectPrefix is the name prefix of the temporary object
+// written on the target to verify write permissions.
+const preflightObjectPrefix = ".mc-preflight-"
+
+// preflightResult is the outcome of checking a single capability.
+type preflightResult struct {
+	URL       string `json:"url"`
+	Operation string `json:"operation"`
+	Allowed   bool   `json:"allowed"`
+	Skipped   bool   `json:"skipped,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// preflightMessage container for the capability report.
+type preflightMessage struct {
+	Status string            `json:"status"`
+	Checks []preflightResult `json:"checks"`
+}
+
+// String colorized capability report.
+func (p preflightMessage) String() string {
+	var lines []string
+	lines = append(lines, console.Colorize("PreflightHeader", "Pre-flight capability report:"))
+	for _, c := range p.Checks {
+		var mark string
+		switch {
+		case c.Skipped:
+			mark = console.Colorize("PreflightSkip", "-")
+		case c.Allowed:
+			mark = console.Colorize("PreflightOK", check)
+		default:
+			mark = console.Colorize("PreflightFail", "✗")
+		}
+		line := "  " + mark + " " + c.Operation + " on `" + c.URL + "`"
+		if c.Error != "" {
+			line += ": " + c.Error
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// JSON jsonified capability report.
+func (p preflightMessage) JSON() string {
+	jsonMessageBytes, e := json.MarshalIndent(p, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// failed returns true if any required capability is missing.
+func (p preflightMessage) failed() bool {
+	for _, c := range p.Checks {
+		if !c.Allowed && !c.Skipped {
+			return true
+		}
+	}
+	return false
+}
+
+func newPreflightResult(url, operation string, err *probe.Error) preflightResult {
+	r := preflightResult{URL: url, Operation: operation, Allowed: err == nil}
+	if err != nil {
+		if _, ok := err.ToGoError().(APINotImplemented); ok {
+			r.Skipped = true
+			r.Error = "not supported by target"
+			return r
+		}
+		r.Error = err.ToGoError().Error()
+	}
+	return r
+}
+
+// preflightSource verifies the source can be listed and read.
+func preflightSource(ctx context.Context, sourceURL string, encKeyDB map[string][]prefixSSEPair) []preflightResult {
+	clnt, err := newClient(sourceURL)
+	if err != nil {
+		return []preflightResult{newPreflightResult(sourceURL, "list", err)}
+	}
+
+	listCtx, cancelList := context.WithCancel(ctx)
+	defer cancelList()
+
+	var first *ClientContent
+	for content := range clnt.List(listCtx, ListOptions{Recursive: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			err = content.Err
+		} else {
+			first = content
+		}
+		break
+	}
+	results := []preflightResult{newPreflightResult(sourceURL, "list", err)}
+	if err != nil || first == nil {
+		// Nothing to read, an empty source is not an error.
+		return results
+	}
+
+	alias, _ := url2Alias(sourceURL)
+	objectURL := first.URL.String()
+	if alias != "" {
+		objectURL = urlJoinPath(alias, first.URL.Path)
+	}
+	reader, err := getSourceStreamFromURL(ctx, objectURL, first.VersionID, encKeyDB)
+	if err == nil {
+		var buf [1]byte
+		if _, e := reader.Read(buf[:]); e != nil && e != io.EOF {
+			err = probe.NewError(e)
+		}
+		reader.Close()
+	}
+	return append(results, newPreflightResult(objectURL, "get", err))
+}
+
+// preflightMultipart verifies a multipart upload can be initiated on
+// the given S3 object, the upload is aborted right away.
+func preflightMultipart(ctx context.Context, clnt Client) *probe.Error {
+	s3Clnt, ok := clnt.(*S3Client)
+	if !ok {
+		return probe.NewError(APINotImplemented{API: "NewMultipartUpload", APIType: "filesystem"})
+	}
+	bucket, object := s3Clnt.url2BucketAndObject()
+	core := minio.Core{Client: s3Clnt.api}
+	uploadID, e := core.NewMultipartUpload(ctx, bucket, object, minio.PutObjectOptions{})
+	if e != nil {
+		return probe.NewError(e)
+	}
+	if e = core.AbortMultipartUpload(ctx, bucket, object, uploadID); e != nil {
+		return probe.NewError(e)
+	}
+	return nil
+}
+
+// preflightTarget verifies objects can be written, tagged, uploaded
+// in multiple parts and removed on the target by working with a
+// temporary object, whose written version is removed.
+func preflightTarget(ctx context.Context, targetURL string, isDir bool) []preflightResult {
+	dirURL := targetURL
+	if !isDir {
+		if idx := strings.LastIndex(targetURL, "/"); idx > 0 {
+			dirURL = targetURL[:idx]
+		}
+	}
+	if alias, path := url2Alias(dirURL); alias != "" && strings.Trim(path, "/") == "" {
+		return []preflightResult{{
+			URL:       dirURL,
+			Operation: "put",
+			Skipped:   true,
+			Error:     "target is not a bucket",
+		}}
+	}
+
+	probeURL := urlJoinPath(dirURL, preflightObjectPrefix+uuid.New().String())
+	clnt, err := newClient(probeURL)
+	if err != nil {
+		return []preflightResult{newPreflightResult(probeURL, "put", err)}
+	}
+
+	data := []byte("mc pre-flight check " + time.Now().UTC().Format(time.RFC3339))
+	_, err = clnt.Put(ctx, bytes.NewReader(data), int64(len(data)), nil, PutOptions{
+		metadata:         map[string]string{},
+		disableMultipart: true,
+	})
+	results := []preflightResult{newPreflightResult(probeURL, "put", err)}
+	if err != nil {
+		return append(results, newPreflightResult(probeURL, "multipart", preflightMultipart(ctx, clnt)))
+	}
+
+	// On a versioned bucket, removing the object without its version
+	// only hides it behind a delete marker.
+	var versionID string
+	if content, err := clnt.Stat(ctx, StatOptions{}); err == nil {
+		versionID = content.VersionID
+	}
+
+	results = append(results, newPreflightResult(probeURL, "tagging", clnt.SetTags(ctx, versionID, "mc-preflight=true")))
+	results = append(results, newPreflightResult(probeURL, "multipart", preflightMultipart(ctx, clnt)))
+
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: clnt.GetURL(), VersionID: versionID}
+	close(contentCh)
+	err = nil
+	for result := range clnt.Remove(ctx, false, false, false, contentCh) {
+		if result.Err != nil {
+			err = result.Err
+		}
+	}
+	return append(results, newPreflightResult(probeURL, "delete", err))
+}
+
+// runPreflight verifies the credentials are allowed to perform all
+// operations needed to transfer from sources to target, prints a
+// capability report and exits upon any missing capability.
+func runPreflight(ctx context.Context, sourceURLs []string, targetURL string, targetIsDir bool, encKeyDB map[string][]prefixSSEPair) {
+	console.SetColor("PreflightHeader", color.New(color.Bold))
+	console.SetColor("PreflightOK", color.New(color.FgGreen, color.Bold))
+	console.SetColor("PreflightFail", color.New(color.FgRed, color.Bold))
+	console.SetColor("PreflightSkip", color.New(color.FgYellow))
+
+	var msg preflightMessage
+	for _, sourceURL := range sourceURLs {
+		msg.Checks = append(msg.Checks, preflightSource(ctx, sourceURL, encKeyDB)...)
+	}
+	msg.Checks = append(msg.Checks, preflightTarget(ctx, targetURL, targetIsDir)...)
+
+	msg.Status = "success"
+	if msg.failed() {
+		msg.Status = "error"
+	}
+	printMsg(msg)
+	if msg.failed() {
+		fatalIf(probe.NewError(errors.New("missing required permissions")), "Pre-flight check failed.")
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+func TestPreflightTarget(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	if err := saveMcConfig(newMcConfig()); err != nil {
+		t.Fatal(err)
+	}
+	loadMcConfig = loadMcConfigFactory()
+
+	versionIDRe := regexp.MustCompile(`<VersionId>([^<]*)</VersionId>`)
+	testCases := []struct {
+		versionID string
+	}{
+		{""},
+		{"c5a6ab8c-44a8-4d8e-8a57-37e2e4a2b6b4"},
+	}
+	for i, testCase := range testCases {
+		var put, deleted bool
+		var tagVersionID, deleteVersionID string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			query := r.URL.Query()
+			if testCase.versionID != "" {
+				w.Header().Set("x-amz-version-id", testCase.versionID)
+			}
+			switch {
+			case query.Has("location"):
+				w.Write([]byte(`<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">us-east-1</LocationConstraint>`))
+			case query.Has("tagging"):
+				tagVersionID = query.Get("versionId")
+			case query.Has("uploads"):
+				w.Write([]byte(`<InitiateMultipartUploadResult><Bucket>bucket</Bucket><Key>key</Key><UploadId>upload</UploadId></InitiateMultipartUploadResult>`))
+			case query.Has("uploadId"):
+				w.WriteHeader(http.StatusNoContent)
+			case query.Has("delete"):
+				body, _ := ioutil.ReadAll(r.Body)
+				if m := versionIDRe.FindSubmatch(body); m != nil {
+					deleteVersionID = string(m[1])
+				}
+				deleted = true
+				w.Write([]byte(`<DeleteResult></DeleteResult>`))
+			case r.Method == http.MethodDelete:
+				deleteVersionID = query.Get("versionId")
+				deleted = true
+				w.WriteHeader(http.StatusNoContent)
+			case r.Method == http.MethodPut:
+				put = true
+				w.Header().Set("ETag", `"5eb63bbbe01eeed093cb22bb8f5acdc3"`)
+			case r.Method == http.MethodHead:
+				w.Header().Set("ETag", `"5eb63bbbe01eeed093cb22bb8f5acdc3"`)
+				w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+				w.Header().Set("Content-Length", "11")
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		}))
+
+		setAlias("preflight", aliasConfigV10{
+			URL:       server.URL,
+			AccessKey: "WLGDGYAQYIGI833EV05A",
+			SecretKey: "BYvgJM101sHngl2uzjXS/OBF/aMxAN06JrJ3qJlF",
+			API:       "S3v4",
+			Path:      "auto",
+		})
+		results := preflightTarget(context.Background(), "preflight/bucket/", true)
+		server.Close()
+
+		for _, result := range results {
+			if !result.Allowed {
+				t.Errorf("Test %d: expected %s to be allowed, got %s", i+1, result.Operation, result.Error)
+			}
+		}
+		if !put || !deleted {
+			t.Fatalf("Test %d: expected the temporary object to be written and removed", i+1)
+		}
+		if tagVersionID != testCase.versionID || deleteVersionID != testCase.versionID {
+			t.Errorf("Test %d: expected version %q to be tagged and removed, got %q and %q", i+1, testCase.versionID, tagVersionID, deleteVersionID)
+		}
+	}
+}
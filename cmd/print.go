@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"strings"
 
+	"github.com/jmespath/go-jmespath"
 	"github.com/minio/pkg/console"
 )
 
@@ -38,6 +39,12 @@ func printMsg(msg message) {
 		msgStr = msg.String()
 	} else {
 		msgStr = msg.JSON()
+		if globalQuery != nil {
+			var ok bool
+			if msgStr, ok = queryJSON(msgStr, globalQuery); !ok {
+				return
+			}
+		}
 		if globalJSONLine && strings.ContainsRune(msgStr, '\n') {
 			// Reformat.
 			var dst bytes.Buffer
@@ -48,3 +55,31 @@ func printMsg(msg message) {
 	}
 	console.Println(msgStr)
 }
+
+// printNulTerminated prints s followed by a NUL character instead of a
+// newline, so that names with newlines can be piped to 'xargs -0'.
+func printNulTerminated(s string) {
+	console.Print(s + "\x00")
+}
+
+// queryJSON returns the fields of a JSON message selected by the JMESPath
+// expression, strings are returned unquoted. Messages without a match are
+// not printed.
+func queryJSON(msgStr string, expr *jmespath.JMESPath) (string, bool) {
+	var data interface{}
+	if e := json.Unmarshal([]byte(msgStr), &data); e != nil {
+		return "", false
+	}
+	result, e := expr.Search(data)
+	if e != nil || result == nil {
+		return "", false
+	}
+	if s, ok := result.(string); ok {
+		return s, true
+	}
+	out, e := json.Marshal(result)
+	if e != nil {
+		return "", false
+	}
+	return string(out), true
+}
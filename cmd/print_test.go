@@ -0,0 +1,49 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jmespath/go-jmespath"
+)
+
+func TestQueryJSON(t *testing.T) {
+	msg := `{"status":"success","key":"photos/a.jpg","size":1024,"metadata":{"Content-Type":"image/jpeg"},"versions":[{"id":"v1"},{"id":"v2"}]}`
+	testCases := []struct {
+		query  string
+		output string
+		found  bool
+	}{
+		{"key", "photos/a.jpg", true},
+		{"size", "1024", true},
+		{"metadata", `{"Content-Type":"image/jpeg"}`, true},
+		{`metadata."Content-Type"`, "image/jpeg", true},
+		{"versions[*].id", `["v1","v2"]`, true},
+		{"versions[?id=='v2'] | [0].id", "v2", true},
+		{"{name: key, bytes: size}", `{"bytes":1024,"name":"photos/a.jpg"}`, true},
+		{"etag", "", false},
+	}
+
+	for i, testCase := range testCases {
+		output, found := queryJSON(msg, jmespath.MustCompile(testCase.query))
+		if found != testCase.found || output != testCase.output {
+			t.Fatalf("Test %d: expected %q (%v), got %q (%v)", i+1, testCase.output, testCase.found, output, found)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Bandwidth limit flags of the commands transferring data.
+var transferLimitFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "limit-upload",
+		Usage: "limit the upload bandwidth to object storage, e.g. 50MiB/s",
+	},
+	cli.StringFlag{
+		Name:  "limit-download",
+		Usage: "limit the download bandwidth from object storage, e.g. 50MiB/s",
+	},
+}
+
+// Request rate limit flag of the commands sending many requests.
+var maxOpsFlag = cli.IntFlag{
+	Name:  "max-ops",
+	Usage: "limit the requests sent to object storage per second, shared by all workers",
+}
+
+var (
+	// Bandwidth limits shared by all connections, nil when unlimited.
+	globalUploadLimiter   *rateLimiter
+	globalDownloadLimiter *rateLimiter
+
+	// Requests per second limit shared by all connections, nil when unlimited.
+	globalOpsLimiter *rateLimiter
+)
+
+// rateLimiter bounds the number of bytes per second transferred by
+// all readers sharing it.
+type rateLimiter struct {
+	mu    sync.Mutex
+	rate  float64 // bytes per second
+	start time.Time
+	total float64
+}
+
+// parseRate parses a rate like "50MiB/s" or "1GB" into bytes per second.
+func parseRate(rate string) (uint64, *probe.Error) {
+	bytesPerSec, e := humanize.ParseBytes(strings.TrimSuffix(strings.TrimSpace(rate), "/s"))
+	if e != nil {
+		return 0, probe.NewError(e)
+	}
+	if bytesPerSec == 0 {
+		return 0, errInvalidArgument().Trace(rate)
+	}
+	return bytesPerSec, nil
+}
+
+// newRateLimiter returns a rate limiter allowing bytesPerSec bytes per second.
+func newRateLimiter(bytesPerSec uint64) *rateLimiter {
+	return &rateLimiter{
+		rate:  float64(bytesPerSec),
+		start: time.Now(),
+	}
+}
+
+// wait blocks until n more bytes may be transferred or ctx is done.
+// Credit earned while idle is capped to a second to avoid bursts after
+// pauses.
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	elapsed := now.Sub(l.start)
+	expected := time.Duration(l.total / l.rate * float64(time.Second))
+	if elapsed > expected+time.Second {
+		l.start = now.Add(-time.Second)
+		l.total = l.rate
+		elapsed = time.Second
+	}
+	l.total += float64(n)
+	delay := time.Duration(l.total/l.rate*float64(time.Second)) - elapsed
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// Give the bytes which were not transferred back.
+		l.mu.Lock()
+		l.total -= float64(n)
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// chunkSize returns the maximum size of a single read, small enough to
+// keep the transfer smooth at low rates.
+func (l *rateLimiter) chunkSize() int {
+	chunk := int(l.rate / 10)
+	switch {
+	case chunk < 4*humanize.KiByte:
+		return 4 * humanize.KiByte
+	case chunk > 256*humanize.KiByte:
+		return 256 * humanize.KiByte
+	}
+	return chunk
+}
+
+// rateLimitedReader is an io.Reader throttled by a rateLimiter, reads
+// fail once ctx is done.
+type rateLimitedReader struct {
+	io.Reader
+	ctx     context.Context
+	limiter *rateLimiter
+}
+
+func (r rateLimitedReader) Read(p []byte) (n int, err error) {
+	if chunk := r.limiter.chunkSize(); len(p) > chunk {
+		p = p[:chunk]
+	}
+	n, err = r.Reader.Read(p)
+	if n > 0 {
+		if e := r.limiter.wait(r.ctx, n); e != nil {
+			return n, e
+		}
+	}
+	return n, err
+}
+
+// newRateLimitedReader wraps the reader with the limiter, a nil
+// limiter returns the reader as is.
+func newRateLimitedReader(ctx context.Context, r io.Reader, limiter *rateLimiter) io.Reader {
+	if limiter == nil {
+		return r
+	}
+	return rateLimitedReader{Reader: r, ctx: ctx, limiter: limiter}
+}
+
+// setTransferLimits sets the global bandwidth limits from the
+// --limit-upload and --limit-download flags.
+func setTransferLimits(cliCtx *cli.Context) {
+	if rate := cliCtx.String("limit-upload"); rate != "" {
+		bytesPerSec, err := parseRate(rate)
+		fatalIf(err.Trace(rate), "Unable to parse --limit-upload.")
+		globalUploadLimiter = newRateLimiter(bytesPerSec)
+	}
+	if rate := cliCtx.String("limit-download"); rate != "" {
+		bytesPerSec, err := parseRate(rate)
+		fatalIf(err.Trace(rate), "Unable to parse --limit-download.")
+		globalDownloadLimiter = newRateLimiter(bytesPerSec)
+	}
+}
+
+// setOpsLimit sets the global request rate limit from the --max-ops flag,
+// the limiter then acts as a token bucket holding a second of requests.
+func setOpsLimit(cliCtx *cli.Context) {
+	if !cliCtx.IsSet("max-ops") {
+		return
+	}
+	ops := cliCtx.Int("max-ops")
+	if ops < 1 {
+		fatalIf(errInvalidArgument().Trace(cliCtx.String("max-ops")), "--max-ops must be at least 1.")
+	}
+	globalOpsLimiter = newRateLimiter(uint64(ops))
+}
+
+// rateLimitedReadCloser is an io.ReadCloser throttled by a rateLimiter.
+type rateLimitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// rateLimitedTransport throttles the requests with the global request
+// rate limit, and their request and response bodies with the global
+// bandwidth limits.
+type rateLimitedTransport struct {
+	http.RoundTripper
+}
+
+func (t rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if globalOpsLimiter != nil {
+		if e := globalOpsLimiter.wait(req.Context(), 1); e != nil {
+			return nil, e
+		}
+	}
+	if globalUploadLimiter != nil && req.Body != nil && req.Body != http.NoBody {
+		ctx, limiter := req.Context(), globalUploadLimiter
+		limitBody := func(body io.ReadCloser) io.ReadCloser {
+			if body == http.NoBody {
+				return body
+			}
+			return rateLimitedReadCloser{
+				Reader: newRateLimitedReader(ctx, body, limiter),
+				Closer: body,
+			}
+		}
+		body, getBody := req.Body, req.GetBody
+		req = req.Clone(ctx)
+		req.Body = limitBody(body)
+		// Bodies sent again, e.g. on redirects or retried connections,
+		// are limited as well.
+		if getBody != nil {
+			req.GetBody = func() (io.ReadCloser, error) {
+				body, e := getBody()
+				if e != nil {
+					return nil, e
+				}
+				return limitBody(body), nil
+			}
+		}
+	}
+	resp, e := t.RoundTripper.RoundTrip(req)
+	if e == nil && globalDownloadLimiter != nil && resp.Body != nil {
+		resp.Body = rateLimitedReadCloser{
+			Reader: newRateLimitedReader(req.Context(), resp.Body, globalDownloadLimiter),
+			Closer: resp.Body,
+		}
+	}
+	return resp, e
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRateLimitedTransportMaxOps(t *testing.T) {
+	defer func(limiter *rateLimiter) { globalOpsLimiter = limiter }(globalOpsLimiter)
+	globalOpsLimiter = newRateLimiter(100)
+
+	transport := rateLimitedTransport{roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})}
+
+	start := time.Now()
+	for i := 0; i < 30; i++ {
+		req, _ := http.NewRequest(http.MethodHead, "http://localhost:9000/bucket/object", nil)
+		if _, e := transport.RoundTrip(req); e != nil {
+			t.Fatal(e)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("expected 30 requests at 100 per second to take at least 250ms, took %v", elapsed)
+	}
+}
+
+func TestRateLimitedTransportGetBody(t *testing.T) {
+	defer func(limiter *rateLimiter) { globalUploadLimiter = limiter }(globalUploadLimiter)
+	globalUploadLimiter = newRateLimiter(1 << 20)
+
+	var sent *http.Request
+	transport := rateLimitedTransport{roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		sent = req
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})}
+	req, _ := http.NewRequest(http.MethodPut, "http://localhost:9000/bucket/object", bytes.NewReader([]byte("data")))
+	if _, e := transport.RoundTrip(req); e != nil {
+		t.Fatal(e)
+	}
+	if _, ok := sent.Body.(rateLimitedReadCloser); !ok {
+		t.Fatalf("expected the body to be limited, got %T", sent.Body)
+	}
+	// The body sent again on retries is limited as well.
+	body, e := sent.GetBody()
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, ok := body.(rateLimitedReadCloser); !ok {
+		t.Fatalf("expected the body of retries to be limited, got %T", body)
+	}
+	if data, _ := ioutil.ReadAll(body); string(data) != "data" {
+		t.Fatalf("expected the body of retries to be sent again, got %q", data)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	testCases := []struct {
+		rate    string
+		bytes   uint64
+		success bool
+	}{
+		{"50MiB/s", 50 << 20, true},
+		{" 1GB ", 1000 * 1000 * 1000, true},
+		{"0/s", 0, false},
+		{"fast", 0, false},
+	}
+	for i, testCase := range testCases {
+		bytesPerSec, err := parseRate(testCase.rate)
+		if (err == nil) != testCase.success || bytesPerSec != testCase.bytes {
+			t.Errorf("Test %d: expected %d (success %v), got %d: %v", i+1, testCase.bytes, testCase.success, bytesPerSec, err)
+		}
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	l := newRateLimiter(10000)
+	ctx := context.Background()
+	start := time.Now()
+	if e := l.wait(ctx, 1000); e != nil {
+		t.Fatal(e)
+	}
+	if e := l.wait(ctx, 500); e != nil {
+		t.Fatal(e)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("expected 1500 bytes at 10000 per second to take at least 100ms, took %v", elapsed)
+	}
+
+	// A cancelled wait returns at once and gives its bytes back.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	total := l.total
+	start = time.Now()
+	if e := l.wait(ctx, 5000); e != context.Canceled {
+		t.Fatalf("expected the wait to be cancelled, got %v", e)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected a cancelled wait to return at once, took %v", elapsed)
+	}
+	if l.total != total {
+		t.Errorf("expected the cancelled bytes to be given back, total %v instead of %v", l.total, total)
+	}
+}
+
+func TestRateLimitedReaderCancel(t *testing.T) {
+	data := bytes.Repeat([]byte("a"), 64*1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := newRateLimitedReader(ctx, bytes.NewReader(data), newRateLimiter(4096))
+
+	// The first read of 4KiB waits for a second, unless cancelled.
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	if _, e := ioutil.ReadAll(reader); e != context.Canceled {
+		t.Fatalf("expected the read to be cancelled, got %v", e)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("expected the read to stop once cancelled, took %v", elapsed)
+	}
+	if newRateLimitedReader(ctx, bytes.NewReader(data), nil) == nil {
+		t.Error("expected the reader to be returned as is without a limiter")
+	}
+}
@@ -0,0 +1,327 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/replication"
+	"github.com/minio/pkg/console"
+)
+
+var replicateLagFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "watch, w",
+		Usage: "refresh replication lag continuously",
+	},
+	cli.DurationFlag{
+		Name:  "interval",
+		Usage: "refresh interval when watching",
+		Value: 2 * time.Second,
+	},
+}
+
+var replicateLagCmd = cli.Command{
+	Name:         "lag",
+	Usage:        "show server side replication backlog per remote target",
+	Action:       mainReplicateLag,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(replicateLagFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Show replication queue depth, oldest pending object and bandwidth for bucket "mybucket" for alias "myminio".
+     The oldest pending object is looked up in a sample of the object versions of large buckets.
+     {{.Prompt}} {{.HelpName}} myminio/mybucket
+
+  2. Watch the replication backlog of bucket "mybucket" drain, refreshing every 5 seconds.
+     {{.Prompt}} {{.HelpName}} --watch --interval 5s myminio/mybucket
+`,
+}
+
+// checkReplicateLagSyntax - validate all the passed arguments
+func checkReplicateLagSyntax(ctx *cli.Context) {
+	if len(ctx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(ctx, "lag", 1) // last argument is exit code
+	}
+	if ctx.Duration("interval") <= 0 {
+		fatalIf(errInvalidArgument().Trace(ctx.Args()...), "Refresh interval should be greater than zero.")
+	}
+}
+
+// replicateLagTarget holds the replication backlog of a remote target.
+type replicateLagTarget struct {
+	Arn          string  `json:"arn"`
+	PendingCount uint64  `json:"pendingCount"`
+	PendingSize  uint64  `json:"pendingSize"`
+	FailedCount  uint64  `json:"failedCount"`
+	FailedSize   uint64  `json:"failedSize"`
+	Bandwidth    float64 `json:"bandwidth"`
+}
+
+type replicateLagMessage struct {
+	Op            string     `json:"op"`
+	URL           string     `json:"url"`
+	Status        string     `json:"status"`
+	PendingCount  uint64     `json:"pendingCount"`
+	PendingSize   uint64     `json:"pendingSize"`
+	OldestPending *time.Time `json:"oldestPending,omitempty"`
+	// OldestPendingSampled is set when the oldest pending version was
+	// looked up in a sample of the versions only.
+	OldestPendingSampled bool                 `json:"oldestPendingSampled,omitempty"`
+	Targets              []replicateLagTarget `json:"targets"`
+}
+
+func (s replicateLagMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+func (s replicateLagMessage) String() string {
+	var b strings.Builder
+
+	oldest := "-"
+	if s.OldestPending != nil {
+		oldest = timeDurationToHumanizedDuration(time.Since(*s.OldestPending)).StringShort()
+	}
+	if s.OldestPendingSampled {
+		oldest += " (sampled)"
+	}
+	b.WriteString(console.Colorize("THeaders", "Replication lag for "+s.URL))
+	b.WriteString("\n")
+	b.WriteString(console.Colorize("Pending", "Pending: "+humanize.Comma(int64(s.PendingCount))+
+		" objects, "+humanize.IBytes(s.PendingSize)+", oldest pending "+oldest))
+	b.WriteString("\n")
+
+	table := newPrettyTable(" | ",
+		Field{"Arn", 60},
+		Field{"Count", 12},
+		Field{"Size", 12},
+		Field{"Failed", 12},
+		Field{"Bandwidth", 14},
+	)
+	b.WriteString(console.Colorize("TgtHeaders", table.buildRow("Target", "Pending", "Pending Size", "Failed", "Bandwidth")))
+	for _, t := range s.Targets {
+		b.WriteString("\n")
+		b.WriteString(console.Colorize("Pending", table.buildRow(t.Arn,
+			humanize.Comma(int64(t.PendingCount)),
+			humanize.IBytes(t.PendingSize),
+			humanize.Comma(int64(t.FailedCount)),
+			humanize.IBytes(uint64(t.Bandwidth))+"/s")))
+	}
+	return b.String()
+}
+
+// replicationLagTargets computes the backlog of each remote target, the
+// bandwidth is derived from the replicated bytes since the previous sample.
+func replicationLagTargets(prev, cur replication.Metrics, elapsed time.Duration) []replicateLagTarget {
+	var targets []replicateLagTarget
+	for arn, st := range cur.Stats {
+		t := replicateLagTarget{
+			Arn:          arn,
+			PendingCount: st.PendingCount,
+			PendingSize:  st.PendingSize,
+			FailedCount:  st.FailedCount,
+			FailedSize:   st.FailedSize,
+		}
+		if p, ok := prev.Stats[arn]; ok && elapsed > 0 && st.ReplicatedSize > p.ReplicatedSize {
+			t.Bandwidth = float64(st.ReplicatedSize-p.ReplicatedSize) / elapsed.Seconds()
+		}
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Arn < targets[j].Arn
+	})
+	return targets
+}
+
+const (
+	// replicateLagSampleSize bounds the current object versions listed
+	// to find the oldest pending one, their listing carries the status.
+	replicateLagSampleSize = 1000
+	// replicateLagVersionsSampleSize bounds the noncurrent versions
+	// whose status is fetched one by one.
+	replicateLagVersionsSampleSize = 100
+	// replicateLagSampleInterval is the least time between two samples
+	// of the oldest pending version when watching.
+	replicateLagSampleInterval = time.Minute
+)
+
+// oldestPending returns the modification time of the oldest pending
+// version among the first limit listed versions, whose status is
+// returned by status. It is not complete when more versions are listed.
+func oldestPending(contents <-chan *ClientContent, limit int, status func(*ClientContent) string) (oldest *time.Time, complete bool) {
+	var n int
+	for content := range contents {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(), "Unable to list the object versions.")
+			continue
+		}
+		if n == limit {
+			// Let the canceled listing finish.
+			go func() {
+				for range contents {
+				}
+			}()
+			return oldest, false
+		}
+		n++
+		if status(content) != "PENDING" {
+			continue
+		}
+		if oldest == nil || content.Time.Before(*oldest) {
+			t := content.Time
+			oldest = &t
+		}
+	}
+	return oldest, true
+}
+
+// oldestPendingReplication returns the modification time of the oldest
+// object version waiting to be replicated among a bounded sample of the
+// current and noncurrent versions, and whether the sample covered all
+// versions.
+func oldestPendingReplication(ctx context.Context, clnt Client, alias string) (*time.Time, bool) {
+	listCtx, cancelList := context.WithCancel(ctx)
+	oldest, complete := oldestPending(clnt.List(listCtx, ListOptions{Recursive: true, WithMetadata: true, ShowDir: DirNone}),
+		replicateLagSampleSize, func(content *ClientContent) string {
+			return content.ReplicationStatus
+		})
+	cancelList()
+
+	// Version listings do not carry the replication status.
+	versionsCtx, cancelVersions := context.WithCancel(ctx)
+	defer cancelVersions()
+	noncurrent := make(chan *ClientContent)
+	go func() {
+		defer close(noncurrent)
+		for content := range clnt.List(versionsCtx, ListOptions{Recursive: true, WithOlderVersions: true, ShowDir: DirNone}) {
+			if content.Err == nil && (content.IsLatest || content.IsDeleteMarker) {
+				continue
+			}
+			select {
+			case noncurrent <- content:
+			case <-versionsCtx.Done():
+				return
+			}
+		}
+	}()
+	oldestVersion, completeVersions := oldestPending(noncurrent, replicateLagVersionsSampleSize, func(content *ClientContent) string {
+		versionClnt, err := newClientFromAlias(alias, content.URL.String())
+		if err != nil {
+			errorIf(err.Trace(content.URL.String()), "Unable to get the replication status.")
+			return ""
+		}
+		version, err := versionClnt.Stat(ctx, StatOptions{versionID: content.VersionID})
+		if err != nil {
+			errorIf(err.Trace(content.URL.String(), content.VersionID), "Unable to get the replication status.")
+			return ""
+		}
+		return version.ReplicationStatus
+	})
+	if oldestVersion != nil && (oldest == nil || oldestVersion.Before(*oldest)) {
+		oldest = oldestVersion
+	}
+	return oldest, complete && completeVersions
+}
+
+func mainReplicateLag(cliCtx *cli.Context) error {
+	ctx, cancelReplicateLag := context.WithCancel(globalContext)
+	defer cancelReplicateLag()
+
+	console.SetColor("THeaders", color.New(color.Bold, color.FgHiWhite))
+	console.SetColor("TgtHeaders", color.New(color.Bold, color.FgCyan))
+	console.SetColor("Pending", color.New(color.FgWhite))
+
+	checkReplicateLagSyntax(cliCtx)
+
+	// Get the alias parameter from cli
+	args := cliCtx.Args()
+	aliasedURL := args.Get(0)
+	watch := cliCtx.Bool("watch")
+	interval := cliCtx.Duration("interval")
+
+	// Create a new Client
+	client, err := newClient(aliasedURL)
+	fatalIf(err, "Unable to initialize connection.")
+	alias, _ := url2Alias(aliasedURL)
+
+	var prev replication.Metrics
+	var prevTime, sampleTime time.Time
+	var oldestPending *time.Time
+	var oldestPendingSampled bool
+	var rewind int
+	for {
+		metrics, err := client.GetReplicationMetrics(ctx)
+		fatalIf(err.Trace(args...), "Unable to get replication status")
+		now := time.Now()
+
+		msg := replicateLagMessage{
+			Op:           "lag",
+			URL:          aliasedURL,
+			PendingCount: metrics.PendingCount,
+			PendingSize:  metrics.PendingSize,
+			Targets:      replicationLagTargets(prev, metrics, now.Sub(prevTime)),
+		}
+		switch {
+		case metrics.PendingCount == 0:
+			oldestPending, oldestPendingSampled = nil, false
+		case now.Sub(sampleTime) >= replicateLagSampleInterval:
+			var complete bool
+			oldestPending, complete = oldestPendingReplication(ctx, client, alias)
+			oldestPendingSampled = !complete
+			sampleTime = now
+		}
+		msg.OldestPending, msg.OldestPendingSampled = oldestPending, oldestPendingSampled
+
+		if !globalJSON {
+			console.RewindLines(rewind)
+			rewind = strings.Count(msg.String(), "\n") + 1
+		}
+		printMsg(msg)
+
+		if !watch {
+			return nil
+		}
+		prev, prevTime = metrics, now
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
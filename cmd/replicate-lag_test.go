@@ -0,0 +1,75 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/replication"
+)
+
+func TestOldestPending(t *testing.T) {
+	base := time.Date(2021, time.December, 1, 0, 0, 0, 0, time.UTC)
+	versions := []*ClientContent{
+		{Time: base.Add(3 * time.Hour), ReplicationStatus: "PENDING"},
+		{Time: base, ReplicationStatus: "COMPLETED"},
+		{Time: base.Add(time.Hour), ReplicationStatus: "PENDING"},
+		{Time: base.Add(-time.Hour), ReplicationStatus: "PENDING"},
+	}
+	testCases := []struct {
+		limit    int
+		oldest   time.Time
+		complete bool
+	}{
+		{10, base.Add(-time.Hour), true},
+		{4, base.Add(-time.Hour), true},
+		{3, base.Add(time.Hour), false},
+		{2, base.Add(3 * time.Hour), false},
+	}
+	for i, testCase := range testCases {
+		contents := make(chan *ClientContent, len(versions))
+		for _, content := range versions {
+			contents <- content
+		}
+		close(contents)
+		oldest, complete := oldestPending(contents, testCase.limit, func(content *ClientContent) string {
+			return content.ReplicationStatus
+		})
+		if oldest == nil || !oldest.Equal(testCase.oldest) || complete != testCase.complete {
+			t.Errorf("Test %d: expected %v complete %t, got %v complete %t", i+1, testCase.oldest, testCase.complete, oldest, complete)
+		}
+	}
+}
+
+func TestReplicationLagTargets(t *testing.T) {
+	prev := replication.Metrics{Stats: map[string]replication.TargetMetrics{
+		"arn:b": {ReplicatedSize: 1000},
+	}}
+	cur := replication.Metrics{Stats: map[string]replication.TargetMetrics{
+		"arn:b": {ReplicatedSize: 5000, PendingCount: 3},
+		"arn:a": {ReplicatedSize: 100},
+	}}
+	targets := replicationLagTargets(prev, cur, 2*time.Second)
+	if len(targets) != 2 || targets[0].Arn != "arn:a" || targets[1].Arn != "arn:b" {
+		t.Fatalf("expected the targets sorted by ARN, got %+v", targets)
+	}
+	if targets[0].Bandwidth != 0 || targets[1].Bandwidth != 2000 || targets[1].PendingCount != 3 {
+		t.Errorf("unexpected targets %+v", targets)
+	}
+}
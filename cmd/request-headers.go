@@ -0,0 +1,218 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"golang.org/x/net/http/httpguts"
+)
+
+// Flags adding headers to the S3 requests of a command.
+var requestHeaderFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "request-payer",
+		Usage: "acknowledge the requester pays for the requests to the bucket, valid option is '[requester]'",
+	},
+	cli.StringSliceFlag{
+		Name:  "header",
+		Usage: "add the header 'NAME: VALUE' to the S3 requests, can be repeated",
+	},
+}
+
+// Headers set by the signature, which cannot be overridden.
+var reservedRequestHeaders = map[string]bool{
+	"Authorization":        true,
+	"Host":                 true,
+	"X-Amz-Date":           true,
+	"X-Amz-Content-Sha256": true,
+	"X-Amz-Security-Token": true,
+}
+
+// Payload hash of the uploads signed chunk by chunk.
+const streamingSignV4Payload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// Headers added to all S3 requests, empty when not set.
+var globalRequestHeaders = http.Header{}
+
+// parseRequestHeader parses a 'NAME: VALUE' header.
+func parseRequestHeader(header string) (string, string, *probe.Error) {
+	i := strings.Index(header, ":")
+	if i <= 0 {
+		return "", "", errInvalidArgument().Trace(header)
+	}
+	name, value := http.CanonicalHeaderKey(strings.TrimSpace(header[:i])), strings.TrimSpace(header[i+1:])
+	if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) || reservedRequestHeaders[name] {
+		return "", "", errInvalidArgument().Trace(header)
+	}
+	return name, value, nil
+}
+
+// setRequestHeaders sets the headers added to the S3 requests from the
+// --request-payer and --header flags.
+func setRequestHeaders(cliCtx *cli.Context) {
+	for _, header := range cliCtx.StringSlice("header") {
+		name, value, err := parseRequestHeader(header)
+		fatalIf(err, "Unable to parse --header, expected `NAME: VALUE`.")
+		globalRequestHeaders.Add(name, value)
+	}
+	if payer := cliCtx.String("request-payer"); payer != "" {
+		if !strings.EqualFold(payer, "requester") {
+			fatalIf(errInvalidArgument().Trace(payer), "Unrecognized request payer. Valid option is `[requester]`.")
+		}
+		globalRequestHeaders.Set("X-Amz-Request-Payer", "requester")
+	}
+}
+
+// ifNotExistsKey is the context key of the uploads creating objects
+// only when they do not exist yet.
+type ifNotExistsKey struct{}
+
+// withIfNotExists returns a context whose uploads are sent with the
+// 'If-None-Match: *' header, refused by the servers supporting
+// conditional writes when the object exists, even when created by a
+// concurrent writer after it was checked.
+func withIfNotExists(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ifNotExistsKey{}, true)
+}
+
+// isIfNotExists returns true if the uploads of the context must not
+// replace an existing object.
+func isIfNotExists(ctx context.Context) bool {
+	return ctx.Value(ifNotExistsKey{}) != nil
+}
+
+// checksumsKey is the context key of the HEAD requests asking for the
+// checksums stored with objects.
+type checksumsKey struct{}
+
+// Prefix of the headers returning the checksums stored with an object.
+const checksumHeaderPrefix = "X-Amz-Checksum-"
+
+// withChecksums returns a context whose HEAD requests ask for the checksums
+// stored with the object, which are added to checksums keyed by algorithm,
+// e.g. 'CRC32C' or 'SHA256', when the server returns them.
+func withChecksums(ctx context.Context, checksums map[string]string) context.Context {
+	return context.WithValue(ctx, checksumsKey{}, checksums)
+}
+
+// isObjectWrite returns true for the requests creating an object, a
+// single part upload or copy and the completion of a multipart upload.
+func isObjectWrite(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPut:
+		return req.URL.RawQuery == ""
+	case http.MethodPost:
+		query := req.URL.Query()
+		return len(query) == 1 && query.Get("uploadId") != ""
+	}
+	return false
+}
+
+// isErrPreconditionFailed returns true if a conditional write was
+// refused because the object exists.
+func isErrPreconditionFailed(err *probe.Error) bool {
+	if err == nil {
+		return false
+	}
+	if _, ok := err.ToGoError().(ObjectAlreadyExists); ok {
+		return true
+	}
+	return minio.ToErrorResponse(err.ToGoError()).Code == "PreconditionFailed"
+}
+
+// requestHeaderTransport adds the global request headers to the S3
+// requests. AWS requires all x-amz- headers to be signed, requests
+// signed with signature V4 are then signed again with these headers.
+type requestHeaderTransport struct {
+	http.RoundTripper
+	creds *credentials.Credentials
+}
+
+func (t requestHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ifNotExists := isIfNotExists(req.Context()) && isObjectWrite(req)
+	checksums, _ := req.Context().Value(checksumsKey{}).(map[string]string)
+	if req.Method != http.MethodHead {
+		checksums = nil
+	}
+	if len(globalRequestHeaders) == 0 && !ifNotExists && checksums == nil {
+		return t.RoundTripper.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if ifNotExists {
+		req.Header.Set("If-None-Match", "*")
+	}
+	resign := false
+	if checksums != nil {
+		// Signature V2 requests cannot be signed again with the header.
+		authorization := req.Header.Get("Authorization")
+		if _, ok := signatureV4Region(authorization); ok || authorization == "" {
+			req.Header.Set("X-Amz-Checksum-Mode", "ENABLED")
+			resign = true
+		}
+	}
+	for name, values := range globalRequestHeaders {
+		req.Header[name] = values
+		resign = resign || strings.HasPrefix(name, "X-Amz-")
+	}
+	// Signed streaming uploads are left as is since their chunks are
+	// signed from the signature of the headers.
+	if resign && req.Header.Get("X-Amz-Content-Sha256") != streamingSignV4Payload {
+		if region, ok := signatureV4Region(req.Header.Get("Authorization")); ok {
+			value, e := t.creds.Get()
+			if e != nil {
+				return nil, e
+			}
+			req = signer.SignV4(*req, value.AccessKeyID, value.SecretAccessKey, value.SessionToken, region)
+		}
+	}
+	resp, e := t.RoundTripper.RoundTrip(req)
+	if e == nil && checksums != nil {
+		for name := range resp.Header {
+			algo := strings.ToUpper(strings.TrimPrefix(name, checksumHeaderPrefix))
+			if strings.HasPrefix(name, checksumHeaderPrefix) && algo != "MODE" && algo != "TYPE" {
+				checksums[algo] = resp.Header.Get(name)
+			}
+		}
+	}
+	return resp, e
+}
+
+// signatureV4Region returns the region of a signature V4 Authorization header.
+func signatureV4Region(authorization string) (string, bool) {
+	const credentialPrefix = "Credential="
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 ") {
+		return "", false
+	}
+	i := strings.Index(authorization, credentialPrefix)
+	if i < 0 {
+		return "", false
+	}
+	scope := strings.Split(strings.SplitN(authorization[i+len(credentialPrefix):], ",", 2)[0], "/")
+	if len(scope) != 5 {
+		return "", false
+	}
+	return scope[2], true
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/signer"
+)
+
+func TestParseRequestHeader(t *testing.T) {
+	testCases := []struct {
+		header      string
+		name, value string
+		ok          bool
+	}{
+		{"x-tenant-id: acme", "X-Tenant-Id", "acme", true},
+		{"X-Amz-Request-Payer:requester", "X-Amz-Request-Payer", "requester", true},
+		{"X-Empty:", "X-Empty", "", true},
+		{"no-colon", "", "", false},
+		{": value", "", "", false},
+		{"Authorization: AWS4-HMAC-SHA256", "", "", false},
+		{"bad header: value", "", "", false},
+	}
+	for i, testCase := range testCases {
+		name, value, err := parseRequestHeader(testCase.header)
+		if (err == nil) != testCase.ok || name != testCase.name || value != testCase.value {
+			t.Errorf("Test %d: expected (%q, %q, %v), got (%q, %q, %v)", i+1, testCase.name, testCase.value, testCase.ok, name, value, err)
+		}
+	}
+}
+
+func TestRequestHeaderTransport(t *testing.T) {
+	defer func(headers http.Header) { globalRequestHeaders = headers }(globalRequestHeaders)
+	globalRequestHeaders = http.Header{"X-Amz-Request-Payer": {"requester"}}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://s3.amazonaws.com/bucket/object", nil)
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	req = signer.SignV4(*req, "access", "secret", "", "eu-west-1")
+
+	var sent *http.Request
+	transport := requestHeaderTransport{
+		RoundTripper: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}),
+		creds: credentials.NewStaticV4("access", "secret", ""),
+	}
+	if _, e := transport.RoundTrip(req); e != nil {
+		t.Fatal(e)
+	}
+	if sent.Header.Get("X-Amz-Request-Payer") != "requester" {
+		t.Fatalf("expected the request payer header, got %v", sent.Header)
+	}
+	authorization := sent.Header.Get("Authorization")
+	if !strings.Contains(authorization, "x-amz-request-payer") || !strings.Contains(authorization, "/eu-west-1/s3/") {
+		t.Errorf("expected the request to be signed again in eu-west-1 with the header, got %s", authorization)
+	}
+	if req.Header.Get("X-Amz-Request-Payer") != "" {
+		t.Error("expected the original request to be left untouched")
+	}
+}
+
+func TestRequestHeaderTransportIfNotExists(t *testing.T) {
+	var sent *http.Request
+	transport := requestHeaderTransport{
+		RoundTripper: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}),
+	}
+	testCases := []struct {
+		method, url string
+		condition   bool
+	}{
+		{http.MethodPut, "https://play.min.io/bucket/object", true},
+		{http.MethodPost, "https://play.min.io/bucket/object?uploadId=abc", true},
+		{http.MethodPost, "https://play.min.io/bucket/object?uploads=", false},
+		{http.MethodPut, "https://play.min.io/bucket/object?partNumber=1&uploadId=abc", false},
+		{http.MethodPut, "https://play.min.io/bucket/object?tagging=", false},
+		{http.MethodGet, "https://play.min.io/bucket/object", false},
+	}
+	for _, testCase := range testCases {
+		req, _ := http.NewRequestWithContext(withIfNotExists(context.Background()), testCase.method, testCase.url, nil)
+		if _, e := transport.RoundTrip(req); e != nil {
+			t.Fatal(e)
+		}
+		if condition := sent.Header.Get("If-None-Match") == "*"; condition != testCase.condition {
+			t.Errorf("%s %s: expected condition %t, got %t", testCase.method, testCase.url, testCase.condition, condition)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, "https://play.min.io/bucket/object", nil)
+	if _, e := transport.RoundTrip(req); e != nil {
+		t.Fatal(e)
+	}
+	if sent.Header.Get("If-None-Match") != "" {
+		t.Error("expected no condition without --if-not-exists")
+	}
+}
+
+func TestRequestHeaderTransportChecksums(t *testing.T) {
+	var sent *http.Request
+	transport := requestHeaderTransport{
+		RoundTripper: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			sent = req
+			header := http.Header{}
+			header.Set("X-Amz-Checksum-Crc32c", "yZRlqg==")
+			header.Set("X-Amz-Checksum-Type", "FULL_OBJECT")
+			header.Set("ETag", `"abc"`)
+			return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+		}),
+		creds: credentials.NewStaticV4("access", "secret", ""),
+	}
+
+	req, _ := http.NewRequest(http.MethodHead, "https://s3.amazonaws.com/bucket/object", nil)
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	req = signer.SignV4(*req, "access", "secret", "", "us-east-1")
+	checksums := map[string]string{}
+	if _, e := transport.RoundTrip(req.WithContext(withChecksums(context.Background(), checksums))); e != nil {
+		t.Fatal(e)
+	}
+	if sent.Header.Get("X-Amz-Checksum-Mode") != "ENABLED" || !strings.Contains(sent.Header.Get("Authorization"), "x-amz-checksum-mode") {
+		t.Errorf("expected a signed checksum mode header, got %v", sent.Header)
+	}
+	if !reflect.DeepEqual(checksums, map[string]string{"CRC32C": "yZRlqg=="}) {
+		t.Errorf("expected the CRC32C checksum, got %v", checksums)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://s3.amazonaws.com/bucket/object", nil)
+	if _, e := transport.RoundTrip(req.WithContext(withChecksums(context.Background(), map[string]string{}))); e != nil {
+		t.Fatal(e)
+	}
+	if sent.Header.Get("X-Amz-Checksum-Mode") != "" {
+		t.Error("expected checksums to be asked only by HEAD requests")
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/pkg/console"
+)
+
+// Retry flags of the commands transferring objects.
+var retryFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "retry",
+		Usage: "retry a failed object transfer up to N times, 0 fails on the first error (default 3)",
+	},
+	cli.StringFlag{
+		Name:  "retry-max-wait",
+		Usage: "longest wait between two retries, e.g. 1m (default 30s)",
+	},
+	cli.StringFlag{
+		Name:  "retry-on",
+		Usage: "comma separated error classes (network, timeout, throttle, server) and HTTP status codes to retry on",
+	},
+}
+
+// Retry flags of pipe, whose input cannot be read twice.
+var pipeRetryFlags = []cli.Flag{
+	cli.IntFlag{
+		Name:  "retry",
+		Usage: "retry a failed request up to N times, 0 fails on the first error",
+	},
+	cli.StringFlag{
+		Name:  "retry-max-wait",
+		Usage: "longest wait between two retries of a request, e.g. 1m",
+	},
+}
+
+const (
+	defaultTransferRetries = 3
+	defaultRetryMaxWait    = 30 * time.Second
+	retryUnit              = 500 * time.Millisecond
+)
+
+// Error classes accepted by --retry-on.
+var retryErrorClasses = []string{"network", "timeout", "throttle", "server"}
+
+// S3 error codes telling the client to slow down.
+var throttleErrorCodes = map[string]bool{
+	"SlowDown":               true,
+	"Throttling":             true,
+	"ThrottlingException":    true,
+	"RequestLimitExceeded":   true,
+	"TooManyRequests":        true,
+	"XMinioServerNotReady":   true,
+	"RequestThrottled":       true,
+	"BandwidthLimitExceeded": true,
+}
+
+// Retry policy of the object transfers, nil when failed transfers are
+// not retried.
+var globalRetryPolicy *retryPolicy
+
+// retryPolicy decides which failed transfers are retried and how long
+// to wait before each retry.
+type retryPolicy struct {
+	retries     int
+	maxWait     time.Duration
+	classes     map[string]bool
+	statusCodes map[int]bool
+}
+
+// parseRetryPolicy parses the values of the retry flags, retries is
+// negative when not given.
+func parseRetryPolicy(retries int, maxWait, retryOn string) (*retryPolicy, *probe.Error) {
+	p := &retryPolicy{
+		retries:     retries,
+		maxWait:     defaultRetryMaxWait,
+		classes:     map[string]bool{},
+		statusCodes: map[int]bool{},
+	}
+	if retries < 0 {
+		p.retries = defaultTransferRetries
+	}
+	if maxWait != "" {
+		d, e := time.ParseDuration(maxWait)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		if d <= 0 {
+			return nil, errInvalidArgument().Trace(maxWait)
+		}
+		p.maxWait = d
+	}
+	if retryOn == "" {
+		for _, class := range retryErrorClasses {
+			p.classes[class] = true
+		}
+		return p, nil
+	}
+	for _, v := range strings.Split(retryOn, ",") {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if code, e := strconv.Atoi(v); e == nil {
+			if code < 100 || code > 599 {
+				return nil, errInvalidArgument().Trace(v)
+			}
+			p.statusCodes[code] = true
+			continue
+		}
+		valid := false
+		for _, class := range retryErrorClasses {
+			if v == class {
+				valid = true
+			}
+		}
+		if !valid {
+			return nil, errInvalidArgument().Trace(v)
+		}
+		p.classes[v] = true
+	}
+	return p, nil
+}
+
+// errorClass returns the --retry-on class of an error, or an empty
+// string when the error is not worth retrying.
+func errorClass(e error) string {
+	if errResp := minio.ToErrorResponse(e); errResp.StatusCode != 0 {
+		switch {
+		case errResp.StatusCode == 429, errResp.StatusCode == 503, throttleErrorCodes[errResp.Code]:
+			return "throttle"
+		case errResp.StatusCode == 408, errResp.Code == "RequestTimeout":
+			return "timeout"
+		case errResp.StatusCode >= 500:
+			return "server"
+		}
+		return ""
+	}
+	if errors.Is(e, context.Canceled) {
+		return ""
+	}
+	var netErr net.Error
+	if errors.Is(e, context.DeadlineExceeded) || (errors.As(e, &netErr) && netErr.Timeout()) {
+		return "timeout"
+	}
+	var unexpectedEOF UnexpectedEOF
+	switch {
+	case errors.As(e, &unexpectedEOF), errors.As(e, &netErr),
+		errors.Is(e, io.ErrUnexpectedEOF), errors.Is(e, io.EOF),
+		errors.Is(e, syscall.ECONNRESET), errors.Is(e, syscall.ECONNREFUSED), errors.Is(e, syscall.EPIPE):
+		return "network"
+	}
+	return ""
+}
+
+// retryable tells if a transfer failing with err should be retried.
+func (p *retryPolicy) retryable(err *probe.Error) bool {
+	e := err.ToGoError()
+	if code := minio.ToErrorResponse(e).StatusCode; code != 0 && p.statusCodes[code] {
+		return true
+	}
+	class := errorClass(e)
+	return class != "" && p.classes[class]
+}
+
+// backoff returns the wait before the given retry, doubling with each
+// retry up to the maximum wait, with jitter to spread concurrent retries.
+func (p *retryPolicy) backoff(retry int) time.Duration {
+	wait := p.maxWait
+	if retry < 32 && retryUnit<<uint(retry-1) < wait {
+		wait = retryUnit << uint(retry-1)
+	}
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retryMessage is printed before a failed transfer is retried.
+type retryMessage struct {
+	Status     string `json:"status"`
+	Source     string `json:"source"`
+	Target     string `json:"target"`
+	Retry      int    `json:"retry"`
+	MaxRetries int    `json:"maxRetries"`
+	Wait       string `json:"wait"`
+	Error      string `json:"error"`
+}
+
+func (r retryMessage) String() string {
+	return console.Colorize("Retry", fmt.Sprintf("Retrying `%s` -> `%s` in %s (retry %d of %d): %s",
+		r.Source, r.Target, r.Wait, r.Retry, r.MaxRetries, r.Error))
+}
+
+func (r retryMessage) JSON() string {
+	r.Status = "retry"
+	retryMessageBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+
+	return string(retryMessageBytes)
+}
+
+// attemptReader counts the progress made by a single transfer attempt,
+// so that it can be taken back when the attempt fails.
+type attemptReader struct {
+	progress io.Reader
+	n        int64
+}
+
+func (r *attemptReader) Read(p []byte) (int, error) {
+	n, e := r.progress.Read(p)
+	atomic.AddInt64(&r.n, int64(n))
+	return n, e
+}
+
+// undo takes the progress of the attempt back.
+func (r *attemptReader) undo() {
+	undoProgress(r.progress, atomic.SwapInt64(&r.n, 0))
+}
+
+// undoProgress takes n bytes back from a progress reader.
+func undoProgress(progress io.Reader, n int64) {
+	switch p := progress.(type) {
+	case *objectTransfer:
+		p.undo(n)
+	case *progressBar:
+		p.ProgressBar.Add64(-n)
+	case *accounter:
+		p.Add(-n)
+	case Status:
+		p.Add(-n)
+	}
+}
+
+// retryTransfer runs transfer until it succeeds or the retry policy
+// gives up, printing a message before each retry.
+func retryTransfer(ctx context.Context, urls URLs, progress io.Reader, printRetry func(message), transfer func(progress io.Reader) URLs) URLs {
+	if globalRetryPolicy == nil || progress == nil {
+		return transfer(progress)
+	}
+	attempt := &attemptReader{progress: progress}
+	for retry := 1; ; retry++ {
+		ret := transfer(attempt)
+		if ret.Error == nil || retry > globalRetryPolicy.retries || !globalRetryPolicy.retryable(ret.Error) {
+			return ret
+		}
+		attempt.undo()
+		transferRetried(progress)
+
+		wait := globalRetryPolicy.backoff(retry)
+		printRetry(retryMessage{
+			Source:     filepath.ToSlash(filepath.Join(urls.SourceAlias, urls.SourceContent.URL.Path)),
+			Target:     filepath.ToSlash(filepath.Join(urls.TargetAlias, urls.TargetContent.URL.Path)),
+			Retry:      retry,
+			MaxRetries: globalRetryPolicy.retries,
+			Wait:       wait.String(),
+			Error:      ret.Error.ToGoError().Error(),
+		})
+		select {
+		case <-ctx.Done():
+			return ret
+		case <-time.After(wait):
+		}
+	}
+}
+
+// setRetryPolicy sets the retry policy of the object transfers from
+// the retry flags.
+func setRetryPolicy(cliCtx *cli.Context) {
+	if !cliCtx.IsSet("retry") && !cliCtx.IsSet("retry-max-wait") && !cliCtx.IsSet("retry-on") {
+		return
+	}
+	retries := -1
+	if cliCtx.IsSet("retry") {
+		retries = cliCtx.Int("retry")
+		if retries < 0 {
+			fatalIf(errInvalidArgument().Trace(strconv.Itoa(retries)), "--retry cannot be negative.")
+		}
+	}
+	policy, err := parseRetryPolicy(retries, cliCtx.String("retry-max-wait"), cliCtx.String("retry-on"))
+	fatalIf(err, "Unable to parse the retry flags.")
+	globalRetryPolicy = policy
+	if policy.retries == 0 {
+		// Fail fast, without the retries of the client either.
+		minio.MaxRetry = 1
+	}
+	console.SetColor("Retry", color.New(color.FgYellow))
+}
+
+// setRequestRetries sets the number of retries and the longest wait
+// between retries of every request, for pipe.
+func setRequestRetries(cliCtx *cli.Context) {
+	if cliCtx.IsSet("retry") {
+		retries := cliCtx.Int("retry")
+		if retries < 0 {
+			fatalIf(errInvalidArgument().Trace(strconv.Itoa(retries)), "--retry cannot be negative.")
+		}
+		minio.MaxRetry = retries + 1
+	}
+	if maxWait := cliCtx.String("retry-max-wait"); maxWait != "" {
+		d, e := time.ParseDuration(maxWait)
+		if e == nil && d <= 0 {
+			e = errors.New("the wait must be positive")
+		}
+		fatalIf(probe.NewError(e).Trace(maxWait), "Unable to parse --retry-max-wait.")
+		minio.DefaultRetryCap = d
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7"
+)
+
+func TestParseRetryPolicy(t *testing.T) {
+	testCases := []struct {
+		retries     int
+		maxWait     string
+		retryOn     string
+		wantRetries int
+		wantMaxWait time.Duration
+		success     bool
+	}{
+		{-1, "", "", defaultTransferRetries, defaultRetryMaxWait, true},
+		{0, "", "", 0, defaultRetryMaxWait, true},
+		{10, "2m", "network,throttle", 10, 2 * time.Minute, true},
+		{5, "", "503, 500,timeout", 5, defaultRetryMaxWait, true},
+		{5, "soon", "", 0, 0, false},
+		{5, "-1s", "", 0, 0, false},
+		{5, "", "flaky", 0, 0, false},
+		{5, "", "999", 0, 0, false},
+	}
+	for i, testCase := range testCases {
+		p, err := parseRetryPolicy(testCase.retries, testCase.maxWait, testCase.retryOn)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: expected success %v, got error %v", i+1, testCase.success, err)
+		}
+		if err != nil {
+			continue
+		}
+		if p.retries != testCase.wantRetries || p.maxWait != testCase.wantMaxWait {
+			t.Errorf("Test %d: expected %d retries waiting up to %s, got %d and %s",
+				i+1, testCase.wantRetries, testCase.wantMaxWait, p.retries, p.maxWait)
+		}
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	all, _ := parseRetryPolicy(-1, "", "")
+	throttleOnly, _ := parseRetryPolicy(-1, "", "throttle,404")
+	testCases := []struct {
+		policy    *retryPolicy
+		err       error
+		retryable bool
+	}{
+		{all, minio.ErrorResponse{StatusCode: http.StatusServiceUnavailable, Code: "SlowDown"}, true},
+		{all, minio.ErrorResponse{StatusCode: http.StatusInternalServerError, Code: "InternalError"}, true},
+		{all, minio.ErrorResponse{StatusCode: http.StatusBadRequest, Code: "RequestTimeout"}, true},
+		{all, minio.ErrorResponse{StatusCode: http.StatusForbidden, Code: "AccessDenied"}, false},
+		{all, &url.Error{Op: "Put", URL: "http://localhost:9000", Err: syscall.ECONNRESET}, true},
+		{all, io.ErrUnexpectedEOF, true},
+		{all, context.Canceled, false},
+		{all, errors.New("invalid argument"), false},
+		{throttleOnly, minio.ErrorResponse{StatusCode: http.StatusServiceUnavailable, Code: "SlowDown"}, true},
+		{throttleOnly, minio.ErrorResponse{StatusCode: http.StatusInternalServerError, Code: "InternalError"}, false},
+		{throttleOnly, minio.ErrorResponse{StatusCode: http.StatusNotFound, Code: "NoSuchKey"}, true},
+		{throttleOnly, io.ErrUnexpectedEOF, false},
+	}
+	for i, testCase := range testCases {
+		if retryable := testCase.policy.retryable(probe.NewError(testCase.err)); retryable != testCase.retryable {
+			t.Errorf("Test %d: expected retryable %v, got %v", i+1, testCase.retryable, retryable)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p, _ := parseRetryPolicy(-1, "3s", "")
+	for retry := 1; retry <= 40; retry++ {
+		wait := retryUnit << uint(retry-1)
+		if retry > 3 {
+			wait = 3 * time.Second
+		}
+		if backoff := p.backoff(retry); backoff < wait/2 || backoff > wait {
+			t.Errorf("Retry %d: expected a wait between %s and %s, got %s", retry, wait/2, wait, backoff)
+		}
+	}
+}
+
+func TestRetryTransfer(t *testing.T) {
+	defer func(p *retryPolicy) { globalRetryPolicy = p }(globalRetryPolicy)
+	globalRetryPolicy, _ = parseRetryPolicy(2, "1ms", "")
+
+	urls := URLs{SourceContent: &ClientContent{}, TargetContent: &ClientContent{}}
+	pg := newAccounter(0)
+	var retries []int
+	attempts := 0
+	ret := retryTransfer(context.Background(), urls, pg, func(msg message) {
+		retries = append(retries, msg.(retryMessage).Retry)
+	}, func(progress io.Reader) URLs {
+		attempts++
+		progress.Read(make([]byte, 100))
+		if attempts < 3 {
+			return urls.WithError(probe.NewError(io.ErrUnexpectedEOF))
+		}
+		return urls.WithError(nil)
+	})
+	if ret.Error != nil {
+		t.Fatalf("Expected the third attempt to succeed, got %v", ret.Error)
+	}
+	if len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Errorf("Expected retries 1 and 2 to be reported, got %v", retries)
+	}
+	if pg.Get() != 100 {
+		t.Errorf("Expected the progress of the failed attempts to be taken back, got %d bytes", pg.Get())
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// rmAuditEntry is a line of the audit log of rm --bypass, recording
+// who removed which object version, when, and the retention bypassed.
+type rmAuditEntry struct {
+	Time            time.Time  `json:"time"`
+	User            string     `json:"user"`
+	AccessKey       string     `json:"accessKey,omitempty"`
+	Key             string     `json:"key"`
+	VersionID       string     `json:"versionID,omitempty"`
+	DeleteMarker    bool       `json:"deleteMarker,omitempty"`
+	RetentionMode   string     `json:"retentionMode,omitempty"`
+	RetainUntilDate *time.Time `json:"retainUntilDate,omitempty"`
+}
+
+// rmAuditLog appends an entry to a local file for every content
+// removed, its retention being looked up before the removal.
+type rmAuditLog struct {
+	mu      sync.Mutex
+	file    *os.File
+	user    string
+	pending map[rmReclaimedKey]rmAuditEntry
+	// Clients looking up retentions, by alias.
+	clients map[string]*S3Client
+}
+
+// newRmAuditLog opens the audit log name, entries being only
+// appended to it.
+func newRmAuditLog(name string) (*rmAuditLog, *probe.Error) {
+	f, e := os.OpenFile(name, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	userName := os.Getenv("USER")
+	if u, e := user.Current(); e == nil {
+		userName = u.Username
+	}
+	return &rmAuditLog{
+		file:    f,
+		user:    userName,
+		pending: make(map[rmReclaimedKey]rmAuditEntry),
+		clients: make(map[string]*S3Client),
+	}, nil
+}
+
+// track looks up the retention of a content about to be removed, the
+// retention of objects without one being left empty.
+func (a *rmAuditLog) track(ctx context.Context, alias string, content *ClientContent) {
+	if a == nil {
+		return
+	}
+	entry := rmAuditEntry{
+		User:      a.user,
+		Key:       alias + content.URL.Path,
+		VersionID: content.VersionID,
+	}
+	if _, _, hostCfg, err := expandAlias(alias); err == nil && hostCfg != nil {
+		entry.AccessKey = hostCfg.AccessKey
+	}
+	entry.RetentionMode, entry.RetainUntilDate = a.retention(ctx, alias, content)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending[rmReclaimedKey{path: strings.TrimPrefix(filepath.ToSlash(content.URL.Path), "/"), versionID: content.VersionID}] = entry
+}
+
+// retention returns the retention of a content, read from its metadata
+// when it was listed or stat'ed with them, else looked up with a client
+// created once per alias.
+func (a *rmAuditLog) retention(ctx context.Context, alias string, content *ClientContent) (string, *time.Time) {
+	if len(content.Metadata) > 0 || len(content.UserMetadata) > 0 {
+		return metadataRetention(content.Metadata, content.UserMetadata)
+	}
+	if content.IsDeleteMarker {
+		return "", nil
+	}
+
+	a.mu.Lock()
+	clnt, ok := a.clients[alias]
+	if !ok {
+		c, err := newClientFromAlias(alias, content.URL.String())
+		if err == nil {
+			// Local files have no retention.
+			clnt, _ = c.(*S3Client)
+		}
+		a.clients[alias] = clnt
+	}
+	a.mu.Unlock()
+	if clnt == nil {
+		return "", nil
+	}
+
+	bucket, object := url2BucketAndObject(&content.URL, clnt.virtualStyle)
+	mode, until, e := clnt.api.GetObjectRetention(ctx, bucket, object, content.VersionID)
+	if e != nil || mode == nil || *mode == "" || until == nil {
+		return "", nil
+	}
+	return string(*mode), until
+}
+
+// metadataRetention returns the retention found in the metadata of an
+// object, whatever the case of their keys.
+func metadataRetention(metadata ...map[string]string) (mode string, until *time.Time) {
+	for _, m := range metadata {
+		for k, v := range m {
+			switch {
+			case strings.EqualFold(k, AmzObjectLockMode):
+				mode = v
+			case strings.EqualFold(k, AmzObjectLockRetainUntilDate):
+				if t, e := time.Parse(time.RFC3339, v); e == nil {
+					until = &t
+				}
+			}
+		}
+	}
+	if mode == "" || until == nil {
+		return "", nil
+	}
+	return mode, until
+}
+
+// removed appends the entry of a tracked content to the log once its
+// removal succeeded, failing to do so stops the removal.
+func (a *rmAuditLog) removed(result RemoveResult) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := rmReclaimedKey{path: path.Join(result.BucketName, result.ObjectName), versionID: result.ObjectVersionID}
+	entry, ok := a.pending[key]
+	if !ok {
+		return
+	}
+	delete(a.pending, key)
+
+	entry.Time = UTCNow()
+	entry.DeleteMarker = result.DeleteMarker
+	line, e := json.Marshal(entry)
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	_, e = a.file.Write(append(line, '\n'))
+	fatalIf(probe.NewError(e).Trace(a.file.Name()), "Unable to write to the audit log.")
+}
+
+// Close flushes the audit log to the disk.
+func (a *rmAuditLog) Close() *probe.Error {
+	if a == nil {
+		return nil
+	}
+	if e := a.file.Sync(); e != nil {
+		a.file.Close()
+		return probe.NewError(e)
+	}
+	return probe.NewError(a.file.Close())
+}
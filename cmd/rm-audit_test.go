@@ -0,0 +1,121 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func TestRmAuditLog(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	name := filepath.Join(t.TempDir(), "audit.jsonl")
+	for i := 0; i < 2; i++ {
+		audit, err := newRmAuditLog(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		audit.track(globalContext, "", &ClientContent{URL: ClientURL{Path: "/bucket/key", Separator: '/'}, VersionID: "v1"})
+		audit.track(globalContext, "", &ClientContent{URL: ClientURL{Path: "/bucket/failed", Separator: '/'}, VersionID: "v1"})
+		audit.removed(RemoveResult{BucketName: "bucket", RemoveObjectResult: minio.RemoveObjectResult{ObjectName: "key", ObjectVersionID: "v1"}})
+		// Results of untracked contents are not logged.
+		audit.removed(RemoveResult{BucketName: "bucket", RemoveObjectResult: minio.RemoveObjectResult{ObjectName: "other"}})
+		if err = audit.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, e := os.Open(name)
+	if e != nil {
+		t.Fatal(e)
+	}
+	defer f.Close()
+	var entries []rmAuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry rmAuditEntry
+		if e = json.Unmarshal(scanner.Bytes(), &entry); e != nil {
+			t.Fatal(e)
+		}
+		entries = append(entries, entry)
+	}
+	// The log is appended to, not truncated.
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	for _, entry := range entries {
+		if entry.Key != "/bucket/key" || entry.VersionID != "v1" || entry.Time.IsZero() || entry.RetentionMode != "" {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+	}
+}
+
+func TestRmAuditLogRetention(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	audit, err := newRmAuditLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer audit.Close()
+
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	testCases := []struct {
+		metadata, userMetadata map[string]string
+		mode                   string
+	}{
+		// Stat'ed objects carry their retention in the headers.
+		{map[string]string{AmzObjectLockMode: "COMPLIANCE", AmzObjectLockRetainUntilDate: until.Format(time.RFC3339)}, nil, "COMPLIANCE"},
+		// Objects listed with their metadata, whatever the case of the keys.
+		{nil, map[string]string{"x-amz-object-lock-mode": "GOVERNANCE", "x-amz-object-lock-retain-until-date": until.Format(time.RFC3339)}, "GOVERNANCE"},
+		{map[string]string{"Content-Type": "text/plain"}, nil, ""},
+		{map[string]string{AmzObjectLockMode: "GOVERNANCE", AmzObjectLockRetainUntilDate: "invalid"}, nil, ""},
+		// Looked up, local files have no retention.
+		{nil, nil, ""},
+		{nil, nil, ""},
+	}
+	for i, testCase := range testCases {
+		content := &ClientContent{
+			URL:          ClientURL{Path: "/bucket/key", Separator: '/'},
+			Metadata:     testCase.metadata,
+			UserMetadata: testCase.userMetadata,
+		}
+		mode, retainUntil := audit.retention(globalContext, "", content)
+		if mode != testCase.mode {
+			t.Errorf("Test %d: expected mode %q, got %q", i+1, testCase.mode, mode)
+		}
+		if (mode != "") != (retainUntil != nil) || (retainUntil != nil && !retainUntil.Equal(until)) {
+			t.Errorf("Test %d: unexpected retain until date %v", i+1, retainUntil)
+		}
+	}
+	// The client of an alias is created once.
+	if len(audit.clients) != 1 {
+		t.Errorf("expected a single client, got %d", len(audit.clients))
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"path"
+	"sync"
+	"sync/atomic"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// removeFilesFrom removes the objects listed one per line, sending
+// them to the bulk delete API of their alias so they are removed in
+// batches. Objects which cannot be removed are reported without
+// stopping the others. A dry run ends with the summary of the objects
+// listed, reported as removed from summaryURL.
+func removeFilesFrom(ctx context.Context, list io.Reader, prefix, summaryURL string, isFake, isBypass bool, audit *rmAuditLog, workers int) error {
+	var failed int32
+	fakeSummary := rmSummaryMessage{URL: summaryURL, Fake: true}
+	var wg sync.WaitGroup
+	contentChs := make(map[string]chan *ClientContent)
+
+	scanner := bufio.NewScanner(list)
+scan:
+	for scanner.Scan() {
+		object, ok := parseListedObject(scanner.Text(), prefix)
+		if !ok {
+			continue
+		}
+		alias, urlStr, hostCfg, err := expandAlias(object.url)
+		if err == nil && hostCfg == nil {
+			err = errInvalidArgument().Trace(object.url)
+		}
+		if err != nil {
+			errorIf(err.Trace(object.url), "Unable to remove `"+object.url+"`, only objects of an alias can be listed.")
+			atomic.StoreInt32(&failed, 1)
+			continue
+		}
+
+		content := &ClientContent{URL: *newClientURL(urlStr), VersionID: object.versionID}
+		if isFake {
+			printMsg(rmMessage{Key: object.url, VersionID: object.versionID, Fake: true})
+			fakeSummary.add(content)
+			continue
+		}
+
+		contentCh, ok := contentChs[alias]
+		if !ok {
+			clnt, err := newClientFromAlias(alias, hostCfg.URL)
+			if err != nil {
+				errorIf(err.Trace(object.url), "Unable to remove `"+object.url+"`.")
+				atomic.StoreInt32(&failed, 1)
+				continue
+			}
+			contentCh = make(chan *ClientContent)
+			contentChs[alias] = contentCh
+
+			resultCh := removeWithWorkers(ctx, clnt, workers, false, false, isBypass, contentCh)
+			wg.Add(1)
+			go func(alias string) {
+				defer wg.Done()
+				for result := range resultCh {
+					if result.Err != nil {
+						errorIf(result.Err.Trace(path.Join(alias, result.BucketName, result.ObjectName)),
+							"Unable to remove `"+path.Join(alias, result.BucketName, result.ObjectName)+"`.")
+						atomic.StoreInt32(&failed, 1)
+						continue
+					}
+					audit.removed(result)
+					versionID := result.ObjectVersionID
+					if versionID == "" {
+						versionID = result.DeleteMarkerVersionID
+					}
+					printMsg(rmMessage{
+						Key:          path.Join(alias, result.BucketName, result.ObjectName),
+						VersionID:    versionID,
+						DeleteMarker: result.DeleteMarker,
+					})
+				}
+			}(alias)
+		}
+
+		audit.track(ctx, alias, content)
+		select {
+		case contentCh <- content:
+		case <-ctx.Done():
+			break scan
+		}
+	}
+	for _, contentCh := range contentChs {
+		close(contentCh)
+	}
+	wg.Wait()
+
+	if e := scanner.Err(); e != nil {
+		fatalIf(probe.NewError(e), "Unable to read the list of objects to remove.")
+	}
+	if isFake {
+		printMsg(fakeSummary)
+	}
+	if atomic.LoadInt32(&failed) != 0 {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
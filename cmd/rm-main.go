@@ -26,8 +26,11 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/dustin/go-humanize"
+	"github.com/dustin/go-humanize/english"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	json "github.com/minio/colorjson"
@@ -72,13 +75,17 @@ var (
 			Usage: "remove incomplete uploads",
 		},
 		cli.BoolFlag{
-			Name:  "fake",
-			Usage: "perform a fake remove operation",
+			Name:  "fake, dry-run",
+			Usage: "list what would be removed with a summary, without removing anything",
 		},
 		cli.BoolFlag{
 			Name:  "stdin",
 			Usage: "read object names from STDIN",
 		},
+		cli.StringFlag{
+			Name:  "files-from",
+			Usage: "remove in batches the objects listed one per line in the file, each optionally followed by a tab and a version id, '-' reads from stdin",
+		},
 		cli.StringFlag{
 			Name:  "older-than",
 			Usage: "remove objects older than L days, M hours and N minutes",
@@ -87,10 +94,41 @@ var (
 			Name:  "newer-than",
 			Usage: "remove objects newer than L days, M hours and N minutes",
 		},
+		cli.StringFlag{
+			Name:  "after",
+			Usage: "remove only the noncurrent versions created at or after this date or duration, requires --versions",
+		},
+		cli.StringFlag{
+			Name:  "before",
+			Usage: "remove only the noncurrent versions created before this date or duration, requires --versions",
+		},
 		cli.BoolFlag{
 			Name:  "bypass",
 			Usage: "bypass governance",
 		},
+		cli.IntFlag{
+			Name:  "workers",
+			Value: 1,
+			Usage: "number of bulk removals of a recursive removal running concurrently, see --max-ops to limit their rate",
+		},
+		cli.StringFlag{
+			Name:  "audit-log",
+			Usage: "append who removed which version, when and the retention bypassed to this local file, requires --bypass",
+		},
+		cli.BoolFlag{
+			Name:  "trash",
+			Usage: "move object(s) to the trash of their bucket instead of removing them",
+		},
+		trashPrefixFlag,
+		cli.StringSliceFlag{
+			Name:  "include-tag",
+			Usage: "remove only objects carrying the tag key[=value], can be repeated",
+		},
+		cli.StringSliceFlag{
+			Name:  "exclude-tag",
+			Usage: "skip objects carrying the tag key[=value], can be repeated",
+		},
+		maxOpsFlag,
 	}
 )
 
@@ -154,6 +192,37 @@ EXAMPLES:
   13. Remove all object versions older than one year.
       {{.Prompt}} {{.HelpName}} s3/docs/ --recursive --versions --rewind 365d
 
+  14. Remove all objects tagged with "stage=tmp" recursively, keeping the ones also tagged "retention=legal".
+      {{.Prompt}} {{.HelpName}} --recursive --force --include-tag "stage=tmp" --exclude-tag "retention=legal" s3/docs/
+
+  15. Move all objects under the prefix 'louis' to the trash, they can be restored with 'mc trash restore'.
+      {{.Prompt}} {{.HelpName}} --recursive --force --trash s3/jazz-songs/louis/
+
+  16. Remove all objects under a prefix of a production bucket, sending at most 100 requests per second.
+      {{.Prompt}} {{.HelpName}} --recursive --force --max-ops 100 s3/jazz-songs/louis/
+
+  17. Drop incomplete uploads older than a week on the bucket 'jazz-songs' and report the reclaimed space.
+      {{.Prompt}} {{.HelpName}} --incomplete --recursive --force --older-than 7d s3/jazz-songs/
+
+  18. Check then purge the noncurrent versions of 'jazz-songs' created during a bad deployment.
+      {{.Prompt}} {{.HelpName}} --recursive --versions --force --fake --after 2021.11.30T09:00 --before 2021.11.30T11:30 s3/jazz-songs/
+      {{.Prompt}} {{.HelpName}} --recursive --versions --force --after 2021.11.30T09:00 --before 2021.11.30T11:30 s3/jazz-songs/
+
+  19. Remove in batches the objects found by another command, reading their names from stdin.
+      {{.Prompt}} mc find s3/jazz-songs --name "*.tmp" | {{.HelpName}} --force --files-from -
+
+  20. Remove the object versions listed in a file as key, tab and version id, relative to the bucket 'jazz-songs'.
+      {{.Prompt}} {{.HelpName}} --force --files-from versions.tsv s3/jazz-songs/
+
+  21. List the objects and versions a recursive removal would remove, with their count and size, without removing them.
+      {{.Prompt}} {{.HelpName}} --recursive --versions --force --dry-run s3/jazz-songs/louis/
+
+  22. Remove the versions of an object locked in governance mode, recording the removal in a local audit log.
+      {{.Prompt}} {{.HelpName}} --versions --force --bypass --audit-log /var/log/mc-bypass.jsonl s3/jazz-songs/louis/mostrecent.mp3
+
+  23. Remove a large prefix with 8 bulk removals running concurrently, sending at most 200 requests per second.
+      {{.Prompt}} {{.HelpName}} --recursive --force --workers 8 --max-ops 200 s3/jazz-songs/archive/
+
 `,
 }
 
@@ -165,11 +234,15 @@ type rmMessage struct {
 	VersionID    string    `json:"versionID"`
 	ModTime      time.Time `json:"modTime"`
 	Size         int64     `json:"size"`
+	Fake         bool      `json:"fake,omitempty"`
 }
 
 // Colorized message for console printing.
 func (r rmMessage) String() string {
 	msg := console.Colorize("Remove", fmt.Sprintf("Removing `%s`", r.Key))
+	if r.Fake {
+		msg = console.Colorize("Remove", fmt.Sprintf("Would remove `%s`", r.Key))
+	}
 	if r.DeleteMarker {
 		msg = console.Colorize("Remove", fmt.Sprintf("Creating delete marker `%s`", r.Key))
 	}
@@ -192,10 +265,164 @@ func (r rmMessage) JSON() string {
 	return string(msgBytes)
 }
 
+// rmIncompleteMessage summarizes the incomplete uploads removed from a URL.
+type rmIncompleteMessage struct {
+	Status  string `json:"status"`
+	URL     string `json:"url"`
+	Uploads int64  `json:"uploads"`
+	Size    int64  `json:"size"`
+	Fake    bool   `json:"fake,omitempty"`
+}
+
+// Colorized message for console printing.
+func (r rmIncompleteMessage) String() string {
+	verb := "Removed"
+	if r.Fake {
+		verb = "Would remove"
+	}
+	return console.Colorize("Remove", fmt.Sprintf("%s %s from `%s`, reclaiming %s.",
+		verb, english.Plural(int(r.Uploads), "incomplete upload", ""), r.URL, humanize.IBytes(uint64(r.Size))))
+}
+
+// JSON'ified message for scripting.
+func (r rmIncompleteMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// rmSummaryMessage summarizes the objects, and their versions,
+// removed from a URL.
+type rmSummaryMessage struct {
+	Status   string `json:"status"`
+	URL      string `json:"url"`
+	Objects  int64  `json:"objects"`
+	Versions int64  `json:"versions"`
+	Size     int64  `json:"size"`
+	Fake     bool   `json:"fake,omitempty"`
+
+	lastPath string
+}
+
+// add counts a content listed for removal, the versions of an
+// object being listed one after the other, and every incomplete
+// upload of an object on its own. Folders are not counted.
+func (r *rmSummaryMessage) add(content *ClientContent) {
+	if content.Type.IsDir() {
+		return
+	}
+	if content.URL.Path != r.lastPath || content.UploadID != "" {
+		r.lastPath = content.URL.Path
+		r.Objects++
+	}
+	if content.VersionID != "" {
+		r.Versions++
+	}
+	r.Size += content.Size
+}
+
+// Colorized message for console printing.
+func (r rmSummaryMessage) String() string {
+	verb := "Removed"
+	if r.Fake {
+		verb = "Would remove"
+	}
+	what := english.Plural(int(r.Objects), "object", "")
+	if r.Versions > 0 {
+		what += " (" + english.Plural(int(r.Versions), "version", "") + ")"
+	}
+	return console.Colorize("Remove", fmt.Sprintf("%s %s from `%s`, reclaiming %s.",
+		verb, what, r.URL, humanize.IBytes(uint64(r.Size))))
+}
+
+// JSON'ified message for scripting.
+func (r rmSummaryMessage) JSON() string {
+	r.Status = "success"
+	msgBytes, e := json.MarshalIndent(r, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(msgBytes)
+}
+
+// rmVersionWindow selects the noncurrent versions created in
+// [after, before), either bound being optional.
+type rmVersionWindow struct {
+	after, before time.Time
+}
+
+// contains returns true if the content is removable, every content
+// is without a window, and only versions of object storage with one.
+func (w *rmVersionWindow) contains(content *ClientContent) bool {
+	if w == nil {
+		return true
+	}
+	if content.VersionID == "" || content.IsLatest {
+		return false
+	}
+	if !w.after.IsZero() && content.Time.Before(w.after) {
+		return false
+	}
+	return w.before.IsZero() || content.Time.Before(w.before)
+}
+
+// rmReclaimed keeps the size of every content, incomplete upload or
+// version, handed over for removal, forgetting those whose removal
+// is reported as failed.
+type rmReclaimed map[rmReclaimedKey]int64
+
+type rmReclaimedKey struct {
+	path      string
+	versionID string
+	uploadID  string
+}
+
+func (r rmReclaimed) add(content *ClientContent) {
+	r[rmReclaimedKey{strings.TrimPrefix(filepath.ToSlash(content.URL.Path), "/"), content.VersionID, content.UploadID}] += content.Size
+}
+
+// drop forgets a content whose removal failed, the incomplete uploads
+// of an object being removed, and failing, together.
+func (r rmReclaimed) drop(result RemoveResult) {
+	objectPath := path.Join(result.BucketName, result.ObjectName)
+	for key := range r {
+		if key.path == objectPath && key.versionID == result.ObjectVersionID {
+			delete(r, key)
+		}
+	}
+}
+
+// summary returns the number of objects and versions removed and
+// their size.
+func (r rmReclaimed) summary(url string) rmSummaryMessage {
+	msg := rmSummaryMessage{URL: url}
+	objects := make(map[rmReclaimedKey]struct{})
+	for key, size := range r {
+		objects[rmReclaimedKey{path: key.path, uploadID: key.uploadID}] = struct{}{}
+		if key.versionID != "" {
+			msg.Versions++
+		}
+		msg.Size += size
+	}
+	msg.Objects = int64(len(objects))
+	return msg
+}
+
+// rmFakeMessage is printed for a content which would be removed.
+func rmFakeMessage(alias string, content *ClientContent) rmMessage {
+	return rmMessage{
+		Key:       alias + content.URL.Path,
+		Size:      content.Size,
+		VersionID: content.VersionID,
+		ModTime:   content.Time,
+		Fake:      true,
+	}
+}
+
 // Validate command line arguments.
 func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) {
 	// Set command flags from context.
 	isForce := cliCtx.Bool("force")
+	isFake := cliCtx.Bool("fake")
 	isRecursive := cliCtx.Bool("recursive")
 	isStdin := cliCtx.Bool("stdin")
 	isDangerous := cliCtx.Bool("dangerous")
@@ -204,11 +431,70 @@ func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string
 	rewind := cliCtx.String("rewind")
 	isNamespaceRemoval := false
 
+	if cliCtx.IsSet("files-from") {
+		if len(cliCtx.Args()) > 1 {
+			cli.ShowCommandHelpAndExit(cliCtx, "rm", 1) // last argument is exit code
+		}
+		for _, flag := range []string{"recursive", "versions", "non-current", "rewind", "version-id", "incomplete", "stdin", "older-than", "newer-than", "after", "before", "trash", "include-tag", "exclude-tag"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errDummy().Trace(), "You cannot specify --files-from with --"+flag+".")
+			}
+		}
+		if !isForce && !isFake {
+			fatalIf(errDummy().Trace(),
+				"Removal requires --force flag. This operation is *IRREVERSIBLE*. Please review carefully before performing this *DANGEROUS* operation.")
+		}
+		return
+	}
+
 	if versionID != "" && (isRecursive || isVersions || rewind != "") {
 		fatalIf(errDummy().Trace(),
 			"You cannot specify --version-id with any of --versions, --rewind and --recursive flags.")
 	}
 
+	if cliCtx.Bool("trash") {
+		if isVersions || cliCtx.Bool("non-current") || cliCtx.Bool("incomplete") || versionID != "" || rewind != "" {
+			fatalIf(errDummy().Trace(),
+				"You cannot specify --trash with any of --versions, --non-current, --incomplete, --version-id and --rewind flags.")
+		}
+		for _, url := range cliCtx.Args() {
+			if _, _, hostCfg := mustExpandAlias(url); hostCfg == nil {
+				fatalIf(errDummy().Trace(url), "--trash is only available on object storage.")
+			}
+		}
+	}
+
+	if cliCtx.IsSet("after") || cliCtx.IsSet("before") {
+		if !isVersions || cliCtx.Bool("non-current") {
+			fatalIf(errDummy().Trace(),
+				"You cannot specify --after or --before without --versions, or with --non-current.")
+		}
+		after := parseTimeFlag("after", cliCtx.String("after"))
+		before := parseTimeFlag("before", cliCtx.String("before"))
+		if !after.IsZero() && !before.IsZero() && !after.Before(before) {
+			fatalIf(errDummy().Trace(cliCtx.String("after"), cliCtx.String("before")), "--after must be earlier than --before.")
+		}
+	}
+
+	if cliCtx.IsSet("workers") {
+		if !isRecursive && !isVersions && !cliCtx.IsSet("files-from") {
+			fatalIf(errDummy().Trace(), "You cannot specify --workers without --recursive, --versions or --files-from.")
+		}
+		if cliCtx.Int("workers") < 1 {
+			fatalIf(errDummy().Trace(cliCtx.String("workers")), "--workers must be at least 1.")
+		}
+	}
+
+	if cliCtx.IsSet("audit-log") && !cliCtx.Bool("bypass") {
+		fatalIf(errDummy().Trace(), "You cannot specify --audit-log without --bypass.")
+	}
+
+	hasTagFilter := len(cliCtx.StringSlice("include-tag")) > 0 || len(cliCtx.StringSlice("exclude-tag")) > 0
+	if hasTagFilter && cliCtx.Bool("incomplete") {
+		fatalIf(errDummy().Trace(),
+			"You cannot specify --include-tag or --exclude-tag with --incomplete flag.")
+	}
+
 	for _, url := range cliCtx.Args() {
 		// clean path for aliases like s3/.
 		// Note: UNC path using / works properly in go 1.9.2 even though it breaks the UNC specification.
@@ -220,7 +506,7 @@ func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string
 			isNamespaceRemoval = (path == "")
 			break
 		}
-		if dir && isRecursive && !isForce {
+		if dir && isRecursive && !isForce && !isFake {
 			fatalIf(errDummy().Trace(),
 				"Removal requires --force flag. This operation is *IRREVERSIBLE*. Please review carefully before performing this *DANGEROUS* operation.")
 		}
@@ -234,8 +520,9 @@ func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string
 		cli.ShowCommandHelpAndExit(cliCtx, "rm", exitCode)
 	}
 
-	// For all recursive or versions bulk deletion operations make sure to check for 'force' flag.
-	if (isVersions || isRecursive || isStdin) && !isForce {
+	// For all recursive or versions bulk deletion operations make sure to check for 'force' flag,
+	// a dry run removing nothing.
+	if (isVersions || isRecursive || isStdin) && !isForce && !isFake {
 		fatalIf(errDummy().Trace(),
 			"Removal requires --force flag. This operation is *IRREVERSIBLE*. Please review carefully before performing this *DANGEROUS* operation.")
 	}
@@ -248,7 +535,7 @@ func checkRmSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string
 }
 
 // Remove a single object or a single version in a versioned bucket
-func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass bool, olderThan, newerThan string, encKeyDB map[string][]prefixSSEPair) error {
+func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass bool, olderThan, newerThan string, tagFilter objectTagFilter, trashPrefix string, audit *rmAuditLog, encKeyDB map[string][]prefixSSEPair) error {
 	ctx, cancel := context.WithCancel(globalContext)
 	defer cancel()
 
@@ -296,53 +583,123 @@ func removeSingle(url, versionID string, isIncomplete, isFake, isForce, isBypass
 		return nil
 	}
 
-	if !isFake {
-		targetAlias, targetURL, _ := mustExpandAlias(url)
-		clnt, pErr := newClientFromAlias(targetAlias, targetURL)
+	// Skip objects not matching --include-tag and --exclude-tag if specified
+	if !tagFilter.isEmpty() {
+		if ignoreStatError {
+			errorIf(pErr.Trace(url), "Unable to stat `"+url+"`.")
+			return exitStatus(globalErrorExitStatus)
+		}
+		alias, _ := url2Alias(url)
+		matched, pErr := tagFilter.matchContent(ctx, alias, content)
 		if pErr != nil {
-			errorIf(pErr.Trace(url), "Invalid argument `"+url+"`.")
-			return exitStatus(globalErrorExitStatus) // End of journey.
-		}
-
-		if !strings.HasSuffix(targetURL, string(clnt.GetURL().Separator)) && isDir {
-			targetURL = targetURL + string(clnt.GetURL().Separator)
-		}
-
-		contentCh := make(chan *ClientContent, 1)
-		contentURL := *newClientURL(targetURL)
-		contentCh <- &ClientContent{URL: contentURL, VersionID: versionID}
-		close(contentCh)
-		isRemoveBucket := false
-		resultCh := clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh)
-		for result := range resultCh {
-			if result.Err != nil {
-				errorIf(result.Err.Trace(url), "Failed to remove `"+url+"`.")
-				switch result.Err.ToGoError().(type) {
-				case PathInsufficientPermission:
-					// Ignore Permission error.
-					continue
-				}
-				return exitStatus(globalErrorExitStatus)
-			}
-			if versionID == "" {
-				versionID = result.DeleteMarkerVersionID
+			errorIf(pErr.Trace(url), "Unable to get tags of `"+url+"`.")
+			return exitStatus(globalErrorExitStatus)
+		}
+		if !matched {
+			return nil
+		}
+	}
+
+	if isFake {
+		printMsg(rmMessage{Key: url, Size: size, VersionID: versionID, ModTime: modTime, Fake: true})
+		summary := rmSummaryMessage{URL: url, Fake: true}
+		summary.add(&ClientContent{URL: *newClientURL(url), Size: size, VersionID: versionID})
+		printMsg(summary)
+		return nil
+	}
+
+	targetAlias, targetURL, _ := mustExpandAlias(url)
+	clnt, pErr := newClientFromAlias(targetAlias, targetURL)
+	if pErr != nil {
+		errorIf(pErr.Trace(url), "Invalid argument `"+url+"`.")
+		return exitStatus(globalErrorExitStatus) // End of journey.
+	}
+
+	if !strings.HasSuffix(targetURL, string(clnt.GetURL().Separator)) && isDir {
+		targetURL = targetURL + string(clnt.GetURL().Separator)
+	}
+
+	if trashPrefix != "" && !isDir {
+		if ignoreStatError {
+			errorIf(errDummy().Trace(url), "Unable to stat `"+url+"`, cannot move it to the trash.")
+			return exitStatus(globalErrorExitStatus)
+		}
+		if pErr = serverSideCopy(ctx, targetAlias, content, trashPath(content.URL.Path, trashPrefix), encKeyDB); pErr != nil {
+			errorIf(pErr.Trace(url), "Unable to move `"+url+"` to the trash.")
+			return exitStatus(globalErrorExitStatus)
+		}
+	}
+
+	contentCh := make(chan *ClientContent, 1)
+	contentURL := *newClientURL(targetURL)
+	auditContent := &ClientContent{URL: contentURL, VersionID: versionID}
+	if content != nil {
+		// Stat'ed with its retention.
+		auditContent.Metadata = content.Metadata
+	}
+	audit.track(ctx, targetAlias, auditContent)
+	contentCh <- &ClientContent{URL: contentURL, VersionID: versionID}
+	close(contentCh)
+	isRemoveBucket := false
+	resultCh := clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh)
+	for result := range resultCh {
+		if result.Err != nil {
+			errorIf(result.Err.Trace(url), "Failed to remove `"+url+"`.")
+			switch result.Err.ToGoError().(type) {
+			case PathInsufficientPermission:
+				// Ignore Permission error.
+				continue
 			}
-			printMsg(rmMessage{
-				Key:          targetAlias + contentURL.Path,
-				Size:         size,
-				VersionID:    versionID,
-				DeleteMarker: result.DeleteMarker,
-			})
+			return exitStatus(globalErrorExitStatus)
+		}
+		if versionID == "" {
+			versionID = result.DeleteMarkerVersionID
 		}
+		audit.removed(result)
+		printMsg(rmMessage{
+			Key:          targetAlias + contentURL.Path,
+			Size:         size,
+			VersionID:    versionID,
+			DeleteMarker: result.DeleteMarker,
+		})
 	}
 	return nil
 }
 
+// removeWithWorkers removes the contents with several concurrent
+// removals, each sending its own bulk delete requests, and merges
+// their results.
+func removeWithWorkers(ctx context.Context, clnt Client, workers int, isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *ClientContent) <-chan RemoveResult {
+	// Folders of a file system are removed after their files, so
+	// file systems are removed by a single worker.
+	if workers <= 1 || clnt.GetURL().Type != objectStorage {
+		return clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh)
+	}
+
+	resultCh := make(chan RemoveResult)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Every worker takes the next content to remove.
+			for result := range clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh) {
+				resultCh <- result
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+	return resultCh
+}
+
 // listAndRemove uses listing before removal, it can list recursively or not, with versions or not.
 //   Use cases:
 //      * Remove objects recursively
 //      * Remove all versions of a single object
-func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersion, isForce, isRecursive, isIncomplete, isFake, isBypass bool, olderThan, newerThan string, encKeyDB map[string][]prefixSSEPair) error {
+func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersion, isForce, isRecursive, isIncomplete, isFake, isBypass bool, olderThan, newerThan string, tagFilter objectTagFilter, trashPrefix string, window *rmVersionWindow, audit *rmAuditLog, workers int, encKeyDB map[string][]prefixSSEPair) error {
 	ctx, cancelRemove := context.WithCancel(globalContext)
 	defer cancelRemove()
 
@@ -355,7 +712,8 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 	contentCh := make(chan *ClientContent)
 	isRemoveBucket := false
 
-	listOpts := ListOptions{Recursive: isRecursive, Incomplete: isIncomplete, ShowDir: DirLast}
+	// The audit log reads the retention of the objects listed with their metadata.
+	listOpts := ListOptions{Recursive: isRecursive, Incomplete: isIncomplete, ShowDir: DirLast, WithMetadata: audit != nil}
 	if !timeRef.IsZero() {
 		listOpts.WithOlderVersions = withVersions
 		listOpts.WithDeleteMarkers = true
@@ -372,8 +730,10 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 	}
 
 	atLeastOneObjectFound := false
+	reclaimed := rmReclaimed{}
+	fakeSummary := rmSummaryMessage{URL: url, Fake: true}
 
-	resultCh := clnt.Remove(ctx, isIncomplete, isRemoveBucket, isBypass, contentCh)
+	resultCh := removeWithWorkers(ctx, clnt, workers, isIncomplete, isRemoveBucket, isBypass, contentCh)
 
 	var lastPath string
 	var perObjectVersions []*ClientContent
@@ -408,10 +768,16 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 			if lastPath != content.URL.Path {
 				lastPath = content.URL.Path
 				if isNonCurrent(perObjectVersions) {
+					toRemove := perObjectVersions
 					if isFake {
-						continue
+						for _, content := range perObjectVersions {
+							fakeSummary.add(content)
+							printMsg(rmFakeMessage(targetAlias, content))
+						}
+						toRemove = nil
 					}
-					for _, content := range perObjectVersions {
+					for _, content := range toRemove {
+						audit.track(ctx, targetAlias, content)
 						select {
 						case contentCh <- content:
 						case result := <-resultCh:
@@ -430,6 +796,7 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 							if content.VersionID == "" {
 								versionID = result.DeleteMarkerVersionID
 							}
+							audit.removed(result)
 							printMsg(rmMessage{
 								Key:          path.Join(targetAlias, content.BucketName, result.ObjectName),
 								Size:         content.Size,
@@ -462,12 +829,49 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 			if newerThan != "" && isNewer(content.Time, newerThan) {
 				continue
 			}
+
+			// Skip objects not matching --include-tag and --exclude-tag if specified
+			if !tagFilter.isEmpty() {
+				matched, pErr := tagFilter.matchContent(ctx, targetAlias, content)
+				if pErr != nil {
+					errorIf(pErr.Trace(content.URL.Path), "Unable to get tags of `"+content.URL.Path+"`.")
+					continue
+				}
+				if !matched {
+					continue
+				}
+			}
 		} else {
 			// Skip prefix levels.
 			continue
 		}
 
+		if trashPrefix != "" {
+			if isTrashed(content.URL.Path, trashPrefix) {
+				// Never move the trash into itself.
+				continue
+			}
+			if !isFake {
+				if pErr := serverSideCopy(ctx, targetAlias, content, trashPath(content.URL.Path, trashPrefix), encKeyDB); pErr != nil {
+					errorIf(pErr.Trace(content.URL.Path), "Unable to move `"+content.URL.Path+"` to the trash.")
+					continue
+				}
+			}
+		}
+
+		if !window.contains(content) {
+			continue
+		}
+
+		if isFake {
+			fakeSummary.add(content)
+			printMsg(rmFakeMessage(targetAlias, content))
+		} else if isIncomplete || window != nil {
+			reclaimed.add(content)
+		}
+
 		if !isFake {
+			audit.track(ctx, targetAlias, content)
 			sent := false
 			for !sent {
 				select {
@@ -480,6 +884,7 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 						switch result.Err.ToGoError().(type) {
 						case PathInsufficientPermission:
 							// Ignore Permission error.
+							reclaimed.drop(result)
 							continue
 						}
 						close(contentCh)
@@ -489,6 +894,7 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 					if content.VersionID == "" {
 						versionID = result.DeleteMarkerVersionID
 					}
+					audit.removed(result)
 					printMsg(rmMessage{
 						Key:          path.Join(targetAlias, content.BucketName, result.ObjectName),
 						Size:         content.Size,
@@ -503,10 +909,16 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 
 	if nonCurrentVersion && isRecursive && withVersions {
 		if isNonCurrent(perObjectVersions) {
+			toRemove := perObjectVersions
 			if isFake {
-				return nil
+				for _, content := range perObjectVersions {
+					fakeSummary.add(content)
+					printMsg(rmFakeMessage(targetAlias, content))
+				}
+				toRemove = nil
 			}
-			for _, content := range perObjectVersions {
+			for _, content := range toRemove {
+				audit.track(ctx, targetAlias, content)
 				select {
 				case contentCh <- content:
 				case result := <-resultCh:
@@ -525,6 +937,7 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 					if content.VersionID == "" {
 						versionID = result.DeleteMarkerVersionID
 					}
+					audit.removed(result)
 					printMsg(rmMessage{
 						Key:          path.Join(targetAlias, result.BucketName, result.ObjectName),
 						Size:         content.Size,
@@ -544,6 +957,7 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 			switch result.Err.ToGoError().(type) {
 			case PathInsufficientPermission:
 				// Ignore Permission error.
+				reclaimed.drop(result)
 				continue
 			}
 			return exitStatus(globalErrorExitStatus)
@@ -552,6 +966,7 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 		if versionID == "" {
 			versionID = result.DeleteMarkerVersionID
 		}
+		audit.removed(result)
 		printMsg(rmMessage{
 			Key:          path.Join(targetAlias, result.BucketName, result.ObjectName),
 			VersionID:    versionID,
@@ -559,6 +974,20 @@ func listAndRemove(url string, timeRef time.Time, withVersions, nonCurrentVersio
 		})
 	}
 
+	if atLeastOneObjectFound {
+		switch {
+		case isFake && isIncomplete:
+			printMsg(rmIncompleteMessage{URL: url, Uploads: fakeSummary.Objects, Size: fakeSummary.Size, Fake: true})
+		case isFake:
+			printMsg(fakeSummary)
+		case isIncomplete:
+			summary := reclaimed.summary(url)
+			printMsg(rmIncompleteMessage{URL: url, Uploads: summary.Objects, Size: summary.Size})
+		case window != nil:
+			printMsg(reclaimed.summary(url))
+		}
+	}
+
 	if !atLeastOneObjectFound {
 		if isForce {
 			// Do not throw an exit code with --force check unix `rm -f`
@@ -583,6 +1012,7 @@ func mainRm(cliCtx *cli.Context) error {
 
 	// check 'rm' cli arguments.
 	checkRmSyntax(ctx, cliCtx, encKeyDB)
+	setOpsLimit(cliCtx)
 
 	// rm specific flags.
 	isIncomplete := cliCtx.Bool("incomplete")
@@ -597,22 +1027,61 @@ func mainRm(cliCtx *cli.Context) error {
 	withVersions := cliCtx.Bool("versions")
 	versionID := cliCtx.String("version-id")
 	rewind := parseRewindFlag(cliCtx.String("rewind"))
+	var trashPrefix string
+	if cliCtx.Bool("trash") {
+		trashPrefix = normalizeTrashPrefix(cliCtx.String("trash-prefix"))
+	}
+	tagFilter, err := newObjectTagFilter(cliCtx.StringSlice("include-tag"), cliCtx.StringSlice("exclude-tag"))
+	fatalIf(err, "Unable to parse tag filters.")
 
 	if withVersions && rewind.IsZero() {
 		rewind = time.Now().UTC()
 	}
 
+	var window *rmVersionWindow
+	if cliCtx.IsSet("after") || cliCtx.IsSet("before") {
+		window = &rmVersionWindow{
+			after:  parseTimeFlag("after", cliCtx.String("after")),
+			before: parseTimeFlag("before", cliCtx.String("before")),
+		}
+	}
+
 	// Set color.
 	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
 
+	var audit *rmAuditLog
+	if auditLog := cliCtx.String("audit-log"); auditLog != "" && !isFake {
+		audit, err = newRmAuditLog(auditLog)
+		fatalIf(err.Trace(auditLog), "Unable to open the audit log.")
+		defer func() {
+			fatalIf(audit.Close().Trace(auditLog), "Unable to close the audit log.")
+		}()
+	}
+
+	if filesFrom := cliCtx.String("files-from"); filesFrom != "" {
+		list, err := openFilesFrom(filesFrom)
+		fatalIf(err.Trace(filesFrom), "Unable to open the list of objects to remove.")
+		defer list.Close()
+
+		var prefix string
+		if cliCtx.Args().Present() {
+			prefix = cliCtx.Args().First()
+		}
+		summaryURL := prefix
+		if summaryURL == "" {
+			summaryURL = filesFrom
+		}
+		return removeFilesFrom(ctx, list, prefix, summaryURL, isFake, isBypass, audit, cliCtx.Int("workers"))
+	}
+
 	var rerr error
 	var e error
 	// Support multiple targets.
 	for _, url := range cliCtx.Args() {
 		if isRecursive || withVersions {
-			e = listAndRemove(url, rewind, withVersions, withNoncurrentVersion, isForce, isRecursive, isIncomplete, isFake, isBypass, olderThan, newerThan, encKeyDB)
+			e = listAndRemove(url, rewind, withVersions, withNoncurrentVersion, isForce, isRecursive, isIncomplete, isFake, isBypass, olderThan, newerThan, tagFilter, trashPrefix, window, audit, cliCtx.Int("workers"), encKeyDB)
 		} else {
-			e = removeSingle(url, versionID, isIncomplete, isFake, isForce, isBypass, olderThan, newerThan, encKeyDB)
+			e = removeSingle(url, versionID, isIncomplete, isFake, isForce, isBypass, olderThan, newerThan, tagFilter, trashPrefix, audit, encKeyDB)
 		}
 		if rerr == nil {
 			rerr = e
@@ -627,9 +1096,9 @@ func mainRm(cliCtx *cli.Context) error {
 	for scanner.Scan() {
 		url := scanner.Text()
 		if isRecursive || withVersions {
-			e = listAndRemove(url, rewind, withVersions, withNoncurrentVersion, isForce, isRecursive, isIncomplete, isFake, isBypass, olderThan, newerThan, encKeyDB)
+			e = listAndRemove(url, rewind, withVersions, withNoncurrentVersion, isForce, isRecursive, isIncomplete, isFake, isBypass, olderThan, newerThan, tagFilter, trashPrefix, window, audit, cliCtx.Int("workers"), encKeyDB)
 		} else {
-			e = removeSingle(url, versionID, isIncomplete, isFake, isForce, isBypass, olderThan, newerThan, encKeyDB)
+			e = removeSingle(url, versionID, isIncomplete, isFake, isForce, isBypass, olderThan, newerThan, tagFilter, trashPrefix, audit, encKeyDB)
 		}
 		if rerr == nil {
 			rerr = e
@@ -0,0 +1,229 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/minio-go/v7"
+)
+
+func TestRmReclaimed(t *testing.T) {
+	reclaimed := rmReclaimed{}
+	for _, upload := range []struct {
+		path     string
+		uploadID string
+		size     int64
+	}{
+		{"/bucket/a", "u1", 10},
+		{"/bucket/dir/b", "u2", 20},
+		{"/bucket/dir/b", "u3", 25},
+		{"/other/c", "u4", 30},
+		// A second incomplete upload of the same object.
+		{"/other/c", "u5", 5},
+	} {
+		reclaimed.add(&ClientContent{URL: ClientURL{Path: upload.path, Separator: '/'}, UploadID: upload.uploadID, Size: upload.size})
+	}
+	reclaimed.add(&ClientContent{URL: ClientURL{Path: "/bucket/d", Separator: '/'}, VersionID: "v1", Size: 40})
+	reclaimed.add(&ClientContent{URL: ClientURL{Path: "/bucket/d", Separator: '/'}, VersionID: "v2", Size: 50})
+	// Incomplete uploads of an object are removed, and fail, together.
+	reclaimed.drop(RemoveResult{BucketName: "bucket", RemoveObjectResult: minio.RemoveObjectResult{ObjectName: "dir/b"}})
+	reclaimed.drop(RemoveResult{BucketName: "bucket", RemoveObjectResult: minio.RemoveObjectResult{ObjectName: "d", ObjectVersionID: "v3"}})
+
+	summary := reclaimed.summary("s3/bucket")
+	if summary.Objects != 4 || summary.Versions != 2 || summary.Size != 135 {
+		t.Fatalf("expected 4 objects, 2 versions and 135 bytes, got %+v", summary)
+	}
+	reclaimed.drop(RemoveResult{BucketName: "bucket", RemoveObjectResult: minio.RemoveObjectResult{ObjectName: "d", ObjectVersionID: "v2"}})
+	if summary = reclaimed.summary("s3/bucket"); summary.Objects != 4 || summary.Versions != 1 || summary.Size != 85 {
+		t.Fatalf("expected 4 objects, 1 version and 85 bytes, got %+v", summary)
+	}
+	msg := rmIncompleteMessage{URL: "s3/bucket", Uploads: 2, Size: 40}
+	if s := msg.String(); s != "Removed 2 incomplete uploads from `s3/bucket`, reclaiming 40 B." {
+		t.Fatalf("unexpected message: %q", s)
+	}
+}
+
+func TestRmVersionWindow(t *testing.T) {
+	deploy := time.Date(2021, 11, 30, 9, 0, 0, 0, time.UTC)
+	window := &rmVersionWindow{after: deploy, before: deploy.Add(2 * time.Hour)}
+	testCases := []struct {
+		window   *rmVersionWindow
+		content  ClientContent
+		expected bool
+	}{
+		{window, ClientContent{VersionID: "v", Time: deploy}, true},
+		{window, ClientContent{VersionID: "v", Time: deploy.Add(time.Hour)}, true},
+		{window, ClientContent{VersionID: "v", Time: deploy.Add(time.Hour), IsLatest: true}, false},
+		{window, ClientContent{VersionID: "v", Time: deploy.Add(-time.Second)}, false},
+		{window, ClientContent{VersionID: "v", Time: deploy.Add(2 * time.Hour)}, false},
+		{&rmVersionWindow{after: deploy}, ClientContent{VersionID: "v", Time: deploy.Add(48 * time.Hour)}, true},
+		{&rmVersionWindow{before: deploy}, ClientContent{VersionID: "v", Time: deploy.Add(-48 * time.Hour)}, true},
+		{window, ClientContent{Time: deploy}, false},
+		{nil, ClientContent{VersionID: "v", Time: deploy, IsLatest: true}, true},
+	}
+	for i, testCase := range testCases {
+		if got := testCase.window.contains(&testCase.content); got != testCase.expected {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestParseListedObject(t *testing.T) {
+	testCases := []struct {
+		line     string
+		prefix   string
+		expected listedObject
+		ok       bool
+	}{
+		{"s3/bucket/a.txt", "", listedObject{url: "s3/bucket/a.txt"}, true},
+		{"a.txt\r", "s3/bucket/", listedObject{url: "s3/bucket/a.txt"}, true},
+		{"dir/a b.txt\tv1", "s3/bucket", listedObject{url: "s3/bucket/dir/a b.txt", versionID: "v1"}, true},
+		{"/a.txt", "s3/bucket/", listedObject{url: "s3/bucket/a.txt"}, true},
+		{" a.txt ", "s3/bucket/", listedObject{url: "s3/bucket/ a.txt "}, true},
+		{"", "s3/bucket/", listedObject{}, false},
+		{"dir/", "s3/bucket/", listedObject{}, false},
+		{"# removed on 2021-12-01", "s3/bucket/", listedObject{}, false},
+	}
+	for i, testCase := range testCases {
+		object, ok := parseListedObject(testCase.line, testCase.prefix)
+		if ok != testCase.ok || object != testCase.expected {
+			t.Errorf("Test %d: expected %+v %v, got %+v %v", i+1, testCase.expected, testCase.ok, object, ok)
+		}
+	}
+}
+
+func TestRmSummaryMessage(t *testing.T) {
+	summary := rmSummaryMessage{URL: "s3/bucket/", Fake: true}
+	for _, content := range []*ClientContent{
+		{URL: ClientURL{Path: "/bucket/a"}, VersionID: "v2", Size: 10},
+		{URL: ClientURL{Path: "/bucket/a"}, VersionID: "v1", Size: 20},
+		{URL: ClientURL{Path: "/bucket/b"}, VersionID: "v1", Size: 30},
+	} {
+		summary.add(content)
+	}
+	if s := summary.String(); s != "Would remove 2 objects (3 versions) from `s3/bucket/`, reclaiming 60 B." {
+		t.Fatalf("unexpected message: %q", s)
+	}
+
+	summary = rmSummaryMessage{URL: "s3/bucket/", Fake: true}
+	for _, uploadID := range []string{"u1", "u2"} {
+		summary.add(&ClientContent{URL: ClientURL{Path: "/bucket/a"}, UploadID: uploadID, Size: 5})
+	}
+	if summary.Objects != 2 || summary.Size != 10 {
+		t.Fatalf("expected 2 incomplete uploads of 10 bytes, got %+v", summary)
+	}
+
+	summary = rmSummaryMessage{URL: "/tmp/logs/"}
+	summary.add(&ClientContent{URL: ClientURL{Path: "/tmp/logs/a"}, Size: 1024})
+	if s := summary.String(); s != "Removed 1 object from `/tmp/logs/`, reclaiming 1.0 KiB." {
+		t.Fatalf("unexpected message: %q", s)
+	}
+}
+
+func TestRemoveFilesFromFake(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	saveMcConfig(newMcConfig())
+	loadMcConfig = loadMcConfigFactory()
+
+	defer func(output io.Writer, json bool) { color.Output, globalJSON = output, json }(color.Output, globalJSON)
+	globalJSON = true
+	var buf bytes.Buffer
+	color.Output = &buf
+
+	list := "local/bucket/a\nlocal/bucket/a\tv1\n\nlocal/bucket/b\n"
+	if e := removeFilesFrom(context.Background(), strings.NewReader(list), "", "list.txt", true, false, nil, 1); e != nil {
+		t.Fatal(e)
+	}
+
+	var removed int
+	var summary rmSummaryMessage
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var msg rmSummaryMessage
+		if e := decoder.Decode(&msg); e != nil {
+			t.Fatal(e)
+		}
+		if msg.URL == "" {
+			removed++
+			continue
+		}
+		summary = msg
+	}
+	if removed != 3 {
+		t.Errorf("expected 3 objects listed, got %d", removed)
+	}
+	if !summary.Fake || summary.URL != "list.txt" || summary.Objects != 2 || summary.Versions != 1 {
+		t.Errorf("expected a dry run summary of 2 objects and 1 version from list.txt, got %+v", summary)
+	}
+}
+
+// removeTestClient removes contents by reporting them removed.
+type removeTestClient struct {
+	Client
+	removals int32
+}
+
+func (c *removeTestClient) GetURL() ClientURL {
+	return ClientURL{Type: objectStorage, Path: "/bucket/", Separator: '/'}
+}
+
+func (c *removeTestClient) Remove(ctx context.Context, isIncomplete, isRemoveBucket, isBypass bool, contentCh <-chan *ClientContent) <-chan RemoveResult {
+	atomic.AddInt32(&c.removals, 1)
+	resultCh := make(chan RemoveResult)
+	go func() {
+		defer close(resultCh)
+		for content := range contentCh {
+			resultCh <- RemoveResult{RemoveObjectResult: minio.RemoveObjectResult{ObjectName: content.URL.Path}}
+		}
+	}()
+	return resultCh
+}
+
+func TestRemoveWithWorkers(t *testing.T) {
+	for _, workers := range []int{1, 4} {
+		clnt := &removeTestClient{}
+		contentCh := make(chan *ClientContent)
+		go func() {
+			defer close(contentCh)
+			for i := 0; i < 100; i++ {
+				contentCh <- &ClientContent{URL: ClientURL{Path: fmt.Sprintf("/bucket/%03d", i)}}
+			}
+		}()
+		removed := make(map[string]bool)
+		for result := range removeWithWorkers(context.Background(), clnt, workers, false, false, false, contentCh) {
+			removed[result.ObjectName] = true
+		}
+		if len(removed) != 100 {
+			t.Errorf("%d workers: expected 100 removed objects, got %d", workers, len(removed))
+		}
+		if clnt.removals != int32(workers) {
+			t.Errorf("%d workers: expected %d removals, got %d", workers, workers, clnt.removals)
+		}
+	}
+}
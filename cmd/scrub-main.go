@@ -0,0 +1,368 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var scrubFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "rate",
+		Usage: "limit the read throughput, e.g. 50MiB/s",
+	},
+	cli.StringFlag{
+		Name:  "state",
+		Usage: "file to record the scrub progress in, to resume an interrupted scrub",
+	},
+	cli.BoolFlag{
+		Name:  "continuous",
+		Usage: "start a new pass once all objects have been verified",
+	},
+}
+
+// Verify objects by reading them back.
+var scrubCmd = cli.Command{
+	Name:         "scrub",
+	Usage:        "verify object integrity by reading objects back at a bounded rate",
+	Action:       mainScrub,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(append(scrubFlags, ioFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Objects are read in full and their MD5 sum is compared with their ETag. Objects
+  uploaded in multiple parts or encrypted with SSE-C or SSE-KMS do not carry
+  an MD5 ETag, they are only verified to be readable up to their size.
+
+ENVIRONMENT VARIABLES:
+  MC_ENCRYPT_KEY: list of comma delimited prefix=secret values
+
+EXAMPLES:
+  1. Verify all objects of bucket 'photos', reading at most 50MiB per second.
+     {{.Prompt}} {{.HelpName}} --rate 50MiB/s myminio/photos
+
+  2. Verify bucket 'photos' recording the progress, running the same command again
+     after an interruption resumes where it left off.
+     {{.Prompt}} {{.HelpName}} --rate 50MiB/s --state scrub.db myminio/photos
+
+  3. Sweep bucket 'photos' forever in the background, starting over after each pass.
+     {{.Prompt}} {{.HelpName}} --continuous --rate 10MiB/s --state scrub.db myminio/photos
+`,
+}
+
+// scrubState is the progress of a scrub saved in the --state file.
+type scrubState struct {
+	URL          string    `json:"url"`
+	Pass         int       `json:"pass"`
+	LastKey      string    `json:"lastKey"`
+	Scanned      int64     `json:"scanned"`
+	Verified     int64     `json:"verified"`
+	Unverifiable int64     `json:"unverifiable"`
+	Corrupted    int64     `json:"corrupted"`
+	Bytes        int64     `json:"bytes"`
+	Started      time.Time `json:"started"`
+	Updated      time.Time `json:"updated"`
+}
+
+// loadScrubState reads the scrub progress of urlStr, a missing file
+// starts a new scrub.
+func loadScrubState(stateFile, urlStr string) (*scrubState, *probe.Error) {
+	state := &scrubState{URL: urlStr, Pass: 1, Started: time.Now().UTC()}
+	if stateFile == "" {
+		return state, nil
+	}
+	data, e := ioutil.ReadFile(stateFile)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return state, nil
+		}
+		return nil, probe.NewError(e)
+	}
+	if e = json.Unmarshal(data, state); e != nil {
+		return nil, probe.NewError(e)
+	}
+	if state.URL != urlStr {
+		return nil, probe.NewError(fmt.Errorf("state file `%s` belongs to the scrub of `%s`", stateFile, state.URL))
+	}
+	return state, nil
+}
+
+// save atomically writes the scrub progress.
+func (s *scrubState) save(stateFile string) *probe.Error {
+	if stateFile == "" {
+		return nil
+	}
+	s.Updated = time.Now().UTC()
+	data, e := json.MarshalIndent(s, "", " ")
+	if e != nil {
+		return probe.NewError(e)
+	}
+	tmpFile := stateFile + ".tmp"
+	if e = ioutil.WriteFile(tmpFile, data, 0o600); e != nil {
+		return probe.NewError(e)
+	}
+	return probe.NewError(os.Rename(tmpFile, stateFile))
+}
+
+// scrubMessage reports an object failing verification.
+type scrubMessage struct {
+	Status    string `json:"status"`
+	Key       string `json:"key"`
+	VersionID string `json:"versionId,omitempty"`
+	Size      int64  `json:"size"`
+	Result    string `json:"result"`
+	Expected  string `json:"expected,omitempty"`
+	Actual    string `json:"actual,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (s scrubMessage) String() string {
+	msg := fmt.Sprintf("Object `%s` is %s", s.Key, s.Result)
+	if s.Expected != "" {
+		msg += fmt.Sprintf(": expected %s, got %s", s.Expected, s.Actual)
+	}
+	if s.Error != "" {
+		msg += ": " + s.Error
+	}
+	return console.Colorize("ScrubFailed", msg)
+}
+
+func (s scrubMessage) JSON() string {
+	s.Status = "error"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// scrubSummaryMessage reports the outcome of a scrub pass.
+type scrubSummaryMessage struct {
+	Status string `json:"status"`
+	scrubState
+}
+
+func (s scrubSummaryMessage) String() string {
+	theme := "ScrubOK"
+	if s.Corrupted > 0 {
+		theme = "ScrubFailed"
+	}
+	return console.Colorize(theme, fmt.Sprintf("Pass %d of `%s`: %d objects (%s) scanned, %d verified, %d readable only, %d failed in %s.",
+		s.Pass, s.URL, s.Scanned, humanize.IBytes(uint64(s.Bytes)), s.Verified, s.Unverifiable, s.Corrupted,
+		timeDurationToHumanizedDuration(s.Updated.Sub(s.Started)).StringShort()))
+}
+
+func (s scrubSummaryMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+var md5ETagRegex = regexp.MustCompile("^[0-9a-f]{32}$")
+
+// scrubExpectedMD5 returns the MD5 sum an object is expected to have,
+// if its ETag is one.
+func scrubExpectedMD5(content *ClientContent) (string, bool) {
+	etag := strings.ToLower(strings.Trim(content.ETag, "\""))
+	if !md5ETagRegex.MatchString(etag) {
+		// Multipart uploads and non S3 targets.
+		return "", false
+	}
+	// Listings with metadata report the encryption among user metadata.
+	for _, metadata := range []map[string]string{content.Metadata, content.UserMetadata} {
+		for k, v := range metadata {
+			k = strings.ToLower(k)
+			if strings.HasSuffix(k, "server-side-encryption-customer-algorithm") {
+				return "", false
+			}
+			if strings.HasSuffix(k, "server-side-encryption") && v != "AES256" {
+				return "", false
+			}
+		}
+	}
+	return etag, true
+}
+
+// scrubObject reads the object back and verifies its size and checksum.
+func scrubObject(ctx context.Context, alias string, content *ClientContent, limiter *rateLimiter, encKeyDB map[string][]prefixSSEPair) (msg *scrubMessage, verified bool) {
+	objectURL := content.URL.String()
+	if alias != "" {
+		objectURL = urlJoinPath(alias, content.URL.Path)
+	}
+	failed := func(result string) *scrubMessage {
+		return &scrubMessage{Key: objectURL, VersionID: content.VersionID, Size: content.Size, Result: result}
+	}
+
+	reader, err := getSourceStreamFromURL(ctx, objectURL, content.VersionID, encKeyDB)
+	if err != nil {
+		msg = failed("unreadable")
+		msg.Error = err.ToGoError().Error()
+		return msg, false
+	}
+	defer reader.Close()
+
+	hasher := md5.New()
+	n, e := io.Copy(hasher, newRateLimitedReader(ctx, reader, limiter))
+	if e != nil {
+		msg = failed("unreadable")
+		msg.Error = e.Error()
+		return msg, false
+	}
+	if n != content.Size {
+		msg = failed("corrupted")
+		msg.Expected = fmt.Sprintf("%d bytes", content.Size)
+		msg.Actual = fmt.Sprintf("%d bytes", n)
+		return msg, false
+	}
+
+	expected, ok := scrubExpectedMD5(content)
+	if !ok {
+		return nil, false
+	}
+	if actual := hex.EncodeToString(hasher.Sum(nil)); actual != expected {
+		msg = failed("corrupted")
+		msg.Expected, msg.Actual = expected, actual
+		return msg, false
+	}
+	return nil, true
+}
+
+// scrubPass verifies all objects sorting after state.LastKey, the state
+// is saved periodically so that an interrupted pass can be resumed.
+func scrubPass(ctx context.Context, urlStr, stateFile string, state *scrubState, limiter *rateLimiter, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	alias, _ := url2Alias(urlStr)
+	clnt, err := newClient(urlStr)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+
+	lastSave := time.Now()
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, WithMetadata: true, ShowDir: DirNone}) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(urlStr), "Unable to list `"+urlStr+"`.")
+			continue
+		}
+		if !content.Type.IsRegular() || content.URL.Path <= state.LastKey {
+			continue
+		}
+
+		msg, verified := scrubObject(ctx, alias, content, limiter, encKeyDB)
+		if ctx.Err() != nil {
+			// Interrupted, the object will be verified again on resume.
+			break
+		}
+		state.Scanned++
+		state.Bytes += content.Size
+		state.LastKey = content.URL.Path
+		switch {
+		case msg != nil:
+			state.Corrupted++
+			printMsg(*msg)
+		case verified:
+			state.Verified++
+		default:
+			state.Unverifiable++
+		}
+
+		if msg != nil || time.Since(lastSave) > 10*time.Second {
+			if err = state.save(stateFile); err != nil {
+				return err.Trace(stateFile)
+			}
+			lastSave = time.Now()
+		}
+	}
+	state.Updated = time.Now().UTC()
+	return state.save(stateFile)
+}
+
+// mainScrub is the entry point for scrub command.
+func mainScrub(cliCtx *cli.Context) error {
+	ctx, cancelScrub := context.WithCancel(globalContext)
+	defer cancelScrub()
+
+	console.SetColor("ScrubOK", color.New(color.FgGreen, color.Bold))
+	console.SetColor("ScrubFailed", color.New(color.FgRed, color.Bold))
+
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, "scrub", 1) // last argument is exit code
+	}
+	urlStr := cliCtx.Args().Get(0)
+
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	var limiter *rateLimiter
+	if rate := cliCtx.String("rate"); rate != "" {
+		bytesPerSec, err := parseRate(rate)
+		fatalIf(err.Trace(rate), "Unable to parse rate `"+rate+"`.")
+		limiter = newRateLimiter(bytesPerSec)
+	}
+
+	stateFile := cliCtx.String("state")
+	state, err := loadScrubState(stateFile, urlStr)
+	fatalIf(err.Trace(stateFile), "Unable to load scrub state.")
+
+	for {
+		err = scrubPass(ctx, urlStr, stateFile, state, limiter, encKeyDB)
+		fatalIf(err, "Unable to scrub `"+urlStr+"`.")
+		if ctx.Err() != nil {
+			// Interrupted, progress is saved in the state file.
+			return exitStatus(globalErrorExitStatus)
+		}
+
+		printMsg(scrubSummaryMessage{scrubState: *state})
+		corrupted := state.Corrupted > 0
+		if !cliCtx.Bool("continuous") {
+			// Start over next time.
+			if stateFile != "" {
+				fatalIf(probe.NewError(os.Remove(stateFile)), "Unable to remove scrub state.")
+			}
+			if corrupted {
+				return exitStatus(globalErrorExitStatus)
+			}
+			return nil
+		}
+
+		state = &scrubState{URL: urlStr, Pass: state.Pass + 1, Started: time.Now().UTC()}
+		fatalIf(state.save(stateFile).Trace(stateFile), "Unable to save scrub state.")
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/cli"
+)
+
+// Set by --disable-server-copy to always stream objects through mc.
+var globalDisableServerCopy bool
+
+// setServerCopyOptions reads the --disable-server-copy flag.
+func setServerCopyOptions(cliCtx *cli.Context) {
+	globalDisableServerCopy = cliCtx.Bool("disable-server-copy")
+}
+
+// isServerSideCopy returns true if the target can copy the source object
+// without mc downloading and uploading it. This is the case for objects
+// of the same alias, or of aliases of the same endpoint and credentials,
+// unless the data must flow through mc to be transformed or accounted.
+func isServerSideCopy(sourceAlias, targetAlias string, sourceURL, targetURL ClientURL) bool {
+	if globalDisableServerCopy || sourceURL.Type != targetURL.Type {
+		return false
+	}
+	if sourceURL.Type == objectStorage && isStreamCopyRequired() {
+		return false
+	}
+	if sourceAlias == targetAlias {
+		return true
+	}
+	if sourceURL.Type != objectStorage || sourceAlias == "" || targetAlias == "" {
+		return false
+	}
+	_, _, sourceCfg := mustExpandAlias(sourceAlias)
+	_, _, targetCfg := mustExpandAlias(targetAlias)
+	return isSameEndpoint(sourceCfg, targetCfg)
+}
+
+// isStreamCopyRequired returns true if the objects must be streamed
+// through mc, which a server side copy would bypass: --compress,
+// --checksum and the --limit-upload and --limit-download bandwidth.
+func isStreamCopyRequired() bool {
+	return globalCompress != "" || globalChecksumAlgo != "" ||
+		globalUploadLimiter != nil || globalDownloadLimiter != nil
+}
+
+// isSameEndpoint returns true if two aliases reach the same endpoint
+// with the same credentials.
+func isSameEndpoint(a, b *aliasConfigV10) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if a.AccessKey != b.AccessKey || a.SecretKey != b.SecretKey || a.SessionToken != b.SessionToken {
+		return false
+	}
+	au, e := url.Parse(a.URL)
+	if e != nil {
+		return false
+	}
+	bu, e := url.Parse(b.URL)
+	if e != nil {
+		return false
+	}
+	return strings.EqualFold(au.Scheme, bu.Scheme) && strings.EqualFold(au.Host, bu.Host) &&
+		strings.TrimSuffix(au.Path, "/") == strings.TrimSuffix(bu.Path, "/")
+}
+
+// renameLocalFile moves a local file by renaming it, the local
+// counterpart of a server side copy followed by a removal. It returns
+// false when the file cannot be renamed, e.g. to another file system,
+// and must be copied instead.
+func renameLocalFile(cpURLs URLs) bool {
+	sourceURL, targetURL := cpURLs.SourceContent.URL, cpURLs.TargetContent.URL
+	if globalDisableServerCopy || cpURLs.SourceAlias != "" || cpURLs.TargetAlias != "" ||
+		sourceURL.Type != fileSystem || targetURL.Type != fileSystem {
+		return false
+	}
+	if e := os.MkdirAll(filepath.Dir(targetURL.Path), 0o777); e != nil {
+		return false
+	}
+	return os.Rename(sourceURL.Path, targetURL.Path) == nil
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSameEndpoint(t *testing.T) {
+	base := aliasConfigV10{URL: "https://play.min.io", AccessKey: "ak", SecretKey: "sk"}
+	with := func(f func(c *aliasConfigV10)) *aliasConfigV10 {
+		c := base
+		f(&c)
+		return &c
+	}
+
+	testCases := []struct {
+		a, b     *aliasConfigV10
+		expected bool
+	}{
+		{&base, &base, true},
+		{&base, with(func(c *aliasConfigV10) { c.URL = "https://PLAY.min.io/" }), true},
+		{&base, with(func(c *aliasConfigV10) { c.URL = "http://play.min.io" }), false},
+		{&base, with(func(c *aliasConfigV10) { c.URL = "https://play.min.io:9000" }), false},
+		{&base, with(func(c *aliasConfigV10) { c.URL = "https://play.min.io/tenant" }), false},
+		{&base, with(func(c *aliasConfigV10) { c.AccessKey = "other" }), false},
+		{&base, with(func(c *aliasConfigV10) { c.SecretKey = "other" }), false},
+		{&base, with(func(c *aliasConfigV10) { c.SessionToken = "token" }), false},
+		{&base, nil, false},
+	}
+
+	for i, testCase := range testCases {
+		if got := isSameEndpoint(testCase.a, testCase.b); got != testCase.expected {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.expected, got)
+		}
+	}
+}
+
+func TestIsServerSideCopyDisabled(t *testing.T) {
+	defer func() { globalDisableServerCopy = false }()
+
+	source, target := ClientURL{Type: objectStorage}, ClientURL{Type: objectStorage}
+	if !isServerSideCopy("play", "play", source, target) {
+		t.Fatal("expected a server side copy within the same alias")
+	}
+	if isServerSideCopy("play", "play", source, ClientURL{Type: fileSystem}) {
+		t.Fatal("expected no server side copy to a local target")
+	}
+	globalDisableServerCopy = true
+	if isServerSideCopy("play", "play", source, target) {
+		t.Fatal("expected no server side copy with --disable-server-copy")
+	}
+}
+
+func TestIsServerSideCopyStreaming(t *testing.T) {
+	defer func() {
+		globalCompress, globalChecksumAlgo = "", ""
+		globalUploadLimiter, globalDownloadLimiter = nil, nil
+	}()
+
+	source, target := ClientURL{Type: objectStorage}, ClientURL{Type: objectStorage}
+	testCases := []struct {
+		name string
+		set  func()
+	}{
+		{"--compress", func() { globalCompress = "gzip" }},
+		{"--checksum", func() { globalChecksumAlgo = "sha256" }},
+		{"--limit-upload", func() { globalUploadLimiter = newRateLimiter(1 << 20) }},
+		{"--limit-download", func() { globalDownloadLimiter = newRateLimiter(1 << 20) }},
+	}
+	for _, testCase := range testCases {
+		globalCompress, globalChecksumAlgo = "", ""
+		globalUploadLimiter, globalDownloadLimiter = nil, nil
+		if !isServerSideCopy("play", "play", source, target) {
+			t.Fatalf("%s: expected a server side copy without the flag", testCase.name)
+		}
+		testCase.set()
+		if isServerSideCopy("play", "play", source, target) {
+			t.Errorf("%s: expected the object to be streamed", testCase.name)
+		}
+		// Local copies are not affected.
+		if !isServerSideCopy("", "", ClientURL{Type: fileSystem}, ClientURL{Type: fileSystem}) {
+			t.Errorf("%s: expected local files to be copied locally", testCase.name)
+		}
+	}
+}
+
+func TestRenameLocalFile(t *testing.T) {
+	dir := t.TempDir()
+	source, target := filepath.Join(dir, "a.txt"), filepath.Join(dir, "sub", "dir", "a.txt")
+	if e := ioutil.WriteFile(source, []byte("hello"), 0o644); e != nil {
+		t.Fatal(e)
+	}
+
+	cpURLs := URLs{
+		SourceContent: &ClientContent{URL: *newClientURL(source)},
+		TargetContent: &ClientContent{URL: *newClientURL(target)},
+	}
+	objectURLs := URLs{
+		SourceAlias:   "play",
+		SourceContent: &ClientContent{URL: *newClientURL("https://play.min.io/bucket/a.txt")},
+		TargetAlias:   "play",
+		TargetContent: &ClientContent{URL: *newClientURL("https://play.min.io/bucket/b.txt")},
+	}
+	if renameLocalFile(objectURLs) {
+		t.Fatal("expected objects not to be renamed locally")
+	}
+	if !renameLocalFile(cpURLs) {
+		t.Fatal("expected the local file to be renamed")
+	}
+	if _, e := os.Stat(source); !os.IsNotExist(e) {
+		t.Fatalf("expected the source to be moved, got %v", e)
+	}
+	if data, e := ioutil.ReadFile(target); e != nil || string(data) != "hello" {
+		t.Fatalf("expected the target to be the source, got %q: %v", data, e)
+	}
+	// A missing source is left to the copy to report.
+	if renameLocalFile(cpURLs) {
+		t.Fatal("expected a missing source not to be renamed")
+	}
+}
@@ -52,6 +52,8 @@ type sessionV8Header struct {
 	TotalBytes         int64             `json:"totalBytes"`
 	TotalObjects       int64             `json:"totalObjects"`
 	UserMetaData       map[string]string `json:"metaData"`
+	// Incomplete multipart uploads by target URL.
+	ResumableUploads map[string]resumableUpload `json:"resumableUploads,omitempty"`
 }
 
 // sessionMessage container for session messages
@@ -359,16 +361,24 @@ func (s *sessionV8) Delete() *probe.Error {
 	return nil
 }
 
+// resumeHint tells how to resume the session.
+func (s sessionV8) resumeHint() string {
+	if s.Header.CommandType == "cp" {
+		return "Run the same command or 'mc cp --resume " + s.SessionID + "' to resume copy again."
+	}
+	return "Run the same command to resume copy again."
+}
+
 // Close a session and exit.
 func (s sessionV8) CloseAndDie() {
 	s.Close()
-	console.Fatalln("Session safely terminated. Run the same command to resume copy again.")
+	console.Fatalln("Session safely terminated. " + s.resumeHint())
 }
 
 func (s sessionV8) copyCloseAndDie(sessionFlag bool) {
 	if sessionFlag {
 		s.Close()
-		console.Fatalln("Command terminated safely. Run this command to resume copy again.")
+		console.Fatalln("Command terminated safely. " + s.resumeHint())
 	} else {
 		s.mutex.Lock()
 		defer s.mutex.Unlock()
@@ -398,3 +408,65 @@ func isLastFactory(lastURL string) func(string) bool {
 		return false
 	}
 }
+
+// multipartResume returns how to continue the upload to targetURL of a
+// source modified at modTime, recording the upload in the session.
+func (s *sessionV8) multipartResume(targetURL string, modTime time.Time) *multipartResume {
+	s.mutex.Lock()
+	previous := s.Header.ResumableUploads[targetURL]
+	s.mutex.Unlock()
+
+	return &multipartResume{
+		previous: previous,
+		modTime:  modTime,
+		record: func(upload resumableUpload) {
+			s.mutex.Lock()
+			if upload.UploadID == "" {
+				delete(s.Header.ResumableUploads, targetURL)
+			} else {
+				if s.Header.ResumableUploads == nil {
+					s.Header.ResumableUploads = make(map[string]resumableUpload)
+				}
+				s.Header.ResumableUploads[targetURL] = upload
+			}
+			s.mutex.Unlock()
+			s.Save()
+		},
+	}
+}
+
+// sessionCheckpoint tracks objects copied concurrently, so that the
+// session only records an object as the last copied once all objects
+// queued before it are copied as well.
+type sessionCheckpoint struct {
+	mutex   sync.Mutex
+	pending []string
+	done    map[string]int
+}
+
+func newSessionCheckpoint() *sessionCheckpoint {
+	return &sessionCheckpoint{done: make(map[string]int)}
+}
+
+// queue records an object about to be copied.
+func (c *sessionCheckpoint) queue(sourceURL string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.pending = append(c.pending, sourceURL)
+}
+
+// complete records a copied object and returns the last object copied
+// with no pending object queued before it, empty if it did not change.
+func (c *sessionCheckpoint) complete(sourceURL string) (lastCopied string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.done[sourceURL]++
+	for len(c.pending) > 0 && c.done[c.pending[0]] > 0 {
+		lastCopied = c.pending[0]
+		if c.done[lastCopied]--; c.done[lastCopied] == 0 {
+			delete(c.done, lastCopied)
+		}
+		c.pending = c.pending[1:]
+	}
+	return lastCopied
+}
@@ -59,3 +59,15 @@ func (s *TestSuite) TestSession(c *C) {
 	_, e = os.Stat(session.DataFP.Name())
 	c.Assert(e, NotNil)
 }
+
+func (s *TestSuite) TestSessionCheckpoint(c *C) {
+	checkpoint := newSessionCheckpoint()
+	for _, url := range []string{"a", "b", "c", "d"} {
+		checkpoint.queue(url)
+	}
+	// Copies completing out of order do not advance the checkpoint.
+	c.Assert(checkpoint.complete("b"), Equals, "")
+	c.Assert(checkpoint.complete("d"), Equals, "")
+	c.Assert(checkpoint.complete("a"), Equals, "b")
+	c.Assert(checkpoint.complete("c"), Equals, "d")
+}
@@ -0,0 +1,307 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/signer"
+	"github.com/minio/pkg/console"
+)
+
+var shareAliasFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "expire, E",
+		Value: "1h",
+		Usage: "set expiry in NN[h|m|s], at least 15m",
+	},
+	cli.StringFlag{
+		Name:  "perms",
+		Value: "ro",
+		Usage: "permissions of the credentials, 'ro' to download, 'wo' to upload or 'rw' for both",
+	},
+	cli.StringFlag{
+		Name:  "name",
+		Value: "shared",
+		Usage: "alias name of the collaborator",
+	},
+}
+
+// Share a prefix with temporary credentials.
+var shareAlias = cli.Command{
+	Name:         "alias",
+	Usage:        "generate temporary credentials restricted to a prefix",
+	Action:       mainShareAlias,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(shareAliasFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+  The credentials are issued by the STS AssumeRole API of a MinIO server with
+  a policy restricted to TARGET. The collaborator pastes the printed line to
+  use them as an alias. Credentials containing a ':' or a quote are escaped,
+  the line then ends with the '#escaped' marker.
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Give a collaborator read access to the 'reports/2021/' prefix of bucket 'finance' for a day.
+     {{.Prompt}} {{.HelpName}} --expire 24h --perms ro play/finance/reports/2021/
+
+  2. Let a collaborator upload to bucket 'incoming' for 2 hours, as the alias 'acme'.
+     {{.Prompt}} {{.HelpName}} --expire 2h --perms wo --name acme play/incoming
+`,
+}
+
+// Actions allowed on the objects and on the bucket for each permission.
+var shareAliasActions = map[string]struct {
+	object []string
+	bucket []string
+}{
+	"ro": {
+		object: []string{"s3:GetObject", "s3:GetObjectVersion"},
+		bucket: []string{"s3:ListBucket"},
+	},
+	"wo": {
+		object: []string{"s3:PutObject", "s3:AbortMultipartUpload", "s3:ListMultipartUploadParts"},
+	},
+	"rw": {
+		object: []string{"s3:GetObject", "s3:GetObjectVersion", "s3:PutObject", "s3:DeleteObject",
+			"s3:AbortMultipartUpload", "s3:ListMultipartUploadParts"},
+		bucket: []string{"s3:ListBucket", "s3:ListBucketMultipartUploads"},
+	},
+}
+
+// shareAliasPolicy returns the session policy restricting the credentials
+// to the prefix of the bucket.
+func shareAliasPolicy(bucket, prefix, perms string) (string, *probe.Error) {
+	actions, ok := shareAliasActions[perms]
+	if !ok {
+		return "", errInvalidArgument().Trace(perms)
+	}
+	type statement struct {
+		Effect    string                 `json:"Effect"`
+		Action    []string               `json:"Action"`
+		Resource  []string               `json:"Resource"`
+		Condition map[string]interface{} `json:"Condition,omitempty"`
+	}
+	statements := []statement{
+		{
+			Effect:   "Allow",
+			Action:   append([]string{"s3:GetBucketLocation"}, actions.bucket...),
+			Resource: []string{"arn:aws:s3:::" + bucket},
+		},
+		{
+			Effect:   "Allow",
+			Action:   actions.object,
+			Resource: []string{"arn:aws:s3:::" + bucket + "/" + prefix + "*"},
+		},
+	}
+	if prefix != "" && len(actions.bucket) > 0 {
+		// Only allow listing the shared prefix.
+		statements[0].Action = []string{"s3:GetBucketLocation"}
+		statements = append(statements, statement{
+			Effect:   "Allow",
+			Action:   actions.bucket,
+			Resource: []string{"arn:aws:s3:::" + bucket},
+			Condition: map[string]interface{}{
+				"StringLike": map[string][]string{"s3:prefix": {prefix + "*"}},
+			},
+		})
+	}
+	policy, e := json.Marshal(map[string]interface{}{
+		"Version":   "2012-10-17",
+		"Statement": statements,
+	})
+	return string(policy), probe.NewError(e)
+}
+
+// assumeRole requests temporary credentials restricted by the session
+// policy from the STS API of the server.
+func assumeRole(ctx context.Context, hostCfg *aliasConfigV10, region, policy string, expire time.Duration) (credentials.AssumeRoleResult, *probe.Error) {
+	v := url.Values{}
+	v.Set("Action", "AssumeRole")
+	v.Set("Version", credentials.STSVersion)
+	v.Set("DurationSeconds", strconv.Itoa(int(expire.Seconds())))
+	v.Set("Policy", policy)
+	body := v.Encode()
+
+	u, e := url.Parse(hostCfg.URL)
+	if e != nil {
+		return credentials.AssumeRoleResult{}, probe.NewError(e)
+	}
+	u.Path = "/"
+	req, e := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(body))
+	if e != nil {
+		return credentials.AssumeRoleResult{}, probe.NewError(e)
+	}
+	hash := sha256.Sum256([]byte(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(hash[:]))
+	req = signer.SignV4STS(*req, hostCfg.AccessKey, hostCfg.SecretKey, region)
+
+	clnt := httpClient(30 * time.Second)
+	clnt.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify = globalInsecure
+	resp, e := clnt.Do(req)
+	if e != nil {
+		return credentials.AssumeRoleResult{}, probe.NewError(e)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var errResp credentials.ErrorResponse
+		if e = xml.NewDecoder(resp.Body).Decode(&errResp); e != nil {
+			return credentials.AssumeRoleResult{}, probe.NewError(fmt.Errorf("STS request failed with %s", resp.Status))
+		}
+		return credentials.AssumeRoleResult{}, probe.NewError(errResp)
+	}
+	var a credentials.AssumeRoleResponse
+	if e = xml.NewDecoder(resp.Body).Decode(&a); e != nil {
+		return credentials.AssumeRoleResult{}, probe.NewError(e)
+	}
+	return a.Result, nil
+}
+
+// shareAliasMessage container for temporary credentials of a prefix.
+type shareAliasMessage struct {
+	Status       string    `json:"status"`
+	Alias        string    `json:"alias"`
+	URL          string    `json:"url"`
+	Path         string    `json:"path"`
+	Perms        string    `json:"perms"`
+	AccessKey    string    `json:"accessKey"`
+	SecretKey    string    `json:"secretKey"`
+	SessionToken string    `json:"sessionToken"`
+	Expiration   time.Time `json:"expiration"`
+	Command      string    `json:"command"`
+}
+
+func (s shareAliasMessage) String() string {
+	return fmt.Sprintf("%s `%s` (%s) until %s, paste this line to use it as the alias `%s`:\n%s",
+		console.Colorize("Share", "Shared"), s.Path, s.Perms, s.Expiration.Local().Format(printDate), s.Alias,
+		s.Command)
+}
+
+func (s shareAliasMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// shareAliasCommand returns the shell line defining the alias with the
+// temporary credentials, in the MC_HOST_<alias> format. The credentials
+// are escaped, and marked as such, only when they contain a separator of
+// the format or a quote.
+func shareAliasCommand(alias, hostURL, accessKey, secretKey, sessionToken string) string {
+	u, e := url.Parse(hostURL)
+	if e != nil {
+		return ""
+	}
+	userInfo := accessKey + ":" + secretKey
+	if sessionToken != "" {
+		userInfo += ":" + sessionToken
+	}
+	var marker string
+	if strings.Contains(accessKey+secretKey, ":") || strings.Contains(userInfo, "'") {
+		userInfo = url.UserPassword(accessKey, secretKey).String()
+		if sessionToken != "" {
+			userInfo += ":" + url.User(sessionToken).String()
+		}
+		marker = mcEnvHostEscaped
+	}
+	return fmt.Sprintf("export MC_HOST_%s='%s://%s@%s%s'", alias, u.Scheme, userInfo, u.Host, marker)
+}
+
+func mainShareAlias(cliCtx *cli.Context) error {
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, "alias", 1) // last argument is exit code
+	}
+	ctx, cancelShareAlias := context.WithCancel(globalContext)
+	defer cancelShareAlias()
+
+	shareSetColor()
+
+	targetURL := cliCtx.Args().Get(0)
+	alias, path := url2Alias(targetURL)
+	_, _, hostCfg := mustExpandAlias(targetURL)
+	if hostCfg == nil {
+		fatalIf(errInvalidAliasedURL(targetURL), "Only object storage can be shared.")
+	}
+	bucket, prefix := splitBucketObject("/" + path)
+	if bucket == "" {
+		fatalIf(errInvalidArgument().Trace(targetURL), "Please specify a bucket.")
+	}
+	name := cliCtx.String("name")
+	if !isValidAlias(name) || strings.ContainsAny(name, "-.") {
+		// The alias is also the name of an environment variable.
+		fatalIf(errInvalidAlias(name), "Invalid alias, only letters, digits and '_' are allowed.")
+	}
+	perms := cliCtx.String("perms")
+	policy, err := shareAliasPolicy(bucket, prefix, perms)
+	fatalIf(err, "Invalid permissions, valid options are `[ro, wo, rw]`.")
+	expire, e := time.ParseDuration(cliCtx.String("expire"))
+	fatalIf(probe.NewError(e), "Unable to parse expire=`"+cliCtx.String("expire")+"`.")
+	if expire < 15*time.Minute {
+		fatalIf(errInvalidArgument().Trace(expire.String()), "Expiry must be at least 15m.")
+	}
+
+	// The STS request is signed for the region of the server.
+	region := "us-east-1"
+	if clnt, err := newClientFromAlias(alias, hostCfg.URL+"/"+bucket); err == nil {
+		if s3Clnt, ok := clnt.(*S3Client); ok {
+			if location, e := s3Clnt.api.GetBucketLocation(ctx, bucket); e == nil && location != "" {
+				region = location
+			}
+		}
+	}
+
+	result, err := assumeRole(ctx, hostCfg, region, policy, expire)
+	fatalIf(err.Trace(targetURL), "Unable to generate temporary credentials.")
+
+	creds := result.Credentials
+	printMsg(shareAliasMessage{
+		Alias:        name,
+		URL:          hostCfg.URL,
+		Path:         targetURL,
+		Perms:        perms,
+		AccessKey:    creds.AccessKey,
+		SecretKey:    creds.SecretKey,
+		SessionToken: creds.SessionToken,
+		Expiration:   creds.Expiration,
+		Command:      shareAliasCommand(name, hostCfg.URL, creds.AccessKey, creds.SecretKey, creds.SessionToken),
+	})
+	return nil
+}
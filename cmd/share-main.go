@@ -34,6 +34,7 @@ var shareSubcommands = []cli.Command{
 	shareDownload,
 	shareUpload,
 	shareList,
+	shareAlias,
 }
 
 // Share documents via URL.
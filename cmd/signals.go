@@ -18,6 +18,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
 )
@@ -52,5 +53,6 @@ func trapSignals(sig ...os.Signal) {
 	default:
 		exitCode = globalErrorExitStatus
 	}
+	notifyCompletion(exitCode, fmt.Errorf("received %s signal", s))
 	os.Exit(exitCode)
 }
@@ -0,0 +1,89 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Object size filter flags of cp and mirror.
+var sizeFilterFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "larger-than",
+		Usage: "filter object(s) larger than the given size, e.g. 64MiB",
+	},
+	cli.StringFlag{
+		Name:  "smaller-than",
+		Usage: "filter object(s) smaller than the given size, e.g. 1GiB",
+	},
+}
+
+// objectSizeFilter selects objects strictly larger than --larger-than
+// and strictly smaller than --smaller-than when set.
+type objectSizeFilter struct {
+	larger, smaller       uint64
+	hasLarger, hasSmaller bool
+}
+
+// newObjectSizeFilter parses the --larger-than and --smaller-than values.
+func newObjectSizeFilter(larger, smaller string) (objectSizeFilter, *probe.Error) {
+	var f objectSizeFilter
+	var e error
+	if larger != "" {
+		if f.larger, e = humanize.ParseBytes(larger); e != nil {
+			return f, probe.NewError(e).Trace(larger)
+		}
+		f.hasLarger = true
+	}
+	if smaller != "" {
+		if f.smaller, e = humanize.ParseBytes(smaller); e != nil {
+			return f, probe.NewError(e).Trace(smaller)
+		}
+		f.hasSmaller = true
+		if f.smaller == 0 || (f.hasLarger && f.larger+1 >= f.smaller) {
+			return f, probe.NewError(fmt.Errorf("no object size matches --larger-than `%s` and --smaller-than `%s`", larger, smaller))
+		}
+	}
+	return f, nil
+}
+
+// isEmpty returns true if no size filter is set.
+func (f objectSizeFilter) isEmpty() bool {
+	return !f.hasLarger && !f.hasSmaller
+}
+
+// match returns true if an object of the given size passes the filter.
+func (f objectSizeFilter) match(size int64) bool {
+	if f.isEmpty() {
+		return true
+	}
+	if size < 0 {
+		return false
+	}
+	if f.hasLarger && uint64(size) <= f.larger {
+		return false
+	}
+	if f.hasSmaller && uint64(size) >= f.smaller {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import "testing"
+
+func TestObjectSizeFilter(t *testing.T) {
+	testCases := []struct {
+		larger, smaller string
+		matched         []int64
+		unmatched       []int64
+		success         bool
+	}{
+		{"", "", []int64{0, 1, 1 << 40}, nil, true},
+		{"0", "", []int64{1, 1 << 40}, []int64{0}, true},
+		{"1KiB", "", []int64{1025}, []int64{0, 1024}, true},
+		{"", "1MiB", []int64{0, 1<<20 - 1}, []int64{1 << 20, 1 << 30}, true},
+		{"1KB", "2KB", []int64{1001, 1999}, []int64{1000, 2000}, true},
+		{"", "0", nil, nil, false},
+		{"2KiB", "1KiB", nil, nil, false},
+		{"1KiB", "1025", nil, nil, false},
+		{"10x", "", nil, nil, false},
+		{"", "abc", nil, nil, false},
+	}
+
+	for i, testCase := range testCases {
+		f, err := newObjectSizeFilter(testCase.larger, testCase.smaller)
+		if testCase.success != (err == nil) {
+			t.Fatalf("Test %d: expected success %v, got error %v", i+1, testCase.success, err)
+		}
+		for _, size := range testCase.matched {
+			if !f.match(size) {
+				t.Errorf("Test %d: expected size %d to match", i+1, size)
+			}
+		}
+		for _, size := range testCase.unmatched {
+			if f.match(size) {
+				t.Errorf("Test %d: expected size %d not to match", i+1, size)
+			}
+		}
+	}
+}
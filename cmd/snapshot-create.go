@@ -0,0 +1,181 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var snapshotCreateFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:  "output, o",
+		Usage: "file to write the snapshot to, compressed with zstd if it ends with '.zst'",
+	},
+	cli.BoolFlag{
+		Name:  "versions",
+		Usage: "record all object versions and delete markers",
+	},
+}
+
+var snapshotCreateCmd = cli.Command{
+	Name:         "create",
+	Usage:        "record a point-in-time listing of a bucket into a file",
+	Action:       mainSnapshotCreate,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(snapshotCreateFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET --output FILE
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  A snapshot records the name, size, ETag, modification time and storage class
+  of every object under TARGET. Snapshots can be compared offline with 'mc snapshot diff'.
+
+EXAMPLES:
+  1. Record the objects of bucket 'photos' into a compressed snapshot.
+     {{.Prompt}} {{.HelpName}} myminio/photos -o photos-monday.json.zst
+
+  2. Record all versions of the objects under the prefix '2021/' of bucket 'photos'.
+     {{.Prompt}} {{.HelpName}} --versions myminio/photos/2021/ -o photos-2021.json.zst
+`,
+}
+
+// snapshotCreateMessage container for a created snapshot.
+type snapshotCreateMessage struct {
+	Status  string `json:"status"`
+	URL     string `json:"url"`
+	File    string `json:"file"`
+	Objects int64  `json:"objects"`
+	Size    int64  `json:"size"`
+}
+
+func (s snapshotCreateMessage) String() string {
+	return console.Colorize("Snapshot", fmt.Sprintf("Recorded %d object(s), %s, of `%s` into `%s`.",
+		s.Objects, humanize.IBytes(uint64(s.Size)), s.URL, s.File))
+}
+
+func (s snapshotCreateMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+func checkSnapshotCreateSyntax(cliCtx *cli.Context) {
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, "create", 1) // last argument is exit code
+	}
+	if cliCtx.String("output") == "" {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "Please specify the snapshot file with --output.")
+	}
+}
+
+// createSnapshot lists all objects under urlStr into the snapshot.
+func createSnapshot(ctx context.Context, urlStr string, versions bool, s *snapshotWriter) *probe.Error {
+	clnt, err := newClient(urlStr)
+	if err != nil {
+		return err.Trace(urlStr)
+	}
+	separator := string(clnt.GetURL().Separator)
+	basePath := clnt.GetURL().Path
+
+	for content := range clnt.List(ctx, ListOptions{
+		Recursive:         true,
+		WithOlderVersions: versions,
+		WithDeleteMarkers: versions,
+		ShowDir:           DirNone,
+	}) {
+		if content.Err != nil {
+			return content.Err.Trace(urlStr)
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		key := strings.TrimPrefix(content.URL.Path, basePath)
+		key = strings.TrimPrefix(key, separator)
+		if key == "" {
+			// The target is a single object.
+			key = content.URL.Path[strings.LastIndex(content.URL.Path, separator)+1:]
+		}
+		if err = s.Write(snapshotEntry{
+			Key:            key,
+			VersionID:      content.VersionID,
+			IsLatest:       versions && content.IsLatest,
+			IsDeleteMarker: content.IsDeleteMarker,
+			Size:           content.Size,
+			ETag:           content.ETag,
+			LastModified:   content.Time.UTC(),
+			StorageClass:   content.StorageClass,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mainSnapshotCreate(cliCtx *cli.Context) error {
+	ctx, cancelSnapshotCreate := context.WithCancel(globalContext)
+	defer cancelSnapshotCreate()
+
+	console.SetColor("Snapshot", color.New(color.FgGreen, color.Bold))
+
+	checkSnapshotCreateSyntax(cliCtx)
+	urlStr := cliCtx.Args().Get(0)
+	filename := cliCtx.String("output")
+	versions := cliCtx.Bool("versions")
+
+	s, err := newSnapshotWriter(filename, snapshotHeader{
+		Version:   snapshotFormatVersion,
+		URL:       urlStr,
+		Versions:  versions,
+		CreatedAt: time.Now().UTC(),
+	})
+	fatalIf(err.Trace(filename), "Unable to create the snapshot file.")
+
+	if err = createSnapshot(ctx, urlStr, versions, s); err != nil {
+		s.Close()
+		os.Remove(filename)
+		fatalIf(err, "Unable to record the snapshot of `"+urlStr+"`.")
+	}
+	fatalIf(s.Close().Trace(filename), "Unable to write the snapshot file.")
+
+	printMsg(snapshotCreateMessage{
+		URL:     urlStr,
+		File:    filename,
+		Objects: s.entries,
+		Size:    s.size,
+	})
+	return nil
+}
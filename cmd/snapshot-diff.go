@@ -0,0 +1,253 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var snapshotDiffFlags = []cli.Flag{
+	cli.BoolFlag{
+		Name:  "summary",
+		Usage: "print only a summary of the differences found",
+	},
+}
+
+var snapshotDiffCmd = cli.Command{
+	Name:         "diff",
+	Usage:        "list differences between two snapshots",
+	Action:       mainSnapshotDiff,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(snapshotDiffFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] FIRST SECOND
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+DESCRIPTION:
+  Snapshots are compared offline, the cluster is not contacted. Objects are
+  compared by size, ETag, modification time and storage class.
+
+LEGEND:
+  - - object was removed after FIRST was recorded.
+  + - object was added after FIRST was recorded.
+  ! - object was modified after FIRST was recorded.
+
+EXIT STATUS:
+  0 - FIRST and SECOND are identical.
+  1 - differences were found.
+  2 - an error occurred, the comparison may be incomplete.
+
+EXAMPLES:
+  1. List the changes in bucket 'photos' between monday and tuesday.
+     {{.Prompt}} {{.HelpName}} photos-monday.json.zst photos-tuesday.json.zst
+
+  2. Print a JSON summary of the changes in bucket 'photos' between monday and tuesday.
+     {{.Prompt}} {{.HelpName}} --summary --json photos-monday.json.zst photos-tuesday.json.zst
+`,
+}
+
+// snapshot difference kinds.
+const (
+	snapshotDiffAdded    = "added"
+	snapshotDiffRemoved  = "removed"
+	snapshotDiffModified = "modified"
+)
+
+// snapshotDiffMessage container for a difference between two snapshots.
+type snapshotDiffMessage struct {
+	Status string         `json:"status"`
+	Key    string         `json:"key"`
+	Diff   string         `json:"diff"`
+	First  *snapshotEntry `json:"first,omitempty"`
+	Second *snapshotEntry `json:"second,omitempty"`
+}
+
+func (s snapshotDiffMessage) String() string {
+	switch s.Diff {
+	case snapshotDiffAdded:
+		return console.Colorize("DiffOnlyInSecond", "+ "+s.Key)
+	case snapshotDiffRemoved:
+		return console.Colorize("DiffOnlyInFirst", "- "+s.Key)
+	default:
+		return console.Colorize("DiffModified", "! "+s.Key)
+	}
+}
+
+func (s snapshotDiffMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// snapshotDiffSummaryMessage container for the summary of a snapshot diff.
+type snapshotDiffSummaryMessage struct {
+	Status      string           `json:"status"`
+	First       string           `json:"first"`
+	Second      string           `json:"second"`
+	Identical   bool             `json:"identical"`
+	Total       int64            `json:"total"`
+	Differences map[string]int64 `json:"differences"`
+}
+
+func (s snapshotDiffSummaryMessage) String() string {
+	if s.Identical {
+		return console.Colorize("DiffMessage", "No differences found between `"+s.First+"` and `"+s.Second+"`.")
+	}
+	var counts []string
+	for _, kind := range []string{snapshotDiffAdded, snapshotDiffRemoved, snapshotDiffModified} {
+		if s.Differences[kind] > 0 {
+			counts = append(counts, fmt.Sprintf("%s: %d", kind, s.Differences[kind]))
+		}
+	}
+	return console.Colorize("DiffModified", fmt.Sprintf("Found %d difference(s) between `%s` and `%s` (%s).",
+		s.Total, s.First, s.Second, strings.Join(counts, ", ")))
+}
+
+func (s snapshotDiffSummaryMessage) JSON() string {
+	s.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// isSnapshotEntryModified returns true if the object changed between both entries.
+func isSnapshotEntryModified(first, second snapshotEntry) bool {
+	return first.Size != second.Size ||
+		first.ETag != second.ETag ||
+		!first.LastModified.Equal(second.LastModified) ||
+		first.StorageClass != second.StorageClass ||
+		first.IsDeleteMarker != second.IsDeleteMarker
+}
+
+// diffSnapshots calls onDiff for every difference between both snapshots.
+// FIRST is held in memory while SECOND is streamed.
+func diffSnapshots(ctx context.Context, first, second *snapshotReader, onDiff func(snapshotDiffMessage)) *probe.Error {
+	firstEntries := make(map[string]snapshotEntry)
+	for {
+		entry, e := first.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return probe.NewError(e)
+		}
+		firstEntries[entry.id()] = entry
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return probe.NewError(ctx.Err())
+		}
+		entry, e := second.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			return probe.NewError(e)
+		}
+		secondEntry := entry
+		firstEntry, ok := firstEntries[entry.id()]
+		if !ok {
+			onDiff(snapshotDiffMessage{Key: entry.id(), Diff: snapshotDiffAdded, Second: &secondEntry})
+			continue
+		}
+		delete(firstEntries, entry.id())
+		if isSnapshotEntryModified(firstEntry, secondEntry) {
+			onDiff(snapshotDiffMessage{Key: entry.id(), Diff: snapshotDiffModified, First: &firstEntry, Second: &secondEntry})
+		}
+	}
+
+	removed := make([]string, 0, len(firstEntries))
+	for id := range firstEntries {
+		removed = append(removed, id)
+	}
+	sort.Strings(removed)
+	for _, id := range removed {
+		firstEntry := firstEntries[id]
+		onDiff(snapshotDiffMessage{Key: id, Diff: snapshotDiffRemoved, First: &firstEntry})
+	}
+	return nil
+}
+
+func mainSnapshotDiff(cliCtx *cli.Context) error {
+	ctx, cancelSnapshotDiff := context.WithCancel(globalContext)
+	defer cancelSnapshotDiff()
+
+	console.SetColor("DiffMessage", color.New(color.FgGreen, color.Bold))
+	console.SetColor("DiffOnlyInFirst", color.New(color.FgRed))
+	console.SetColor("DiffOnlyInSecond", color.New(color.FgGreen))
+	console.SetColor("DiffModified", color.New(color.FgMagenta))
+
+	if len(cliCtx.Args()) != 2 {
+		cli.ShowCommandHelpAndExit(cliCtx, "diff", diffErrorExitStatus) // last argument is exit code
+	}
+	firstFile := cliCtx.Args().Get(0)
+	secondFile := cliCtx.Args().Get(1)
+	summary := cliCtx.Bool("summary")
+
+	first, err := openSnapshot(firstFile)
+	diffFatalIf(err, "Unable to open the snapshot `"+firstFile+"`.")
+	defer first.Close()
+	second, err := openSnapshot(secondFile)
+	diffFatalIf(err, "Unable to open the snapshot `"+secondFile+"`.")
+	defer second.Close()
+
+	if first.Header.URL != second.Header.URL {
+		console.Infoln("Comparing snapshots of different locations `" + first.Header.URL + "` and `" + second.Header.URL + "`.")
+	}
+
+	summaryMsg := snapshotDiffSummaryMessage{
+		First:       firstFile,
+		Second:      secondFile,
+		Differences: make(map[string]int64),
+	}
+	err = diffSnapshots(ctx, first, second, func(msg snapshotDiffMessage) {
+		summaryMsg.Total++
+		summaryMsg.Differences[msg.Diff]++
+		if !summary {
+			printMsg(msg)
+		}
+	})
+	diffFatalIf(err, "Unable to compare `"+firstFile+"` and `"+secondFile+"`.")
+
+	summaryMsg.Identical = summaryMsg.Total == 0
+	if summary {
+		printMsg(summaryMsg)
+	}
+	if !summaryMsg.Identical {
+		return exitStatus(diffDifferentExitStatus)
+	}
+	return nil
+}
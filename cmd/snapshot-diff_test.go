@@ -0,0 +1,171 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTestSnapshot(t *testing.T, filename string, entries []snapshotEntry) {
+	t.Helper()
+	w, err := newSnapshotWriter(filename, snapshotHeader{Version: snapshotFormatVersion, URL: "myminio/bucket"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if err = w.Write(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	modTime := time.Date(2021, 10, 1, 0, 0, 0, 0, time.UTC)
+	base := []snapshotEntry{
+		{Key: "a", Size: 1, ETag: "e1", LastModified: modTime},
+		{Key: "b", Size: 2, ETag: "e2", LastModified: modTime},
+		{Key: "c", Size: 3, ETag: "e3", LastModified: modTime, StorageClass: "STANDARD"},
+		{Key: "d", VersionID: "v1", Size: 4, ETag: "e4", LastModified: modTime},
+		{Key: "d", VersionID: "v2", Size: 4, ETag: "e4", LastModified: modTime, IsLatest: true},
+	}
+	changed := []snapshotEntry{
+		{Key: "a", Size: 1, ETag: "e1", LastModified: modTime},
+		{Key: "b", Size: 2, ETag: "e2-new", LastModified: modTime},
+		{Key: "c", Size: 3, ETag: "e3", LastModified: modTime, StorageClass: "GLACIER"},
+		{Key: "d", VersionID: "v2", Size: 4, ETag: "e4", LastModified: modTime, IsLatest: true},
+		{Key: "d", VersionID: "v3", LastModified: modTime, IsDeleteMarker: true},
+		{Key: "e", Size: 5, ETag: "e5", LastModified: modTime.Add(time.Hour)},
+	}
+
+	testCases := []struct {
+		first, second []snapshotEntry
+		diffs         []string
+	}{
+		// Identical snapshots.
+		{base, base, nil},
+		// Empty snapshots.
+		{nil, nil, nil},
+		// Everything added or removed.
+		{nil, base[:2], []string{"added a", "added b"}},
+		{base[:2], nil, []string{"removed a", "removed b"}},
+		// Additions and modifications in listing order, then removals sorted.
+		{base, changed, []string{"modified b", "modified c", "added d?versionId=v3", "added e", "removed d?versionId=v1"}},
+		{changed, base, []string{"modified b", "modified c", "added d?versionId=v1", "removed d?versionId=v3", "removed e"}},
+		// Only the modification time changed.
+		{base[:1], []snapshotEntry{{Key: "a", Size: 1, ETag: "e1", LastModified: modTime.Add(time.Second)}}, []string{"modified a"}},
+	}
+
+	for i, testCase := range testCases {
+		dir := t.TempDir()
+		// Mix compressed and uncompressed snapshots.
+		firstFile, secondFile := filepath.Join(dir, "first.jsonl"), filepath.Join(dir, "second.jsonl.zst")
+		writeTestSnapshot(t, firstFile, testCase.first)
+		writeTestSnapshot(t, secondFile, testCase.second)
+
+		first, err := openSnapshot(firstFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := openSnapshot(secondFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var diffs []string
+		err = diffSnapshots(context.Background(), first, second, func(msg snapshotDiffMessage) {
+			switch msg.Diff {
+			case snapshotDiffAdded:
+				if msg.First != nil || msg.Second == nil || msg.Second.id() != msg.Key {
+					t.Errorf("Test %d: unexpected entries for added %s", i+1, msg.Key)
+				}
+			case snapshotDiffRemoved:
+				if msg.First == nil || msg.Second != nil || msg.First.id() != msg.Key {
+					t.Errorf("Test %d: unexpected entries for removed %s", i+1, msg.Key)
+				}
+			case snapshotDiffModified:
+				if msg.First == nil || msg.Second == nil || *msg.First == *msg.Second {
+					t.Errorf("Test %d: unexpected entries for modified %s", i+1, msg.Key)
+				}
+			}
+			diffs = append(diffs, msg.Diff+" "+msg.Key)
+		})
+		first.Close()
+		second.Close()
+		if err != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, err)
+		}
+		if !reflect.DeepEqual(diffs, testCase.diffs) {
+			t.Errorf("Test %d: expected %v, got %v", i+1, testCase.diffs, diffs)
+		}
+	}
+}
+
+func TestDiffSnapshotsErrors(t *testing.T) {
+	entries := []snapshotEntry{{Key: "a", Size: 1}, {Key: "b", Size: 2}}
+	dir := t.TempDir()
+	goodFile := filepath.Join(dir, "good.jsonl")
+	writeTestSnapshot(t, goodFile, entries)
+
+	open := func(data string) *snapshotReader {
+		s, err := newSnapshotReader(strings.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	header := `{"version":"` + snapshotFormatVersion + `","url":"myminio/bucket"}` + "\n"
+
+	// A corrupted entry in either snapshot fails the diff.
+	for i, corrupted := range []bool{true, false} {
+		good, err := openSnapshot(goodFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bad := open(header + `{"key":"a","size":1}` + "\n" + `{"key":` + "\n")
+		first, second := bad, good
+		if !corrupted {
+			first, second = good, bad
+		}
+		if err = diffSnapshots(context.Background(), first, second, func(snapshotDiffMessage) {}); err == nil {
+			t.Errorf("Test %d: expected an error for a corrupted snapshot", i+1)
+		}
+		good.Close()
+	}
+
+	// A canceled diff stops before reading the second snapshot.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	first, second := open(header), open(header+`{"key":"a","size":1}`+"\n")
+	called := false
+	if err := diffSnapshots(ctx, first, second, func(snapshotDiffMessage) { called = true }); err == nil || called {
+		t.Errorf("expected the diff to be canceled, got %v, called %v", err, called)
+	}
+
+	// Snapshots of another format version are rejected.
+	if _, err := newSnapshotReader(strings.NewReader(`{"version":"0"}` + "\n")); err == nil {
+		t.Error("expected an error for an unknown snapshot version")
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// snapshotFormatVersion is the version of the snapshot file format.
+const snapshotFormatVersion = "1"
+
+// zstdMagic is the header of every zstd frame.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+var snapshotSubcommands = []cli.Command{
+	snapshotCreateCmd,
+	snapshotDiffCmd,
+}
+
+var snapshotCmd = cli.Command{
+	Name:            "snapshot",
+	Usage:           "record and compare point-in-time listings of a bucket",
+	Action:          mainSnapshot,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	HideHelpCommand: true,
+	Subcommands:     snapshotSubcommands,
+}
+
+func mainSnapshot(ctx *cli.Context) error {
+	commandNotFound(ctx, snapshotSubcommands)
+	return nil
+}
+
+// snapshotHeader is the first line of a snapshot file.
+type snapshotHeader struct {
+	Version   string    `json:"version"`
+	URL       string    `json:"url"`
+	Versions  bool      `json:"versions"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// snapshotEntry is an object, or an object version, in a snapshot.
+type snapshotEntry struct {
+	Key            string    `json:"key"`
+	VersionID      string    `json:"versionId,omitempty"`
+	IsLatest       bool      `json:"isLatest,omitempty"`
+	IsDeleteMarker bool      `json:"isDeleteMarker,omitempty"`
+	Size           int64     `json:"size"`
+	ETag           string    `json:"etag,omitempty"`
+	LastModified   time.Time `json:"lastModified"`
+	StorageClass   string    `json:"storageClass,omitempty"`
+}
+
+// id identifies the entry across snapshots.
+func (e snapshotEntry) id() string {
+	if e.VersionID == "" {
+		return e.Key
+	}
+	return e.Key + "?versionId=" + e.VersionID
+}
+
+// snapshotWriter writes a snapshot as JSON lines, compressed with zstd
+// when the file name ends with ".zst".
+type snapshotWriter struct {
+	file    *os.File
+	zw      *zstd.Encoder
+	w       *bufio.Writer
+	entries int64
+	size    int64
+}
+
+func newSnapshotWriter(filename string, header snapshotHeader) (*snapshotWriter, *probe.Error) {
+	f, e := os.OpenFile(filename, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	s := &snapshotWriter{file: f}
+	if strings.HasSuffix(filename, ".zst") {
+		s.zw, e = zstd.NewWriter(f)
+		if e != nil {
+			f.Close()
+			return nil, probe.NewError(e)
+		}
+		s.w = bufio.NewWriter(s.zw)
+	} else {
+		s.w = bufio.NewWriter(f)
+	}
+	if err := s.writeLine(header); err != nil {
+		s.Close()
+		return nil, err.Trace(filename)
+	}
+	return s, nil
+}
+
+func (s *snapshotWriter) writeLine(v interface{}) *probe.Error {
+	buf, e := json.Marshal(v)
+	if e != nil {
+		return probe.NewError(e)
+	}
+	buf = append(buf, '\n')
+	_, e = s.w.Write(buf)
+	return probe.NewError(e)
+}
+
+// Write adds an entry to the snapshot.
+func (s *snapshotWriter) Write(entry snapshotEntry) *probe.Error {
+	if err := s.writeLine(entry); err != nil {
+		return err.Trace(entry.Key)
+	}
+	s.entries++
+	s.size += entry.Size
+	return nil
+}
+
+// Close flushes the snapshot to disk.
+func (s *snapshotWriter) Close() *probe.Error {
+	e := s.w.Flush()
+	if s.zw != nil {
+		if ze := s.zw.Close(); e == nil {
+			e = ze
+		}
+	}
+	if fe := s.file.Close(); e == nil {
+		e = fe
+	}
+	return probe.NewError(e)
+}
+
+// snapshotReader reads a snapshot written by snapshotWriter.
+type snapshotReader struct {
+	Header snapshotHeader
+
+	closer io.Closer
+	zr     *zstd.Decoder
+	dec    *json.Decoder
+}
+
+func newSnapshotReader(r io.Reader) (*snapshotReader, *probe.Error) {
+	s := &snapshotReader{}
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(zstdMagic))
+	if bytes.Equal(magic, zstdMagic) {
+		zr, e := zstd.NewReader(br)
+		if e != nil {
+			return nil, probe.NewError(e)
+		}
+		s.zr = zr
+		s.dec = json.NewDecoder(zr)
+	} else {
+		s.dec = json.NewDecoder(br)
+	}
+	if e := s.dec.Decode(&s.Header); e != nil {
+		s.Close()
+		return nil, probe.NewError(e)
+	}
+	if s.Header.Version != snapshotFormatVersion {
+		s.Close()
+		return nil, errInvalidArgument().Trace(s.Header.Version)
+	}
+	return s, nil
+}
+
+func openSnapshot(filename string) (*snapshotReader, *probe.Error) {
+	f, e := os.Open(filename)
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	s, err := newSnapshotReader(f)
+	if err != nil {
+		f.Close()
+		return nil, err.Trace(filename)
+	}
+	s.closer = f
+	return s, nil
+}
+
+// Next returns the next entry of the snapshot, io.EOF once all
+// entries have been read.
+func (s *snapshotReader) Next() (entry snapshotEntry, e error) {
+	e = s.dec.Decode(&entry)
+	return entry, e
+}
+
+// Close releases the resources held by the reader.
+func (s *snapshotReader) Close() {
+	if s.zr != nil {
+		s.zr.Close()
+	}
+	if s.closer != nil {
+		s.closer.Close()
+	}
+}
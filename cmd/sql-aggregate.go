@@ -0,0 +1,291 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// sqlAggregate is the function of a selected column, telling how the
+// values returned by every object merge.
+type sqlAggregate string
+
+const (
+	sqlCount sqlAggregate = "count"
+	sqlSum   sqlAggregate = "sum"
+	sqlMin   sqlAggregate = "min"
+	sqlMax   sqlAggregate = "max"
+)
+
+// parseSQLAggregates returns the aggregate function of every column
+// selected by the query. Only COUNT, SUM, MIN and MAX results merge
+// across objects, so any other column is an error.
+func parseSQLAggregates(query string) ([]sqlAggregate, error) {
+	columns, e := sqlSelectList(query)
+	if e != nil {
+		return nil, e
+	}
+	aggregates := make([]sqlAggregate, 0, len(columns))
+	for _, column := range columns {
+		// An aggregate column is a call, with an optional alias.
+		name := ""
+		if m := sqlAggregateColumnRgx.FindStringSubmatch(column); m != nil {
+			name = m[1]
+		}
+		switch fn := sqlAggregate(strings.ToLower(name)); fn {
+		case sqlCount, sqlSum, sqlMin, sqlMax:
+			aggregates = append(aggregates, fn)
+		case "avg":
+			return nil, fmt.Errorf("unable to aggregate `%s`, select SUM and COUNT instead and divide them", column)
+		default:
+			return nil, fmt.Errorf("unable to aggregate `%s`, every selected column must be a COUNT, SUM, MIN or MAX", column)
+		}
+	}
+	return aggregates, nil
+}
+
+var sqlAggregateColumnRgx = regexp.MustCompile(`(?is)^([a-z_]+)\s*\(.*\)(\s+(as\s+)?("[^"]*"|[a-z_][a-z0-9_]*))?$`)
+
+// sqlSelectList returns the columns selected by the query, the
+// comma separated expressions between SELECT and FROM.
+func sqlSelectList(query string) ([]string, error) {
+	query = strings.TrimSpace(query)
+	if len(query) < 6 || !strings.EqualFold(query[:6], "select") {
+		return nil, fmt.Errorf("unable to aggregate `%s`, expected a SELECT query", query)
+	}
+
+	var (
+		columns []string
+		start   = 6
+		depth   int
+		quote   rune
+	)
+	for i, r := range query {
+		if i < start {
+			continue
+		}
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			columns = append(columns, strings.TrimSpace(query[start:i]))
+			start = i + 1
+		case depth == 0 && (i == 0 || !isSQLIdentRune(rune(query[i-1]))) &&
+			len(query) >= i+4 && strings.EqualFold(query[i:i+4], "from") &&
+			(len(query) == i+4 || !isSQLIdentRune(rune(query[i+4]))):
+			columns = append(columns, strings.TrimSpace(query[start:i]))
+			for _, column := range columns {
+				if column == "" {
+					return nil, fmt.Errorf("unable to aggregate `%s`, empty column in SELECT", query)
+				}
+			}
+			return columns, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to aggregate `%s`, expected a FROM clause", query)
+}
+
+func isSQLIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// sqlAggregator merges the results of an aggregate query run on many
+// objects into a single record. Every object yields one record, whose
+// counts and sums add up and whose minimums and maximums are compared.
+type sqlAggregator struct {
+	aggregates      []sqlAggregate
+	format          string // "csv" or "json", detected from the first result when unset.
+	fieldDelimiter  string
+	recordDelimiter string
+	quoteChar       string
+
+	keys   []string // column names of json records.
+	values []float64
+	seen   []bool // whether a column had a value, objects without rows have none.
+	rows   int
+}
+
+// newSQLAggregator returns an aggregator of the columns of the query,
+// reading the results written with the given output serialization
+// options.
+func newSQLAggregator(aggregates []sqlAggregate, outputOpts map[string]map[string]string) *sqlAggregator {
+	a := &sqlAggregator{
+		aggregates:      aggregates,
+		fieldDelimiter:  defaultFieldDelimiter,
+		recordDelimiter: defaultRecordDelimiter,
+		quoteChar:       `"`,
+		values:          make([]float64, len(aggregates)),
+		seen:            make([]bool, len(aggregates)),
+	}
+	for format, kv := range outputOpts {
+		a.format = format
+		if v, ok := kv[recordDelimiterType]; ok && v != "" {
+			a.recordDelimiter = v
+		}
+		if v, ok := kv[fieldDelimiterType]; ok && v != "" {
+			a.fieldDelimiter = v
+		}
+		if v, ok := kv[quoteCharacterType]; ok && v != "" {
+			a.quoteChar = v
+		}
+	}
+	return a
+}
+
+// add merges all the records of one object's results.
+func (a *sqlAggregator) add(data []byte) error {
+	if a.format == "" {
+		a.format = "csv"
+		if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+			a.format = "json"
+		}
+	}
+	for _, record := range strings.Split(string(data), a.recordDelimiter) {
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		var (
+			keys   []string
+			values []string
+			err    error
+		)
+		if a.format == "json" {
+			keys, values, err = parseJSONRecord(record)
+			if err != nil {
+				return err
+			}
+		} else {
+			for _, field := range strings.Split(record, a.fieldDelimiter) {
+				values = append(values, strings.Trim(strings.TrimSpace(field), a.quoteChar))
+			}
+		}
+		if err = a.addRecord(keys, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *sqlAggregator) addRecord(keys, values []string) error {
+	if len(values) != len(a.aggregates) {
+		return fmt.Errorf("record has %d columns, expected %d", len(values), len(a.aggregates))
+	}
+	if a.rows == 0 {
+		a.keys = keys
+	} else if strings.Join(keys, ",") != strings.Join(a.keys, ",") {
+		return fmt.Errorf("record has different columns than the first result")
+	}
+	for i, value := range values {
+		// MIN, MAX and SUM of an object without matching rows are null.
+		if value == "" {
+			continue
+		}
+		n, e := strconv.ParseFloat(value, 64)
+		if e != nil {
+			return fmt.Errorf("unable to aggregate non numeric value `%s`", value)
+		}
+		switch {
+		case !a.seen[i]:
+			a.values[i] = n
+		case a.aggregates[i] == sqlMin:
+			a.values[i] = math.Min(a.values[i], n)
+		case a.aggregates[i] == sqlMax:
+			a.values[i] = math.Max(a.values[i], n)
+		default:
+			a.values[i] += n
+		}
+		a.seen[i] = true
+	}
+	a.rows++
+	return nil
+}
+
+// parseJSONRecord returns the keys and the numeric values of a flat
+// json object, in the order they appear. Null values are empty.
+func parseJSONRecord(record string) (keys, values []string, err error) {
+	dec := json.NewDecoder(strings.NewReader(record))
+	dec.UseNumber()
+	if t, e := dec.Token(); e != nil || t != json.Delim('{') {
+		return nil, nil, fmt.Errorf("unable to aggregate record `%s`, expected a json object", record)
+	}
+	for dec.More() {
+		k, e := dec.Token()
+		if e != nil {
+			return nil, nil, e
+		}
+		v, e := dec.Token()
+		if e != nil {
+			return nil, nil, e
+		}
+		var value string
+		switch v := v.(type) {
+		case json.Number:
+			value = v.String()
+		case nil:
+		default:
+			return nil, nil, fmt.Errorf("unable to aggregate non numeric value of `%v`", k)
+		}
+		keys = append(keys, k.(string))
+		values = append(values, value)
+	}
+	return keys, values, nil
+}
+
+// write prints the merged record, or nothing if no object returned any.
+func (a *sqlAggregator) write(w io.Writer) error {
+	if a.rows == 0 {
+		return nil
+	}
+	fields := make([]string, len(a.values))
+	for i, value := range a.values {
+		switch {
+		case a.seen[i]:
+			fields[i] = strconv.FormatFloat(value, 'f', -1, 64)
+		case a.format == "json":
+			fields[i] = "null"
+		}
+	}
+	var record string
+	if a.format == "json" {
+		for i := range fields {
+			key, _ := json.Marshal(a.keys[i])
+			fields[i] = string(key) + ":" + fields[i]
+		}
+		record = "{" + strings.Join(fields, ",") + "}"
+	} else {
+		record = strings.Join(fields, a.fieldDelimiter)
+	}
+	_, e := io.WriteString(w, record+a.recordDelimiter)
+	return e
+}
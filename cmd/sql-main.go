@@ -19,6 +19,7 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
 	"compress/bzip2"
 	"compress/gzip"
 	"context"
@@ -30,6 +31,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/minio/cli"
@@ -73,6 +75,39 @@ var (
 			Name:  "json-output",
 			Usage: "json output serialization option",
 		},
+		cli.StringFlag{
+			Name:  "output-format",
+			Usage: "format of the query results, one of 'csv' or 'json'",
+		},
+		cli.StringFlag{
+			Name:  "field-delimiter",
+			Usage: "field delimiter of csv results, escapes like '\\t' are honored",
+		},
+		cli.StringFlag{
+			Name:  "record-delimiter",
+			Usage: "record delimiter of the results, escapes like '\\n' are honored",
+		},
+		cli.StringFlag{
+			Name:  "quote-fields",
+			Usage: "quote the fields of csv results, one of 'always' or 'asneeded'",
+		},
+		cli.StringFlag{
+			Name:  "quote-char",
+			Usage: "quote character of csv results",
+		},
+		cli.StringFlag{
+			Name:  "output, o",
+			Usage: "write the results to a file instead of stdout",
+		},
+		cli.IntFlag{
+			Name:  "workers",
+			Value: 1,
+			Usage: "number of objects queried concurrently, the results of each object are written whole",
+		},
+		cli.BoolFlag{
+			Name:  "aggregate",
+			Usage: "merge the COUNT, SUM, MIN and MAX columns returned by each object into one record",
+		},
 	}
 )
 
@@ -83,7 +118,7 @@ var sqlCmd = cli.Command{
 	Action:       mainSQL,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(sqlFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(sqlFlags, ioFlags...), globalFlagsWithout("query")...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -125,6 +160,14 @@ EXAMPLES:
      {{.Prompt}} {{.HelpName}} --compression GZIP --csv-input "rd=\n,fh=USE,fd=;" \
            --csv-output "rd=\n" --csv-output-header "device_id,uptime,lat,lon" \
            --query "select * from S3Object" myminio/iot-devices/data.csv
+
+  7. Export the results of a query as tab separated values with every field quoted to a local file.
+     {{.Prompt}} {{.HelpName}} --output-format csv --field-delimiter '\t' --quote-fields always \
+           --output devices.tsv --query "select * from S3Object" myminio/iot-devices/data.csv
+
+  8. Count the records and sum the uptime of all devices, querying 16 objects at a time.
+     {{.Prompt}} {{.HelpName}} --recursive --workers 16 --aggregate --csv-input "fh=USE" \
+           --query "select count(*), sum(cast(s.uptime as int)) from S3Object s" myminio/iot-devices/
 `,
 }
 
@@ -288,6 +331,13 @@ func getOutputSerializationOpts(ctx *cli.Context, csvHdrs []string) (opts map[st
 		fatalIf(errInvalidArgument(), "--csv-output-header incompatible with --json-output option")
 	}
 
+	// Conflicts with --output-format are rejected by checkSQLSyntax.
+	formatOpts, _ := parseOutputFormat(ctx.String("output-format"), ctx.String("field-delimiter"),
+		ctx.String("record-delimiter"), ctx.String("quote-fields"), ctx.String("quote-char"))
+	if formatOpts != nil {
+		return formatOpts
+	}
+
 	if csvType {
 		validKeys := append(validCSVCommonKeys, validJSONCSVCommonOutputKeys...)
 		kv, err := parseSerializationOpts(ocsv, append(validKeys, validCSVOutputKeys...), validCSVOutputAbbrKeys)
@@ -303,6 +353,45 @@ func getOutputSerializationOpts(ctx *cli.Context, csvHdrs []string) (opts map[st
 	return m
 }
 
+// parseOutputFormat returns the output serialization options of
+// --output-format and of the delimiter and quoting flags, which can
+// only be used with it. It returns nil when no format is given.
+func parseOutputFormat(format, fieldDelimiter, recordDelimiter, quoteFields, quoteChar string) (map[string]map[string]string, *probe.Error) {
+	format = strings.ToLower(format)
+	if format == "" {
+		if fieldDelimiter != "" || recordDelimiter != "" || quoteFields != "" || quoteChar != "" {
+			return nil, probe.NewError(errors.New("--field-delimiter, --record-delimiter, --quote-fields and --quote-char require --output-format"))
+		}
+		return nil, nil
+	}
+	if format != "csv" && format != "json" {
+		return nil, probe.NewError(fmt.Errorf("unknown output format `%s`, expected one of 'csv' or 'json'", format))
+	}
+	if format == "json" && (fieldDelimiter != "" || quoteFields != "" || quoteChar != "") {
+		return nil, probe.NewError(errors.New("--field-delimiter, --quote-fields and --quote-char can only be used with csv results"))
+	}
+
+	unescape := strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\r`, "\r").Replace
+	kv := make(map[string]string)
+	if recordDelimiter != "" {
+		kv[recordDelimiterType] = unescape(recordDelimiter)
+	}
+	if fieldDelimiter != "" {
+		kv[fieldDelimiterType] = unescape(fieldDelimiter)
+	}
+	if quoteChar != "" {
+		kv[quoteCharacterType] = quoteChar
+	}
+	switch strings.ToLower(quoteFields) {
+	case "":
+	case "always", "asneeded":
+		kv[quoteFieldsType] = strings.ToUpper(quoteFields)
+	default:
+		return nil, probe.NewError(fmt.Errorf("unknown --quote-fields value `%s`, expected one of 'always' or 'asneeded'", quoteFields))
+	}
+	return map[string]map[string]string{format: kv}, nil
+}
+
 // getCSVHeader fetches the first line of csv query object
 func getCSVHeader(sourceURL string, encKeyDB map[string][]prefixSSEPair) ([]string, *probe.Error) {
 	var r io.ReadCloser
@@ -384,7 +473,7 @@ func isCSVOrJSON(inOpts map[string]map[string]string) bool {
 	return false
 }
 
-func sqlSelect(targetURL, expression string, encKeyDB map[string][]prefixSSEPair, selOpts SelectObjectOpts, csvHdrs []string, writeHdr bool) *probe.Error {
+func sqlSelect(w io.Writer, targetURL, expression string, encKeyDB map[string][]prefixSSEPair, selOpts SelectObjectOpts) *probe.Error {
 	ctx, cancelSelect := context.WithCancel(globalContext)
 	defer cancelSelect()
 
@@ -405,11 +494,7 @@ func sqlSelect(targetURL, expression string, encKeyDB map[string][]prefixSSEPair
 	}
 	defer outputer.Close()
 
-	// write csv header to stdout
-	if len(csvHdrs) > 0 && writeHdr {
-		fmt.Println(strings.Join(csvHdrs, ","))
-	}
-	_, e := io.Copy(os.Stdout, outputer)
+	_, e := io.Copy(w, outputer)
 	return probe.NewError(e)
 }
 
@@ -434,6 +519,148 @@ func checkSQLSyntax(ctx *cli.Context) {
 	if len(ctx.Args()) == 0 {
 		cli.ShowCommandHelpAndExit(ctx, "sql", 1) // last argument is exit code.
 	}
+
+	formatOpts, err := parseOutputFormat(ctx.String("output-format"), ctx.String("field-delimiter"),
+		ctx.String("record-delimiter"), ctx.String("quote-fields"), ctx.String("quote-char"))
+	fatalIf(err, "Invalid output format option(s)")
+	if formatOpts != nil {
+		if ctx.IsSet("csv-output") || ctx.IsSet("json-output") {
+			fatalIf(errInvalidArgument(), "--output-format cannot be used with --csv-output or --json-output")
+		}
+		if _, ok := formatOpts["json"]; ok && ctx.IsSet("csv-output-header") {
+			fatalIf(errInvalidArgument(), "--csv-output-header incompatible with json results")
+		}
+	}
+
+	if ctx.Int("workers") < 1 {
+		fatalIf(errInvalidArgument().Trace(ctx.String("workers")), "--workers must be at least 1.")
+	}
+	if ctx.Bool("aggregate") {
+		_, e := parseSQLAggregates(ctx.String("query"))
+		fatalIf(probe.NewError(e), "Invalid query for --aggregate.")
+	}
+}
+
+// sqlJob is the query of one object.
+type sqlJob struct {
+	url     string
+	query   string
+	selOpts SelectObjectOpts
+}
+
+// sqlRunner runs the queries of mc sql. A single worker streams the
+// results of each object to the output as they arrive, more workers
+// spool the results of an object to a temporary file to write them
+// whole. When aggregating, the single record returned by each object
+// is merged and written once all queries complete.
+type sqlRunner struct {
+	output   io.Writer
+	encKeyDB map[string][]prefixSSEPair
+	agg      *sqlAggregator
+
+	jobs chan sqlJob
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+}
+
+func newSQLRunner(output io.Writer, encKeyDB map[string][]prefixSSEPair, workers int, agg *sqlAggregator) *sqlRunner {
+	r := &sqlRunner{
+		output:   output,
+		encKeyDB: encKeyDB,
+		agg:      agg,
+	}
+	if workers > 1 {
+		r.jobs = make(chan sqlJob)
+		for i := 0; i < workers; i++ {
+			r.wg.Add(1)
+			go func() {
+				defer r.wg.Done()
+				for job := range r.jobs {
+					r.do(job)
+				}
+			}()
+		}
+	}
+	return r
+}
+
+// run queries an object, or hands it to a worker.
+func (r *sqlRunner) run(job sqlJob) {
+	if r.jobs != nil {
+		r.jobs <- job
+		return
+	}
+	r.do(job)
+}
+
+func (r *sqlRunner) do(job sqlJob) {
+	if r.jobs == nil && r.agg == nil {
+		errorIf(sqlSelect(r.output, job.url, job.query, r.encKeyDB, job.selOpts).Trace(job.url), "Unable to run sql")
+		return
+	}
+
+	if r.agg != nil {
+		var buf bytes.Buffer
+		err := sqlSelect(&buf, job.url, job.query, r.encKeyDB, job.selOpts)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if err != nil {
+			errorIf(err.Trace(job.url), "Unable to run sql")
+			return
+		}
+		if e := r.agg.add(buf.Bytes()); e != nil {
+			errorIf(probe.NewError(e).Trace(job.url), "Unable to aggregate the results")
+		}
+		return
+	}
+
+	spool, e := ioutil.TempFile("", "mc-sql-")
+	if e != nil {
+		errorIf(probe.NewError(e).Trace(job.url), "Unable to run sql")
+		return
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+	err := sqlSelect(spool, job.url, job.query, r.encKeyDB, job.selOpts)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Write what was received before reporting a failure, as when streaming.
+	if _, e = spool.Seek(0, io.SeekStart); e == nil {
+		_, e = io.Copy(r.output, spool)
+	}
+	if e != nil {
+		errorIf(probe.NewError(e).Trace(job.url), "Unable to write the results")
+	}
+	errorIf(err.Trace(job.url), "Unable to run sql")
+}
+
+// wait waits for the running queries and writes the merged results
+// when aggregating.
+func (r *sqlRunner) wait() {
+	if r.jobs != nil {
+		close(r.jobs)
+		r.wg.Wait()
+	}
+	if r.agg != nil {
+		fatalIf(probe.NewError(r.agg.write(r.output)), "Unable to write the results.")
+	}
+}
+
+// csvHeader returns the header record of csv results, written with the
+// field and record delimiters of the output serialization options.
+func csvHeader(hdrs []string, outputOpts map[string]map[string]string) string {
+	fieldDelimiter, recordDelimiter := defaultFieldDelimiter, defaultRecordDelimiter
+	if v, ok := outputOpts["csv"][fieldDelimiterType]; ok && v != "" {
+		fieldDelimiter = v
+	}
+	if v, ok := outputOpts["csv"][recordDelimiterType]; ok && v != "" {
+		recordDelimiter = v
+	}
+	return strings.Join(hdrs, fieldDelimiter) + recordDelimiter
 }
 
 // mainSQL is the main entry point for sql command.
@@ -452,19 +679,48 @@ func mainSQL(cliCtx *cli.Context) error {
 
 	// validate sql input arguments.
 	checkSQLSyntax(cliCtx)
+
+	var output io.Writer = os.Stdout
+	if name := cliCtx.String("output"); name != "" {
+		f, e := os.Create(name)
+		fatalIf(probe.NewError(e).Trace(name), "Unable to create the output file.")
+		defer func() {
+			fatalIf(probe.NewError(f.Close()).Trace(name), "Unable to write the output file.")
+		}()
+		output = f
+	}
+	var (
+		agg    *sqlAggregator
+		runner *sqlRunner
+		first  = true
+	)
+	// start validates the arguments with the first object queried and
+	// writes the csv header, which comes before the merged record when
+	// aggregating.
+	start := func(url string) {
+		query, csvHdrs, selOpts = getAndValidateArgs(cliCtx, encKeyDB, url)
+		if cliCtx.Bool("aggregate") {
+			aggregates, _ := parseSQLAggregates(query)
+			agg = newSQLAggregator(aggregates, selOpts.OutputSerOpts)
+		}
+		if len(csvHdrs) > 0 {
+			fmt.Fprint(output, csvHeader(csvHdrs, selOpts.OutputSerOpts))
+		}
+		runner = newSQLRunner(output, encKeyDB, cliCtx.Int("workers"), agg)
+		first = false
+	}
+
 	// extract URLs.
 	URLs := cliCtx.Args()
-	writeHdr := true
 	for _, url := range URLs {
 		if _, targetContent, err := url2Stat(ctx, url, "", false, encKeyDB, time.Time{}); err != nil {
 			errorIf(err.Trace(url), "Unable to run sql for "+url+".")
 			continue
 		} else if !targetContent.Type.IsDir() {
-			if writeHdr {
-				query, csvHdrs, selOpts = getAndValidateArgs(cliCtx, encKeyDB, url)
+			if first {
+				start(url)
 			}
-			errorIf(sqlSelect(url, query, encKeyDB, selOpts, csvHdrs, writeHdr).Trace(url), "Unable to run sql")
-			writeHdr = false
+			runner.run(sqlJob{url: url, query: query, selOpts: selOpts})
 			continue
 		}
 		targetAlias, targetURL, _ := mustExpandAlias(url)
@@ -479,19 +735,21 @@ func mainSQL(cliCtx *cli.Context) error {
 				errorIf(content.Err.Trace(url), "Unable to list on target `"+url+"`.")
 				continue
 			}
-			if writeHdr {
-				query, csvHdrs, selOpts = getAndValidateArgs(cliCtx, encKeyDB, targetAlias+content.URL.Path)
+			if first {
+				start(targetAlias + content.URL.Path)
 			}
 			contentType := mimedb.TypeByExtension(filepath.Ext(content.URL.Path))
 			for _, cTypeSuffix := range supportedContentTypes {
 				if strings.Contains(contentType, cTypeSuffix) {
-					errorIf(sqlSelect(targetAlias+content.URL.Path, query,
-						encKeyDB, selOpts, csvHdrs, writeHdr).Trace(content.URL.String()), "Unable to run sql")
+					runner.run(sqlJob{url: targetAlias + content.URL.Path, query: query, selOpts: selOpts})
+					break
 				}
-				writeHdr = false
 			}
 		}
 	}
+	if runner != nil {
+		runner.wait()
+	}
 
 	// Done.
 	return nil
@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"bytes"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -139,3 +141,122 @@ func TestParseSerializationOpts(t *testing.T) {
 		}
 	}
 }
+
+func TestParseOutputFormat(t *testing.T) {
+	testCases := []struct {
+		format, fieldDelim, recordDelim, quoteFields, quoteChar string
+		expected                                                map[string]map[string]string
+		expectErr                                               bool
+	}{
+		{"", "", "", "", "", nil, false},
+		{"", "\\t", "", "", "", nil, true},
+		{"yaml", "", "", "", "", nil, true},
+		{"json", "", "", "", "", map[string]map[string]string{"json": {}}, false},
+		{"JSON", "", "\\r\\n", "", "", map[string]map[string]string{"json": {recordDelimiterType: "\r\n"}}, false},
+		{"json", ";", "", "", "", nil, true},
+		{"csv", "\\t", "", "always", "'", map[string]map[string]string{
+			"csv": {fieldDelimiterType: "\t", quoteFieldsType: "ALWAYS", quoteCharacterType: "'"},
+		}, false},
+		{"csv", "", "", "sometimes", "", nil, true},
+	}
+	for i, tc := range testCases {
+		opts, err := parseOutputFormat(tc.format, tc.fieldDelim, tc.recordDelim, tc.quoteFields, tc.quoteChar)
+		if (err != nil) != tc.expectErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, tc.expectErr, err)
+		}
+		if !reflect.DeepEqual(opts, tc.expected) {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, tc.expected, opts)
+		}
+	}
+}
+
+func TestCSVHeader(t *testing.T) {
+	hdrs := []string{"id", "name"}
+	testCases := []struct {
+		outputOpts map[string]map[string]string
+		expected   string
+	}{
+		{nil, "id,name\n"},
+		{map[string]map[string]string{"csv": {}}, "id,name\n"},
+		{map[string]map[string]string{"csv": {fieldDelimiterType: "\t"}}, "id\tname\n"},
+		{map[string]map[string]string{"csv": {fieldDelimiterType: ";", recordDelimiterType: "\r\n"}}, "id;name\r\n"},
+	}
+	for i, tc := range testCases {
+		if got := csvHeader(hdrs, tc.outputOpts); got != tc.expected {
+			t.Errorf("Test %d: expected %q, got %q", i+1, tc.expected, got)
+		}
+	}
+}
+
+func TestParseSQLAggregates(t *testing.T) {
+	testCases := []struct {
+		query     string
+		expected  []sqlAggregate
+		expectErr bool
+	}{
+		{"select count(*) from S3Object", []sqlAggregate{sqlCount}, false},
+		{"SELECT COUNT(*) AS n, sum(cast(s.uptime as int)) total, MIN(s.t), max(s.t) FROM S3Object s",
+			[]sqlAggregate{sqlCount, sqlSum, sqlMin, sqlMax}, false},
+		{"select sum(s.a), count(s.\"from\") from S3Object s", []sqlAggregate{sqlSum, sqlCount}, false},
+		{"select avg(s.a) from S3Object s", nil, true},
+		{"select s.a from S3Object s", nil, true},
+		{"select count(*), s.a from S3Object s", nil, true},
+		{"select sum(s.a) + 1 from S3Object s", nil, true},
+		{"select * from S3Object", nil, true},
+		{"count(*)", nil, true},
+	}
+	for i, tc := range testCases {
+		aggregates, err := parseSQLAggregates(tc.query)
+		if (err != nil) != tc.expectErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, tc.expectErr, err)
+		}
+		if !reflect.DeepEqual(aggregates, tc.expected) {
+			t.Fatalf("Test %d: expected %v, got %v", i+1, tc.expected, aggregates)
+		}
+	}
+}
+
+func TestSQLAggregator(t *testing.T) {
+	testCases := []struct {
+		aggregates []sqlAggregate
+		outputOpts map[string]map[string]string
+		results    []string
+		expected   string
+		expectErr  bool
+	}{
+		{[]sqlAggregate{sqlCount}, nil, nil, "", false},
+		{[]sqlAggregate{sqlCount, sqlSum}, nil, []string{"3,10.5\n", "", "2,4\n"}, "5,14.5\n", false},
+		{[]sqlAggregate{sqlCount, sqlMin, sqlMax}, nil, []string{"3,5,9\n", "0,,\n", "2,-1,4\n"}, "5,-1,9\n", false},
+		{[]sqlAggregate{sqlSum, sqlMax}, map[string]map[string]string{"csv": {fieldDelimiterType: "\t", quoteCharacterType: "'"}},
+			[]string{"'1'\t'2'\n", "'3'\t'4'\n"}, "4\t4\n", false},
+		{[]sqlAggregate{sqlCount, sqlMin}, nil, []string{"{\"_1\":3,\"low\":7}\n", "{\"_1\":1,\"low\":2.5}\n"}, "{\"_1\":4,\"low\":2.5}\n", false},
+		{[]sqlAggregate{sqlCount, sqlMax}, nil, []string{"{\"n\":0,\"high\":null}\n"}, "{\"n\":0,\"high\":null}\n", false},
+		{[]sqlAggregate{sqlCount}, map[string]map[string]string{"json": {recordDelimiterType: ";"}},
+			[]string{"{\"n\":1};{\"n\":2};"}, "{\"n\":3};", false},
+		{[]sqlAggregate{sqlCount, sqlSum}, nil, []string{"1,2\n", "1\n"}, "", true},
+		{[]sqlAggregate{sqlCount, sqlSum}, nil, []string{"a,b\n"}, "", true},
+		{[]sqlAggregate{sqlCount}, nil, []string{"{\"name\":\"x\"}\n"}, "", true},
+	}
+	for i, tc := range testCases {
+		agg := newSQLAggregator(tc.aggregates, tc.outputOpts)
+		var err error
+		for _, result := range tc.results {
+			if err = agg.add([]byte(result)); err != nil {
+				break
+			}
+		}
+		if (err != nil) != tc.expectErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, tc.expectErr, err)
+		}
+		if tc.expectErr {
+			continue
+		}
+		var buf bytes.Buffer
+		if err = agg.write(&buf); err != nil {
+			t.Fatalf("Test %d: unexpected error %v", i+1, err)
+		}
+		if buf.String() != tc.expected {
+			t.Fatalf("Test %d: expected %q, got %q", i+1, tc.expected, buf.String())
+		}
+	}
+}
@@ -46,6 +46,15 @@ var (
 			Name:  "recursive, r",
 			Usage: "stat all objects recursively",
 		},
+		cli.StringFlag{
+			Name:  "files-from",
+			Usage: "stat the objects listed one per line in the file as JSON lines, optionally followed by a tab and a version id, '#' starting a comment, '-' reads from stdin",
+		},
+		cli.IntFlag{
+			Name:  "concurrent",
+			Value: 16,
+			Usage: "number of objects of --files-from to stat concurrently",
+		},
 	}
 )
 
@@ -56,7 +65,7 @@ var statCmd = cli.Command{
 	Action:       mainStat,
 	OnUsageError: onUsageError,
 	Before:       setGlobalsFromContext,
-	Flags:        append(append(statFlags, ioFlags...), globalFlags...),
+	Flags:        append(append(append(statFlags, requestHeaderFlags...), ioFlags...), globalFlags...),
 	CustomHelpTemplate: `NAME:
   {{.HelpName}} - {{.Usage}}
 
@@ -91,11 +100,38 @@ EXAMPLES:
 
   7. Stat all objects versions recursively created before 1st January 2020.
      {{.Prompt}} {{.HelpName}} --versions --rewind 2020.01.01T00:00 s3/personal-docs/
+
+  8. Stat the objects listed in a file, relative to a bucket, 64 at a time.
+     {{.Prompt}} {{.HelpName}} --files-from keys.txt --concurrent 64 s3/personal-docs/
+
+  9. Stat the objects found by another command, reading their names from stdin.
+     {{.Prompt}} mc find s3/personal-docs --name "*.pdf" | {{.HelpName}} --files-from -
+
+  10. Stat an object of a service requiring a custom header on every request.
+     {{.Prompt}} {{.HelpName}} --header "X-Tenant-Id: acme" myobjstore/personal-docs/2018-account_report.docx
+
+  11. Stat every version of the objects of a prefix, reading names and version ids separated by a tab from stdin.
+     {{.Prompt}} mc ls --versions --json s3/personal-docs/2018/ | jq -r '[.key, .versionId] | @tsv' | {{.HelpName}} --files-from - s3/personal-docs/2018/
 `,
 }
 
 // parseAndCheckStatSyntax - parse and validate all the passed arguments
 func parseAndCheckStatSyntax(ctx context.Context, cliCtx *cli.Context, encKeyDB map[string][]prefixSSEPair) ([]string, bool, string, time.Time, bool) {
+	if cliCtx.IsSet("files-from") {
+		if len(cliCtx.Args()) > 1 {
+			cli.ShowCommandHelpAndExit(cliCtx, "stat", 1) // last argument is exit code
+		}
+		for _, flag := range []string{"recursive", "versions", "version-id", "rewind"} {
+			if cliCtx.IsSet(flag) {
+				fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "You cannot specify --files-from with --"+flag+".")
+			}
+		}
+		if cliCtx.Int("concurrent") < 1 {
+			fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "--concurrent should be equal or greater than 1.")
+		}
+		return cliCtx.Args(), false, "", time.Time{}, false
+	}
+
 	if !cliCtx.Args().Present() {
 		cli.ShowCommandHelpAndExit(cliCtx, "stat", 1) // last argument is exit code
 	}
@@ -155,8 +191,25 @@ func mainStat(cliCtx *cli.Context) error {
 	encKeyDB, err := getEncKeys(cliCtx)
 	fatalIf(err, "Unable to parse encryption keys.")
 
+	setRequestHeaders(cliCtx)
+
 	// check 'stat' cli arguments.
 	args, isRecursive, versionID, rewind, withVersions := parseAndCheckStatSyntax(ctx, cliCtx, encKeyDB)
+
+	if filesFrom := cliCtx.String("files-from"); filesFrom != "" {
+		list, err := openFilesFrom(filesFrom)
+		fatalIf(err.Trace(filesFrom), "Unable to open the list of objects to stat.")
+		defer list.Close()
+
+		var prefix string
+		if len(args) > 0 {
+			prefix = args[0]
+		}
+		// One JSON document per object, in the order stats complete.
+		globalJSON, globalJSONLine = true, true
+		return statFilesFrom(ctx, list, prefix, cliCtx.Int("concurrent"), encKeyDB)
+	}
+
 	// mimic operating system tool behavior.
 	if len(args) == 0 {
 		args = []string{"."}
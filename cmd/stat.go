@@ -18,13 +18,17 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
@@ -49,12 +53,21 @@ type statMessage struct {
 	Expiration        time.Time         `json:"expiration,omitempty"`
 	ExpirationRuleID  string            `json:"expirationRuleID,omitempty"`
 	ReplicationStatus string            `json:"replicationStatus,omitempty"`
+	StorageClass      string            `json:"storageClass,omitempty"`
+	Checksums         map[string]string `json:"checksums,omitempty"`
+	Restore           *statRestoreInfo  `json:"restore,omitempty"`
 	Metadata          map[string]string `json:"metadata,omitempty"`
 	VersionID         string            `json:"versionID,omitempty"`
 	DeleteMarker      bool              `json:"deleteMarker,omitempty"`
 	singleObject      bool
 }
 
+// statRestoreInfo is the status of the restore of an archived object.
+type statRestoreInfo struct {
+	Ongoing bool      `json:"ongoing"`
+	Expiry  time.Time `json:"expiry,omitempty"`
+}
+
 func (stat statMessage) String() (msg string) {
 	var msgBuilder strings.Builder
 	// Format properly for alignment based on maxKey leng
@@ -80,6 +93,20 @@ func (stat statMessage) String() (msg string) {
 		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s (lifecycle-rule-id: %s) ", "Expiration",
 			stat.Expiration.Local().Format(printDate), stat.ExpirationRuleID) + "\n")
 	}
+	if stat.StorageClass != "" {
+		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s ", "Class", stat.StorageClass) + "\n")
+	}
+	if len(stat.Checksums) > 0 {
+		msgBuilder.WriteString(fmt.Sprintf("%-10s:", "Checksums") + "\n")
+		algos := make([]string, 0, len(stat.Checksums))
+		for algo := range stat.Checksums {
+			algos = append(algos, algo)
+		}
+		sort.Strings(algos)
+		for _, algo := range algos {
+			msgBuilder.WriteString(fmt.Sprintf("  %-6s: %s ", algo, stat.Checksums[algo]) + "\n")
+		}
+	}
 	var maxKeyMetadata = 0
 	var maxKeyEncrypted = 0
 	for k := range stat.Metadata {
@@ -113,7 +140,17 @@ func (stat statMessage) String() (msg string) {
 		}
 	}
 	if stat.ReplicationStatus != "" {
-		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s ", "Replication Status", stat.ReplicationStatus))
+		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s ", "Replication", stat.ReplicationStatus) + "\n")
+	}
+	if stat.Restore != nil {
+		restore := "ongoing"
+		if !stat.Restore.Ongoing {
+			restore = "restored"
+			if !stat.Restore.Expiry.IsZero() {
+				restore += " until " + stat.Restore.Expiry.Local().Format(printDate)
+			}
+		}
+		msgBuilder.WriteString(fmt.Sprintf("%-10s: %s ", "Restore", restore) + "\n")
 	}
 
 	return msgBuilder.String()
@@ -149,9 +186,28 @@ func parseStat(c *ClientContent) statMessage {
 	content.Expiration = c.Expiration
 	content.ExpirationRuleID = c.ExpirationRuleID
 	content.ReplicationStatus = c.ReplicationStatus
+	content.StorageClass = c.StorageClass
+	if content.StorageClass == "" {
+		content.StorageClass = c.Metadata["X-Amz-Storage-Class"]
+	}
+	content.Checksums = c.Checksums
+	if c.Restore != nil {
+		content.Restore = &statRestoreInfo{Ongoing: c.Restore.OngoingRestore, Expiry: c.Restore.ExpiryTime}
+	}
 	return content
 }
 
+// url2StatWithChecksums stats an object like url2Stat, also asking for
+// the checksums stored with it.
+func url2StatWithChecksums(ctx context.Context, urlStr, versionID string, encKeyDB map[string][]prefixSSEPair, timeRef time.Time) (Client, *ClientContent, *probe.Error) {
+	checksums := map[string]string{}
+	clnt, content, err := url2Stat(withChecksums(ctx, checksums), urlStr, versionID, true, encKeyDB, timeRef)
+	if err == nil && len(checksums) > 0 {
+		content.Checksums = checksums
+	}
+	return clnt, content, err
+}
+
 // Return standardized URL to be used to compare later.
 func getStandardizedURL(targetURL string) string {
 	return filepath.FromSlash(targetURL)
@@ -225,7 +281,7 @@ func statURL(ctx context.Context, targetURL, versionID string, timeRef time.Time
 				continue
 			}
 		}
-		clnt, stat, err := url2Stat(ctx, url, content.VersionID, true, encKeyDB, timeRef)
+		clnt, stat, err := url2StatWithChecksums(ctx, url, content.VersionID, encKeyDB, timeRef)
 		if err != nil {
 			continue
 		}
@@ -256,6 +312,57 @@ func statURL(ctx context.Context, targetURL, versionID string, timeRef time.Time
 	return stats, bucketStats, probe.NewError(cErr)
 }
 
+// statFilesFrom stats the objects listed one per line, relative to the
+// prefix when set and optionally followed by a tab and a version id,
+// with bounded concurrency. Objects which cannot be stat'ed are reported
+// without stopping the others.
+func statFilesFrom(ctx context.Context, list io.Reader, prefix string, concurrent int, encKeyDB map[string][]prefixSSEPair) error {
+	objectsCh := make(chan listedObject)
+	var failed int32
+	var wg sync.WaitGroup
+	for i := 0; i < concurrent; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for object := range objectsCh {
+				_, content, err := url2StatWithChecksums(ctx, object.url, object.versionID, encKeyDB, time.Time{})
+				if err != nil {
+					errorIf(err.Trace(object.url, object.versionID), "Unable to stat `"+object.url+"`.")
+					atomic.StoreInt32(&failed, 1)
+					continue
+				}
+				stat := parseStat(content)
+				stat.Key = object.url
+				printMsg(stat)
+			}
+		}()
+	}
+
+	scanner := bufio.NewScanner(list)
+scan:
+	for scanner.Scan() {
+		object, ok := parseListedObject(scanner.Text(), prefix)
+		if !ok {
+			continue
+		}
+		select {
+		case objectsCh <- object:
+		case <-ctx.Done():
+			break scan
+		}
+	}
+	close(objectsCh)
+	wg.Wait()
+
+	if e := scanner.Err(); e != nil {
+		fatalIf(probe.NewError(e), "Unable to read the list of objects to stat.")
+	}
+	if atomic.LoadInt32(&failed) != 0 {
+		return exitStatus(globalErrorExitStatus)
+	}
+	return nil
+}
+
 // BucketInfo holds info about a bucket
 type BucketInfo struct {
 	URL        ClientURL   `json:"-"`
@@ -18,11 +18,22 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	minio "github.com/minio/minio-go/v7"
 )
 
 func TestParseStat(t *testing.T) {
@@ -66,3 +77,113 @@ func TestParseStat(t *testing.T) {
 		})
 	}
 }
+
+func TestParseStatStorageDetails(t *testing.T) {
+	expiry := time.Date(2021, 11, 2, 0, 0, 0, 0, time.UTC)
+	content := ClientContent{
+		URL:               *newClientURL("https://play.min.io/bucket/object"),
+		Type:              0644,
+		Metadata:          map[string]string{"X-Amz-Storage-Class": "GLACIER"},
+		ReplicationStatus: "COMPLETED",
+		Restore:           &minio.RestoreInfo{ExpiryTime: expiry},
+		Checksums:         map[string]string{"SHA256": "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=", "CRC32C": "yZRlqg=="},
+	}
+	stat := parseStat(&content)
+	if stat.StorageClass != "GLACIER" {
+		t.Errorf("Expecting GLACIER storage class, got %q", stat.StorageClass)
+	}
+	if !reflect.DeepEqual(stat.Restore, &statRestoreInfo{Expiry: expiry}) {
+		t.Errorf("Expecting a completed restore, got %+v", stat.Restore)
+	}
+	msg := stat.String()
+	for _, line := range []string{
+		"Class     : GLACIER",
+		"Checksums :\n  CRC32C: yZRlqg== \n  SHA256: n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=",
+		"Replication: COMPLETED \n",
+		"Restore   : restored until ",
+	} {
+		if !strings.Contains(msg, line) {
+			t.Errorf("Expecting %q in %q", line, msg)
+		}
+	}
+}
+
+func TestStatFilesFrom(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	saveMcConfig(newMcConfig())
+	loadMcConfig = loadMcConfigFactory()
+
+	defer func(output io.Writer, json bool, stdin *os.File) {
+		color.Output, globalJSON, os.Stdin = output, json, stdin
+	}(color.Output, globalJSON, os.Stdin)
+	globalJSON = true
+
+	dir := t.TempDir()
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if e := ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); e != nil {
+			t.Fatal(e)
+		}
+	}
+	list := "# objects to verify\n\na.txt\r\nmissing.txt\n\n# the last one\nb.txt\n"
+	stdin := filepath.Join(t.TempDir(), "stdin")
+	if e := ioutil.WriteFile(stdin, []byte(list), 0o644); e != nil {
+		t.Fatal(e)
+	}
+
+	testCases := []struct {
+		name   string
+		reader func() io.Reader
+	}{
+		{"file", func() io.Reader { return strings.NewReader(list) }},
+		{"stdin", func() io.Reader {
+			f, e := os.Open(stdin)
+			if e != nil {
+				t.Fatal(e)
+			}
+			t.Cleanup(func() { f.Close() })
+			os.Stdin = f
+			r, err := openFilesFrom("-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			return r
+		}},
+	}
+	for _, testCase := range testCases {
+		var buf bytes.Buffer
+		color.Output = &buf
+		e := statFilesFrom(context.Background(), testCase.reader(), dir, 2, nil)
+
+		// The missing object fails the run, without stopping it.
+		exitErr, ok := e.(cli.ExitCoder)
+		if !ok || exitErr.ExitCode() != globalErrorExitStatus {
+			t.Fatalf("%s: expected exit status %d, got %v", testCase.name, globalErrorExitStatus, e)
+		}
+		var names, failed []string
+		decoder := json.NewDecoder(&buf)
+		for decoder.More() {
+			var msg struct {
+				statMessage
+				Error struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if e = decoder.Decode(&msg); e != nil {
+				t.Fatalf("%s: %v", testCase.name, e)
+			}
+			if msg.Status == "error" {
+				failed = append(failed, msg.Error.Message)
+				continue
+			}
+			names = append(names, filepath.Base(msg.Key))
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, []string{"a.txt", "b.txt"}) {
+			t.Errorf("%s: expected a.txt and b.txt to be stat'ed, got %v", testCase.name, names)
+		}
+		if len(failed) != 1 || !strings.Contains(failed[0], "missing.txt") {
+			t.Errorf("%s: expected missing.txt to be reported, got %v", testCase.name, failed)
+		}
+	}
+}
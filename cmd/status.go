@@ -130,7 +130,7 @@ func (qs *QuietStatus) Start() {
 
 // Finish displays the accounting summary
 func (qs *QuietStatus) Finish() {
-	printMsg(qs.accounter.Stat())
+	printMsg(summarizeTransfer(qs.accounter.Stat()))
 }
 
 // Update is ignored for quietstatus
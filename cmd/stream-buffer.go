@@ -0,0 +1,96 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/dustin/go-humanize"
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// minStreamBufferSize is the smallest part size accepted by S3.
+const minStreamBufferSize = 5 * humanize.MiByte
+
+// Flags controlling how objects are streamed between two aliases.
+var streamFlags = []cli.Flag{
+	cli.StringFlag{
+		Name:   "buffer-size",
+		Usage:  "same as --part-size",
+		Hidden: true,
+	},
+	cli.BoolFlag{
+		Name:  "via-disk",
+		Usage: "download objects copied between aliases to a local temporary file before uploading them, for slow targets",
+	},
+}
+
+// Download objects copied between aliases to a temporary file
+// before uploading them.
+var globalStreamViaDisk bool
+
+// setStreamOptions sets the global streaming options from the
+// --via-disk flag.
+func setStreamOptions(cliCtx *cli.Context) {
+	globalStreamViaDisk = cliCtx.Bool("via-disk")
+}
+
+// isStreamViaDisk returns true when an object copied from the source
+// to the target is downloaded to a temporary file first.
+func isStreamViaDisk(sourceURL, targetURL ClientURL) bool {
+	return globalStreamViaDisk && sourceURL.Type == objectStorage && targetURL.Type == objectStorage
+}
+
+// tempFileReader is a temporary file removed once closed.
+type tempFileReader struct {
+	*os.File
+}
+
+func (t tempFileReader) Close() error {
+	e := t.File.Close()
+	os.Remove(t.Name())
+	return e
+}
+
+// spillToDisk copies the length bytes of the reader to a temporary
+// file and returns it rewound, ready to be uploaded.
+func spillToDisk(reader io.Reader, length int64) (io.ReadCloser, *probe.Error) {
+	f, e := ioutil.TempFile("", "mc-via-disk-")
+	if e != nil {
+		return nil, probe.NewError(e)
+	}
+	tmp := tempFileReader{f}
+	if length >= 0 {
+		reader = io.LimitReader(reader, length)
+	}
+	n, e := io.Copy(f, reader)
+	if e == nil && length >= 0 && n != length {
+		e = io.ErrUnexpectedEOF
+	}
+	if e == nil {
+		_, e = f.Seek(0, io.SeekStart)
+	}
+	if e != nil {
+		tmp.Close()
+		return nil, probe.NewError(e)
+	}
+	return tmp, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIsStreamViaDisk(t *testing.T) {
+	defer func(viaDisk bool) { globalStreamViaDisk = viaDisk }(globalStreamViaDisk)
+
+	testCases := []struct {
+		viaDisk  bool
+		source   ClientURLType
+		target   ClientURLType
+		expected bool
+	}{
+		{true, objectStorage, objectStorage, true},
+		{true, objectStorage, fileSystem, false},
+		{true, fileSystem, objectStorage, false},
+		{false, objectStorage, objectStorage, false},
+	}
+	for i, testCase := range testCases {
+		globalStreamViaDisk = testCase.viaDisk
+		if viaDisk := isStreamViaDisk(ClientURL{Type: testCase.source}, ClientURL{Type: testCase.target}); viaDisk != testCase.expected {
+			t.Errorf("Test %d: expected %t, got %t", i+1, testCase.expected, viaDisk)
+		}
+	}
+}
+
+func TestSpillToDisk(t *testing.T) {
+	testCases := []struct {
+		length  int64
+		success bool
+	}{
+		{5, true},
+		{-1, true},
+		{6, false},
+	}
+	for i, testCase := range testCases {
+		tmp, err := spillToDisk(strings.NewReader("hello"), testCase.length)
+		if success := err == nil; success != testCase.success {
+			t.Fatalf("Test %d: expected success %t, got %v", i+1, testCase.success, err)
+		}
+		if err != nil {
+			continue
+		}
+		data, e := ioutil.ReadAll(tmp)
+		if e != nil || string(data) != "hello" {
+			t.Errorf("Test %d: expected the spilled data, got %q, %v", i+1, data, e)
+		}
+		name := tmp.(tempFileReader).Name()
+		tmp.Close()
+		if _, e = os.Stat(name); !os.IsNotExist(e) {
+			t.Errorf("Test %d: expected the temporary file to be removed, got %v", i+1, e)
+		}
+	}
+}
@@ -148,7 +148,11 @@ func subnetAuthHeaders(authToken string) map[string]string {
 }
 
 func httpDo(req *http.Request) (*http.Response, error) {
-	client := httpClient(10 * time.Second)
+	return httpDoWithTimeout(req, 10*time.Second)
+}
+
+func httpDoWithTimeout(req *http.Request, timeout time.Duration) (*http.Response, error) {
+	client := httpClient(timeout)
 	if globalSubnetProxyURL != nil {
 		client.Transport.(*http.Transport).Proxy = http.ProxyURL(globalSubnetProxyURL)
 	}
@@ -156,6 +160,10 @@ func httpDo(req *http.Request) (*http.Response, error) {
 }
 
 func subnetReqDo(r *http.Request, headers map[string]string) (string, error) {
+	return subnetReqDoWithTimeout(r, headers, 10*time.Second)
+}
+
+func subnetReqDoWithTimeout(r *http.Request, headers map[string]string, timeout time.Duration) (string, error) {
 	for k, v := range headers {
 		r.Header.Add(k, v)
 	}
@@ -165,7 +173,7 @@ func subnetReqDo(r *http.Request, headers map[string]string) (string, error) {
 		r.Header.Add("Content-Type", "application/json")
 	}
 
-	resp, e := httpDo(r)
+	resp, e := httpDoWithTimeout(r, timeout)
 	if e != nil {
 		return "", e
 	}
@@ -180,7 +188,17 @@ func subnetReqDo(r *http.Request, headers map[string]string) (string, error) {
 	if resp.StatusCode == http.StatusOK {
 		return respStr, nil
 	}
-	return respStr, fmt.Errorf("Request failed with code %d and error: %s", resp.StatusCode, respStr)
+	return respStr, subnetRespError{StatusCode: resp.StatusCode, Body: respStr}
+}
+
+// subnetRespError is the failure status of a SUBNET request.
+type subnetRespError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e subnetRespError) Error() string {
+	return fmt.Sprintf("Request failed with code %d and error: %s", e.StatusCode, e.Body)
 }
 
 func subnetGetReq(reqURL string, headers map[string]string) (string, error) {
@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 )
@@ -32,3 +34,33 @@ func TestSubnetBaseURL(t *testing.T) {
 		t.Fatalf("Expected TestSubnetBaseURL() to return an https url, received %s", u.Scheme)
 	}
 }
+
+func TestSubnetUploadWithRetry(t *testing.T) {
+	testCases := []struct {
+		statuses      []int
+		expectedCalls int
+		expectErr     bool
+	}{
+		{[]int{http.StatusOK}, 1, false},
+		{[]int{http.StatusServiceUnavailable, http.StatusOK}, 2, false},
+		{[]int{http.StatusForbidden, http.StatusOK}, 1, true},
+		{[]int{http.StatusUnauthorized, http.StatusOK}, 1, true},
+	}
+	for i, tc := range testCases {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.statuses[calls])
+			calls++
+		}))
+		_, err := subnetUploadWithRetry(func() (*http.Request, error) {
+			return http.NewRequest(http.MethodPost, server.URL, nil)
+		}, nil)
+		server.Close()
+		if (err != nil) != tc.expectErr {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, tc.expectErr, err)
+		}
+		if calls != tc.expectedCalls {
+			t.Fatalf("Test %d: expected %d requests, got %d", i+1, tc.expectedCalls, calls)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+// objectTag is a single key=value pair of an --include-tag or
+// --exclude-tag filter, an empty value matches any value of the key.
+type objectTag struct {
+	key, value string
+}
+
+// objectTagFilter selects objects based on their tags.
+type objectTagFilter struct {
+	include []objectTag
+	exclude []objectTag
+
+	// Tags fetched during this run, shared by copies of the filter.
+	cache *objectTagCache
+}
+
+// Number of objects whose tags are cached at most.
+const objectTagCacheSize = 10000
+
+// objectTagCache remembers the tags of the objects seen last by URL and
+// version, so objects seen more than once in a run are only fetched
+// once. The least recently used entries are evicted beyond size objects.
+type objectTagCache struct {
+	sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type objectTagCacheEntry struct {
+	key  string
+	tags map[string]string
+}
+
+func newObjectTagCache(size int) *objectTagCache {
+	return &objectTagCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (c *objectTagCache) get(key string) (map[string]string, bool) {
+	c.Lock()
+	defer c.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*objectTagCacheEntry).tags, true
+}
+
+func (c *objectTagCache) set(key string, tags map[string]string) {
+	c.Lock()
+	defer c.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*objectTagCacheEntry).tags = tags
+		c.lru.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&objectTagCacheEntry{key: key, tags: tags})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*objectTagCacheEntry).key)
+	}
+}
+
+// parseObjectTags parses filter values of the form "k1=v1" or
+// "k1=v1&k2=v2", a key without a value matches any value.
+func parseObjectTags(values []string) ([]objectTag, *probe.Error) {
+	var tags []objectTag
+	for _, value := range values {
+		for _, kv := range strings.Split(value, "&") {
+			if kv == "" {
+				continue
+			}
+			tokens := strings.SplitN(kv, "=", 2)
+			key := strings.TrimSpace(tokens[0])
+			if key == "" {
+				return nil, errInvalidArgument().Trace(value)
+			}
+			tag := objectTag{key: key}
+			if len(tokens) == 2 {
+				tag.value = tokens[1]
+			}
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
+// newObjectTagFilter returns a filter out of --include-tag and --exclude-tag values.
+func newObjectTagFilter(include, exclude []string) (objectTagFilter, *probe.Error) {
+	var f objectTagFilter
+	var err *probe.Error
+	if f.include, err = parseObjectTags(include); err != nil {
+		return f, err
+	}
+	if f.exclude, err = parseObjectTags(exclude); err != nil {
+		return f, err
+	}
+	if !f.isEmpty() {
+		f.cache = newObjectTagCache(objectTagCacheSize)
+	}
+	return f, nil
+}
+
+// isEmpty returns true if no tag filter is specified.
+func (f objectTagFilter) isEmpty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+func hasObjectTag(tags map[string]string, filter []objectTag) bool {
+	for _, tag := range filter {
+		value, ok := tags[tag.key]
+		if ok && (tag.value == "" || tag.value == value) {
+			return true
+		}
+	}
+	return false
+}
+
+// match returns true if an object with the given tags carries any of
+// the included tags, when specified, and none of the excluded tags.
+func (f objectTagFilter) match(tags map[string]string) bool {
+	if len(f.include) > 0 && !hasObjectTag(tags, f.include) {
+		return false
+	}
+	return !hasObjectTag(tags, f.exclude)
+}
+
+// matchContent fetches the tags of the object, once per run, and matches
+// them against the filter. Objects on targets without tagging support
+// carry no tags.
+func (f objectTagFilter) matchContent(ctx context.Context, alias string, content *ClientContent) (bool, *probe.Error) {
+	if f.isEmpty() {
+		return true, nil
+	}
+	objectURL := content.URL.String()
+	if alias != "" {
+		objectURL = urlJoinPath(alias, content.URL.Path)
+	}
+	cacheKey := objectURL + "\x00" + content.VersionID
+	if f.cache != nil {
+		if tags, ok := f.cache.get(cacheKey); ok {
+			return f.match(tags), nil
+		}
+	}
+	clnt, err := newClient(objectURL)
+	if err != nil {
+		return false, err.Trace(objectURL)
+	}
+	tags, err := clnt.GetTags(ctx, content.VersionID)
+	if err != nil {
+		if _, ok := err.ToGoError().(APINotImplemented); !ok {
+			return false, err.Trace(objectURL)
+		}
+		tags = nil
+	}
+	if f.cache != nil {
+		f.cache.set(cacheKey, tags)
+	}
+	return f.match(tags), nil
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+)
+
+func TestObjectTagFilter(t *testing.T) {
+	testCases := []struct {
+		include []string
+		exclude []string
+		tags    map[string]string
+		match   bool
+		err     bool
+	}{
+		{nil, nil, nil, true, false},
+		{nil, []string{"retention=legal"}, map[string]string{"retention": "legal"}, false, false},
+		{nil, []string{"retention=legal"}, map[string]string{"retention": "none"}, true, false},
+		{nil, []string{"retention"}, map[string]string{"retention": "none"}, false, false},
+		{[]string{"stage=tmp"}, nil, nil, false, false},
+		{[]string{"stage=tmp"}, nil, map[string]string{"stage": "tmp"}, true, false},
+		{[]string{"stage=tmp&stage=scratch"}, nil, map[string]string{"stage": "scratch"}, true, false},
+		{[]string{"stage=tmp"}, []string{"retention=legal"}, map[string]string{"stage": "tmp", "retention": "legal"}, false, false},
+		{[]string{"=tmp"}, nil, nil, false, true},
+	}
+
+	for i, testCase := range testCases {
+		filter, err := newObjectTagFilter(testCase.include, testCase.exclude)
+		if testCase.err != (err != nil) {
+			t.Fatalf("Test %d: expected error %v, got %v", i+1, testCase.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if match := filter.match(testCase.tags); match != testCase.match {
+			t.Fatalf("Test %d: expected match %v, got %v", i+1, testCase.match, match)
+		}
+	}
+}
+
+func TestObjectTagFilterCache(t *testing.T) {
+	filter, err := newObjectTagFilter(nil, []string{"retention=legal"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := &ClientContent{URL: *newClientURL("/nonexistent/object")}
+
+	// Tags already fetched in the run are used without a request.
+	filter.cache.set(content.URL.String()+"\x00", map[string]string{"retention": "legal"})
+	matched, err := filter.matchContent(context.Background(), "", content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matched {
+		t.Fatal("expected the cached tags to exclude the object")
+	}
+}
+
+func TestObjectTagCacheEviction(t *testing.T) {
+	c := newObjectTagCache(2)
+	c.set("a", map[string]string{"k": "a"})
+	c.set("b", map[string]string{"k": "b"})
+	// a is used more recently than b, which is evicted first.
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	c.set("c", map[string]string{"k": "c"})
+	if _, ok := c.get("b"); ok {
+		t.Error("expected the least recently used b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if tags, ok := c.get(key); !ok || tags["k"] != key {
+			t.Errorf("expected %s to be cached, got %v", key, tags)
+		}
+	}
+	if len(c.entries) != 2 || c.lru.Len() != 2 {
+		t.Errorf("expected 2 cached objects, got %d", len(c.entries))
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// Interval between two progress events of an object.
+const transferEventInterval = time.Second
+
+// Counters of the JSON event stream of cp and mirror, nil when the
+// events are not printed.
+var globalTransferEvents *transferEvents
+
+type transferEvents struct {
+	start   time.Time
+	objects int64
+	failed  int64
+	retries int64
+}
+
+// setTransferEvents enables the per object events with --json.
+func setTransferEvents() {
+	if globalJSON {
+		globalTransferEvents = &transferEvents{start: time.Now()}
+	}
+}
+
+// transferEventName returns the event name of a copy or mirror message.
+func transferEventName(event string) string {
+	if globalTransferEvents == nil {
+		return ""
+	}
+	return event
+}
+
+// transferEvent is a progress, complete or error event of the transfer
+// of an object. The start event is the copy or mirror message.
+type transferEvent struct {
+	Status      string  `json:"status"`
+	Event       string  `json:"event"`
+	Source      string  `json:"source"`
+	Target      string  `json:"target"`
+	Size        int64   `json:"size"`
+	Transferred int64   `json:"transferred"`
+	Throughput  float64 `json:"throughput"`
+	Retries     int64   `json:"retries"`
+	Elapsed     float64 `json:"elapsed"`
+	Error       string  `json:"error,omitempty"`
+}
+
+func (e transferEvent) String() string {
+	return fmt.Sprintf("%s `%s` -> `%s`: %d of %d bytes", e.Event, e.Source, e.Target, e.Transferred, e.Size)
+}
+
+func (e transferEvent) JSON() string {
+	e.Status = "success"
+	if e.Error != "" {
+		e.Status = "error"
+	}
+	eventBytes, err := json.MarshalIndent(e, "", " ")
+	fatalIf(probe.NewError(err), "Unable to marshal into JSON.")
+	return string(eventBytes)
+}
+
+// transferSummary is the last event of cp and mirror.
+type transferSummary struct {
+	accountStat
+	Event    string  `json:"event"`
+	Objects  int64   `json:"objects"`
+	Failed   int64   `json:"failed"`
+	Retries  int64   `json:"retries"`
+	Duration float64 `json:"duration"`
+}
+
+func (s transferSummary) JSON() string {
+	s.Status = "success"
+	summaryBytes, e := json.MarshalIndent(s, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(summaryBytes)
+}
+
+// summarizeTransfer returns the final message of a transfer, with the
+// object counters when the events are printed.
+func summarizeTransfer(stat accountStat) message {
+	events := globalTransferEvents
+	if events == nil {
+		return stat
+	}
+	return transferSummary{
+		accountStat: stat,
+		Event:       "summary",
+		Objects:     atomic.LoadInt64(&events.objects),
+		Failed:      atomic.LoadInt64(&events.failed),
+		Retries:     atomic.LoadInt64(&events.retries),
+		Duration:    time.Since(events.start).Seconds(),
+	}
+}
+
+// objectTransfer counts the bytes transferred for an object and prints
+// its progress events.
+type objectTransfer struct {
+	progress    io.Reader
+	source      string
+	target      string
+	size        int64
+	start       time.Time
+	transferred int64
+	retries     int64
+
+	mu        sync.Mutex
+	lastEvent time.Time
+}
+
+// newObjectTransfer returns the progress reader of an object printing
+// its events, or progress when the events are not printed.
+func newObjectTransfer(urls URLs, progress io.Reader) io.Reader {
+	if globalTransferEvents == nil || progress == nil {
+		return progress
+	}
+	now := time.Now()
+	return &objectTransfer{
+		progress:  progress,
+		source:    filepath.ToSlash(filepath.Join(urls.SourceAlias, urls.SourceContent.URL.Path)),
+		target:    filepath.ToSlash(filepath.Join(urls.TargetAlias, urls.TargetContent.URL.Path)),
+		size:      urls.SourceContent.Size,
+		start:     now,
+		lastEvent: now,
+	}
+}
+
+func (t *objectTransfer) Read(p []byte) (int, error) {
+	n, e := t.progress.Read(p)
+	atomic.AddInt64(&t.transferred, int64(n))
+
+	t.mu.Lock()
+	now := time.Now()
+	due := now.Sub(t.lastEvent) >= transferEventInterval
+	if due {
+		t.lastEvent = now
+	}
+	t.mu.Unlock()
+	if due {
+		printMsg(t.event("progress", nil))
+	}
+	return n, e
+}
+
+// undo takes back n bytes of a failed attempt.
+func (t *objectTransfer) undo(n int64) {
+	atomic.AddInt64(&t.transferred, -n)
+	undoProgress(t.progress, n)
+}
+
+// retried counts a retry of the transfer.
+func (t *objectTransfer) retried() {
+	atomic.AddInt64(&t.retries, 1)
+	atomic.AddInt64(&globalTransferEvents.retries, 1)
+}
+
+func (t *objectTransfer) event(event string, err *probe.Error) transferEvent {
+	elapsed := time.Since(t.start).Seconds()
+	e := transferEvent{
+		Event:       event,
+		Source:      t.source,
+		Target:      t.target,
+		Size:        t.size,
+		Transferred: atomic.LoadInt64(&t.transferred),
+		Retries:     atomic.LoadInt64(&t.retries),
+		Elapsed:     elapsed,
+	}
+	if elapsed > 0 {
+		e.Throughput = float64(e.Transferred) / elapsed
+	}
+	if err != nil {
+		e.Error = err.ToGoError().Error()
+	}
+	return e
+}
+
+// transferRetried counts a retry of an object, if progress prints events.
+func transferRetried(progress io.Reader) {
+	if t, ok := progress.(*objectTransfer); ok {
+		t.retried()
+	}
+}
+
+// transferDone prints the complete or error event of an object, if
+// progress prints events.
+func transferDone(progress io.Reader, err *probe.Error) {
+	t, ok := progress.(*objectTransfer)
+	if !ok {
+		return
+	}
+	if err != nil {
+		atomic.AddInt64(&globalTransferEvents.failed, 1)
+		printMsg(t.event("error", err))
+		return
+	}
+	atomic.AddInt64(&globalTransferEvents.objects, 1)
+	printMsg(t.event("complete", nil))
+}
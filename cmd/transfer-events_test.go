@@ -0,0 +1,82 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"io"
+	"testing"
+
+	"github.com/minio/mc/pkg/probe"
+)
+
+func TestObjectTransferEvents(t *testing.T) {
+	defer func(events *transferEvents) { globalTransferEvents = events }(globalTransferEvents)
+	globalTransferEvents = nil
+
+	urls := URLs{
+		SourceContent: &ClientContent{URL: *newClientURL("/tmp/a.txt"), Size: 100},
+		TargetContent: &ClientContent{URL: *newClientURL("/bucket/a.txt")},
+		TargetAlias:   "play",
+	}
+	pg := newAccounter(0)
+	if progress := newObjectTransfer(urls, pg); progress != io.Reader(pg) {
+		t.Fatalf("Expected the progress reader to be unchanged without events")
+	}
+
+	globalTransferEvents = &transferEvents{}
+	progress := newObjectTransfer(urls, pg)
+	transfer, ok := progress.(*objectTransfer)
+	if !ok {
+		t.Fatalf("Expected an object transfer, got %T", progress)
+	}
+	progress.Read(make([]byte, 60))
+	undoProgress(progress, 60)
+	transferRetried(progress)
+	progress.Read(make([]byte, 100))
+
+	event := transfer.event("complete", nil)
+	if event.Source != "/tmp/a.txt" || event.Target != "play/bucket/a.txt" {
+		t.Errorf("Unexpected source and target %q and %q", event.Source, event.Target)
+	}
+	if event.Transferred != 100 || event.Retries != 1 || pg.Get() != 100 {
+		t.Errorf("Expected 100 bytes transferred after 1 retry, got %d bytes (%d in total) and %d retries",
+			event.Transferred, pg.Get(), event.Retries)
+	}
+
+	if event = transfer.event("error", probe.NewError(io.ErrUnexpectedEOF)); event.Error == "" {
+		t.Errorf("Expected the error event to have an error")
+	}
+}
+
+func TestSummarizeTransfer(t *testing.T) {
+	defer func(events *transferEvents) { globalTransferEvents = events }(globalTransferEvents)
+
+	globalTransferEvents = nil
+	if _, ok := summarizeTransfer(accountStat{Transferred: 10}).(accountStat); !ok {
+		t.Errorf("Expected the account stat without events")
+	}
+
+	globalTransferEvents = &transferEvents{objects: 3, failed: 1, retries: 2}
+	summary, ok := summarizeTransfer(accountStat{Transferred: 10}).(transferSummary)
+	if !ok {
+		t.Fatalf("Expected a transfer summary with events")
+	}
+	if summary.Event != "summary" || summary.Objects != 3 || summary.Failed != 1 || summary.Retries != 2 || summary.Transferred != 10 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+}
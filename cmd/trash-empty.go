@@ -0,0 +1,121 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"path"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	"github.com/minio/pkg/console"
+)
+
+var trashEmptyFlags = []cli.Flag{
+	trashPrefixFlag,
+	cli.StringFlag{
+		Name:  "older-than",
+		Usage: "remove only objects moved to the trash more than L days, M hours and N minutes ago",
+	},
+	cli.BoolFlag{
+		Name:  "force",
+		Usage: "allow permanently removing objects from the trash",
+	},
+}
+
+var trashEmptyCmd = cli.Command{
+	Name:         "empty",
+	Usage:        "permanently remove objects from the trash",
+	Action:       mainTrashEmpty,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(trashEmptyFlags, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Permanently remove all objects in the trash of bucket 'photos'.
+     {{.Prompt}} {{.HelpName}} --force myminio/photos
+
+  2. Permanently remove objects moved to the trash of bucket 'photos' more than 7 days ago.
+     {{.Prompt}} {{.HelpName}} --force --older-than 7d myminio/photos
+
+  3. Let the server expire objects in the trash of bucket 'photos' after 7 days instead.
+     {{.Prompt}} mc ilm add --prefix "` + defaultTrashPrefix + `" --expiry-days 7 myminio/photos
+`,
+}
+
+func mainTrashEmpty(cliCtx *cli.Context) error {
+	ctx, cancelTrashEmpty := context.WithCancel(globalContext)
+	defer cancelTrashEmpty()
+
+	console.SetColor("Remove", color.New(color.FgGreen, color.Bold))
+
+	alias := checkTrashSyntax(cliCtx, "empty")
+	if !cliCtx.Bool("force") {
+		fatalIf(errDummy().Trace(cliCtx.Args()...),
+			"Emptying the trash requires --force flag. This operation is *IRREVERSIBLE*. Please review carefully before performing this *DANGEROUS* operation.")
+	}
+	trashPrefix := normalizeTrashPrefix(cliCtx.String("trash-prefix"))
+	olderThan := cliCtx.String("older-than")
+
+	_, urlPath := url2Alias(cliCtx.Args().Get(0))
+	bucket, _ := splitBucketObject("/" + urlPath)
+	clnt, err := newClient(alias + "/" + bucket)
+	fatalIf(err.Trace(cliCtx.Args()...), "Unable to initialize connection.")
+
+	contentCh := make(chan *ClientContent)
+	resultCh := clnt.Remove(ctx, false, false, false, contentCh)
+	go func() {
+		defer close(contentCh)
+		for content := range listTrash(ctx, cliCtx.Args().Get(0), trashPrefix) {
+			if content.Err != nil {
+				errorIf(content.Err.Trace(cliCtx.Args()...), "Unable to list the trash.")
+				continue
+			}
+			if olderThan != "" && isOlder(content.Time, olderThan) {
+				continue
+			}
+			select {
+			case contentCh <- content:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var cErr error
+	for result := range resultCh {
+		if result.Err != nil {
+			errorIf(result.Err.Trace(cliCtx.Args()...), "Unable to remove from the trash.")
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		printMsg(rmMessage{
+			Key:       path.Join(alias, result.BucketName, result.ObjectName),
+			VersionID: result.ObjectVersionID,
+		})
+	}
+	return cErr
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var trashListCmd = cli.Command{
+	Name:         "ls",
+	Usage:        "list objects in the trash",
+	Action:       mainTrashList,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append([]cli.Flag{trashPrefixFlag}, globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. List all objects removed with 'rm --trash' from bucket 'photos'.
+     {{.Prompt}} {{.HelpName}} myminio/photos
+
+  2. List removed objects of bucket 'photos' under the prefix '2021/'.
+     {{.Prompt}} {{.HelpName}} myminio/photos/2021/
+`,
+}
+
+// trashListMessage container for an object in the trash.
+type trashListMessage struct {
+	Status    string    `json:"status"`
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	TrashedAt time.Time `json:"trashedAt"`
+}
+
+func (t trashListMessage) String() string {
+	return fmt.Sprintf("%s %9s %s", console.Colorize("Time", "["+t.TrashedAt.Local().Format(printDate)+"]"),
+		console.Colorize("Size", strings.Join(strings.Fields(humanize.IBytes(uint64(t.Size))), "")),
+		console.Colorize("File", t.Key))
+}
+
+func (t trashListMessage) JSON() string {
+	t.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(t, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+// checkTrashSyntax - validate the arguments of all trash subcommands.
+func checkTrashSyntax(cliCtx *cli.Context, name string) (alias string) {
+	if len(cliCtx.Args()) != 1 {
+		cli.ShowCommandHelpAndExit(cliCtx, name, 1) // last argument is exit code
+	}
+	alias, path := url2Alias(cliCtx.Args().Get(0))
+	if _, _, hostCfg := mustExpandAlias(cliCtx.Args().Get(0)); hostCfg == nil {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "The trash is only available on object storage.")
+	}
+	if bucket, _ := splitBucketObject("/" + path); bucket == "" {
+		fatalIf(errInvalidArgument().Trace(cliCtx.Args()...), "Please specify a bucket.")
+	}
+	return alias
+}
+
+// listTrash lists the objects in the trash whose original path is
+// under urlStr.
+func listTrash(ctx context.Context, urlStr, trashPrefix string) <-chan *ClientContent {
+	alias, path := url2Alias(urlStr)
+	trashURL := alias + trashPath("/"+path, trashPrefix)
+	clnt, err := newClient(trashURL)
+	if err != nil {
+		contentCh := make(chan *ClientContent, 1)
+		contentCh <- &ClientContent{Err: err.Trace(trashURL)}
+		close(contentCh)
+		return contentCh
+	}
+	return clnt.List(ctx, ListOptions{Recursive: true, ShowDir: DirNone})
+}
+
+func mainTrashList(cliCtx *cli.Context) error {
+	ctx, cancelTrashList := context.WithCancel(globalContext)
+	defer cancelTrashList()
+
+	console.SetColor("Time", color.New(color.FgGreen))
+	console.SetColor("Size", color.New(color.FgYellow))
+	console.SetColor("File", color.New(color.Bold))
+
+	alias := checkTrashSyntax(cliCtx, "ls")
+	trashPrefix := normalizeTrashPrefix(cliCtx.String("trash-prefix"))
+
+	var cErr error
+	for content := range listTrash(ctx, cliCtx.Args().Get(0), trashPrefix) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(cliCtx.Args()...), "Unable to list the trash.")
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		printMsg(trashListMessage{
+			Key:       alias + untrashPath(content.URL.Path, trashPrefix),
+			Size:      content.Size,
+			TrashedAt: content.Time,
+		})
+	}
+	return cErr
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/minio/cli"
+	"github.com/minio/mc/pkg/probe"
+)
+
+// defaultTrashPrefix is the prefix, within each bucket, removed
+// objects are moved to by 'mc rm --trash'.
+const defaultTrashPrefix = ".mc-trash/"
+
+var trashPrefixFlag = cli.StringFlag{
+	Name:  "trash-prefix",
+	Usage: "prefix within the bucket holding removed objects",
+	Value: defaultTrashPrefix,
+}
+
+var trashSubcommands = []cli.Command{
+	trashListCmd,
+	trashRestoreCmd,
+	trashEmptyCmd,
+}
+
+var trashCmd = cli.Command{
+	Name:            "trash",
+	Usage:           "manage objects removed with 'rm --trash'",
+	Action:          mainTrash,
+	Before:          setGlobalsFromContext,
+	Flags:           globalFlags,
+	HideHelpCommand: true,
+	Subcommands:     trashSubcommands,
+}
+
+func mainTrash(ctx *cli.Context) error {
+	commandNotFound(ctx, trashSubcommands)
+	return nil
+}
+
+// normalizeTrashPrefix makes sure the trash prefix is a folder.
+func normalizeTrashPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		prefix = strings.Trim(defaultTrashPrefix, "/")
+	}
+	return prefix + "/"
+}
+
+// splitBucketObject splits a "/bucket/object" path.
+func splitBucketObject(path string) (bucket, object string) {
+	tokens := splitStr(path, "/", 3)
+	return tokens[1], tokens[2]
+}
+
+// isTrashed returns true if the object path lies within the trash.
+func isTrashed(path, trashPrefix string) bool {
+	_, object := splitBucketObject(path)
+	return strings.HasPrefix(object, trashPrefix)
+}
+
+// trashPath returns the path of an object moved to the trash.
+func trashPath(path, trashPrefix string) string {
+	bucket, object := splitBucketObject(path)
+	return "/" + bucket + "/" + trashPrefix + object
+}
+
+// untrashPath returns the original path of an object in the trash.
+func untrashPath(path, trashPrefix string) string {
+	bucket, object := splitBucketObject(path)
+	return "/" + bucket + "/" + strings.TrimPrefix(object, trashPrefix)
+}
+
+// serverSideCopy copies the object to the target path of the same alias.
+func serverSideCopy(ctx context.Context, alias string, content *ClientContent, targetPath string, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	sourceURL := alias + content.URL.Path
+	targetURL := alias + targetPath
+	clnt, err := newClient(targetURL)
+	if err != nil {
+		return err.Trace(targetURL)
+	}
+	err = clnt.Copy(ctx, content.URL.Path, CopyOptions{
+		versionID: content.VersionID,
+		size:      content.Size,
+		srcSSE:    getSSE(sourceURL, encKeyDB[alias]),
+		tgtSSE:    getSSE(targetURL, encKeyDB[alias]),
+		metadata:  map[string]string{},
+	}, nil)
+	return err.Trace(sourceURL, targetURL)
+}
+
+// moveObject copies the object to the target path on the server side
+// and removes the source once copied.
+func moveObject(ctx context.Context, alias string, content *ClientContent, targetPath string, encKeyDB map[string][]prefixSSEPair) *probe.Error {
+	if err := serverSideCopy(ctx, alias, content, targetPath, encKeyDB); err != nil {
+		return err
+	}
+
+	sourceURL := alias + content.URL.Path
+	clnt, err := newClient(sourceURL)
+	if err != nil {
+		return err.Trace(sourceURL)
+	}
+	contentCh := make(chan *ClientContent, 1)
+	contentCh <- &ClientContent{URL: content.URL, VersionID: content.VersionID}
+	close(contentCh)
+	for result := range clnt.Remove(ctx, false, false, false, contentCh) {
+		if result.Err != nil {
+			return result.Err.Trace(sourceURL)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestTrashPath(t *testing.T) {
+	testCases := []struct {
+		path      string
+		prefix    string
+		trashPath string
+	}{
+		{"/photos/summer.jpg", ".mc-trash/", "/photos/.mc-trash/summer.jpg"},
+		{"/photos/2021/summer.jpg", ".mc-trash/", "/photos/.mc-trash/2021/summer.jpg"},
+		{"/photos/2021/summer.jpg", normalizeTrashPrefix("/deleted"), "/photos/deleted/2021/summer.jpg"},
+		{"/photos/summer.jpg", normalizeTrashPrefix(""), "/photos/.mc-trash/summer.jpg"},
+	}
+
+	for i, testCase := range testCases {
+		trashed := trashPath(testCase.path, testCase.prefix)
+		if trashed != testCase.trashPath {
+			t.Fatalf("Test %d: expected %s, got %s", i+1, testCase.trashPath, trashed)
+		}
+		if !isTrashed(trashed, testCase.prefix) {
+			t.Fatalf("Test %d: expected %s to be in the trash", i+1, trashed)
+		}
+		if isTrashed(testCase.path, testCase.prefix) {
+			t.Fatalf("Test %d: expected %s not to be in the trash", i+1, testCase.path)
+		}
+		if restored := untrashPath(trashed, testCase.prefix); restored != testCase.path {
+			t.Fatalf("Test %d: expected %s, got %s", i+1, testCase.path, restored)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/minio/cli"
+	json "github.com/minio/colorjson"
+	"github.com/minio/mc/pkg/probe"
+	"github.com/minio/pkg/console"
+)
+
+var trashRestoreFlags = []cli.Flag{
+	trashPrefixFlag,
+	cli.BoolFlag{
+		Name:  "overwrite",
+		Usage: "overwrite objects created at the original location since their removal",
+	},
+}
+
+var trashRestoreCmd = cli.Command{
+	Name:         "restore",
+	Usage:        "restore objects from the trash to their original location",
+	Action:       mainTrashRestore,
+	OnUsageError: onUsageError,
+	Before:       setGlobalsFromContext,
+	Flags:        append(append(trashRestoreFlags, ioFlags...), globalFlags...),
+	CustomHelpTemplate: `NAME:
+  {{.HelpName}} - {{.Usage}}
+
+USAGE:
+  {{.HelpName}} [FLAGS] TARGET
+
+FLAGS:
+  {{range .VisibleFlags}}{{.}}
+  {{end}}
+EXAMPLES:
+  1. Restore the object 'summer.jpg' removed from bucket 'photos'.
+     {{.Prompt}} {{.HelpName}} myminio/photos/summer.jpg
+
+  2. Restore all objects removed from bucket 'photos' under the prefix '2021/'.
+     {{.Prompt}} {{.HelpName}} myminio/photos/2021/
+
+  3. Restore all objects removed from bucket 'photos', overwriting the ones uploaded again since.
+     {{.Prompt}} {{.HelpName}} --overwrite myminio/photos
+`,
+}
+
+// trashRestoreMessage container for a restored object.
+type trashRestoreMessage struct {
+	Status string `json:"status"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+func (t trashRestoreMessage) String() string {
+	return console.Colorize("Restore", fmt.Sprintf("Restored `%s`", t.Target))
+}
+
+func (t trashRestoreMessage) JSON() string {
+	t.Status = "success"
+	jsonMessageBytes, e := json.MarshalIndent(t, "", " ")
+	fatalIf(probe.NewError(e), "Unable to marshal into JSON.")
+	return string(jsonMessageBytes)
+}
+
+func mainTrashRestore(cliCtx *cli.Context) error {
+	ctx, cancelTrashRestore := context.WithCancel(globalContext)
+	defer cancelTrashRestore()
+
+	console.SetColor("Restore", color.New(color.FgGreen, color.Bold))
+
+	alias := checkTrashSyntax(cliCtx, "restore")
+	trashPrefix := normalizeTrashPrefix(cliCtx.String("trash-prefix"))
+	overwrite := cliCtx.Bool("overwrite")
+
+	encKeyDB, err := getEncKeys(cliCtx)
+	fatalIf(err, "Unable to parse encryption keys.")
+
+	var cErr error
+	found := false
+	for content := range listTrash(ctx, cliCtx.Args().Get(0), trashPrefix) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(cliCtx.Args()...), "Unable to list the trash.")
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		found = true
+
+		sourceURL := alias + content.URL.Path
+		targetPath := untrashPath(content.URL.Path, trashPrefix)
+		targetURL := alias + targetPath
+		if !overwrite {
+			if _, _, err := url2Stat(ctx, targetURL, "", false, encKeyDB, time.Time{}); err == nil {
+				errorIf(errDummy().Trace(targetURL), "Object `"+targetURL+"` already exists, use --overwrite to replace it.")
+				cErr = exitStatus(globalErrorExitStatus)
+				continue
+			}
+		}
+		if err := moveObject(ctx, alias, content, targetPath, encKeyDB); err != nil {
+			errorIf(err, "Unable to restore `"+targetURL+"`.")
+			cErr = exitStatus(globalErrorExitStatus)
+			continue
+		}
+		printMsg(trashRestoreMessage{Source: sourceURL, Target: targetURL})
+	}
+	if !found && cErr == nil {
+		errorIf(errDummy().Trace(cliCtx.Args()...), "No object found in the trash for `"+cliCtx.Args().Get(0)+"`.")
+		return exitStatus(globalErrorExitStatus)
+	}
+	return cErr
+}
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	humanize "github.com/dustin/go-humanize"
 	"github.com/fatih/color"
 	"github.com/minio/cli"
 	"github.com/minio/mc/pkg/probe"
@@ -43,6 +44,8 @@ type treeMessage struct {
 	Entry        string
 	IsDir        bool
 	BranchString string
+	// Usage of the entry, with --du.
+	Usage *duClassUsage
 }
 
 // Colorized message for console printing.
@@ -51,7 +54,16 @@ func (t treeMessage) String() string {
 	if t.IsDir {
 		entryType = "Dir"
 	}
-	return fmt.Sprintf("%s%s", t.BranchString, console.Colorize(entryType, t.Entry))
+	msg := fmt.Sprintf("%s%s", t.BranchString, console.Colorize(entryType, t.Entry))
+	if t.Usage != nil {
+		humanSize := strings.Join(strings.Fields(humanize.IBytes(uint64(t.Usage.Size))), "")
+		if t.IsDir {
+			msg += console.Colorize("Usage", fmt.Sprintf(" [%s, %d object(s)]", humanSize, t.Usage.Objects))
+		} else {
+			msg += console.Colorize("Usage", fmt.Sprintf(" [%s]", humanSize))
+		}
+	}
+	return msg
 }
 
 // JSON'ified message for scripting.
@@ -75,6 +87,10 @@ var treeFlags = []cli.Flag{
 		Name:  "rewind",
 		Usage: "display tree no later than specified date",
 	},
+	cli.BoolFlag{
+		Name:  "du",
+		Usage: "show the total size and number of objects of each folder",
+	},
 }
 
 // trees files and folders.
@@ -109,6 +125,9 @@ EXAMPLES:
 
    5. List all directories upto depth level '2' in tree format.
       {{.Prompt}} {{.HelpName}} --depth 2 myminio/mybucket/
+
+   6. Map the capacity used by the first two levels of folders of "mybucket".
+      {{.Prompt}} {{.HelpName}} --du --depth 2 myminio/mybucket/
 `,
 }
 
@@ -126,6 +145,10 @@ func parseTreeSyntax(ctx context.Context, cliCtx *cli.Context) (args []string, d
 			"please set a proper depth, for example: '--depth 1' to limit the tree output, default (-1) output displays everything")
 	}
 
+	if cliCtx.Bool("du") && globalJSON {
+		fatalIf(errInvalidArgument().Trace(args...), "`--du` cannot be used with `--json`.")
+	}
+
 	if len(args) == 0 {
 		return
 	}
@@ -138,8 +161,57 @@ func parseTreeSyntax(ctx context.Context, cliCtx *cli.Context) (args []string, d
 	return
 }
 
-// doTree - list all entities inside a folder in a tree format.
-func doTree(ctx context.Context, url string, timeRef time.Time, level int, leaf bool, branchString string, depth int, includeFiles bool) error {
+// treeKey returns the key of a folder in the usage of a tree.
+func treeKey(path string) string {
+	return strings.TrimSuffix(filepath.ToSlash(path), "/")
+}
+
+// treeUsage sums the size and number of objects under each folder of the
+// target with a single recursive listing.
+func treeUsage(ctx context.Context, url string, timeRef time.Time) (map[string]duClassUsage, *probe.Error) {
+	targetAlias, targetURL, _ := mustExpandAlias(url)
+	if !strings.HasSuffix(targetURL, "/") {
+		targetURL += "/"
+	}
+	clnt, err := newClientFromAlias(targetAlias, targetURL)
+	if err != nil {
+		return nil, err.Trace(targetURL)
+	}
+
+	root := treeKey(clnt.GetURL().Path)
+	usage := make(map[string]duClassUsage)
+	add := func(key string, size int64) {
+		u := usage[key]
+		u.Size += size
+		u.Objects++
+		usage[key] = u
+	}
+	for content := range clnt.List(ctx, ListOptions{Recursive: true, TimeRef: timeRef, ShowDir: DirNone}) {
+		if content.Err != nil {
+			errorIf(content.Err.Trace(clnt.GetURL().String()), "Unable to list folder.")
+			continue
+		}
+		if content.Type.IsDir() {
+			continue
+		}
+		add(root, content.Size)
+		path := filepath.ToSlash(content.URL.Path)
+		if !strings.HasPrefix(path, root+"/") {
+			continue
+		}
+		// Add the object to each of its folders below the root.
+		for i := len(root) + 1; i < len(path); i++ {
+			if path[i] == '/' {
+				add(path[:i], content.Size)
+			}
+		}
+	}
+	return usage, nil
+}
+
+// doTree - list all entities inside a folder in a tree format, with the
+// usage of each entry if passed.
+func doTree(ctx context.Context, url string, timeRef time.Time, level int, leaf bool, branchString string, depth int, includeFiles bool, usage map[string]duClassUsage) error {
 
 	targetAlias, targetURL, _ := mustExpandAlias(url)
 	if !strings.HasSuffix(targetURL, "/") {
@@ -161,11 +233,16 @@ func doTree(ctx context.Context, url string, timeRef time.Time, level int, leaf
 		currbranchString := branchString
 		if level == 1 && !bucketNameShowed {
 			bucketNameShowed = true
-			printMsg(treeMessage{
+			msg := treeMessage{
 				Entry:        url,
 				IsDir:        true,
 				BranchString: branchString,
-			})
+			}
+			if usage != nil {
+				u := usage[treeKey(clnt.GetURL().Path)]
+				msg.Usage = &u
+			}
+			printMsg(msg)
 		}
 
 		isLevelClosed := strings.HasSuffix(currbranchString, treeLastEntry)
@@ -196,19 +273,28 @@ func doTree(ctx context.Context, url string, timeRef time.Time, level int, leaf
 		// Trim prefix of current working dir
 		prefixPath = strings.TrimPrefix(prefixPath, "."+separator)
 
+		var msg treeMessage
 		if prev.Type.IsDir() {
-			printMsg(treeMessage{
+			msg = treeMessage{
 				Entry:        strings.TrimSuffix(strings.TrimPrefix(contentURL, prefixPath), "/"),
 				IsDir:        true,
 				BranchString: currbranchString,
-			})
+			}
+			if usage != nil {
+				u := usage[treeKey(contentURL)]
+				msg.Usage = &u
+			}
 		} else {
-			printMsg(treeMessage{
+			msg = treeMessage{
 				Entry:        strings.TrimPrefix(contentURL, prefixPath),
 				IsDir:        false,
 				BranchString: currbranchString,
-			})
+			}
+			if usage != nil {
+				msg.Usage = &duClassUsage{Size: prev.Size, Objects: 1}
+			}
 		}
+		printMsg(msg)
 
 		if prev.Type.IsDir() {
 			url := ""
@@ -219,7 +305,7 @@ func doTree(ctx context.Context, url string, timeRef time.Time, level int, leaf
 			}
 
 			if depth == -1 || level <= depth {
-				if err := doTree(ctx, url, timeRef, level+1, end, currbranchString, depth, includeFiles); err != nil {
+				if err := doTree(ctx, url, timeRef, level+1, end, currbranchString, depth, includeFiles, usage); err != nil {
 					return err
 				}
 			}
@@ -263,6 +349,7 @@ func mainTree(cliCtx *cli.Context) error {
 
 	console.SetColor("File", color.New(color.Bold))
 	console.SetColor("Dir", color.New(color.FgCyan, color.Bold))
+	console.SetColor("Usage", color.New(color.FgYellow))
 
 	// parse 'tree' cliCtx arguments.
 	args, depth, includeFiles, timeRef := parseTreeSyntax(ctx, cliCtx)
@@ -275,7 +362,13 @@ func mainTree(cliCtx *cli.Context) error {
 	var cErr error
 	for _, targetURL := range args {
 		if !globalJSON {
-			if e := doTree(ctx, targetURL, timeRef, 1, false, "", depth, includeFiles); e != nil {
+			var usage map[string]duClassUsage
+			if cliCtx.Bool("du") {
+				var err *probe.Error
+				usage, err = treeUsage(ctx, targetURL, timeRef)
+				fatalIf(err, "Unable to summarize disk usage of `"+targetURL+"`.")
+			}
+			if e := doTree(ctx, targetURL, timeRef, 1, false, "", depth, includeFiles, usage); e != nil {
 				cErr = e
 			}
 		} else {
@@ -285,7 +378,7 @@ func mainTree(cliCtx *cli.Context) error {
 			}
 			clnt, err := newClientFromAlias(targetAlias, targetURL)
 			fatalIf(err.Trace(targetURL), "Unable to initialize target `"+targetURL+"`.")
-			if e := doList(ctx, clnt, true, false, false, timeRef, false); e != nil {
+			if e := doList(ctx, clnt, true, false, false, timeRef, false, "", nil, lsFormat{}, nil, nil); e != nil {
 				cErr = e
 			}
 		}
@@ -0,0 +1,68 @@
+// Copyright (c) 2015-2021 MinIO, Inc.
+//
+// This file is part of MinIO Object Storage stack
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public License for more details.
+//
+// You should have received a copy of the GNU Affero General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTreeUsage(t *testing.T) {
+	defer setMcConfigDir(mcCustomConfigDir)
+	setMcConfigDir(t.TempDir())
+	loadMcConfig = loadMcConfigFactory()
+
+	root := t.TempDir()
+	for name, size := range map[string]int{
+		"top":          7,
+		"x/f2":         500,
+		"x/y/f1":       1000,
+		"z/f3":         20,
+		"x/y/w/v/deep": 3,
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage, err := treeUsage(context.Background(), root, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := func(name string) string { return treeKey(filepath.Join(root, filepath.FromSlash(name))) }
+	expected := map[string]duClassUsage{
+		key(""):        {Size: 1530, Objects: 5},
+		key("x"):       {Size: 1503, Objects: 3},
+		key("x/y"):     {Size: 1003, Objects: 2},
+		key("x/y/w"):   {Size: 3, Objects: 1},
+		key("x/y/w/v"): {Size: 3, Objects: 1},
+		key("z"):       {Size: 20, Objects: 1},
+	}
+	if !reflect.DeepEqual(usage, expected) {
+		t.Errorf("expected %v, got %v", expected, usage)
+	}
+}
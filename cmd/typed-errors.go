@@ -53,6 +53,13 @@ var errInvalidAliasedURL = func(URL string) *probe.Error {
 	return probe.NewError(invalidAliasedURLErr(errors.New(msg))).Untrace()
 }
 
+type regionMismatchErr error
+
+var errRegionMismatch = func(region, serverRegion string) *probe.Error {
+	msg := "Region `" + region + "` does not match the region `" + serverRegion + "` of the server, use `--region " + serverRegion + "` or leave it out to detect it."
+	return probe.NewError(regionMismatchErr(errors.New(msg))).Untrace()
+}
+
 type invalidAliasErr error
 
 var errInvalidAlias = func(alias string) *probe.Error {
@@ -104,6 +111,13 @@ var errTargetNotFound = func(URL string) *probe.Error {
 	return probe.NewError(targetNotFoundErr(errors.New(msg))).Untrace()
 }
 
+type mirrorConflictErr error
+
+var errMirrorConflict = func(URL string) *probe.Error {
+	msg := "Object `" + URL + "` differs from the source, not overwritten with `--conflict fail`."
+	return probe.NewError(mirrorConflictErr(errors.New(msg))).Untrace()
+}
+
 type overwriteNotAllowedErr struct {
 	error
 }
@@ -18,6 +18,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/minio/mc/pkg/probe"
 )
 
@@ -31,7 +33,11 @@ type URLs struct {
 	TotalSize        int64
 	MD5              bool
 	DisableMultipart bool
+	SHA256           string `json:",omitempty"`
 	encKeyDB         map[string][]prefixSSEPair
+	resumeSession    *sessionV8
+	targetRoot       string
+	duration         time.Duration
 	Error            *probe.Error `json:"-"`
 	ErrorCond        differType   `json:"-"`
 }
@@ -152,6 +152,8 @@ func NewS3Config(urlStr string, aliasCfg *aliasConfigV10) *Config {
 		s3Config.SecretKey = aliasCfg.SecretKey
 		s3Config.SessionToken = aliasCfg.SessionToken
 		s3Config.Signature = aliasCfg.API
+		s3Config.Region = aliasCfg.Region
+		s3Config.endpoints = aliasCfg.endpointSet
 	}
 	s3Config.Lookup = getLookupType(aliasCfg.Path)
 	return s3Config
@@ -456,7 +458,8 @@ func httpClient(timeout time.Duration) *http.Client {
 	return &http.Client{
 		Timeout: timeout,
 		Transport: &http.Transport{
-			Proxy: ieproxy.GetProxyFunc(),
+			Proxy:       ieproxy.GetProxyFunc(),
+			DialContext: newCustomDialContext(10*time.Second, 15*time.Second),
 			TLSClientConfig: &tls.Config{
 				RootCAs: globalRootCAs,
 				// Can't use SSLv3 because of POODLE and BEAST